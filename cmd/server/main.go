@@ -1,21 +1,179 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/analytics"
 	"github.com/werewolf-game/backend/internal/game"
 	"github.com/werewolf-game/backend/internal/handlers"
+	"github.com/werewolf-game/backend/internal/store"
 )
 
+// shutdownTimeout is how long a stopping server waits for requests in flight
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	// Export gameplay analytics when ANALYTICS_FILE is set (off by default)
+	var observers []game.RoomObserver
+	var exporter *analytics.Exporter
+	if path := os.Getenv("ANALYTICS_FILE"); path != "" {
+		maxMB := 100
+		if v := os.Getenv("ANALYTICS_MAX_MB"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				log.Fatal("Invalid ANALYTICS_MAX_MB:", err)
+			}
+			maxMB = n
+		}
+
+		sink, err := analytics.NewFileSink(path, int64(maxMB)<<20)
+		if err != nil {
+			log.Fatal("Failed to open analytics file:", err)
+		}
+		exporter = analytics.NewExporter(sink, analytics.Config{
+			RawIDs: os.Getenv("ANALYTICS_RAW_IDS") == "1",
+		})
+		defer exporter.Close()
+		observers = append(observers, exporter)
+	}
+
+	// Push public room changes to lobby browsers
+	lobbyFeed := handlers.NewLobbyFeed()
+	observers = append(observers, lobbyFeed)
+
+	// Disconnect whoever is still in a game when it is archived
+	observers = append(observers, handlers.NewRoomCloser())
+
+	// Stop counting an address's public room memberships as they end
+	observers = append(observers, handlers.NewMembershipReleaser())
+
 	// Initialize game manager
-	gameManager := game.NewGameManager()
+	gameManager := game.NewGameManager(observers...)
+
+	// Cap the number of rooms from environment variable, default to no cap
+	if maxRooms := os.Getenv("MAX_ROOMS"); maxRooms != "" {
+		n, err := strconv.Atoi(maxRooms)
+		if err != nil {
+			log.Fatal("Invalid MAX_ROOMS:", err)
+		}
+		gameManager.MaxRooms = n
+	}
+
+	// Hold starts back after a settings change, default 5 seconds
+	if cooldown := os.Getenv("SETTINGS_ACK_SECONDS"); cooldown != "" {
+		n, err := strconv.Atoi(cooldown)
+		if err != nil {
+			log.Fatal("Invalid SETTINGS_ACK_SECONDS:", err)
+		}
+		gameManager.SettingsAckCooldown = time.Duration(n) * time.Second
+	}
+
+	// Resolve nights that run past this many seconds, default 300; 0 disables it
+	if ceiling := os.Getenv("NIGHT_CEILING_SECONDS"); ceiling != "" {
+		n, err := strconv.Atoi(ceiling)
+		if err != nil {
+			log.Fatal("Invalid NIGHT_CEILING_SECONDS:", err)
+		}
+		gameManager.NightCeiling = time.Duration(n) * time.Second
+	}
+
+	// Tiger-team players gone this many seconds flee the game, default 180; 0 disables it
+	if seconds := os.Getenv("WITHDRAW_AFTER_SECONDS"); seconds != "" {
+		n, err := strconv.Atoi(seconds)
+		if err != nil {
+			log.Fatal("Invalid WITHDRAW_AFTER_SECONDS:", err)
+		}
+		gameManager.WithdrawAfter = time.Duration(n) * time.Second
+	}
+
+	// Voters reconnecting with less than this many seconds of the vote left get that long, default 15; 0 disables it
+	if seconds := os.Getenv("RECONNECT_VOTE_GRACE_SECONDS"); seconds != "" {
+		n, err := strconv.Atoi(seconds)
+		if err != nil {
+			log.Fatal("Invalid RECONNECT_VOTE_GRACE_SECONDS:", err)
+		}
+		gameManager.ReconnectVoteGrace = time.Duration(n) * time.Second
+	}
+
+	// Spill chat that outgrows memory to CHAT_STORE_DIR (off by default)
+	if dir := os.Getenv("CHAT_STORE_DIR"); dir != "" {
+		chatStore, err := store.NewFileChatStore(dir)
+		if err != nil {
+			log.Fatal("Failed to open chat store:", err)
+		}
+		gameManager.ChatStore = chatStore
+	}
+
+	// Check room invariants after every change when debugging
+	gameManager.DebugValidate = os.Getenv("DEBUG_VALIDATE") == "1"
+	gameManager.AutoRepairStuckRooms = os.Getenv("AUTO_REPAIR_STUCK_ROOMS") == "1"
+
+	// Reclaim abandoned rooms in the background
+	stopJanitor := gameManager.StartJanitor(game.DefaultJanitorInterval, game.DefaultRoomIdleTimeout)
+	defer stopJanitor()
+
+	// Rescue nights stuck past their ceiling
+	stopNightWatch := gameManager.StartNightWatch(game.DefaultNightWatchInterval, handlers.AnnounceForcedNight(gameManager))
+	defer stopNightWatch()
+
+	// Keep games moving when players leave for good
+	stopWithdrawalWatch := gameManager.StartWithdrawalWatch(game.DefaultWithdrawalWatchInterval, handlers.AnnounceWithdrawal(gameManager))
+	defer stopWithdrawalWatch()
+
+	// Tell rooms that went quiet who is still there, default every 30 seconds; 0 disables it
+	heartbeat := handlers.DefaultRoomHeartbeatInterval
+	if seconds := os.Getenv("ROOM_HEARTBEAT_SECONDS"); seconds != "" {
+		n, err := strconv.Atoi(seconds)
+		if err != nil {
+			log.Fatal("Invalid ROOM_HEARTBEAT_SECONDS:", err)
+		}
+		heartbeat = time.Duration(n) * time.Second
+	}
+	handlers.StartRoomHeartbeat(gameManager, heartbeat)
+
+	// Stop each room's sender, delay lines and held-back lobby update along with its room
+	handlers.ScopeRoomSenders(gameManager)
+	lobbyFeed.SetSpawner(gameManager.GoRoom)
+
+	// Keep delayed spectators this many seconds behind the game, default 90; 0 disables the mode
+	if seconds := os.Getenv("SPECTATOR_DELAY_SECONDS"); seconds != "" {
+		n, err := strconv.Atoi(seconds)
+		if err != nil {
+			log.Fatal("Invalid SPECTATOR_DELAY_SECONDS:", err)
+		}
+		handlers.SpectatorDelay = time.Duration(n) * time.Second
+	}
+
+	// Cap the public rooms one address may hold players in, default 3; 0 disables the cap
+	if limit := os.Getenv("MAX_PUBLIC_ROOMS_PER_IP"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			log.Fatal("Invalid MAX_PUBLIC_ROOMS_PER_IP:", err)
+		}
+		handlers.PublicRoomsPerAddr = n
+	}
 
 	// Setup Gin router
 	router := gin.Default()
 
+	// Only take client addresses from forwarding headers set by these
+	// comma-separated proxies; unset keeps gin's default of trusting any
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		if err := router.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+			log.Fatal("Invalid TRUSTED_PROXIES:", err)
+		}
+	}
+
 	// CORS middleware
 	router.Use(func(c *gin.Context) {
 		// Get allowed origin from environment variable, default to "*" for development
@@ -26,7 +184,7 @@ func main() {
 
 		c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Session-Token")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == "OPTIONS" {
@@ -40,26 +198,97 @@ func main() {
 	// API routes
 	api := router.Group("/api")
 	{
+		api.GET("/roles", handlers.ListRoles())
+		api.GET("/cues", handlers.ListCues())
+		api.GET("/rooms", handlers.ListRooms(gameManager))
 		api.POST("/rooms", handlers.CreateRoom(gameManager))
 		api.GET("/rooms/:code", handlers.GetRoom(gameManager))
+		api.GET("/rooms/:code/chat", handlers.ChatHistory(gameManager))
 		api.POST("/rooms/:code/join", handlers.JoinRoom(gameManager))
+		api.GET("/rooms/:code/join-requests/:id", handlers.JoinRequestStatus(gameManager))
+		api.POST("/rooms/:code/actions", handlers.PerformAction(gameManager))
+		api.POST("/rooms/:code/practice/resolve-night", handlers.PreviewNight(gameManager))
+		api.POST("/rooms/:code/invites", handlers.CreateInvites(gameManager))
+		api.GET("/rooms/:code/invites", handlers.ListInvites(gameManager))
+		api.DELETE("/rooms/:code/invites/:token", handlers.RevokeInvite(gameManager))
+		api.POST("/rooms/join-by-invite", handlers.JoinByInvite(gameManager))
+	}
+
+	// Admin routes, enabled by setting ADMIN_TOKEN
+	admin := api.Group("/admin", handlers.AdminAuth(os.Getenv("ADMIN_TOKEN")))
+	{
+		admin.GET("/rooms", handlers.AdminListRooms(gameManager))
+		admin.GET("/clients", handlers.AdminListClients())
+		admin.POST("/rooms/:code/repair", handlers.AdminRepairRoom(gameManager))
+		admin.GET("/rooms/:code/fixture", handlers.AdminRecordFixture(gameManager))
+		admin.GET("/rooms/:code/host-actions", handlers.AdminHostActions(gameManager))
+		admin.GET("/rooms/:code/disconnects", handlers.AdminDisconnects(gameManager))
+		admin.POST("/announce", handlers.AdminAnnounce(gameManager))
+		admin.DELETE("/announce", handlers.AdminClearAnnouncement(gameManager))
+	}
+
+	// Profiler and room inspector, behind the admin token, when DEBUG_ENDPOINTS=true
+	if os.Getenv("DEBUG_ENDPOINTS") == "true" {
+		handlers.MountDebug(router, gameManager, os.Getenv("ADMIN_TOKEN"))
 	}
 
 	// WebSocket endpoint
-	router.GET("/ws", handlers.HandleWebSocket(gameManager))
+	router.GET(handlers.WebSocketPath, handlers.HandleWebSocket(gameManager))
+	router.GET(handlers.LobbyPath, handlers.HandleLobby(gameManager))
 
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Readiness check with room capacity for load balancers and dashboards
+	router.GET("/readyz", func(c *gin.Context) {
+		body := gin.H{
+			"status":             "ready",
+			"capacity":           gameManager.Capacity(),
+			"clientProtocols":    handlers.ProtocolCounts(),
+			"clientCapabilities": handlers.CapabilityCounts(),
+			"broadcastDrops":     handlers.BroadcastDrops(),
+			"nightForceResolved": gameManager.NightsForceResolved(),
+			"chatSpilled":        gameManager.ChatSpilled(),
+			"stuckRooms":         gameManager.StuckRooms(),
+			"roomGoroutines":     gameManager.RoomGoroutines(),
+			"overdueShutdowns":   gameManager.OverdueRoomShutdowns(),
+			"disconnects":        gameManager.DisconnectCounts(),
+			"pingLatency":        handlers.PingLatency(),
+		}
+		if exporter != nil {
+			body["analyticsDropped"] = exporter.Dropped()
+		}
+		c.JSON(200, body)
+	})
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	// Stop on SIGINT or SIGTERM by returning from main, so the deferred
+	// cleanup above runs and queued analytics reach the file
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		log.Printf("🛑 Shutting down, waiting up to %s for requests in flight", shutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Shutdown: %v", err)
+		}
+	}()
+
 	log.Printf("🎮 Werewolf Game Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal("Failed to start server:", err)
 	}
+	<-stopped
 }
@@ -0,0 +1,216 @@
+// Command simulate plays a complete game in-process with random players.
+// It drives the rules engine directly, the way a non-websocket frontend would.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// narrator prints the engine's events. It runs under the manager's lock
+// and must not call back into it.
+type narrator struct {
+	game.NopObserver
+}
+
+func (narrator) OnGameStarted(room *models.GameRoom) {
+	players := make([]*models.Player, 0, len(room.Players))
+	for _, player := range room.Players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Seat < players[j].Seat })
+
+	for _, player := range players {
+		fmt.Printf("  seat %d: %s is a %s\n", player.Seat, player.Username, player.Role)
+	}
+}
+
+func (narrator) OnPhaseChanged(room *models.GameRoom, from models.GamePhase) {
+	fmt.Printf("round %d: %s -> %s\n", room.Round, from, room.Phase)
+}
+
+func (narrator) OnPlayerDied(room *models.GameRoom, player *models.Player) {
+	fmt.Printf("  %s (%s) died: %s\n", player.Username, player.Role, player.DeathCause)
+}
+
+func (narrator) OnGameEnded(room *models.GameRoom, winner string) {
+	fmt.Printf("game over after %d rounds: %s team wins\n", room.Round, winner)
+}
+
+func main() {
+	players := flag.Int("players", 7, "number of players (5-20)")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed for the players' choices")
+	flag.Parse()
+
+	fmt.Printf("seed %d\n", *seed)
+	if _, err := simulate(*players, *seed, narrator{}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// simulate plays a game of the given size to its end and returns the final board
+func simulate(players int, seed int64, observer game.RoomObserver) (*models.GameRoom, error) {
+	gm := game.NewGameManager(observer)
+	gm.Seed = func() int64 { return seed }
+
+	settings := models.DefaultRoomSettings()
+	settings.MinDiscussionSeconds = 0
+	settings.QuorumFraction = 0 // nobody holds a connection in a simulation
+	if players > settings.MaxPlayers {
+		settings.MaxPlayers = players
+	}
+	room, err := gm.CreateRoom("player-1", "Player 1", settings)
+	if err != nil {
+		return nil, err
+	}
+	code := room.Code
+
+	for i := 2; i <= players; i++ {
+		id := fmt.Sprintf("player-%d", i)
+		if _, err := gm.JoinRoom(code, id, fmt.Sprintf("Player %d", i)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := gm.StartGame(code, room.HostID); err != nil {
+		return nil, err
+	}
+
+	s := &simulation{gm: gm, code: code, rng: rand.New(rand.NewSource(seed))}
+	for turns := 0; s.phase() != models.PhaseEnded; turns++ {
+		if turns > 1000 {
+			return nil, errors.New("game did not finish")
+		}
+
+		switch s.phase() {
+		case models.PhaseNight:
+			if err := s.playNight(); err != nil {
+				return nil, err
+			}
+		case models.PhaseVoting:
+			s.playVote()
+		}
+		if err := s.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	view, _ := gm.RoomView(code, "")
+	return view, nil
+}
+
+// simulation makes every player's choices at random
+type simulation struct {
+	gm   *game.GameManager
+	code models.RoomCode
+	rng  *rand.Rand
+}
+
+func (s *simulation) phase() models.GamePhase {
+	view, _ := s.gm.RoomView(s.code, "")
+	return view.Phase
+}
+
+// advance ends the current phase, letting a dead hunter shoot first
+func (s *simulation) advance() error {
+	if _, err := s.gm.MoveToNextPhase(s.code); err != nil {
+		return err
+	}
+
+	view, _ := s.gm.RoomView(s.code, "")
+	if !view.WaitingHunterShoot {
+		return nil
+	}
+
+	hunterID := view.DeadHunterID
+	target := s.pick(hunterID, func(*models.Player) bool { return true })
+	if _, err := s.gm.HunterShoot(s.code, hunterID, target, true); err != nil {
+		return err
+	}
+	if s.phase() != models.PhaseEnded {
+		return s.advance()
+	}
+	return nil
+}
+
+// playNight lets each night role act in turn
+func (s *simulation) playNight() error {
+	for step := 0; step < 10; step++ {
+		prompts, open := s.gm.CurrentTurnPrompts(s.code)
+		if open {
+			for playerID, prompt := range prompts {
+				s.act(prompt, playerID)
+			}
+		}
+
+		done, err := s.gm.MoveToNextNightRole(s.code)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	return nil
+}
+
+// act makes one player's night choice, skipping when it is refused
+func (s *simulation) act(prompt game.TurnPrompt, playerID string) {
+	view, _ := s.gm.RoomView(s.code, playerID)
+	self := view.Players[playerID]
+
+	target := s.pick(playerID, func(p *models.Player) bool {
+		switch prompt.Role {
+		case models.RoleTiger:
+			return p.Role != models.RoleTiger && p.Role != models.RoleAlphaTiger
+		case models.RoleHunter:
+			return p.ID != self.LastProtected
+		}
+		return true
+	})
+
+	if target != "" {
+		if err := s.gm.PerformNightAction(s.code, playerID, target, prompt.TurnToken); err == nil {
+			return
+		}
+	}
+	if err := s.gm.SkipNightAction(s.code, playerID, prompt.TurnToken); err != nil {
+		log.Printf("%s could not act: %v", playerID, err)
+	}
+}
+
+// playVote has every living player vote for someone else
+func (s *simulation) playVote() {
+	view, _ := s.gm.RoomView(s.code, "")
+	for _, voter := range view.LivingPlayers() {
+		target := s.pick(voter.ID, func(*models.Player) bool { return true })
+		if err := s.gm.Vote(s.code, voter.ID, target); err != nil {
+			log.Printf("%s could not vote: %v", voter.ID, err)
+		}
+	}
+}
+
+// pick chooses a random living player other than the chooser, as seen by them
+func (s *simulation) pick(chooserID string, allowed func(*models.Player) bool) string {
+	view, _ := s.gm.RoomView(s.code, chooserID)
+
+	// Living players come ordered by ID, so a seed replays the same choices
+	var candidates []string
+	for _, player := range view.LivingPlayers() {
+		if player.ID != chooserID && allowed(player) {
+			candidates = append(candidates, player.ID)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[s.rng.Intn(len(candidates))]
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const modulePath = "github.com/werewolf-game/backend/"
+
+func TestSimulatedGamesFinish(t *testing.T) {
+	for players := 5; players <= 12; players++ {
+		for seed := int64(1); seed <= 10; seed++ {
+			room, err := simulate(players, seed, game.NopObserver{})
+			if err != nil {
+				t.Fatalf("%d players, seed %d: %v", players, seed, err)
+			}
+			if room.Phase != models.PhaseEnded || room.WinningTeam == "" {
+				t.Fatalf("%d players, seed %d: ended in %s with winner %q", players, seed, room.Phase, room.WinningTeam)
+			}
+		}
+	}
+}
+
+func TestSimulationIsReplayable(t *testing.T) {
+	first, _ := simulate(8, 42, game.NopObserver{})
+	again, _ := simulate(8, 42, game.NopObserver{})
+	for id, player := range first.Players {
+		if other := again.Players[id]; other.Role != player.Role || other.IsAlive != player.IsAlive {
+			t.Errorf("%s: %s alive %v, then %s alive %v", id, player.Role, player.IsAlive, other.Role, other.IsAlive)
+		}
+	}
+}
+
+// TestEngineStandsAlone walks the simulator's imports within the module and
+// fails if driving a game pulls in the transport
+func TestEngineStandsAlone(t *testing.T) {
+	seen := map[string]bool{}
+	var walk func(dir string)
+	walk = func(dir string) {
+		if seen[dir] {
+			return
+		}
+		seen[dir] = true
+
+		pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(info fs.FileInfo) bool {
+			return !strings.HasSuffix(info.Name(), "_test.go")
+		}, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pkg := range pkgs {
+			for name, file := range pkg.Files {
+				for _, spec := range file.Imports {
+					path, _ := strconv.Unquote(spec.Path.Value)
+					switch {
+					case strings.Contains(path, "gin-gonic"), strings.Contains(path, "gorilla"),
+						path == "net/http", strings.HasSuffix(path, "/internal/handlers"), strings.HasSuffix(path, "/internal/ws"):
+						t.Errorf("%s imports %s", name, path)
+					case strings.HasPrefix(path, modulePath):
+						walk(filepath.Join("..", "..", strings.TrimPrefix(path, modulePath)))
+					}
+				}
+			}
+		}
+	}
+	walk(".")
+
+	if !seen[filepath.Join("..", "..", "internal", "game")] {
+		t.Error("the walk never reached the engine")
+	}
+}
@@ -0,0 +1,311 @@
+package analytics
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Record is one flat analytics event. Player IDs are anonymized unless the
+// exporter was built with RawIDs; usernames are never exported.
+type Record struct {
+	Time       models.Timestamp `json:"ts"`
+	Event      string           `json:"event"`
+	Room       string           `json:"room"`
+	Round      int              `json:"round"`
+	Phase      string           `json:"phase,omitempty"`
+	From       string           `json:"from,omitempty"`
+	Reason     string           `json:"reason,omitempty"`
+	Player     string           `json:"player,omitempty"`
+	Role       string           `json:"role,omitempty"`
+	Alive      *bool            `json:"alive,omitempty"`
+	Action     string           `json:"action,omitempty"`
+	Target     string           `json:"target,omitempty"`
+	TargetRole string           `json:"targetRole,omitempty"`
+	Outcome    string           `json:"outcome,omitempty"`
+	Cause      string           `json:"cause,omitempty"`
+	Winner     string           `json:"winner,omitempty"`
+	Players    int              `json:"players,omitempty"`
+}
+
+// Config controls an Exporter
+type Config struct {
+	Buffer int  // records queued before new ones are dropped (default 1024)
+	RawIDs bool // export player IDs as-is instead of anonymizing them
+}
+
+// Exporter is a game.RoomObserver that streams gameplay events to a Sink.
+// Observers run under the game lock, so records are queued without blocking
+// and dropped when the queue is full.
+type Exporter struct {
+	game.NopObserver
+
+	sink    Sink
+	dropped atomic.Int64
+	rawIDs  bool
+	salt    []byte
+	done    chan struct{}
+
+	// queueMu guards sending on queue against Close closing it
+	queueMu sync.RWMutex
+	queue   chan []byte
+	closed  bool
+
+	// exported counts, per room and player, the action records already sent;
+	// ballots counts, per room, the vote history entries already sent
+	mu       sync.Mutex
+	exported map[models.RoomCode]map[string]int
+	ballots  map[models.RoomCode]int
+}
+
+// NewExporter starts an exporter writing to sink
+func NewExporter(sink Sink, cfg Config) *Exporter {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1024
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		log.Printf("Analytics salt error: %v", err)
+	}
+
+	e := &Exporter{
+		sink:     sink,
+		queue:    make(chan []byte, cfg.Buffer),
+		rawIDs:   cfg.RawIDs,
+		salt:     salt,
+		done:     make(chan struct{}),
+		exported: make(map[models.RoomCode]map[string]int),
+		ballots:  make(map[models.RoomCode]int),
+	}
+	go e.run()
+	return e
+}
+
+// Dropped returns how many records were discarded because the sink fell
+// behind or the exporter was already closed
+func (e *Exporter) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Close flushes queued records and closes the sink. Records emitted after
+// Close are dropped; closing again does nothing.
+func (e *Exporter) Close() error {
+	e.queueMu.Lock()
+	if e.closed {
+		e.queueMu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.queue)
+	e.queueMu.Unlock()
+
+	<-e.done
+	return e.sink.Close()
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	for record := range e.queue {
+		if err := e.sink.Write(record); err != nil {
+			log.Printf("Analytics sink error: %v", err)
+		}
+	}
+}
+
+func (e *Exporter) OnRoomCreated(room *models.GameRoom) {
+	e.emit(e.record("room_created", room))
+}
+
+func (e *Exporter) OnPlayerJoined(room *models.GameRoom, player *models.Player) {
+	r := e.record("player_joined", room)
+	r.Player = e.anonymize(player.ID)
+	r.Players = len(room.Players)
+	e.emit(r)
+}
+
+func (e *Exporter) OnGameStarted(room *models.GameRoom) {
+	r := e.record("game_started", room)
+	r.Players = len(room.Players)
+	e.emit(r)
+
+	for _, player := range room.Players {
+		r := e.record("role_assigned", room)
+		r.Player = e.anonymize(player.ID)
+		r.Role = string(player.Role)
+		e.emit(r)
+	}
+}
+
+func (e *Exporter) OnPhaseChanged(room *models.GameRoom, from models.GamePhase) {
+	e.flushActions(room, false)
+
+	r := e.record("phase_changed", room)
+	r.From = string(from)
+	r.Reason = room.PhaseReason
+	e.emit(r)
+}
+
+func (e *Exporter) OnPlayerDied(room *models.GameRoom, player *models.Player) {
+	r := e.record("player_died", room)
+	r.Player = e.anonymize(player.ID)
+	r.Role = string(player.Role)
+	r.Cause = player.DeathCause
+	e.emit(r)
+}
+
+func (e *Exporter) OnGameEnded(room *models.GameRoom, winner string) {
+	e.flushActions(room, true)
+
+	r := e.record("game_ended", room)
+	r.Winner = winner
+	r.Reason = room.PhaseReason
+	r.Players = len(room.Players)
+	e.emit(r)
+
+	// An aborted game is not a result for anyone
+	if room.PhaseReason == game.ReasonAborted {
+		return
+	}
+
+	for _, player := range room.Players {
+		alive := player.IsAlive
+		r := e.record("player_result", room)
+		r.Player = e.anonymize(player.ID)
+		r.Role = string(player.Role)
+		r.Alive = &alive
+		r.Winner = winner
+		e.emit(r)
+	}
+}
+
+func (e *Exporter) OnHostAction(room *models.GameRoom, action models.HostAction) {
+	r := e.record("host_action", room)
+	r.Player = e.anonymize(action.ActorID)
+	r.Action = action.Action
+	if action.TargetID != "" {
+		r.Target = e.anonymize(action.TargetID)
+	}
+	e.emit(r)
+}
+
+func (e *Exporter) OnRoomDeleted(room *models.GameRoom) {
+	e.mu.Lock()
+	delete(e.exported, room.Code)
+	delete(e.ballots, room.Code)
+	e.mu.Unlock()
+
+	e.emit(e.record("room_deleted", room))
+}
+
+// flushActions exports every action record and ballot not yet sent. Records from
+// the phase still in progress wait until their outcome is settled, unless all is set.
+func (e *Exporter) flushActions(room *models.GameRoom, all bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cursors := e.exported[room.Code]
+	if cursors == nil {
+		cursors = make(map[string]int)
+		e.exported[room.Code] = cursors
+	}
+
+	for _, player := range room.Players {
+		history := player.ActionHistory
+		i := cursors[player.ID]
+		for ; i < len(history); i++ {
+			action := history[i]
+			if !all && action.Outcome == "" && action.Round == room.Round && action.Phase == room.Phase {
+				break
+			}
+
+			r := e.record("action", room)
+			r.Round = action.Round
+			r.Phase = string(action.Phase)
+			r.Player = e.anonymize(player.ID)
+			r.Role = string(player.Role)
+			r.Action = action.ActionType
+			r.Outcome = action.Outcome
+			if target := room.Players[action.TargetID]; target != nil {
+				r.Target = e.anonymize(target.ID)
+				r.TargetRole = string(target.Role)
+			}
+			e.emit(r)
+		}
+		cursors[player.ID] = i
+	}
+
+	// Ballots are settled as soon as they are in the history; a shorter
+	// history means a new game started in the room
+	sent := e.ballots[room.Code]
+	if sent > len(room.VoteHistory) {
+		sent = 0
+	}
+	for _, ballot := range room.VoteHistory[sent:] {
+		r := e.record("ballot", room)
+		r.Round = ballot.Round
+		r.Phase = string(models.PhaseVoting)
+		r.Outcome = ballot.Outcome
+		if voter := room.Players[ballot.VoterID]; voter != nil {
+			r.Player = e.anonymize(voter.ID)
+			r.Role = string(voter.Role)
+		}
+		if target := room.Players[ballot.TargetID]; target != nil {
+			r.Target = e.anonymize(target.ID)
+			r.TargetRole = string(target.Role)
+		}
+		e.emit(r)
+	}
+	e.ballots[room.Code] = len(room.VoteHistory)
+}
+
+func (e *Exporter) record(event string, room *models.GameRoom) Record {
+	return Record{
+		Time:  models.NewTimestamp(time.Now()),
+		Event: event,
+		Room:  string(room.Code),
+		Round: room.Round,
+		Phase: string(room.Phase),
+	}
+}
+
+// emit queues the record, dropping it rather than blocking when the sink is behind
+func (e *Exporter) emit(r Record) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		log.Printf("Analytics marshal error: %v", err)
+		return
+	}
+
+	e.queueMu.RLock()
+	defer e.queueMu.RUnlock()
+	if e.closed {
+		e.dropped.Add(1)
+		return
+	}
+
+	select {
+	case e.queue <- append(data, '\n'):
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// anonymize maps a player ID to a stable pseudonym for this process
+func (e *Exporter) anonymize(id string) string {
+	if e.rawIDs || id == "" {
+		return id
+	}
+	mac := hmac.New(sha256.New, e.salt)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
@@ -0,0 +1,229 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// memSink keeps written records in memory. With block set, each Write
+// reports on started, unless a report is already waiting, and then waits
+// for block to be closed.
+type memSink struct {
+	mu      sync.Mutex
+	records [][]byte
+	closed  bool
+
+	started chan struct{}
+	block   chan struct{}
+}
+
+func (s *memSink) Write(record []byte) error {
+	if s.block != nil {
+		select {
+		case s.started <- struct{}{}:
+		default:
+		}
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// decoded returns the records written so far
+func (s *memSink) decoded(t *testing.T) []Record {
+	t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]Record, len(s.records))
+	for i, line := range s.records {
+		if !bytes.HasSuffix(line, []byte("\n")) || bytes.Count(line, []byte("\n")) != 1 {
+			t.Fatalf("record %d is not one NDJSON line: %q", i, line)
+		}
+		if err := json.Unmarshal(line, &records[i]); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+	}
+	return records
+}
+
+// analyticsRoom is a room of two on its first night
+func analyticsRoom() (*models.GameRoom, *models.Player) {
+	player := &models.Player{ID: "player-1", Username: "Somchai", Role: models.RoleHunter, IsAlive: true}
+	room := &models.GameRoom{
+		Code:    "ABC123",
+		Round:   1,
+		Phase:   models.PhaseNight,
+		Players: map[string]*models.Player{player.ID: player, "player-2": {ID: "player-2", Username: "Malee", IsAlive: true}},
+	}
+	return room, player
+}
+
+func TestRecordShape(t *testing.T) {
+	sink := &memSink{}
+	e := NewExporter(sink, Config{})
+	room, player := analyticsRoom()
+
+	e.OnPlayerJoined(room, player)
+	player.IsAlive = false
+	player.DeathCause = "tiger"
+	e.OnPlayerDied(room, player)
+	if err := e.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	records := sink.decoded(t)
+	if len(records) != 2 || !sink.closed {
+		t.Fatalf("%d records, sink closed %v; want 2 and closed", len(records), sink.closed)
+	}
+	joined, died := records[0], records[1]
+	if joined.Event != "player_joined" || joined.Room != "ABC123" || joined.Round != 1 ||
+		joined.Phase != string(models.PhaseNight) || joined.Players != 2 || joined.Time.IsZero() {
+		t.Errorf("joined = %+v", joined)
+	}
+	if died.Event != "player_died" || died.Role != string(models.RoleHunter) || died.Cause != "tiger" || died.Player != joined.Player {
+		t.Errorf("died = %+v, want the hunter killed by the tiger, as the same pseudonym", died)
+	}
+
+	// Nothing that names a player leaves the process
+	for _, line := range sink.records {
+		if strings.Contains(string(line), "Somchai") || strings.Contains(string(line), "player-1") {
+			t.Errorf("record names the player: %s", line)
+		}
+	}
+}
+
+func TestIDsAreHashed(t *testing.T) {
+	e := NewExporter(&memSink{}, Config{})
+	defer e.Close()
+
+	first, again, other := e.anonymize("player-1"), e.anonymize("player-1"), e.anonymize("player-2")
+	if first != again || first == other || len(first) != 16 {
+		t.Errorf("pseudonyms %q, %q, %q; want 16 hex, stable per player and distinct", first, again, other)
+	}
+	if e.anonymize("") != "" {
+		t.Error("an absent ID got a pseudonym")
+	}
+
+	// Each process salts afresh, so pseudonyms do not link across restarts
+	restarted := NewExporter(&memSink{}, Config{})
+	defer restarted.Close()
+	if restarted.anonymize("player-1") == first {
+		t.Error("two exporters gave the same pseudonym")
+	}
+
+	raw := NewExporter(&memSink{}, Config{RawIDs: true})
+	defer raw.Close()
+	if raw.anonymize("player-1") != "player-1" {
+		t.Error("RawIDs still anonymized")
+	}
+}
+
+func TestEmitNeverBlocks(t *testing.T) {
+	sink := &memSink{started: make(chan struct{}, 1), block: make(chan struct{})}
+	e := NewExporter(sink, Config{Buffer: 2})
+	room, _ := analyticsRoom()
+
+	// The writer takes the first record and hangs in the sink
+	e.OnRoomCreated(room)
+	<-sink.started
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			e.OnRoomCreated(room)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("emitting blocked on a stalled sink")
+	}
+	if dropped := e.Dropped(); dropped != 8 {
+		t.Errorf("dropped %d, want all but the 2 the buffer holds", dropped)
+	}
+
+	close(sink.block)
+	e.Close()
+	if written := len(sink.decoded(t)); written != 3 {
+		t.Errorf("%d records written, want the first and the 2 buffered", written)
+	}
+}
+
+func TestEmitAfterClose(t *testing.T) {
+	e := NewExporter(&memSink{}, Config{})
+	room, _ := analyticsRoom()
+
+	// Observers may still fire while the server stops
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				e.OnRoomCreated(room)
+			}
+		}()
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	wg.Wait()
+
+	before := e.Dropped()
+	e.OnRoomCreated(room)
+	if e.Dropped() != before+1 {
+		t.Error("a record after Close was not counted as dropped")
+	}
+	if err := e.Close(); err != nil {
+		t.Errorf("closing twice: %v", err)
+	}
+}
+
+func TestBallotsExportedOnce(t *testing.T) {
+	sink := &memSink{}
+	e := NewExporter(sink, Config{})
+	room, hunter := analyticsRoom()
+	room.Phase = models.PhaseVoting
+	room.VoteHistory = []models.Ballot{{Round: 1, VoterID: "player-2", TargetID: hunter.ID, Outcome: "eliminated"}}
+
+	// Each ballot goes out with the first phase change after it is settled
+	e.OnPhaseChanged(room, models.PhaseVoting)
+	e.OnPhaseChanged(room, models.PhaseNight)
+	room.VoteHistory = append(room.VoteHistory, models.Ballot{Round: 2, VoterID: hunter.ID, TargetID: "player-2", Outcome: "survived"})
+	e.OnGameEnded(room, "village")
+	e.Close()
+
+	var ballots []Record
+	for _, record := range sink.decoded(t) {
+		if record.Event == "ballot" {
+			ballots = append(ballots, record)
+		}
+	}
+	if len(ballots) != 2 {
+		t.Fatalf("%d ballots exported, want each of the 2 once", len(ballots))
+	}
+	first, second := ballots[0], ballots[1]
+	if first.Round != 1 || first.Outcome != "eliminated" || first.TargetRole != string(models.RoleHunter) || first.Player == "" {
+		t.Errorf("first ballot = %+v", first)
+	}
+	if second.Round != 2 || second.Player != first.Target || second.Target != first.Player || second.Role != string(models.RoleHunter) {
+		t.Errorf("second ballot = %+v, want the hunter voting back", second)
+	}
+}
@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sink receives encoded NDJSON records, one per Write, from a single writer
+// goroutine. A message-queue sink only needs to implement this interface.
+type Sink interface {
+	Write(record []byte) error
+	Close() error
+}
+
+// FileSink appends records to a file and rotates it once it would grow past
+// MaxBytes. Rotated files keep the path with a nanosecond timestamp suffix.
+type FileSink struct {
+	Path     string
+	MaxBytes int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens (or creates) the file at path for appending
+func NewFileSink(path string, maxBytes int64) (*FileSink, error) {
+	sink := &FileSink{Path: path, MaxBytes: maxBytes}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *FileSink) Write(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.MaxBytes > 0 && s.size > 0 && s.size+int64(len(record)) > s.MaxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(record)
+	s.size += int64(n)
+	return err
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	rotated := fmt.Sprintf("%s.%d", s.Path, time.Now().UnixNano())
+	if err := os.Rename(s.Path, rotated); err != nil {
+		return err
+	}
+	return s.open()
+}
@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rotatedFiles returns the contents of every file rotated away from path
+func rotatedFiles(t *testing.T, path string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(data))
+	}
+	return contents
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analytics.ndjson")
+	sink, err := NewFileSink(path, 100)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	record := strings.Repeat("x", 39) + "\n"
+	for i := 0; i < 3; i++ {
+		if err := sink.Write([]byte(record)); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// The third record would have taken the file to 120 bytes
+	if rotated := rotatedFiles(t, path); len(rotated) != 1 || rotated[0] != record+record {
+		t.Errorf("rotated = %q, want one file of the first two records", rotated)
+	}
+	if current, _ := os.ReadFile(path); string(current) != record {
+		t.Errorf("current file = %q, want the third record", current)
+	}
+}
+
+func TestFileSinkCountsAnExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analytics.ndjson")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 89)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sink, err := NewFileSink(path, 100)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := sink.Write([]byte("0123456789abcdef\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	sink.Close()
+
+	if rotated := rotatedFiles(t, path); len(rotated) != 1 || len(rotated[0]) != 90 {
+		t.Errorf("rotated %d files, want the 90 bytes left by the last run", len(rotated))
+	}
+}
+
+func TestFileSinkWithoutLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analytics.ndjson")
+	sink, err := NewFileSink(path, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		sink.Write([]byte(strings.Repeat("x", 99) + "\n"))
+	}
+	sink.Close()
+
+	if rotated := rotatedFiles(t, path); len(rotated) != 0 {
+		t.Errorf("rotated %d files with no size limit", len(rotated))
+	}
+}
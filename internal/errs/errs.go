@@ -0,0 +1,86 @@
+// Package errs is the error model the engine and its frontends share. A
+// rule violation is an *Error: an English message plus, where clients need
+// one, a stable Code they can match on and translate. Errors may be wrapped
+// with context on the way out; frontends find the code again with
+// errors.As, and compare against sentinels with errors.Is.
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error is a rule violation. Code, when set, is a stable identifier clients
+// can match on instead of the message; Params carries its details. Err is
+// the underlying cause, if any.
+type Error struct {
+	Code    string
+	Params  map[string]interface{}
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same code, so a sentinel still matches after
+// being copied to carry params. Errors without a code only match themselves.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.Code != "" && e.Code == t.Code
+}
+
+// MessageKey is the translation key clients look the error up by, or ""
+// when the error has no code
+func (e *Error) MessageKey() string {
+	if e.Code == "" {
+		return ""
+	}
+	return MessageKey(e.Code)
+}
+
+// WithParams returns a copy of the error carrying params
+func (e *Error) WithParams(params map[string]interface{}) *Error {
+	copied := *e
+	copied.Params = params
+	return &copied
+}
+
+// MessageKey is the translation key for an error code
+func MessageKey(code string) string {
+	return "error." + strings.ToLower(code)
+}
+
+// Wrap adds context to err, e.g. "vote in room ABC123: player cannot vote".
+// The result still matches err with errors.Is and errors.As. A nil err stays nil.
+func Wrap(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf(format+": %w", append(args, err)...)
+}
+
+// Coded returns the first error in err's chain that has a code
+func Coded(err error) (*Error, bool) {
+	for err != nil {
+		var e *Error
+		if !errors.As(err, &e) {
+			return nil, false
+		}
+		if e.Code != "" {
+			return e, true
+		}
+		err = e.Err
+	}
+	return nil, false
+}
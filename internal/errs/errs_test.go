@@ -0,0 +1,85 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var (
+	errFull   = &Error{Code: "ROOM_FULL", Message: "room is full"}
+	errClosed = &Error{Code: "ROOM_CLOSED", Message: "room is closed"}
+)
+
+func TestWrapChainMatches(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{"bare", errFull},
+		{"wrapped", Wrap(errFull, "join room %s", "ABC123")},
+		{"wrapped twice", Wrap(Wrap(errFull, "join room %s", "ABC123"), "invite")},
+		{"wrapped by fmt", fmt.Errorf("lookup: %w", errFull)},
+		{"copied with params", errFull.WithParams(map[string]interface{}{"max": 8})},
+		{"as a cause", &Error{Message: "could not seat the player", Err: errFull}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if !errors.Is(tc.err, errFull) {
+				t.Errorf("%v does not match the sentinel", tc.err)
+			}
+			if errors.Is(tc.err, errClosed) {
+				t.Errorf("%v matches another code", tc.err)
+			}
+			var e *Error
+			if !errors.As(tc.err, &e) {
+				t.Fatalf("%v holds no *Error", tc.err)
+			}
+			coded, ok := Coded(tc.err)
+			if !ok || coded.Code != "ROOM_FULL" || coded.MessageKey() != "error.room_full" {
+				t.Errorf("Coded(%v) = %v, %v", tc.err, coded, ok)
+			}
+		})
+	}
+}
+
+func TestWrapMessage(t *testing.T) {
+	err := Wrap(errFull, "join room %s", "ABC123")
+	if err.Error() != "join room ABC123: room is full" {
+		t.Errorf("message = %q", err)
+	}
+	if Wrap(nil, "join room %s", "ABC123") != nil {
+		t.Error("wrapping nil made an error")
+	}
+
+	caused := &Error{Message: "could not seat the player", Err: errFull}
+	if caused.Error() != "could not seat the player: room is full" || errors.Unwrap(caused) != errFull {
+		t.Errorf("caused = %q, unwraps to %v", caused, errors.Unwrap(caused))
+	}
+}
+
+func TestUncodedErrors(t *testing.T) {
+	a := &Error{Message: "something broke"}
+	b := &Error{Message: "something broke"}
+	if !errors.Is(a, a) || errors.Is(a, b) {
+		t.Error("an error without a code matched something other than itself")
+	}
+	if a.MessageKey() != "" {
+		t.Errorf("message key = %q", a.MessageKey())
+	}
+	if coded, ok := Coded(Wrap(a, "context")); ok {
+		t.Errorf("Coded found %v", coded)
+	}
+	if _, ok := Coded(errors.New("plain")); ok {
+		t.Error("Coded found a code in a plain error")
+	}
+}
+
+func TestWithParamsCopies(t *testing.T) {
+	copied := errFull.WithParams(map[string]interface{}{"max": 8})
+	if copied == errFull || errFull.Params != nil {
+		t.Error("WithParams changed the sentinel")
+	}
+	if copied.Params["max"] != 8 || copied.Message != errFull.Message {
+		t.Errorf("copy = %+v", copied)
+	}
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// abilityOf is the player's own view of one of their abilities, or nil
+// when the view lists no such ability
+func abilityOf(gm *GameManager, code models.RoomCode, playerID, name string) *models.AbilityStatus {
+	view, _ := gm.RoomView(code, playerID)
+	for _, status := range view.Players[playerID].AbilityStatus {
+		if status.Name == name {
+			return &status
+		}
+	}
+	return nil
+}
+
+// wantAbility checks the player's view of a once-per-game ability
+func wantAbility(t *testing.T, gm *GameManager, code models.RoomCode, playerID, name string, used int, usable bool) {
+	t.Helper()
+
+	status := abilityOf(gm, code, playerID, name)
+	if status == nil {
+		t.Fatalf("%s's view lists no %s", playerID, name)
+	}
+	remaining := -1
+	if status.Remaining != nil {
+		remaining = *status.Remaining
+	}
+	if status.Used != used || remaining != 1-used || status.Usable != usable {
+		t.Errorf("%s's %s: used %d, remaining %d, usable %v; want %d, %d, %v",
+			playerID, name, status.Used, remaining, status.Usable, used, 1-used, usable)
+	}
+}
+
+func TestAlphaTigerCurseCounter(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleAlphaTiger, "p2": models.RoleHunter, "p3": models.RoleShaman})
+
+	// Unused, and not usable by day
+	wantAbility(t, gm, code, "p1", models.AbilityCurse, 0, false)
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+	prompt := turnOf(t, gm, code, "p1")
+	wantAbility(t, gm, code, "p1", models.AbilityCurse, 0, true)
+
+	if err := gm.SetAlphaTigerCurse(code, "p1", "p4", prompt.TurnToken); err != nil {
+		t.Fatalf("curse: %v", err)
+	}
+	wantAbility(t, gm, code, "p1", models.AbilityCurse, 1, false)
+}
+
+func TestHunterShotCounter(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleHunter, "p3": models.RoleShaman})
+	wantAbility(t, gm, code, "p2", models.AbilityShoot, 0, false)
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+	playNight(t, gm, code, map[string]string{"p1": "p2", "p2": "p4"})
+
+	// Dead and waiting to shoot is the one time the shot is usable
+	wantAbility(t, gm, code, "p2", models.AbilityShoot, 0, true)
+	if _, err := gm.HunterShoot(code, "p2", "p5", true); err != nil {
+		t.Fatalf("shoot: %v", err)
+	}
+	wantAbility(t, gm, code, "p2", models.AbilityShoot, 1, false)
+}
+
+func TestAbilitiesBlockIsPrivate(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleAlphaTiger, "p2": models.RoleHunter, "p3": models.RoleShaman})
+
+	// Roles without a limited ability have nothing to count
+	for _, id := range []string{"p3", "p4"} {
+		view, _ := gm.RoomView(code, id)
+		if statuses := view.Players[id].AbilityStatus; len(statuses) != 0 {
+			t.Errorf("%s's view lists %+v", id, statuses)
+		}
+	}
+	// Nobody sees another player's block, not even a teammate's
+	for _, viewer := range []string{"", "p2", "p4"} {
+		view, _ := gm.RoomView(code, viewer)
+		if statuses := view.Players["p1"].AbilityStatus; statuses != nil {
+			t.Errorf("viewer %q sees the alpha tiger's abilities: %+v", viewer, statuses)
+		}
+	}
+}
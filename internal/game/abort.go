@@ -0,0 +1,217 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// abortVoteDuration is how long players have to answer an abort request
+const abortVoteDuration = 30 * time.Second
+
+// Where an abort vote stands
+const (
+	AbortPending  = "pending"
+	AbortApproved = "aborted"
+	AbortRejected = "rejected"
+)
+
+// ErrGamePaused is returned for clock-driven changes while the game is
+// paused for an abort vote or a lost quorum
+var ErrGamePaused = &errs.Error{Code: "GAME_PAUSED", Message: "the game is paused"}
+
+var (
+	ErrNoGameInProgress     = &errs.Error{Code: "NO_GAME_IN_PROGRESS", Message: "no game in progress"}
+	ErrAbortVoteOpen        = &errs.Error{Code: "ABORT_VOTE_OPEN", Message: "an abort vote is already open"}
+	ErrNoAbortVote          = &errs.Error{Code: "NO_ABORT_VOTE", Message: "no abort vote is open"}
+	ErrAbortVoteNotEligible = &errs.Error{Code: "ABORT_VOTE_NOT_ELIGIBLE", Message: "you cannot vote on this abort"}
+	ErrAbortAlreadyAnswered = &errs.Error{Code: "ABORT_ALREADY_ANSWERED", Message: "you have already answered"}
+	ErrAbortVoteNotTimedOut = &errs.Error{Code: "ABORT_VOTE_NOT_TIMED_OUT", Message: "abort vote has not timed out yet"}
+)
+
+// RequestAbort opens a consent vote on abandoning the game and reports
+// where it stands. The host's request counts as their approval. The phase
+// clocks stop until it closes.
+func (gm *GameManager) RequestAbort(code models.RoomCode, hostID string) (AbortPrompt, string, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return AbortPrompt{}, "", gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, hostID, PermAbortGame); err != nil {
+		return AbortPrompt{}, "", err
+	}
+
+	if room.Phase == models.PhaseWaiting || room.Phase == models.PhaseEnded {
+		return AbortPrompt{}, "", ErrNoGameInProgress
+	}
+
+	if room.AbortVote != nil {
+		return AbortPrompt{}, "", ErrAbortVoteOpen
+	}
+
+	now := time.Now()
+	vote := &models.AbortVote{
+		RequestedBy: hostID,
+		EndsAt:      models.NewTimestamp(now.Add(abortVoteDuration)),
+		StartedAt:   now,
+		Votes:       make(map[string]bool),
+	}
+	for _, player := range room.LivingConnected() {
+		if !player.IsBot {
+			vote.Eligible = append(vote.Eligible, player.ID)
+		}
+	}
+	vote.Needed = abortApprovalsNeeded(room.Settings.AbortApprovals, len(vote.Eligible))
+	room.AbortVote = vote
+	issuePromptLocked(room, vote.Eligible, models.PendingPrompt{
+		Type:     models.EventAbortRequested,
+		Deadline: &vote.EndsAt,
+	})
+
+	gm.recordHostActionLocked(room, hostID, HostActionAbortGame, "", "")
+	if containsID(vote.Eligible, hostID) {
+		vote.Votes[hostID] = true
+		vote.Approvals++
+	}
+
+	prompt := abortPromptLocked(room, now)
+	return prompt, abortOutcome(vote, false), gm.settleAbortLocked(room, false)
+}
+
+// abortPromptLocked shows the open abort vote without anyone's answer
+func abortPromptLocked(room *models.GameRoom, now time.Time) AbortPrompt {
+	opened := *room.AbortVote
+	opened.Eligible = append([]string(nil), opened.Eligible...)
+	opened.Votes = nil
+	return AbortPrompt{
+		AbortVote:      opened,
+		PromptDeadline: promptDeadlineLocked(room, models.EventAbortRequested, now),
+	}
+}
+
+// VoteAbort records an eligible player's answer to the abort request and
+// reports whether the vote is still open, approved or rejected
+func (gm *GameManager) VoteAbort(code models.RoomCode, playerID string, approve bool) (string, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return "", gm.missingRoomLocked(code)
+	}
+
+	vote := room.AbortVote
+	if vote == nil {
+		return "", ErrNoAbortVote
+	}
+
+	if !containsID(vote.Eligible, playerID) {
+		return "", ErrAbortVoteNotEligible
+	}
+
+	if _, voted := vote.Votes[playerID]; voted {
+		return "", ErrAbortAlreadyAnswered
+	}
+
+	vote.Votes[playerID] = approve
+	answerPromptLocked(room, playerID, models.EventAbortRequested)
+	if approve {
+		vote.Approvals++
+	} else {
+		vote.Rejections++
+	}
+	room.LastActivityAt = time.Now()
+
+	return abortOutcome(vote, false), gm.settleAbortLocked(room, false)
+}
+
+// TimeoutAbort closes the abort vote once its deadline has passed; players
+// who never answered count as rejecting
+func (gm *GameManager) TimeoutAbort(code models.RoomCode) (string, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return "", gm.missingRoomLocked(code)
+	}
+
+	vote := room.AbortVote
+	if vote == nil {
+		return "", ErrNoAbortVote
+	}
+
+	if time.Now().Before(vote.EndsAt.Time) {
+		return "", ErrAbortVoteNotTimedOut
+	}
+
+	return abortOutcome(vote, true), gm.settleAbortLocked(room, true)
+}
+
+// abortApprovalsNeeded applies the room's threshold, defaulting to all but one
+func abortApprovalsNeeded(setting, eligible int) int {
+	needed := eligible - 1
+	if setting > 0 {
+		needed = setting
+	}
+	if needed > eligible {
+		needed = eligible
+	}
+	if needed < 1 {
+		needed = 1
+	}
+	return needed
+}
+
+// abortOutcome decides the vote: approved once enough players agreed,
+// rejected once that can no longer happen
+func abortOutcome(vote *models.AbortVote, closed bool) string {
+	if vote.Approvals >= vote.Needed {
+		return AbortApproved
+	}
+	undecided := len(vote.Eligible) - vote.Approvals - vote.Rejections
+	if closed || vote.Approvals+undecided < vote.Needed {
+		return AbortRejected
+	}
+	return AbortPending
+}
+
+// settleAbortLocked acts on a decided vote: an approved abort ends the game
+// with no winner and reopens the lobby; a rejected one restarts the clocks
+// where they stopped
+func (gm *GameManager) settleAbortLocked(room *models.GameRoom, closed bool) error {
+	vote := room.AbortVote
+	switch abortOutcome(vote, closed) {
+	case AbortApproved:
+		room.WinningTeam = ""
+		if err := gm.transitionLocked(room, models.PhaseEnded, ReasonAborted); err != nil {
+			return err
+		}
+		return gm.transitionLocked(room, models.PhaseWaiting, ReasonAborted)
+
+	case AbortRejected:
+		room.AbortVote = nil
+		shiftClocksLocked(room, time.Since(vote.StartedAt))
+	}
+	return nil
+}
+
+// pausedLocked reports whether the game's clocks are stopped
+func pausedLocked(room *models.GameRoom) bool {
+	return room.AbortVote != nil || room.QuorumPause != nil
+}
+
+// shiftClocksLocked moves the room's deadlines forward by the time it spent paused
+func shiftClocksLocked(room *models.GameRoom, paused time.Duration) {
+	for _, t := range []*models.Timestamp{room.PhaseEndTime, room.TurnEndTime, room.NightCeilingAt, room.PhaseStartedAt, room.HunterShotEndsAt} {
+		if t != nil {
+			t.Time = t.Add(paused)
+		}
+	}
+	shiftPromptsLocked(room, paused)
+}
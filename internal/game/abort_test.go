@@ -0,0 +1,157 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// abortGame is a six-player game in its first day with everyone connected
+func abortGame(t *testing.T, observers ...RoomObserver) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager(observers...)
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger, "p3": models.RoleHunter})
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+		gm.SetConnected(code, id, true)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.PhaseEndTime = models.TimestampPtr(time.Now().Add(time.Minute))
+	})
+	return gm, code
+}
+
+func TestAbortApproved(t *testing.T) {
+	recorder := &recordingObserver{}
+	gm, code := abortGame(t, recorder)
+
+	prompt, outcome, err := gm.RequestAbort(code, "p1")
+	if err != nil {
+		t.Fatalf("request abort: %v", err)
+	}
+	if outcome != AbortPending || prompt.Needed != 5 || len(prompt.Eligible) != 6 {
+		t.Fatalf("outcome %s, needs %d of %d; want pending, 5 of 6", outcome, prompt.Needed, len(prompt.Eligible))
+	}
+	if _, err := gm.VoteAbort(code, "p1", true); err == nil {
+		t.Error("the host answered twice")
+	}
+
+	for _, id := range []string{"p2", "p3", "p4"} {
+		if outcome, err := gm.VoteAbort(code, id, true); err != nil || outcome != AbortPending {
+			t.Fatalf("%s approves: outcome %s, err %v", id, outcome, err)
+		}
+	}
+	if outcome, err := gm.VoteAbort(code, "p5", true); err != nil || outcome != AbortApproved {
+		t.Fatalf("fifth approval: outcome %s, err %v", outcome, err)
+	}
+
+	if got := recorder.events[len(recorder.events)-2:]; !reflect.DeepEqual(got, []string{"ended: ", "ended -> waiting"}) {
+		t.Errorf("observer saw %v, want an ending with no winner and a return to the lobby", got)
+	}
+}
+
+func TestAbortLeavesCleanLobby(t *testing.T) {
+	gm, code := abortGame(t)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.SetAlive(room.Players["p6"], false)
+		room.Players["p6"].DeathCause = models.DeathByVote
+	})
+
+	if _, _, err := gm.RequestAbort(code, "p1"); err != nil {
+		t.Fatalf("request abort: %v", err)
+	}
+	// The dead player has no say, so four of the five living agreeing is enough
+	for _, id := range []string{"p2", "p3", "p4"} {
+		if _, err := gm.VoteAbort(code, id, true); err != nil {
+			t.Fatalf("%s approves: %v", id, err)
+		}
+	}
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.Phase != models.PhaseWaiting || room.PhaseReason != ReasonAborted {
+			t.Errorf("phase %s (%s), want an aborted return to the lobby", room.Phase, room.PhaseReason)
+		}
+		if room.WinningTeam != "" || room.AbortVote != nil || room.Round != 0 {
+			t.Errorf("winner %q, vote %v, round %d left behind", room.WinningTeam, room.AbortVote, room.Round)
+		}
+		for id, player := range room.Players {
+			if !player.IsAlive || player.Role != "" || player.DeathCause != "" {
+				t.Errorf("%s: alive %v, role %q, cause %q", id, player.IsAlive, player.Role, player.DeathCause)
+			}
+		}
+		if len(room.PendingPrompts) != 0 {
+			t.Errorf("prompts left open: %v", room.PendingPrompts)
+		}
+	})
+}
+
+func TestAbortRejectedResumesClocks(t *testing.T) {
+	gm, code := abortGame(t)
+	var deadline time.Time
+	withRoom(t, gm, code, func(room *models.GameRoom) { deadline = room.PhaseEndTime.Time })
+
+	if _, _, err := gm.RequestAbort(code, "p1"); err != nil {
+		t.Fatalf("request abort: %v", err)
+	}
+	if _, err := gm.VoteAbort(code, "p2", false); err != nil {
+		t.Fatalf("p2 rejects: %v", err)
+	}
+	// One rejection is allowed; a second makes five approvals impossible
+	if outcome, err := gm.VoteAbort(code, "p3", false); err != nil || outcome != AbortRejected {
+		t.Fatalf("second rejection: outcome %s, err %v", outcome, err)
+	}
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.Phase != models.PhaseDay || room.AbortVote != nil {
+			t.Errorf("phase %s, vote %v; want the day to go on", room.Phase, room.AbortVote)
+		}
+		if !room.PhaseEndTime.After(deadline) {
+			t.Error("the day's deadline did not move for the time spent paused")
+		}
+	})
+}
+
+func TestAbortTimeoutCountsAsRejection(t *testing.T) {
+	gm, code := abortGame(t)
+
+	if _, _, err := gm.RequestAbort(code, "p1"); err != nil {
+		t.Fatalf("request abort: %v", err)
+	}
+	for _, id := range []string{"p2", "p3", "p4"} {
+		if _, err := gm.VoteAbort(code, id, true); err != nil {
+			t.Fatalf("%s approves: %v", id, err)
+		}
+	}
+	if _, err := gm.TimeoutAbort(code); err == nil {
+		t.Fatal("the vote timed out before its deadline")
+	}
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.AbortVote.EndsAt = models.NewTimestamp(time.Now().Add(-time.Second))
+	})
+	if outcome, err := gm.TimeoutAbort(code); err != nil || outcome != AbortRejected {
+		t.Fatalf("timeout: outcome %s, err %v; want the silent players to reject", outcome, err)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Errorf("phase = %s after a timed-out abort", phase)
+	}
+}
+
+func TestAbortApprovalsNeeded(t *testing.T) {
+	tests := []struct {
+		setting, eligible, want int
+	}{
+		{setting: 0, eligible: 6, want: 5},
+		{setting: 0, eligible: 1, want: 1},
+		{setting: 3, eligible: 6, want: 3},
+		{setting: 9, eligible: 6, want: 6},
+	}
+	for _, tt := range tests {
+		if got := abortApprovalsNeeded(tt.setting, tt.eligible); got != tt.want {
+			t.Errorf("abortApprovalsNeeded(%d, %d) = %d, want %d", tt.setting, tt.eligible, got, tt.want)
+		}
+	}
+}
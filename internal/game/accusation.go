@@ -0,0 +1,108 @@
+package game
+
+import (
+	"sort"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+var (
+	ErrAccusationsDisabled  = &errs.Error{Code: "ACCUSATIONS_DISABLED", Message: "formal accusations are disabled"}
+	ErrNominationOutsideDay = &errs.Error{Code: "NOMINATION_OUTSIDE_DAY", Message: "nominations are only allowed during the day"}
+	ErrCannotNominate       = &errs.Error{Code: "PLAYER_CANNOT_NOMINATE", Message: "player cannot nominate"}
+	ErrInvalidNominee       = &errs.Error{Code: "INVALID_NOMINEE", Message: "invalid nominee"}
+)
+
+// Nominate records a player's accusation during a formal-accusations day.
+// Each player holds at most one nomination; nominating someone else replaces it.
+func (gm *GameManager) Nominate(code models.RoomCode, playerID, nomineeID string) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if !room.Settings.FormalAccusations {
+		return ErrAccusationsDisabled
+	}
+
+	if room.Phase != models.PhaseDay {
+		return ErrNominationOutsideDay
+	}
+
+	player := room.Players[playerID]
+	if player == nil || !player.IsAlive {
+		return ErrCannotNominate
+	}
+
+	nominee := room.Players[nomineeID]
+	if nominee == nil || !nominee.IsAlive || nomineeID == playerID {
+		return ErrInvalidNominee
+	}
+
+	for i, n := range room.Nominations {
+		if n.NominatorID != playerID {
+			continue
+		}
+		if n.NomineeID == nomineeID {
+			return nil
+		}
+		room.Nominations = append(room.Nominations[:i], room.Nominations[i+1:]...)
+		break
+	}
+
+	room.Nominations = append(room.Nominations, models.Nomination{
+		NominatorID: playerID,
+		NomineeID:   nomineeID,
+		At:          models.NewTimestamp(time.Now()),
+	})
+
+	return nil
+}
+
+// topNomineesLocked returns the two most-nominated living players.
+// Ties go to whoever was nominated first.
+func topNomineesLocked(room *models.GameRoom) []string {
+	counts := make(map[string]int)
+	first := make(map[string]time.Time)
+	for _, n := range room.Nominations {
+		nominee := room.Players[n.NomineeID]
+		if nominee == nil || !nominee.IsAlive {
+			continue
+		}
+		counts[n.NomineeID]++
+		if at, ok := first[n.NomineeID]; !ok || n.At.Before(at) {
+			first[n.NomineeID] = n.At.Time
+		}
+	}
+
+	nominees := make([]string, 0, len(counts))
+	for id := range counts {
+		nominees = append(nominees, id)
+	}
+	sort.Slice(nominees, func(i, j int) bool {
+		a, b := nominees[i], nominees[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return first[a].Before(first[b])
+	})
+
+	if len(nominees) > 2 {
+		nominees = nominees[:2]
+	}
+	return nominees
+}
+
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
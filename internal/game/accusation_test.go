@@ -0,0 +1,167 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// accusationGame starts a seven-player formal-accusations game in its first day
+func accusationGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 7, func(settings *models.RoomSettings) {
+		settings.FormalAccusations = true
+	})
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+	return gm, code
+}
+
+// nominate records each "nominator nominee" pair a second apart, so ties
+// break by the order they are listed in
+func nominate(t *testing.T, gm *GameManager, code models.RoomCode, pairs ...[2]string) {
+	t.Helper()
+
+	for _, pair := range pairs {
+		if err := gm.Nominate(code, pair[0], pair[1]); err != nil {
+			t.Fatalf("%s nominates %s: %v", pair[0], pair[1], err)
+		}
+	}
+	start := time.Now()
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for i := range room.Nominations {
+			room.Nominations[i].At = models.NewTimestamp(start.Add(time.Duration(i) * time.Second))
+		}
+	})
+}
+
+func TestNominationsPickTopTwo(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs [][2]string
+		want  []string
+	}{
+		{
+			name:  "most nominated first",
+			pairs: [][2]string{{"p2", "p5"}, {"p3", "p6"}, {"p4", "p6"}, {"p5", "p7"}},
+			want:  []string{"p6", "p5"},
+		},
+		{
+			name:  "ties go to the earliest nomination",
+			pairs: [][2]string{{"p2", "p7"}, {"p3", "p5"}, {"p4", "p5"}, {"p6", "p7"}, {"p1", "p4"}},
+			want:  []string{"p7", "p5"},
+		},
+		{
+			name:  "a changed nomination replaces the old one",
+			pairs: [][2]string{{"p2", "p5"}, {"p3", "p5"}, {"p2", "p6"}, {"p4", "p7"}},
+			want:  []string{"p5", "p6"},
+		},
+		{
+			name:  "a single nominee stands alone",
+			pairs: [][2]string{{"p2", "p5"}, {"p3", "p5"}},
+			want:  []string{"p5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, code := accusationGame(t)
+			nominate(t, gm, code, tt.pairs...)
+
+			nextPhase(t, gm, code)
+			if phase := phaseOf(t, gm, code); phase != models.PhaseDefense {
+				t.Fatalf("day ended in %s, want defense", phase)
+			}
+			view, _ := gm.RoomView(code, "")
+			if !reflect.DeepEqual(view.Nominees, tt.want) {
+				t.Errorf("nominees = %v, want %v", view.Nominees, tt.want)
+			}
+		})
+	}
+}
+
+func TestNominationRules(t *testing.T) {
+	gm, code := accusationGame(t)
+
+	if err := gm.Nominate(code, "p2", "p2"); err == nil {
+		t.Error("a player nominated themselves")
+	}
+	if err := gm.Nominate(code, "p2", "nobody"); err == nil {
+		t.Error("a stranger was nominated")
+	}
+
+	nextPhase(t, gm, code)
+	if phase := phaseOf(t, gm, code); phase != models.PhaseVoting {
+		t.Errorf("a day without nominations ended in %s, want voting", phase)
+	}
+	if err := gm.Nominate(code, "p2", "p3"); err == nil {
+		t.Error("a nomination was taken during the vote")
+	}
+}
+
+func TestOnlyNomineesSpeakDuringDefense(t *testing.T) {
+	gm, code := accusationGame(t)
+	nominate(t, gm, code, [2]string{"p2", "p5"}, [2]string{"p3", "p6"})
+	nextPhase(t, gm, code)
+
+	for _, id := range []string{"p5", "p6"} {
+		if _, err := gm.PostChat(code, id, "it wasn't me"); err != nil {
+			t.Errorf("nominee %s could not speak: %v", id, err)
+		}
+	}
+	for _, id := range []string{"p1", "p2", "p7"} {
+		if _, err := gm.PostChat(code, id, "guilty"); err == nil {
+			t.Errorf("%s spoke during the defense", id)
+		}
+	}
+
+	nextPhase(t, gm, code)
+	if _, err := gm.PostChat(code, "p7", "now I can talk"); err != nil {
+		t.Errorf("chat stayed gated after the defense: %v", err)
+	}
+}
+
+func TestBallotIsRestrictedToNominees(t *testing.T) {
+	gm, code := accusationGame(t)
+	nominate(t, gm, code, [2]string{"p2", "p5"}, [2]string{"p3", "p5"}, [2]string{"p4", "p6"})
+	nextPhase(t, gm, code)
+	nextPhase(t, gm, code)
+
+	if err := gm.Vote(code, "p1", "p7"); err == nil {
+		t.Error("a vote for someone who was not nominated was taken")
+	}
+	for _, ballot := range []struct{ voter, target string }{
+		{"p1", "p5"}, {"p2", "p5"}, {"p3", "p6"}, {"p4", models.VoteAbstain}, {"p5", "p6"}, {"p6", "p5"}, {"p7", "p5"},
+	} {
+		if err := gm.Vote(code, ballot.voter, ballot.target); err != nil {
+			t.Fatalf("%s votes for %s: %v", ballot.voter, ballot.target, err)
+		}
+	}
+	nextPhase(t, gm, code)
+
+	view, _ := gm.RoomView(code, "")
+	if view.Players["p5"].IsAlive {
+		t.Error("the nominee with most votes survived")
+	}
+	if len(view.Nominees) != 0 {
+		t.Errorf("nominees %v outlived the vote", view.Nominees)
+	}
+}
+
+func TestAbstainNeedsNominees(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 5, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	nextPhase(t, gm, code)
+
+	if err := gm.Vote(code, "p2", models.VoteAbstain); err == nil {
+		t.Error("abstained without a restricted ballot")
+	}
+}
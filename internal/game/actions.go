@@ -0,0 +1,328 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// ErrTargetDead is returned for a night action aimed at a dead player
+var ErrTargetDead = &errs.Error{Code: "TARGET_DEAD", Message: "target is dead"}
+
+// ErrKillRequired is returned when the tiger team tries to pass in a room
+// with TigersMustKill on
+var ErrKillRequired = &errs.Error{Code: "KILL_REQUIRED", Message: "the tigers must attack someone tonight"}
+
+// ErrOnCooldown is returned for a night ability that is still resting. Its
+// params name the ability and the nights remaining, which is
+// models.CooldownForever for a once-per-game ability already spent.
+var ErrOnCooldown = &errs.Error{Code: "ON_COOLDOWN", Message: "this ability is not ready yet"}
+
+var (
+	ErrInvalidActionTarget = &errs.Error{Code: "INVALID_ACTION_TARGET", Message: "invalid action target"}
+	ErrProtectedTwice      = &errs.Error{Code: "PROTECTED_TWICE", Message: "cannot protect same player twice in a row"}
+	ErrNoNightAction       = &errs.Error{Code: "NO_NIGHT_ACTION", Message: "role has no night action"}
+	ErrCannotAct           = &errs.Error{Code: "PLAYER_CANNOT_ACT", Message: "player cannot act"}
+	ErrNotYourTurn         = &errs.Error{Code: "NOT_YOUR_TURN", Message: "not your turn"}
+)
+
+// PerformNightAction records a player's night action for their role's turn.
+// turnToken must match the open turn (see TurnPrompt).
+func (gm *GameManager) PerformNightAction(code models.RoomCode, playerID, targetID, turnToken string) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if player := room.Players[playerID]; player != nil {
+		if err := duplicateActionLocked(room, player, nightActionOnLocked(room, player, targetID), targetID); err != nil {
+			return err
+		}
+	}
+
+	player, err := nightActorLocked(room, playerID, turnToken)
+	if err != nil {
+		return err
+	}
+
+	target := room.Players[targetID]
+	if target == nil {
+		return ErrInvalidActionTarget
+	}
+
+	actionType := nightActionOnLocked(room, player, targetID)
+	if !target.IsAlive && actionType != models.ActionInspect {
+		return ErrTargetDead
+	}
+
+	if isTigerTeam(player.Role) {
+		return submitTigerDecisionLocked(room, player, actionType, target)
+	}
+
+	ability := nightAbility(player.Role)
+	if err := abilityCooldownLocked(room, player, ability); err != nil {
+		return err
+	}
+
+	switch actionType {
+	case models.ActionVision, models.ActionInspect:
+		room.ShamanVision = targetID
+	case models.ActionProtect:
+		// ห้ามกันคนเดิม 2 คืนซ้อน
+		if player.LastProtected == targetID {
+			return ErrProtectedTwice
+		}
+		room.HunterProtection = targetID
+		player.LastProtected = targetID
+	case models.ActionKill:
+		room.TigerTarget = targetID
+	default:
+		return ErrNoNightAction
+	}
+
+	player.UseAbility(ability, room.NightNumber)
+	recordActionLocked(room, player, actionType, target)
+	markNightActionCompleteLocked(room, player)
+	room.TurnToken = "" // the turn is used up; only the handoff can follow
+
+	return nil
+}
+
+// abilityCooldownLocked rejects using an ability the player holds while it
+// is still resting tonight. Abilities the player does not hold are
+// unlimited.
+func abilityCooldownLocked(room *models.GameRoom, player *models.Player, name string) error {
+	ability := player.Abilities[name]
+	if ability == nil {
+		return nil
+	}
+	wait := ability.NightsUntilReady(room.NightNumber)
+	if wait == 0 {
+		return nil
+	}
+	return ErrOnCooldown.WithParams(map[string]interface{}{"ability": name, "nightsRemaining": wait})
+}
+
+// SkipNightAction lets the acting player pass on their night ability
+func (gm *GameManager) SkipNightAction(code models.RoomCode, playerID, turnToken string) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if player := room.Players[playerID]; player != nil {
+		if err := duplicateActionLocked(room, player, models.ActionSkip, ""); err != nil {
+			return err
+		}
+	}
+
+	player, err := nightActorLocked(room, playerID, turnToken)
+	if err != nil {
+		return err
+	}
+
+	if isTigerTeam(player.Role) {
+		return submitTigerDecisionLocked(room, player, models.ActionSkip, nil)
+	}
+
+	recordActionLocked(room, player, models.ActionSkip, nil)
+	markNightActionCompleteLocked(room, player)
+	room.TurnToken = ""
+
+	return nil
+}
+
+// PassNightAction records that the player chose to do nothing with their
+// turn. Unlike a skip it is a decision: it is kept apart in the action
+// history and recaps, and a hunter who passes may protect anyone next night.
+// The tiger team may not pass when the room's TigersMustKill is on. It
+// returns the record to acknowledge the pass with.
+func (gm *GameManager) PassNightAction(code models.RoomCode, playerID, turnToken string) (models.ActionRecord, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return models.ActionRecord{}, gm.missingRoomLocked(code)
+	}
+
+	if player := room.Players[playerID]; player != nil {
+		if err := duplicateActionLocked(room, player, models.ActionPass, ""); err != nil {
+			return models.ActionRecord{}, err
+		}
+	}
+
+	player, err := nightActorLocked(room, playerID, turnToken)
+	if err != nil {
+		return models.ActionRecord{}, err
+	}
+
+	record := models.ActionRecord{Round: room.Round, Phase: room.Phase, ActionType: models.ActionPass}
+
+	switch {
+	case isTigerTeam(player.Role):
+		if room.Settings.TigersMustKill {
+			return models.ActionRecord{}, ErrKillRequired
+		}
+		return record, submitTigerDecisionLocked(room, player, models.ActionPass, nil)
+
+	case nightActionType(player.Role) == "":
+		return models.ActionRecord{}, ErrNoNightAction
+
+	case player.Role == models.RoleHunter:
+		// Nobody was protected, so nobody is off limits tomorrow
+		player.LastProtected = ""
+	}
+
+	recordActionLocked(room, player, models.ActionPass, nil)
+	markNightActionCompleteLocked(room, player)
+	room.TurnToken = ""
+
+	return record, nil
+}
+
+// nightActionType returns the action a role performs at night
+func nightActionType(role models.Role) string {
+	switch role {
+	case models.RoleShaman:
+		return models.ActionVision
+	case models.RoleHunter:
+		return models.ActionProtect
+	case models.RoleTiger, models.RoleAlphaTiger:
+		return models.ActionKill
+	}
+	return ""
+}
+
+// nightAbility names the night ability a room may put on a cooldown for
+// the role. The tiger team's kill is decided together and has none.
+func nightAbility(role models.Role) string {
+	switch role {
+	case models.RoleShaman:
+		return models.AbilityVision
+	case models.RoleHunter:
+		return models.AbilityProtect
+	}
+	return ""
+}
+
+// nightActionOnLocked returns the action the player performs on the target.
+// With ShamanCanInspectDead on, the shaman's vision of a dead player is an
+// inspection, kept apart from visions in the action history.
+func nightActionOnLocked(room *models.GameRoom, player *models.Player, targetID string) string {
+	actionType := nightActionType(player.Role)
+	if actionType != models.ActionVision || !room.Settings.ShamanCanInspectDead {
+		return actionType
+	}
+	if target := room.Players[targetID]; target != nil && !target.IsAlive {
+		return models.ActionInspect
+	}
+	return actionType
+}
+
+// DuplicateActionError is returned when a player resends an action that was
+// already accepted. It carries the original record so the client can be acked.
+type DuplicateActionError struct {
+	Record models.ActionRecord
+}
+
+func (e *DuplicateActionError) Error() string {
+	return "duplicate action"
+}
+
+// duplicateActionLocked reports a DuplicateActionError if the player's last
+// accepted action in this round and phase was the same action on the same target
+func duplicateActionLocked(room *models.GameRoom, player *models.Player, actionType, targetID string) error {
+	if len(player.ActionHistory) == 0 {
+		return nil
+	}
+
+	last := player.ActionHistory[len(player.ActionHistory)-1]
+	if last.Round == room.Round && last.Phase == room.Phase &&
+		last.ActionType == actionType && last.TargetID == targetID {
+		return &DuplicateActionError{Record: last}
+	}
+
+	return nil
+}
+
+// nightActorLocked returns the player if it is currently their turn to act
+// and turnToken still identifies that turn
+func nightActorLocked(room *models.GameRoom, playerID, turnToken string) (*models.Player, error) {
+	if room.Phase != models.PhaseNight {
+		return nil, ErrNotNightPhase
+	}
+
+	player := room.Players[playerID]
+	if player == nil || !player.IsAlive {
+		return nil, ErrCannotAct
+	}
+
+	if room.CurrentNightRole != nightTurnRole(player.Role) {
+		return nil, ErrNotYourTurn
+	}
+
+	if err := checkTurnTokenLocked(room, turnToken); err != nil {
+		return nil, err
+	}
+
+	return player, nil
+}
+
+// markNightActionCompleteLocked marks a player as done for the night
+func markNightActionCompleteLocked(room *models.GameRoom, player *models.Player) {
+	player.HasActedThisNight = true
+
+	if room.NightActionsCompleted == nil {
+		room.NightActionsCompleted = make(map[string]bool)
+	}
+	room.NightActionsCompleted[player.ID] = true
+
+	acknowledgeNightLocked(room, player)
+}
+
+// recordActionLocked appends an entry to the player's action history
+func recordActionLocked(room *models.GameRoom, player *models.Player, actionType string, target *models.Player) {
+	record := models.ActionRecord{
+		Round:      room.Round,
+		Phase:      room.Phase,
+		ActionType: actionType,
+	}
+	if target != nil {
+		record.TargetID = target.ID
+		record.TargetUsername = target.Username
+	}
+	player.ActionHistory = append(player.ActionHistory, record)
+	player.ActionSeq++
+	room.LastActivityAt = time.Now()
+}
+
+// lastActionLocked returns the player's pending record of the given type for the current round
+func lastActionLocked(room *models.GameRoom, player *models.Player, actionType string) *models.ActionRecord {
+	for i := len(player.ActionHistory) - 1; i >= 0; i-- {
+		record := &player.ActionHistory[i]
+		if record.Round != room.Round {
+			return nil
+		}
+		if record.ActionType == actionType && record.Outcome == "" {
+			return record
+		}
+	}
+	return nil
+}
+
+// settleActionsLocked fills in the outcome of every pending action of the given type this round
+func settleActionsLocked(room *models.GameRoom, actionType string, outcome func(record *models.ActionRecord) string) {
+	for _, player := range room.Players {
+		if record := lastActionLocked(room, player, actionType); record != nil {
+			record.Outcome = outcome(record)
+		}
+	}
+}
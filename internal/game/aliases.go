@@ -0,0 +1,123 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+
+	"github.com/werewolf-game/backend/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxAliasAttempts bounds the draws for a free alias; past it the room goes
+// without one in that language rather than failing to open
+const maxAliasAttempts = 20
+
+// aliasWords are the curated lists an alias draws one word from each of,
+// per language. Every word is short, common and easy to say and spell over
+// voice chat; none sounds like another in its list.
+var aliasWords = map[string][3][]string{
+	"en": {
+		{"amber", "brave", "bright", "calm", "clever", "cozy", "eager", "fancy", "gentle", "golden",
+			"happy", "jolly", "kind", "lucky", "merry", "mighty", "noble", "proud", "quick", "quiet",
+			"rapid", "royal", "shiny", "silver", "sleepy", "sunny", "swift", "tiny", "wild", "witty"},
+		{"badger", "bear", "beaver", "camel", "crane", "dolphin", "eagle", "falcon", "gecko", "heron",
+			"koala", "lemur", "lion", "llama", "moose", "otter", "owl", "panda", "parrot", "pony",
+			"rabbit", "raven", "robin", "salmon", "seal", "swan", "turtle", "walrus", "whale", "zebra"},
+		{"anchor", "basket", "bell", "candle", "castle", "cloud", "comet", "drum", "feather", "garden",
+			"harbor", "island", "kettle", "lantern", "meadow", "mirror", "mountain", "orchard", "pebble", "pillow",
+			"planet", "river", "rocket", "saddle", "teapot", "tower", "tulip", "valley", "violin", "window"},
+	},
+	"th": {
+		{"ช้าง", "ม้า", "ปลา", "นก", "กบ", "เต่า", "แมว", "หมี", "ลิง", "กวาง",
+			"กระต่าย", "ไก่", "เป็ด", "ห่าน", "ปู", "กุ้ง", "หอย", "ผึ้ง", "ผีเสื้อ", "ยีราฟ"},
+		{"แดง", "ขาว", "ดำ", "เขียว", "ฟ้า", "ชมพู", "ม่วง", "ทอง", "เงิน", "ส้ม",
+			"ใหญ่", "น้อย", "อ้วน", "ผอม", "ใจดี", "ขี้เล่น", "ร่าเริง", "สดใส", "ขยัน", "ซน"},
+		{"มะม่วง", "มะพร้าว", "กล้วย", "ส้มโอ", "ทุเรียน", "ลำไย", "เงาะ", "มังคุด", "แตงโม", "สับปะรด",
+			"ดาว", "เมฆ", "ภูเขา", "ทะเล", "แม่น้ำ", "ดอกไม้", "ต้นไม้", "ก้อนหิน", "สายรุ้ง", "พระจันทร์"},
+	},
+}
+
+// aliasSeparators join an alias's words for display, per language. Thai is
+// written without spaces, so a dot keeps its words apart.
+var aliasSeparators = map[string]string{
+	"en": "-",
+	"th": ".",
+}
+
+// normalizeAlias folds an alias the way clients may type it: any case, with
+// or without the separators or spaces between its words
+func normalizeAlias(alias string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' || r == '.' || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, norm.NFC.String(alias))
+}
+
+// assignAliasesLocked draws the room an alias in each language, redrawing
+// any that is taken, and indexes them
+func (gm *GameManager) assignAliasesLocked(room *models.GameRoom) {
+	if gm.aliases == nil {
+		gm.aliases = make(map[string]models.RoomCode)
+	}
+	room.Aliases = make(map[string]string, len(aliasWords))
+	for lang, lists := range aliasWords {
+		for attempt := 0; attempt < maxAliasAttempts; attempt++ {
+			words := make([]string, len(lists))
+			for i, list := range lists {
+				words[i] = list[rand.Intn(len(list))]
+			}
+			alias := strings.Join(words, aliasSeparators[lang])
+			if _, taken := gm.aliases[normalizeAlias(alias)]; taken {
+				continue
+			}
+			room.Aliases[lang] = alias
+			gm.aliases[normalizeAlias(alias)] = room.Code
+			break
+		}
+	}
+}
+
+// indexAliasesLocked indexes the aliases a loaded room already has,
+// dropping any another room holds
+func (gm *GameManager) indexAliasesLocked(room *models.GameRoom) {
+	if gm.aliases == nil {
+		gm.aliases = make(map[string]models.RoomCode)
+	}
+	for lang, alias := range room.Aliases {
+		if _, taken := gm.aliases[normalizeAlias(alias)]; taken {
+			delete(room.Aliases, lang)
+			continue
+		}
+		gm.aliases[normalizeAlias(alias)] = room.Code
+	}
+}
+
+// forgetAliasesLocked frees a deleted room's aliases
+func (gm *GameManager) forgetAliasesLocked(room *models.GameRoom) {
+	for _, alias := range room.Aliases {
+		if gm.aliases[normalizeAlias(alias)] == room.Code {
+			delete(gm.aliases, normalizeAlias(alias))
+		}
+	}
+}
+
+// ResolveRoomCode returns the room code a client means, given either a code
+// or one of a room's aliases. Input that is neither comes back as a
+// normalized code, so looking it up fails as usual.
+func (gm *GameManager) ResolveRoomCode(input string) models.RoomCode {
+	code := models.NormalizeRoomCode(input)
+
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	if _, exists := gm.Rooms[code]; exists {
+		return code
+	}
+	if aliased, ok := gm.aliases[normalizeAlias(input)]; ok {
+		return aliased
+	}
+	return code
+}
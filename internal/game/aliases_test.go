@@ -0,0 +1,106 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestRoomsResolveByAlias(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 1, nil)
+	view, _ := gm.RoomView(code, "")
+
+	english, thai := view.Aliases["en"], view.Aliases["th"]
+	if len(strings.Split(english, "-")) != 3 || len(strings.Split(thai, ".")) != 3 {
+		t.Fatalf("aliases = %v, want three words in each language", view.Aliases)
+	}
+	for _, input := range []string{
+		code.String(),
+		strings.ToLower(code.String()),
+		english,
+		strings.ToUpper(english),
+		" " + strings.ReplaceAll(english, "-", " ") + " ",
+		strings.ReplaceAll(english, "-", ""),
+		thai,
+		strings.ReplaceAll(thai, ".", " "),
+	} {
+		if got := gm.ResolveRoomCode(input); got != code {
+			t.Errorf("%q resolves to %q, want %s", input, got, code)
+		}
+	}
+	if got := gm.ResolveRoomCode("no-such-alias"); got == code {
+		t.Error("an unknown alias resolved to the room")
+	}
+}
+
+func TestTakenAliasIsRedrawn(t *testing.T) {
+	words := aliasWords
+	aliasWords = map[string][3][]string{"en": {{"amber", "brave"}, {"badger"}, {"anchor"}}}
+	t.Cleanup(func() { aliasWords = words })
+
+	gm := NewGameManager()
+	first := newTestRoom(t, gm, 1, nil)
+	second, err := gm.CreateRoom("h2", "Host2", models.DefaultRoomSettings())
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	view, _ := gm.RoomView(first, "")
+	if view.Aliases["en"] == "" || second.Aliases["en"] == "" || view.Aliases["en"] == second.Aliases["en"] {
+		t.Fatalf("aliases %q and %q, want the second redrawn", view.Aliases["en"], second.Aliases["en"])
+	}
+
+	// With every alias taken a room opens without one
+	third, err := gm.CreateRoom("h3", "Host3", models.DefaultRoomSettings())
+	if err != nil {
+		t.Fatalf("create a room past the last alias: %v", err)
+	}
+	if alias, ok := third.Aliases["en"]; ok {
+		t.Errorf("the third room took %q, already held", alias)
+	}
+}
+
+func TestDeletedRoomFreesItsAliases(t *testing.T) {
+	t.Run("left empty", func(t *testing.T) {
+		gm := NewGameManager()
+		code := newTestRoom(t, gm, 1, nil)
+		view, _ := gm.RoomView(code, "")
+		if err := gm.RemovePlayer(code, "p1"); err != nil {
+			t.Fatalf("leave: %v", err)
+		}
+		wantAliasesFreed(t, gm, code, view.Aliases)
+	})
+
+	t.Run("swept", func(t *testing.T) {
+		gm := NewGameManager()
+		code := newTestRoom(t, gm, 1, nil)
+		view, _ := gm.RoomView(code, "")
+		gm.mu.Lock()
+		gm.janitor.idleTimeout = time.Minute
+		gm.mu.Unlock()
+		gm.SweepIdleRooms(time.Now().Add(time.Hour))
+		wantAliasesFreed(t, gm, code, view.Aliases)
+	})
+}
+
+// wantAliasesFreed fails the test if any of a deleted room's aliases still
+// resolves to it or is still indexed
+func wantAliasesFreed(t *testing.T, gm *GameManager, code models.RoomCode, aliases map[string]string) {
+	t.Helper()
+
+	if len(aliases) == 0 {
+		t.Fatal("the room had no aliases")
+	}
+	for lang, alias := range aliases {
+		if got := gm.ResolveRoomCode(alias); got == code {
+			t.Errorf("the %s alias %q still resolves to the deleted room", lang, alias)
+		}
+	}
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	if len(gm.aliases) != 0 {
+		t.Errorf("the index still holds %v", gm.aliases)
+	}
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// archiveRetention is how long the code of a reclaimed ended game is still
+// recognized, so stale clients learn the game is over rather than that it
+// never existed
+const archiveRetention = 24 * time.Hour
+
+var ErrRoomArchived = &errs.Error{Code: "ROOM_ARCHIVED", Message: "this game has ended"}
+
+// archiveLocked reclaims an ended game's room. Observers hear OnRoomArchived,
+// then TeardownRoom, then OnRoomDeleted; lookups by its code fail with
+// ErrRoomArchived until archiveRetention has passed.
+func (gm *GameManager) archiveLocked(room *models.GameRoom, now time.Time) {
+	if gm.archived == nil {
+		gm.archived = make(map[models.RoomCode]time.Time)
+	}
+	gm.archived[room.Code] = now
+	gm.notify(room, func(o RoomObserver) { o.OnRoomArchived(room) })
+	gm.teardown(room)
+}
+
+// pruneArchiveLocked forgets archived codes older than archiveRetention
+func (gm *GameManager) pruneArchiveLocked(now time.Time) {
+	for code, archivedAt := range gm.archived {
+		if now.Sub(archivedAt) >= archiveRetention {
+			delete(gm.archived, code)
+		}
+	}
+}
+
+// missingRoomLocked is the error for a code with no room: ErrRoomArchived
+// for a recently archived game, ErrRoomNotFound otherwise
+func (gm *GameManager) missingRoomLocked(code models.RoomCode) error {
+	if archivedAt, archived := gm.archived[code]; archived && time.Since(archivedAt) < archiveRetention {
+		return ErrRoomArchived
+	}
+	return ErrRoomNotFound
+}
+
+// MissingRoomError is the error to report for a code with no room, for
+// frontends that looked the room up themselves
+func (gm *GameManager) MissingRoomError(code models.RoomCode) error {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.missingRoomLocked(code)
+}
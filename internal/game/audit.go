@@ -0,0 +1,194 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Administrative actions recorded in a room's host action log
+const (
+	HostActionKick              = "kick"
+	HostActionMute              = "mute"
+	HostActionUnmute            = "unmute"
+	HostActionGrantModerator    = "grant_moderator"
+	HostActionRevokeModerator   = "revoke_moderator"
+	HostActionSkipPhase         = "skip_phase"
+	HostActionChangeSettings    = "change_settings"
+	HostActionAcceptComposition = "accept_composition"
+	HostActionExtendDiscussion  = "extend_discussion"
+	HostActionAbortGame         = "abort_game"
+	HostActionSlowMode          = "slow_mode"
+	HostActionAutoStart         = "auto_start"
+	HostActionRevealBody        = "reveal_body"
+)
+
+// recordHostActionLocked logs an administrative action, queues a system chat
+// line telling the room about it and notifies observers
+func (gm *GameManager) recordHostActionLocked(room *models.GameRoom, actorID, action, targetID, detail string) {
+	entry := models.HostAction{
+		ActorID:  actorID,
+		Action:   action,
+		TargetID: targetID,
+		Detail:   detail,
+		At:       models.NewTimestamp(time.Now()),
+	}
+	room.HostActionLog = append(room.HostActionLog, entry)
+	gm.systemNoticeLocked(room, hostActionText(room, entry), "", entry.At.Time)
+
+	gm.notify(room, func(o RoomObserver) { o.OnHostAction(room, entry) })
+}
+
+// systemNoticeLocked adds a system line to the chat and queues it for the room
+func (gm *GameManager) systemNoticeLocked(room *models.GameRoom, content, key string, at time.Time) {
+	notice := &models.Message{
+		ID:        uuid.New().String(),
+		RoomCode:  room.Code,
+		Username:  "System",
+		Content:   content,
+		Timestamp: models.NewTimestamp(at),
+		Type:      models.MessageSystem,
+		Key:       key,
+		Phase:     room.Phase,
+	}
+	gm.appendChatLocked(room, notice)
+	room.SystemNotices = append(room.SystemNotices, notice)
+}
+
+// hostActionText is the terse line the room sees, e.g. "Host Ann skipped the day phase"
+func hostActionText(room *models.GameRoom, entry models.HostAction) string {
+	if entry.Action == HostActionAutoStart {
+		return "Everyone was ready, so the game started on its own"
+	}
+
+	actor := "Moderator"
+	if entry.ActorID == room.HostID {
+		actor = "Host"
+	} else if player := room.Players[entry.ActorID]; player == nil || !player.IsModerator {
+		actor = "Player"
+	}
+	actor += " " + playerName(room, entry.ActorID)
+	target := playerName(room, entry.TargetID)
+
+	switch entry.Action {
+	case HostActionKick:
+		return fmt.Sprintf("%s kicked %s", actor, target)
+	case HostActionMute:
+		return fmt.Sprintf("%s muted %s", actor, target)
+	case HostActionUnmute:
+		return fmt.Sprintf("%s unmuted %s", actor, target)
+	case HostActionGrantModerator:
+		return fmt.Sprintf("%s made %s a moderator", actor, target)
+	case HostActionRevokeModerator:
+		return fmt.Sprintf("%s removed %s as moderator", actor, target)
+	case HostActionSkipPhase:
+		return fmt.Sprintf("%s skipped the %s phase", actor, entry.Detail)
+	case HostActionChangeSettings:
+		return fmt.Sprintf("%s changed the room settings", actor)
+	case HostActionAcceptComposition:
+		return fmt.Sprintf("%s applied the suggested roles for %s players", actor, entry.Detail)
+	case HostActionExtendDiscussion:
+		return fmt.Sprintf("%s extended the discussion by %s seconds", actor, entry.Detail)
+	case HostActionAbortGame:
+		return fmt.Sprintf("%s asked to abort the game", actor)
+	case HostActionRevealBody:
+		return fmt.Sprintf("%s revealed who died last night", actor)
+	case HostActionSlowMode:
+		if entry.Detail == "0" {
+			return fmt.Sprintf("%s turned off slow mode", actor)
+		}
+		return fmt.Sprintf("%s turned on slow mode: one message every %s seconds", actor, entry.Detail)
+	}
+	return fmt.Sprintf("%s: %s", actor, entry.Action)
+}
+
+// playerName is the player's username, or their ID once they have left
+func playerName(room *models.GameRoom, playerID string) string {
+	if player := room.Players[playerID]; player != nil {
+		return player.Username
+	}
+	return playerID
+}
+
+// TakeSystemNotices returns the system chat lines queued since the last call
+// and forgets them, so each is broadcast once
+func (gm *GameManager) TakeSystemNotices(code models.RoomCode) []models.Message {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil
+	}
+
+	notices := make([]models.Message, 0, len(room.SystemNotices))
+	for _, notice := range room.SystemNotices {
+		notices = append(notices, *notice)
+	}
+	room.SystemNotices = nil
+	return notices
+}
+
+// HostActionLog returns a copy of every administrative action taken in the room
+func (gm *GameManager) HostActionLog(code models.RoomCode) ([]models.HostAction, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	return append([]models.HostAction{}, room.HostActionLog...), nil
+}
+
+// AdvancePhase ends the current phase at a player's request. Ending it
+// before its timer has run out is an administrative skip: it needs
+// PermSkipPhase and is logged.
+func (gm *GameManager) AdvancePhase(code models.RoomCode, playerID string) (*NightResult, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	from := room.Phase
+	early := room.PhaseEndTime != nil && time.Now().Before(room.PhaseEndTime.Time)
+	if early {
+		if err := earlySkipLocked(room, playerID); err != nil {
+			return nil, err
+		}
+	}
+
+	nightResult, err := gm.moveToNextPhaseLocked(room)
+	if err != nil {
+		return nil, err
+	}
+
+	if early && room.Phase != from {
+		gm.recordHostActionLocked(room, playerID, HostActionSkipPhase, "", string(from))
+	}
+
+	return nightResult, nil
+}
+
+// earlySkipLocked checks that the player may end a running phase before its timer
+func earlySkipLocked(room *models.GameRoom, playerID string) error {
+	if err := authorizeLocked(room, playerID, PermSkipPhase); err != nil {
+		return err
+	}
+
+	if pausedLocked(room) {
+		return ErrGamePaused
+	}
+
+	switch room.Phase {
+	case models.PhaseNight, models.PhaseDay, models.PhaseDefense, models.PhaseVoting:
+		return nil
+	}
+	return ErrInvalidTransition
+}
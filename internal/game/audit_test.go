@@ -0,0 +1,210 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestHostActionsLoggedAndAnnounced(t *testing.T) {
+	tests := []struct {
+		name    string
+		started bool
+		act     func(gm *GameManager, code models.RoomCode) error
+		want    models.HostAction
+		text    string
+	}{
+		{
+			name: "kick",
+			act:  func(gm *GameManager, code models.RoomCode) error { return gm.KickPlayer(code, "p1", "p5") },
+			want: models.HostAction{ActorID: "p1", Action: HostActionKick, TargetID: "p5"},
+			text: "Host Player1 kicked Player5",
+		},
+		{
+			name: "mute by a moderator",
+			act: func(gm *GameManager, code models.RoomCode) error {
+				if err := gm.SetModerator(code, "p1", "p2", true); err != nil {
+					return err
+				}
+				return gm.SetMuted(code, "p2", "p4", true)
+			},
+			want: models.HostAction{ActorID: "p2", Action: HostActionMute, TargetID: "p4"},
+			text: "Moderator Player2 muted Player4",
+		},
+		{
+			name: "unmute",
+			act: func(gm *GameManager, code models.RoomCode) error {
+				if err := gm.SetMuted(code, "p1", "p4", true); err != nil {
+					return err
+				}
+				return gm.SetMuted(code, "p1", "p4", false)
+			},
+			want: models.HostAction{ActorID: "p1", Action: HostActionUnmute, TargetID: "p4"},
+			text: "Host Player1 unmuted Player4",
+		},
+		{
+			name: "grant moderator",
+			act:  func(gm *GameManager, code models.RoomCode) error { return gm.SetModerator(code, "p1", "p3", true) },
+			want: models.HostAction{ActorID: "p1", Action: HostActionGrantModerator, TargetID: "p3"},
+			text: "Host Player1 made Player3 a moderator",
+		},
+		{
+			name: "revoke moderator",
+			act: func(gm *GameManager, code models.RoomCode) error {
+				if err := gm.SetModerator(code, "p1", "p3", true); err != nil {
+					return err
+				}
+				return gm.SetModerator(code, "p1", "p3", false)
+			},
+			want: models.HostAction{ActorID: "p1", Action: HostActionRevokeModerator, TargetID: "p3"},
+			text: "Host Player1 removed Player3 as moderator",
+		},
+		{
+			name: "change settings",
+			act: func(gm *GameManager, code models.RoomCode) error {
+				room, _ := gm.GetRoom(code)
+				settings := room.Settings
+				settings.RevealVotes = !settings.RevealVotes
+				return gm.UpdateSettings(code, "p1", settings)
+			},
+			want: models.HostAction{ActorID: "p1", Action: HostActionChangeSettings},
+			text: "Host Player1 changed the room settings",
+		},
+		{
+			name: "accept composition",
+			act: func(gm *GameManager, code models.RoomCode) error {
+				_, err := gm.AcceptSuggestedComposition(code, "p1")
+				return err
+			},
+			want: models.HostAction{ActorID: "p1", Action: HostActionAcceptComposition, Detail: "6"},
+			text: "Host Player1 applied the suggested roles for 6 players",
+		},
+		{
+			name: "slow mode on",
+			act:  func(gm *GameManager, code models.RoomCode) error { return gm.SetSlowMode(code, "p1", 10) },
+			want: models.HostAction{ActorID: "p1", Action: HostActionSlowMode, Detail: "10"},
+			text: "Host Player1 turned on slow mode: one message every 10 seconds",
+		},
+		{
+			name: "slow mode off",
+			act: func(gm *GameManager, code models.RoomCode) error {
+				if err := gm.SetSlowMode(code, "p1", 10); err != nil {
+					return err
+				}
+				return gm.SetSlowMode(code, "p1", 0)
+			},
+			want: models.HostAction{ActorID: "p1", Action: HostActionSlowMode, Detail: "0"},
+			text: "Host Player1 turned off slow mode",
+		},
+		{
+			name:    "extend discussion",
+			started: true,
+			act: func(gm *GameManager, code models.RoomCode) error {
+				return gm.ExtendDiscussion(code, "p1", 30*time.Second)
+			},
+			want: models.HostAction{ActorID: "p1", Action: HostActionExtendDiscussion, Detail: "30"},
+			text: "Host Player1 extended the discussion by 30 seconds",
+		},
+		{
+			name:    "skip",
+			started: true,
+			act: func(gm *GameManager, code models.RoomCode) error {
+				_, err := gm.AdvancePhase(code, "p1")
+				return err
+			},
+			want: models.HostAction{ActorID: "p1", Action: HostActionSkipPhase, Detail: string(models.PhaseDay)},
+			text: "Host Player1 skipped the day phase",
+		},
+		{
+			name:    "abort",
+			started: true,
+			act: func(gm *GameManager, code models.RoomCode) error {
+				_, _, err := gm.RequestAbort(code, "p1")
+				return err
+			},
+			want: models.HostAction{ActorID: "p1", Action: HostActionAbortGame},
+			text: "Host Player1 asked to abort the game",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := NewGameManager()
+			code := newTestRoom(t, gm, 6, nil)
+			if tt.started {
+				startTestGame(t, gm, code, map[string]models.Role{"p6": models.RoleTiger})
+			}
+			gm.TakeSystemNotices(code)
+
+			if err := tt.act(gm, code); err != nil {
+				t.Fatalf("act: %v", err)
+			}
+
+			log, err := gm.HostActionLog(code)
+			if err != nil || len(log) == 0 {
+				t.Fatalf("log = %v, %v", log, err)
+			}
+			got := log[len(log)-1]
+			if got.At.IsZero() {
+				t.Error("the entry has no time")
+			}
+			got.At = models.Timestamp{}
+			if got != tt.want {
+				t.Errorf("entry = %+v, want %+v", got, tt.want)
+			}
+
+			notices := gm.TakeSystemNotices(code)
+			if len(notices) == 0 {
+				t.Fatal("nothing was announced")
+			}
+			if notice := notices[len(notices)-1]; notice.Content != tt.text || notice.Type != models.MessageSystem {
+				t.Errorf("announced %s %q, want %q", notice.Type, notice.Content, tt.text)
+			}
+			if again := gm.TakeSystemNotices(code); len(again) != 0 {
+				t.Errorf("announced twice: %v", again)
+			}
+		})
+	}
+}
+
+func TestRefusedActionsAreNotLogged(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+
+	gm.KickPlayer(code, "p3", "p4")
+	gm.SetMuted(code, "p3", "p4", true)
+	gm.SetSlowMode(code, "p1", 0)
+	if log, _ := gm.HostActionLog(code); len(log) != 0 {
+		t.Errorf("log = %+v, want nothing for refused or empty actions", log)
+	}
+	if notices := gm.TakeSystemNotices(code); len(notices) != 0 {
+		t.Errorf("announced %v", notices)
+	}
+}
+
+func TestHostActionsInTheSummary(t *testing.T) {
+	gm, code := rematchGame(t)
+
+	if _, err := gm.Rematch(code, "p1"); err != nil {
+		t.Fatalf("rematch: %v", err)
+	}
+	if err := gm.KickPlayer(code, "p1", "p7"); err != nil {
+		t.Fatalf("kick: %v", err)
+	}
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p2")
+
+	summary, err := gm.GameSummary(code)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	var kicked bool
+	for _, action := range summary.HostActions {
+		kicked = kicked || action.Action == HostActionKick && action.TargetID == "p7"
+	}
+	if !kicked {
+		t.Errorf("summary host actions = %+v, want the kick", summary.HostActions)
+	}
+}
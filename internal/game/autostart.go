@@ -0,0 +1,173 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// DefaultAutoStartSeconds is the auto-start countdown when the room sets none
+const DefaultAutoStartSeconds = 10
+
+// AutoStartActor is who the host action log credits with an auto-start
+const AutoStartActor = "auto-start"
+
+// Reasons an auto-start countdown stops short
+const (
+	AutoStartNotReady      = "not_ready"      // someone un-readied, left or dropped
+	AutoStartHostCancelled = "host_cancelled" // the host called it off
+	AutoStartFailed        = "start_failed"   // the start itself was refused
+)
+
+// ErrNoAutoStart is returned for cancelling a countdown that is not running
+var ErrNoAutoStart = &errs.Error{Code: "NO_AUTO_START", Message: "no auto-start countdown is running"}
+
+// AutoStartCountdown is a running countdown to the game starting on its own
+type AutoStartCountdown struct {
+	StartsAt models.Timestamp `json:"startsAt"`
+	Seconds  int              `json:"seconds"`
+}
+
+// SetReady marks the player ready or not in the lobby
+func (gm *GameManager) SetReady(code models.RoomCode, playerID string, ready bool) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if room.Phase != models.PhaseWaiting {
+		return ErrGameAlreadyStarted
+	}
+
+	player := room.Players[playerID]
+	if player == nil {
+		return ErrPlayerNotFound
+	}
+
+	player.IsReady = ready
+	room.LastActivityAt = time.Now()
+	return nil
+}
+
+// RefreshAutoStart starts the room's auto-start countdown once the lobby
+// qualifies and stops it once it no longer does. It returns the countdown
+// it started, or whether it stopped one; call it after anything that may
+// change readiness, seats or connections in a lobby.
+func (gm *GameManager) RefreshAutoStart(code models.RoomCode, now time.Time) (*AutoStartCountdown, bool) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, false
+	}
+
+	ready := autoStartReadyLocked(room)
+	if !ready {
+		// A host's cancel holds until the lobby stops qualifying
+		room.AutoStartHeld = false
+	}
+
+	switch {
+	case room.AutoStartAt == nil && ready && !room.AutoStartHeld:
+		seconds := autoStartSeconds(room.Settings)
+		room.AutoStartAt = models.TimestampPtr(now.Add(time.Duration(seconds) * time.Second))
+		return &AutoStartCountdown{StartsAt: *room.AutoStartAt, Seconds: seconds}, false
+	case room.AutoStartAt != nil && !ready:
+		room.AutoStartAt = nil
+		return nil, true
+	}
+	return nil, false
+}
+
+// CancelAutoStart stops the running countdown (host only). It stays off
+// until the lobby stops qualifying and qualifies again.
+func (gm *GameManager) CancelAutoStart(code models.RoomCode, playerID string) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, playerID, PermStartGame); err != nil {
+		return err
+	}
+
+	if room.AutoStartAt == nil {
+		return ErrNoAutoStart
+	}
+
+	room.AutoStartAt = nil
+	room.AutoStartHeld = true
+	return nil
+}
+
+// AutoStartGame starts the game for the countdown ending at startsAt, if
+// that countdown is still running and has elapsed. It goes through the same
+// checks as StartGame and is credited to AutoStartActor. It reports whether
+// the game started; a refused start also ends the countdown.
+func (gm *GameManager) AutoStartGame(code models.RoomCode, startsAt models.Timestamp, now time.Time) (bool, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return false, gm.missingRoomLocked(code)
+	}
+
+	// A countdown that was stopped or replaced has nothing left to do
+	if room.AutoStartAt == nil || !room.AutoStartAt.Equal(startsAt.Time) || now.Before(startsAt.Time) {
+		return false, nil
+	}
+	room.AutoStartAt = nil
+
+	if !autoStartReadyLocked(room) {
+		return false, nil
+	}
+	if err := gm.startGameLocked(room); err != nil {
+		return false, err
+	}
+	gm.recordHostActionLocked(room, AutoStartActor, HostActionAutoStart, "", "")
+	return true, nil
+}
+
+// autoStartReadyLocked reports whether the lobby qualifies for auto-start:
+// the room allows it, every seat up to the composition size is filled, and
+// every player is ready and connected
+func autoStartReadyLocked(room *models.GameRoom) bool {
+	if !room.Settings.AutoStartWhenReady || room.Phase != models.PhaseWaiting || room.Settings.PracticeMode {
+		return false
+	}
+
+	seats := room.MaxPlayers
+	if len(room.Settings.Composition) > 0 {
+		seats = 0
+		for _, count := range room.Settings.Composition {
+			seats += count
+		}
+	}
+	if len(room.Players) < seats {
+		return false
+	}
+
+	for _, player := range room.Players {
+		if !player.IsReady || !player.IsConnected {
+			return false
+		}
+	}
+	return startableLocked(room) == nil
+}
+
+// autoStartSeconds is the room's countdown length
+func autoStartSeconds(settings models.RoomSettings) int {
+	if settings.AutoStartSeconds > 0 {
+		return settings.AutoStartSeconds
+	}
+	return DefaultAutoStartSeconds
+}
@@ -0,0 +1,167 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// readyLobby is a full lobby of six that auto-starts, everyone connected
+// and all but p6 ready
+func readyLobby(t *testing.T, adjust func(*models.RoomSettings)) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.MaxPlayers = 6
+		settings.AutoStartWhenReady = true
+		if adjust != nil {
+			adjust(settings)
+		}
+	})
+	for i := 1; i <= 6; i++ {
+		id := fmt.Sprintf("p%d", i)
+		gm.SetConnected(code, id, true)
+		if i < 6 {
+			if err := gm.SetReady(code, id, true); err != nil {
+				t.Fatalf("%s readies: %v", id, err)
+			}
+		}
+	}
+	if countdown, _ := gm.RefreshAutoStart(code, time.Now()); countdown != nil {
+		t.Fatalf("a countdown began with p6 not ready: %+v", countdown)
+	}
+	return gm, code
+}
+
+// lastReady readies p6 and returns the countdown that starts
+func lastReady(t *testing.T, gm *GameManager, code models.RoomCode, now time.Time) *AutoStartCountdown {
+	t.Helper()
+
+	if err := gm.SetReady(code, "p6", true); err != nil {
+		t.Fatalf("p6 readies: %v", err)
+	}
+	countdown, _ := gm.RefreshAutoStart(code, now)
+	if countdown == nil {
+		t.Fatal("no countdown once everyone was ready")
+	}
+	return countdown
+}
+
+func TestAutoStartCountdownCompletes(t *testing.T) {
+	gm, code := readyLobby(t, nil)
+	now := time.Now()
+	countdown := lastReady(t, gm, code, now)
+	if countdown.Seconds != DefaultAutoStartSeconds || !countdown.StartsAt.Equal(now.Add(DefaultAutoStartSeconds*time.Second)) {
+		t.Errorf("countdown = %+v, want the default %d seconds from now", countdown, DefaultAutoStartSeconds)
+	}
+	if again, stopped := gm.RefreshAutoStart(code, now.Add(time.Second)); again != nil || stopped {
+		t.Errorf("a second refresh returned %+v, stopped %v; want the countdown left running", again, stopped)
+	}
+
+	if started, err := gm.AutoStartGame(code, countdown.StartsAt, countdown.StartsAt.Add(-time.Second)); started || err != nil {
+		t.Fatalf("started %v, %v before the countdown ended", started, err)
+	}
+	if started, err := gm.AutoStartGame(code, countdown.StartsAt, countdown.StartsAt.Time); !started || err != nil {
+		t.Fatalf("the countdown ended without a start: %v", err)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Errorf("phase = %s, want the first day", phase)
+	}
+	actions, _ := gm.HostActionLog(code)
+	if n := len(actions); n == 0 || actions[n-1].ActorID != AutoStartActor || actions[n-1].Action != HostActionAutoStart {
+		t.Errorf("host action log = %+v, want the start credited to %s", actions, AutoStartActor)
+	}
+}
+
+func TestAutoStartSeconds(t *testing.T) {
+	gm, code := readyLobby(t, func(settings *models.RoomSettings) { settings.AutoStartSeconds = 3 })
+	if countdown := lastReady(t, gm, code, time.Now()); countdown.Seconds != 3 {
+		t.Errorf("countdown of %d seconds, want 3", countdown.Seconds)
+	}
+}
+
+func TestAutoStartCountdownAborts(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		change func(gm *GameManager, code models.RoomCode) error
+	}{
+		{"un-ready", func(gm *GameManager, code models.RoomCode) error { return gm.SetReady(code, "p3", false) }},
+		{"leave", func(gm *GameManager, code models.RoomCode) error { return gm.RemovePlayer(code, "p3") }},
+		{"kick", func(gm *GameManager, code models.RoomCode) error { return gm.KickPlayer(code, "p1", "p3") }},
+		{"disconnect", func(gm *GameManager, code models.RoomCode) error {
+			gm.SetConnected(code, "p3", false)
+			return nil
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gm, code := readyLobby(t, nil)
+			countdown := lastReady(t, gm, code, time.Now())
+
+			if err := tc.change(gm, code); err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if again, stopped := gm.RefreshAutoStart(code, time.Now()); again != nil || !stopped {
+				t.Fatalf("refresh returned %+v, stopped %v; want the countdown stopped", again, stopped)
+			}
+			if started, _ := gm.AutoStartGame(code, countdown.StartsAt, countdown.StartsAt.Time); started {
+				t.Error("a stopped countdown started the game")
+			}
+			if phase := phaseOf(t, gm, code); phase != models.PhaseWaiting {
+				t.Errorf("phase = %s, want the lobby", phase)
+			}
+		})
+	}
+}
+
+func TestHostCancelsAutoStart(t *testing.T) {
+	gm, code := readyLobby(t, nil)
+	countdown := lastReady(t, gm, code, time.Now())
+
+	if err := gm.CancelAutoStart(code, "p2"); err == nil {
+		t.Error("a guest cancelled the countdown")
+	}
+	if err := gm.CancelAutoStart(code, "p1"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if err := gm.CancelAutoStart(code, "p1"); !errors.Is(err, ErrNoAutoStart) {
+		t.Errorf("cancelling twice: %v, want %s", err, ErrNoAutoStart.Code)
+	}
+	if started, _ := gm.AutoStartGame(code, countdown.StartsAt, countdown.StartsAt.Time); started {
+		t.Fatal("a cancelled countdown started the game")
+	}
+
+	// It stays off while the lobby still qualifies...
+	if again, _ := gm.RefreshAutoStart(code, time.Now()); again != nil {
+		t.Errorf("the countdown came back straight after the cancel: %+v", again)
+	}
+	// ...and returns once it stops and qualifies again
+	if err := gm.SetReady(code, "p6", false); err != nil {
+		t.Fatalf("p6 un-readies: %v", err)
+	}
+	gm.RefreshAutoStart(code, time.Now())
+	lastReady(t, gm, code, time.Now())
+}
+
+func TestAutoStartOff(t *testing.T) {
+	gm, code := readyLobby(t, func(settings *models.RoomSettings) { settings.AutoStartWhenReady = false })
+	if err := gm.SetReady(code, "p6", true); err != nil {
+		t.Fatalf("p6 readies: %v", err)
+	}
+	if countdown, _ := gm.RefreshAutoStart(code, time.Now()); countdown != nil {
+		t.Errorf("a countdown began with auto-start off: %+v", countdown)
+	}
+}
+
+func TestAutoStartWaitsForEverySeat(t *testing.T) {
+	gm, code := readyLobby(t, func(settings *models.RoomSettings) { settings.MaxPlayers = 7 })
+	if err := gm.SetReady(code, "p6", true); err != nil {
+		t.Fatalf("p6 readies: %v", err)
+	}
+	if countdown, _ := gm.RefreshAutoStart(code, time.Now()); countdown != nil {
+		t.Errorf("a countdown began with a seat empty: %+v", countdown)
+	}
+}
@@ -0,0 +1,293 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/sanitize"
+)
+
+const (
+	maxChatHistory    = 100              // messages kept per room for late joiners
+	chatEditWindow    = 60 * time.Second // how long the author may edit or delete
+	chatDeletedByHost = "host"
+	chatDeletedByUser = "author"
+)
+
+var ErrMessageNotFound = &errs.Error{Code: "MESSAGE_NOT_FOUND", Message: "message not found"}
+
+var (
+	ErrMuted               = &errs.Error{Code: "PLAYER_MUTED", Message: "you are muted"}
+	ErrDefenseNomineesOnly = &errs.Error{Code: "DEFENSE_NOMINEES_ONLY", Message: "only nominees may speak during the defense"}
+	ErrDeadChatDisabled    = &errs.Error{Code: "DEAD_CHAT_DISABLED", Message: "the dead cannot speak in this room"}
+	ErrNotMessageAuthor    = &errs.Error{Code: "NOT_MESSAGE_AUTHOR", Message: "only the author can change this message"}
+	ErrMessageEditClosed   = &errs.Error{Code: "MESSAGE_EDIT_CLOSED", Message: "message can no longer be changed"}
+)
+
+// PostChat filters and stores a chat message, returning it as it should be broadcast
+func (gm *GameManager) PostChat(code models.RoomCode, playerID, content string) (*models.Message, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	player := room.Players[playerID]
+	if player == nil {
+		return nil, ErrPlayerNotFound
+	}
+
+	// The channel decides the type; nothing the client sent does
+	kind, err := chatChannelLocked(room, player)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err = filterChatContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := checkSlowModeLocked(room, playerID, kind, now); err != nil {
+		return nil, err
+	}
+
+	message := &models.Message{
+		ID:        uuid.New().String(),
+		RoomCode:  room.Code,
+		PlayerID:  playerID,
+		Username:  player.Username,
+		Content:   content,
+		Timestamp: models.NewTimestamp(now),
+		Type:      kind,
+		Phase:     room.Phase,
+	}
+
+	gm.appendChatLocked(room, message)
+	noteChatSentLocked(room, playerID, kind, now)
+
+	copied := *message
+	return &copied, nil
+}
+
+// EditMessage replaces the content of the author's own recent message
+func (gm *GameManager) EditMessage(code models.RoomCode, playerID, messageID, content string) (*models.Message, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	message := findMessageLocked(room, messageID)
+	if message == nil {
+		return nil, ErrMessageNotFound
+	}
+
+	now := time.Now()
+	if err := authorMayChangeLocked(room, message, playerID, now); err != nil {
+		return nil, err
+	}
+
+	content, err := filterChatContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	message.Content = content
+	message.EditedAt = models.TimestampPtr(now)
+
+	copied := *message
+	return &copied, nil
+}
+
+// DeleteMessage removes a chat message. The author may delete their own
+// message within the edit window; the host may delete any message at any time.
+func (gm *GameManager) DeleteMessage(code models.RoomCode, playerID, messageID string) (*models.Message, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	message := findMessageLocked(room, messageID)
+	if message == nil {
+		return nil, ErrMessageNotFound
+	}
+
+	deletedBy := chatDeletedByHost
+	if room.HostID != playerID {
+		if err := authorMayChangeLocked(room, message, playerID, time.Now()); err != nil {
+			return nil, err
+		}
+		deletedBy = chatDeletedByUser
+	}
+
+	message.Content = ""
+	message.Deleted = true
+	message.DeletedBy = deletedBy
+
+	copied := *message
+	return &copied, nil
+}
+
+// ChatHistory returns a copy of the room's recent chat messages, oldest first
+func (gm *GameManager) ChatHistory(code models.RoomCode, viewerID string) []models.Message {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil
+	}
+
+	readsDead := readsDeadChatLocked(room, viewerID)
+	history := make([]models.Message, 0, len(room.ChatHistory))
+	for _, message := range room.ChatHistory {
+		if message.Type == models.MessageDead && !readsDead {
+			continue
+		}
+		history = append(history, *message)
+	}
+	return history
+}
+
+// ChatAudience returns the players who may read a message, or nil when
+// everyone in the room may
+func (gm *GameManager) ChatAudience(code models.RoomCode, message *models.Message) []string {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists || message.Type != models.MessageDead || room.Phase == models.PhaseEnded {
+		return nil
+	}
+
+	audience := []string{}
+	for id := range room.Players {
+		if readsDeadChatLocked(room, id) {
+			audience = append(audience, id)
+		}
+	}
+	return audience
+}
+
+// appendChatLocked numbers and stores a message. Past ChatMemoryLimit the
+// oldest half is spilled to the ChatStore, or dropped when there is none.
+func (gm *GameManager) appendChatLocked(room *models.GameRoom, message *models.Message) {
+	room.ChatSeq++
+	message.Seq = room.ChatSeq
+	room.ChatHistory = append(room.ChatHistory, message)
+	room.LastActivityAt = message.Timestamp.Time
+
+	limit := gm.ChatMemoryLimit
+	if limit <= 0 {
+		limit = maxChatHistory
+	}
+	if len(room.ChatHistory) <= limit {
+		return
+	}
+
+	if gm.ChatStore == nil {
+		room.ChatHistory = room.ChatHistory[len(room.ChatHistory)-limit:]
+		return
+	}
+
+	spill := room.ChatHistory[:len(room.ChatHistory)/2]
+	messages := make([]models.Message, len(spill))
+	for i, spilled := range spill {
+		messages[i] = *spilled
+	}
+	if err := gm.ChatStore.AppendChat(room.Code, messages); err != nil {
+		log.Printf("Room %s chat spill failed, dropping %d messages: %v", room.Code, len(spill), err)
+	} else {
+		gm.chatSpilled += len(spill)
+	}
+	room.ChatHistory = append([]*models.Message(nil), room.ChatHistory[len(spill):]...)
+}
+
+// deadDuringGame reports whether the player died in a game still being played
+func deadDuringGame(room *models.GameRoom, player *models.Player) bool {
+	return !player.IsAlive && room.Phase != models.PhaseWaiting && room.Phase != models.PhaseEnded
+}
+
+// readsDeadChatLocked reports whether the viewer may read the dead chat:
+// the dead can while roles are not hard-hidden, and everyone can once the game is over
+func readsDeadChatLocked(room *models.GameRoom, viewerID string) bool {
+	if room.Phase == models.PhaseEnded {
+		return true
+	}
+
+	viewer := room.Players[viewerID]
+	return viewer != nil && !viewer.IsAlive && !room.Settings.HardHiddenRoles
+}
+
+// canChatLocked reports whether the player may currently speak in the room chat
+func canChatLocked(room *models.GameRoom, playerID string) error {
+	if player := room.Players[playerID]; player != nil && player.IsMuted {
+		return ErrMuted
+	}
+
+	// Only the accused may speak during their defense
+	if room.Phase == models.PhaseDefense && !containsID(room.Nominees, playerID) {
+		return ErrDefenseNomineesOnly
+	}
+
+	return nil
+}
+
+// chatChannelLocked returns the channel the player's messages go to now
+func chatChannelLocked(room *models.GameRoom, player *models.Player) (string, error) {
+	if err := canChatLocked(room, player.ID); err != nil {
+		return "", err
+	}
+
+	if deadDuringGame(room, player) {
+		if room.Settings.HardHiddenRoles {
+			return "", ErrDeadChatDisabled
+		}
+		return models.MessageDead, nil
+	}
+	return models.MessageChat, nil
+}
+
+// authorMayChangeLocked checks ownership, the edit window, and that the
+// author would still be allowed to say it in the current phase
+func authorMayChangeLocked(room *models.GameRoom, message *models.Message, playerID string, now time.Time) error {
+	if message.PlayerID != playerID {
+		return ErrNotMessageAuthor
+	}
+
+	if message.Deleted {
+		return ErrMessageNotFound
+	}
+
+	if now.Sub(message.Timestamp.Time) > chatEditWindow {
+		return ErrMessageEditClosed
+	}
+
+	return canChatLocked(room, playerID)
+}
+
+func findMessageLocked(room *models.GameRoom, messageID string) *models.Message {
+	for _, message := range room.ChatHistory {
+		if message.ID == messageID {
+			return message
+		}
+	}
+	return nil
+}
+
+// filterChatContent normalizes a chat message and rejects empty or oversized ones
+func filterChatContent(content string) (string, error) {
+	return sanitize.Text(content, sanitize.ChatMessage)
+}
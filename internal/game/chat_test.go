@@ -0,0 +1,133 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/sanitize"
+)
+
+// chatRoom is a lobby of four where p2 has just said "helo"
+func chatRoom(t *testing.T) (*GameManager, models.RoomCode, string) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 4, nil)
+	message, err := gm.PostChat(code, "p2", "helo")
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	return gm, code, message.ID
+}
+
+// ageMessage moves a message's timestamp back by d
+func ageMessage(t *testing.T, gm *GameManager, code models.RoomCode, messageID string, d time.Duration) {
+	t.Helper()
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		message := findMessageLocked(room, messageID)
+		message.Timestamp = models.NewTimestamp(message.Timestamp.Add(-d))
+	})
+}
+
+func TestEditMessageReachesLateJoiners(t *testing.T) {
+	gm, code, id := chatRoom(t)
+
+	edited, err := gm.EditMessage(code, "p2", id, "  hello\u200b   all ")
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	// The edit goes through the same filter as the original
+	if edited.Content != "hello all" || edited.EditedAt == nil {
+		t.Errorf("edited = %q at %v, want the filtered text and a time", edited.Content, edited.EditedAt)
+	}
+	if _, err := gm.EditMessage(code, "p2", id, " \u200b "); !errors.Is(err, sanitize.ErrTextEmpty) {
+		t.Errorf("editing to nothing: %v, want %s", err, sanitize.ErrTextEmpty.Code)
+	}
+
+	if _, err := gm.JoinRoom(code, "late", "Late"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	history := gm.ChatHistory(code, "late")
+	if len(history) != 1 || history[0].ID != id || history[0].Content != "hello all" || history[0].EditedAt == nil {
+		t.Errorf("late joiner's history = %+v, want the edited message", history)
+	}
+}
+
+func TestOnlyTheAuthorEdits(t *testing.T) {
+	gm, code, id := chatRoom(t)
+
+	// The host moderates by deleting, not by rewording
+	for _, editor := range []string{"p3", "p1"} {
+		if _, err := gm.EditMessage(code, editor, id, "hacked"); !errors.Is(err, ErrNotMessageAuthor) {
+			t.Errorf("%s edits p2's message: %v, want %s", editor, err, ErrNotMessageAuthor.Code)
+		}
+	}
+	if _, err := gm.DeleteMessage(code, "p3", id); !errors.Is(err, ErrNotMessageAuthor) {
+		t.Errorf("p3 deletes p2's message: %v, want %s", err, ErrNotMessageAuthor.Code)
+	}
+	if history := gm.ChatHistory(code, "p3"); history[0].Content != "helo" || history[0].Deleted {
+		t.Errorf("history = %+v, want the message untouched", history)
+	}
+}
+
+func TestEditWindow(t *testing.T) {
+	gm, code, id := chatRoom(t)
+
+	ageMessage(t, gm, code, id, chatEditWindow-time.Second)
+	if _, err := gm.EditMessage(code, "p2", id, "hello"); err != nil {
+		t.Fatalf("edit just inside the window: %v", err)
+	}
+
+	ageMessage(t, gm, code, id, 2*time.Second)
+	if _, err := gm.EditMessage(code, "p2", id, "hello!"); !errors.Is(err, ErrMessageEditClosed) {
+		t.Errorf("edit after the window: %v, want %s", err, ErrMessageEditClosed.Code)
+	}
+	if _, err := gm.DeleteMessage(code, "p2", id); !errors.Is(err, ErrMessageEditClosed) {
+		t.Errorf("delete after the window: %v, want %s", err, ErrMessageEditClosed.Code)
+	}
+}
+
+func TestEditRechecksTheRightToSpeak(t *testing.T) {
+	gm, code, id := chatRoom(t)
+	if err := gm.SetMuted(code, "p1", "p2", true); err != nil {
+		t.Fatalf("mute: %v", err)
+	}
+
+	if _, err := gm.EditMessage(code, "p2", id, "hello"); !errors.Is(err, ErrMuted) {
+		t.Errorf("a muted author edits: %v, want %s", err, ErrMuted.Code)
+	}
+}
+
+func TestAuthorDeletesMessage(t *testing.T) {
+	gm, code, id := chatRoom(t)
+
+	deleted, err := gm.DeleteMessage(code, "p2", id)
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !deleted.Deleted || deleted.DeletedBy != chatDeletedByUser || deleted.Content != "" {
+		t.Errorf("deleted = %+v, want it blanked and marked by the author", deleted)
+	}
+	if _, err := gm.EditMessage(code, "p2", id, "hello"); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("editing a deleted message: %v, want %s", err, ErrMessageNotFound.Code)
+	}
+}
+
+func TestHostDeletesAnyMessage(t *testing.T) {
+	gm, code, id := chatRoom(t)
+	ageMessage(t, gm, code, id, time.Hour)
+
+	deleted, err := gm.DeleteMessage(code, "p1", id)
+	if err != nil {
+		t.Fatalf("host delete: %v", err)
+	}
+	if !deleted.Deleted || deleted.DeletedBy != chatDeletedByHost || deleted.Content != "" {
+		t.Errorf("deleted = %+v, want it blanked and marked by the host", deleted)
+	}
+	if history := gm.ChatHistory(code, "p4"); len(history) != 1 || !history[0].Deleted || history[0].Content != "" {
+		t.Errorf("history = %+v, want the deleted placeholder", history)
+	}
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// ChatStore keeps the chat messages a room has spilled out of memory. Both
+// methods are called under the manager lock, so they should be quick.
+type ChatStore interface {
+	// AppendChat adds messages, oldest first, after the room's stored ones
+	AppendChat(roomCode models.RoomCode, messages []models.Message) error
+	// ReadChat returns the room's stored messages with a Seq above since,
+	// oldest first
+	ReadChat(roomCode models.RoomCode, since int64) ([]models.Message, error)
+	// DeleteChat forgets everything stored for the room
+	DeleteChat(roomCode models.RoomCode) error
+}
+
+// ChatSince returns the room's chat messages numbered above since, oldest
+// first, reading the spilled ones back from the ChatStore. Messages the
+// viewer may not read are left out.
+func (gm *GameManager) ChatSince(code models.RoomCode, viewerID string, since int64) ([]models.Message, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	var messages []models.Message
+	if gm.ChatStore != nil && (len(room.ChatHistory) == 0 || room.ChatHistory[0].Seq > since+1) {
+		stored, err := gm.ChatStore.ReadChat(code, since)
+		if err != nil {
+			return nil, fmt.Errorf("read stored chat: %w", err)
+		}
+		messages = stored
+	}
+
+	// Memory picks up where the store left off
+	last := since
+	if n := len(messages); n > 0 {
+		last = messages[n-1].Seq
+	}
+	for _, message := range room.ChatHistory {
+		if message.Seq > last {
+			messages = append(messages, *message)
+		}
+	}
+
+	readsDead := readsDeadChatLocked(room, viewerID)
+	visible := messages[:0]
+	for _, message := range messages {
+		if message.Type == models.MessageDead && !readsDead {
+			continue
+		}
+		visible = append(visible, message)
+	}
+	return visible, nil
+}
+
+// forgetChatLocked drops a room's stored chat when the room goes away
+func (gm *GameManager) forgetChatLocked(code models.RoomCode) {
+	if gm.ChatStore == nil {
+		return
+	}
+	if err := gm.ChatStore.DeleteChat(code); err != nil {
+		log.Printf("Room %s stored chat could not be deleted: %v", code, err)
+	}
+}
+
+// ChatSpilled returns how many chat messages have been moved to the ChatStore
+func (gm *GameManager) ChatSpilled() int {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.chatSpilled
+}
@@ -0,0 +1,133 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// memChatStore is a ChatStore kept in memory
+type memChatStore struct {
+	rooms map[models.RoomCode][]models.Message
+}
+
+func newMemChatStore() *memChatStore {
+	return &memChatStore{rooms: make(map[models.RoomCode][]models.Message)}
+}
+
+func (s *memChatStore) AppendChat(roomCode models.RoomCode, messages []models.Message) error {
+	s.rooms[roomCode] = append(s.rooms[roomCode], messages...)
+	return nil
+}
+
+func (s *memChatStore) ReadChat(roomCode models.RoomCode, since int64) ([]models.Message, error) {
+	var messages []models.Message
+	for _, message := range s.rooms[roomCode] {
+		if message.Seq > since {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (s *memChatStore) DeleteChat(roomCode models.RoomCode) error {
+	delete(s.rooms, roomCode)
+	return nil
+}
+
+// postChat appends count messages of the given type to the room
+func postChat(t *testing.T, gm *GameManager, code models.RoomCode, kind string, count int) {
+	t.Helper()
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for i := 0; i < count; i++ {
+			gm.appendChatLocked(room, &models.Message{
+				RoomCode:  code,
+				PlayerID:  "p1",
+				Content:   "hello",
+				Timestamp: models.NewTimestamp(time.Now()),
+				Type:      kind,
+			})
+		}
+	})
+}
+
+func TestChatSinceStitchesStoreAndMemory(t *testing.T) {
+	gm := NewGameManager()
+	store := newMemChatStore()
+	gm.ChatStore = store
+	gm.ChatMemoryLimit = 10
+	code := newTestRoom(t, gm, 5, nil)
+
+	postChat(t, gm, code, models.MessageChat, 37)
+	if len(store.rooms[code]) == 0 {
+		t.Fatal("nothing was spilled to the store")
+	}
+	if gm.ChatSpilled() != len(store.rooms[code]) {
+		t.Errorf("spilled = %d, store holds %d", gm.ChatSpilled(), len(store.rooms[code]))
+	}
+
+	// Every starting point, in the store, on the boundary or in memory,
+	// reads the rest of the sequence exactly once and in order
+	for since := int64(0); since <= 37; since++ {
+		messages, err := gm.ChatSince(code, "", since)
+		if err != nil {
+			t.Fatalf("since %d: %v", since, err)
+		}
+		if want := 37 - int(since); len(messages) != want {
+			t.Fatalf("since %d: got %d messages, want %d", since, len(messages), want)
+		}
+		for i, message := range messages {
+			if message.Seq != since+int64(i)+1 {
+				t.Fatalf("since %d: message %d has seq %d", since, i, message.Seq)
+			}
+		}
+	}
+}
+
+func TestChatWithoutStoreDropsOldest(t *testing.T) {
+	gm := NewGameManager()
+	gm.ChatMemoryLimit = 10
+	code := newTestRoom(t, gm, 5, nil)
+
+	postChat(t, gm, code, models.MessageChat, 25)
+
+	messages, err := gm.ChatSince(code, "", 0)
+	if err != nil {
+		t.Fatalf("chat since: %v", err)
+	}
+	if len(messages) != 10 || messages[0].Seq != 16 || messages[9].Seq != 25 {
+		t.Errorf("kept %d messages starting at %d, want the last 10", len(messages), messages[0].Seq)
+	}
+	if gm.ChatSpilled() != 0 {
+		t.Errorf("spilled = %d without a store", gm.ChatSpilled())
+	}
+}
+
+func TestChatSinceHidesDeadChatFromAnonymousReaders(t *testing.T) {
+	gm := NewGameManager()
+	gm.ChatStore = newMemChatStore()
+	gm.ChatMemoryLimit = 4
+	code := newTestRoom(t, gm, 5, nil)
+	startTestGame(t, gm, code, nil)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.Players["p5"].IsAlive = false
+	})
+
+	postChat(t, gm, code, models.MessageDead, 5)
+	postChat(t, gm, code, models.MessageChat, 5)
+
+	public, _ := gm.ChatSince(code, "", 0)
+	for _, message := range public {
+		if message.Type == models.MessageDead {
+			t.Fatalf("an anonymous reader got dead chat %d", message.Seq)
+		}
+	}
+	if len(public) != 5 {
+		t.Errorf("anonymous reader got %d messages, want the 5 public ones", len(public))
+	}
+	if dead, _ := gm.ChatSince(code, "p5", 0); len(dead) != 10 {
+		t.Errorf("a dead player read %d messages, want all 10", len(dead))
+	}
+}
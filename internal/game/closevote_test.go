@@ -0,0 +1,68 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestVoteClosesOnlyWhenOver(t *testing.T) {
+	t.Run("early", func(t *testing.T) {
+		gm, code := votingGame(t)
+		if err := gm.Vote(code, "p2", "p1"); err != nil {
+			t.Fatalf("vote: %v", err)
+		}
+		if _, err := gm.CloseVote(code); !errors.Is(err, ErrVoteNotOver) {
+			t.Fatalf("closing an open vote: %v, want %s", err, ErrVoteNotOver.Code)
+		}
+		if phase := phaseOf(t, gm, code); phase != models.PhaseVoting {
+			t.Errorf("the vote ended early: %s", phase)
+		}
+	})
+
+	t.Run("out of time", func(t *testing.T) {
+		gm, code := votingGame(t)
+		withRoom(t, gm, code, func(room *models.GameRoom) {
+			room.PhaseEndTime = models.TimestampPtr(time.Now().Add(-time.Second))
+		})
+		if _, err := gm.CloseVote(code); err != nil {
+			t.Fatalf("closing a vote past its deadline: %v", err)
+		}
+		if phase := phaseOf(t, gm, code); phase != models.PhaseNight {
+			t.Errorf("phase = %s, want night", phase)
+		}
+	})
+
+	t.Run("everyone voted", func(t *testing.T) {
+		gm, code := votingGame(t)
+		for _, voter := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+			target := "p2"
+			if voter == "p2" {
+				target = "p3"
+			}
+			if err := gm.Vote(code, voter, target); err != nil {
+				t.Fatalf("%s votes: %v", voter, err)
+			}
+		}
+		if _, err := gm.CloseVote(code); err != nil {
+			t.Fatalf("closing a complete vote: %v", err)
+		}
+		if isAlive(gm, code, "p2") {
+			t.Error("the votes were not counted")
+		}
+	})
+
+	t.Run("not voting", func(t *testing.T) {
+		gm := NewGameManager()
+		code := newTestRoom(t, gm, 6, nil)
+		startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+		withRoom(t, gm, code, func(room *models.GameRoom) {
+			room.PhaseEndTime = models.TimestampPtr(time.Now().Add(-time.Second))
+		})
+		if _, err := gm.CloseVote(code); !errors.Is(err, ErrNotVotingPhase) {
+			t.Errorf("closing the day as a vote: %v, want %s", err, ErrNotVotingPhase.Code)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// ErrInvalidColorSlot is returned for a slot outside models.PlayerColors
+var ErrInvalidColorSlot = &errs.Error{Code: "INVALID_COLOR_SLOT", Message: "invalid color slot"}
+
+// ChooseColor moves a player to an unclaimed color slot while in the lobby
+func (gm *GameManager) ChooseColor(code models.RoomCode, playerID string, slot int) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if room.Phase != models.PhaseWaiting {
+		return ErrGameAlreadyStarted
+	}
+
+	player := room.Players[playerID]
+	if player == nil {
+		return ErrPlayerNotFound
+	}
+
+	if slot < 0 || slot >= len(models.PlayerColors) {
+		return ErrInvalidColorSlot
+	}
+
+	if player.ColorSlot == slot {
+		return nil
+	}
+
+	for _, other := range room.Players {
+		if other.ColorSlot == slot {
+			return ErrSlotTaken
+		}
+	}
+
+	player.ColorSlot = slot
+
+	return nil
+}
+
+// firstFreeColorSlotLocked returns the lowest color slot no player holds.
+// Slots are freed simply by the player leaving the Players map. The palette
+// has a slot for every seat, but should a room ever outgrow it the slot
+// returned is still one no one holds, past the end of the palette.
+func firstFreeColorSlotLocked(room *models.GameRoom) int {
+	taken := make(map[int]bool, len(room.Players))
+	for _, player := range room.Players {
+		taken[player.ColorSlot] = true
+	}
+
+	slot := 0
+	for taken[slot] {
+		slot++
+	}
+	return slot
+}
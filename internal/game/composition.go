@@ -0,0 +1,194 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// DefaultComposition is the role distribution dealt to a room of count
+// players. The tiger team grows with the room but stays under a third of it.
+func DefaultComposition(count int) map[models.Role]int {
+	// 5 คน: เสือ 1, ชาวบ้าน 2, พราน 1, หมอผี 1
+	// 6 คน: เสือ 1, ชาวบ้าน 3, พราน 1, หมอผี 1
+	// 7-10 คน: เสือ 1, พญาสมิง 1, ชาวบ้าน (เหลือ), พราน 1, หมอผี 1
+	// 11-15 คน: เสือ 2, พญาสมิง 1, ชาวบ้าน (เหลือ), พราน 1, หมอผี 1
+	// 16-20 คน: เสือ 3, พญาสมิง 1, ชาวบ้าน (เหลือ), พราน 1, หมอผี 1
+	composition := map[models.Role]int{
+		models.RoleTiger:  1,
+		models.RoleHunter: 1,
+		models.RoleShaman: 1,
+	}
+	if count >= 7 {
+		composition[models.RoleAlphaTiger] = 1
+	}
+	if count >= 11 {
+		composition[models.RoleTiger]++
+	}
+	if count >= 16 {
+		composition[models.RoleTiger]++
+	}
+
+	if villagers := count - compositionSize(composition); villagers > 0 {
+		composition[models.RoleVillager] = villagers
+	}
+	return composition
+}
+
+// CompositionWarning tells the host their chosen roles no longer fit the lobby
+type CompositionWarning struct {
+	Players     int                 `json:"players"`
+	Composition map[models.Role]int `json:"composition"`
+	Suggested   map[models.Role]int `json:"suggested"`
+	Reason      string              `json:"reason"`
+	AcceptEvent string              `json:"acceptEvent"` // sent back to take the suggestion
+	HostID      string              `json:"-"`           // who to warn
+	// Event is composition_outdated when the lobby has grown past the
+	// composition, composition_invalid otherwise
+	Event string `json:"-"`
+}
+
+// CheckComposition re-validates the room's chosen composition against the
+// players now in the lobby. It returns nil when there is nothing to warn about.
+func (gm *GameManager) CheckComposition(code models.RoomCode) *CompositionWarning {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists || room.Phase != models.PhaseWaiting || len(room.Settings.Composition) == 0 {
+		return nil
+	}
+
+	err := validateComposition(room.Settings.Composition, len(room.Players))
+	if err == nil {
+		return nil
+	}
+
+	event := models.EventCompositionInvalid
+	if len(room.Players) > compositionSize(room.Settings.Composition) {
+		event = models.EventCompositionOutdated
+	}
+
+	return &CompositionWarning{
+		Players:     len(room.Players),
+		Composition: copyComposition(room.Settings.Composition),
+		Suggested:   DefaultComposition(len(room.Players)),
+		Reason:      err.Error(),
+		AcceptEvent: models.EventAcceptSuggestedComposition,
+		HostID:      room.HostID,
+		Event:       event,
+	}
+}
+
+// AcceptSuggestedComposition replaces the room's composition with the
+// default for the players now in the lobby (host only)
+func (gm *GameManager) AcceptSuggestedComposition(code models.RoomCode, playerID string) (map[models.Role]int, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	if err := settingsEditorLocked(room, playerID); err != nil {
+		return nil, err
+	}
+
+	room.Settings.Composition = DefaultComposition(len(room.Players))
+	markSettingsChangedLocked(room, playerID, time.Now())
+	gm.recordHostActionLocked(room, playerID, HostActionAcceptComposition, "", strconv.Itoa(len(room.Players)))
+
+	return copyComposition(room.Settings.Composition), nil
+}
+
+// compositionFor is what StartGame deals: the room's chosen composition, or
+// the default for its size
+func compositionFor(room *models.GameRoom) map[models.Role]int {
+	if len(room.Settings.Composition) > 0 {
+		return room.Settings.Composition
+	}
+	return DefaultComposition(len(room.Players))
+}
+
+// checkCompositionShape rejects compositions no lobby up to maxPlayers could play
+func checkCompositionShape(composition map[models.Role]int, maxPlayers int) error {
+	size := compositionSize(composition)
+	if size < minPlayers || size > maxPlayers {
+		return compositionError(size, size, fmt.Sprintf("composition must have between %d and %d roles", minPlayers, maxPlayers))
+	}
+	return validateComposition(composition, size)
+}
+
+// validateComposition checks that a composition can be dealt to count players
+// and gives both teams a game
+func validateComposition(composition map[models.Role]int, count int) error {
+	size := compositionSize(composition)
+
+	for role, n := range composition {
+		if _, known := roleCatalog[role]; !known {
+			return compositionError(count, size, fmt.Sprintf("unknown role %q", role))
+		}
+		if uniqueRoles[role] && n > 1 {
+			return compositionError(count, size, fmt.Sprintf("at most one %s", role))
+		}
+		if n < 0 {
+			return compositionError(count, size, fmt.Sprintf("negative count for %s", role))
+		}
+	}
+
+	if size < count {
+		err := compositionError(count, size, fmt.Sprintf("too many players for the composition: %d roles for %d players", size, count))
+		err.Code = "COMPOSITION_TOO_MANY_PLAYERS"
+		return err
+	}
+	if size > count {
+		err := compositionError(count, size, fmt.Sprintf("too few players for the composition: %d roles for %d players", size, count))
+		err.Code = "COMPOSITION_TOO_FEW_PLAYERS"
+		return err
+	}
+
+	tigers := composition[models.RoleTiger] + composition[models.RoleAlphaTiger]
+	if tigers == 0 {
+		return compositionError(count, size, "composition needs at least one tiger")
+	}
+	if tigers*2 >= size {
+		return compositionError(count, size, "tigers must be fewer than the humans")
+	}
+
+	return nil
+}
+
+// uniqueRoles may be dealt to one player at most
+var uniqueRoles = map[models.Role]bool{
+	models.RoleAlphaTiger: true,
+	models.RoleHunter:     true,
+	models.RoleShaman:     true,
+}
+
+func compositionError(players, roles int, message string) *errs.Error {
+	return &errs.Error{
+		Code:    "COMPOSITION_INVALID",
+		Params:  map[string]interface{}{"players": players, "roles": roles},
+		Message: message,
+	}
+}
+
+func copyComposition(composition map[models.Role]int) map[models.Role]int {
+	copied := make(map[models.Role]int, len(composition))
+	for role, n := range composition {
+		copied[role] = n
+	}
+	return copied
+}
+
+func compositionSize(composition map[models.Role]int) int {
+	size := 0
+	for _, n := range composition {
+		size += n
+	}
+	return size
+}
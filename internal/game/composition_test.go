@@ -0,0 +1,306 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestDefaultCompositionScales(t *testing.T) {
+	for count := minPlayers; count <= maxPlayersLimit; count++ {
+		composition := DefaultComposition(count)
+
+		tigers := 1
+		if count >= 11 {
+			tigers++
+		}
+		if count >= 16 {
+			tigers++
+		}
+		alphas := 0
+		if count >= 7 {
+			alphas = 1
+		}
+
+		if size := compositionSize(composition); size != count {
+			t.Errorf("%d players: the composition deals %d roles", count, size)
+		}
+		if n := composition[models.RoleTiger]; n != tigers {
+			t.Errorf("%d players: %d tigers, want %d", count, n, tigers)
+		}
+		if n := composition[models.RoleAlphaTiger]; n != alphas {
+			t.Errorf("%d players: %d alpha tigers, want %d", count, n, alphas)
+		}
+		if composition[models.RoleHunter] != 1 || composition[models.RoleShaman] != 1 {
+			t.Errorf("%d players: %v, want one hunter and one shaman", count, composition)
+		}
+		if team := tigers + alphas; 3*team >= count {
+			t.Errorf("%d players: a tiger team of %d is a third or more", count, team)
+		}
+	}
+}
+
+func TestColorSlotsStayUnique(t *testing.T) {
+	if len(models.PlayerColors) < maxPlayersLimit {
+		t.Fatalf("%d colors for rooms of up to %d", len(models.PlayerColors), maxPlayersLimit)
+	}
+	seen := make(map[string]bool)
+	for _, color := range models.PlayerColors {
+		if seen[color] {
+			t.Errorf("%s is in the palette twice", color)
+		}
+		seen[color] = true
+	}
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, maxPlayersLimit, nil)
+	slots := make(map[int]string)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for id, player := range room.Players {
+			if player.ColorSlot < 0 || player.ColorSlot >= len(models.PlayerColors) {
+				t.Errorf("%s has slot %d, outside the palette", id, player.ColorSlot)
+			}
+			if other, taken := slots[player.ColorSlot]; taken {
+				t.Errorf("%s and %s share slot %d", id, other, player.ColorSlot)
+			}
+			slots[player.ColorSlot] = id
+		}
+	})
+
+	// A seat given up frees its color for the next to join
+	freed := 0
+	withRoom(t, gm, code, func(room *models.GameRoom) { freed = room.Players["p7"].ColorSlot })
+	if err := gm.RemovePlayer(code, "p7"); err != nil {
+		t.Fatalf("remove p7: %v", err)
+	}
+	if _, err := gm.JoinRoom(code, "late", "Late"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if slot := room.Players["late"].ColorSlot; slot != freed {
+			t.Errorf("the newcomer got slot %d, want the freed %d", slot, freed)
+		}
+	})
+}
+
+func TestColorSlotPastTheFullPalette(t *testing.T) {
+	room := &models.GameRoom{Players: make(map[string]*models.Player)}
+	for slot := range models.PlayerColors {
+		id := fmt.Sprintf("p%d", slot)
+		room.Players[id] = &models.Player{ID: id, ColorSlot: slot}
+	}
+	// One already past the end, as a room restored from an older palette might have
+	room.Players["extra"] = &models.Player{ID: "extra", ColorSlot: len(models.PlayerColors)}
+
+	if slot := firstFreeColorSlotLocked(room); slot != len(models.PlayerColors)+1 {
+		t.Errorf("slot = %d, want %d, the first no one holds", slot, len(models.PlayerColors)+1)
+	}
+}
+
+// bigGame starts a game of the largest room size on the default composition
+func bigGame(t testing.TB, seed int64) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	gm.Seed = func() int64 { return seed }
+	code := newTestRoom(t, gm, maxPlayersLimit, nil)
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	return gm, code
+}
+
+func TestSimulatedLargestGamesFinish(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			gm, code := bigGame(t, seed)
+			simulateGame(t, gm, code, rand.New(rand.NewSource(seed)))
+
+			view, _ := gm.RoomView(code, "")
+			if view.WinningTeam != "human" && view.WinningTeam != "tiger" {
+				t.Errorf("winner = %q", view.WinningTeam)
+			}
+		})
+	}
+}
+
+func BenchmarkLargestNightResolution(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		gm, code := bigGame(b, int64(i))
+		villager := ""
+		withRoom(b, gm, code, func(room *models.GameRoom) {
+			for _, player := range playersByIDLocked(room) {
+				if player.Role == models.RoleVillager {
+					villager = player.ID
+				}
+			}
+		})
+		nextPhase(b, gm, code)
+		voteOut(b, gm, code, villager)
+		b.StartTimer()
+
+		playNight(b, gm, code, nil)
+	}
+}
+
+func TestCompositionOutgrownByJoins(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.Composition = DefaultComposition(6)
+	})
+	if warning := gm.CheckComposition(code); warning != nil {
+		t.Fatalf("a lobby that fits its composition was warned: %+v", warning)
+	}
+
+	for _, id := range []string{"p7", "p8"} {
+		if _, err := gm.JoinRoom(code, id, id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+	}
+	warning := gm.CheckComposition(code)
+	if warning == nil || warning.Event != models.EventCompositionOutdated {
+		t.Fatalf("warning = %+v, want composition_outdated", warning)
+	}
+	if !reflect.DeepEqual(warning.Suggested, DefaultComposition(8)) || warning.AcceptEvent != models.EventAcceptSuggestedComposition {
+		t.Errorf("suggested %v with %q, want the default for 8 and its accept event", warning.Suggested, warning.AcceptEvent)
+	}
+	if warning.HostID != "p1" || warning.Players != 8 {
+		t.Errorf("warning for %s about %d players", warning.HostID, warning.Players)
+	}
+
+	err := gm.StartGame(code, "p1")
+	var coded *errs.Error
+	if !errors.As(err, &coded) || coded.Code != "COMPOSITION_TOO_MANY_PLAYERS" {
+		t.Fatalf("start = %v, want COMPOSITION_TOO_MANY_PLAYERS", err)
+	}
+
+	if _, err := gm.AcceptSuggestedComposition(code, "p2"); err == nil {
+		t.Error("a player took the suggestion for the host")
+	}
+	if _, err := gm.AcceptSuggestedComposition(code, "p1"); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if warning := gm.CheckComposition(code); warning != nil {
+		t.Errorf("still warned after accepting: %+v", warning)
+	}
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Errorf("start after accepting: %v", err)
+	}
+}
+
+func TestCompositionTooBigForLobby(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.Composition = DefaultComposition(8)
+	})
+
+	// A lobby filling up is not told its composition is outdated
+	if warning := gm.CheckComposition(code); warning == nil || warning.Event != models.EventCompositionInvalid {
+		t.Errorf("warning = %+v, want composition_invalid", warning)
+	}
+	err := gm.StartGame(code, "p1")
+	var coded *errs.Error
+	if !errors.As(err, &coded) || coded.Code != "COMPOSITION_TOO_FEW_PLAYERS" {
+		t.Errorf("start = %v, want COMPOSITION_TOO_FEW_PLAYERS", err)
+	}
+}
+
+func TestRoomSettingsAreACopy(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.Composition = map[models.Role]int{models.RoleTiger: 1, models.RoleVillager: 5}
+		settings.NightCooldowns = map[models.Role]int{models.RoleShaman: 1}
+		settings.NightModifiers = []string{ModifierFog}
+	})
+
+	// A partial update decoded over the copy writes into its maps and slices
+	settings, _ := gm.RoomSettings(code)
+	update := `{"composition":{"hunter":1},"nightCooldowns":{"hunter":2},"nightModifiers":["quiet_night"]}`
+	if err := json.Unmarshal([]byte(update), &settings); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.Settings.Composition[models.RoleHunter] != 0 || room.Settings.NightCooldowns[models.RoleHunter] != 0 {
+			t.Errorf("the update reached the room's maps: %v, %v", room.Settings.Composition, room.Settings.NightCooldowns)
+		}
+		if !reflect.DeepEqual(room.Settings.NightModifiers, []string{ModifierFog}) {
+			t.Errorf("the update reached the room's modifiers: %v", room.Settings.NightModifiers)
+		}
+	})
+}
+
+func TestCompositionBrokenByLeaving(t *testing.T) {
+	// The host set up for nine and three friends left
+	lobby := func(t *testing.T) (*GameManager, models.RoomCode) {
+		t.Helper()
+
+		gm := NewGameManager()
+		code := newTestRoom(t, gm, 9, func(settings *models.RoomSettings) {
+			settings.Composition = DefaultComposition(9)
+		})
+		for _, id := range []string{"p7", "p8"} {
+			if err := gm.RemovePlayer(code, id); err != nil {
+				t.Fatalf("%s leaves: %v", id, err)
+			}
+		}
+		if err := gm.KickPlayer(code, "p1", "p9"); err != nil {
+			t.Fatalf("kick p9: %v", err)
+		}
+		return gm, code
+	}
+
+	t.Run("warns", func(t *testing.T) {
+		gm, code := lobby(t)
+		warning := gm.CheckComposition(code)
+		if warning == nil || warning.Event != models.EventCompositionInvalid || warning.Players != 6 || warning.HostID != "p1" {
+			t.Fatalf("warning = %+v, want composition_invalid for 6 players", warning)
+		}
+		if !reflect.DeepEqual(warning.Suggested, DefaultComposition(6)) || !reflect.DeepEqual(warning.Composition, DefaultComposition(9)) {
+			t.Errorf("suggested %v in place of %v, want the default for 6", warning.Suggested, warning.Composition)
+		}
+		err := gm.StartGame(code, "p1")
+		var coded *errs.Error
+		if !errors.As(err, &coded) || coded.Code != "COMPOSITION_TOO_FEW_PLAYERS" {
+			t.Errorf("start = %v, want COMPOSITION_TOO_FEW_PLAYERS", err)
+		}
+	})
+
+	t.Run("accepting the suggestion", func(t *testing.T) {
+		gm, code := lobby(t)
+		composition, err := gm.AcceptSuggestedComposition(code, "p1")
+		if err != nil || !reflect.DeepEqual(composition, DefaultComposition(6)) {
+			t.Fatalf("accept = %v, %v; want the default for 6", composition, err)
+		}
+		if warning := gm.CheckComposition(code); warning != nil {
+			t.Errorf("still warned: %+v", warning)
+		}
+		if err := gm.StartGame(code, "p1"); err != nil {
+			t.Errorf("start: %v", err)
+		}
+	})
+
+	t.Run("fixing it by hand", func(t *testing.T) {
+		gm, code := lobby(t)
+		var settings models.RoomSettings
+		withRoom(t, gm, code, func(room *models.GameRoom) { settings = room.Settings })
+		settings.Composition = map[models.Role]int{models.RoleTiger: 1, models.RoleHunter: 1, models.RoleVillager: 4}
+		if err := gm.UpdateSettings(code, "p1", settings); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if err := gm.StartGame(code, "p1"); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		view, _ := gm.RoomView(code, "")
+		if view.Phase == models.PhaseWaiting || len(view.Players) != 6 {
+			t.Errorf("started in %s with %d players", view.Phase, len(view.Players))
+		}
+	})
+}
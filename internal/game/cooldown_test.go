@@ -0,0 +1,171 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// cooldownGame is a game of ten whose hunter, p2, protects every other
+// night, brought to its first night
+func cooldownGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 10, func(settings *models.RoomSettings) {
+		settings.NightCooldowns = map[models.Role]int{models.RoleHunter: 1}
+	})
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p10")
+	return gm, code
+}
+
+// protectStatus is the hunter's own view of their protection
+func protectStatus(t *testing.T, gm *GameManager, code models.RoomCode) models.AbilityStatus {
+	t.Helper()
+
+	view, _ := gm.RoomView(code, "p2")
+	for _, status := range view.Players["p2"].AbilityStatus {
+		if status.Name == models.AbilityProtect {
+			return status
+		}
+	}
+	t.Fatal("the hunter's view has no protect ability")
+	return models.AbilityStatus{}
+}
+
+// nextNight plays out the rest of the night, then a day that votes out
+// victim
+func nextNight(t *testing.T, gm *GameManager, code models.RoomCode, victim string) {
+	t.Helper()
+
+	playNight(t, gm, code, nil)
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, victim)
+}
+
+func TestCooldownCountsNights(t *testing.T) {
+	gm, code := cooldownGame(t)
+
+	// Night 1: ready
+	prompt := turnOf(t, gm, code, "p2")
+	if prompt.CooldownNights != 0 {
+		t.Fatalf("first night: the prompt says %d nights to wait", prompt.CooldownNights)
+	}
+	if status := protectStatus(t, gm, code); !status.Usable || status.CooldownNights != 0 || status.Remaining != nil {
+		t.Errorf("first night: status = %+v, want usable with no limit", status)
+	}
+	if err := gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken); err != nil {
+		t.Fatalf("protect: %v", err)
+	}
+	nextNight(t, gm, code, "p9")
+
+	// Night 2: resting, though the hunter may still pass
+	prompt = turnOf(t, gm, code, "p2")
+	if prompt.CooldownNights != 1 {
+		t.Errorf("second night: the prompt says %d nights to wait, want 1", prompt.CooldownNights)
+	}
+	if status := protectStatus(t, gm, code); status.Usable || status.CooldownNights != 1 {
+		t.Errorf("second night: status = %+v, want resting a night", status)
+	}
+	err := gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken)
+	var coded *errs.Error
+	if !errors.Is(err, ErrOnCooldown) || !errors.As(err, &coded) ||
+		coded.Params["ability"] != models.AbilityProtect || coded.Params["nightsRemaining"] != 1 {
+		t.Fatalf("protecting while resting: %v, want %s for one night", err, ErrOnCooldown.Code)
+	}
+	if err := gm.SkipNightAction(code, "p2", prompt.TurnToken); err != nil {
+		t.Fatalf("skipping while resting: %v", err)
+	}
+	nextNight(t, gm, code, "p8")
+
+	// Night 3: ready again
+	prompt = turnOf(t, gm, code, "p2")
+	if prompt.CooldownNights != 0 {
+		t.Errorf("third night: the prompt says %d nights to wait", prompt.CooldownNights)
+	}
+	if err := gm.PerformNightAction(code, "p2", "p5", prompt.TurnToken); err != nil {
+		t.Errorf("protecting after the rest: %v", err)
+	}
+}
+
+func TestNoCooldownByDefault(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 10, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleHunter})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p10")
+
+	for night, step := range []struct{ protected, victim string }{{"p4", "p9"}, {"p5", "p8"}} {
+		prompt := turnOf(t, gm, code, "p2")
+		if prompt.CooldownNights != 0 {
+			t.Errorf("night %d: the prompt says %d nights to wait", night+1, prompt.CooldownNights)
+		}
+		if err := gm.PerformNightAction(code, "p2", step.protected, prompt.TurnToken); err != nil {
+			t.Fatalf("night %d: protect: %v", night+1, err)
+		}
+		nextNight(t, gm, code, step.victim)
+	}
+}
+
+func TestCooldownSettings(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+
+	for _, tc := range []struct {
+		name      string
+		cooldowns map[models.Role]int
+		want      map[string]interface{}
+	}{
+		{
+			name:      "a role without a night ability",
+			cooldowns: map[models.Role]int{models.RoleTiger: 1},
+			want:      map[string]interface{}{"field": "nightCooldowns", "role": models.RoleTiger},
+		},
+		{
+			name:      "below once per game",
+			cooldowns: map[models.Role]int{models.RoleShaman: -2},
+			want:      map[string]interface{}{"field": "nightCooldowns", "role": models.RoleShaman, "value": -2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := models.DefaultRoomSettings()
+			settings.NightCooldowns = tc.cooldowns
+			err := gm.UpdateSettings(code, "p1", settings)
+			var coded *errs.Error
+			if !errors.As(err, &coded) || coded.Code != "INVALID_SETTING" {
+				t.Fatalf("err = %v, want INVALID_SETTING", err)
+			}
+			for key, value := range tc.want {
+				if coded.Params[key] != value {
+					t.Errorf("params = %v, want %v", coded.Params, tc.want)
+				}
+			}
+		})
+	}
+
+	// The room's value is what the role is dealt; once per game is allowed
+	settings := models.DefaultRoomSettings()
+	settings.MinDiscussionSeconds = 0
+	settings.QuorumFraction = 0
+	settings.NightCooldowns = map[models.Role]int{models.RoleShaman: 2, models.RoleHunter: models.CooldownForever}
+	if err := gm.UpdateSettings(code, "p1", settings); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleShaman, "p3": models.RoleHunter})
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if vision := room.Players["p2"].Abilities[models.AbilityVision]; vision == nil || vision.CooldownNights != 2 {
+			t.Errorf("the shaman was dealt vision %+v, want a two-night cooldown", vision)
+		}
+		if protect := room.Players["p3"].Abilities[models.AbilityProtect]; protect == nil || protect.CooldownNights != models.CooldownForever {
+			t.Errorf("the hunter was dealt protect %+v, want once per game", protect)
+		}
+	})
+}
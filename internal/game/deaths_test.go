@@ -0,0 +1,130 @@
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// massacre has the tigers kill their target and two more players, which
+// no built-in modifier does yet
+type massacre struct {
+	NopNightModifier
+	extra []string
+}
+
+func (massacre) Key() string               { return "massacre" }
+func (massacre) Announcement() CatalogText { return modifierText("massacre", "") }
+
+func (m massacre) Kills(_ *models.GameRoom, targets []string) []string {
+	return append(targets, m.extra...)
+}
+
+// deathsGame starts an eight-player game and plays its first day, so the
+// next phase is the first night
+func deathsGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+	return gm, code
+}
+
+func deathIDs(result *NightResult) []string {
+	ids := []string{}
+	for _, death := range result.Deaths {
+		ids = append(ids, death.PlayerID)
+	}
+	return ids
+}
+
+func TestNightWithoutDeaths(t *testing.T) {
+	gm, code := deathsGame(t)
+	result := playNight(t, gm, code, map[string]string{"p1": "p4", "p2": "p4"})
+
+	if len(result.Deaths) != 0 || result.Killed != "" {
+		t.Errorf("deaths = %v, killed = %q; want none", result.Deaths, result.Killed)
+	}
+	if !result.Protected {
+		t.Error("the blocked attack was not reported as protected")
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Errorf("night ended in %s, want day", phase)
+	}
+}
+
+func TestNightWithOneDeath(t *testing.T) {
+	gm, code := deathsGame(t)
+	result := playNight(t, gm, code, map[string]string{"p1": "p4", "p2": "p5"})
+
+	want := []Death{{PlayerID: "p4", Username: "Player4", Cause: models.DeathByTiger}}
+	if !reflect.DeepEqual(result.Deaths, want) {
+		t.Errorf("deaths = %v, want %v", result.Deaths, want)
+	}
+
+	// The legacy fields still name the death for older clients
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var legacy struct {
+		Killed     string `json:"killed"`
+		KilledName string `json:"killedName"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		t.Fatal(err)
+	}
+	if legacy.Killed != "p4" || legacy.KilledName != "Player4" {
+		t.Errorf("legacy fields = %+v, want p4", legacy)
+	}
+
+	view, _ := gm.RoomView(code, "")
+	if player := view.Players["p4"]; player.IsAlive || player.DeathCause != models.DeathByTiger {
+		t.Errorf("p4 alive = %v, cause = %q", player.IsAlive, player.DeathCause)
+	}
+}
+
+func TestNightWithThreeDeathsIncludingHunter(t *testing.T) {
+	nightModifiers["massacre"] = massacre{extra: []string{"p2", "p5"}}
+	defer delete(nightModifiers, "massacre")
+
+	gm, code := deathsGame(t)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.NightModifier = "massacre"
+	})
+	result := playNight(t, gm, code, map[string]string{"p1": "p4", "p2": "p6"})
+
+	if got, want := deathIDs(result), []string{"p4", "p2", "p5"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("deaths = %v, want %v", got, want)
+	}
+	if result.Killed != "p4" {
+		t.Errorf("legacy killed = %q, want the first death", result.Killed)
+	}
+
+	// The hunter was not the first to die and still gets to shoot
+	view, _ := gm.RoomView(code, "")
+	if !view.WaitingHunterShoot || view.DeadHunterID != "p2" {
+		t.Fatalf("waiting for hunter = %v (%q), want p2", view.WaitingHunterShoot, view.DeadHunterID)
+	}
+	for _, id := range []string{"p4", "p2", "p5"} {
+		if view.Players[id].IsAlive {
+			t.Errorf("%s survived", id)
+		}
+	}
+
+	if _, err := gm.HunterShoot(code, "p2", "p1", true); err != nil {
+		t.Fatalf("hunter shoots: %v", err)
+	}
+	if ended, winner := gm.CheckGameEnd(code); !ended || winner != "human" {
+		t.Errorf("game ended = %v, winner %q; want the humans to win", ended, winner)
+	}
+}
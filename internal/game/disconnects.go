@@ -0,0 +1,54 @@
+package game
+
+import "github.com/werewolf-game/backend/internal/models"
+
+// maxDisconnectRecords is how many disconnects a room remembers
+const maxDisconnectRecords = 50
+
+// RecordDisconnect remembers why one of the room's connections ended, for
+// support. Only the most recent disconnects are kept, and they go with the room.
+func (gm *GameManager) RecordDisconnect(code models.RoomCode, record models.DisconnectRecord) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	if gm.disconnects == nil {
+		gm.disconnects = make(map[string]int)
+	}
+	gm.disconnects[record.Reason]++
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return
+	}
+
+	room.Disconnects = append(room.Disconnects, record)
+	if extra := len(room.Disconnects) - maxDisconnectRecords; extra > 0 {
+		room.Disconnects = append([]models.DisconnectRecord(nil), room.Disconnects[extra:]...)
+	}
+}
+
+// DisconnectLog returns the room's recent disconnects, oldest first
+func (gm *GameManager) DisconnectLog(code models.RoomCode) ([]models.DisconnectRecord, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	return append([]models.DisconnectRecord{}, room.Disconnects...), nil
+}
+
+// DisconnectCounts returns how many connections have ended for each reason
+// since the server started
+func (gm *GameManager) DisconnectCounts() map[string]int {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	counts := make(map[string]int, len(gm.disconnects))
+	for reason, count := range gm.disconnects {
+		counts[reason] = count
+	}
+	return counts
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// discussionGame is a game of six on its first day, which began elapsed ago
+func discussionGame(t *testing.T, elapsed time.Duration, adjust func(*models.RoomSettings)) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.MinDiscussionSeconds = 30
+		if adjust != nil {
+			adjust(settings)
+		}
+	})
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.DayStartedAt = models.TimestampPtr(time.Now().Add(-elapsed))
+		room.PhaseEndTime = models.TimestampPtr(time.Now().Add(time.Minute))
+	})
+	return gm, code
+}
+
+func TestDiscussionTooShort(t *testing.T) {
+	gm, code := discussionGame(t, 10*time.Second, nil)
+
+	_, err := gm.AdvancePhase(code, "p1")
+	var coded *errs.Error
+	if !errors.As(err, &coded) || coded.Code != "DISCUSSION_TOO_SHORT" || coded.Params["remainingSeconds"] != 20 {
+		t.Fatalf("skip at 10s: %v, want DISCUSSION_TOO_SHORT with 20 seconds remaining", err)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Errorf("phase = %s, want the day to go on", phase)
+	}
+}
+
+func TestDiscussionLongEnough(t *testing.T) {
+	gm, code := discussionGame(t, 31*time.Second, nil)
+	if _, err := gm.AdvancePhase(code, "p1"); err != nil {
+		t.Fatalf("skip at 31s: %v", err)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseVoting {
+		t.Errorf("phase = %s, want the vote", phase)
+	}
+}
+
+func TestNoMinimumDiscussion(t *testing.T) {
+	gm, code := discussionGame(t, 0, func(settings *models.RoomSettings) { settings.MinDiscussionSeconds = 0 })
+	if _, err := gm.AdvancePhase(code, "p1"); err != nil {
+		t.Errorf("skip with the guard off: %v", err)
+	}
+}
+
+func TestDayTimerIgnoresMinimumDiscussion(t *testing.T) {
+	gm, code := discussionGame(t, 5*time.Second, nil)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.PhaseEndTime = models.TimestampPtr(time.Now().Add(-time.Second))
+	})
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Errorf("the day timer running out: %v", err)
+	}
+}
+
+func TestMinimumDiscussionBeforeAccusations(t *testing.T) {
+	gm, code := discussionGame(t, 10*time.Second, func(settings *models.RoomSettings) { settings.FormalAccusations = true })
+
+	_, err := gm.AdvancePhase(code, "p1")
+	var coded *errs.Error
+	if !errors.As(err, &coded) || coded.Code != "DISCUSSION_TOO_SHORT" {
+		t.Errorf("skip to the accusations at 10s: %v, want DISCUSSION_TOO_SHORT", err)
+	}
+}
+
+func TestReadyVillageWaitsOutTheDiscussion(t *testing.T) {
+	gm, code := discussionGame(t, 10*time.Second, nil)
+
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+		gm.SetConnected(code, id, true)
+	}
+	var status ReadyStatus
+	for _, id := range []string{"p1", "p2", "p3", "p4"} {
+		var err error
+		if status, _, err = gm.ToggleReadyToVote(code, id); err != nil {
+			t.Fatalf("%s readies: %v", id, err)
+		}
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Fatalf("a ready majority cut the discussion short: %s", phase)
+	}
+	// The day now ends when the minimum does
+	view, _ := gm.RoomView(code, "")
+	if status.EndsAt == nil || view.PhaseEndTime == nil || !status.EndsAt.Equal(view.PhaseEndTime.Time) ||
+		!view.PhaseEndTime.Equal(view.DayStartedAt.Add(30*time.Second)) {
+		t.Errorf("day ends %v (status %v), want 30s after it began at %v", view.PhaseEndTime, status.EndsAt, view.DayStartedAt)
+	}
+}
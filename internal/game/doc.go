@@ -0,0 +1,27 @@
+// Package game is the rules engine: rooms, role assignment, the phase
+// machine, night resolution, voting and win conditions.
+//
+// It knows nothing about HTTP or websockets. Everything a frontend needs goes
+// through GameManager:
+//
+//   - commands are its methods (JoinRoom, StartGame, Vote, PerformNightAction,
+//     MoveToNextPhase, ...). Rule violations come back as *errs.Error
+//     values with a stable Code where clients need one; compare them to the
+//     Err* sentinels with errors.Is.
+//   - state is read through RoomView / RoomViews, which redact what each
+//     viewer may not see, and CurrentTurnPrompts for whose night turn it is.
+//   - events arrive through RoomObserver callbacks passed to NewGameManager.
+//     They run under the manager's lock, so they must not call back into it.
+//
+// The allowed phase changes and what each phase sets up on entry live in one
+// table, phaseMachine in phases.go; every change goes through it.
+//
+// The engine never runs game timers. Deadlines are stored on the room
+// (PhaseEndTime, TurnEndTime) and the frontend decides when to advance. The
+// only background work is housekeeping the frontend opts into: StartJanitor
+// reclaims idle rooms and StartNightWatch resolves nights stuck past their
+// ceiling.
+//
+// internal/handlers is one frontend; cmd/simulate is a minimal in-process
+// one that plays a whole game importing nothing but this package and models.
+package game
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// wantDuplicate checks err acknowledges a resend of the given action
+func wantDuplicate(t *testing.T, err error, actionType, targetID string) models.ActionRecord {
+	t.Helper()
+
+	var dup *DuplicateActionError
+	if !errors.As(err, &dup) {
+		t.Fatalf("resend: %v, want it acknowledged as a duplicate", err)
+	}
+	if dup.Record.ActionType != actionType || dup.Record.TargetID != targetID {
+		t.Errorf("acked %+v, want the original %s on %s", dup.Record, actionType, targetID)
+	}
+	return dup.Record
+}
+
+// notDuplicate checks err is not a duplicate acknowledgement
+func notDuplicate(t *testing.T, err error) {
+	t.Helper()
+
+	var dup *DuplicateActionError
+	if errors.As(err, &dup) {
+		t.Errorf("a different action was acked as a duplicate of %+v", dup.Record)
+	}
+}
+
+func TestResentVote(t *testing.T) {
+	gm, code := votingGame(t)
+	if err := gm.Vote(code, "p2", "p3"); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+
+	wantDuplicate(t, gm.Vote(code, "p2", "p3"), models.ActionVote, "p3")
+	view, _ := gm.RoomView(code, "")
+	if votes := view.VoteResults["p3"]; votes != 1 {
+		t.Errorf("p3 has %d votes after the resend, want 1", votes)
+	}
+
+	// A different target is a change of mind
+	err := gm.Vote(code, "p2", "p4")
+	notDuplicate(t, err)
+	if err != nil {
+		t.Fatalf("changing the vote: %v", err)
+	}
+	view, _ = gm.RoomView(code, "")
+	if view.VoteResults["p3"] != 0 || view.VoteResults["p4"] != 1 {
+		t.Errorf("votes = %v, want the one vote moved to p4", view.VoteResults)
+	}
+}
+
+func TestResentNightAction(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleHunter, "p3": models.RoleShaman})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+
+	prompt := turnOf(t, gm, code, "p2")
+	if err := gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken); err != nil {
+		t.Fatalf("protect: %v", err)
+	}
+	wantDuplicate(t, gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken), models.ActionProtect, "p4")
+
+	// The turn is spent, so another target is refused, not acked
+	err := gm.PerformNightAction(code, "p2", "p5", prompt.TurnToken)
+	notDuplicate(t, err)
+	if err == nil {
+		t.Error("the hunter changed their protection after their turn")
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.HunterProtection != "p4" {
+			t.Errorf("protection = %q, want p4", room.HunterProtection)
+		}
+	})
+}
+
+func TestResentSkip(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleHunter, "p3": models.RoleShaman})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+
+	prompt := turnOf(t, gm, code, "p2")
+	if err := gm.SkipNightAction(code, "p2", prompt.TurnToken); err != nil {
+		t.Fatalf("skip: %v", err)
+	}
+	wantDuplicate(t, gm.SkipNightAction(code, "p2", prompt.TurnToken), models.ActionSkip, "")
+}
+
+func TestResentCurse(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleAlphaTiger, "p2": models.RoleHunter, "p3": models.RoleShaman})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+
+	prompt := turnOf(t, gm, code, "p1")
+	if err := gm.SetAlphaTigerCurse(code, "p1", "p4", prompt.TurnToken); err != nil {
+		t.Fatalf("curse: %v", err)
+	}
+	wantDuplicate(t, gm.SetAlphaTigerCurse(code, "p1", "p4", prompt.TurnToken), models.ActionCurse, "p4")
+
+	// Cursing someone else is still refused: the curse is spent
+	err := gm.SetAlphaTigerCurse(code, "p1", "p5", prompt.TurnToken)
+	notDuplicate(t, err)
+	if err == nil {
+		t.Error("a second curse was accepted")
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.Players["p5"].IsCursed {
+			t.Error("p5 was cursed by the second attempt")
+		}
+	})
+}
+
+func TestResentHunterShot(t *testing.T) {
+	gm, code := deadHunterGame(t, nil)
+
+	first, err := gm.HunterShoot(code, "p2", "p5", true)
+	if err != nil {
+		t.Fatalf("shoot: %v", err)
+	}
+	record, err := gm.HunterShoot(code, "p2", "p5", true)
+	acked := wantDuplicate(t, err, models.ActionShoot, "p5")
+	if acked.Outcome != first.Outcome || record.Outcome != first.Outcome {
+		t.Errorf("resend acked with outcome %q, want the original %q", acked.Outcome, first.Outcome)
+	}
+
+	// Another target after the shot is the normal refusal
+	_, err = gm.HunterShoot(code, "p2", "p6", true)
+	notDuplicate(t, err)
+	if err == nil {
+		t.Error("the hunter shot twice")
+	}
+	if !isAlive(gm, code, "p6") {
+		t.Error("the second shot killed p6")
+	}
+}
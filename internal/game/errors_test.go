@@ -0,0 +1,135 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestRuleViolationsAreCoded(t *testing.T) {
+	gm := NewGameManager()
+	lobby := newTestRoom(t, gm, 6, nil)
+	playing := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, playing, map[string]models.Role{"p2": models.RoleTiger})
+
+	for _, tc := range []struct {
+		name   string
+		call   func() error
+		want   *errs.Error
+		params map[string]interface{}
+	}{
+		{
+			name: "unknown player",
+			call: func() error { return gm.ChooseColor(lobby, "nobody", 0) },
+			want: ErrPlayerNotFound,
+		},
+		{
+			name: "color slot",
+			call: func() error { return gm.ChooseColor(lobby, "p2", 99) },
+			want: ErrInvalidColorSlot,
+		},
+		{
+			name: "host only",
+			call: func() error {
+				_, err := gm.CreateInvites(lobby, "p2", 1, 0)
+				return err
+			},
+			want:   hostOnlyError("create invites"),
+			params: map[string]interface{}{"action": "create invites"},
+		},
+		{
+			name: "invite count",
+			call: func() error {
+				_, err := gm.CreateInvites(lobby, "p1", 0, 0)
+				return err
+			},
+			want: ErrInvalidInviteCount,
+		},
+		{
+			name: "setting",
+			call: func() error {
+				settings := models.DefaultRoomSettings()
+				settings.Hints = "everything"
+				return gm.UpdateSettings(lobby, "p1", settings)
+			},
+			want:   invalidSetting("hints", nil, ""),
+			params: map[string]interface{}{"field": "hints", "value": "everything"},
+		},
+		{
+			name: "join approval",
+			call: func() error {
+				_, err := gm.RequestJoin(lobby, "late", "Late")
+				return err
+			},
+			want: ErrApprovalNotRequired,
+		},
+		{
+			name: "auto-start",
+			call: func() error { return gm.CancelAutoStart(lobby, "p1") },
+			want: ErrNoAutoStart,
+		},
+		{
+			name: "night preview",
+			call: func() error {
+				_, err := gm.PreviewNight(lobby, "p1", NightActions{})
+				return err
+			},
+			want: ErrNotPracticeRoom,
+		},
+		{
+			name: "vote by day",
+			call: func() error { return gm.Vote(playing, "p3", "p2") },
+			want: ErrNotVotingPhase,
+		},
+		{
+			name: "nomination",
+			call: func() error { return gm.Nominate(playing, "p3", "p2") },
+			want: ErrAccusationsDisabled,
+		},
+		{
+			name: "sleep",
+			call: func() error {
+				_, err := gm.Sleep(playing, "p3")
+				return err
+			},
+			want: ErrSleepConfirmationDisabled,
+		},
+		{
+			name: "night action by day",
+			call: func() error { return gm.PerformNightAction(playing, "p2", "p3", "") },
+			want: ErrNotNightPhase,
+		},
+		{
+			name:   "extension",
+			call:   func() error { return gm.ExtendDiscussion(playing, "p1", time.Hour) },
+			want:   ErrExtensionTooLong,
+			params: map[string]interface{}{"maxSeconds": 120},
+		},
+		{
+			name: "abort vote",
+			call: func() error {
+				_, err := gm.VoteAbort(playing, "p3", true)
+				return err
+			},
+			want: ErrNoAbortVote,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %s", err, tc.want.Code)
+			}
+			coded, ok := errs.Coded(errs.Wrap(err, "in room %s", lobby))
+			if !ok || coded.Code == "" || coded.MessageKey() == "" {
+				t.Fatalf("%v has no code once wrapped", err)
+			}
+			if tc.params != nil && !reflect.DeepEqual(coded.Params, tc.params) {
+				t.Errorf("params = %v, want %v", coded.Params, tc.params)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// firstNightGame is deathsGame with the first-night rule on
+func firstNightGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, func(settings *models.RoomSettings) {
+		settings.FirstNightNoKill = true
+	})
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+	return gm, code
+}
+
+// lastKillOutcome returns how the tiger's latest kill record settled
+func lastKillOutcome(t *testing.T, gm *GameManager, code models.RoomCode, tigerID string) string {
+	t.Helper()
+
+	outcome := ""
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		history := room.Players[tigerID].ActionHistory
+		for i := len(history) - 1; i >= 0; i-- {
+			if history[i].ActionType == models.ActionKill {
+				outcome = history[i].Outcome
+				return
+			}
+		}
+	})
+	return outcome
+}
+
+func TestFirstNightSparesTheVictim(t *testing.T) {
+	gm, code := firstNightGame(t)
+	result := playNight(t, gm, code, map[string]string{"p1": "p4", "p3": "p1"})
+
+	if len(result.Deaths) != 0 || result.Killed != "" {
+		t.Errorf("night one killed %v", deathIDs(result))
+	}
+	if view, _ := gm.RoomView(code, ""); !view.Players["p4"].IsAlive {
+		t.Error("the tigers' target died on night one")
+	}
+	if outcome := lastKillOutcome(t, gm, code, "p1"); outcome != "spared" {
+		t.Errorf("kill record settled %q, want spared", outcome)
+	}
+	if recap := result.Recaps["p1"]; recap.Outcome != RecapKillSpared {
+		t.Errorf("tiger recap = %q, want %s", recap.Outcome, RecapKillSpared)
+	}
+	// Everything but the kill resolves as usual
+	if result.VisionResult != "tiger" {
+		t.Errorf("the shaman saw the tiger as %q", result.VisionResult)
+	}
+	spared := false
+	for _, step := range result.Trace {
+		spared = spared || step.Key == TraceFirstNightSpared
+	}
+	if !spared {
+		t.Error("the trace does not name the first-night rule")
+	}
+}
+
+func TestSecondNightKillsAsUsual(t *testing.T) {
+	gm, code := firstNightGame(t)
+	playNight(t, gm, code, map[string]string{"p1": "p4"})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p7")
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.NightNumber != 2 {
+			t.Fatalf("night number = %d, want 2", room.NightNumber)
+		}
+	})
+	result := playNight(t, gm, code, map[string]string{"p1": "p4"})
+	if ids := deathIDs(result); len(ids) != 1 || ids[0] != "p4" {
+		t.Errorf("night two deaths = %v, want p4", ids)
+	}
+	if outcome := lastKillOutcome(t, gm, code, "p1"); outcome != "killed" {
+		t.Errorf("kill record settled %q, want killed", outcome)
+	}
+	if recap := result.Recaps["p1"]; recap.Outcome != RecapKillSucceeded {
+		t.Errorf("tiger recap = %q, want %s", recap.Outcome, RecapKillSucceeded)
+	}
+}
+
+func TestFirstNightRuleOff(t *testing.T) {
+	gm, code := deathsGame(t)
+	result := playNight(t, gm, code, map[string]string{"p1": "p4"})
+
+	if ids := deathIDs(result); len(ids) != 1 || ids[0] != "p4" {
+		t.Errorf("night one deaths = %v without the rule, want p4", ids)
+	}
+}
+
+func TestNightNumberRestartsWithEachGame(t *testing.T) {
+	gm, code := firstNightGame(t)
+	playNight(t, gm, code, nil)
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.WinningTeam = "human"
+		if err := gm.transitionLocked(room, models.PhaseEnded, ReasonGameOver); err != nil {
+			t.Fatalf("end the game: %v", err)
+		}
+		for _, player := range room.Players {
+			player.IsConnected = true
+		}
+	})
+	if _, err := gm.Rematch(code, "p1"); err != nil {
+		t.Fatalf("rematch: %v", err)
+	}
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.NightNumber != 0 {
+			t.Errorf("the rematch's first day has night number %d", room.NightNumber)
+		}
+	})
+
+	// So the rematch's first night is spared too
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+	if result := playNight(t, gm, code, map[string]string{"p1": "p4"}); len(result.Deaths) != 0 {
+		t.Errorf("the rematch's first night killed %v", deathIDs(result))
+	}
+}
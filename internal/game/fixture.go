@@ -0,0 +1,262 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// FixtureVersion is the schema version RecordFixture writes. Bump it when a
+// hidden field is added and register a migration for the old version.
+const FixtureVersion = 8
+
+// Fixture is a room's complete internal state, including everything the
+// room's own JSON leaves out. It lets tests start from a recorded mid-game
+// position instead of scripting the game from the lobby, and lets a
+// restored room give every returning player their private state back.
+type Fixture struct {
+	// Version is the schema the fixture was recorded with; recordings from
+	// before it existed are version 1
+	Version    int              `json:"version"`
+	RecordedAt models.Timestamp `json:"recordedAt"`
+	Room       *models.GameRoom `json:"room"`
+
+	// Hidden room state
+	Abilities         map[string]map[string]*models.AbilityUse `json:"abilities,omitempty"` // by player ID
+	Invites           map[string]*models.Invite                `json:"invites,omitempty"`
+	NightAcknowledged map[string]bool                          `json:"nightAcknowledged,omitempty"`
+	ChatHistory       []*models.Message                        `json:"chatHistory,omitempty"`
+	SettingsAcks      map[string]bool                          `json:"settingsAcks,omitempty"`
+	NightTraces       []models.NightTrace                      `json:"nightTraces,omitempty"`
+	ReadyToVote       map[string]bool                          `json:"readyToVote,omitempty"`
+	VotesAgainst      map[string][]string                      `json:"votesAgainst,omitempty"`
+	HostActionLog     []models.HostAction                      `json:"hostActionLog,omitempty"`
+	NightCeilingAt    *models.Timestamp                        `json:"nightCeilingAt,omitempty"`
+	Seed              int64                                    `json:"seed"`
+	PhaseStartedAt    *models.Timestamp                        `json:"phaseStartedAt,omitempty"`
+	LastVoteOutcome   *models.VoteOutcome                      `json:"lastVoteOutcome,omitempty"`
+	PendingPrompts    map[string][]models.PendingPrompt        `json:"pendingPrompts,omitempty"` // by player ID
+	AbortVotes        map[string]bool                          `json:"abortVotes,omitempty"`
+	AbortStartedAt    *models.Timestamp                        `json:"abortStartedAt,omitempty"`
+	HunterSelection   string                                   `json:"hunterSelection,omitempty"`
+	AutoStartHeld     bool                                     `json:"autoStartHeld,omitempty"`
+	ConcealedDeaths   []string                                 `json:"concealedDeaths,omitempty"`
+	RevealedDeaths    []string                                 `json:"revealedDeaths,omitempty"`
+	Sessions          map[string]string                        `json:"sessions,omitempty"`        // session tokens by player ID
+	HandledRequests   map[string][]models.HandledRequest       `json:"handledRequests,omitempty"` // by player ID
+}
+
+// fixtureMigrations bring a fixture from the version it is keyed by up to
+// the next one
+var fixtureMigrations = map[int]func(*Fixture){
+	// Version 1 didn't record pending prompts or abort answers. A hunter
+	// still waiting to shoot is asked again; abort answers are lost.
+	1: func(fixture *Fixture) {
+		room := fixture.Room
+		if room.WaitingHunterShoot && room.DeadHunterID != "" {
+			fixture.PendingPrompts = map[string][]models.PendingPrompt{
+				room.DeadHunterID: {{Type: models.EventHunterPrompt}},
+			}
+		}
+	},
+	// Version 2 didn't record the hunter's unconfirmed selection; the hunter
+	// selects again
+	2: func(*Fixture) {},
+	// Version 3 counted abilities against a use limit, and every limited
+	// ability was single-use; they now rest forever after their one use
+	3: func(fixture *Fixture) {
+		for _, abilities := range fixture.Abilities {
+			for _, ability := range abilities {
+				ability.CooldownNights = models.CooldownForever
+			}
+		}
+	},
+	// Version 4 didn't record a host cancelling the auto-start; the
+	// countdown may run again
+	4: func(*Fixture) {},
+	// Version 5 predates mystery dawn, so no death was ever concealed
+	5: func(*Fixture) {},
+	// Version 6 predates session tokens. Every player gets a new one, so
+	// clients from before the recording have to join again.
+	6: func(fixture *Fixture) {
+		fixture.Sessions = make(map[string]string, len(fixture.Room.Players))
+		for id := range fixture.Room.Players {
+			fixture.Sessions[id] = newSessionToken()
+		}
+	},
+	// Version 7 didn't record handled requestIds, so a request retried
+	// after the restore is applied as new
+	7: func(*Fixture) {},
+}
+
+// migrateFixture upgrades a fixture to FixtureVersion
+func migrateFixture(fixture *Fixture) error {
+	if fixture.Version == 0 {
+		fixture.Version = 1
+	}
+	if fixture.Version > FixtureVersion {
+		return fmt.Errorf("fixture version %d is newer than %d", fixture.Version, FixtureVersion)
+	}
+	for fixture.Version < FixtureVersion {
+		migrate, ok := fixtureMigrations[fixture.Version]
+		if !ok {
+			return fmt.Errorf("no migration from fixture version %d", fixture.Version)
+		}
+		migrate(fixture)
+		fixture.Version++
+	}
+	return nil
+}
+
+// RecordFixture dumps a room's full internal state as indented JSON
+func (gm *GameManager) RecordFixture(code models.RoomCode) ([]byte, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	fixture := Fixture{
+		Version:           FixtureVersion,
+		RecordedAt:        models.NewTimestamp(time.Now()),
+		Room:              room,
+		Abilities:         make(map[string]map[string]*models.AbilityUse, len(room.Players)),
+		Invites:           room.Invites,
+		NightAcknowledged: room.NightAcknowledged,
+		ChatHistory:       room.ChatHistory,
+		SettingsAcks:      room.SettingsAcks,
+		NightTraces:       room.NightTraces,
+		ReadyToVote:       room.ReadyToVote,
+		VotesAgainst:      room.VotesAgainst,
+		HostActionLog:     room.HostActionLog,
+		NightCeilingAt:    room.NightCeilingAt,
+		Seed:              room.Seed,
+		PhaseStartedAt:    room.PhaseStartedAt,
+		LastVoteOutcome:   room.LastVoteOutcome,
+		HunterSelection:   room.HunterSelection,
+		PendingPrompts:    room.PendingPrompts,
+		AutoStartHeld:     room.AutoStartHeld,
+		ConcealedDeaths:   room.ConcealedDeaths,
+		RevealedDeaths:    room.RevealedDeaths,
+		Sessions:          make(map[string]string, len(room.Players)),
+		HandledRequests:   make(map[string][]models.HandledRequest),
+	}
+	for id, player := range room.Players {
+		fixture.Abilities[id] = player.Abilities
+		fixture.Sessions[id] = player.SessionToken
+		if len(player.HandledRequests) > 0 {
+			fixture.HandledRequests[id] = player.HandledRequests
+		}
+	}
+	if room.AbortVote != nil {
+		fixture.AbortVotes = room.AbortVote.Votes
+		fixture.AbortStartedAt = models.TimestampPtr(room.AbortVote.StartedAt)
+	}
+
+	return json.MarshalIndent(fixture, "", "  ")
+}
+
+// LoadFixture adds a recorded room to the manager and returns its code.
+// Deadlines are moved forward by the time since recording, so timers
+// resume with what they had left. Rooms breaking GameRoom.Validate are refused.
+func (gm *GameManager) LoadFixture(data []byte) (models.RoomCode, error) {
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return "", fmt.Errorf("decode fixture: %w", err)
+	}
+
+	room := fixture.Room
+	if room == nil || room.Code == "" {
+		return "", fmt.Errorf("fixture has no room")
+	}
+	if err := migrateFixture(&fixture); err != nil {
+		return "", err
+	}
+	if room.Players == nil {
+		room.Players = make(map[string]*models.Player)
+	}
+
+	room.Invites = fixture.Invites
+	room.NightAcknowledged = fixture.NightAcknowledged
+	room.ChatHistory = fixture.ChatHistory
+	if n := len(room.ChatHistory); n > 0 {
+		room.ChatSeq = room.ChatHistory[n-1].Seq
+	}
+	room.SettingsAcks = fixture.SettingsAcks
+	room.NightTraces = fixture.NightTraces
+	room.ReadyToVote = fixture.ReadyToVote
+	room.VotesAgainst = fixture.VotesAgainst
+	room.HostActionLog = fixture.HostActionLog
+	room.NightCeilingAt = fixture.NightCeilingAt
+	room.Seed = fixture.Seed
+	room.PhaseStartedAt = fixture.PhaseStartedAt
+	room.LastVoteOutcome = fixture.LastVoteOutcome
+	room.HunterSelection = fixture.HunterSelection
+	room.AutoStartHeld = fixture.AutoStartHeld
+	room.ConcealedDeaths = fixture.ConcealedDeaths
+	room.RevealedDeaths = fixture.RevealedDeaths
+	room.PendingPrompts = fixture.PendingPrompts
+	if room.AbortVote != nil {
+		room.AbortVote.Votes = fixture.AbortVotes
+		if room.AbortVote.Votes == nil {
+			room.AbortVote.Votes = make(map[string]bool)
+		}
+		if fixture.AbortStartedAt != nil {
+			room.AbortVote.StartedAt = fixture.AbortStartedAt.Time
+		}
+	}
+	// Nobody is connected to a freshly loaded room; their absence counts from now
+	loadedAt := time.Now()
+	for id, player := range room.Players {
+		player.Abilities = fixture.Abilities[id]
+		player.SessionToken = fixture.Sessions[id]
+		player.HandledRequests = fixture.HandledRequests[id]
+		player.Connections = 0
+		player.IsConnected = false
+		player.DisconnectedAt = &loadedAt
+	}
+
+	// Recover timers
+	now := time.Now()
+	if !fixture.RecordedAt.IsZero() {
+		elapsed := now.Sub(fixture.RecordedAt.Time)
+		for _, t := range []*models.Timestamp{room.PhaseEndTime, room.TurnEndTime, room.DayStartedAt, room.SettingsChangedAt, room.NightCeilingAt, room.PhaseStartedAt, room.HunterShotEndsAt, room.AutoStartAt, room.BodyRevealAt} {
+			if t != nil {
+				t.Time = t.Add(elapsed)
+			}
+		}
+		for _, invite := range room.Invites {
+			if invite.ExpiresAt != nil {
+				invite.ExpiresAt.Time = invite.ExpiresAt.Add(elapsed)
+			}
+		}
+		if room.AbortVote != nil {
+			room.AbortVote.EndsAt.Time = room.AbortVote.EndsAt.Add(elapsed)
+			room.AbortVote.StartedAt = room.AbortVote.StartedAt.Add(elapsed)
+		}
+		shiftPromptsLocked(room, elapsed)
+	}
+	room.LastActivityAt = now
+
+	if problems := room.Validate(); len(problems) > 0 {
+		return "", fmt.Errorf("fixture room %s is invalid: %s", room.Code, strings.Join(problems, "; "))
+	}
+
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room.Code = models.NormalizeRoomCode(string(room.Code))
+	if _, exists := gm.Rooms[room.Code]; exists {
+		return "", fmt.Errorf("room %s already exists", room.Code)
+	}
+	gm.Rooms[room.Code] = room
+	gm.beginLifecycleLocked(room.Code)
+	gm.indexAliasesLocked(room)
+
+	return room.Code, nil
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// restore records the room and loads it into a fresh manager, as a restart would
+func restore(t *testing.T, gm *GameManager, code models.RoomCode) *GameManager {
+	t.Helper()
+
+	data, err := gm.RecordFixture(code)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	restored := NewGameManager()
+	if _, err := restored.LoadFixture(data); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return restored
+}
+
+// privateState is everything a player keeps to themselves, encoded so it
+// compares across the clock's monotonic readings
+func privateState(t *testing.T, gm *GameManager, code models.RoomCode, playerID string) string {
+	t.Helper()
+
+	var state struct {
+		Player    *models.Player
+		Abilities map[string]*models.AbilityUse
+		Session   string
+		Prompts   []string
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		player := *room.Players[playerID]
+		// A restored room has nobody connected
+		player.IsConnected, player.Connections, player.DisconnectedAt = false, 0, nil
+		state.Player = &player
+		state.Abilities = player.Abilities
+		state.Session = player.SessionToken
+	})
+	for _, prompt := range gm.PendingPrompts(code, playerID) {
+		state.Prompts = append(state.Prompts, prompt.Type)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("encode %s: %v", playerID, err)
+	}
+	return string(data)
+}
+
+func TestShamanPrivateStateSurvivesARestore(t *testing.T) {
+	gm, code := deathsGame(t)
+	playNight(t, gm, code, map[string]string{"p1": "p4", "p3": "p1"})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p7")
+	turn := turnOf(t, gm, code, "p3")
+
+	before := privateState(t, gm, code, "p3")
+	restored := restore(t, gm, code)
+	if after := privateState(t, restored, code, "p3"); after != before {
+		t.Errorf("the shaman's private state changed across the restore:\nbefore %s\nafter  %s", before, after)
+	}
+
+	// Back on the same session, the shaman sees their role and finishes their turn
+	token, _ := gm.SessionToken(code, "p3")
+	if err := restored.Authenticate(code, "p3", token); err != nil {
+		t.Fatalf("the shaman's session did not survive: %v", err)
+	}
+	view, _ := restored.RoomView(code, "p3")
+	if view.Players["p3"].Role != models.RoleShaman {
+		t.Errorf("the shaman sees their role as %q", view.Players["p3"].Role)
+	}
+	if err := restored.PerformNightAction(code, "p3", "p2", turn.TurnToken); err != nil {
+		t.Errorf("the shaman's turn did not survive: %v", err)
+	}
+}
+
+func TestFixtureMigrations(t *testing.T) {
+	gm, code := deathsGame(t)
+	playNight(t, gm, code, map[string]string{"p1": "p2"})
+	data, err := gm.RecordFixture(code)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	// downgrade rewrites the recording as an older version would have had it
+	downgrade := func(version int, drop ...string) []byte {
+		var fixture map[string]interface{}
+		json.Unmarshal(data, &fixture)
+		fixture["version"] = version
+		for _, field := range drop {
+			delete(fixture, field)
+		}
+		old, _ := json.Marshal(fixture)
+		return old
+	}
+
+	t.Run("version 1 asks the hunter again", func(t *testing.T) {
+		restored := NewGameManager()
+		if _, err := restored.LoadFixture(downgrade(1, "pendingPrompts", "sessions")); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		prompts := restored.PendingPrompts(code, "p2")
+		if len(prompts) != 1 || prompts[0].Type != models.EventHunterPrompt {
+			t.Errorf("the waiting hunter has prompts %+v, want the shot", prompts)
+		}
+	})
+
+	t.Run("version 6 mints sessions", func(t *testing.T) {
+		restored := NewGameManager()
+		if _, err := restored.LoadFixture(downgrade(6, "sessions")); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		seen := make(map[string]bool)
+		for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8"} {
+			token, ok := restored.SessionToken(code, id)
+			if !ok || token == "" || seen[token] {
+				t.Errorf("%s has session %q", id, token)
+			}
+			seen[token] = true
+		}
+	})
+
+	t.Run("local-zone times load as the same instant", func(t *testing.T) {
+		// Before Timestamp, times were written in the server's own zone
+		bangkok := time.FixedZone("ICT", 7*60*60)
+		utc := regexp.MustCompile(`"(\d{4}-\d\d-\d\dT[^"]*Z)"`)
+		local := utc.ReplaceAllStringFunc(string(data), func(quoted string) string {
+			at, err := time.Parse(time.RFC3339Nano, strings.Trim(quoted, `"`))
+			if err != nil {
+				t.Fatalf("recorded %s: %v", quoted, err)
+			}
+			return `"` + at.In(bangkok).Format(time.RFC3339Nano) + `"`
+		})
+		if local == string(data) {
+			t.Fatal("the recording has no times")
+		}
+
+		restored := NewGameManager()
+		if _, err := restored.LoadFixture([]byte(local)); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		// Compared with the recording as written, which kept milliseconds
+		withRoom(t, restore(t, gm, code), code, func(room *models.GameRoom) {
+			withRoom(t, restored, code, func(loaded *models.GameRoom) {
+				if !loaded.CreatedAt.Equal(room.CreatedAt.Time) || !loaded.StartedAt.Equal(room.StartedAt.Time) {
+					t.Errorf("created %v, started %v; want %v, %v", loaded.CreatedAt, loaded.StartedAt, room.CreatedAt, room.StartedAt)
+				}
+				if !loaded.Players["p1"].JoinedAt.Equal(room.Players["p1"].JoinedAt.Time) {
+					t.Errorf("p1 joined %v, want %v", loaded.Players["p1"].JoinedAt, room.Players["p1"].JoinedAt)
+				}
+			})
+		})
+
+		// Recorded again, the times are written in UTC
+		again, err := restored.RecordFixture(code)
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		if strings.Contains(string(again), "+07:00") {
+			t.Error("the restored room was recorded with local-zone times")
+		}
+	})
+
+	t.Run("a newer version is refused", func(t *testing.T) {
+		if _, err := NewGameManager().LoadFixture(downgrade(FixtureVersion + 1)); err == nil {
+			t.Error("a fixture from a newer schema was loaded")
+		}
+	})
+}
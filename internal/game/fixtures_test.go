@@ -0,0 +1,206 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/testutil"
+)
+
+func TestHunterShotEndsGame(t *testing.T) {
+	gm, code := testutil.LoadFixture(t, testutil.FixturePath("hunter_shot_pending.json"))
+
+	if _, err := gm.HunterShoot(code, "s", "a", true); err == nil {
+		t.Error("the shaman was allowed to shoot")
+	}
+	if _, err := gm.HunterShoot(code, "h", "t", true); err == nil {
+		t.Error("the hunter shot a dead player")
+	}
+
+	record, err := gm.HunterShoot(code, "h", "a", true)
+	if err != nil {
+		t.Fatalf("hunter shoots the alpha tiger: %v", err)
+	}
+	if record.TargetID != "a" || record.Outcome != "killed" {
+		t.Errorf("shot record = %+v", record)
+	}
+
+	view, _ := gm.RoomView(code, "")
+	if view.Phase != models.PhaseEnded || view.WinningTeam != "human" {
+		t.Errorf("phase %s, winner %q; want the humans to win", view.Phase, view.WinningTeam)
+	}
+	if cause := view.Players["a"].DeathCause; cause != models.DeathByHunter {
+		t.Errorf("alpha tiger's death cause = %q", cause)
+	}
+}
+
+func TestHunterShotGameGoesOn(t *testing.T) {
+	gm, code := testutil.LoadFixture(t, testutil.FixturePath("hunter_shot_pending.json"))
+
+	if _, err := gm.HunterShoot(code, "h", "v1", true); err != nil {
+		t.Fatalf("hunter shoots a villager: %v", err)
+	}
+	view, _ := gm.RoomView(code, "")
+	if view.WaitingHunterShoot || view.DeadHunterID != "" {
+		t.Error("the room still waits for the hunter")
+	}
+	if _, err := gm.HunterShoot(code, "h", "s", true); err == nil {
+		t.Error("the hunter shot twice")
+	}
+
+	// The night then ends as usual, with nobody else dying
+	result, err := gm.MoveToNextPhase(code)
+	if err != nil {
+		t.Fatalf("end the night: %v", err)
+	}
+	if len(result.Deaths) != 0 {
+		t.Errorf("the resumed night killed %v", result.Deaths)
+	}
+	if phase, _ := gm.RoomPhase(code); phase != models.PhaseDay {
+		t.Errorf("phase = %s, want day", phase)
+	}
+}
+
+func TestHunterShotWindowIsNotCutShort(t *testing.T) {
+	gm, code := testutil.LoadFixture(t, testutil.FixturePath("hunter_shot_pending.json"))
+
+	if err := gm.TimeoutHunterShot(code); err == nil {
+		t.Error("the shot timed out before its window closed")
+	}
+	if _, err := gm.HunterShoot(code, "h", "a", true); err != nil {
+		t.Errorf("the hunter could not shoot within their window: %v", err)
+	}
+}
+
+func TestSpentHunterCannotShoot(t *testing.T) {
+	gm, code := testutil.LoadFixture(t, testutil.FixturePath("day_hunter_dead.json"))
+
+	if _, err := gm.HunterShoot(code, "h", "a", true); err == nil {
+		t.Error("a hunter who already shot fired again")
+	}
+}
+
+func TestFinalVoteEndsGame(t *testing.T) {
+	gm, code := testutil.LoadFixture(t, testutil.FixturePath("final_vote.json"))
+
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("count the votes: %v", err)
+	}
+	summary, err := gm.GameSummary(code)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if summary.WinningTeam != "human" {
+		t.Errorf("winner = %q, want human", summary.WinningTeam)
+	}
+	for _, player := range summary.Players {
+		if player.ID == "a" && (player.IsAlive || player.DeathCause != models.DeathByVote) {
+			t.Errorf("alpha tiger alive = %v, cause %q", player.IsAlive, player.DeathCause)
+		}
+	}
+}
+
+func TestTigersWinAtParity(t *testing.T) {
+	gm, code := testutil.LoadFixture(t, testutil.FixturePath("day_three_left.json"))
+
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("end the day: %v", err)
+	}
+	for _, ballot := range [][2]string{{"a", "s"}, {"v2", "s"}, {"s", "a"}} {
+		if err := gm.Vote(code, ballot[0], ballot[1]); err != nil {
+			t.Fatalf("%s votes for %s: %v", ballot[0], ballot[1], err)
+		}
+	}
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("count the votes: %v", err)
+	}
+
+	if ended, winner := gm.CheckGameEnd(code); !ended || winner != "tiger" {
+		t.Errorf("ended = %v, winner %q; want the tigers to win one on one", ended, winner)
+	}
+}
+
+func TestEndedGameRevealsEverything(t *testing.T) {
+	gm, code := testutil.LoadFixture(t, testutil.FixturePath("ended_human_win.json"))
+
+	view, _ := gm.RoomView(code, "")
+	for id, player := range view.Players {
+		if player.Role == "" {
+			t.Errorf("%s's role is still hidden", id)
+		}
+	}
+	if err := gm.Vote(code, "s", "v2"); err == nil {
+		t.Error("a vote was taken after the game ended")
+	}
+	if _, err := gm.HunterShoot(code, "h", "s", true); err == nil {
+		t.Error("a shot was taken after the game ended")
+	}
+	if _, err := gm.GameSummary(code); err != nil {
+		t.Errorf("summary: %v", err)
+	}
+}
+
+func TestVisionAfterTheCurse(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "a", want: "tiger"},  // the alpha tiger no longer hides once the curse is spent
+		{target: "v2", want: "tiger"}, // the cursed villager looks like a tiger
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			gm, code := testutil.LoadFixture(t, testutil.FixturePath("night_curse_spent.json"))
+
+			prompts, _ := gm.CurrentTurnPrompts(code)
+			prompt, ok := prompts["s"]
+			if !ok {
+				t.Fatal("it is not the shaman's turn")
+			}
+			if err := gm.PerformNightAction(code, "s", tt.target, prompt.TurnToken); err != nil {
+				t.Fatalf("shaman looks at %s: %v", tt.target, err)
+			}
+			if done, err := gm.MoveToNextNightRole(code); err != nil || !done {
+				t.Fatalf("next night role: done = %v, err = %v", done, err)
+			}
+			result, err := gm.MoveToNextPhase(code)
+			if err != nil {
+				t.Fatalf("end the night: %v", err)
+			}
+
+			if result.VisionResult != tt.want {
+				t.Errorf("vision of %s = %q, want %q", tt.target, result.VisionResult, tt.want)
+			}
+			if len(result.Deaths) != 0 {
+				t.Errorf("the curse night killed %v", result.Deaths)
+			}
+		})
+	}
+}
+
+// Every curated fixture loads and stays valid
+func TestFixturesLoad(t *testing.T) {
+	for _, name := range []string{
+		"day_hunter_dead.json",
+		"day_three_left.json",
+		"ended_human_win.json",
+		"final_vote.json",
+		"hunter_shot_pending.json",
+		"night_curse_spent.json",
+	} {
+		t.Run(name, func(t *testing.T) {
+			gm, code := testutil.LoadFixture(t, testutil.FixturePath(name))
+			data, err := gm.RecordFixture(code)
+			if err != nil {
+				t.Fatalf("record: %v", err)
+			}
+
+			// A recording loads back into a fresh manager
+			if _, err := game.NewGameManager().LoadFixture(data); err != nil {
+				t.Errorf("reload: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// newTestRoom creates a lobby of n players, p1 to pn, hosted by p1. Its
+// settings let a test end phases at will: there is no minimum discussion
+// and no quorum, since nobody holds a connection. adjust may change them further.
+func newTestRoom(t testing.TB, gm *GameManager, n int, adjust func(*models.RoomSettings)) models.RoomCode {
+	t.Helper()
+
+	gm.DebugValidate = true
+	gm.SettingsAckCooldown = 0
+
+	settings := models.DefaultRoomSettings()
+	settings.MinDiscussionSeconds = 0
+	settings.QuorumFraction = 0
+	settings.MaxPlayers = maxPlayersLimit
+	if adjust != nil {
+		adjust(&settings)
+	}
+
+	room, err := gm.CreateRoom("p1", "Player1", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for i := 2; i <= n; i++ {
+		if _, err := gm.JoinRoom(room.Code, fmt.Sprintf("p%d", i), fmt.Sprintf("Player%d", i)); err != nil {
+			t.Fatalf("join p%d: %v", i, err)
+		}
+	}
+	return room.Code
+}
+
+// startTestGame starts the game and deals the given roles in place of the
+// random ones; everyone left out is a villager
+func startTestGame(t testing.TB, gm *GameManager, code models.RoomCode, roles map[string]models.Role) {
+	t.Helper()
+
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for _, player := range room.Players {
+			role, ok := roles[player.ID]
+			if !ok {
+				role = models.RoleVillager
+			}
+			room.SetRole(player, role)
+			player.Abilities = roleAbilities(role, room.Settings)
+		}
+		// The first dawn's count was taken from the deal just replaced
+		tigers := livingTigersLocked(room)
+		room.TigersRemaining = &tigers
+	})
+}
+
+// withRoom runs fn on the room while holding the manager lock
+func withRoom(t testing.TB, gm *GameManager, code models.RoomCode, fn func(room *models.GameRoom)) {
+	t.Helper()
+
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		t.Fatalf("room %s not found", code)
+	}
+	fn(room)
+}
+
+// phaseOf returns the room's current phase
+func phaseOf(t testing.TB, gm *GameManager, code models.RoomCode) models.GamePhase {
+	t.Helper()
+
+	phase, ok := gm.RoomPhase(code)
+	if !ok {
+		t.Fatalf("room %s not found", code)
+	}
+	return phase
+}
+
+// nextPhase ends the current phase and returns the night's result, if any
+func nextPhase(t testing.TB, gm *GameManager, code models.RoomCode) *NightResult {
+	t.Helper()
+
+	result, err := gm.MoveToNextPhase(code)
+	if err != nil {
+		t.Fatalf("end %s: %v", phaseOf(t, gm, code), err)
+	}
+	return result
+}
+
+// voteOut has every living player vote for targetID, who votes for the
+// first other living player, then counts the votes
+func voteOut(t testing.TB, gm *GameManager, code models.RoomCode, targetID string) {
+	t.Helper()
+
+	if phase := phaseOf(t, gm, code); phase != models.PhaseVoting {
+		t.Fatalf("vote out %s during %s", targetID, phase)
+	}
+	view, _ := gm.RoomView(code, "")
+	for _, voter := range view.LivingPlayers() {
+		choice := targetID
+		if voter.ID == targetID {
+			for _, other := range view.LivingPlayers() {
+				if other.ID != targetID {
+					choice = other.ID
+					break
+				}
+			}
+		}
+		if err := gm.Vote(code, voter.ID, choice); err != nil {
+			t.Fatalf("%s votes for %s: %v", voter.ID, choice, err)
+		}
+	}
+	nextPhase(t, gm, code)
+}
+
+// playNight plays every night turn, then resolves the night
+func playNight(t testing.TB, gm *GameManager, code models.RoomCode, actions map[string]string) *NightResult {
+	t.Helper()
+
+	playTurns(t, gm, code, actions)
+	return nextPhase(t, gm, code)
+}
+
+// playTurns plays every night turn in order, each turn holder acting on the
+// target actions gives them and skipping otherwise. It reports whether the
+// night then had nothing left to wait for.
+func playTurns(t testing.TB, gm *GameManager, code models.RoomCode, actions map[string]string) bool {
+	t.Helper()
+
+	if phase := phaseOf(t, gm, code); phase != models.PhaseNight {
+		t.Fatalf("play a night during %s", phase)
+	}
+	for step := 0; ; step++ {
+		if step > 10 {
+			t.Fatal("the night's turns never finished")
+		}
+		prompts, _ := gm.CurrentTurnPrompts(code)
+		for playerID, prompt := range prompts {
+			if target, ok := actions[playerID]; ok {
+				if err := gm.PerformNightAction(code, playerID, target, prompt.TurnToken); err != nil {
+					t.Fatalf("%s acts on %s: %v", playerID, target, err)
+				}
+				continue
+			}
+			if err := gm.SkipNightAction(code, playerID, prompt.TurnToken); err != nil {
+				t.Fatalf("%s skips: %v", playerID, err)
+			}
+		}
+
+		done, err := gm.MoveToNextNightRole(code)
+		if err != nil {
+			t.Fatalf("next night role: %v", err)
+		}
+		if done {
+			return true
+		}
+		if role, _ := gm.GetCurrentNightRole(code); role == "" {
+			return false
+		}
+	}
+}
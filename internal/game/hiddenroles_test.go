@@ -0,0 +1,86 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// deadShamanGame is deathsGame after a night in which the shaman looked at
+// the tiger and was killed, with roles hard-hidden or not
+func deadShamanGame(t *testing.T, hardHidden bool) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm, code := deathsGame(t)
+	withRoom(t, gm, code, func(room *models.GameRoom) { room.Settings.HardHiddenRoles = hardHidden })
+	result := playNight(t, gm, code, map[string]string{"p1": "p3", "p2": "p4", "p3": "p1"})
+	if deaths := deathIDs(result); len(deaths) != 1 || deaths[0] != "p3" {
+		t.Fatalf("deaths = %v, want the shaman", deaths)
+	}
+	return gm, code
+}
+
+func TestHardHiddenRolesFromTheDead(t *testing.T) {
+	gm, code := deadShamanGame(t, true)
+
+	view, _ := gm.RoomView(code, "p3")
+	for _, id := range []string{"p1", "p2", "p4"} {
+		if role := view.Players[id].Role; role != "" {
+			t.Errorf("the dead shaman sees %s as %s", id, role)
+		}
+	}
+	// What the shaman was and learned stays theirs
+	shaman := view.Players["p3"]
+	if shaman.Role != models.RoleShaman {
+		t.Errorf("own role = %q, want the shaman", shaman.Role)
+	}
+	var sawTiger bool
+	for _, record := range shaman.ActionHistory {
+		sawTiger = sawTiger || (record.Phase == models.PhaseNight && record.TargetID == "p1")
+	}
+	if !sawTiger {
+		t.Errorf("action history = %+v, want the vision of p1", shaman.ActionHistory)
+	}
+
+	// There is no dead chat to talk in or read
+	if _, err := gm.PostChat(code, "p3", "it was p1"); !errors.Is(err, ErrDeadChatDisabled) {
+		t.Errorf("dead chat: %v, want %s", err, ErrDeadChatDisabled.Code)
+	}
+}
+
+func TestDeadSeeRolesWhenNotHardHidden(t *testing.T) {
+	gm, code := deadShamanGame(t, false)
+
+	view, _ := gm.RoomView(code, "p3")
+	if view.Players["p1"].Role != models.RoleTiger || view.Players["p2"].Role != models.RoleHunter {
+		t.Errorf("the dead shaman sees p1 as %q and p2 as %q", view.Players["p1"].Role, view.Players["p2"].Role)
+	}
+
+	message, err := gm.PostChat(code, "p3", "it was p1")
+	if err != nil {
+		t.Fatalf("dead chat: %v", err)
+	}
+	if message.Type != models.MessageDead {
+		t.Errorf("type = %q, want dead chat", message.Type)
+	}
+	for _, seen := range gm.ChatHistory(code, "p4") {
+		if seen.ID == message.ID {
+			t.Error("the living read the dead chat")
+		}
+	}
+}
+
+func TestHardHiddenRolesRevealedAtTheEnd(t *testing.T) {
+	gm, code := deadShamanGame(t, true)
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p1")
+
+	if phase := phaseOf(t, gm, code); phase != models.PhaseEnded {
+		t.Fatalf("phase = %s, want the game over", phase)
+	}
+	view, _ := gm.RoomView(code, "p3")
+	if view.Players["p1"].Role != models.RoleTiger || view.Players["p2"].Role != models.RoleHunter {
+		t.Errorf("at the end the shaman sees p1 as %q and p2 as %q", view.Players["p1"].Role, view.Players["p2"].Role)
+	}
+}
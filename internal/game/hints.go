@@ -0,0 +1,84 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// PromptTarget is one player a night prompt lets its holder choose. The
+// hint flags are only set in rooms with HintsBasic, and only ever restate
+// what the holder already knows: their own past actions, and ballots the
+// room revealed to everyone.
+type PromptTarget struct {
+	ID                       string `json:"id"`
+	Username                 string `json:"username"`
+	PreviouslyProtected      bool   `json:"previouslyProtected,omitempty"`      // ผู้เล่นคนนี้เคยปกป้องเป้าหมายนี้ในคืนก่อน ๆ
+	PreviouslyInspected      bool   `json:"previouslyInspected,omitempty"`      // ผู้เล่นคนนี้เคยส่องเป้าหมายนี้แล้ว
+	VotedAgainstYouLastRound bool   `json:"votedAgainstYouLastRound,omitempty"` // เป้าหมายโหวตใส่ผู้เล่นในวันล่าสุด (เฉพาะห้องที่เปิดเผยผลโหวต)
+}
+
+// nightTargetsLocked lists who the player may choose tonight, decorated
+// with hints when the room asks for them
+func nightTargetsLocked(room *models.GameRoom, player *models.Player) []PromptTarget {
+	targets := []PromptTarget{}
+	for _, target := range room.Players {
+		if target.ID == player.ID {
+			continue
+		}
+		actionType := nightActionOnLocked(room, player, target.ID)
+		if !target.IsAlive && actionType != models.ActionInspect {
+			continue
+		}
+		if actionType == models.ActionProtect && player.LastProtected == target.ID {
+			continue
+		}
+		targets = append(targets, PromptTarget{ID: target.ID, Username: target.Username})
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].ID < targets[j].ID })
+
+	if room.Settings.Hints == models.HintsBasic {
+		hintTargetsLocked(room, player, targets)
+	}
+	return targets
+}
+
+// hintTargetsLocked flags targets with what the player knows about them.
+// It reads nothing the player could not see in their own view.
+func hintTargetsLocked(room *models.GameRoom, player *models.Player, targets []PromptTarget) {
+	protected := make(map[string]bool)
+	inspected := make(map[string]bool)
+	for _, record := range player.ActionHistory {
+		switch record.ActionType {
+		case models.ActionProtect:
+			protected[record.TargetID] = true
+		case models.ActionVision, models.ActionInspect:
+			inspected[record.TargetID] = true
+		}
+	}
+
+	votedAgainst := votedAgainstLastRoundLocked(room, player.ID)
+	for i := range targets {
+		targets[i].PreviouslyProtected = protected[targets[i].ID]
+		targets[i].PreviouslyInspected = inspected[targets[i].ID]
+		targets[i].VotedAgainstYouLastRound = votedAgainst[targets[i].ID]
+	}
+}
+
+// votedAgainstLastRoundLocked returns who voted for the player in the day
+// vote just before this night; a day that ended without votes flags no one.
+// Anonymous ballots name no voters, so it is empty unless the room reveals
+// votes.
+func votedAgainstLastRoundLocked(room *models.GameRoom, playerID string) map[string]bool {
+	voters := make(map[string]bool)
+	if !room.Settings.RevealVotes {
+		return voters
+	}
+
+	for _, ballot := range room.VoteHistory {
+		if ballot.Round == room.Round && ballot.TargetID == playerID && ballot.VoterID != "" {
+			voters[ballot.VoterID] = true
+		}
+	}
+	return voters
+}
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// hintsGame is a game of six into its second night: on the first the hunter
+// p1 protected p4 and the shaman p2 looked at p5, and in the day between p4
+// and p5 voted for the hunter while the village voted out p6
+func hintsGame(t *testing.T, hints string, revealVotes bool) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.Hints = hints
+		settings.RevealVotes = revealVotes
+	})
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleHunter,
+		"p2": models.RoleShaman,
+		"p3": models.RoleTiger,
+	})
+
+	nextPhase(t, gm, code)
+	nextPhase(t, gm, code)
+	playNight(t, gm, code, map[string]string{"p1": "p4", "p2": "p5"})
+
+	nextPhase(t, gm, code)
+	for voter, target := range map[string]string{"p1": "p6", "p2": "p6", "p3": "p6", "p4": "p1", "p5": "p1", "p6": "p3"} {
+		if err := gm.Vote(code, voter, target); err != nil {
+			t.Fatalf("%s votes: %v", voter, err)
+		}
+	}
+	nextPhase(t, gm, code)
+	if isAlive(gm, code, "p6") || phaseOf(t, gm, code) != models.PhaseNight {
+		t.Fatal("the village did not vote out p6 and go to sleep")
+	}
+	return gm, code
+}
+
+// targetOf is the entry for id in the prompt's target list
+func targetOf(t *testing.T, prompt TurnPrompt, id string) PromptTarget {
+	t.Helper()
+
+	for _, target := range prompt.Targets {
+		if target.ID == id {
+			return target
+		}
+	}
+	t.Fatalf("%s is not a target of the %s prompt", id, prompt.Role)
+	return PromptTarget{}
+}
+
+// flagged lists the targets carrying any hint
+func flagged(prompt TurnPrompt) []PromptTarget {
+	var hinted []PromptTarget
+	for _, target := range prompt.Targets {
+		if target.PreviouslyProtected || target.PreviouslyInspected || target.VotedAgainstYouLastRound {
+			hinted = append(hinted, target)
+		}
+	}
+	return hinted
+}
+
+func TestHintsFromTheHoldersOwnKnowledge(t *testing.T) {
+	gm, code := hintsGame(t, models.HintsBasic, true)
+
+	hunter := turnOf(t, gm, code, "p1")
+	if hasTarget(hunter, "p4") {
+		t.Error("the hunter may protect p4 two nights running")
+	}
+	if target := targetOf(t, hunter, "p5"); !target.VotedAgainstYouLastRound || target.PreviouslyInspected {
+		t.Errorf("the hunter sees p5 as %+v, want only the vote against them", target)
+	}
+	if target := targetOf(t, hunter, "p3"); target.VotedAgainstYouLastRound {
+		t.Error("p3 is flagged for a vote against someone else")
+	}
+	if err := gm.PerformNightAction(code, "p1", "p3", hunter.TurnToken); err != nil {
+		t.Fatalf("protect: %v", err)
+	}
+	if _, err := gm.MoveToNextNightRole(code); err != nil {
+		t.Fatalf("next night role: %v", err)
+	}
+
+	shaman := turnOf(t, gm, code, "p2")
+	if target := targetOf(t, shaman, "p5"); !target.PreviouslyInspected || target.PreviouslyProtected {
+		t.Errorf("the shaman sees p5 as %+v, want only their own vision", target)
+	}
+	// The hunter's protection of p4 and the votes against the hunter are
+	// not the shaman's to know
+	for _, target := range flagged(shaman) {
+		if target.ID != "p5" {
+			t.Errorf("the shaman's prompt flags %+v", target)
+		}
+	}
+	playNight(t, gm, code, nil)
+
+	// Two nights on, p4 may be protected again and is flagged as before
+	nextPhase(t, gm, code)
+	nextPhase(t, gm, code)
+	if phaseOf(t, gm, code) != models.PhaseNight {
+		t.Fatal("an empty vote did not lead to the night")
+	}
+	hunter = turnOf(t, gm, code, "p1")
+	if target := targetOf(t, hunter, "p4"); !target.PreviouslyProtected {
+		t.Errorf("the hunter sees p4 as %+v, want previously protected", target)
+	}
+	if target := targetOf(t, hunter, "p5"); target.VotedAgainstYouLastRound {
+		t.Error("a vote from before the last day is still flagged")
+	}
+}
+
+func TestTigerPromptHintsNothingHidden(t *testing.T) {
+	gm, code := hintsGame(t, models.HintsBasic, true)
+
+	tiger := turnOf(t, gm, code, "p3")
+	for _, target := range flagged(tiger) {
+		t.Errorf("the tiger's prompt flags %+v from others' secrets", target)
+	}
+}
+
+func TestAnonymousVotesAreNotHinted(t *testing.T) {
+	gm, code := hintsGame(t, models.HintsBasic, false)
+
+	hunter := turnOf(t, gm, code, "p1")
+	if target := targetOf(t, hunter, "p5"); target.VotedAgainstYouLastRound {
+		t.Error("an anonymous ballot was attributed to p5")
+	}
+}
+
+func TestNoHintsInCompetitiveRooms(t *testing.T) {
+	gm, code := hintsGame(t, models.HintsNone, true)
+
+	for _, id := range []string{"p1", "p2"} {
+		prompt := turnOf(t, gm, code, id)
+		if hinted := flagged(prompt); len(hinted) > 0 {
+			t.Errorf("%s's prompt flags %+v", id, hinted)
+		}
+		if err := gm.SkipNightAction(code, id, prompt.TurnToken); err != nil {
+			t.Fatalf("%s skips: %v", id, err)
+		}
+		if _, err := gm.MoveToNextNightRole(code); err != nil {
+			t.Fatalf("next night role: %v", err)
+		}
+	}
+}
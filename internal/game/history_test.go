@@ -0,0 +1,90 @@
+package game
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// historyOf lists a player's action history as "round phase action target outcome"
+func historyOf(player *models.Player) []string {
+	var entries []string
+	for _, record := range player.ActionHistory {
+		entries = append(entries, fmt.Sprintf("%d %s %s %s %s", record.Round, record.Phase, record.ActionType, record.TargetUsername, record.Outcome))
+	}
+	return entries
+}
+
+// assertNoForeignHistory fails if any view shows a player someone else's history
+func assertNoForeignHistory(t *testing.T, gm *GameManager, code models.RoomCode, when string) {
+	t.Helper()
+
+	public, views, _ := gm.RoomViews(code)
+	for id, player := range public.Players {
+		if len(player.ActionHistory) > 0 {
+			t.Errorf("%s: public view shows %s's history %v", when, id, historyOf(player))
+		}
+	}
+	for viewerID, view := range views {
+		for id, player := range view.Players {
+			if id != viewerID && len(player.ActionHistory) > 0 {
+				t.Errorf("%s: %s sees %s's history %v", when, viewerID, id, historyOf(player))
+			}
+		}
+	}
+}
+
+func TestActionHistoryTracesScriptedGame(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 5, nil)
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p4")
+	assertNoForeignHistory(t, gm, code, "after the first vote")
+
+	// The hunter's protection has no outcome until the night resolves
+	prompts, _ := gm.CurrentTurnPrompts(code)
+	if err := gm.PerformNightAction(code, "p2", "p5", prompts["p2"].TurnToken); err != nil {
+		t.Fatalf("hunter protects: %v", err)
+	}
+	view, _ := gm.RoomView(code, "p2")
+	if got, want := historyOf(view.Players["p2"]), []string{"1 voting vote Player4 eliminated", "1 night protect Player5 "}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hunter's history mid-night = %q, want %q", got, want)
+	}
+	assertNoForeignHistory(t, gm, code, "mid-night")
+
+	playNight(t, gm, code, map[string]string{"p1": "p5", "p3": "p1"})
+	assertNoForeignHistory(t, gm, code, "at dawn")
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p1")
+
+	want := map[string][]string{
+		"p1": {"1 voting vote Player4 eliminated", "1 night kill Player5 failed", "2 voting vote Player2 survived"},
+		"p2": {"1 voting vote Player4 eliminated", "1 night protect Player5 saved", "2 voting vote Player1 eliminated"},
+		"p3": {"1 voting vote Player4 eliminated", "1 night vision Player1 tiger", "2 voting vote Player1 eliminated"},
+		"p4": {"1 voting vote Player1 survived"},
+		"p5": {"1 voting vote Player4 eliminated", "2 voting vote Player1 eliminated"},
+	}
+
+	// Once the game is over everyone's history is disclosed to everyone
+	public, _ := gm.RoomView(code, "")
+	summary, err := gm.GameSummary(code)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	for id, history := range want {
+		if got := historyOf(public.Players[id]); !reflect.DeepEqual(got, history) {
+			t.Errorf("%s's history = %q, want %q", id, got, history)
+		}
+		if got := summary.Room.Players[id].ActionHistory; len(got) != len(history) {
+			t.Errorf("summary shows %d of %s's actions, want %d", len(got), id, len(history))
+		}
+	}
+}
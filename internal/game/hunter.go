@@ -0,0 +1,101 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// hunterShotWindow is how long a dead hunter has to take their shot before
+// it can be timed out
+const hunterShotWindow = 60 * time.Second
+
+var (
+	ErrShotProtected     = &errs.Error{Code: "HUNTER_TARGET_PROTECTED", Message: "the hunter cannot shoot the player they protected last night"}
+	ErrShotNotSelected   = &errs.Error{Code: "HUNTER_SHOT_NOT_SELECTED", Message: "select the target before confirming the shot"}
+	ErrHunterCannotShoot = &errs.Error{Code: "HUNTER_CANNOT_SHOOT", Message: "hunter cannot shoot now"}
+	ErrNoHunterWaiting   = &errs.Error{Code: "NO_HUNTER_WAITING", Message: "no hunter is waiting to shoot"}
+	ErrShotNotTimedOut   = &errs.Error{Code: "HUNTER_SHOT_NOT_TIMED_OUT", Message: "hunter shot has not timed out yet"}
+)
+
+// hunterMayShootLocked checks that the game is waiting on this hunter's shot
+func hunterMayShootLocked(room *models.GameRoom, hunter *models.Player) error {
+	if !room.WaitingHunterShoot || room.DeadHunterID != hunter.ID || !hunter.AbilityReady(models.AbilityShoot, 0) {
+		return ErrHunterCannotShoot
+	}
+	return nil
+}
+
+// hunterTargetLocked rejects a target the room's rules keep out of the
+// hunter's sights
+func hunterTargetLocked(room *models.GameRoom, hunter *models.Player, targetID string) error {
+	if room.Settings.HunterCannotShootProtectedTarget && hunter.LastProtected == targetID {
+		return ErrShotProtected
+	}
+	return nil
+}
+
+// selectHunterTargetLocked records the target the hunter means to shoot,
+// replacing an earlier selection, and returns the pending record
+func selectHunterTargetLocked(room *models.GameRoom, hunter *models.Player, target *models.Player) models.ActionRecord {
+	room.HunterSelection = target.ID
+
+	record := lastActionLocked(room, hunter, models.ActionShoot)
+	if record == nil {
+		recordActionLocked(room, hunter, models.ActionShoot, target)
+		return *lastActionLocked(room, hunter, models.ActionShoot)
+	}
+	record.TargetID = target.ID
+	record.TargetUsername = target.Username
+	hunter.ActionSeq++
+	return *record
+}
+
+// clearHunterWaitLocked lets the game go on once the hunter's shot is settled
+func clearHunterWaitLocked(room *models.GameRoom) {
+	if room.DeadHunterID != "" {
+		answerPromptLocked(room, room.DeadHunterID, models.EventHunterPrompt)
+	}
+	room.WaitingHunterShoot = false
+	room.DeadHunterID = ""
+	room.HunterSelection = ""
+	room.HunterShotEndsAt = nil
+}
+
+// TimeoutHunterShot forfeits the dead hunter's shot once their window has
+// passed. A target selected but never confirmed is not shot.
+func (gm *GameManager) TimeoutHunterShot(code models.RoomCode) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if !room.WaitingHunterShoot {
+		return ErrNoHunterWaiting
+	}
+
+	if pausedLocked(room) {
+		return ErrGamePaused
+	}
+
+	if room.HunterShotEndsAt == nil || time.Now().Before(room.HunterShotEndsAt.Time) {
+		return ErrShotNotTimedOut
+	}
+
+	if hunter := room.Players[room.DeadHunterID]; hunter != nil {
+		hunter.UseAbility(models.AbilityShoot, 0)
+		record := lastActionLocked(room, hunter, models.ActionShoot)
+		if record == nil {
+			recordActionLocked(room, hunter, models.ActionShoot, nil)
+			record = lastActionLocked(room, hunter, models.ActionShoot)
+		}
+		record.Outcome = "timed_out"
+	}
+
+	clearHunterWaitLocked(room)
+	return nil
+}
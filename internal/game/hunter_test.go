@@ -0,0 +1,150 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// deadHunterGame has the tiger kill the hunter p2 on the first night, after
+// the hunter protected p4, and waits on the hunter's shot
+func deadHunterGame(t *testing.T, adjust func(*models.RoomSettings)) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, adjust)
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+	playNight(t, gm, code, map[string]string{"p1": "p2", "p2": "p4"})
+
+	if view, _ := gm.RoomView(code, ""); !view.WaitingHunterShoot || view.DeadHunterID != "p2" {
+		t.Fatalf("waiting for hunter = %v (%q), want p2", view.WaitingHunterShoot, view.DeadHunterID)
+	}
+	return gm, code
+}
+
+// isAlive reports whether the player is alive in the room
+func isAlive(gm *GameManager, code models.RoomCode, playerID string) bool {
+	view, _ := gm.RoomView(code, "")
+	return view.Players[playerID].IsAlive
+}
+
+func TestHunterShotNeedsConfirmation(t *testing.T) {
+	gm, code := deadHunterGame(t, func(settings *models.RoomSettings) {
+		settings.HunterShotNeedsConfirmation = true
+	})
+
+	record, err := gm.HunterShoot(code, "p2", "p5", false)
+	if err != nil || record.TargetID != "p5" || record.Outcome != "" {
+		t.Fatalf("select p5 = %+v, %v; want a pending shot", record, err)
+	}
+	if !isAlive(gm, code, "p5") {
+		t.Fatal("selecting p5 shot them")
+	}
+	if prompt, _, _ := gm.HunterPromptFor(code); !prompt.NeedsConfirmation || prompt.Selected != "p5" {
+		t.Errorf("prompt = %+v, want p5 selected awaiting confirmation", prompt)
+	}
+
+	if _, err := gm.HunterShoot(code, "p2", "p6", true); !errors.Is(err, ErrShotNotSelected) {
+		t.Errorf("confirming an unselected target: err = %v, want %v", err, ErrShotNotSelected)
+	}
+
+	// The hunter changes their mind before firing
+	if _, err := gm.HunterShoot(code, "p2", "p6", false); err != nil {
+		t.Fatalf("select p6: %v", err)
+	}
+	record, err = gm.HunterShoot(code, "p2", "p6", true)
+	if err != nil || record.Outcome != "killed" {
+		t.Fatalf("confirm p6 = %+v, %v", record, err)
+	}
+	if isAlive(gm, code, "p6") || !isAlive(gm, code, "p5") {
+		t.Error("the confirmed shot did not hit p6 alone")
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		shots := 0
+		for _, record := range room.Players["p2"].ActionHistory {
+			if record.ActionType == models.ActionShoot {
+				shots++
+			}
+		}
+		if shots != 1 {
+			t.Errorf("the hunter's history has %d shots, want the selection and shot as one", shots)
+		}
+	})
+}
+
+func TestUnconfirmedHunterSelectionTimesOut(t *testing.T) {
+	gm, code := deadHunterGame(t, func(settings *models.RoomSettings) {
+		settings.HunterShotNeedsConfirmation = true
+	})
+	if _, err := gm.HunterShoot(code, "p2", "p5", false); err != nil {
+		t.Fatalf("select p5: %v", err)
+	}
+
+	if err := gm.TimeoutHunterShot(code); err == nil {
+		t.Fatal("the shot timed out inside its window")
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.HunterShotEndsAt = models.TimestampPtr(time.Now().Add(-time.Second))
+	})
+	if err := gm.TimeoutHunterShot(code); err != nil {
+		t.Fatalf("timeout: %v", err)
+	}
+
+	if !isAlive(gm, code, "p5") {
+		t.Error("the unconfirmed selection was fired")
+	}
+	if view, _ := gm.RoomView(code, ""); view.WaitingHunterShoot {
+		t.Error("the game still waits on the hunter")
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		history := room.Players["p2"].ActionHistory
+		if last := history[len(history)-1]; last.ActionType != models.ActionShoot || last.Outcome != "timed_out" {
+			t.Errorf("the hunter's last action is %+v, want a timed out shot", last)
+		}
+	})
+}
+
+func TestHunterCannotShootProtectedTarget(t *testing.T) {
+	gm, code := deadHunterGame(t, func(settings *models.RoomSettings) {
+		settings.HunterCannotShootProtectedTarget = true
+	})
+
+	prompt, _, _ := gm.HunterPromptFor(code)
+	for _, id := range prompt.Targets {
+		if id == "p4" {
+			t.Errorf("targets %v include the protected p4", prompt.Targets)
+		}
+	}
+	if len(prompt.Targets) == 0 {
+		t.Error("the hunter has nobody to shoot")
+	}
+
+	if _, err := gm.HunterShoot(code, "p2", "p4", true); !errors.Is(err, ErrShotProtected) {
+		t.Errorf("shooting p4: err = %v, want %v", err, ErrShotProtected)
+	}
+	if !isAlive(gm, code, "p4") {
+		t.Error("the protected player was shot")
+	}
+	if _, err := gm.HunterShoot(code, "p2", "p5", true); err != nil {
+		t.Errorf("shooting p5: %v", err)
+	}
+}
+
+func TestHunterMayShootProtectedTargetByDefault(t *testing.T) {
+	gm, code := deadHunterGame(t, nil)
+
+	if _, err := gm.HunterShoot(code, "p2", "p4", true); err != nil {
+		t.Fatalf("shooting p4: %v", err)
+	}
+	if isAlive(gm, code, "p4") {
+		t.Error("p4 survived the shot")
+	}
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// cursedLynchGame starts an eight-player game, curses the villager p4 and
+// votes them out, so the next phase is the first night
+func cursedLynchGame(t *testing.T, inspectDead bool) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, func(settings *models.RoomSettings) {
+		settings.ShamanCanInspectDead = inspectDead
+	})
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleAlphaTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.Players["p4"].IsCursed = true
+		room.CursedPlayer = "p4"
+	})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p4")
+	return gm, code
+}
+
+// turnOf skips every night turn until the player holds one, and returns it
+func turnOf(t *testing.T, gm *GameManager, code models.RoomCode, playerID string) TurnPrompt {
+	t.Helper()
+
+	for step := 0; step < 10; step++ {
+		prompts, _ := gm.CurrentTurnPrompts(code)
+		if prompt, ok := prompts[playerID]; ok {
+			return prompt
+		}
+		for holder, prompt := range prompts {
+			if err := gm.SkipNightAction(code, holder, prompt.TurnToken); err != nil {
+				t.Fatalf("%s skips: %v", holder, err)
+			}
+		}
+		if _, err := gm.MoveToNextNightRole(code); err != nil {
+			t.Fatalf("next night role: %v", err)
+		}
+	}
+	t.Fatalf("%s never got a turn", playerID)
+	return TurnPrompt{}
+}
+
+func hasTarget(prompt TurnPrompt, id string) bool {
+	for _, target := range prompt.Targets {
+		if target.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInspectingACursedLynchVictim(t *testing.T) {
+	gm, code := cursedLynchGame(t, true)
+	prompt := turnOf(t, gm, code, "p3")
+	if !hasTarget(prompt, "p4") {
+		t.Fatal("the dead p4 is not offered to the shaman")
+	}
+
+	if err := gm.PerformNightAction(code, "p3", "p4", prompt.TurnToken); err != nil {
+		t.Fatalf("inspect the dead: %v", err)
+	}
+	playTurns(t, gm, code, nil)
+	result := nextPhase(t, gm, code)
+
+	// The curse made p4 look like a tiger alive; dead, they show what they were
+	if result.VisionResult != string(models.RoleVillager) {
+		t.Errorf("inspection showed %q, want %s", result.VisionResult, models.RoleVillager)
+	}
+	recap := result.Recaps["p3"]
+	if recap.Outcome != RecapInspected {
+		t.Errorf("shaman recap = %q, want %s", recap.Outcome, RecapInspected)
+	}
+	if n := len(recap.Visions); n != 1 || !recap.Visions[0].Inspected || recap.Visions[0].Result != string(models.RoleVillager) {
+		t.Errorf("vision history = %+v, want one inspection of a villager", recap.Visions)
+	}
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if history := room.Players["p3"].ActionHistory; len(history) == 0 || history[len(history)-1].ActionType != models.ActionInspect {
+			t.Errorf("the shaman's last action = %+v, want an inspection", history)
+		}
+	})
+}
+
+func TestCursedVictimLooksLikeATigerAlive(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, func(settings *models.RoomSettings) {
+		settings.ShamanCanInspectDead = true
+	})
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleAlphaTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.Players["p4"].IsCursed = true
+	})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+
+	if result := playNight(t, gm, code, map[string]string{"p3": "p4"}); result.VisionResult != "tiger" {
+		t.Errorf("a living cursed villager showed as %q, want tiger", result.VisionResult)
+	}
+}
+
+func TestDeadTargetsRejectedWithoutTheSetting(t *testing.T) {
+	gm, code := cursedLynchGame(t, false)
+	prompt := turnOf(t, gm, code, "p3")
+	if hasTarget(prompt, "p4") {
+		t.Error("the dead p4 is offered to the shaman")
+	}
+
+	if err := gm.PerformNightAction(code, "p3", "p4", prompt.TurnToken); err == nil {
+		t.Fatal("the shaman looked into a dead player")
+	}
+	// The turn is still theirs to use on the living
+	if err := gm.PerformNightAction(code, "p3", "p5", prompt.TurnToken); err != nil {
+		t.Errorf("a vision after the refusal: %v", err)
+	}
+}
+
+func TestInspectionsCountApartFromVisions(t *testing.T) {
+	gm, code := cursedLynchGame(t, true)
+	playNight(t, gm, code, map[string]string{"p3": "p5"})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p5")
+
+	// Seen alive, p5 may still be inspected once dead
+	prompt := turnOf(t, gm, code, "p3")
+	if err := gm.PerformNightAction(code, "p3", "p5", prompt.TurnToken); err != nil {
+		t.Fatalf("inspect the dead p5 after a vision of them: %v", err)
+	}
+	playTurns(t, gm, code, nil)
+	visions := nextPhase(t, gm, code).Recaps["p3"].Visions
+
+	if len(visions) != 2 || visions[0].Inspected || !visions[1].Inspected {
+		t.Fatalf("vision history = %+v, want a vision then an inspection", visions)
+	}
+	if visions[0].Result != "human" || visions[1].Result != string(models.RoleVillager) {
+		t.Errorf("p5 was seen as %q, then inspected as %q", visions[0].Result, visions[1].Result)
+	}
+}
@@ -0,0 +1,184 @@
+package game
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// maxInvitesPerRequest caps how many invites the host can mint at once
+const maxInvitesPerRequest = 20
+
+var (
+	ErrInviteNotFound      = &errs.Error{Code: "INVITE_NOT_FOUND", Message: "invite not found"}
+	ErrInviteUsed          = &errs.Error{Code: "INVITE_USED", Message: "invite already used"}
+	ErrInviteExpired       = &errs.Error{Code: "INVITE_EXPIRED", Message: "invite expired"}
+	ErrInvalidInviteCount  = &errs.Error{Code: "INVALID_INVITE_COUNT", Message: "invalid invite count"}
+	ErrInvalidInviteExpiry = &errs.Error{Code: "INVALID_INVITE_EXPIRY", Message: "invalid invite expiry"}
+)
+
+// CreateInvites mints count single-use invites for the room (host only).
+// A zero ttl means the invites never expire.
+func (gm *GameManager) CreateInvites(code models.RoomCode, playerID string, count int, ttl time.Duration) ([]models.Invite, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	if room.HostID != playerID {
+		return nil, hostOnlyError("create invites")
+	}
+
+	if room.Settings.PracticeMode {
+		return nil, ErrPracticeSinglePlayer
+	}
+
+	if count < 1 || count > maxInvitesPerRequest {
+		return nil, ErrInvalidInviteCount
+	}
+
+	if ttl < 0 {
+		return nil, ErrInvalidInviteExpiry
+	}
+
+	if room.Invites == nil {
+		room.Invites = make(map[string]*models.Invite)
+	}
+
+	now := time.Now()
+	invites := make([]models.Invite, 0, count)
+	for i := 0; i < count; i++ {
+		invite := &models.Invite{
+			Token:     uuid.New().String(),
+			CreatedAt: models.NewTimestamp(now),
+		}
+		if ttl > 0 {
+			expiresAt := now.Add(ttl)
+			invite.ExpiresAt = models.TimestampPtr(expiresAt)
+		}
+		room.Invites[invite.Token] = invite
+		invites = append(invites, *invite)
+	}
+
+	return invites, nil
+}
+
+// ListInvites returns the room's outstanding invites, oldest first (host only)
+func (gm *GameManager) ListInvites(code models.RoomCode, playerID string) ([]models.Invite, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	if room.HostID != playerID {
+		return nil, hostOnlyError("list invites")
+	}
+
+	now := time.Now()
+	invites := make([]models.Invite, 0, len(room.Invites))
+	for _, invite := range room.Invites {
+		if invite.UsedBy == "" && !inviteExpired(invite, now) {
+			invites = append(invites, *invite)
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.Before(invites[j].CreatedAt.Time)
+	})
+
+	return invites, nil
+}
+
+// RevokeInvite deletes an invite so it can no longer be redeemed (host only)
+func (gm *GameManager) RevokeInvite(code models.RoomCode, playerID, token string) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if room.HostID != playerID {
+		return hostOnlyError("revoke invites")
+	}
+
+	if room.Invites[token] == nil {
+		return ErrInviteNotFound
+	}
+
+	delete(room.Invites, token)
+
+	return nil
+}
+
+// RedeemInvite joins the player to the invite's room and consumes the invite.
+// Both happen under the same lock, so an invite can only ever be used once.
+func (gm *GameManager) RedeemInvite(token, playerID, username string) (*models.GameRoom, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, invite := gm.findInviteLocked(token)
+	if invite == nil {
+		return nil, ErrInviteNotFound
+	}
+
+	if invite.UsedBy != "" {
+		return nil, ErrInviteUsed
+	}
+
+	now := time.Now()
+	if inviteExpired(invite, now) {
+		return nil, ErrInviteExpired
+	}
+
+	// The invite is the credential, so it skips any lobby gate but not the
+	// basic join rules (room full, game started). The room comes back with
+	// those errors so the caller can say what to do instead.
+	if err := gm.joinRoomLocked(room, playerID, username); err != nil {
+		return room, err
+	}
+
+	invite.UsedBy = playerID
+	invite.UsedAt = models.TimestampPtr(now)
+
+	return room, nil
+}
+
+// InviteRoom returns the code of the room an invite token belongs to, so a
+// frontend can vet the join before redeeming it
+func (gm *GameManager) InviteRoom(token string) (models.RoomCode, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, invite := gm.findInviteLocked(token)
+	if invite == nil {
+		return "", false
+	}
+	return room.Code, true
+}
+
+// findInviteLocked looks the token up across all rooms
+func (gm *GameManager) findInviteLocked(token string) (*models.GameRoom, *models.Invite) {
+	if token == "" {
+		return nil, nil
+	}
+	for _, room := range gm.Rooms {
+		if invite := room.Invites[token]; invite != nil {
+			return room, invite
+		}
+	}
+	return nil, nil
+}
+
+func inviteExpired(invite *models.Invite, now time.Time) bool {
+	return invite.ExpiresAt != nil && !now.Before(invite.ExpiresAt.Time)
+}
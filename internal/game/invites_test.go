@@ -0,0 +1,140 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// inviteFor mints one invite to a fresh lobby of three
+func inviteFor(t *testing.T, gm *GameManager) (models.RoomCode, models.Invite) {
+	t.Helper()
+
+	code := newTestRoom(t, gm, 3, nil)
+	invites, err := gm.CreateInvites(code, "p1", 1, time.Hour)
+	if err != nil {
+		t.Fatalf("create invite: %v", err)
+	}
+	return code, invites[0]
+}
+
+func TestInviteAdmitsExactlyOne(t *testing.T) {
+	gm := NewGameManager()
+	code, invite := inviteFor(t, gm)
+
+	const racers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, racers)
+	for i := 0; i < racers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := gm.RedeemInvite(invite.Token, fmt.Sprintf("racer%d", i), "Racer")
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	admitted := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			admitted++
+		case !errors.Is(err, ErrInviteUsed):
+			t.Errorf("a losing redemption: %v, want %s", err, ErrInviteUsed.Code)
+		}
+	}
+	if admitted != 1 {
+		t.Fatalf("%d players redeemed one invite", admitted)
+	}
+	view, _ := gm.RoomView(code, "")
+	if len(view.Players) != 4 {
+		t.Errorf("%d players in the room, want the three and one racer", len(view.Players))
+	}
+	if failures := gm.ValidationFailures(); failures != 0 {
+		t.Errorf("%d validation failures", failures)
+	}
+}
+
+func TestExpiredInvite(t *testing.T) {
+	gm := NewGameManager()
+	code, invite := inviteFor(t, gm)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.Invites[invite.Token].ExpiresAt = models.TimestampPtr(time.Now().Add(-time.Second))
+	})
+
+	if _, err := gm.RedeemInvite(invite.Token, "late", "Late"); !errors.Is(err, ErrInviteExpired) {
+		t.Errorf("redeeming an expired invite: %v, want %s", err, ErrInviteExpired.Code)
+	}
+	if outstanding, _ := gm.ListInvites(code, "p1"); len(outstanding) != 0 {
+		t.Errorf("expired invite still listed: %+v", outstanding)
+	}
+}
+
+func TestRevokedInvite(t *testing.T) {
+	gm := NewGameManager()
+	code, invite := inviteFor(t, gm)
+
+	if err := gm.RevokeInvite(code, "p2", invite.Token); err == nil {
+		t.Error("a guest revoked the invite")
+	}
+	if err := gm.RevokeInvite(code, "p1", invite.Token); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := gm.RedeemInvite(invite.Token, "late", "Late"); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("redeeming a revoked invite: %v, want %s", err, ErrInviteNotFound.Code)
+	}
+	if err := gm.RevokeInvite(code, "p1", invite.Token); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("revoking twice: %v, want %s", err, ErrInviteNotFound.Code)
+	}
+}
+
+func TestInviteSkipsTheApprovalGate(t *testing.T) {
+	gm := NewGameManager()
+	code, invite := inviteFor(t, gm)
+	withRoom(t, gm, code, func(room *models.GameRoom) { room.Settings.JoinApprovalRequired = true })
+
+	if _, err := gm.JoinRoom(code, "walkin", "Walk-in"); !errors.Is(err, ErrJoinApprovalRequired) {
+		t.Fatalf("joining without the invite: %v, want %s", err, ErrJoinApprovalRequired.Code)
+	}
+	if _, err := gm.RedeemInvite(invite.Token, "invited", "Invited"); err != nil {
+		t.Fatalf("redeeming the invite: %v", err)
+	}
+	if view, _ := gm.RoomView(code, ""); view.Players["invited"] == nil {
+		t.Error("the invited player is not in the room")
+	}
+}
+
+func TestInvitesAreTheHosts(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 3, nil)
+
+	if _, err := gm.CreateInvites(code, "p2", 1, 0); err == nil {
+		t.Error("a guest minted invites")
+	}
+	if _, err := gm.ListInvites(code, "p2"); err == nil {
+		t.Error("a guest listed the invites")
+	}
+
+	invites, err := gm.CreateInvites(code, "p1", 3, 0)
+	if err != nil {
+		t.Fatalf("create invites: %v", err)
+	}
+	if _, err := gm.RedeemInvite(invites[1].Token, "guest", "Guest"); err != nil {
+		t.Fatalf("redeem: %v", err)
+	}
+	// One batch shares its creation time, so the listing has no order within it
+	outstanding, _ := gm.ListInvites(code, "p1")
+	listed := map[string]bool{}
+	for _, invite := range outstanding {
+		listed[invite.Token] = true
+	}
+	if len(outstanding) != 2 || !listed[invites[0].Token] || !listed[invites[2].Token] {
+		t.Errorf("outstanding = %+v, want the first and third", outstanding)
+	}
+}
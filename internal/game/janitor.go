@@ -0,0 +1,164 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const (
+	DefaultJanitorInterval = time.Minute      // how often idle rooms are swept
+	DefaultRoomIdleTimeout = 30 * time.Minute // inactivity after which a room is reclaimed
+
+	// capacityRetryFallback is suggested to clients when no janitor is running
+	capacityRetryFallback = time.Minute
+)
+
+// janitorSchedule is the idle-room janitor's configuration and next run,
+// guarded by the manager lock
+type janitorSchedule struct {
+	interval    time.Duration
+	idleTimeout time.Duration
+	nextSweep   time.Time
+}
+
+// Capacity describes how full the server is
+type Capacity struct {
+	RoomsUsed         int `json:"roomsUsed"`
+	RoomCap           int `json:"roomCap"` // 0 means no cap
+	OldestIdleSeconds int `json:"oldestIdleSeconds"`
+	// RetryAfterSeconds estimates when a room slot frees up; only set at capacity
+	RetryAfterSeconds int `json:"retryAfterSeconds,omitempty"`
+}
+
+// StartJanitor sweeps rooms idle for longer than idleTimeout every interval
+// until stop is called
+func (gm *GameManager) StartJanitor(interval, idleTimeout time.Duration) (stop func()) {
+	gm.mu.Lock()
+	gm.janitor = janitorSchedule{
+		interval:    interval,
+		idleTimeout: idleTimeout,
+		nextSweep:   time.Now().Add(interval),
+	}
+	gm.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				gm.SweepIdleRooms(now)
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// SweepIdleRooms deletes every room whose last activity is older than the
+// janitor's idle timeout and returns how many were removed. Ended games are
+// archived as they go. The rooms left
+// are then checked for being stuck.
+func (gm *GameManager) SweepIdleRooms(now time.Time) int {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	gm.janitor.nextSweep = now.Add(gm.janitor.interval)
+	defer gm.checkStuckRoomsLocked(now)
+	gm.pruneArchiveLocked(now)
+	if gm.janitor.idleTimeout <= 0 {
+		return 0
+	}
+
+	removed := 0
+	for code, room := range gm.Rooms {
+		if now.Sub(room.LastActivityAt) < gm.janitor.idleTimeout {
+			continue
+		}
+		delete(gm.Rooms, code)
+		gm.forgetAliasesLocked(room)
+		gm.forgetChatLocked(code)
+		gm.endLifecycleLocked(code)
+		if room.Phase == models.PhaseEnded {
+			gm.archiveLocked(room, now)
+		}
+		gm.notify(room, func(o RoomObserver) { o.OnRoomDeleted(room) })
+		removed++
+	}
+
+	return removed
+}
+
+// Capacity reports room usage and, at capacity, when a slot is expected to free up
+func (gm *GameManager) Capacity() Capacity {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	now := time.Now()
+	capacity := Capacity{
+		RoomsUsed: len(gm.Rooms),
+		RoomCap:   gm.MaxRooms,
+	}
+
+	if oldest := oldestIdleRoomLocked(gm.Rooms); oldest != nil {
+		capacity.OldestIdleSeconds = int(now.Sub(oldest.LastActivityAt).Seconds())
+	}
+
+	if gm.MaxRooms > 0 && len(gm.Rooms) >= gm.MaxRooms {
+		wait := capacityRetryFallback
+		if next, ok := gm.nextReclamationLocked(now); ok {
+			wait = next.Sub(now)
+		}
+		capacity.RetryAfterSeconds = ceilSeconds(wait)
+	}
+
+	return capacity
+}
+
+// nextReclamationLocked returns the janitor run that will first find a room
+// idle long enough to delete
+func (gm *GameManager) nextReclamationLocked(now time.Time) (time.Time, bool) {
+	schedule := gm.janitor
+	if schedule.interval <= 0 || schedule.idleTimeout <= 0 {
+		return time.Time{}, false
+	}
+
+	oldest := oldestIdleRoomLocked(gm.Rooms)
+	if oldest == nil {
+		return time.Time{}, false
+	}
+
+	reclaimable := oldest.LastActivityAt.Add(schedule.idleTimeout)
+	next := schedule.nextSweep
+	if next.Before(now) {
+		next = now
+	}
+	if next.Before(reclaimable) {
+		runs := (reclaimable.Sub(next) + schedule.interval - 1) / schedule.interval
+		next = next.Add(runs * schedule.interval)
+	}
+	return next, true
+}
+
+func oldestIdleRoomLocked(rooms map[models.RoomCode]*models.GameRoom) *models.GameRoom {
+	var oldest *models.GameRoom
+	for _, room := range rooms {
+		if oldest == nil || room.LastActivityAt.Before(oldest.LastActivityAt) {
+			oldest = room
+		}
+	}
+	return oldest
+}
+
+// ceilSeconds rounds a positive duration up to whole seconds, never below one
+func ceilSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
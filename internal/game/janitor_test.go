@@ -0,0 +1,70 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// fullManager is a manager capped at two rooms, holding two lobbies last
+// active idle ago
+func fullManager(t *testing.T, idle time.Duration) (*GameManager, []models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	gm.MaxRooms = 2
+	codes := []models.RoomCode{newTestRoom(t, gm, 1, nil), newTestRoom(t, gm, 1, nil)}
+	for _, code := range codes {
+		withRoom(t, gm, code, func(room *models.GameRoom) { room.LastActivityAt = time.Now().Add(-idle) })
+	}
+	return gm, codes
+}
+
+func TestRoomCap(t *testing.T) {
+	gm, _ := fullManager(t, time.Minute)
+
+	if _, err := gm.CreateRoom("p9", "Player9", models.DefaultRoomSettings()); !errors.Is(err, ErrServerAtCapacity) {
+		t.Fatalf("a third room: %v, want %s", err, ErrServerAtCapacity.Code)
+	}
+	capacity := gm.Capacity()
+	if capacity.RoomsUsed != 2 || capacity.RoomCap != 2 || capacity.OldestIdleSeconds != 60 {
+		t.Errorf("capacity = %+v, want 2 of 2 used, idle a minute", capacity)
+	}
+	// Without a janitor nothing is reclaimed on a schedule
+	if capacity.RetryAfterSeconds != int(capacityRetryFallback/time.Second) {
+		t.Errorf("retry after %ds, want the %v fallback", capacity.RetryAfterSeconds, capacityRetryFallback)
+	}
+}
+
+func TestRetryAfterFollowsTheJanitor(t *testing.T) {
+	// The oldest room becomes reclaimable in a minute, but the janitor runs
+	// in 15s and then every minute, so the slot frees on its second run
+	gm, _ := fullManager(t, 9*time.Minute)
+	gm.mu.Lock()
+	gm.janitor = janitorSchedule{interval: time.Minute, idleTimeout: 10 * time.Minute, nextSweep: time.Now().Add(15 * time.Second)}
+	gm.mu.Unlock()
+
+	if retry := gm.Capacity().RetryAfterSeconds; retry != 75 {
+		t.Errorf("retry after %ds, want 75", retry)
+	}
+}
+
+func TestFreedSlotAdmitsARoom(t *testing.T) {
+	gm, codes := fullManager(t, time.Hour)
+	withRoom(t, gm, codes[1], func(room *models.GameRoom) { room.LastActivityAt = time.Now() })
+	gm.mu.Lock()
+	gm.janitor = janitorSchedule{interval: time.Minute, idleTimeout: 30 * time.Minute}
+	gm.mu.Unlock()
+
+	if removed := gm.SweepIdleRooms(time.Now()); removed != 1 {
+		t.Fatalf("swept %d rooms, want the idle one", removed)
+	}
+	if capacity := gm.Capacity(); capacity.RoomsUsed != 1 || capacity.RetryAfterSeconds != 0 {
+		t.Errorf("capacity = %+v, want a free slot and no retry hint", capacity)
+	}
+	if _, err := gm.CreateRoom("p9", "Player9", models.DefaultRoomSettings()); err != nil {
+		t.Errorf("creating into the freed slot: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/sanitize"
+)
+
+const (
+	// joinRequestTimeout is how long a request waits for the host's answer
+	joinRequestTimeout = 2 * time.Minute
+	// maxPendingJoinRequests caps how many players can wait at a lobby's door
+	maxPendingJoinRequests = 10
+)
+
+var (
+	ErrJoinApprovalRequired = &errs.Error{Code: "JOIN_APPROVAL_REQUIRED", Message: "the host must approve new players"}
+	ErrJoinRequestNotFound  = &errs.Error{Code: "JOIN_REQUEST_NOT_FOUND", Message: "join request not found"}
+	ErrTooManyJoinRequests  = &errs.Error{Code: "TOO_MANY_JOIN_REQUESTS", Message: "too many players are already waiting to join"}
+	ErrApprovalNotRequired  = &errs.Error{Code: "JOIN_APPROVAL_NOT_REQUIRED", Message: "the room does not require approval"}
+)
+
+// RequestJoin asks the host of a room that requires approval to let the
+// player in. The player ID is only handed out once the host approves; the
+// session token, returned to the requester alone, is what they poll with.
+func (gm *GameManager) RequestJoin(code models.RoomCode, playerID, username string) (models.JoinRequest, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return models.JoinRequest{}, gm.missingRoomLocked(code)
+	}
+
+	if !room.Settings.JoinApprovalRequired {
+		return models.JoinRequest{}, ErrApprovalNotRequired
+	}
+
+	if room.Phase != models.PhaseWaiting {
+		return models.JoinRequest{}, ErrGameAlreadyStarted
+	}
+
+	if len(room.Players) >= room.MaxPlayers {
+		return models.JoinRequest{}, ErrRoomFull
+	}
+
+	username, err := sanitize.Text(username, sanitize.Username)
+	if err != nil {
+		return models.JoinRequest{}, err
+	}
+
+	now := time.Now()
+	expireJoinRequestsLocked(room, now)
+
+	pending := 0
+	for _, request := range room.JoinRequests {
+		if request.Status == models.JoinPending {
+			pending++
+		}
+	}
+	if pending >= maxPendingJoinRequests {
+		return models.JoinRequest{}, ErrTooManyJoinRequests
+	}
+
+	if room.JoinRequests == nil {
+		room.JoinRequests = make(map[string]*models.JoinRequest)
+	}
+	request := &models.JoinRequest{
+		ID:           uuid.New().String(),
+		Username:     username,
+		RequestedAt:  models.NewTimestamp(now),
+		ExpiresAt:    models.NewTimestamp(now.Add(joinRequestTimeout)),
+		Status:       models.JoinPending,
+		PlayerID:     playerID,
+		HostID:       room.HostID,
+		SessionToken: newSessionToken(),
+	}
+	room.JoinRequests[request.ID] = request
+	room.LastActivityAt = now
+
+	return *request, nil
+}
+
+// RespondJoinRequest lets the host approve or deny a waiting player.
+// Approval joins them on the spot, so a lobby that filled up in the
+// meantime turns them away instead.
+func (gm *GameManager) RespondJoinRequest(code models.RoomCode, hostID, requestID string, approve bool) (models.JoinRequest, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return models.JoinRequest{}, gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, hostID, PermAdmitPlayers); err != nil {
+		return models.JoinRequest{}, err
+	}
+
+	expireJoinRequestsLocked(room, time.Now())
+
+	request := room.JoinRequests[requestID]
+	if request == nil {
+		return models.JoinRequest{}, ErrJoinRequestNotFound
+	}
+	if request.Status != models.JoinPending {
+		return *request, &errs.Error{
+			Code:    "JOIN_REQUEST_RESOLVED",
+			Params:  map[string]interface{}{"status": request.Status},
+			Message: "join request already " + request.Status,
+		}
+	}
+
+	if !approve {
+		request.Status = models.JoinDenied
+		return *request, nil
+	}
+
+	if err := gm.joinRoomLocked(room, request.PlayerID, request.Username); err != nil {
+		request.Status = models.JoinDenied
+		request.Reason = "room_full"
+		if errors.Is(err, ErrGameAlreadyStarted) {
+			request.Reason = "game_started"
+		}
+		return *request, err
+	}
+	request.Status = models.JoinApproved
+	// The requester already holds the session they polled with
+	room.Players[request.PlayerID].SessionToken = request.SessionToken
+
+	return *request, nil
+}
+
+// JoinRequestStatus is how a waiting player learns the host's answer
+func (gm *GameManager) JoinRequestStatus(code models.RoomCode, requestID string) (models.JoinRequest, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return models.JoinRequest{}, gm.missingRoomLocked(code)
+	}
+
+	expireJoinRequestsLocked(room, time.Now())
+
+	request := room.JoinRequests[requestID]
+	if request == nil {
+		return models.JoinRequest{}, ErrJoinRequestNotFound
+	}
+	return *request, nil
+}
+
+// expireJoinRequestsLocked times out requests the host never answered and
+// forgets answered ones once their requester has had time to learn the outcome
+func expireJoinRequestsLocked(room *models.GameRoom, now time.Time) {
+	for id, request := range room.JoinRequests {
+		switch {
+		case request.Status == models.JoinPending && !now.Before(request.ExpiresAt.Time):
+			request.Status = models.JoinExpired
+		case request.Status != models.JoinPending && now.Sub(request.ExpiresAt.Time) > joinRequestTimeout:
+			delete(room.JoinRequests, id)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// knockRoom is a lobby of n players whose host approves newcomers
+func knockRoom(t *testing.T, n int) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, n, nil)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.Settings.JoinApprovalRequired = true
+	})
+	return gm, code
+}
+
+// knock asks to join and fails the test if the request is refused
+func knock(t *testing.T, gm *GameManager, code models.RoomCode, playerID string) models.JoinRequest {
+	t.Helper()
+
+	if _, err := gm.JoinRoom(code, playerID, "Guest"); !errors.Is(err, ErrJoinApprovalRequired) {
+		t.Fatalf("join without approval: err = %v, want ErrJoinApprovalRequired", err)
+	}
+	request, err := gm.RequestJoin(code, playerID, "Guest")
+	if err != nil {
+		t.Fatalf("request join: %v", err)
+	}
+	if request.Status != models.JoinPending || request.HostID != "p1" {
+		t.Fatalf("request = %+v", request)
+	}
+	return request
+}
+
+// inRoom reports whether the player holds a seat in the room
+func inRoom(t *testing.T, gm *GameManager, code models.RoomCode, playerID string) bool {
+	t.Helper()
+
+	view, _ := gm.RoomView(code, "")
+	_, ok := view.Players[playerID]
+	return ok
+}
+
+func TestJoinRequestApproved(t *testing.T) {
+	gm, code := knockRoom(t, 3)
+	request := knock(t, gm, code, "guest")
+	if inRoom(t, gm, code, "guest") {
+		t.Fatal("the guest got in before the host answered")
+	}
+
+	if _, err := gm.RespondJoinRequest(code, "p2", request.ID, true); err == nil {
+		t.Error("a player who is not the host let the guest in")
+	}
+	answered, err := gm.RespondJoinRequest(code, "p1", request.ID, true)
+	if err != nil || answered.Status != models.JoinApproved {
+		t.Fatalf("approve: status %s, err %v", answered.Status, err)
+	}
+	if !inRoom(t, gm, code, "guest") {
+		t.Error("the approved guest has no seat")
+	}
+	if _, err := gm.RespondJoinRequest(code, "p1", request.ID, false); err == nil {
+		t.Error("an approved request was answered again")
+	}
+}
+
+func TestJoinRequestDenied(t *testing.T) {
+	gm, code := knockRoom(t, 3)
+	request := knock(t, gm, code, "guest")
+
+	if answered, err := gm.RespondJoinRequest(code, "p1", request.ID, false); err != nil || answered.Status != models.JoinDenied {
+		t.Fatalf("deny: status %s, err %v", answered.Status, err)
+	}
+	if status, _ := gm.JoinRequestStatus(code, request.ID); status.Status != models.JoinDenied {
+		t.Errorf("the guest polls %s", status.Status)
+	}
+	if inRoom(t, gm, code, "guest") {
+		t.Error("a denied guest got in")
+	}
+}
+
+func TestJoinRequestTimesOut(t *testing.T) {
+	gm, code := knockRoom(t, 3)
+	request := knock(t, gm, code, "guest")
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.JoinRequests[request.ID].ExpiresAt = models.NewTimestamp(time.Now().Add(-time.Second))
+	})
+
+	if status, _ := gm.JoinRequestStatus(code, request.ID); status.Status != models.JoinExpired {
+		t.Errorf("status = %s, want expired", status.Status)
+	}
+	if _, err := gm.RespondJoinRequest(code, "p1", request.ID, true); err == nil {
+		t.Error("the host approved an expired request")
+	}
+	if inRoom(t, gm, code, "guest") {
+		t.Error("an expired request got in")
+	}
+}
+
+func TestJoinRequestApprovalRacesFullRoom(t *testing.T) {
+	gm, code := knockRoom(t, 4)
+	withRoom(t, gm, code, func(room *models.GameRoom) { room.MaxPlayers = 5 })
+	first := knock(t, gm, code, "first")
+	second := knock(t, gm, code, "second")
+
+	if _, err := gm.RespondJoinRequest(code, "p1", first.ID, true); err != nil {
+		t.Fatalf("approve the first: %v", err)
+	}
+	answered, err := gm.RespondJoinRequest(code, "p1", second.ID, true)
+	if !errors.Is(err, ErrRoomFull) {
+		t.Fatalf("approve into a full room: err = %v, want ErrRoomFull", err)
+	}
+	if answered.Status != models.JoinDenied || answered.Reason != "room_full" {
+		t.Errorf("second request = %s (%s), want denied as room_full", answered.Status, answered.Reason)
+	}
+	if inRoom(t, gm, code, "second") {
+		t.Error("the room went over its cap")
+	}
+}
+
+func TestJoinRequestsBoundedAndClearedAtStart(t *testing.T) {
+	gm, code := knockRoom(t, 5)
+
+	var requests []models.JoinRequest
+	for i := 0; i < maxPendingJoinRequests; i++ {
+		requests = append(requests, knock(t, gm, code, fmt.Sprintf("guest%d", i)))
+	}
+	if _, err := gm.RequestJoin(code, "one-too-many", "Guest"); !errors.Is(err, ErrTooManyJoinRequests) {
+		t.Errorf("request past the cap: err = %v, want ErrTooManyJoinRequests", err)
+	}
+
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	for _, request := range requests {
+		status, _ := gm.JoinRequestStatus(code, request.ID)
+		if status.Status != models.JoinDenied || status.Reason != "game_started" {
+			t.Fatalf("request left %s (%s) once the game started", status.Status, status.Reason)
+		}
+	}
+}
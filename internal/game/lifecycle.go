@@ -0,0 +1,111 @@
+package game
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// DefaultRoomShutdownTimeout is how long a deleted room's goroutines have
+// to exit before they are reported as leaked
+const DefaultRoomShutdownTimeout = 5 * time.Second
+
+// roomLifecycle scopes the goroutines working for one room. Its context is
+// cancelled when the room is deleted or archived, and its WaitGroup lets
+// the manager check that they all exited.
+type roomLifecycle struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running atomic.Int32
+}
+
+// beginLifecycleLocked opens the lifecycle of a room just added to Rooms
+func (gm *GameManager) beginLifecycleLocked(code models.RoomCode) {
+	gm.lifeMu.Lock()
+	defer gm.lifeMu.Unlock()
+
+	if gm.lifecycles == nil {
+		gm.lifecycles = make(map[models.RoomCode]*roomLifecycle)
+	}
+	if old := gm.lifecycles[code]; old != nil {
+		old.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	gm.lifecycles[code] = &roomLifecycle{ctx: ctx, cancel: cancel}
+}
+
+// endLifecycleLocked cancels a deleted room's context and, if any of its
+// goroutines are still running, watches that they exit within
+// RoomShutdownTimeout
+func (gm *GameManager) endLifecycleLocked(code models.RoomCode) {
+	gm.lifeMu.Lock()
+	life := gm.lifecycles[code]
+	delete(gm.lifecycles, code)
+	gm.lifeMu.Unlock()
+
+	if life == nil {
+		return
+	}
+	life.cancel()
+
+	if gm.RoomShutdownTimeout <= 0 || life.running.Load() == 0 {
+		return
+	}
+	timeout := gm.RoomShutdownTimeout
+	go func() {
+		done := make(chan struct{})
+		go func() {
+			life.wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(timeout):
+			gm.overdueShutdowns.Add(1)
+			log.Printf("⚠️ Room %s: %d goroutines still running %v after the room was deleted", code, life.running.Load(), timeout)
+		}
+	}()
+}
+
+// GoRoom runs fn in a goroutine scoped to the room: its context is
+// cancelled when the room is deleted or archived, and fn must return soon
+// after. Every goroutine that works for one room belongs here. It reports
+// false, without running fn, when there is no such room.
+//
+// It takes only the lifecycle lock, so observers may call it.
+func (gm *GameManager) GoRoom(code models.RoomCode, fn func(ctx context.Context)) bool {
+	gm.lifeMu.Lock()
+	defer gm.lifeMu.Unlock()
+
+	life := gm.lifecycles[code]
+	if life == nil {
+		return false
+	}
+	life.wg.Add(1)
+	life.running.Add(1)
+	gm.roomGoroutines.Add(1)
+	go func() {
+		defer life.wg.Done()
+		defer life.running.Add(-1)
+		defer gm.roomGoroutines.Add(-1)
+		fn(life.ctx)
+	}()
+	return true
+}
+
+// RoomGoroutines returns how many room-scoped goroutines are running
+func (gm *GameManager) RoomGoroutines() int64 {
+	return gm.roomGoroutines.Load()
+}
+
+// OverdueRoomShutdowns returns how many deleted rooms still had goroutines
+// running RoomShutdownTimeout later. Anything above zero is a leak.
+func (gm *GameManager) OverdueRoomShutdowns() int64 {
+	return gm.overdueShutdowns.Load()
+}
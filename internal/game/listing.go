@@ -0,0 +1,61 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// RoomListing is what the lobby browser shows of a public room. Nothing
+// else about a room is ever listed.
+type RoomListing struct {
+	Code                 models.RoomCode  `json:"code"`
+	HostName             string           `json:"hostName"`
+	Players              int              `json:"players"`
+	MaxPlayers           int              `json:"maxPlayers"`
+	Phase                models.GamePhase `json:"phase"`
+	JoinApprovalRequired bool             `json:"joinApprovalRequired"`
+	CreatedAt            models.Timestamp `json:"createdAt"`
+}
+
+// PublicRooms lists every public room, oldest first
+func (gm *GameManager) PublicRooms() []RoomListing {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	listings := []RoomListing{}
+	for _, room := range gm.Rooms {
+		if listing, listed := ListRoom(room); listed {
+			listings = append(listings, listing)
+		}
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		if !listings[i].CreatedAt.Equal(listings[j].CreatedAt.Time) {
+			return listings[i].CreatedAt.Before(listings[j].CreatedAt.Time)
+		}
+		return listings[i].Code < listings[j].Code
+	})
+	return listings
+}
+
+// ListRoom returns the room's listing and whether it is listed at all.
+// Practice rooms never are. It reads the room without taking the manager
+// lock, so it is meant for observers, which run with the lock held.
+func ListRoom(room *models.GameRoom) (RoomListing, bool) {
+	if !room.Settings.Public || room.Settings.PracticeMode {
+		return RoomListing{}, false
+	}
+
+	listing := RoomListing{
+		Code:                 room.Code,
+		Players:              len(room.Players),
+		MaxPlayers:           room.MaxPlayers,
+		Phase:                room.Phase,
+		JoinApprovalRequired: room.Settings.JoinApprovalRequired,
+		CreatedAt:            room.CreatedAt,
+	}
+	if host := room.Players[room.HostID]; host != nil {
+		listing.HostName = host.Username
+	}
+	return listing, true
+}
@@ -0,0 +1,158 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// wantIndexed fails the test unless the room's living index agrees with a
+// recount of its roster
+func wantIndexed(t *testing.T, gm *GameManager, code models.RoomCode, step string) {
+	t.Helper()
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		living, present := 0, 0
+		byRole := make(map[models.Role]int)
+		for _, player := range room.Players {
+			if !player.IsAlive {
+				continue
+			}
+			living++
+			byRole[player.Role]++
+			if player.IsConnected || player.IsBot {
+				present++
+			}
+		}
+		if n := room.LivingCount(); n != living {
+			t.Errorf("%s: %d living, the roster %d", step, n, living)
+		}
+		for _, role := range []models.Role{"", models.RoleVillager, models.RoleTiger, models.RoleAlphaTiger, models.RoleHunter, models.RoleShaman} {
+			if n := room.LivingCount(role); n != byRole[role] {
+				t.Errorf("%s: %d living %q, the roster %d", step, n, role, byRole[role])
+			}
+		}
+		if n := len(room.LivingPlayers()); n != living {
+			t.Errorf("%s: LivingPlayers lists %d, the roster %d", step, n, living)
+		}
+		if n := len(room.LivingConnected()); n != present {
+			t.Errorf("%s: LivingConnected lists %d, the roster %d", step, n, present)
+		}
+	})
+	if failures := gm.ValidationFailures(); failures != 0 {
+		t.Errorf("%s: debug validation saw %d problems", step, failures)
+	}
+}
+
+func TestLivingIndexFollowsEveryDeath(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 12, nil)
+	// p3 connects later, to walk out
+	for i := 1; i <= 12; i++ {
+		if i != 3 {
+			gm.SetConnected(code, fmt.Sprintf("p%d", i), true)
+		}
+	}
+	wantIndexed(t, gm, code, "lobby")
+
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleTiger,
+		"p4": models.RoleAlphaTiger,
+		"p5": models.RoleShaman,
+	})
+	wantIndexed(t, gm, code, "deal")
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p6")
+	wantIndexed(t, gm, code, "vote")
+
+	playNight(t, gm, code, map[string]string{"p1": "p7"})
+	if isAlive(gm, code, "p7") {
+		t.Fatal("the night kill missed")
+	}
+	wantIndexed(t, gm, code, "night kill")
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p2")
+	if _, err := gm.HunterShoot(code, "p2", "p4", true); err != nil {
+		t.Fatalf("shoot: %v", err)
+	}
+	wantIndexed(t, gm, code, "hunter shot")
+
+	nextPhase(t, gm, code)
+	leave(gm, code, "p3")
+	if withdrawals := gm.WithdrawAbsentPlayers(time.Now().Add(DefaultWithdrawAfter)); len(withdrawals) != 1 {
+		t.Fatalf("withdrawals = %+v, want p3", withdrawals)
+	}
+	wantIndexed(t, gm, code, "withdrawal")
+
+	// A game that ends and is played again brings everyone back to life
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if err := gm.endGameLocked(room, "human"); err != nil {
+			t.Fatalf("end game: %v", err)
+		}
+	})
+	if _, err := gm.Rematch(code, "p1"); err != nil {
+		t.Fatalf("rematch: %v", err)
+	}
+	wantIndexed(t, gm, code, "rematch")
+
+	if err := gm.RemovePlayer(code, "p8"); err != nil {
+		t.Fatalf("leave: %v", err)
+	}
+	if err := gm.KickPlayer(code, "p1", "p9"); err != nil {
+		t.Fatalf("kick: %v", err)
+	}
+	if _, err := gm.JoinRoom(code, "p13", "Player13"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	wantIndexed(t, gm, code, "lobby changes")
+
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Fatalf("start again: %v", err)
+	}
+	wantIndexed(t, gm, code, "second deal")
+}
+
+func TestLivingIndexOfACopy(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+
+	view, _ := gm.RoomView(code, "")
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p2")
+
+	// A view taken before the death keeps its own count
+	if n := view.LivingCount(); n != 6 {
+		t.Errorf("the earlier view counts %d living, want 6", n)
+	}
+	if problems := view.Validate(); len(problems) != 0 {
+		t.Errorf("the earlier view is inconsistent: %v", problems)
+	}
+	wantIndexed(t, gm, code, "after the vote")
+}
+
+// BenchmarkWinCheck checks for a winner in rooms of every size. The check
+// reads the living index, so its cost stays flat as rooms grow.
+func BenchmarkWinCheck(b *testing.B) {
+	for _, n := range []int{6, 12, maxPlayersLimit} {
+		b.Run(fmt.Sprintf("%d players", n), func(b *testing.B) {
+			gm := NewGameManager()
+			code := newTestRoom(b, gm, n, nil)
+			startTestGame(b, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleAlphaTiger})
+			withRoom(b, gm, code, func(room *models.GameRoom) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if ended, _ := gm.checkGameEndLocked(room); ended {
+						b.Fatal("the game ended")
+					}
+				}
+			})
+		})
+	}
+}
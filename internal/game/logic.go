@@ -1,55 +1,161 @@
 package game
 
 import (
-	"strings"
-
+	"github.com/werewolf-game/backend/internal/errs"
 	"github.com/werewolf-game/backend/internal/models"
 )
 
+var (
+	ErrNotAlphaTiger = &errs.Error{Code: "NOT_ALPHA_TIGER", Message: "not alpha tiger"}
+	ErrCurseUsed     = &errs.Error{Code: "CURSE_USED", Message: "curse already used"}
+)
+
 // ProcessNightPhase processes all night actions
-func (gm *GameManager) ProcessNightPhase(code string) (*NightResult, error) {
+func (gm *GameManager) ProcessNightPhase(code models.RoomCode) (*NightResult, error) {
 	// Note: This function is called from MoveToNextPhase which already has the lock
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return nil, ErrRoomNotFound
+		return nil, gm.missingRoomLocked(code)
 	}
 
+	// A night cut short settles the tiger team with whatever was decided
+	if !tigerTeamResolvedLocked(room) {
+		resolveTigerTeamLocked(room)
+	}
+
+	result := resolveNight(room, func(victim *models.Player, cause string) {
+		gm.killPlayerLocked(room, victim, cause)
+	})
+
+	// Fill in the outcomes players learn at dawn
+	settleActionsLocked(room, models.ActionProtect, func(*models.ActionRecord) string {
+		if result.attacked(room.HunterProtection) {
+			return "saved"
+		}
+		return "no_attack"
+	})
+	settleActionsLocked(room, models.ActionKill, func(*models.ActionRecord) string {
+		if result.diedOf(models.DeathByTiger) {
+			return "killed"
+		}
+		if result.firstNightSpared {
+			return "spared"
+		}
+		return "failed"
+	})
+	settleActionsLocked(room, models.ActionVision, func(*models.ActionRecord) string {
+		return result.VisionResult
+	})
+	settleActionsLocked(room, models.ActionInspect, func(*models.ActionRecord) string {
+		return result.VisionResult
+	})
+
+	result.Recaps = RecapBuilder{Room: room, Result: result}.Build()
+	room.NightTraces = append(room.NightTraces, models.NightTrace{Round: room.Round, Modifier: room.NightModifier, Steps: result.Trace})
+
+	// Reset night actions
+	room.TigerTarget = ""
+	room.HunterProtection = ""
+	room.ShamanVision = ""
+	room.TigerTeam = nil
+
+	return result, nil
+}
+
+// resolveNight works out the night's outcome from the recorded actions.
+// It never touches the room except through kill, so previews can run it on a copy.
+func resolveNight(room *models.GameRoom, kill func(victim *models.Player, cause string)) *NightResult {
 	result := &NightResult{
-		Killed:       "",
-		KilledName:   "",
+		Deaths:       []Death{},
 		Protected:    false,
 		ShamanSaved:  false,
 		ShamanVision: "",
 		VisionResult: "",
 	}
 
-	// 1. Check if hunter protected the tiger's target
+	trace := func(key string, params ...string) {
+		result.Trace = append(result.Trace, traceStep(key, params...))
+	}
+
+	// Every death goes through die so the result lists them all in order
+	die := func(victim *models.Player, cause string) {
+		kill(victim, cause)
+		result.addDeath(victim, cause)
+		trace(TraceKilled, "target", victim.Username, "cause", cause)
+	}
+
+	// Whether the shaman is looking at the dead is settled before anyone dies tonight
+	inspectingDead := false
+	if seen := room.Players[room.ShamanVision]; seen != nil && !seen.IsAlive {
+		inspectingDead = true
+	}
+
+	modifier := activeNightModifier(room)
+	if modifier != nil {
+		trace(TraceModifier, "modifier", modifierName(modifier))
+	}
+
+	for _, player := range playersByIDLocked(room) {
+		if passedTonight(room, player) {
+			trace(TracePassed, "player", player.Username)
+		}
+	}
+
 	if room.TigerTarget != "" {
-		if room.HunterProtection == room.TigerTarget {
+		trace(TraceTigerTarget, "target", traceName(room, room.TigerTarget))
+	} else {
+		trace(TraceNoTigerTarget)
+	}
+	if room.HunterProtection != "" {
+		trace(TraceHunterProtected, "target", traceName(room, room.HunterProtection))
+	}
+
+	// 0. The night's modifier decides who the tigers actually attack
+	var targets []string
+	if room.TigerTarget != "" {
+		targets = []string{room.TigerTarget}
+	}
+	if modifier != nil {
+		attacked := modifier.Kills(room, targets)
+		if len(attacked) == 0 && len(targets) > 0 {
+			trace(TraceKillsCalled, "modifier", modifierName(modifier))
+		}
+		for _, targetID := range attacked {
+			if targetID != room.TigerTarget {
+				trace(TraceTigerTarget, "target", traceName(room, targetID))
+			}
+		}
+		targets = attacked
+	}
+	if room.Settings.FirstNightNoKill && room.NightNumber == 1 && len(targets) > 0 {
+		trace(TraceFirstNightSpared)
+		result.firstNightSpared = true
+		targets = nil
+	}
+	result.targets = targets
+
+	// 1. Check if hunter protected each of the tigers' targets
+	for _, targetID := range targets {
+		if room.HunterProtection == targetID {
 			result.Protected = true
-			result.Killed = ""
+			trace(TraceKillBlocked, "target", traceName(room, targetID))
 		} else {
 			// Check if victim is shaman who saw alpha tiger
-			victim := room.Players[room.TigerTarget]
+			victim := room.Players[targetID]
 			if victim != nil && victim.Role == models.RoleShaman && room.ShamanVision != "" {
 				// Check if shaman saw alpha tiger tonight
 				seen := room.Players[room.ShamanVision]
-				if seen != nil && seen.Role == models.RoleAlphaTiger && !seen.HasUsedCurse {
+				if seen != nil && !inspectingDead && seen.Role == models.RoleAlphaTiger && !seen.HasUsedAbility(models.AbilityCurse) {
 					// Shaman survives (ดวงแข็ง)
 					result.ShamanSaved = true
-					result.Killed = ""
+					trace(TraceShamanLuck, "target", victim.Username, "seen", seen.Username)
 				} else {
 					// Shaman dies
-					victim.IsAlive = false
-					result.Killed = room.TigerTarget
-					result.KilledName = victim.Username
+					die(victim, models.DeathByTiger)
 				}
-			} else {
+			} else if victim != nil {
 				// Normal death
-				victim.IsAlive = false
-				result.Killed = room.TigerTarget
-				result.KilledName = victim.Username
+				die(victim, models.DeathByTiger)
 			}
 		}
 	}
@@ -57,13 +163,22 @@ func (gm *GameManager) ProcessNightPhase(code string) (*NightResult, error) {
 	// 2. Process shaman's vision
 	if room.ShamanVision != "" {
 		target := room.Players[room.ShamanVision]
-		if target != nil {
+		if target != nil && modifier != nil && !modifier.Vision(room) {
+			result.VisionResult = "unknown"
+			result.ShamanVision = target.Username
+			trace(TraceVisionClouded, "modifier", modifierName(modifier), "target", target.Username)
+		} else if target != nil && inspectingDead {
+			// The dead show their true role; a curse no longer disguises them
+			result.VisionResult = string(target.Role)
+			result.ShamanVision = target.Username
+			trace(TraceDeadInspected, "target", target.Username, "result", result.VisionResult)
+		} else if target != nil {
 			// Check if target is cursed
 			if target.IsCursed {
 				result.VisionResult = "tiger"
 			} else if target.Role == models.RoleAlphaTiger {
 				// Alpha tiger can hide unless curse was used
-				if target.HasUsedCurse {
+				if target.HasUsedAbility(models.AbilityCurse) {
 					result.VisionResult = "tiger"
 				} else {
 					result.VisionResult = "human"
@@ -74,59 +189,71 @@ func (gm *GameManager) ProcessNightPhase(code string) (*NightResult, error) {
 				result.VisionResult = "human"
 			}
 			result.ShamanVision = target.Username
+			trace(TraceVision, "target", target.Username, "result", result.VisionResult)
 		}
 	}
 
-	// Reset night actions
-	room.TigerTarget = ""
-	room.HunterProtection = ""
-	room.ShamanVision = ""
+	return result
+}
 
-	return result, nil
+// passedTonight reports whether the player chose to pass this night
+func passedTonight(room *models.GameRoom, player *models.Player) bool {
+	for i := len(player.ActionHistory) - 1; i >= 0; i-- {
+		record := player.ActionHistory[i]
+		if record.Round != room.Round || record.Phase != models.PhaseNight {
+			return false
+		}
+		if record.ActionType == models.ActionPass {
+			return true
+		}
+	}
+	return false
 }
 
 // SetAlphaTigerCurse sets a curse on a player
-func (gm *GameManager) SetAlphaTigerCurse(code, alphaTigerID, targetID string) error {
+func (gm *GameManager) SetAlphaTigerCurse(code models.RoomCode, alphaTigerID, targetID, turnToken string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
 	alphaTiger := room.Players[alphaTigerID]
 	if alphaTiger == nil || alphaTiger.Role != models.RoleAlphaTiger {
-		return &GameError{"not alpha tiger"}
+		return ErrNotAlphaTiger
 	}
 
-	if alphaTiger.HasUsedCurse {
-		return &GameError{"curse already used"}
+	if err := duplicateActionLocked(room, alphaTiger, models.ActionCurse, targetID); err != nil {
+		return err
 	}
 
-	target := room.Players[targetID]
-	if target == nil {
-		return &GameError{"target not found"}
+	if _, err := nightActorLocked(room, alphaTigerID, turnToken); err != nil {
+		return err
 	}
 
-	// Set curse
-	target.IsCursed = true
-	alphaTiger.HasUsedCurse = true
-	room.CursedPlayer = targetID
+	if !alphaTiger.AbilityReady(models.AbilityCurse, room.NightNumber) {
+		return ErrCurseUsed
+	}
 
-	return nil
+	target := room.Players[targetID]
+	if target == nil || !target.IsAlive {
+		return ErrInvalidActionTarget
+	}
+
+	// The curse is the alpha's decision for the tiger team's step
+	return submitTigerDecisionLocked(room, alphaTiger, models.ActionCurse, target)
 }
 
 // SetTigerTarget sets the tiger's kill target
-func (gm *GameManager) SetTigerTarget(code, targetID string) error {
+func (gm *GameManager) SetTigerTarget(code models.RoomCode, targetID string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
 	room.TigerTarget = targetID
@@ -134,24 +261,23 @@ func (gm *GameManager) SetTigerTarget(code, targetID string) error {
 }
 
 // SetHunterProtection sets the hunter's protection target
-func (gm *GameManager) SetHunterProtection(code, hunterID, targetID string) error {
+func (gm *GameManager) SetHunterProtection(code models.RoomCode, hunterID, targetID string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
 	hunter := room.Players[hunterID]
 	if hunter == nil || hunter.Role != models.RoleHunter {
-		return &GameError{"not hunter"}
+		return ErrNotHunter
 	}
 
 	// Can't protect same person two nights in a row
 	if hunter.LastProtected == targetID {
-		return &GameError{"cannot protect same person twice in a row"}
+		return ErrProtectedTwice
 	}
 
 	room.HunterProtection = targetID
@@ -161,14 +287,13 @@ func (gm *GameManager) SetHunterProtection(code, hunterID, targetID string) erro
 }
 
 // SetShamanVision sets the shaman's vision target
-func (gm *GameManager) SetShamanVision(code, targetID string) error {
+func (gm *GameManager) SetShamanVision(code models.RoomCode, targetID string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
 	room.ShamanVision = targetID
@@ -176,14 +301,13 @@ func (gm *GameManager) SetShamanVision(code, targetID string) error {
 }
 
 // ProcessVoting processes voting results
-func (gm *GameManager) ProcessVoting(code string, votes map[string]string) (string, error) {
+func (gm *GameManager) ProcessVoting(code models.RoomCode, votes map[string]string) (string, error) {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return "", ErrRoomNotFound
+		return "", gm.missingRoomLocked(code)
 	}
 
 	// Count votes
@@ -193,25 +317,15 @@ func (gm *GameManager) ProcessVoting(code string, votes map[string]string) (stri
 	}
 
 	// Find player with most votes
-	maxVotes := 0
-	var eliminated string
-	for playerID, count := range voteCount {
-		if count > maxVotes {
-			maxVotes = count
-			eliminated = playerID
-		}
-	}
+	outcome := settleVoteLocked(room, voteCount)
+	gm.recordVoteOutcomeLocked(room, outcome)
+	eliminated := outcome.EliminatedID
 
 	// Eliminate player
 	if eliminated != "" {
 		player := room.Players[eliminated]
 		if player != nil {
-			player.IsAlive = false
-
-			// If hunter, allow shooting
-			if player.Role == models.RoleHunter {
-				player.CanShoot = true
-			}
+			gm.killPlayerLocked(room, player, models.DeathByVote)
 		}
 	}
 
@@ -221,10 +335,100 @@ func (gm *GameManager) ProcessVoting(code string, votes map[string]string) (stri
 
 // NightResult represents the result of night actions
 type NightResult struct {
-	Killed       string `json:"killed"`       // ID of killed player
-	KilledName   string `json:"killedName"`   // Name of killed player
-	Protected    bool   `json:"protected"`    // Was target protected
-	ShamanSaved  bool   `json:"shamanSaved"`  // Shaman saved by luck
-	ShamanVision string `json:"shamanVision"` // Who shaman saw
-	VisionResult string `json:"visionResult"` // "tiger" or "human"
+	Deaths       []Death `json:"deaths"`       // Everyone who died tonight, in order
+	Protected    bool    `json:"protected"`    // Was target protected
+	ShamanSaved  bool    `json:"shamanSaved"`  // Shaman saved by luck
+	ShamanVision string  `json:"shamanVision"` // Who shaman saw
+	VisionResult string  `json:"visionResult"` // "tiger" or "human"
+	// Concealed marks a mystery dawn: the public result leaves out who the
+	// tigers killed until the body is found
+	Concealed bool `json:"concealed,omitempty"`
+
+	// Deprecated: legacy single-death fields, set to the first death.
+	// Kept for one release so older clients keep working; use Deaths.
+	Killed     string `json:"killed"`     // ID of killed player
+	KilledName string `json:"killedName"` // Name of killed player
+
+	// Recaps are the private per-role summaries, keyed by player ID.
+	// They are sent to each player separately, never with the public result.
+	Recaps map[string]Recap `json:"-"`
+	// Trace explains the resolution step by step. It is kept on the room
+	// and only revealed once the game has ended.
+	Trace []models.NightTraceStep `json:"-"`
+
+	// targets are the players the tigers attacked, protected or not
+	targets []string
+	// firstNightSpared is set when FirstNightNoKill called off the attack
+	firstNightSpared bool
+}
+
+// Death is one player's death during the night
+type Death struct {
+	PlayerID string `json:"playerId"`
+	Username string `json:"username"`
+	Cause    string `json:"cause"`
+}
+
+// Public is the result as the whole room may see it. What the shaman saw is
+// theirs alone; it reaches them in their recap.
+func (r *NightResult) Public() *NightResult {
+	if r == nil {
+		return nil
+	}
+	public := *r
+	public.ShamanVision = ""
+	public.VisionResult = ""
+	if r.Concealed {
+		// Only how many died and of what is known until the body is found
+		public.Deaths = make([]Death, len(r.Deaths))
+		for i, death := range r.Deaths {
+			public.Deaths[i] = Death{Cause: death.Cause}
+		}
+		public.Killed = ""
+		public.KilledName = ""
+	}
+	return &public
+}
+
+// addDeath appends a death, keeping the legacy fields on the first one
+func (r *NightResult) addDeath(victim *models.Player, cause string) {
+	r.Deaths = append(r.Deaths, Death{
+		PlayerID: victim.ID,
+		Username: victim.Username,
+		Cause:    cause,
+	})
+	if r.Killed == "" {
+		r.Killed = victim.ID
+		r.KilledName = victim.Username
+	}
+}
+
+// attacked reports whether the tigers went after the player tonight
+func (r *NightResult) attacked(playerID string) bool {
+	for _, target := range r.targets {
+		if playerID != "" && target == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// died reports whether the player died tonight
+func (r *NightResult) died(playerID string) bool {
+	for _, death := range r.Deaths {
+		if death.PlayerID == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// diedOf reports whether anyone died of the given cause tonight
+func (r *NightResult) diedOf(cause string) bool {
+	for _, death := range r.Deaths {
+		if death.Cause == cause {
+			return true
+		}
+	}
+	return false
 }
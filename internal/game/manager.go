@@ -1,219 +1,522 @@
 package game
 
 import (
-	"math/rand"
-	"strings"
+	"fmt"
+	"math"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/errs"
 	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/sanitize"
 )
 
 // GameManager manages all game rooms
 type GameManager struct {
-	Rooms map[string]*models.GameRoom
-	mu    sync.RWMutex
+	Rooms    map[models.RoomCode]*models.GameRoom
+	MaxRooms int // 0 means no cap; set before serving requests
+	// DebugValidate runs GameRoom.Validate on every room after each mutation
+	DebugValidate bool
+	// SettingsAckCooldown holds StartGame back after a settings change; 0 disables it
+	SettingsAckCooldown time.Duration
+	// NightCeiling is the longest a night may last before the night watch
+	// resolves it; 0 disables it
+	NightCeiling time.Duration
+	// WithdrawAfter is how long a tiger-team player may stay disconnected
+	// before the withdrawal watch has them flee; 0 disables it
+	WithdrawAfter time.Duration
+	// ReconnectVoteGrace is the voting time a voter who reconnects late in
+	// the vote is given; 0 disables the extension
+	ReconnectVoteGrace time.Duration
+	// ChatMemoryLimit is how many chat messages a room keeps in memory;
+	// 0 means the default of 100
+	ChatMemoryLimit int
+	// ChatStore receives chat messages that no longer fit in memory; nil
+	// drops them instead
+	ChatStore ChatStore
+	// Seed picks each new room's random seed; nil seeds from the clock.
+	// Set it to replay games exactly.
+	Seed func() int64
+	// AutoRepairStuckRooms lets the janitor repair rooms it finds stuck in a
+	// phase instead of only reporting them
+	AutoRepairStuckRooms bool
+	// RoomShutdownTimeout is how long a deleted room's goroutines have to
+	// exit before they are reported as leaked; 0 stops checking
+	RoomShutdownTimeout time.Duration
+
+	mu                 sync.RWMutex
+	observers          []RoomObserver
+	janitor            janitorSchedule
+	validationFailures int
+	nightsForced       int
+	chatSpilled        int
+	stuckRooms         int
+	disconnects        map[string]int // by reason
+	archived           map[models.RoomCode]time.Time
+	aliases            map[string]models.RoomCode // by normalized alias
+
+	// lifeMu guards lifecycles; it is taken after mu, never before
+	lifeMu           sync.Mutex
+	lifecycles       map[models.RoomCode]*roomLifecycle
+	roomGoroutines   atomic.Int64
+	overdueShutdowns atomic.Int64
 }
 
-// NewGameManager creates a new game manager
-func NewGameManager() *GameManager {
+// NewGameManager creates a new game manager. Observers are notified of room
+// lifecycle events in the order given (see RoomObserver).
+func NewGameManager(observers ...RoomObserver) *GameManager {
 	return &GameManager{
-		Rooms: make(map[string]*models.GameRoom),
+		Rooms:               make(map[models.RoomCode]*models.GameRoom),
+		SettingsAckCooldown: DefaultSettingsAckCooldown,
+		NightCeiling:        DefaultNightCeiling,
+		WithdrawAfter:       DefaultWithdrawAfter,
+		ReconnectVoteGrace:  DefaultReconnectVoteGrace,
+		RoomShutdownTimeout: DefaultRoomShutdownTimeout,
+		observers:           observers,
 	}
 }
 
-// CreateRoom creates a new game room
-func (gm *GameManager) CreateRoom(hostID, hostUsername string) *models.GameRoom {
+// CreateRoom creates a new game room, or returns ErrServerAtCapacity when
+// the server already holds MaxRooms rooms
+func (gm *GameManager) CreateRoom(hostID, hostUsername string, settings models.RoomSettings) (*models.GameRoom, error) {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code := generateRoomCode()
+	if gm.MaxRooms > 0 && len(gm.Rooms) >= gm.MaxRooms {
+		return nil, ErrServerAtCapacity
+	}
+
+	hostUsername, err := sanitize.Text(hostUsername, sanitize.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.MaxPlayers < minPlayers || settings.MaxPlayers > maxPlayersLimit {
+		settings.MaxPlayers = models.DefaultRoomSettings().MaxPlayers
+	}
+
+	code := gm.newRoomCodeLocked()
+	delete(gm.archived, code) // the code now belongs to a new room
 	room := &models.GameRoom{
 		Code:       code,
 		HostID:     hostID,
 		Players:    make(map[string]*models.Player),
 		Phase:      models.PhaseWaiting,
 		Round:      0,
-		MaxPlayers: 10,
-		CreatedAt:  time.Now(),
+		MaxPlayers: settings.MaxPlayers,
+		CreatedAt:  models.NewTimestamp(time.Now()),
+		Settings:   settings,
+		Seed:       gm.newSeed(),
 	}
+	room.LastActivityAt = room.CreatedAt.Time
+	gm.forgetChatLocked(code) // a reused code must not inherit an old room's chat
 
 	// Add host as first player
-	room.Players[hostID] = &models.Player{
-		ID:       hostID,
-		Username: hostUsername,
-		IsAlive:  true,
-		IsReady:  false,
-		RoomCode: code,
-		JoinedAt: time.Now(),
-	}
+	room.AddPlayer(&models.Player{
+		ID:           hostID,
+		Username:     hostUsername,
+		IsAlive:      true,
+		IsReady:      false,
+		ColorSlot:    0,
+		RoomCode:     code,
+		JoinedAt:     models.NewTimestamp(time.Now()),
+		SessionToken: newSessionToken(),
+	})
 
 	gm.Rooms[code] = room
-	return room
+	gm.beginLifecycleLocked(code)
+	gm.assignAliasesLocked(room)
+	gm.notify(room, func(o RoomObserver) { o.OnRoomCreated(room) })
+	return room, nil
 }
 
 // GetRoom retrieves a room by code
-func (gm *GameManager) GetRoom(code string) (*models.GameRoom, bool) {
+func (gm *GameManager) GetRoom(code models.RoomCode) (*models.GameRoom, bool) {
 	gm.mu.RLock()
 	defer gm.mu.RUnlock()
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	return room, exists
 }
 
+// RoomPhase returns the room's current phase
+func (gm *GameManager) RoomPhase(code models.RoomCode) (models.GamePhase, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return "", false
+	}
+	return room.Phase, true
+}
+
+// RoomSettings returns a copy of the room's settings the caller may change,
+// say by decoding a partial update over it
+func (gm *GameManager) RoomSettings(code models.RoomCode) (models.RoomSettings, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return models.RoomSettings{}, false
+	}
+
+	settings := room.Settings
+	if settings.Composition != nil {
+		settings.Composition = copyComposition(settings.Composition)
+	}
+	if settings.NightCooldowns != nil {
+		settings.NightCooldowns = copyComposition(settings.NightCooldowns)
+	}
+	settings.NightModifiers = append([]string(nil), settings.NightModifiers...)
+	return settings, true
+}
+
+// RoomPhases returns every room's current phase
+func (gm *GameManager) RoomPhases() map[models.RoomCode]models.GamePhase {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	phases := make(map[models.RoomCode]models.GamePhase, len(gm.Rooms))
+	for code, room := range gm.Rooms {
+		phases[code] = room.Phase
+	}
+	return phases
+}
+
 // JoinRoom adds a player to a room
-func (gm *GameManager) JoinRoom(code, playerID, username string) (*models.GameRoom, error) {
+func (gm *GameManager) JoinRoom(code models.RoomCode, playerID, username string) (*models.GameRoom, error) {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return nil, ErrRoomNotFound
+		return nil, gm.missingRoomLocked(code)
 	}
 
-	if len(room.Players) >= room.MaxPlayers {
-		return nil, ErrRoomFull
+	if room.Settings.JoinApprovalRequired {
+		return nil, ErrJoinApprovalRequired
+	}
+
+	if err := gm.joinRoomLocked(room, playerID, username); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// JoinAlternatives is what a player refused at the door can do instead
+type JoinAlternatives struct {
+	CanSpectate  bool             `json:"canSpectate"` // connect without a player ID to watch
+	CanLateJoin  bool             `json:"canLateJoin"` // take a seat in the game under way
+	PlayersAlive int              `json:"playersAlive"`
+	Phase        models.GamePhase `json:"phase"`
+	Round        int              `json:"round"`
+	// SpectatorDelaySeconds is how far behind the watcher would be, when
+	// the room can only be watched delayed
+	SpectatorDelaySeconds int `json:"spectatorDelaySeconds,omitempty"`
+}
+
+// JoinAlternatives describes the room for a player whose join was refused,
+// given how far behind delayed spectators watch (0 when the server has
+// the mode off). Anyone may watch a finished game as it is; any other room
+// can be watched only delayed. Nobody joins a game under way.
+func (gm *GameManager) JoinAlternatives(code models.RoomCode, spectatorDelay time.Duration) (JoinAlternatives, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return JoinAlternatives{}, false
+	}
+
+	alternatives := JoinAlternatives{
+		CanSpectate: room.Phase == models.PhaseEnded || spectatorDelay > 0,
+		Phase:       room.Phase,
+		Round:       room.Round,
+	}
+	if room.Phase != models.PhaseEnded && spectatorDelay > 0 {
+		alternatives.SpectatorDelaySeconds = int(spectatorDelay / time.Second)
+	}
+	alternatives.PlayersAlive = room.LivingCount()
+	return alternatives, true
+}
+
+// SetConnected records a player's websocket opening or closing and reports
+// whether a reconnection restored a lost quorum, resuming the game, and any
+// time it added to the vote for the returning player. A player may hold
+// several connections; they count as connected while any is open.
+func (gm *GameManager) SetConnected(code models.RoomCode, playerID string, connected bool) (bool, *PhaseExtension) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return false, nil
+	}
+
+	player := room.Players[playerID]
+	if player == nil {
+		return false, nil
+	}
+
+	reconnecting := connected && player.Connections == 0 && player.DisconnectedAt != nil
+	if connected {
+		player.Connections++
+	} else if player.Connections > 0 {
+		player.Connections--
+	}
+	player.IsConnected = player.Connections > 0
+	if player.IsConnected {
+		player.DisconnectedAt = nil
+	} else if player.DisconnectedAt == nil {
+		now := time.Now()
+		player.DisconnectedAt = &now
+	}
+
+	now := time.Now()
+	if connected && restoreQuorumLocked(room, now) {
+		return true, nil
+	}
+	if reconnecting {
+		return false, extendVoteForReconnectLocked(room, player, gm.ReconnectVoteGrace, now)
+	}
+	return false, nil
+}
+
+// joinRoomLocked adds a player to the room if the lobby accepts them
+func (gm *GameManager) joinRoomLocked(room *models.GameRoom, playerID, username string) error {
+	if room.Settings.PracticeMode {
+		return ErrPracticeSinglePlayer
 	}
 
 	if room.Phase != models.PhaseWaiting {
-		return nil, ErrGameAlreadyStarted
+		return ErrGameAlreadyStarted
 	}
 
-	room.Players[playerID] = &models.Player{
-		ID:       playerID,
-		Username: username,
-		IsAlive:  true,
-		IsReady:  false,
-		RoomCode: code,
-		JoinedAt: time.Now(),
+	if len(room.Players) >= room.MaxPlayers {
+		return ErrRoomFull
 	}
 
-	return room, nil
+	username, err := sanitize.Text(username, sanitize.Username)
+	if err != nil {
+		return err
+	}
+
+	player := &models.Player{
+		ID:           playerID,
+		Username:     username,
+		IsAlive:      true,
+		IsReady:      false,
+		ColorSlot:    firstFreeColorSlotLocked(room),
+		RoomCode:     room.Code,
+		JoinedAt:     models.NewTimestamp(time.Now()),
+		SessionToken: newSessionToken(),
+	}
+	room.AddPlayer(player)
+	room.LastActivityAt = player.JoinedAt.Time
+	gm.notify(room, func(o RoomObserver) { o.OnPlayerJoined(room, player) })
+
+	return nil
 }
 
-// RemovePlayer removes a player from a room
-func (gm *GameManager) RemovePlayer(code, playerID string) error {
+// RemovePlayer takes a player who leaves out of the lobby
+func (gm *GameManager) RemovePlayer(code models.RoomCode, playerID string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
+	}
+
+	if room.Phase != models.PhaseWaiting {
+		return ErrGameAlreadyStarted
+	}
+
+	player := room.Players[playerID]
+	if player == nil {
+		return ErrPlayerNotFound
 	}
 
-	delete(room.Players, playerID)
+	room.RemovePlayer(playerID)
+	room.LastActivityAt = time.Now()
 
 	// Delete room if empty
 	if len(room.Players) == 0 {
 		delete(gm.Rooms, code)
+		gm.forgetAliasesLocked(room)
+		gm.forgetChatLocked(code)
+		gm.endLifecycleLocked(code)
+		gm.notify(room, func(o RoomObserver) { o.OnRoomDeleted(room) })
+		return nil
 	}
 
+	// The lobby keeps a host
+	if room.HostID == playerID {
+		room.HostID = longestStandingPlayerLocked(room).ID
+	}
+	gm.notify(room, func(o RoomObserver) { o.OnPlayerLeft(room, player) })
+
 	return nil
 }
 
-// StartGame assigns roles and starts the game
-func (gm *GameManager) StartGame(code string) error {
+// StartGame assigns roles and starts the game (host only). A lobby whose
+// host has gone is given a new one instead, and the start is refused with
+// ErrHostReassigned so that the new host starts it deliberately.
+func (gm *GameManager) StartGame(code models.RoomCode, playerID string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
-	if len(room.Players) < 5 {
-		return ErrNotEnoughPlayers
+	if len(room.Players) > 0 && room.Players[room.HostID] == nil {
+		room.HostID = longestStandingPlayerLocked(room).ID
+		return ErrHostReassigned.WithParams(map[string]interface{}{"hostId": room.HostID})
+	}
+
+	if err := authorizeLocked(room, playerID, PermStartGame); err != nil {
+		return err
+	}
+
+	return gm.startGameLocked(room)
+}
+
+// startGameLocked checks that the lobby may start and starts it
+func (gm *GameManager) startGameLocked(room *models.GameRoom) error {
+	if err := startableLocked(room); err != nil {
+		return err
+	}
+
+	if err := settingsGuardLocked(room, gm.SettingsAckCooldown, time.Now()); err != nil {
+		return err
+	}
+	room.AutoStartAt = nil
+	room.AutoStartHeld = false
+
+	// Practice rooms are topped up with bots instead
+	if room.Settings.PracticeMode {
+		fillBotsLocked(room)
 	}
 
-	// Assign roles
+	// Assign roles and seats
 	assignRoles(room)
+	assignSeats(room)
 
 	// Start game
 	now := time.Now()
-	room.StartedAt = &now
-	room.Phase = models.PhaseDay // เริ่มที่เช้าเลย
-	room.Round = 1               // เริ่มรอบ 1
-	endTime := now.Add(2 * time.Minute)
-	room.PhaseEndTime = &endTime // ตั้งเวลา 2 นาทีสำหรับเฟสกลางวัน
+	room.StartedAt = models.TimestampPtr(now)
+	room.Round = 0 // เฟสกลางวันแรกจะนับเป็นรอบ 1
+	room.NightNumber = 0
+	room.NightTraces = nil
+	room.VoteHistory = nil
+	room.VoteOutcomes = nil
+
+	// เริ่มที่เช้าเลย
+	return gm.transitionLocked(room, models.PhaseDay, ReasonGameStarted)
+}
 
-	// Initialize night actions tracking
-	for _, player := range room.Players {
-		player.HasActedThisNight = false
+// startableLocked checks that the lobby could be dealt its roles. Practice
+// rooms count the bots StartGame will add.
+func startableLocked(room *models.GameRoom) error {
+	if room.Phase != models.PhaseWaiting {
+		return ErrGameAlreadyStarted
 	}
-	room.NightActionsCompleted = make(map[string]bool)
 
-	return nil
+	players := len(room.Players)
+	if room.Settings.PracticeMode && players < minPlayers {
+		players = minPlayers
+	}
+	if players < minPlayers {
+		return ErrNotEnoughPlayers
+	}
+
+	// The lobby may have changed size since the composition was chosen
+	composition := room.Settings.Composition
+	if len(composition) == 0 {
+		composition = DefaultComposition(players)
+	}
+	return validateComposition(composition, players)
 }
 
-// assignRoles randomly assigns roles to players
+// assignRoles randomly deals the room's composition to its players
 func assignRoles(room *models.GameRoom) {
 	playerCount := len(room.Players)
-
-	// Calculate role distribution based on player count
-	// 5 คน: เสือ 1, ชาวบ้าน 2, พราน 1, หมอผี 1
-	// 6 คน: เสือ 1, ชาวบ้าน 3, พราน 1, หมอผี 1
-	// 7+ คน: เสือ 1, พญาสมิง 1, ชาวบ้าน (เหลือ), พราน 1, หมอผี 1
+	composition := compositionFor(room)
 
 	roles := make([]models.Role, 0, playerCount)
-
-	if playerCount >= 7 {
-		// 7+ คน: มีพญาสมิง
-		roles = append(roles, models.RoleAlphaTiger) // พญาสมิง
-		roles = append(roles, models.RoleTiger)      // เสือสมิง
-	} else {
-		// 5-6 คน: มีแค่เสือสมิง (ไม่มีพญาสมิง)
-		roles = append(roles, models.RoleTiger) // เสือสมิง
-	}
-
-	// เพิ่มบทบาทพิเศษ (มีเสมอ)
-	roles = append(roles, models.RoleHunter) // นายพราน
-	roles = append(roles, models.RoleShaman) // หมอผี
-
-	// เติมที่เหลือด้วยชาวบ้าน
-	for len(roles) < playerCount {
-		roles = append(roles, models.RoleVillager)
+	for _, role := range []models.Role{models.RoleAlphaTiger, models.RoleTiger, models.RoleHunter, models.RoleShaman, models.RoleVillager} {
+		for n := 0; n < composition[role]; n++ {
+			roles = append(roles, role)
+		}
 	}
 
 	// Shuffle roles
-	rand.Shuffle(len(roles), func(i, j int) {
+	rng := roomRandLocked(room)
+	rng.Shuffle(len(roles), func(i, j int) {
 		roles[i], roles[j] = roles[j], roles[i]
 	})
 
 	// Assign to players
-	i := 0
-	for _, player := range room.Players {
-		player.Role = roles[i]
+	for i, player := range playersByIDLocked(room) {
+		room.SetRole(player, roles[i])
 		player.IsCursed = false
-		player.HasUsedCurse = false
-		player.CanShoot = (roles[i] == models.RoleHunter) // Hunter can shoot when they die
+		player.Abilities = roleAbilities(roles[i], room.Settings)
 		player.LastProtected = ""
-		i++
 	}
 }
 
+// roleAbilities returns the limited abilities a role starts the game with.
+// A night ability is limited only when the room gives it a cooldown.
+func roleAbilities(role models.Role, settings models.RoomSettings) map[string]*models.AbilityUse {
+	abilities := make(map[string]*models.AbilityUse)
+	switch role {
+	case models.RoleAlphaTiger:
+		abilities[models.AbilityCurse] = &models.AbilityUse{CooldownNights: models.CooldownForever}
+	case models.RoleHunter:
+		abilities[models.AbilityShoot] = &models.AbilityUse{CooldownNights: models.CooldownForever} // Hunter can shoot when they die
+	}
+	if cooldown := settings.NightCooldowns[role]; cooldown != 0 {
+		if name := nightAbility(role); name != "" {
+			abilities[name] = &models.AbilityUse{CooldownNights: cooldown}
+		}
+	}
+	return abilities
+}
+
 // generateRoomCode generates a random 6-character room code
-func generateRoomCode() string {
-	code := uuid.New().String()[:6]
-	return strings.ToUpper(code)
+func generateRoomCode() models.RoomCode {
+	return models.NormalizeRoomCode(uuid.New().String()[:6])
+}
+
+// newRoomCodeLocked draws codes until one is not held by a live room
+func (gm *GameManager) newRoomCodeLocked() models.RoomCode {
+	for {
+		code := generateRoomCode()
+		if _, taken := gm.Rooms[code]; !taken {
+			return code
+		}
+	}
 }
 
 // SkipPhase allows host to skip current phase
-func (gm *GameManager) SkipPhase(code, playerID string) error {
+func (gm *GameManager) SkipPhase(code models.RoomCode, playerID string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
-	if room.HostID != playerID {
-		return &GameError{"only host can skip phase"}
+	if err := authorizeLocked(room, playerID, PermSkipPhase); err != nil {
+		return err
 	}
 
 	// Clear phase end time
@@ -223,176 +526,117 @@ func (gm *GameManager) SkipPhase(code, playerID string) error {
 }
 
 // MarkNightActionComplete marks a player as having completed their night action
-func (gm *GameManager) MarkNightActionComplete(code, playerID string) error {
+func (gm *GameManager) MarkNightActionComplete(code models.RoomCode, playerID string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
 	player := room.Players[playerID]
 	if player == nil {
-		return &GameError{"player not found"}
-	}
-
-	// Mark as acted
-	player.HasActedThisNight = true
-
-	// Initialize map if nil
-	if room.NightActionsCompleted == nil {
-		room.NightActionsCompleted = make(map[string]bool)
+		return ErrPlayerNotFound
 	}
 
-	room.NightActionsCompleted[playerID] = true
+	markNightActionCompleteLocked(room, player)
 
 	return nil
 }
 
 // CheckAllNightActionsComplete checks if all required night actions are complete
-func (gm *GameManager) CheckAllNightActionsComplete(code string) bool {
+func (gm *GameManager) CheckAllNightActionsComplete(code models.RoomCode) bool {
 	gm.mu.RLock()
 	defer gm.mu.RUnlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
 		return false
 	}
 
-	// Count players with night actions
-	required := 0
-	for _, player := range room.Players {
-		if !player.IsAlive {
-			continue
-		}
-		// Only count players with night abilities
-		if player.Role == models.RoleTiger || player.Role == models.RoleAlphaTiger ||
-			player.Role == models.RoleHunter || player.Role == models.RoleShaman {
-			required++
-		}
+	if room.Settings.NightSleepConfirmation {
+		return room.CurrentNightRole == "" && nightAcknowledgedLocked(room)
 	}
 
-	room.NightActionsRequired = required
+	// Count players with night actions. The count is not kept on the room:
+	// it would tell every viewer how many night roles are still alive.
+	required := room.LivingCount(models.RoleTiger, models.RoleAlphaTiger, models.RoleHunter, models.RoleShaman)
 
 	// Check if all have acted
 	completed := len(room.NightActionsCompleted)
 	return completed >= required
 }
 
-// StartDayPhase sets up the day phase with 2-minute timer
-func (gm *GameManager) StartDayPhase(code string) error {
+// StartDayPhase moves the room into the day phase
+func (gm *GameManager) StartDayPhase(code models.RoomCode) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
-	}
-
-	room.Phase = models.PhaseDay
-	endTime := time.Now().Add(2 * time.Minute)
-	room.PhaseEndTime = &endTime
-
-	// Reset night actions tracking
-	for _, player := range room.Players {
-		player.HasActedThisNight = false
+		return gm.missingRoomLocked(code)
 	}
-	room.NightActionsCompleted = make(map[string]bool)
 
-	return nil
+	return gm.transitionLocked(room, models.PhaseDay, ReasonForced)
 }
 
-// StartNightPhase sets up the night phase (no timer)
-func (gm *GameManager) StartNightPhase(code string) error {
+// StartNightPhase moves the room into the night phase
+func (gm *GameManager) StartNightPhase(code models.RoomCode) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
-	room.Phase = models.PhaseNight
-	room.PhaseEndTime = nil // No timer for night phase
-
-	// Reset night actions tracking
-	for _, player := range room.Players {
-		player.HasActedThisNight = false
-	}
-	room.NightActionsCompleted = make(map[string]bool)
-
-	return nil
+	return gm.transitionLocked(room, models.PhaseNight, ReasonForced)
 }
 
 // MoveToNextPhase transitions the game to the next phase
-func (gm *GameManager) MoveToNextPhase(code string) (*NightResult, error) {
+func (gm *GameManager) MoveToNextPhase(code models.RoomCode) (*NightResult, error) {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return nil, ErrRoomNotFound
+		return nil, gm.missingRoomLocked(code)
 	}
 
-	var nightResult *NightResult
+	return gm.moveToNextPhaseLocked(room)
+}
+
+// moveToNextPhaseLocked ends the room's current phase
+func (gm *GameManager) moveToNextPhaseLocked(room *models.GameRoom) (*NightResult, error) {
+	if pausedLocked(room) {
+		return nil, ErrGamePaused
+	}
 
 	switch room.Phase {
 	case models.PhaseNight:
-		// Process night actions before moving to day
-		result, err := gm.ProcessNightPhase(code)
-		if err != nil {
-			return nil, err
-		}
-		nightResult = result
-
-		// Check if hunter died tonight and can shoot
-		if nightResult != nil && nightResult.Killed != "" {
-			killedPlayer := room.Players[nightResult.Killed]
-			if killedPlayer != nil && killedPlayer.Role == models.RoleHunter && killedPlayer.CanShoot {
-				room.WaitingHunterShoot = true
-				room.DeadHunterID = nightResult.Killed
-				// Don't move to day yet, wait for hunter shoot
-				return nightResult, nil
-			}
-		}
+		return gm.endNightLocked(room, ReasonNightResolved)
 
-		// Check game end after night
-		isEnded, winner := gm.checkGameEndLocked(room)
-		if isEnded {
-			room.Phase = models.PhaseEnded
-			room.WinningTeam = winner
-			return nightResult, nil
+	case models.PhaseDay:
+		// Give the village its minimum discussion time before an early skip
+		if err := discussionGuardLocked(room, time.Now()); err != nil {
+			return nil, err
 		}
 
-		// Night -> Day
-		room.Phase = models.PhaseDay
-		endTime := time.Now().Add(2 * time.Minute)
-		room.PhaseEndTime = &endTime
-		room.Round++ // Increment round when day starts
-
-		// Reset night actions tracking
-		for _, player := range room.Players {
-			player.HasActedThisNight = false
+		// Day -> Defense when formal accusations produced nominees
+		if room.Settings.FormalAccusations {
+			if nominees := topNomineesLocked(room); len(nominees) > 0 {
+				room.Nominees = nominees
+				return nil, gm.transitionLocked(room, models.PhaseDefense, ReasonAccused)
+			}
 		}
-		room.NightActionsCompleted = make(map[string]bool)
 
-	case models.PhaseDay:
 		// Day -> Voting
-		room.Phase = models.PhaseVoting
-		endTime := time.Now().Add(2 * time.Minute) // 2 minutes for voting
-		room.PhaseEndTime = &endTime
+		return nil, gm.transitionLocked(room, models.PhaseVoting, ReasonDiscussionEnd)
 
-		// Reset vote tracking
-		room.VoteResults = make(map[string]int)
-		for _, player := range room.Players {
-			player.VotedFor = ""
-		}
+	case models.PhaseDefense:
+		// Defense -> Voting restricted to the nominees
+		return nil, gm.transitionLocked(room, models.PhaseVoting, ReasonDefenseEnd)
 
 	case models.PhaseVoting:
 		// Process votes
@@ -408,79 +652,163 @@ func (gm *GameManager) MoveToNextPhase(code string) (*NightResult, error) {
 		// Check game end after vote
 		isEnded, winner := gm.checkGameEndLocked(room)
 		if isEnded {
-			room.Phase = models.PhaseEnded
-			room.WinningTeam = winner
-			return nil, nil
+			return nil, gm.endGameLocked(room, winner)
 		}
 
 		// Voting -> Night
-		room.Phase = models.PhaseNight
-		room.PhaseEndTime = nil
+		return nil, gm.transitionLocked(room, models.PhaseNight, ReasonVotesCounted)
 
-		// Reset night actions tracking and set up turn order
-		for _, player := range room.Players {
-			player.HasActedThisNight = false
-		}
-		room.NightActionsCompleted = make(map[string]bool)
-
-		// Set up night action order: Hunter -> Tiger/AlphaTiger -> Shaman
-		room.NightActionOrder = gm.getNightActionOrder(room)
-		if len(room.NightActionOrder) > 0 {
-			room.CurrentNightRole = room.NightActionOrder[0]
-		} else {
-			room.CurrentNightRole = ""
+	default:
+		return nil, ErrInvalidTransition
+	}
+}
+
+// endNightLocked resolves the night's actions and moves on to day, or waits
+// for a dead hunter's shot, or ends the game
+func (gm *GameManager) endNightLocked(room *models.GameRoom, reason string) (*NightResult, error) {
+	// Anyone who has not gone to sleep by now timed out
+	autoSleepLocked(room)
+
+	// Process night actions before moving to day
+	nightResult, err := gm.ProcessNightPhase(room.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if a hunter died tonight and can shoot
+	for _, death := range nightResult.Deaths {
+		killedPlayer := room.Players[death.PlayerID]
+		if killedPlayer != nil && killedPlayer.Role == models.RoleHunter && killedPlayer.AbilityReady(models.AbilityShoot, 0) && !killedPlayer.IsBot {
+			awaitHunterShotLocked(room, death.PlayerID)
+			// Don't move to day yet, wait for hunter shoot
+			return nightResult, nil
 		}
+	}
 
-	default:
-		return nil, &GameError{"invalid phase transition"}
+	// Check game end after night
+	isEnded, winner := gm.checkGameEndLocked(room)
+	if isEnded {
+		return nightResult, gm.endGameLocked(room, winner)
 	}
 
+	// Night -> Day
+	if err := gm.transitionLocked(room, models.PhaseDay, reason); err != nil {
+		return nil, err
+	}
+	concealNightDeathsLocked(room, nightResult, time.Now())
 	return nightResult, nil
 }
 
-// Custom errors
-var (
-	ErrRoomNotFound       = &GameError{"room not found"}
-	ErrRoomFull           = &GameError{"room is full"}
-	ErrGameAlreadyStarted = &GameError{"game already started"}
-	ErrNotEnoughPlayers   = &GameError{"not enough players to start"}
-)
+// discussionGuardLocked rejects ending the day early before the room's
+// minimum discussion time has passed. The day timer running out is never blocked.
+func discussionGuardLocked(room *models.GameRoom, now time.Time) error {
+	minimum := time.Duration(room.Settings.MinDiscussionSeconds) * time.Second
+	if minimum <= 0 || room.DayStartedAt == nil {
+		return nil
+	}
 
-type GameError struct {
-	message string
+	if room.PhaseEndTime != nil && !now.Before(room.PhaseEndTime.Time) {
+		return nil
+	}
+
+	remaining := room.DayStartedAt.Add(minimum).Sub(now)
+	if remaining <= 0 {
+		return nil
+	}
+
+	seconds := int(math.Ceil(remaining.Seconds()))
+	return &errs.Error{
+		Code:    "DISCUSSION_TOO_SHORT",
+		Params:  map[string]interface{}{"remainingSeconds": seconds},
+		Message: fmt.Sprintf("discussion too short: %d seconds remaining", seconds),
+	}
 }
 
-func (e *GameError) Error() string {
-	return e.message
+// voteLockGuardLocked rejects a changed vote once the room's vote lock
+// holds, and a first vote too when the lock covers those
+func voteLockGuardLocked(room *models.GameRoom, player *models.Player, now time.Time) error {
+	lockAt := room.VoteLockAt()
+	if lockAt == nil || now.Before(lockAt.Time) {
+		return nil
+	}
+	if player.VotedFor == "" && !room.Settings.VoteLockBlocksFirstVotes {
+		return nil
+	}
+
+	seconds := int(math.Ceil(math.Max(room.PhaseEndTime.Sub(now).Seconds(), 0)))
+	return &errs.Error{
+		Code:    "VOTE_LOCKED",
+		Params:  map[string]interface{}{"remainingSeconds": seconds},
+		Message: fmt.Sprintf("votes are locked: %d seconds remaining", seconds),
+	}
 }
 
+// Custom errors
+var (
+	ErrRoomNotFound         = &errs.Error{Code: "ROOM_NOT_FOUND", Message: "room not found"}
+	ErrRoomFull             = &errs.Error{Code: "ROOM_FULL", Message: "room is full"}
+	ErrGameAlreadyStarted   = &errs.Error{Code: "GAME_ALREADY_STARTED", Message: "game already started"}
+	ErrNotEnoughPlayers     = &errs.Error{Code: "NOT_ENOUGH_PLAYERS", Message: "not enough players to start"}
+	ErrSlotTaken            = &errs.Error{Code: "SLOT_TAKEN", Message: "color slot already taken"}
+	ErrServerAtCapacity     = &errs.Error{Code: "SERVER_AT_CAPACITY", Message: "server is at its room capacity"}
+	ErrHostReassigned       = &errs.Error{Code: "HOST_REASSIGNED", Message: "the host has left, so a new host was chosen"}
+	ErrPlayerNotFound       = &errs.Error{Code: "PLAYER_NOT_FOUND", Message: "player not found"}
+	ErrPracticeSinglePlayer = &errs.Error{Code: "PRACTICE_SINGLE_PLAYER", Message: "practice rooms are single-player"}
+	ErrInvalidTransition    = &errs.Error{Code: "INVALID_PHASE_TRANSITION", Message: "invalid phase transition"}
+	ErrNotDayPhase          = &errs.Error{Code: "NOT_DAY_PHASE", Message: "not day phase"}
+	ErrNotVotingPhase       = &errs.Error{Code: "NOT_VOTING_PHASE", Message: "voting is only allowed during voting phase"}
+	ErrVoteNotOver          = &errs.Error{Code: "VOTE_NOT_OVER", Message: "the vote is still open"}
+	ErrNotNightPhase        = &errs.Error{Code: "NOT_NIGHT_PHASE", Message: "not in night phase"}
+	ErrInvalidVoteTarget    = &errs.Error{Code: "INVALID_VOTE_TARGET", Message: "invalid vote target"}
+	ErrVoteForNomineeOnly   = &errs.Error{Code: "VOTE_FOR_NOMINEE_ONLY", Message: "can only vote for a nominee"}
+	ErrCannotVote           = &errs.Error{Code: "PLAYER_CANNOT_VOTE", Message: "player cannot vote"}
+	ErrNotHunter            = &errs.Error{Code: "NOT_HUNTER", Message: "not a hunter"}
+)
+
 // Vote records a player's vote
-func (gm *GameManager) Vote(code, playerID, targetID string) error {
+func (gm *GameManager) Vote(code models.RoomCode, playerID, targetID string) error {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return gm.missingRoomLocked(code)
 	}
 
-	if room.Phase != models.PhaseVoting {
-		return &GameError{"voting is only allowed during voting phase"}
+	player, err := voterLocked(room, playerID)
+	if err != nil {
+		return err
 	}
 
-	player := room.Players[playerID]
-	if player == nil || !player.IsAlive {
-		return &GameError{"player cannot vote"}
+	// A resent identical vote is acknowledged without re-tallying
+	if player.VotedFor == targetID {
+		if record := lastActionLocked(room, player, models.ActionVote); record != nil {
+			return &DuplicateActionError{Record: *record}
+		}
 	}
 
-	target := room.Players[targetID]
-	if target == nil || !target.IsAlive {
-		return &GameError{"invalid vote target"}
+	if err := voteLockGuardLocked(room, player, time.Now()); err != nil {
+		return err
+	}
+
+	// With formal accusations the ballot is frozen to the nominees plus abstain
+	var target *models.Player
+	if targetID == models.VoteAbstain {
+		if len(room.Nominees) == 0 {
+			return ErrInvalidVoteTarget
+		}
+	} else {
+		target = room.Players[targetID]
+		if target == nil || !target.IsAlive {
+			return ErrInvalidVoteTarget
+		}
+		if len(room.Nominees) > 0 && !containsID(room.Nominees, targetID) {
+			return ErrVoteForNomineeOnly
+		}
 	}
 
 	// Remove previous vote if exists
-	if player.VotedFor != "" {
+	if player.VotedFor != "" && player.VotedFor != models.VoteAbstain {
 		room.VoteResults[player.VotedFor]--
 		if room.VoteResults[player.VotedFor] <= 0 {
 			delete(room.VoteResults, player.VotedFor)
@@ -492,17 +820,45 @@ func (gm *GameManager) Vote(code, playerID, targetID string) error {
 	if room.VoteResults == nil {
 		room.VoteResults = make(map[string]int)
 	}
-	room.VoteResults[targetID]++
+	if target != nil {
+		room.VoteResults[targetID]++
+	}
+
+	// A changed vote replaces this round's history entry
+	record := lastActionLocked(room, player, models.ActionVote)
+	if record == nil {
+		recordActionLocked(room, player, models.ActionVote, nil)
+		record = lastActionLocked(room, player, models.ActionVote)
+	} else {
+		player.ActionSeq++
+	}
+	record.TargetID = targetID
+	record.TargetUsername = ""
+	if target != nil {
+		record.TargetUsername = target.Username
+	}
 
 	return nil
 }
 
+// voterLocked returns the player if they may cast or change a vote now
+func voterLocked(room *models.GameRoom, playerID string) (*models.Player, error) {
+	if room.Phase != models.PhaseVoting {
+		return nil, ErrNotVotingPhase
+	}
+
+	player := room.Players[playerID]
+	if player == nil || !player.IsAlive {
+		return nil, ErrCannotVote
+	}
+	return player, nil
+}
+
 // CheckAllVoted checks if all alive players have voted
-func (gm *GameManager) CheckAllVoted(code string) bool {
+func (gm *GameManager) CheckAllVoted(code models.RoomCode) bool {
 	gm.mu.RLock()
 	defer gm.mu.RUnlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
 		return false
@@ -512,11 +868,37 @@ func (gm *GameManager) CheckAllVoted(code string) bool {
 		return false
 	}
 
-	// Count alive players and voted players
+	return allVotedLocked(room)
+}
+
+// CloseVote counts the votes when a client's countdown runs out. The client
+// only asks: the vote is closed once every living human has voted or the
+// vote's own deadline has passed, and not a moment before.
+func (gm *GameManager) CloseVote(code models.RoomCode) (*NightResult, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	if room.Phase != models.PhaseVoting {
+		return nil, ErrNotVotingPhase
+	}
+	if !allVotedLocked(room) && (room.PhaseEndTime == nil || time.Now().Before(room.PhaseEndTime.Time)) {
+		return nil, ErrVoteNotOver
+	}
+
+	return gm.moveToNextPhaseLocked(room)
+}
+
+// allVotedLocked reports whether every living human has voted
+func allVotedLocked(room *models.GameRoom) bool {
 	aliveCount := 0
 	votedCount := 0
-	for _, player := range room.Players {
-		if player.IsAlive {
+	for _, player := range room.LivingPlayers() {
+		if !player.IsBot {
 			aliveCount++
 			if player.VotedFor != "" {
 				votedCount++
@@ -527,32 +909,42 @@ func (gm *GameManager) CheckAllVoted(code string) bool {
 	return aliveCount > 0 && aliveCount == votedCount
 }
 
-// processVotes processes voting results and eliminates the player with most votes
+// processVotes processes voting results and eliminates the player with
+// most votes, breaking a tie by the room's VoteTieBreak
 func (gm *GameManager) processVotes(room *models.GameRoom) {
+	outcome := settleVoteLocked(room, room.VoteResults)
+	gm.recordVoteOutcomeLocked(room, outcome)
+
 	if len(room.VoteResults) == 0 {
+		recordBallotsLocked(room, "")
 		return
 	}
 
-	// Find player with most votes
-	maxVotes := 0
-	var eliminatedID string
-	for playerID, votes := range room.VoteResults {
-		if votes > maxVotes {
-			maxVotes = votes
-			eliminatedID = playerID
-		}
+	eliminatedID := outcome.EliminatedID
+	recordBallotsLocked(room, eliminatedID)
+
+	// Everyone learns privately who turned on them, unless ballots are anonymous
+	room.VotesAgainst = nil
+	if room.Settings.RevealVotes {
+		room.VotesAgainst = votesAgainstLocked(room)
 	}
 
+	settleActionsLocked(room, models.ActionVote, func(record *models.ActionRecord) string {
+		if record.TargetID == eliminatedID {
+			return "eliminated"
+		}
+		return "survived"
+	})
+
 	// Eliminate player
-	if eliminatedID != "" && maxVotes > 0 {
+	if eliminatedID != "" {
 		player := room.Players[eliminatedID]
 		if player != nil {
-			player.IsAlive = false
+			gm.killPlayerLocked(room, player, models.DeathByVote)
 
 			// Check if eliminated player is hunter
-			if player.Role == models.RoleHunter && player.CanShoot {
-				room.WaitingHunterShoot = true
-				room.DeadHunterID = eliminatedID
+			if player.Role == models.RoleHunter && player.AbilityReady(models.AbilityShoot, 0) && !player.IsBot {
+				awaitHunterShotLocked(room, eliminatedID)
 			}
 
 			// Check if cursed player is voted out (instant death for cursed)
@@ -569,33 +961,75 @@ func (gm *GameManager) processVotes(room *models.GameRoom) {
 	}
 }
 
-// getNightActionOrder returns the order of night actions based on alive players
-func (gm *GameManager) getNightActionOrder(room *models.GameRoom) []models.Role {
-	order := []models.Role{}
-	rolePresent := make(map[models.Role]bool)
-
-	// Check which special roles are alive
-	for _, player := range room.Players {
-		if !player.IsAlive {
+// votesAgainstLocked lists, for each player who received votes, the
+// usernames of their voters in a stable order
+func votesAgainstLocked(room *models.GameRoom) map[string][]string {
+	against := make(map[string][]string)
+	for _, voter := range room.Players {
+		if voter.VotedFor == "" || room.Players[voter.VotedFor] == nil {
 			continue
 		}
-		switch player.Role {
-		case models.RoleShaman, models.RoleHunter, models.RoleTiger, models.RoleAlphaTiger:
-			rolePresent[player.Role] = true
+		against[voter.VotedFor] = append(against[voter.VotedFor], voter.Username)
+	}
+	for _, voters := range against {
+		sort.Strings(voters)
+	}
+	return against
+}
+
+// recordBallotsLocked adds the day's ballots to the vote history before they are cleared
+func recordBallotsLocked(room *models.GameRoom, eliminatedID string) {
+	voterIDs := make([]string, 0, len(room.Players))
+	for id, player := range room.Players {
+		if player.VotedFor != "" {
+			voterIDs = append(voterIDs, id)
+		}
+	}
+	sort.Strings(voterIDs)
+
+	for _, id := range voterIDs {
+		ballot := models.Ballot{Round: room.Round, VoterID: id, TargetID: room.Players[id].VotedFor}
+		switch ballot.TargetID {
+		case models.VoteAbstain:
+			ballot.Outcome = "abstained"
+		case eliminatedID:
+			ballot.Outcome = "eliminated"
+		default:
+			ballot.Outcome = "survived"
 		}
+		room.VoteHistory = append(room.VoteHistory, ballot)
+	}
+}
+
+// TakeVotesAgainst returns who voted for each player in the round just
+// resolved and forgets it, so each resolution is announced once
+func (gm *GameManager) TakeVotesAgainst(code models.RoomCode) map[string][]string {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil
 	}
 
-	// Set order: Hunter -> Tiger/AlphaTiger -> Shaman (ตามกติกา)
-	if rolePresent[models.RoleHunter] {
+	against := room.VotesAgainst
+	room.VotesAgainst = nil
+	return against
+}
+
+// getNightActionOrder returns the order of night actions based on alive players
+func (gm *GameManager) getNightActionOrder(room *models.GameRoom) []models.Role {
+	order := []models.Role{}
+
+	// Set order: Hunter -> Tiger team -> Shaman (ตามกติกา)
+	// Tiger and alpha tiger share one step (see TigerTeamNight)
+	if room.LivingCount(models.RoleHunter) > 0 {
 		order = append(order, models.RoleHunter)
 	}
-	if rolePresent[models.RoleTiger] {
+	if room.LivingCount(tigerTeamRoles...) > 0 {
 		order = append(order, models.RoleTiger)
 	}
-	if rolePresent[models.RoleAlphaTiger] {
-		order = append(order, models.RoleAlphaTiger)
-	}
-	if rolePresent[models.RoleShaman] {
+	if room.LivingCount(models.RoleShaman) > 0 {
 		order = append(order, models.RoleShaman)
 	}
 
@@ -603,18 +1037,29 @@ func (gm *GameManager) getNightActionOrder(room *models.GameRoom) []models.Role
 }
 
 // MoveToNextNightRole advances to the next role in night phase
-func (gm *GameManager) MoveToNextNightRole(code string) (bool, error) {
+func (gm *GameManager) MoveToNextNightRole(code models.RoomCode) (bool, error) {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return false, ErrRoomNotFound
+		return false, gm.missingRoomLocked(code)
 	}
 
 	if room.Phase != models.PhaseNight {
-		return false, &GameError{"not in night phase"}
+		return false, ErrNotNightPhase
+	}
+
+	return advanceNightRoleLocked(room), nil
+}
+
+// advanceNightRoleLocked moves to the next role once the current turn is
+// finished, and reports whether the night has nothing left to wait for
+func advanceNightRoleLocked(room *models.GameRoom) bool {
+	// A turn is finished once its token was consumed; the tiger team's step
+	// only ends once the whole team has decided
+	if room.TurnToken != "" || (room.CurrentNightRole == models.RoleTiger && !tigerTeamResolvedLocked(room)) {
+		return false
 	}
 
 	// Find current role index
@@ -628,66 +1073,107 @@ func (gm *GameManager) MoveToNextNightRole(code string) (bool, error) {
 
 	// Move to next role
 	if currentIndex >= 0 && currentIndex < len(room.NightActionOrder)-1 {
-		room.CurrentNightRole = room.NightActionOrder[currentIndex+1]
-		return false, nil // Not done yet
+		setNightRoleLocked(room, room.NightActionOrder[currentIndex+1])
+		if !skipBotTurnsLocked(room) {
+			return false // Not done yet
+		}
 	}
 
-	// All roles done
-	room.CurrentNightRole = ""
-	return true, nil // Night phase complete
+	// All roles done; with sleep confirmation the night also waits for everyone to sleep
+	setNightRoleLocked(room, "")
+	return nightAcknowledgedLocked(room)
 }
 
 // GetCurrentNightRole returns the current role that should act
-func (gm *GameManager) GetCurrentNightRole(code string) (models.Role, error) {
+func (gm *GameManager) GetCurrentNightRole(code models.RoomCode) (models.Role, error) {
 	gm.mu.RLock()
 	defer gm.mu.RUnlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return "", ErrRoomNotFound
+		return "", gm.missingRoomLocked(code)
 	}
 
 	return room.CurrentNightRole, nil
 }
 
-// HunterShoot handles hunter shooting when they die
-func (gm *GameManager) HunterShoot(code, hunterID, targetID string) error {
+// HunterShoot handles hunter shooting when they die. With the room's
+// HunterShotNeedsConfirmation on, a shot without confirm only selects the
+// target; the hunter fires by sending it again with confirm. The returned
+// record's outcome stays empty while a selection awaits confirmation.
+func (gm *GameManager) HunterShoot(code models.RoomCode, hunterID, targetID string, confirm bool) (models.ActionRecord, error) {
 	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	defer gm.unlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
-		return ErrRoomNotFound
+		return models.ActionRecord{}, gm.missingRoomLocked(code)
 	}
 
 	hunter := room.Players[hunterID]
 	if hunter == nil || hunter.Role != models.RoleHunter {
-		return &GameError{"not a hunter"}
+		return models.ActionRecord{}, ErrNotHunter
+	}
+
+	// A resent shot or selection of the same target is acknowledged with the original outcome
+	for i := len(hunter.ActionHistory) - 1; i >= 0; i-- {
+		if record := hunter.ActionHistory[i]; record.ActionType == models.ActionShoot {
+			if record.TargetID == targetID && (record.Outcome != "" || !confirm) {
+				return record, &DuplicateActionError{Record: record}
+			}
+			break
+		}
+	}
+
+	if err := hunterMayShootLocked(room, hunter); err != nil {
+		return models.ActionRecord{}, err
 	}
 
 	target := room.Players[targetID]
 	if target == nil || !target.IsAlive {
-		return &GameError{"invalid target"}
+		return models.ActionRecord{}, ErrInvalidActionTarget
+	}
+
+	if err := hunterTargetLocked(room, hunter, targetID); err != nil {
+		return models.ActionRecord{}, err
+	}
+
+	if room.Settings.HunterShotNeedsConfirmation {
+		if !confirm {
+			return selectHunterTargetLocked(room, hunter, target), nil
+		}
+		if room.HunterSelection != targetID {
+			return models.ActionRecord{}, ErrShotNotSelected
+		}
 	}
 
 	// Kill target
-	target.IsAlive = false
+	hunter.UseAbility(models.AbilityShoot, 0)
+	gm.killPlayerLocked(room, target, models.DeathByHunter)
+	record := lastActionLocked(room, hunter, models.ActionShoot)
+	if record == nil {
+		recordActionLocked(room, hunter, models.ActionShoot, target)
+		record = lastActionLocked(room, hunter, models.ActionShoot)
+	}
+	record.Outcome = "killed"
+	fired := *record
 
 	// Reset waiting state
-	room.WaitingHunterShoot = false
-	room.DeadHunterID = ""
+	clearHunterWaitLocked(room)
 
-	return nil
+	// The shot may have decided the game
+	if isEnded, winner := gm.checkGameEndLocked(room); isEnded {
+		return fired, gm.endGameLocked(room, winner)
+	}
+
+	return fired, nil
 }
 
 // CheckGameEnd checks if game has ended and returns winning team
-func (gm *GameManager) CheckGameEnd(code string) (bool, string) {
+func (gm *GameManager) CheckGameEnd(code models.RoomCode) (bool, string) {
 	gm.mu.RLock()
 	defer gm.mu.RUnlock()
 
-	code = strings.ToUpper(code)
 	room, exists := gm.Rooms[code]
 	if !exists {
 		return false, ""
@@ -698,19 +1184,8 @@ func (gm *GameManager) CheckGameEnd(code string) (bool, string) {
 
 // checkGameEndLocked checks game end without locking (internal use)
 func (gm *GameManager) checkGameEndLocked(room *models.GameRoom) (bool, string) {
-	tigerCount := 0
-	humanCount := 0
-
-	for _, player := range room.Players {
-		if !player.IsAlive {
-			continue
-		}
-		if player.Role == models.RoleTiger || player.Role == models.RoleAlphaTiger {
-			tigerCount++
-		} else {
-			humanCount++
-		}
-	}
+	tigerCount := room.LivingCount(tigerTeamRoles...)
+	humanCount := room.LivingCount() - tigerCount
 
 	// Tiger wins if tiger count >= human count
 	if tigerCount >= humanCount && tigerCount > 0 {
@@ -0,0 +1,209 @@
+package game
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Permission is an action only some players may take
+type Permission string
+
+// Permissions moderators share with the host
+const (
+	PermKick             Permission = "kick"
+	PermMute             Permission = "mute"
+	PermExtendDiscussion Permission = "extend_discussion"
+	PermSlowMode         Permission = "slow_mode"
+)
+
+// Permissions that stay with the host
+const (
+	PermChangeSettings Permission = "change_settings"
+	PermSkipPhase      Permission = "skip_phase"
+	PermModerators     Permission = "moderators"
+	PermAbortGame      Permission = "abort_game"
+	PermAdmitPlayers   Permission = "admit_players"
+	PermRematch        Permission = "rematch"
+	PermStartGame      Permission = "start_game"
+	PermRevealBody     Permission = "reveal_body"
+)
+
+// moderatorPermissions is what a moderator may do in the host's place
+var moderatorPermissions = map[Permission]bool{
+	PermKick:             true,
+	PermMute:             true,
+	PermExtendDiscussion: true,
+	PermSlowMode:         true,
+}
+
+// maxDiscussionExtension caps how much one extend_discussion adds
+const maxDiscussionExtension = 2 * time.Minute
+
+// ErrNotPermitted is returned when a player lacks the permission for an action
+var ErrNotPermitted = &errs.Error{Code: "NOT_PERMITTED", Message: "you are not allowed to do that"}
+
+var (
+	ErrAlreadyHost      = &errs.Error{Code: "ALREADY_HOST", Message: "the host is already in charge"}
+	ErrExtensionTooLong = &errs.Error{
+		Code:    "DISCUSSION_EXTENSION_TOO_LONG",
+		Params:  map[string]interface{}{"maxSeconds": int(maxDiscussionExtension.Seconds())},
+		Message: "discussion can be extended by up to 2 minutes at a time",
+	}
+)
+
+// hostOnlyError is returned when a player other than the host tries what
+// only the host may, e.g. "create invites"
+func hostOnlyError(action string) *errs.Error {
+	return &errs.Error{
+		Code:    "HOST_ONLY",
+		Params:  map[string]interface{}{"action": action},
+		Message: "only host can " + action,
+	}
+}
+
+// authorizeLocked checks that the player may take an action needing perm
+func authorizeLocked(room *models.GameRoom, playerID string, perm Permission) error {
+	if room.HostID == playerID {
+		return nil
+	}
+
+	if player := room.Players[playerID]; player != nil && player.IsModerator && moderatorPermissions[perm] {
+		return nil
+	}
+
+	return ErrNotPermitted
+}
+
+// SetModerator grants or revokes a player's moderator status (host only)
+func (gm *GameManager) SetModerator(code models.RoomCode, hostID, targetID string, moderator bool) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, hostID, PermModerators); err != nil {
+		return err
+	}
+
+	target := room.Players[targetID]
+	if target == nil || target.IsBot {
+		return ErrPlayerNotFound
+	}
+
+	if targetID == room.HostID {
+		return ErrAlreadyHost
+	}
+
+	target.IsModerator = moderator
+	action := HostActionRevokeModerator
+	if moderator {
+		action = HostActionGrantModerator
+	}
+	gm.recordHostActionLocked(room, hostID, action, targetID, "")
+
+	return nil
+}
+
+// KickPlayer removes a player from the lobby
+func (gm *GameManager) KickPlayer(code models.RoomCode, actorID, targetID string) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, actorID, PermKick); err != nil {
+		return err
+	}
+
+	if room.Phase != models.PhaseWaiting {
+		return ErrGameAlreadyStarted
+	}
+
+	target := room.Players[targetID]
+	if target == nil || targetID == actorID {
+		return ErrPlayerNotFound
+	}
+
+	// Moderators answer to the host, not to each other
+	if targetID == room.HostID || (target.IsModerator && actorID != room.HostID) {
+		return ErrNotPermitted
+	}
+
+	gm.recordHostActionLocked(room, actorID, HostActionKick, targetID, "")
+	room.RemovePlayer(targetID)
+	room.LastActivityAt = time.Now()
+	gm.notify(room, func(o RoomObserver) { o.OnPlayerLeft(room, target) })
+
+	return nil
+}
+
+// SetMuted stops or lets a player speak in the room chat
+func (gm *GameManager) SetMuted(code models.RoomCode, actorID, targetID string, muted bool) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, actorID, PermMute); err != nil {
+		return err
+	}
+
+	target := room.Players[targetID]
+	if target == nil {
+		return ErrPlayerNotFound
+	}
+
+	if targetID == room.HostID || (target.IsModerator && actorID != room.HostID) {
+		return ErrNotPermitted
+	}
+
+	target.IsMuted = muted
+	action := HostActionUnmute
+	if muted {
+		action = HostActionMute
+	}
+	gm.recordHostActionLocked(room, actorID, action, targetID, "")
+
+	return nil
+}
+
+// ExtendDiscussion pushes the end of the day back
+func (gm *GameManager) ExtendDiscussion(code models.RoomCode, actorID string, extra time.Duration) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, actorID, PermExtendDiscussion); err != nil {
+		return err
+	}
+
+	if room.Phase != models.PhaseDay || room.PhaseEndTime == nil {
+		return ErrNotDayPhase
+	}
+
+	if extra <= 0 || extra > maxDiscussionExtension {
+		return ErrExtensionTooLong
+	}
+
+	endTime := room.PhaseEndTime.Add(extra)
+	room.PhaseEndTime = models.TimestampPtr(endTime)
+	gm.recordHostActionLocked(room, actorID, HostActionExtendDiscussion, "", strconv.Itoa(int(extra.Seconds())))
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// moderatedLobby is a lobby of six hosted by p1, with p2 made a moderator
+func moderatedLobby(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	if err := gm.SetModerator(code, "p1", "p2", true); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	return gm, code
+}
+
+func TestPermissionMatrix(t *testing.T) {
+	gm, code := moderatedLobby(t)
+
+	// What the host, the moderator p2 and the player p3 may each do
+	matrix := []struct {
+		perm                   Permission
+		host, moderator, other bool
+	}{
+		{PermKick, true, true, false},
+		{PermMute, true, true, false},
+		{PermExtendDiscussion, true, true, false},
+		{PermSlowMode, true, true, false},
+		{PermChangeSettings, true, false, false},
+		{PermSkipPhase, true, false, false},
+		{PermModerators, true, false, false},
+		{PermAbortGame, true, false, false},
+		{PermAdmitPlayers, true, false, false},
+		{PermRematch, true, false, false},
+		{PermStartGame, true, false, false},
+		{PermRevealBody, true, false, false},
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for _, row := range matrix {
+			for id, want := range map[string]bool{"p1": row.host, "p2": row.moderator, "p3": row.other} {
+				err := authorizeLocked(room, id, row.perm)
+				if (err == nil) != want {
+					t.Errorf("%s %s: %v, want allowed %v", id, row.perm, err, want)
+				}
+				if err != nil && !errors.Is(err, ErrNotPermitted) {
+					t.Errorf("%s %s: %v, want %s", id, row.perm, err, ErrNotPermitted.Code)
+				}
+			}
+		}
+	})
+}
+
+func TestModeratorInTheLobby(t *testing.T) {
+	gm, code := moderatedLobby(t)
+
+	if err := gm.SetMuted(code, "p2", "p4", true); err != nil {
+		t.Errorf("moderator mutes: %v", err)
+	}
+	if err := gm.KickPlayer(code, "p2", "p5"); err != nil {
+		t.Errorf("moderator kicks: %v", err)
+	}
+	if err := gm.SetSlowMode(code, "p2", 5); err != nil {
+		t.Errorf("moderator sets slow mode: %v", err)
+	}
+
+	// The host's own powers, and the host, are out of the moderator's reach
+	refused := map[string]error{
+		"start":       gm.StartGame(code, "p2"),
+		"grant":       gm.SetModerator(code, "p2", "p3", true),
+		"kick host":   gm.KickPlayer(code, "p2", "p1"),
+		"mute host":   gm.SetMuted(code, "p2", "p1", true),
+		"player kick": gm.KickPlayer(code, "p3", "p6"),
+	}
+	var settings models.RoomSettings
+	withRoom(t, gm, code, func(room *models.GameRoom) { settings = room.Settings })
+	refused["settings"] = gm.UpdateSettings(code, "p2", settings)
+	for action, err := range refused {
+		if !errors.Is(err, ErrNotPermitted) {
+			t.Errorf("%s: %v, want %s", action, err, ErrNotPermitted.Code)
+		}
+	}
+
+	// Moderators answer to the host, not to each other
+	if err := gm.SetModerator(code, "p1", "p3", true); err != nil {
+		t.Fatalf("grant p3: %v", err)
+	}
+	if err := gm.KickPlayer(code, "p2", "p3"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("moderator kicks moderator: %v, want %s", err, ErrNotPermitted.Code)
+	}
+	if err := gm.SetModerator(code, "p1", "p1", true); !errors.Is(err, ErrAlreadyHost) {
+		t.Errorf("host made moderator: %v, want %s", err, ErrAlreadyHost.Code)
+	}
+}
+
+func TestModeratorDuringTheDay(t *testing.T) {
+	gm, code := moderatedLobby(t)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+
+	if err := gm.ExtendDiscussion(code, "p2", time.Minute); err != nil {
+		t.Errorf("moderator extends: %v", err)
+	}
+	if err := gm.ExtendDiscussion(code, "p3", time.Minute); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("player extends: %v, want %s", err, ErrNotPermitted.Code)
+	}
+	if _, err := gm.AdvancePhase(code, "p2"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("moderator skips: %v, want %s", err, ErrNotPermitted.Code)
+	}
+}
+
+func TestModeratorShownAndKept(t *testing.T) {
+	gm, code := moderatedLobby(t)
+
+	// Everyone sees who moderates, and a reconnect keeps it
+	gm.SetConnected(code, "p2", true)
+	gm.SetConnected(code, "p2", false)
+	gm.SetConnected(code, "p2", true)
+	view, _ := gm.RoomView(code, "p3")
+	if !view.Players["p2"].IsModerator || view.Players["p3"].IsModerator {
+		t.Errorf("p3 sees p2 moderator %v and self %v", view.Players["p2"].IsModerator, view.Players["p3"].IsModerator)
+	}
+
+	if err := gm.SetModerator(code, "p1", "p2", false); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if err := gm.SetMuted(code, "p2", "p4", true); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("revoked moderator mutes: %v, want %s", err, ErrNotPermitted.Code)
+	}
+}
+
+func TestRematchClearsModerators(t *testing.T) {
+	gm, code := rematchGame(t)
+	withRoom(t, gm, code, func(room *models.GameRoom) { room.Players["p3"].IsModerator = true })
+
+	if _, err := gm.Rematch(code, "p1"); err != nil {
+		t.Fatalf("rematch: %v", err)
+	}
+	view, _ := gm.RoomView(code, "")
+	if view.Players["p3"].IsModerator {
+		t.Error("p3 is still a moderator after the rematch")
+	}
+	if err := gm.SetModerator(code, "p1", "p3", true); err != nil {
+		t.Errorf("grant again: %v", err)
+	}
+}
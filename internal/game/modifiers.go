@@ -0,0 +1,156 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// NightModifier bends one night's rules. The host enables modifiers in the
+// room settings; each night at most one is drawn, weighted, and announced at
+// dusk. Its hooks run inside resolveNight, ahead of protection and the
+// shaman's luck, so those still apply to whatever the modifier leaves.
+type NightModifier interface {
+	// Key names the modifier in settings, traces and message keys
+	Key() string
+	// Announcement is the system line the room sees at dusk
+	Announcement() CatalogText
+	// Weight is how often the modifier is drawn relative to the others
+	Weight() int
+	// Applies reports whether the modifier can change anything in this room tonight
+	Applies(room *models.GameRoom) bool
+	// Kills returns the players the tigers attack tonight
+	Kills(room *models.GameRoom, targets []string) []string
+	// Vision reports whether the shaman's vision works tonight
+	Vision(room *models.GameRoom) bool
+}
+
+// NopNightModifier implements the hooks of NightModifier without changing
+// anything. Embed it to override only the hooks a modifier needs.
+type NopNightModifier struct{}
+
+func (NopNightModifier) Weight() int                                         { return 1 }
+func (NopNightModifier) Applies(*models.GameRoom) bool                       { return true }
+func (NopNightModifier) Kills(_ *models.GameRoom, targets []string) []string { return targets }
+func (NopNightModifier) Vision(*models.GameRoom) bool                        { return true }
+
+// Built-in night modifiers
+const (
+	ModifierBloodMoon  = "blood_moon"
+	ModifierFog        = "fog"
+	ModifierQuietNight = "quiet_night"
+)
+
+// calmNightWeight is the weight of drawing no modifier at all
+const calmNightWeight = 2
+
+// nightModifiers holds every modifier a room may enable, by key
+var nightModifiers = map[string]NightModifier{
+	ModifierBloodMoon:  bloodMoon{},
+	ModifierFog:        fog{},
+	ModifierQuietNight: quietNight{},
+}
+
+// bloodMoon lets the tigers kill twice: when the team split between two
+// victims, the one the alpha overruled dies too
+type bloodMoon struct{ NopNightModifier }
+
+func (bloodMoon) Key() string { return ModifierBloodMoon }
+
+func (bloodMoon) Announcement() CatalogText {
+	return modifierText(ModifierBloodMoon, "A blood moon rises. The tigers may claim two victims tonight.")
+}
+
+// Applies needs two tigers to disagree, so a lone tiger never draws it
+func (bloodMoon) Applies(room *models.GameRoom) bool {
+	return len(tigerTeamMembersLocked(room)) >= 2
+}
+
+func (bloodMoon) Kills(room *models.GameRoom, targets []string) []string {
+	if room.TigerTeam == nil || room.TigerTeam.SecondKillTargetID == "" {
+		return targets
+	}
+	return append(targets, room.TigerTeam.SecondKillTargetID)
+}
+
+// fog hides the truth from the shaman for a night
+type fog struct{ NopNightModifier }
+
+func (fog) Key() string { return ModifierFog }
+
+func (fog) Announcement() CatalogText {
+	return modifierText(ModifierFog, "A thick fog rolls in. Tonight no vision will see through it.")
+}
+
+func (fog) Applies(room *models.GameRoom) bool {
+	return hasLivingRole(room, models.RoleShaman)
+}
+
+func (fog) Vision(*models.GameRoom) bool { return false }
+
+// quietNight keeps the tigers from killing anyone
+type quietNight struct{ NopNightModifier }
+
+func (quietNight) Key() string { return ModifierQuietNight }
+
+func (quietNight) Announcement() CatalogText {
+	return modifierText(ModifierQuietNight, "The night is strangely quiet. No one will be killed tonight.")
+}
+
+func (quietNight) Kills(*models.GameRoom, []string) []string { return nil }
+
+// modifierText builds a modifier's announcement with its message key
+func modifierText(key, text string) CatalogText {
+	return CatalogText{Key: "night_modifier." + key, Text: text}
+}
+
+// modifierName is how traces refer to a modifier, e.g. "blood moon"
+func modifierName(modifier NightModifier) string {
+	return strings.ReplaceAll(modifier.Key(), "_", " ")
+}
+
+// LookupNightModifier returns a built-in modifier by key
+func LookupNightModifier(key string) (NightModifier, bool) {
+	modifier, ok := nightModifiers[key]
+	return modifier, ok
+}
+
+// drawNightModifier picks tonight's modifier from the room's enabled set,
+// weighted, or none at all
+func drawNightModifier(room *models.GameRoom) NightModifier {
+	var candidates []NightModifier
+	total := calmNightWeight
+	for _, key := range room.Settings.NightModifiers {
+		modifier, ok := nightModifiers[key]
+		if !ok || modifier.Weight() <= 0 || !modifier.Applies(room) {
+			continue
+		}
+		candidates = append(candidates, modifier)
+		total += modifier.Weight()
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	roll := roomRandLocked(room).Intn(total)
+	for _, modifier := range candidates {
+		if roll < modifier.Weight() {
+			return modifier
+		}
+		roll -= modifier.Weight()
+	}
+	return nil
+}
+
+// activeNightModifier is the modifier drawn for the room's current night
+func activeNightModifier(room *models.GameRoom) NightModifier {
+	if room.NightModifier == "" {
+		return nil
+	}
+	return nightModifiers[room.NightModifier]
+}
+
+// hasLivingRole reports whether anyone alive holds the role
+func hasLivingRole(room *models.GameRoom, role models.Role) bool {
+	return room.LivingCount(role) > 0
+}
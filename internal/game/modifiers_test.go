@@ -0,0 +1,217 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// modifierRoles deals the alpha tiger to p1, a tiger to p2, the hunter to
+// p3 and the shaman to p4; p5 and p6 are villagers
+var modifierRoles = map[string]models.Role{
+	"p1": models.RoleAlphaTiger,
+	"p2": models.RoleTiger,
+	"p3": models.RoleHunter,
+	"p4": models.RoleShaman,
+}
+
+// nightPlan is what a night's actions came to before resolution
+type nightPlan struct {
+	kill, secondKill string // the team's victim, and the one it overruled
+	protect, vision  string
+}
+
+// resolvePlan resolves one night under the modifier and returns the
+// result and who died
+func resolvePlan(t *testing.T, modifier string, plan nightPlan) (*NightResult, []string) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, modifierRoles)
+
+	var result *NightResult
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.NightModifier = modifier
+		room.TigerTarget = plan.kill
+		room.HunterProtection = plan.protect
+		room.ShamanVision = plan.vision
+		if plan.secondKill != "" {
+			room.TigerTeam = &models.TigerTeamNight{KillTargetID: plan.kill, SecondKillTargetID: plan.secondKill}
+		}
+		result = resolveNight(room, func(victim *models.Player, cause string) {
+			room.SetAlive(victim, false)
+			victim.DeathCause = cause
+		})
+	})
+
+	var deaths []string
+	for _, death := range result.Deaths {
+		deaths = append(deaths, death.PlayerID)
+	}
+	return result, deaths
+}
+
+func TestNightModifiersCompose(t *testing.T) {
+	tests := []struct {
+		name      string
+		modifier  string
+		plan      nightPlan
+		deaths    []string
+		protected bool
+		saved     bool
+		vision    string
+	}{
+		{
+			name: "calm night", plan: nightPlan{kill: "p5", secondKill: "p6"},
+			deaths: []string{"p5"},
+		},
+		{
+			name: "blood moon kills both", modifier: ModifierBloodMoon,
+			plan:   nightPlan{kill: "p5", secondKill: "p6"},
+			deaths: []string{"p5", "p6"},
+		},
+		{
+			name: "blood moon without a split", modifier: ModifierBloodMoon,
+			plan:   nightPlan{kill: "p5"},
+			deaths: []string{"p5"},
+		},
+		{
+			name: "blood moon against protection", modifier: ModifierBloodMoon,
+			plan:   nightPlan{kill: "p5", secondKill: "p6", protect: "p6"},
+			deaths: []string{"p5"}, protected: true,
+		},
+		{
+			name: "blood moon against the shaman's luck", modifier: ModifierBloodMoon,
+			plan:   nightPlan{kill: "p5", secondKill: "p4", vision: "p1"},
+			deaths: []string{"p5"}, saved: true, vision: "human",
+		},
+		{
+			name: "blood moon kills the hunter and the shaman", modifier: ModifierBloodMoon,
+			plan:   nightPlan{kill: "p3", secondKill: "p4", vision: "p2"},
+			deaths: []string{"p3", "p4"}, vision: "tiger",
+		},
+		{
+			name: "fog clouds the vision", modifier: ModifierFog,
+			plan:   nightPlan{kill: "p5", vision: "p2"},
+			deaths: []string{"p5"}, vision: "unknown",
+		},
+		{
+			name: "fog leaves the luck", modifier: ModifierFog,
+			plan:  nightPlan{kill: "p4", vision: "p1"},
+			saved: true, vision: "unknown",
+		},
+		{
+			name: "fog leaves protection", modifier: ModifierFog,
+			plan:      nightPlan{kill: "p5", protect: "p5"},
+			protected: true,
+		},
+		{
+			name: "quiet night", modifier: ModifierQuietNight,
+			plan:   nightPlan{kill: "p5", secondKill: "p6", vision: "p2"},
+			vision: "tiger",
+		},
+		{
+			name: "quiet night needs no protection", modifier: ModifierQuietNight,
+			plan: nightPlan{kill: "p5", protect: "p5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, deaths := resolvePlan(t, tt.modifier, tt.plan)
+
+			if !reflect.DeepEqual(deaths, tt.deaths) {
+				t.Errorf("deaths = %v, want %v", deaths, tt.deaths)
+			}
+			if result.Protected != tt.protected {
+				t.Errorf("protected = %v, want %v", result.Protected, tt.protected)
+			}
+			if result.ShamanSaved != tt.saved {
+				t.Errorf("shaman saved = %v, want %v", result.ShamanSaved, tt.saved)
+			}
+			if result.VisionResult != tt.vision {
+				t.Errorf("vision = %q, want %q", result.VisionResult, tt.vision)
+			}
+			if tt.modifier != "" && (len(result.Trace) == 0 || result.Trace[0].Key != TraceModifier) {
+				t.Errorf("the trace does not open with the modifier: %v", result.Trace)
+			}
+		})
+	}
+}
+
+func TestNightModifierDraw(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.NightModifiers = []string{ModifierBloodMoon, ModifierFog, ModifierQuietNight}
+	})
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleShaman})
+
+	drawn := make(map[string]int)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for i := 0; i < 600; i++ {
+			key := ""
+			if modifier := drawNightModifier(room); modifier != nil {
+				key = modifier.Key()
+			}
+			drawn[key]++
+		}
+
+		// Without a shaman alive, fog has nothing to cloud
+		room.SetAlive(room.Players["p2"], false)
+		for i := 0; i < 100; i++ {
+			if modifier := drawNightModifier(room); modifier != nil && modifier.Key() == ModifierFog {
+				t.Fatal("fog was drawn with no shaman alive")
+			}
+		}
+	})
+
+	// A lone tiger cannot split its kill, so a blood moon never rises
+	if drawn[ModifierBloodMoon] != 0 {
+		t.Errorf("blood moon drawn %d times for a lone tiger", drawn[ModifierBloodMoon])
+	}
+	// Calm nights weigh 2 against 1 each for fog and quiet nights
+	for key, want := range map[string]int{"": 300, ModifierFog: 150, ModifierQuietNight: 150} {
+		if got := drawn[key]; got < want/2 || got > want*3/2 {
+			t.Errorf("%q drawn %d times in 600, want about %d", key, got, want)
+		}
+	}
+}
+
+func TestNightModifierAnnouncedAtDusk(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		gm := NewGameManager()
+		gm.Seed = func() int64 { return seed }
+		code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+			settings.NightModifiers = []string{ModifierQuietNight}
+		})
+		startTestGame(t, gm, code, modifierRoles)
+		nextPhase(t, gm, code)
+		voteOut(t, gm, code, "p6")
+
+		var modifier string
+		withRoom(t, gm, code, func(room *models.GameRoom) { modifier = room.NightModifier })
+		if modifier == "" {
+			continue
+		}
+
+		key := "night_modifier." + ModifierQuietNight
+		for _, message := range gm.ChatHistory(code, "") {
+			if message.Type == models.MessageSystem && message.Key == key {
+				result := nextPhase(t, gm, code)
+				if len(result.Deaths) != 0 {
+					t.Errorf("a quiet night killed %v", result.Deaths)
+				}
+				withRoom(t, gm, code, func(room *models.GameRoom) {
+					if traces := room.NightTraces; len(traces) == 0 || traces[len(traces)-1].Modifier != ModifierQuietNight {
+						t.Error("the night's trace does not record the modifier")
+					}
+				})
+				return
+			}
+		}
+		t.Fatalf("seed %d drew %s without announcing %s", seed, modifier, key)
+	}
+	t.Fatal("no seed in 20 drew a quiet night")
+}
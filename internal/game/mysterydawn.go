@@ -0,0 +1,145 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// DefaultBodyRevealSeconds is how long into the day a concealed body is
+// found when the room sets no time
+const DefaultBodyRevealSeconds = 30
+
+// ErrNoBodyToReveal is returned for revealing a mystery dawn with nothing
+// concealed
+var ErrNoBodyToReveal = &errs.Error{Code: "NO_BODY_TO_REVEAL", Message: "there is no body to reveal"}
+
+// concealNightDeathsLocked keeps the tigers' victims secret from the village
+// as a mystery dawn day begins. They are dead as far as the rules go, so
+// they cannot act, vote or chat and the game-end check counts them out; only
+// the views and the public night result still show them alive.
+func concealNightDeathsLocked(room *models.GameRoom, result *NightResult, now time.Time) {
+	if !room.Settings.MysteryDawn || room.Phase != models.PhaseDay {
+		return
+	}
+
+	for _, death := range result.Deaths {
+		if death.Cause == models.DeathByTiger {
+			room.ConcealedDeaths = append(room.ConcealedDeaths, death.PlayerID)
+		}
+	}
+	if len(room.ConcealedDeaths) == 0 {
+		return
+	}
+
+	result.Concealed = true
+	revealAt := now.Add(time.Duration(bodyRevealSeconds(room.Settings)) * time.Second)
+	room.BodyRevealAt = models.TimestampPtr(revealAt)
+}
+
+// RevealBody reveals last night's concealed victims before their timer runs
+// out (host only)
+func (gm *GameManager) RevealBody(code models.RoomCode, playerID string) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if err := bodyRevealerLocked(room, playerID); err != nil {
+		return err
+	}
+
+	revealBodiesLocked(room)
+	gm.recordHostActionLocked(room, playerID, HostActionRevealBody, "", "")
+	return nil
+}
+
+// RevealDueBodies reveals the concealed victims whose timer ends at
+// revealAt, if that timer is still running and has elapsed. It reports
+// whether anyone was revealed.
+func (gm *GameManager) RevealDueBodies(code models.RoomCode, revealAt models.Timestamp, now time.Time) bool {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return false
+	}
+
+	// A timer whose bodies were already revealed has nothing left to do
+	if room.BodyRevealAt == nil || !room.BodyRevealAt.Equal(revealAt.Time) || now.Before(revealAt.Time) {
+		return false
+	}
+
+	revealBodiesLocked(room)
+	return true
+}
+
+// TakeRevealedDeaths returns the deaths revealed since the last call, for
+// the frontend to announce
+func (gm *GameManager) TakeRevealedDeaths(code models.RoomCode) []Death {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil
+	}
+
+	var deaths []Death
+	for _, playerID := range room.RevealedDeaths {
+		// A victim who has since left the room is not announced
+		if player := room.Players[playerID]; player != nil {
+			deaths = append(deaths, Death{PlayerID: player.ID, Username: player.Username, Cause: player.DeathCause})
+		}
+	}
+	room.RevealedDeaths = nil
+	return deaths
+}
+
+// bodyRevealerLocked checks that the player may reveal the concealed
+// victims right now
+func bodyRevealerLocked(room *models.GameRoom, playerID string) error {
+	if err := authorizeLocked(room, playerID, PermRevealBody); err != nil {
+		return err
+	}
+	if len(room.ConcealedDeaths) == 0 {
+		return ErrNoBodyToReveal
+	}
+	return nil
+}
+
+// revealBodiesLocked moves the concealed deaths to those waiting to be
+// announced; every view shows them dead from now on
+func revealBodiesLocked(room *models.GameRoom) {
+	room.RevealedDeaths = append(room.RevealedDeaths, room.ConcealedDeaths...)
+	room.ConcealedDeaths = nil
+	room.BodyRevealAt = nil
+}
+
+// deathConcealedFrom reports whether the viewer should still see the player
+// alive. The victim, the tiger team and the dead already know; an empty
+// viewer is the public view.
+func deathConcealedFrom(room *models.GameRoom, viewer *models.Player, playerID string) bool {
+	if viewer != nil && (viewer.ID == playerID || !viewer.IsAlive || isTigerTeam(viewer.Role)) {
+		return false
+	}
+	for _, concealed := range room.ConcealedDeaths {
+		if concealed == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyRevealSeconds is how long the room's bodies stay concealed
+func bodyRevealSeconds(settings models.RoomSettings) int {
+	if settings.MysteryDawnRevealSeconds > 0 {
+		return settings.MysteryDawnRevealSeconds
+	}
+	return DefaultBodyRevealSeconds
+}
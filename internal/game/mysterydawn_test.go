@@ -0,0 +1,168 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// mysteryDawnGame is a game of eight with mystery dawn on, brought to the
+// second day after p8 was voted out and the tiger, p1, killed p5
+func mysteryDawnGame(t *testing.T) (*GameManager, models.RoomCode, *NightResult) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, func(settings *models.RoomSettings) {
+		settings.MysteryDawn = true
+		settings.MysteryDawnRevealSeconds = 10
+	})
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleShaman})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+	result := playNight(t, gm, code, map[string]string{"p1": "p5"})
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Fatalf("phase = %s, want the second day", phase)
+	}
+	return gm, code, result
+}
+
+// seesDead reports whether the viewer's view shows the player dead, and
+// of what
+func seesDead(gm *GameManager, code models.RoomCode, viewerID, playerID string) (bool, string) {
+	view, _ := gm.RoomView(code, viewerID)
+	player := view.Players[playerID]
+	return !player.IsAlive, player.DeathCause
+}
+
+func TestMysteryDawnConcealsTheVictim(t *testing.T) {
+	gm, code, result := mysteryDawnGame(t)
+
+	public := result.Public()
+	if !public.Concealed || len(public.Deaths) != 1 || public.Deaths[0].PlayerID != "" || public.Deaths[0].Username != "" || public.Killed != "" {
+		t.Errorf("public night result = %+v, want one unnamed death", public)
+	}
+	if public.Deaths[0].Cause != models.DeathByTiger {
+		t.Errorf("public death cause = %q, want %q", public.Deaths[0].Cause, models.DeathByTiger)
+	}
+
+	for _, viewer := range []string{"", "p3", "p2"} {
+		if dead, cause := seesDead(gm, code, viewer, "p5"); dead || cause != "" {
+			t.Errorf("viewer %q sees p5 dead of %q before the body is found", viewer, cause)
+		}
+	}
+	for _, viewer := range []string{"p5", "p1", "p8"} {
+		if dead, cause := seesDead(gm, code, viewer, "p5"); !dead || cause != models.DeathByTiger {
+			t.Errorf("viewer %q sees p5 dead %v of %q, want killed by the tiger", viewer, dead, cause)
+		}
+	}
+
+	// The rules already count the victim out
+	if message, err := gm.PostChat(code, "p5", "I'm fine"); err == nil && message.Type == models.MessageChat {
+		t.Error("the concealed victim chatted with the living")
+	}
+	view, _ := gm.RoomView(code, "p5")
+	if view.Permissions.CanChat[models.MessageChat] {
+		t.Error("the concealed victim's permissions offer the day chat")
+	}
+	if view.BodyRevealAt == nil {
+		t.Error("no reveal time was set")
+	}
+}
+
+func TestConcealedBodyFoundOnTime(t *testing.T) {
+	gm, code, _ := mysteryDawnGame(t)
+	view, _ := gm.RoomView(code, "")
+	revealAt := *view.BodyRevealAt
+
+	if gm.RevealDueBodies(code, revealAt, revealAt.Add(-time.Second)) {
+		t.Fatal("the body was found before its time")
+	}
+	if gm.RevealDueBodies(code, models.Timestamp{Time: revealAt.Add(time.Second)}, revealAt.Add(time.Minute)) {
+		t.Fatal("another timer found the body")
+	}
+	if deaths := gm.TakeRevealedDeaths(code); len(deaths) != 0 {
+		t.Fatalf("deaths announced early: %+v", deaths)
+	}
+
+	if !gm.RevealDueBodies(code, revealAt, revealAt.Time) {
+		t.Fatal("the body was not found on time")
+	}
+	deaths := gm.TakeRevealedDeaths(code)
+	if len(deaths) != 1 || deaths[0].PlayerID != "p5" || deaths[0].Cause != models.DeathByTiger {
+		t.Errorf("revealed deaths = %+v, want p5 killed by the tiger", deaths)
+	}
+	if again := gm.TakeRevealedDeaths(code); len(again) != 0 {
+		t.Errorf("the deaths were announced twice: %+v", again)
+	}
+	if dead, _ := seesDead(gm, code, "p3", "p5"); !dead {
+		t.Error("the village still sees p5 alive after the body was found")
+	}
+	if gm.RevealDueBodies(code, revealAt, revealAt.Add(time.Second)) {
+		t.Error("the timer found the body a second time")
+	}
+}
+
+func TestHostRevealsTheBody(t *testing.T) {
+	gm, code, _ := mysteryDawnGame(t)
+	view, _ := gm.RoomView(code, "")
+	revealAt := *view.BodyRevealAt
+
+	if err := gm.RevealBody(code, "p3"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("a guest revealed the body: %v", err)
+	}
+	if host, _ := gm.RoomView(code, "p1"); !host.Permissions.CanRevealBody {
+		t.Error("the host's permissions do not offer the reveal")
+	}
+	if err := gm.RevealBody(code, "p1"); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+	if deaths := gm.TakeRevealedDeaths(code); len(deaths) != 1 || deaths[0].PlayerID != "p5" {
+		t.Errorf("revealed deaths = %+v, want p5", deaths)
+	}
+	if dead, _ := seesDead(gm, code, "", "p5"); !dead {
+		t.Error("the public view still shows p5 alive")
+	}
+	if err := gm.RevealBody(code, "p1"); !errors.Is(err, ErrNoBodyToReveal) {
+		t.Errorf("revealing twice: %v, want %s", err, ErrNoBodyToReveal.Code)
+	}
+	if gm.RevealDueBodies(code, revealAt, revealAt.Time) {
+		t.Error("the timer found a body the host already revealed")
+	}
+
+	actions, _ := gm.HostActionLog(code)
+	if n := len(actions); n == 0 || actions[n-1].Action != HostActionRevealBody {
+		t.Errorf("host action log = %+v, want the reveal recorded", actions)
+	}
+}
+
+func TestDayEndRevealsTheBody(t *testing.T) {
+	gm, code, _ := mysteryDawnGame(t)
+	nextPhase(t, gm, code)
+
+	if dead, _ := seesDead(gm, code, "p3", "p5"); !dead {
+		t.Error("the vote began with p5 still hidden")
+	}
+	if deaths := gm.TakeRevealedDeaths(code); len(deaths) != 1 || deaths[0].PlayerID != "p5" {
+		t.Errorf("revealed deaths = %+v, want p5", deaths)
+	}
+}
+
+func TestConcealedDeathEndsTheGame(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) { settings.MysteryDawn = true })
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleTiger})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p6")
+
+	// Two tigers and two humans left: the kill ends the game, hidden or not
+	playNight(t, gm, code, map[string]string{"p1": "p5", "p2": "p5"})
+	view, _ := gm.RoomView(code, "")
+	if view.Phase != models.PhaseEnded || view.WinningTeam != "tiger" {
+		t.Errorf("phase %s, winner %q; want the tigers to win at dawn", view.Phase, view.WinningTeam)
+	}
+	if dead, _ := seesDead(gm, code, "p3", "p5"); !dead {
+		t.Error("the game ended with the victim still hidden")
+	}
+}
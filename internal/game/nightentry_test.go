@@ -0,0 +1,79 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestNightEntryPerViewer(t *testing.T) {
+	gm, code := deathsGame(t)
+	public, views, _ := gm.RoomViews(code)
+
+	for _, tc := range []struct {
+		viewer    string
+		willAct   bool
+		knowsTurn bool
+	}{
+		{"p1", true, false},  // tiger
+		{"p2", true, false},  // hunter
+		{"p3", true, false},  // shaman
+		{"p4", false, false}, // villager
+		{"p8", false, true},  // voted out, watching with every role known
+	} {
+		view := views[tc.viewer]
+		entry := view.NightEntry
+		if entry == nil {
+			t.Errorf("%s has no night entry", tc.viewer)
+			continue
+		}
+		if entry.WillAct != tc.willAct {
+			t.Errorf("%s will act = %v, want %v", tc.viewer, entry.WillAct, tc.willAct)
+		}
+		if got := len(entry.Order) > 0; got != tc.knowsTurn {
+			t.Errorf("%s is told the order %v", tc.viewer, entry.Order)
+		}
+		if want := int(DefaultNightCeiling.Seconds()); entry.MaxDurationSeconds != want {
+			t.Errorf("%s's night lasts at most %ds, want %d", tc.viewer, entry.MaxDurationSeconds, want)
+		}
+		if view.NightActionOrder != nil {
+			t.Errorf("%s was sent the raw night order", tc.viewer)
+		}
+	}
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if order := views["p8"].NightEntry.Order; !reflect.DeepEqual(order, room.NightActionOrder) {
+			t.Errorf("the spectator is told %v, want %v", order, room.NightActionOrder)
+		}
+	})
+	if public.NightEntry == nil || public.NightEntry.WillAct || public.NightEntry.Order != nil {
+		t.Errorf("the public entry = %+v, want no turn and no order", public.NightEntry)
+	}
+	if update := models.NewPhaseUpdate(views["p2"]); update.NightEntry == nil || !update.NightEntry.WillAct {
+		t.Errorf("the hunter's phase update carries %+v", update.NightEntry)
+	}
+}
+
+func TestNightEntryOnlyAtNight(t *testing.T) {
+	gm, code := deathsGame(t)
+	playNight(t, gm, code, nil)
+
+	if view, _ := gm.RoomView(code, "p2"); view.NightEntry != nil {
+		t.Errorf("the day view carries %+v", view.NightEntry)
+	}
+}
+
+func TestNightEntryKeepsOrderFromHardHiddenDead(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, func(settings *models.RoomSettings) {
+		settings.HardHiddenRoles = true
+	})
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleHunter})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+
+	if view, _ := gm.RoomView(code, "p8"); view.NightEntry == nil || view.NightEntry.Order != nil {
+		t.Errorf("a dead player under hard-hidden roles sees %+v", view.NightEntry)
+	}
+}
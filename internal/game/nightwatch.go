@@ -0,0 +1,127 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const (
+	DefaultNightCeiling       = 5 * time.Minute  // longest a night may last before it is resolved anyway
+	DefaultNightWatchInterval = 15 * time.Second // how often nights are checked against their ceiling
+)
+
+// ForcedNight is a night the watch resolved because it outlived its ceiling
+type ForcedNight struct {
+	RoomCode models.RoomCode
+	Result   *NightResult
+}
+
+// StartNightWatch resolves nights that outlive NightCeiling every interval
+// until stop is called. It is a safety net for turn-order bugs that leave a
+// night waiting on nothing. resolved is called outside the manager lock for
+// each night it forces, so the frontend can announce the new day.
+func (gm *GameManager) StartNightWatch(interval time.Duration, resolved func(ForcedNight)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				for _, forced := range gm.ResolveOverdueNights(now) {
+					resolved(forced)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// ResolveOverdueNights force-resolves every night past its ceiling with the
+// actions recorded so far; whoever has not acted is skipped. Rooms waiting on
+// a dead hunter's shot have already resolved their night and are left alone.
+func (gm *GameManager) ResolveOverdueNights(now time.Time) []ForcedNight {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	var forced []ForcedNight
+	for code, room := range gm.Rooms {
+		if room.Phase != models.PhaseNight || room.WaitingHunterShoot || pausedLocked(room) ||
+			room.NightCeilingAt == nil || now.Before(room.NightCeilingAt.Time) {
+			continue
+		}
+
+		log.Printf("⚠️ Room %s night %d outlived its ceiling; resolving it: %s", code, room.NightNumber, nightStateLocked(room))
+		result, err := gm.forceResolveNightLocked(room)
+		if err != nil {
+			continue
+		}
+		forced = append(forced, ForcedNight{RoomCode: code, Result: result})
+	}
+
+	sort.Slice(forced, func(i, j int) bool { return forced[i].RoomCode < forced[j].RoomCode })
+	return forced
+}
+
+// forceResolveNightLocked resolves the night with the actions recorded so
+// far, skipping whoever has not acted
+func (gm *GameManager) forceResolveNightLocked(room *models.GameRoom) (*NightResult, error) {
+	gm.nightsForced++
+
+	skipPendingNightTurnsLocked(room)
+	setNightRoleLocked(room, "")
+
+	result, err := gm.endNightLocked(room, ReasonNightTimedOut)
+	if err != nil {
+		log.Printf("⚠️ Room %s night could not be resolved: %v", room.Code, err)
+		room.NightCeilingAt = nil // don't retry every sweep; an admin repair is needed
+		return nil, err
+	}
+	return result, nil
+}
+
+// NightsForceResolved returns how many nights the night watch has had to resolve
+func (gm *GameManager) NightsForceResolved() int {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.nightsForced
+}
+
+// skipPendingNightTurnsLocked records a timed-out skip for every living
+// player with a night turn who has not acted; ProcessNightPhase settles the
+// tiger team itself
+func skipPendingNightTurnsLocked(room *models.GameRoom) {
+	for _, player := range room.LivingPlayers() {
+		if player.HasActedThisNight || isTigerTeam(player.Role) || !hasNightTurn(player.Role) {
+			continue
+		}
+		recordActionLocked(room, player, models.ActionSkip, nil)
+		lastActionLocked(room, player, models.ActionSkip).Outcome = "timed_out"
+		markNightActionCompleteLocked(room, player)
+	}
+}
+
+// hasNightTurn reports whether the role acts during the night
+func hasNightTurn(role models.Role) bool {
+	info, ok := roleCatalog[role]
+	return ok && info.NightAction != nil
+}
+
+// nightStateLocked describes the turn machinery for the warning log
+func nightStateLocked(room *models.GameRoom) string {
+	acted := 0
+	for _, player := range room.Players {
+		if player.HasActedThisNight {
+			acted++
+		}
+	}
+	return fmt.Sprintf("currentRole=%q order=%v turnToken=%t acted=%d/%d tigerTeamResolved=%t",
+		room.CurrentNightRole, room.NightActionOrder, room.TurnToken != "", acted, len(room.Players), tigerTeamResolvedLocked(room))
+}
@@ -0,0 +1,159 @@
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// ceilingGame is a game of six on its first night: p1 the tiger, p2 the
+// shaman and p3 the hunter, with everyone connected and p6 voted out
+func ceilingGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleShaman, "p3": models.RoleHunter})
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+		gm.SetConnected(code, id, true)
+	}
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p6")
+	return gm, code
+}
+
+// nightCeiling is when the room's night will be resolved regardless
+func nightCeiling(t *testing.T, gm *GameManager, code models.RoomCode) time.Time {
+	t.Helper()
+
+	var ceiling time.Time
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.NightCeilingAt == nil {
+			t.Fatal("the night has no ceiling")
+		}
+		ceiling = room.NightCeilingAt.Time
+	})
+	return ceiling
+}
+
+func TestNightCeilingResolvesABrokenTurnOrder(t *testing.T) {
+	gm, code := ceilingGame(t)
+
+	// The hunter guards p5 and the tiger picks p4, then the turn order is
+	// lost before the shaman's turn
+	if err := gm.PerformNightAction(code, "p3", "p5", turnOf(t, gm, code, "p3").TurnToken); err != nil {
+		t.Fatalf("hunter protects: %v", err)
+	}
+	if err := gm.PerformNightAction(code, "p1", "p4", turnOf(t, gm, code, "p1").TurnToken); err != nil {
+		t.Fatalf("tiger kills: %v", err)
+	}
+	data, err := gm.RecordFixture(code)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	var fixture map[string]interface{}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+	room := fixture["room"].(map[string]interface{})
+	delete(room, "currentNightRole")
+	delete(room, "nightActionOrder")
+	delete(room, "turnToken")
+	if data, err = json.Marshal(fixture); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+
+	stuck := NewGameManager()
+	stuckCode, err := stuck.LoadFixture(data)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if prompts, _ := stuck.CurrentTurnPrompts(stuckCode); len(prompts) != 0 {
+		t.Fatalf("the broken night still prompts %v", prompts)
+	}
+
+	ceiling := nightCeiling(t, stuck, stuckCode)
+	if forced := stuck.ResolveOverdueNights(ceiling.Add(-time.Second)); len(forced) != 0 {
+		t.Fatalf("resolved %v before the ceiling", forced)
+	}
+	forced := stuck.ResolveOverdueNights(ceiling)
+	if len(forced) != 1 || forced[0].RoomCode != stuckCode {
+		t.Fatalf("forced %v, want the stuck room", forced)
+	}
+	if deaths := deathIDs(forced[0].Result); !reflect.DeepEqual(deaths, []string{"p4"}) {
+		t.Errorf("deaths = %v, want the tiger's recorded kill", deaths)
+	}
+	if n := stuck.NightsForceResolved(); n != 1 {
+		t.Errorf("night_force_resolved = %d, want 1", n)
+	}
+
+	// The shaman, who never got their turn, was skipped for timing out
+	withRoom(t, stuck, stuckCode, func(room *models.GameRoom) {
+		history := room.Players["p2"].ActionHistory
+		if n := len(history); n == 0 || history[n-1].ActionType != models.ActionSkip || history[n-1].Outcome != "timed_out" {
+			t.Errorf("the shaman's actions = %+v, want a timed-out skip", history)
+		}
+		if room.NightCeilingAt != nil {
+			t.Error("the ceiling outlived the night")
+		}
+	})
+
+	// The day that follows plays on to the end
+	if phase := phaseOf(t, stuck, stuckCode); phase != models.PhaseDay {
+		t.Fatalf("phase = %s, want the day", phase)
+	}
+	nextPhase(t, stuck, stuckCode)
+	voteOut(t, stuck, stuckCode, "p1")
+	if phase := phaseOf(t, stuck, stuckCode); phase != models.PhaseEnded {
+		t.Errorf("phase = %s, want the tiger found", phase)
+	}
+}
+
+func TestNightCeilingCancelledByResolution(t *testing.T) {
+	gm, code := ceilingGame(t)
+	ceiling := nightCeiling(t, gm, code)
+
+	playNight(t, gm, code, nil)
+	if forced := gm.ResolveOverdueNights(ceiling.Add(time.Hour)); len(forced) != 0 {
+		t.Errorf("forced %v after the night ended", forced)
+	}
+	if n := gm.NightsForceResolved(); n != 0 {
+		t.Errorf("night_force_resolved = %d, want 0", n)
+	}
+}
+
+func TestNightCeilingWaitsOutAPause(t *testing.T) {
+	gm, code := ceilingGame(t)
+	ceiling := nightCeiling(t, gm, code)
+
+	if _, _, err := gm.RequestAbort(code, "p1"); err != nil {
+		t.Fatalf("request abort: %v", err)
+	}
+	if forced := gm.ResolveOverdueNights(ceiling.Add(time.Minute)); len(forced) != 0 {
+		t.Fatalf("forced %v while paused", forced)
+	}
+
+	// The vote ran for two minutes before it was turned down
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.AbortVote.StartedAt = room.AbortVote.StartedAt.Add(-2 * time.Minute)
+	})
+	for _, id := range []string{"p2", "p3"} {
+		if _, err := gm.VoteAbort(code, id, false); err != nil {
+			t.Fatalf("%s rejects: %v", id, err)
+		}
+	}
+
+	shifted := nightCeiling(t, gm, code)
+	if shifted.Sub(ceiling) < 2*time.Minute {
+		t.Fatalf("ceiling moved by %v, want the two minutes paused", shifted.Sub(ceiling))
+	}
+	if forced := gm.ResolveOverdueNights(ceiling.Add(time.Minute)); len(forced) != 0 {
+		t.Errorf("forced %v before the shifted ceiling", forced)
+	}
+	if forced := gm.ResolveOverdueNights(shifted); len(forced) != 1 {
+		t.Errorf("forced %v at the shifted ceiling, want the night", forced)
+	}
+}
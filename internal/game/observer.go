@@ -0,0 +1,111 @@
+package game
+
+import (
+	"log"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// RoomObserver receives room lifecycle notifications from the GameManager.
+//
+// Ordering guarantees:
+//   - Observers are called synchronously, while the manager lock is held, in the
+//     order they were passed to NewGameManager.
+//   - For a single room, callbacks arrive in the order the events happened:
+//     OnRoomCreated is always first and OnRoomDeleted always last.
+//   - OnPlayerDied is delivered before the OnPhaseChanged or OnGameEnded that
+//     the death caused.
+//   - Ending a game fires OnGameEnded only, not OnPhaseChanged.
+//   - The last player leaving a lobby fires OnRoomDeleted only, not OnPlayerLeft.
+//   - An ended game reclaimed by the janitor fires OnRoomArchived just before
+//     OnRoomDeleted.
+//
+// Because the lock is held, observers must return quickly and must never call
+// back into the GameManager. Slow work (network, disk) belongs on a goroutine
+// or queue owned by the observer. A panic inside an observer is recovered and
+// logged so it can never break a game.
+//
+// Practice rooms are never reported to observers, except to RoomTeardown.
+type RoomObserver interface {
+	OnRoomCreated(room *models.GameRoom)
+	OnPlayerJoined(room *models.GameRoom, player *models.Player)
+	OnPlayerLeft(room *models.GameRoom, player *models.Player)
+	OnGameStarted(room *models.GameRoom)
+	OnPhaseChanged(room *models.GameRoom, from models.GamePhase)
+	OnPlayerDied(room *models.GameRoom, player *models.Player)
+	OnGameEnded(room *models.GameRoom, winner string)
+	OnRoomDeleted(room *models.GameRoom)
+	OnRoomArchived(room *models.GameRoom)
+	OnHostAction(room *models.GameRoom, action models.HostAction)
+}
+
+// RoomTeardown is implemented by observers that hold resources for a room,
+// such as a transport's connections and queues. Unlike the RoomObserver
+// callbacks, TeardownRoom is called for practice rooms too, when any room
+// is archived, just after OnRoomArchived would be. It runs under the same
+// rules as the other callbacks.
+type RoomTeardown interface {
+	TeardownRoom(room *models.GameRoom)
+}
+
+// NopObserver implements RoomObserver with no-op methods. Embed it to
+// implement only the callbacks you care about.
+type NopObserver struct{}
+
+func (NopObserver) OnRoomCreated(*models.GameRoom)                    {}
+func (NopObserver) OnPlayerJoined(*models.GameRoom, *models.Player)   {}
+func (NopObserver) OnPlayerLeft(*models.GameRoom, *models.Player)     {}
+func (NopObserver) OnGameStarted(*models.GameRoom)                    {}
+func (NopObserver) OnPhaseChanged(*models.GameRoom, models.GamePhase) {}
+func (NopObserver) OnPlayerDied(*models.GameRoom, *models.Player)     {}
+func (NopObserver) OnGameEnded(*models.GameRoom, string)              {}
+func (NopObserver) OnRoomDeleted(*models.GameRoom)                    {}
+func (NopObserver) OnRoomArchived(*models.GameRoom)                   {}
+func (NopObserver) OnHostAction(*models.GameRoom, models.HostAction)  {}
+
+// notify calls fn for every registered observer, isolating panics
+func (gm *GameManager) notify(room *models.GameRoom, fn func(o RoomObserver)) {
+	if room.Settings.PracticeMode {
+		return
+	}
+
+	for _, o := range gm.observers {
+		observe(o, func() { fn(o) })
+	}
+}
+
+// teardown calls TeardownRoom on every observer that implements it,
+// practice rooms included
+func (gm *GameManager) teardown(room *models.GameRoom) {
+	for _, o := range gm.observers {
+		if t, ok := o.(RoomTeardown); ok {
+			observe(o, func() { t.TeardownRoom(room) })
+		}
+	}
+}
+
+// observe runs one observer callback, recovering and logging a panic
+func observe(o RoomObserver, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Room observer %T panicked: %v", o, r)
+		}
+	}()
+	fn()
+}
+
+// killPlayerLocked marks a player dead with the given cause and notifies observers
+func (gm *GameManager) killPlayerLocked(room *models.GameRoom, player *models.Player, cause string) {
+	if !player.IsAlive {
+		return
+	}
+	room.SetAlive(player, false)
+	player.DeathCause = cause
+	gm.notify(room, func(o RoomObserver) { o.OnPlayerDied(room, player) })
+}
+
+// endGameLocked ends the game with the given winner
+func (gm *GameManager) endGameLocked(room *models.GameRoom, winner string) error {
+	room.WinningTeam = winner
+	return gm.transitionLocked(room, models.PhaseEnded, ReasonGameOver)
+}
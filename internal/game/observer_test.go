@@ -0,0 +1,181 @@
+package game
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// recordingObserver writes down every callback it receives
+type recordingObserver struct {
+	events []string
+}
+
+func (r *recordingObserver) add(format string, args ...interface{}) {
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingObserver) OnRoomCreated(room *models.GameRoom) { r.add("created by %s", room.HostID) }
+func (r *recordingObserver) OnPlayerJoined(room *models.GameRoom, player *models.Player) {
+	r.add("joined %s", player.ID)
+}
+func (r *recordingObserver) OnPlayerLeft(room *models.GameRoom, player *models.Player) {
+	r.add("left %s", player.ID)
+}
+func (r *recordingObserver) OnGameStarted(room *models.GameRoom) { r.add("started in %s", room.Phase) }
+func (r *recordingObserver) OnPhaseChanged(room *models.GameRoom, from models.GamePhase) {
+	r.add("%s -> %s", from, room.Phase)
+}
+func (r *recordingObserver) OnPlayerDied(room *models.GameRoom, player *models.Player) {
+	r.add("died %s (%s)", player.ID, player.DeathCause)
+}
+func (r *recordingObserver) OnGameEnded(room *models.GameRoom, winner string) {
+	r.add("ended: %s", winner)
+}
+func (r *recordingObserver) OnRoomDeleted(room *models.GameRoom)  { r.add("deleted") }
+func (r *recordingObserver) OnRoomArchived(room *models.GameRoom) { r.add("archived") }
+func (r *recordingObserver) OnHostAction(room *models.GameRoom, action models.HostAction) {
+	r.add("host %s", action.Action)
+}
+
+// panickingObserver breaks on every callback
+type panickingObserver struct {
+	NopObserver
+}
+
+func (panickingObserver) OnRoomCreated(*models.GameRoom) { panic("observer bug") }
+
+func TestObserverSeesWholeGameInOrder(t *testing.T) {
+	recorder := &recordingObserver{}
+	gm := NewGameManager(recorder)
+	code := newTestRoom(t, gm, 5, nil)
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p4")
+	playNight(t, gm, code, map[string]string{"p1": "p5", "p2": "p5", "p3": "p1"})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p1")
+
+	gm.mu.Lock()
+	gm.janitor.idleTimeout = time.Minute
+	gm.mu.Unlock()
+	if removed := gm.SweepIdleRooms(time.Now().Add(time.Hour)); removed != 1 {
+		t.Fatalf("swept %d rooms, want 1", removed)
+	}
+
+	want := []string{
+		"created by p1",
+		"joined p2",
+		"joined p3",
+		"joined p4",
+		"joined p5",
+		"started in day",
+		"day -> voting",
+		"died p4 (" + models.DeathByVote + ")",
+		"voting -> night",
+		"night -> day",
+		"day -> voting",
+		"died p1 (" + models.DeathByVote + ")",
+		"ended: human",
+		"archived",
+		"deleted",
+	}
+	if !reflect.DeepEqual(recorder.events, want) {
+		t.Errorf("callbacks:\n  %s\nwant:\n  %s", strings.Join(recorder.events, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+func TestObserverPanicIsIsolated(t *testing.T) {
+	recorder := &recordingObserver{}
+	gm := NewGameManager(panickingObserver{}, recorder)
+
+	if _, err := gm.CreateRoom("p1", "Player1", models.DefaultRoomSettings()); err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	if want := []string{"created by p1"}; !reflect.DeepEqual(recorder.events, want) {
+		t.Errorf("later observer got %v, want %v", recorder.events, want)
+	}
+}
+
+func TestObserversNeverHearOfPracticeRooms(t *testing.T) {
+	recorder := &recordingObserver{}
+	gm := NewGameManager(recorder)
+
+	settings := models.DefaultRoomSettings()
+	settings.PracticeMode = true
+	room, err := gm.CreateRoom("p1", "Player1", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	if err := gm.StartGame(room.Code, "p1"); err != nil {
+		t.Fatalf("start practice game: %v", err)
+	}
+	if len(recorder.events) != 0 {
+		t.Errorf("practice room was reported: %v", recorder.events)
+	}
+}
+
+// teardownRecorder is a transport that only wants to hear of rooms to let go
+type teardownRecorder struct {
+	NopObserver
+	rooms []models.RoomCode
+}
+
+func (r *teardownRecorder) TeardownRoom(room *models.GameRoom) { r.rooms = append(r.rooms, room.Code) }
+
+func TestArchivedPracticeRoomIsTornDown(t *testing.T) {
+	recorder := &recordingObserver{}
+	transport := &teardownRecorder{}
+	gm := NewGameManager(recorder, transport)
+
+	settings := models.DefaultRoomSettings()
+	settings.PracticeMode = true
+	settings.MinDiscussionSeconds = 0
+	settings.QuorumFraction = 0
+	room, err := gm.CreateRoom("p1", "Player1", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	if err := gm.StartGame(room.Code, "p1"); err != nil {
+		t.Fatalf("start practice game: %v", err)
+	}
+	// One bot is the only tiger, and the village votes it out
+	var tiger string
+	withRoom(t, gm, room.Code, func(room *models.GameRoom) {
+		for _, player := range room.Players {
+			role := models.RoleVillager
+			if tiger == "" && player.IsBot {
+				tiger, role = player.ID, models.RoleTiger
+			}
+			room.SetRole(player, role)
+			player.Abilities = roleAbilities(role, room.Settings)
+		}
+	})
+	nextPhase(t, gm, room.Code)
+	voteOut(t, gm, room.Code, tiger)
+	if phase := phaseOf(t, gm, room.Code); phase != models.PhaseEnded {
+		t.Fatalf("phase = %s, want the practice game over", phase)
+	}
+
+	gm.mu.Lock()
+	gm.janitor.idleTimeout = time.Minute
+	gm.mu.Unlock()
+	if removed := gm.SweepIdleRooms(time.Now().Add(time.Hour)); removed != 1 {
+		t.Fatalf("swept %d rooms, want 1", removed)
+	}
+	if want := []models.RoomCode{room.Code}; !reflect.DeepEqual(transport.rooms, want) {
+		t.Errorf("torn down %v, want %v", transport.rooms, want)
+	}
+	if len(recorder.events) != 0 {
+		t.Errorf("practice room was reported: %v", recorder.events)
+	}
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// endTurn ends the player's night turn the given way: "pass", "skip" or "timeout"
+func endTurn(t *testing.T, gm *GameManager, code models.RoomCode, playerID, how string) {
+	t.Helper()
+
+	prompt := turnOf(t, gm, code, playerID)
+	var err error
+	switch how {
+	case "pass":
+		_, err = gm.PassNightAction(code, playerID, prompt.TurnToken)
+	case "skip":
+		err = gm.SkipNightAction(code, playerID, prompt.TurnToken)
+	case "timeout":
+		withRoom(t, gm, code, func(room *models.GameRoom) {
+			room.TurnEndTime = models.TimestampPtr(time.Now().Add(-time.Second))
+		})
+		_, err = gm.TimeoutNightTurn(code, prompt.TurnToken)
+	}
+	if err != nil {
+		t.Fatalf("%s: %s: %v", playerID, how, err)
+	}
+}
+
+func TestPassSkipAndTimeoutAreRecordedApart(t *testing.T) {
+	for _, tc := range []struct {
+		playerID string
+		how      string
+		action   string
+		outcome  string
+		recap    string
+	}{
+		{"p2", "pass", models.ActionPass, "", RecapPassed},
+		{"p2", "skip", models.ActionSkip, "", RecapNoAction},
+		{"p2", "timeout", models.ActionSkip, "timed_out", RecapNoAction},
+		{"p3", "pass", models.ActionPass, "", RecapPassed},
+		{"p3", "skip", models.ActionSkip, "", RecapNoAction},
+		{"p3", "timeout", models.ActionSkip, "timed_out", RecapNoAction},
+	} {
+		t.Run(tc.playerID+" "+tc.how, func(t *testing.T) {
+			gm, code := deathsGame(t)
+			endTurn(t, gm, code, tc.playerID, tc.how)
+			result := playNight(t, gm, code, nil)
+
+			withRoom(t, gm, code, func(room *models.GameRoom) {
+				var last models.ActionRecord
+				for _, record := range room.Players[tc.playerID].ActionHistory {
+					if record.Phase == models.PhaseNight {
+						last = record
+					}
+				}
+				if last.ActionType != tc.action || last.Outcome != tc.outcome {
+					t.Errorf("recorded %s %q, want %s %q", last.ActionType, last.Outcome, tc.action, tc.outcome)
+				}
+			})
+			if recap := result.Recaps[tc.playerID]; recap.Outcome != tc.recap {
+				t.Errorf("recap = %q, want %s", recap.Outcome, tc.recap)
+			}
+		})
+	}
+}
+
+func TestHunterPassFreesLastNightsProtection(t *testing.T) {
+	gm, code := deathsGame(t)
+	playNight(t, gm, code, map[string]string{"p2": "p4"})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p7")
+
+	endTurn(t, gm, code, "p2", "pass")
+	playNight(t, gm, code, nil)
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p6")
+
+	// After a night of protecting no one, p4 may be protected again
+	prompt := turnOf(t, gm, code, "p2")
+	if err := gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken); err != nil {
+		t.Errorf("protect p4 after a pass: %v", err)
+	}
+}
+
+func TestTigersMustKill(t *testing.T) {
+	gm, code := deathsGame(t)
+	prompt := turnOf(t, gm, code, "p1")
+
+	if _, err := gm.PassNightAction(code, "p1", prompt.TurnToken); !errors.Is(err, ErrKillRequired) {
+		t.Fatalf("the tiger passed: err = %v, want %v", err, ErrKillRequired)
+	}
+	// The turn is still theirs
+	if err := gm.PerformNightAction(code, "p1", "p4", prompt.TurnToken); err != nil {
+		t.Errorf("a kill after the refused pass: %v", err)
+	}
+}
+
+func TestTigersMayPassWhenAllowed(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, func(settings *models.RoomSettings) {
+		settings.TigersMustKill = false
+	})
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleHunter, "p3": models.RoleShaman})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+
+	endTurn(t, gm, code, "p1", "pass")
+	result := playNight(t, gm, code, nil)
+	if len(result.Deaths) != 0 {
+		t.Errorf("a night the tigers passed killed %v", deathIDs(result))
+	}
+	if recap := result.Recaps["p1"]; recap.Outcome != RecapPassed {
+		t.Errorf("tiger recap = %q, want %s", recap.Outcome, RecapPassed)
+	}
+}
@@ -0,0 +1,32 @@
+package game
+
+import (
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// permissionsFor answers each of the viewer's permissions with the check
+// that guards the action itself, so a client enabling its controls from
+// them never offers what the server would refuse. Only players get them.
+func permissionsFor(room *models.GameRoom, viewer *models.Player) *models.Permissions {
+	if viewer == nil {
+		return nil
+	}
+
+	_, voteErr := voterLocked(room, viewer.ID)
+	_, actErr := nightActorLocked(room, viewer.ID, room.TurnToken)
+	channel, chatErr := chatChannelLocked(room, viewer)
+
+	return &models.Permissions{
+		CanVote: voteErr == nil,
+		CanChat: map[string]bool{
+			models.MessageChat: chatErr == nil && channel == models.MessageChat,
+			models.MessageDead: chatErr == nil && channel == models.MessageDead,
+		},
+		CanAct:            actErr == nil,
+		CanShoot:          hunterMayShootLocked(room, viewer) == nil,
+		CanSkipPhase:      earlySkipLocked(room, viewer.ID) == nil,
+		CanStartGame:      authorizeLocked(room, viewer.ID, PermStartGame) == nil && startableLocked(room) == nil,
+		CanManageSettings: settingsEditorLocked(room, viewer.ID) == nil,
+		CanRevealBody:     bodyRevealerLocked(room, viewer.ID) == nil,
+	}
+}
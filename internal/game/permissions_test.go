@@ -0,0 +1,135 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// permissionsOf is the permissions block of the player's own view
+func permissionsOf(t *testing.T, gm *GameManager, code models.RoomCode, playerID string) models.Permissions {
+	t.Helper()
+
+	view, _ := gm.RoomView(code, playerID)
+	if view.Permissions == nil {
+		t.Fatalf("%s's view has no permissions", playerID)
+	}
+	return *view.Permissions
+}
+
+// may lists what a viewer may do; chat and dead are the channels they may
+// write to
+type may struct {
+	vote, chat, dead, act, shoot, skip, start, settings bool
+}
+
+func (m may) permissions() models.Permissions {
+	return models.Permissions{
+		CanVote:           m.vote,
+		CanChat:           map[string]bool{models.MessageChat: m.chat, models.MessageDead: m.dead},
+		CanAct:            m.act,
+		CanShoot:          m.shoot,
+		CanSkipPhase:      m.skip,
+		CanStartGame:      m.start,
+		CanManageSettings: m.settings,
+	}
+}
+
+func wantPermissions(t *testing.T, gm *GameManager, code models.RoomCode, want map[string]may) {
+	t.Helper()
+
+	for id, m := range want {
+		if got := permissionsOf(t, gm, code, id); !reflect.DeepEqual(got, m.permissions()) {
+			t.Errorf("%s may %+v, want %+v", id, got, m.permissions())
+		}
+	}
+}
+
+func TestPermissionsInTheLobby(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	wantPermissions(t, gm, code, map[string]may{
+		"p1": {chat: true, start: true, settings: true},
+		"p2": {chat: true},
+	})
+
+	// Too few players to start
+	short := newTestRoom(t, gm, 4, nil)
+	wantPermissions(t, gm, short, map[string]may{
+		"p1": {chat: true, settings: true},
+	})
+
+	view, _ := gm.RoomView(code, "")
+	if view.Permissions != nil {
+		t.Errorf("the public view carries %+v", view.Permissions)
+	}
+}
+
+func TestPermissionsThroughTheGame(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p2": models.RoleTiger,
+		"p3": models.RoleHunter,
+		"p4": models.RoleShaman,
+	})
+
+	// The host is a villager here, and only differs in ending the phase
+	wantPermissions(t, gm, code, map[string]may{
+		"p1": {chat: true, skip: true},
+		"p5": {chat: true},
+	})
+
+	nextPhase(t, gm, code)
+	wantPermissions(t, gm, code, map[string]may{
+		"p1": {vote: true, chat: true, skip: true},
+		"p5": {vote: true, chat: true},
+	})
+
+	voteOut(t, gm, code, "p8")
+	holder, _ := turnHolder(t, gm, code)
+	want := map[string]may{
+		"p1": {chat: true, skip: true},
+		"p5": {chat: true},
+		"p8": {dead: true}, // voted out, speaking with the dead
+	}
+	want[holder] = may{chat: true, act: true}
+	wantPermissions(t, gm, code, want)
+}
+
+func TestPermissionsOfDeadHunterAwaitingShot(t *testing.T) {
+	gm, code := deadHunterGame(t, nil)
+
+	wantPermissions(t, gm, code, map[string]may{
+		"p2": {dead: true, shoot: true},
+		"p4": {chat: true},
+	})
+
+	if _, err := gm.HunterShoot(code, "p2", "p5", true); err != nil {
+		t.Fatalf("shoot: %v", err)
+	}
+	wantPermissions(t, gm, code, map[string]may{
+		"p2": {dead: true},
+	})
+}
+
+func TestPermissionsOfMutedPlayer(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger})
+	if err := gm.SetMuted(code, "p1", "p5", true); err != nil {
+		t.Fatalf("mute: %v", err)
+	}
+
+	wantPermissions(t, gm, code, map[string]may{
+		"p5": {},
+		"p6": {chat: true},
+	})
+
+	// A muted player still votes
+	nextPhase(t, gm, code)
+	wantPermissions(t, gm, code, map[string]may{
+		"p5": {vote: true},
+	})
+}
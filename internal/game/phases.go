@@ -0,0 +1,298 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Why a room entered its current phase, kept in GameRoom.PhaseReason
+const (
+	ReasonGameStarted   = "game_started"
+	ReasonNightResolved = "night_resolved"
+	ReasonDiscussionEnd = "discussion_over"
+	ReasonAccused       = "accused"
+	ReasonDefenseEnd    = "defense_over"
+	ReasonVotesCounted  = "votes_counted"
+	ReasonGameOver      = "game_over"
+	ReasonForced        = "forced"
+	ReasonRepaired      = "repaired"
+	ReasonNightTimedOut = "night_timed_out"
+	ReasonAborted       = "aborted"
+	ReasonForfeit       = "forfeit"
+	ReasonWithdrawal    = "withdrawal"
+	ReasonRematch       = "rematch"
+)
+
+// phaseState is one phase of the game: where it may lead, and what happens
+// on the way in and out
+type phaseState struct {
+	next  []models.GamePhase
+	enter func(gm *GameManager, room *models.GameRoom, now time.Time)
+	exit  func(room *models.GameRoom)
+}
+
+// phaseMachine is the whole game flow. Every phase change goes through
+// transitionLocked, which refuses anything not listed here.
+var phaseMachine = map[models.GamePhase]phaseState{
+	models.PhaseWaiting: {
+		next:  []models.GamePhase{models.PhaseDay},
+		enter: enterWaiting,
+		exit:  exitWaiting,
+	},
+	models.PhaseDay: {
+		next:  []models.GamePhase{models.PhaseDefense, models.PhaseVoting, models.PhaseEnded},
+		enter: enterDay,
+		exit:  exitDay,
+	},
+	models.PhaseDefense: {
+		next:  []models.GamePhase{models.PhaseVoting, models.PhaseEnded},
+		enter: enterDefense,
+	},
+	models.PhaseVoting: {
+		next:  []models.GamePhase{models.PhaseNight, models.PhaseEnded},
+		enter: enterVoting,
+		exit:  exitVoting,
+	},
+	models.PhaseNight: {
+		next:  []models.GamePhase{models.PhaseDay, models.PhaseEnded},
+		enter: enterNight,
+		exit:  exitNight,
+	},
+	models.PhaseEnded: {
+		next:  []models.GamePhase{models.PhaseWaiting},
+		enter: enterEnded,
+	},
+}
+
+// canTransition reports whether the machine allows moving from one phase to another
+func canTransition(from, to models.GamePhase) bool {
+	for _, phase := range phaseMachine[from].next {
+		if phase == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionLocked moves the room to another phase: it runs the old phase's
+// exit action and the new one's entry action, then tells observers. Starting
+// the game is reported as OnGameStarted and ending it as OnGameEnded; every
+// other change as OnPhaseChanged.
+func (gm *GameManager) transitionLocked(room *models.GameRoom, to models.GamePhase, reason string) error {
+	from := room.Phase
+	if !canTransition(from, to) {
+		return &errs.Error{
+			Code:    "ILLEGAL_TRANSITION",
+			Params:  map[string]interface{}{"from": from, "to": to},
+			Message: fmt.Sprintf("cannot go from %s to %s", from, to),
+		}
+	}
+
+	if exit := phaseMachine[from].exit; exit != nil {
+		exit(room)
+	}
+
+	now := time.Now()
+	room.Phase = to
+	room.PhaseReason = reason
+	startedAt := now // enterDay keeps &now, so don't share it
+	room.PhaseStartedAt = models.TimestampPtr(startedAt)
+	room.ReadyToVote = nil // signals only count for the day they were given in
+	room.LastActivityAt = now
+
+	if enter := phaseMachine[to].enter; enter != nil {
+		enter(gm, room, now)
+	}
+	if to != models.PhaseWaiting && to != models.PhaseEnded {
+		checkQuorumLocked(room, now)
+	}
+
+	switch {
+	case to == models.PhaseEnded:
+		gm.notify(room, func(o RoomObserver) { o.OnGameEnded(room, room.WinningTeam) })
+	case from == models.PhaseWaiting:
+		gm.notify(room, func(o RoomObserver) { o.OnGameStarted(room) })
+	default:
+		gm.notify(room, func(o RoomObserver) { o.OnPhaseChanged(room, from) })
+	}
+
+	return nil
+}
+
+// enterWaiting turns a finished game back into a lobby: everyone alive,
+// roles and the last game's state cleared
+func enterWaiting(gm *GameManager, room *models.GameRoom, now time.Time) {
+	room.StartedAt = nil
+	room.Round = 0
+	room.NightNumber = 0
+	room.PhaseEndTime = nil
+	room.DayStartedAt = nil
+	room.VoteResults = nil
+	room.HunterProtection = ""
+	room.TigerTarget = ""
+	room.ShamanVision = ""
+	room.KilledTonight = ""
+	room.CursedPlayer = ""
+	room.NightActionsCompleted = nil
+	room.NightActionsRequired = 0
+	room.NightActionOrder = nil
+	room.WaitingHunterShoot = false
+	room.DeadHunterID = ""
+	room.HunterShotEndsAt = nil
+	room.HunterSelection = ""
+	room.WinningTeam = ""
+	room.TigersRemaining = nil
+	room.Nominations = nil
+	room.Nominees = nil
+	room.NightAcknowledged = nil
+	room.TigerTeam = nil
+	room.TurnToken = ""
+	room.TurnEndTime = nil
+	room.NightTraces = nil
+	room.VotesAgainst = nil
+	room.VoteHistory = nil
+	room.VoteOutcomes = nil
+	room.LastVoteOutcome = nil
+	room.NightCeilingAt = nil
+	room.AbortVote = nil
+	room.QuorumPause = nil
+	room.PendingPrompts = nil
+	room.SlowModeSeconds = 0
+	room.LastChatAt = nil
+	room.ConcealedDeaths = nil
+	room.BodyRevealAt = nil
+	room.RevealedDeaths = nil
+
+	for _, player := range room.Players {
+		room.SetRole(player, "")
+		player.Seat = 0
+		room.SetAlive(player, true)
+		player.DeathCause = ""
+		player.IsReady = false
+		player.IsCursed = false
+		player.LastProtected = ""
+		player.HasActedThisNight = false
+		player.VotedFor = ""
+		player.ActionHistory = nil
+		player.HandledRequests = nil
+		player.Abilities = nil
+	}
+}
+
+// exitWaiting turns away everyone still waiting to be let in
+func exitWaiting(room *models.GameRoom) {
+	for _, request := range room.JoinRequests {
+		if request.Status == models.JoinPending {
+			request.Status = models.JoinDenied
+			request.Reason = "game_started"
+		}
+	}
+}
+
+// enterDay starts a new round with a 2-minute discussion
+func enterDay(gm *GameManager, room *models.GameRoom, now time.Time) {
+	endTime := now.Add(2 * time.Minute)
+	room.PhaseEndTime = models.TimestampPtr(endTime)
+	room.DayStartedAt = models.TimestampPtr(now)
+	room.Round++ // Increment round when day starts
+
+	// Reset night actions tracking
+	for _, player := range room.Players {
+		player.HasActedThisNight = false
+	}
+	room.NightActionsCompleted = make(map[string]bool)
+
+	// The count only moves at dawn, so a death during the day is not
+	// confirmed as a tiger's the moment it happens
+	tigers := livingTigersLocked(room)
+	room.TigersRemaining = &tigers
+}
+
+// exitDay finds any body still concealed; the vote and the night see everyone as they are
+func exitDay(room *models.GameRoom) {
+	revealBodiesLocked(room)
+}
+
+// enterDefense gives the nominees 30 seconds to defend themselves
+func enterDefense(gm *GameManager, room *models.GameRoom, now time.Time) {
+	endTime := now.Add(30 * time.Second)
+	room.PhaseEndTime = models.TimestampPtr(endTime)
+}
+
+// enterVoting opens a 2-minute vote with a fresh tally
+func enterVoting(gm *GameManager, room *models.GameRoom, now time.Time) {
+	endTime := now.Add(2 * time.Minute)
+	room.PhaseEndTime = models.TimestampPtr(endTime)
+
+	// Reset vote tracking
+	room.VoteResults = make(map[string]int)
+	room.VoteExtendedFor = nil
+	for _, player := range room.Players {
+		player.VotedFor = ""
+	}
+}
+
+// exitVoting drops the day's accusations once the vote is over
+func exitVoting(room *models.GameRoom) {
+	room.Nominations = nil
+	room.Nominees = nil
+}
+
+// enterNight resets the night's tracking and sets up the turn order
+func enterNight(gm *GameManager, room *models.GameRoom, now time.Time) {
+	room.PhaseEndTime = nil
+	if room.Settings.NightSleepConfirmation {
+		// Everyone waits on the slowest sleeper, so the night needs a deadline
+		endTime := now.Add(nightSleepTimeout)
+		room.PhaseEndTime = models.TimestampPtr(endTime)
+	}
+
+	for _, player := range room.Players {
+		player.HasActedThisNight = false
+	}
+	room.NightActionsCompleted = make(map[string]bool)
+	room.TigerTeam = nil
+	room.NightNumber++
+	resetNightAcknowledgementsLocked(room)
+
+	room.NightCeilingAt = nil
+	if gm.NightCeiling > 0 {
+		ceiling := now.Add(gm.NightCeiling)
+		room.NightCeilingAt = models.TimestampPtr(ceiling)
+	}
+
+	room.NightModifier = ""
+	if modifier := drawNightModifier(room); modifier != nil {
+		room.NightModifier = modifier.Key()
+		announcement := modifier.Announcement()
+		gm.systemNoticeLocked(room, announcement.Text, announcement.Key, now)
+	}
+
+	// Set up night action order: Hunter -> Tiger/AlphaTiger -> Shaman
+	room.NightActionOrder = gm.getNightActionOrder(room)
+	if len(room.NightActionOrder) > 0 {
+		setNightRoleLocked(room, room.NightActionOrder[0])
+	} else {
+		setNightRoleLocked(room, "")
+	}
+	skipBotTurnsLocked(room)
+}
+
+// exitNight cancels the night's ceiling and any turn a forfeit cut short
+func exitNight(room *models.GameRoom) {
+	room.NightCeilingAt = nil
+	room.NightModifier = ""
+	setNightRoleLocked(room, "")
+}
+
+// enterEnded stops the clock and drops any pause or unanswered prompt; the
+// winner is set before the transition
+func enterEnded(gm *GameManager, room *models.GameRoom, now time.Time) {
+	room.PhaseEndTime = nil
+	room.AbortVote = nil
+	room.QuorumPause = nil
+	room.PendingPrompts = nil
+}
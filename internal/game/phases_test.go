@@ -0,0 +1,135 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestIllegalTransitionIsRejected(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 5, nil)
+
+	var err error
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		err = gm.transitionLocked(room, models.PhaseVoting, ReasonForced)
+	})
+
+	var coded *errs.Error
+	if !errors.As(err, &coded) || coded.Code != "ILLEGAL_TRANSITION" {
+		t.Fatalf("waiting -> voting: err = %v, want ILLEGAL_TRANSITION", err)
+	}
+	if coded.Params["from"] != models.PhaseWaiting || coded.Params["to"] != models.PhaseVoting {
+		t.Errorf("params = %v", coded.Params)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseWaiting {
+		t.Errorf("phase = %s after a refused transition", phase)
+	}
+}
+
+func TestPhaseMachineIsClosed(t *testing.T) {
+	for from, state := range phaseMachine {
+		for _, to := range state.next {
+			if _, ok := phaseMachine[to]; !ok {
+				t.Errorf("%s leads to %s, which is not a phase", from, to)
+			}
+		}
+		if from != models.PhaseWaiting && from != models.PhaseEnded && !canTransition(from, models.PhaseEnded) {
+			t.Errorf("a game in %s cannot end", from)
+		}
+	}
+	if canTransition(models.PhaseNight, models.PhaseVoting) || canTransition(models.PhaseEnded, models.PhaseDay) {
+		t.Error("the machine allows skipping a phase")
+	}
+}
+
+// simulateGame plays the started game to its end with random choices and
+// returns how many phases it went through
+func simulateGame(t testing.TB, gm *GameManager, code models.RoomCode, rng *rand.Rand) int {
+	t.Helper()
+
+	pick := func(chooserID string) string {
+		view, _ := gm.RoomView(code, "")
+		var candidates []string
+		for _, player := range view.LivingPlayers() {
+			if player.ID != chooserID {
+				candidates = append(candidates, player.ID)
+			}
+		}
+		if len(candidates) == 0 {
+			return ""
+		}
+		return candidates[rng.Intn(len(candidates))]
+	}
+
+	phases := 0
+	for ; phaseOf(t, gm, code) != models.PhaseEnded; phases++ {
+		if phases > 200 {
+			t.Fatal("the game did not finish")
+		}
+
+		switch phaseOf(t, gm, code) {
+		case models.PhaseNight:
+			actions := make(map[string]string)
+			view, _ := gm.RoomView(code, "")
+			for _, player := range view.LivingPlayers() {
+				actions[player.ID] = pick(player.ID)
+			}
+			withRoom(t, gm, code, func(room *models.GameRoom) {
+				// The tigers leave each other alone and the hunter never
+				// protects the same player twice; anything else is fair
+				for id, target := range actions {
+					actor, victim := room.Players[id], room.Players[target]
+					if isTigerTeam(actor.Role) && isTigerTeam(victim.Role) || actor.LastProtected == target {
+						delete(actions, id)
+					}
+				}
+			})
+			playTurns(t, gm, code, actions)
+			nextPhase(t, gm, code)
+		case models.PhaseVoting:
+			view, _ := gm.RoomView(code, "")
+			for _, voter := range view.LivingPlayers() {
+				if err := gm.Vote(code, voter.ID, pick(voter.ID)); err != nil {
+					t.Fatalf("%s votes: %v", voter.ID, err)
+				}
+			}
+			nextPhase(t, gm, code)
+		default:
+			nextPhase(t, gm, code)
+		}
+
+		if view, _ := gm.RoomView(code, ""); view.WaitingHunterShoot {
+			if _, err := gm.HunterShoot(code, view.DeadHunterID, pick(view.DeadHunterID), true); err != nil {
+				t.Fatalf("hunter shoots: %v", err)
+			}
+		}
+	}
+	return phases
+}
+
+func TestSimulatedGamesFinish(t *testing.T) {
+	for _, players := range []int{5, 7, 11, 16} {
+		for seed := int64(1); seed <= 5; seed++ {
+			t.Run(fmt.Sprintf("%d players seed %d", players, seed), func(t *testing.T) {
+				gm := NewGameManager()
+				gm.Seed = func() int64 { return seed }
+				code := newTestRoom(t, gm, players, nil)
+				if err := gm.StartGame(code, "p1"); err != nil {
+					t.Fatalf("start game: %v", err)
+				}
+
+				simulateGame(t, gm, code, rand.New(rand.NewSource(seed)))
+
+				view, _ := gm.RoomView(code, "")
+				if view.WinningTeam != "human" && view.WinningTeam != "tiger" {
+					t.Errorf("winner = %q", view.WinningTeam)
+				}
+			})
+		}
+	}
+}
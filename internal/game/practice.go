@@ -0,0 +1,125 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// ErrNotPracticeRoom is returned for a night preview outside practice mode
+var ErrNotPracticeRoom = &errs.Error{Code: "NOT_PRACTICE_ROOM", Message: "night preview is only available in practice rooms"}
+
+// NightActions is a hypothetical set of night choices for a practice preview
+type NightActions struct {
+	TigerTarget      string `json:"tigerTarget"`
+	HunterProtection string `json:"hunterProtection"`
+	ShamanVision     string `json:"shamanVision"`
+}
+
+// PreviewNight returns what the night would resolve to with the given actions,
+// without changing the room. Only the host of a practice room may use it.
+func (gm *GameManager) PreviewNight(code models.RoomCode, playerID string, actions NightActions) (*NightResult, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	if !room.Settings.PracticeMode {
+		return nil, ErrNotPracticeRoom
+	}
+
+	if room.HostID != playerID {
+		return nil, hostOnlyError("preview the night")
+	}
+
+	for _, targetID := range []string{actions.TigerTarget, actions.HunterProtection, actions.ShamanVision} {
+		if targetID != "" && room.Players[targetID] == nil {
+			return nil, ErrInvalidActionTarget
+		}
+	}
+
+	preview := cloneRoomPlayers(room)
+	preview.TigerTarget = actions.TigerTarget
+	preview.HunterProtection = actions.HunterProtection
+	preview.ShamanVision = actions.ShamanVision
+
+	return resolveNight(preview, func(victim *models.Player, cause string) {
+		preview.SetAlive(victim, false)
+		victim.DeathCause = cause
+	}), nil
+}
+
+// cloneRoomPlayers copies the room and its players so the copy can be mutated freely
+func cloneRoomPlayers(room *models.GameRoom) *models.GameRoom {
+	clone := *room
+	clone.Players = make(map[string]*models.Player, len(room.Players))
+	for id, player := range room.Players {
+		p := *player
+		clone.Players[id] = &p
+	}
+	clone.ReindexLiving()
+	return &clone
+}
+
+// fillBotsLocked tops a practice room up to the minimum player count with bots
+func fillBotsLocked(room *models.GameRoom) {
+	for i := 1; len(room.Players) < minPlayers; i++ {
+		botID := "bot-" + uuid.New().String()
+		room.AddPlayer(&models.Player{
+			ID:        botID,
+			Username:  fmt.Sprintf("Bot %d", i),
+			IsAlive:   true,
+			IsReady:   true,
+			IsBot:     true,
+			ColorSlot: firstFreeColorSlotLocked(room),
+			RoomCode:  room.Code,
+			JoinedAt:  models.NewTimestamp(time.Now()),
+		})
+	}
+}
+
+// skipBotTurnsLocked passes over night turns that only bots would take.
+// It reports whether the night has run out of turns.
+func skipBotTurnsLocked(room *models.GameRoom) bool {
+	for room.CurrentNightRole != "" && onlyBotsHoldRole(room, room.CurrentNightRole) {
+		for _, player := range room.LivingPlayers() {
+			if nightTurnRole(player.Role) == room.CurrentNightRole {
+				recordActionLocked(room, player, models.ActionSkip, nil)
+				markNightActionCompleteLocked(room, player)
+			}
+		}
+		setNightRoleLocked(room, nextNightRole(room))
+	}
+	return room.CurrentNightRole == ""
+}
+
+// onlyBotsHoldRole reports whether every living holder of the role is a bot
+func onlyBotsHoldRole(room *models.GameRoom, role models.Role) bool {
+	held := false
+	for _, player := range room.LivingPlayers() {
+		if nightTurnRole(player.Role) != role {
+			continue
+		}
+		if !player.IsBot {
+			return false
+		}
+		held = true
+	}
+	return held
+}
+
+// nextNightRole returns the role after the current one, or "" at the end of the order
+func nextNightRole(room *models.GameRoom) models.Role {
+	for i, role := range room.NightActionOrder {
+		if role == room.CurrentNightRole && i < len(room.NightActionOrder)-1 {
+			return room.NightActionOrder[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// practiceGame is a started practice room with one human, p1, who is the
+// hunter; the first bot is the tiger and the rest are villagers
+func practiceGame(t *testing.T) (*GameManager, models.RoomCode, []string) {
+	t.Helper()
+
+	gm := NewGameManager()
+	gm.DebugValidate = true
+	settings := models.DefaultRoomSettings()
+	settings.PracticeMode = true
+	settings.MinDiscussionSeconds = 0
+	settings.QuorumFraction = 0
+	room, err := gm.CreateRoom("p1", "Player1", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	if err := gm.StartGame(room.Code, "p1"); err != nil {
+		t.Fatalf("start practice game: %v", err)
+	}
+
+	var bots []string
+	withRoom(t, gm, room.Code, func(room *models.GameRoom) {
+		for _, player := range room.Players {
+			if player.IsBot {
+				bots = append(bots, player.ID)
+			}
+		}
+		for _, player := range room.Players {
+			role := models.RoleVillager
+			switch player.ID {
+			case "p1":
+				role = models.RoleHunter
+			case bots[0]:
+				role = models.RoleTiger
+			}
+			room.SetRole(player, role)
+			player.Abilities = roleAbilities(role, room.Settings)
+		}
+	})
+	return gm, room.Code, bots
+}
+
+func TestPracticeRoomFillsWithBots(t *testing.T) {
+	gm, code, bots := practiceGame(t)
+
+	if len(bots) != 4 {
+		t.Fatalf("%d bots joined, want 4 beside the one human", len(bots))
+	}
+	view, _ := gm.RoomView(code, "p1")
+	if len(view.Players) != 5 || view.Players["p1"].IsBot {
+		t.Errorf("players = %d, p1 a bot %v; want the host and four bots", len(view.Players), view.Players["p1"].IsBot)
+	}
+	for _, id := range bots {
+		if bot := view.Players[id]; !bot.IsReady || !bot.IsAlive {
+			t.Errorf("bot %s is ready %v, alive %v", id, bot.IsReady, bot.IsAlive)
+		}
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Errorf("phase = %s, want the first day", phase)
+	}
+}
+
+func TestPreviewNightLeavesTheRoomUntouched(t *testing.T) {
+	gm, code, bots := practiceGame(t)
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, bots[3])
+
+	record := func() Fixture {
+		data, err := gm.RecordFixture(code)
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		fixture.RecordedAt = models.Timestamp{}
+		return fixture
+	}
+	before := record()
+
+	// A kill the hunter does not block, then one they do
+	result, err := gm.PreviewNight(code, "p1", NightActions{TigerTarget: bots[1], HunterProtection: bots[2]})
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if len(result.Deaths) != 1 || result.Deaths[0].PlayerID != bots[1] {
+		t.Errorf("preview deaths = %+v, want %s", result.Deaths, bots[1])
+	}
+	result, err = gm.PreviewNight(code, "p1", NightActions{TigerTarget: bots[1], HunterProtection: bots[1]})
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if len(result.Deaths) != 0 {
+		t.Errorf("a protected preview killed %+v", result.Deaths)
+	}
+
+	if after := record(); !reflect.DeepEqual(before, after) {
+		t.Errorf("the preview changed the room:\nbefore %+v\nafter  %+v", before.Room, after.Room)
+	}
+	if !isAlive(gm, code, bots[1]) {
+		t.Error("the preview's victim died")
+	}
+	if failures := gm.ValidationFailures(); failures != 0 {
+		t.Errorf("%d validation failures", failures)
+	}
+}
+
+func TestPreviewNightOnlyForThePracticeHost(t *testing.T) {
+	gm, code, bots := practiceGame(t)
+	if _, err := gm.PreviewNight(code, bots[0], NightActions{TigerTarget: "p1"}); err == nil {
+		t.Error("a bot previewed the night")
+	}
+	if _, err := gm.PreviewNight(code, "p1", NightActions{TigerTarget: "nobody"}); !errors.Is(err, ErrInvalidActionTarget) {
+		t.Errorf("previewing an unknown target: %v, want %s", err, ErrInvalidActionTarget.Code)
+	}
+
+	plain := NewGameManager()
+	other := newTestRoom(t, plain, 6, nil)
+	if _, err := plain.PreviewNight(other, "p1", NightActions{}); !errors.Is(err, ErrNotPracticeRoom) {
+		t.Errorf("previewing outside practice mode: %v, want %s", err, ErrNotPracticeRoom.Code)
+	}
+}
@@ -0,0 +1,235 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// HunterPrompt asks a dead hunter to take their shot
+type HunterPrompt struct {
+	Targets []string `json:"targets"`
+	// NeedsConfirmation means a shot only selects its target until it is
+	// sent again with confirm
+	NeedsConfirmation bool   `json:"needsConfirmation,omitempty"`
+	Selected          string `json:"selected,omitempty"` // target awaiting confirmation
+	PromptDeadline
+}
+
+// AbortPrompt asks an eligible player whether to abandon the game
+type AbortPrompt struct {
+	models.AbortVote
+	PromptDeadline
+}
+
+// Prompt is a pending prompt as it stands now, ready to send again
+type Prompt struct {
+	Type    string
+	Payload interface{}
+}
+
+// PromptDeadline is when a prompt must be answered by and how many seconds
+// that left when the server sent it. Clients count down from the remainder,
+// so one whose clock is off still shows the right time.
+type PromptDeadline struct {
+	Deadline         *models.Timestamp `json:"deadline,omitempty"`
+	RemainingSeconds *int              `json:"remainingSeconds,omitempty"`
+}
+
+// promptDeadlineLocked stamps a prompt of the given type from the room timer
+// that governs it. A paused game's clocks stand still, so a turn or a shot
+// is counted from when the pause began; the abort vote's own clock runs on.
+func promptDeadlineLocked(room *models.GameRoom, eventType string, now time.Time) PromptDeadline {
+	var deadline *models.Timestamp
+	switch eventType {
+	case models.EventYourTurn:
+		deadline = room.TurnEndTime
+	case models.EventHunterPrompt:
+		deadline = room.HunterShotEndsAt
+	case models.EventAbortRequested:
+		if room.AbortVote != nil {
+			endsAt := room.AbortVote.EndsAt
+			deadline = &endsAt
+		}
+	}
+	if deadline == nil {
+		return PromptDeadline{}
+	}
+
+	if eventType != models.EventAbortRequested {
+		if stopped, paused := pauseStartedLocked(room); paused {
+			now = stopped
+		}
+	}
+	remaining := int(math.Ceil(deadline.Sub(now).Seconds()))
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	at := *deadline
+	return PromptDeadline{Deadline: &at, RemainingSeconds: &remaining}
+}
+
+// pauseStartedLocked reports when the game's clocks stopped, if they have
+func pauseStartedLocked(room *models.GameRoom) (time.Time, bool) {
+	var stopped time.Time
+	if room.QuorumPause != nil {
+		stopped = room.QuorumPause.LostAt.Time
+	}
+	if vote := room.AbortVote; vote != nil && (stopped.IsZero() || vote.StartedAt.Before(stopped)) {
+		stopped = vote.StartedAt
+	}
+	return stopped, !stopped.IsZero()
+}
+
+// issuePromptLocked records that the players were sent a prompt, replacing
+// any earlier prompt of the same type they had not answered
+func issuePromptLocked(room *models.GameRoom, playerIDs []string, prompt models.PendingPrompt) {
+	if room.PendingPrompts == nil {
+		room.PendingPrompts = make(map[string][]models.PendingPrompt)
+	}
+	for _, playerID := range playerIDs {
+		answerPromptLocked(room, playerID, prompt.Type)
+		room.PendingPrompts[playerID] = append(room.PendingPrompts[playerID], prompt)
+	}
+}
+
+// answerPromptLocked forgets the player's prompt of the given type
+func answerPromptLocked(room *models.GameRoom, playerID, eventType string) {
+	prompts := room.PendingPrompts[playerID]
+	kept := prompts[:0]
+	for _, prompt := range prompts {
+		if prompt.Type != eventType {
+			kept = append(kept, prompt)
+		}
+	}
+	if len(kept) == 0 {
+		delete(room.PendingPrompts, playerID)
+		return
+	}
+	room.PendingPrompts[playerID] = kept
+}
+
+// withdrawPromptsLocked forgets every player's prompt of the given type
+func withdrawPromptsLocked(room *models.GameRoom, eventType string) {
+	for playerID := range room.PendingPrompts {
+		answerPromptLocked(room, playerID, eventType)
+	}
+}
+
+// shiftPromptsLocked moves prompt deadlines along with the game's clocks
+func shiftPromptsLocked(room *models.GameRoom, paused time.Duration) {
+	for _, prompts := range room.PendingPrompts {
+		for i := range prompts {
+			if prompts[i].Deadline != nil {
+				prompts[i].Deadline = models.TimestampPtr(prompts[i].Deadline.Add(paused))
+			}
+		}
+	}
+}
+
+// PendingPrompts returns the prompts the player still has to answer, rebuilt
+// from the room so their deadlines and remainders are current. Prompts the
+// game has moved past, or whose deadline has passed, are dropped on the way.
+func (gm *GameManager) PendingPrompts(code models.RoomCode, playerID string) []Prompt {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil
+	}
+
+	now := time.Now()
+	var live []Prompt
+	for _, pending := range append([]models.PendingPrompt(nil), room.PendingPrompts[playerID]...) {
+		if pending.Deadline != nil && !now.Before(pending.Deadline.Time) {
+			answerPromptLocked(room, playerID, pending.Type)
+			continue
+		}
+		payload, ok := promptPayloadLocked(room, playerID, pending, now)
+		if !ok {
+			answerPromptLocked(room, playerID, pending.Type)
+			continue
+		}
+		live = append(live, Prompt{Type: pending.Type, Payload: payload})
+	}
+	return live
+}
+
+// promptPayloadLocked rebuilds a prompt from the room's current state. It
+// reports false once the prompt was answered or overtaken.
+func promptPayloadLocked(room *models.GameRoom, playerID string, pending models.PendingPrompt, now time.Time) (interface{}, bool) {
+	player := room.Players[playerID]
+	if player == nil {
+		return nil, false
+	}
+
+	switch pending.Type {
+	case models.EventYourTurn:
+		if room.Phase != models.PhaseNight || room.TurnToken != pending.Context ||
+			!holdsTurnLocked(room, player) || room.NightActionsCompleted[playerID] {
+			return nil, false
+		}
+		return turnPromptLocked(room, player, now), true
+
+	case models.EventHunterPrompt:
+		if !room.WaitingHunterShoot || room.DeadHunterID != playerID {
+			return nil, false
+		}
+		return hunterPromptLocked(room, now), true
+
+	case models.EventAbortRequested:
+		vote := room.AbortVote
+		if vote == nil || !containsID(vote.Eligible, playerID) {
+			return nil, false
+		}
+		if _, answered := vote.Votes[playerID]; answered {
+			return nil, false
+		}
+		return abortPromptLocked(room, now), true
+	}
+	return nil, false
+}
+
+// HunterPromptFor returns the prompt for the dead hunter waiting to shoot
+// and their ID, if there is one
+func (gm *GameManager) HunterPromptFor(code models.RoomCode) (HunterPrompt, string, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists || !room.WaitingHunterShoot {
+		return HunterPrompt{}, "", false
+	}
+	return hunterPromptLocked(room, time.Now()), room.DeadHunterID, true
+}
+
+// hunterPromptLocked lists whom the dead hunter may shoot
+func hunterPromptLocked(room *models.GameRoom, now time.Time) HunterPrompt {
+	prompt := HunterPrompt{
+		Targets:           []string{},
+		NeedsConfirmation: room.Settings.HunterShotNeedsConfirmation,
+		Selected:          room.HunterSelection,
+		PromptDeadline:    promptDeadlineLocked(room, models.EventHunterPrompt, now),
+	}
+	hunter := room.Players[room.DeadHunterID]
+	for _, player := range room.LivingPlayers() {
+		if hunter != nil && hunterTargetLocked(room, hunter, player.ID) != nil {
+			continue
+		}
+		prompt.Targets = append(prompt.Targets, player.ID)
+	}
+	return prompt
+}
+
+// awaitHunterShotLocked holds the game for the dead hunter's shot
+func awaitHunterShotLocked(room *models.GameRoom, hunterID string) {
+	endsAt := time.Now().Add(hunterShotWindow)
+	room.WaitingHunterShoot = true
+	room.DeadHunterID = hunterID
+	room.HunterSelection = ""
+	room.HunterShotEndsAt = models.TimestampPtr(endsAt)
+	issuePromptLocked(room, []string{hunterID}, models.PendingPrompt{Type: models.EventHunterPrompt, Deadline: models.TimestampPtr(endsAt)})
+}
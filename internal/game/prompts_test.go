@@ -0,0 +1,212 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// reconnect drops the player's connection and opens a new one
+func reconnect(gm *GameManager, code models.RoomCode, playerID string) {
+	gm.SetConnected(code, playerID, false)
+	gm.SetConnected(code, playerID, true)
+}
+
+// onlyPrompt returns the player's one pending prompt, failing the test
+// unless it is of the given type
+func onlyPrompt(t *testing.T, gm *GameManager, code models.RoomCode, playerID, eventType string) Prompt {
+	t.Helper()
+
+	prompts := gm.PendingPrompts(code, playerID)
+	if len(prompts) != 1 || prompts[0].Type != eventType {
+		t.Fatalf("%s's pending prompts = %+v, want one %s", playerID, prompts, eventType)
+	}
+	return prompts[0]
+}
+
+// turnHolder returns the one player holding the open night turn
+func turnHolder(t *testing.T, gm *GameManager, code models.RoomCode) (string, TurnPrompt) {
+	t.Helper()
+
+	prompts, _ := gm.CurrentTurnPrompts(code)
+	if len(prompts) != 1 {
+		t.Fatalf("the turn belongs to %d players", len(prompts))
+	}
+	for id, prompt := range prompts {
+		return id, prompt
+	}
+	panic("unreachable")
+}
+
+// wantRemaining fails the test unless the deadline leaves about want seconds
+func wantRemaining(t *testing.T, deadline PromptDeadline, want int) {
+	t.Helper()
+
+	if deadline.RemainingSeconds == nil {
+		t.Fatal("the prompt has no remainder")
+	}
+	if got := *deadline.RemainingSeconds; got < want-1 || got > want {
+		t.Errorf("remaining = %ds, want %ds", got, want)
+	}
+}
+
+func TestTurnPromptResentAfterReconnect(t *testing.T) {
+	gm, code := deathsGame(t)
+	holder, issued := turnHolder(t, gm, code)
+
+	// Ten seconds of the turn are left when the holder comes back
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.TurnEndTime = models.TimestampPtr(time.Now().Add(10 * time.Second))
+	})
+	reconnect(gm, code, holder)
+
+	prompt := onlyPrompt(t, gm, code, holder, models.EventYourTurn).Payload.(TurnPrompt)
+	if prompt.TurnToken != issued.TurnToken {
+		t.Errorf("resent turn token %q, want %q", prompt.TurnToken, issued.TurnToken)
+	}
+	wantRemaining(t, prompt.PromptDeadline, 10)
+	if others := gm.PendingPrompts(code, "p4"); len(others) != 0 {
+		t.Errorf("a villager has prompts %+v", others)
+	}
+
+	if err := gm.SkipNightAction(code, holder, prompt.TurnToken); err != nil {
+		t.Fatalf("skip: %v", err)
+	}
+	if left := gm.PendingPrompts(code, holder); len(left) != 0 {
+		t.Errorf("an answered turn is still pending: %+v", left)
+	}
+}
+
+func TestPausedTurnPromptCountsFromThePause(t *testing.T) {
+	gm, code := deathsGame(t)
+	holder, _ := turnHolder(t, gm, code)
+	now := time.Now()
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.TurnEndTime = models.TimestampPtr(now.Add(10 * time.Second))
+		room.QuorumPause = &models.QuorumPause{
+			LostAt:      models.NewTimestamp(now.Add(-5 * time.Second)),
+			GraceEndsAt: models.NewTimestamp(now.Add(time.Minute)),
+		}
+	})
+
+	prompt := onlyPrompt(t, gm, code, holder, models.EventYourTurn).Payload.(TurnPrompt)
+	wantRemaining(t, prompt.PromptDeadline, 15)
+}
+
+func TestHunterPromptResentAfterReconnect(t *testing.T) {
+	gm, code := deathsGame(t)
+	playNight(t, gm, code, map[string]string{"p1": "p2"})
+	if phase := phaseOf(t, gm, code); phase != models.PhaseNight {
+		t.Fatalf("the night moved on to %s without the hunter's shot", phase)
+	}
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.HunterShotEndsAt = models.TimestampPtr(time.Now().Add(5 * time.Second))
+	})
+	reconnect(gm, code, "p2")
+
+	prompt := onlyPrompt(t, gm, code, "p2", models.EventHunterPrompt).Payload.(HunterPrompt)
+	wantRemaining(t, prompt.PromptDeadline, 5)
+	if len(prompt.Targets) == 0 {
+		t.Error("the hunter was given no one to shoot")
+	}
+
+	if _, err := gm.HunterShoot(code, "p2", "p1", true); err != nil {
+		t.Fatalf("shoot: %v", err)
+	}
+	if left := gm.PendingPrompts(code, "p2"); len(left) != 0 {
+		t.Errorf("the shot is still pending: %+v", left)
+	}
+}
+
+func TestExpiredPromptIsDropped(t *testing.T) {
+	gm, code := deathsGame(t)
+	playNight(t, gm, code, map[string]string{"p1": "p2"})
+
+	past := models.TimestampPtr(time.Now().Add(-time.Second))
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.HunterShotEndsAt = past
+		room.PendingPrompts["p2"][0].Deadline = past
+	})
+	reconnect(gm, code, "p2")
+
+	if left := gm.PendingPrompts(code, "p2"); len(left) != 0 {
+		t.Errorf("an expired shot was resent: %+v", left)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if _, kept := room.PendingPrompts["p2"]; kept {
+			t.Error("the expired prompt is still recorded")
+		}
+	})
+}
+
+func TestAbortPromptResentAfterReconnect(t *testing.T) {
+	gm, code := quorumGame(t)
+	if _, _, err := gm.RequestAbort(code, "p1"); err != nil {
+		t.Fatalf("request abort: %v", err)
+	}
+	if left := gm.PendingPrompts(code, "p1"); len(left) != 0 {
+		t.Errorf("the host, whose request is their answer, has prompts %+v", left)
+	}
+
+	reconnect(gm, code, "p2")
+	prompt := onlyPrompt(t, gm, code, "p2", models.EventAbortRequested).Payload.(AbortPrompt)
+	wantRemaining(t, prompt.PromptDeadline, int(abortVoteDuration/time.Second))
+	if prompt.Votes != nil {
+		t.Errorf("the resent abort prompt shows answers %v", prompt.Votes)
+	}
+
+	if _, err := gm.VoteAbort(code, "p2", false); err != nil {
+		t.Fatalf("answer: %v", err)
+	}
+	if left := gm.PendingPrompts(code, "p2"); len(left) != 0 {
+		t.Errorf("an answered abort prompt is still pending: %+v", left)
+	}
+	onlyPrompt(t, gm, code, "p3", models.EventAbortRequested)
+}
+
+func TestPromptDeadlineStamping(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *models.Timestamp { return models.TimestampPtr(now.Add(d)) }
+	paused := &models.QuorumPause{
+		LostAt:      models.NewTimestamp(now.Add(-4 * time.Second)),
+		GraceEndsAt: models.NewTimestamp(now.Add(time.Minute)),
+	}
+
+	for _, tc := range []struct {
+		name      string
+		eventType string
+		room      models.GameRoom
+		want      int // -1 for no deadline
+	}{
+		{"turn", models.EventYourTurn, models.GameRoom{TurnEndTime: at(10 * time.Second)}, 10},
+		{"part seconds round up", models.EventYourTurn, models.GameRoom{TurnEndTime: at(9200 * time.Millisecond)}, 10},
+		{"passed", models.EventYourTurn, models.GameRoom{TurnEndTime: at(-3 * time.Second)}, 0},
+		{"no turn clock", models.EventYourTurn, models.GameRoom{}, -1},
+		{"shot", models.EventHunterPrompt, models.GameRoom{HunterShotEndsAt: at(30 * time.Second)}, 30},
+		{"paused shot", models.EventHunterPrompt, models.GameRoom{HunterShotEndsAt: at(30 * time.Second), QuorumPause: paused}, 34},
+		{"abort runs through the pause", models.EventAbortRequested, models.GameRoom{
+			AbortVote:   &models.AbortVote{StartedAt: now.Add(-time.Second), EndsAt: models.NewTimestamp(now.Add(20 * time.Second))},
+			QuorumPause: paused,
+		}, 20},
+		{"no abort vote", models.EventAbortRequested, models.GameRoom{}, -1},
+		{"unstamped prompt", models.EventVotingComplete, models.GameRoom{TurnEndTime: at(time.Second)}, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deadline := promptDeadlineLocked(&tc.room, tc.eventType, now)
+			if tc.want < 0 {
+				if deadline.Deadline != nil || deadline.RemainingSeconds != nil {
+					t.Errorf("stamped %+v, want no deadline", deadline)
+				}
+				return
+			}
+			if deadline.Deadline == nil || deadline.RemainingSeconds == nil {
+				t.Fatalf("no deadline stamped")
+			}
+			if *deadline.RemainingSeconds != tc.want {
+				t.Errorf("remaining = %ds, want %ds", *deadline.RemainingSeconds, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// quorumGrace is how long a game waits for its players to come back
+const quorumGrace = 60 * time.Second
+
+var (
+	ErrNotAwaitingPlayers = &errs.Error{Code: "NOT_AWAITING_PLAYERS", Message: "the game is not waiting for players"}
+	ErrQuorumGraceRunning = &errs.Error{Code: "QUORUM_GRACE_RUNNING", Message: "the grace period has not run out yet"}
+)
+
+// quorumLocked counts the living players who are present (connected, or
+// bots) against how many the room's quorum needs
+func quorumLocked(room *models.GameRoom) (present, needed int, lost bool) {
+	fraction := room.Settings.QuorumFraction
+	if fraction <= 0 {
+		return 0, 0, false
+	}
+
+	present = len(room.LivingConnected())
+	needed = int(math.Ceil(fraction * float64(room.LivingCount())))
+	return present, needed, present < needed
+}
+
+// checkQuorumLocked pauses the game when too few living players are connected
+// as it enters a new phase
+func checkQuorumLocked(room *models.GameRoom, now time.Time) {
+	if room.QuorumPause != nil {
+		return
+	}
+	present, needed, lost := quorumLocked(room)
+	if !lost {
+		return
+	}
+	room.QuorumPause = &models.QuorumPause{
+		LostAt:      models.NewTimestamp(now),
+		GraceEndsAt: models.NewTimestamp(now.Add(quorumGrace)),
+		Connected:   present,
+		Needed:      needed,
+	}
+}
+
+// restoreQuorumLocked resumes a paused game once enough players are back,
+// with its clocks moved on by the time it stood still
+func restoreQuorumLocked(room *models.GameRoom, now time.Time) bool {
+	pause := room.QuorumPause
+	if pause == nil {
+		return false
+	}
+	present, _, lost := quorumLocked(room)
+	pause.Connected = present
+	if lost {
+		return false
+	}
+	room.QuorumPause = nil
+	shiftClocksLocked(room, now.Sub(pause.LostAt.Time))
+	return true
+}
+
+// QuorumPause returns the room's quorum pause, if it is paused for one
+func (gm *GameManager) QuorumPause(code models.RoomCode) (models.QuorumPause, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists || room.QuorumPause == nil {
+		return models.QuorumPause{}, false
+	}
+	return *room.QuorumPause, true
+}
+
+// ForfeitLostQuorum ends a game whose quorum did not come back within the
+// grace period. The team with more of its living players still connected
+// wins; a tie has no winner.
+func (gm *GameManager) ForfeitLostQuorum(code models.RoomCode) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	pause := room.QuorumPause
+	if pause == nil {
+		return ErrNotAwaitingPlayers
+	}
+
+	if time.Now().Before(pause.GraceEndsAt.Time) {
+		return ErrQuorumGraceRunning
+	}
+
+	humans, tigers := 0, 0
+	for _, player := range room.LivingConnected() {
+		if isTigerTeam(player.Role) {
+			tigers++
+		} else {
+			humans++
+		}
+	}
+
+	switch {
+	case humans > tigers:
+		room.WinningTeam = "human"
+	case tigers > humans:
+		room.WinningTeam = "tiger"
+	default:
+		room.WinningTeam = ""
+	}
+	return gm.transitionLocked(room, models.PhaseEnded, ReasonForfeit)
+}
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// quorumGame is a six-player game in its first day with everyone connected
+// and a quorum of half the living players
+func quorumGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.QuorumFraction = 0.5
+	})
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger, "p3": models.RoleHunter})
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+		gm.SetConnected(code, id, true)
+	}
+	return gm, code
+}
+
+// disconnect closes the players' only connections
+func disconnect(gm *GameManager, code models.RoomCode, ids ...string) {
+	for _, id := range ids {
+		gm.SetConnected(code, id, false)
+	}
+}
+
+func TestQuorumLostAtDayEnd(t *testing.T) {
+	gm, code := quorumGame(t)
+	disconnect(gm, code, "p3", "p4", "p5", "p6")
+
+	// Nothing happens until the next transition
+	if _, paused := gm.QuorumPause(code); paused {
+		t.Fatal("the game paused mid-phase")
+	}
+	nextPhase(t, gm, code)
+
+	pause, paused := gm.QuorumPause(code)
+	if !paused {
+		t.Fatal("the game went on with two of six players connected")
+	}
+	if pause.Connected != 2 || pause.Needed != 3 {
+		t.Errorf("pause counts %d of %d, want 2 of 3", pause.Connected, pause.Needed)
+	}
+	if _, err := gm.MoveToNextPhase(code); !errors.Is(err, ErrGamePaused) {
+		t.Errorf("move on while paused: err = %v, want ErrGamePaused", err)
+	}
+}
+
+func TestQuorumLostAtNightfall(t *testing.T) {
+	gm, code := quorumGame(t)
+	nextPhase(t, gm, code)
+	disconnect(gm, code, "p3", "p4", "p5")
+	voteOut(t, gm, code, "p6")
+
+	if phase := phaseOf(t, gm, code); phase != models.PhaseNight {
+		t.Fatalf("phase = %s, want night", phase)
+	}
+	if _, paused := gm.QuorumPause(code); !paused {
+		t.Error("the night went on with two of five players connected")
+	}
+}
+
+func TestQuorumRestoredResumesWithShiftedClock(t *testing.T) {
+	gm, code := quorumGame(t)
+	disconnect(gm, code, "p3", "p4", "p5", "p6")
+	nextPhase(t, gm, code)
+
+	var deadline time.Time
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.QuorumPause.LostAt = models.NewTimestamp(time.Now().Add(-10 * time.Second))
+		deadline = room.PhaseEndTime.Time
+	})
+
+	if resumed, _ := gm.SetConnected(code, "p3", true); !resumed {
+		t.Fatal("three of six connected did not restore the quorum")
+	}
+	if _, paused := gm.QuorumPause(code); paused {
+		t.Error("the game is still paused")
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if shift := room.PhaseEndTime.Sub(deadline); shift < 10*time.Second {
+			t.Errorf("the deadline moved %v, want at least the 10s spent paused", shift)
+		}
+	})
+	nextPhase(t, gm, code)
+}
+
+func TestQuorumForfeit(t *testing.T) {
+	tests := []struct {
+		name    string
+		offline []string
+		winner  string
+	}{
+		{name: "humans outnumber", offline: []string{"p2", "p4", "p5", "p6"}, winner: "human"},
+		{name: "tigers outnumber", offline: []string{"p1", "p3", "p4", "p5", "p6"}, winner: "tiger"},
+		{name: "tie", offline: []string{"p3", "p4", "p5", "p6"}, winner: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, code := quorumGame(t)
+			disconnect(gm, code, tt.offline...)
+			nextPhase(t, gm, code)
+
+			if err := gm.ForfeitLostQuorum(code); err == nil {
+				t.Fatal("forfeited before the grace period ran out")
+			}
+			withRoom(t, gm, code, func(room *models.GameRoom) {
+				room.QuorumPause.GraceEndsAt = models.NewTimestamp(time.Now().Add(-time.Second))
+			})
+			if err := gm.ForfeitLostQuorum(code); err != nil {
+				t.Fatalf("forfeit: %v", err)
+			}
+
+			withRoom(t, gm, code, func(room *models.GameRoom) {
+				if room.Phase != models.PhaseEnded || room.PhaseReason != ReasonForfeit {
+					t.Errorf("phase %s (%s), want a forfeit", room.Phase, room.PhaseReason)
+				}
+				if room.WinningTeam != tt.winner {
+					t.Errorf("winner = %q, want %q", room.WinningTeam, tt.winner)
+				}
+			})
+		})
+	}
+}
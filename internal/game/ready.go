@@ -0,0 +1,116 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+var (
+	ErrReadyToVoteDisabled = &errs.Error{Code: "READY_TO_VOTE_DISABLED", Message: "ready-to-vote is turned off in this room"}
+	ErrCannotCallVote      = &errs.Error{Code: "PLAYER_CANNOT_CALL_VOTE", Message: "only living players can call the vote"}
+)
+
+// ReadyStatus is where the village stands on ending the day early. It
+// carries counts only, so nobody is pressured by name.
+type ReadyStatus struct {
+	Ready  bool `json:"ready"`  // the caller's own signal
+	Count  int  `json:"count"`  // living, connected players who are ready
+	Needed int  `json:"needed"` // how many it takes to end the day
+	// DayEnded is set when this signal reached the threshold and the day ended
+	DayEnded bool `json:"dayEnded"`
+	// EndsAt is set when the threshold was reached before the minimum
+	// discussion time; the day then ends at that moment instead
+	EndsAt *models.Timestamp `json:"endsAt,omitempty"`
+}
+
+// ToggleReadyToVote flips the player's ready-to-vote signal. Once more than
+// the room's ReadyToVoteFraction of living, connected players are ready the
+// day ends as if the host had skipped it, or as soon as the minimum
+// discussion time allows.
+func (gm *GameManager) ToggleReadyToVote(code models.RoomCode, playerID string) (ReadyStatus, *NightResult, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return ReadyStatus{}, nil, gm.missingRoomLocked(code)
+	}
+
+	if room.Settings.ReadyToVoteFraction <= 0 {
+		return ReadyStatus{}, nil, ErrReadyToVoteDisabled
+	}
+
+	if room.Phase != models.PhaseDay {
+		return ReadyStatus{}, nil, ErrNotDayPhase
+	}
+
+	player := room.Players[playerID]
+	if player == nil || !player.IsAlive {
+		return ReadyStatus{}, nil, ErrCannotCallVote
+	}
+
+	if room.ReadyToVote == nil {
+		room.ReadyToVote = make(map[string]bool)
+	}
+	if room.ReadyToVote[playerID] {
+		delete(room.ReadyToVote, playerID)
+	} else {
+		room.ReadyToVote[playerID] = true
+	}
+
+	status := readyStatusLocked(room)
+	status.Ready = room.ReadyToVote[playerID]
+	if !status.Ready || status.Count < status.Needed {
+		return status, nil, nil
+	}
+
+	// The village is ready; the minimum discussion time still applies
+	now := time.Now()
+	if err := discussionGuardLocked(room, now); err != nil {
+		endsAt := room.DayStartedAt.Add(time.Duration(room.Settings.MinDiscussionSeconds) * time.Second)
+		if room.PhaseEndTime == nil || endsAt.Before(room.PhaseEndTime.Time) {
+			room.PhaseEndTime = models.TimestampPtr(endsAt)
+		}
+		status.EndsAt = room.PhaseEndTime
+		return status, nil, nil
+	}
+
+	nightResult, err := gm.moveToNextPhaseLocked(room)
+	if err != nil {
+		return status, nil, err
+	}
+	status.DayEnded = true
+
+	return status, nightResult, nil
+}
+
+// readyStatusLocked counts the ready signals of living, connected players
+func readyStatusLocked(room *models.GameRoom) ReadyStatus {
+	var status ReadyStatus
+	eligible := 0
+	for _, player := range room.LivingConnected() {
+		eligible++
+		if room.ReadyToVote[player.ID] {
+			status.Count++
+		}
+	}
+	status.Needed = readyThreshold(eligible, room.Settings.ReadyToVoteFraction)
+	return status
+}
+
+// readyThreshold is the smallest count strictly greater than fraction of
+// eligible players, capped at all of them. 0.5 means a strict majority.
+func readyThreshold(eligible int, fraction float64) int {
+	if eligible == 0 {
+		return 1
+	}
+
+	needed := int(math.Floor(float64(eligible)*fraction)) + 1
+	if needed > eligible {
+		needed = eligible
+	}
+	return needed
+}
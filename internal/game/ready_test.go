@@ -0,0 +1,129 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// readyGame is a game of six on its first day with everyone connected
+func readyGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+		gm.SetConnected(code, id, true)
+	}
+	return gm, code
+}
+
+// toggleReady flips the player's signal, failing on an error
+func toggleReady(t *testing.T, gm *GameManager, code models.RoomCode, playerID string) ReadyStatus {
+	t.Helper()
+
+	status, _, err := gm.ToggleReadyToVote(code, playerID)
+	if err != nil {
+		t.Fatalf("%s toggles ready: %v", playerID, err)
+	}
+	return status
+}
+
+func TestReadyThreshold(t *testing.T) {
+	tests := []struct {
+		eligible int
+		fraction float64
+		needed   int
+	}{
+		{0, 0.5, 1},
+		{1, 0.5, 1},
+		{2, 0.5, 2},
+		{3, 0.5, 2},
+		{4, 0.5, 3},
+		{5, 0.5, 3},
+		{6, 0.5, 4},
+		{7, 0.5, 4},
+		{6, 2.0 / 3, 5},
+		{9, 0.75, 7},
+		{5, 0.2, 2},
+		{5, 1, 5},
+	}
+	for _, tt := range tests {
+		if needed := readyThreshold(tt.eligible, tt.fraction); needed != tt.needed {
+			t.Errorf("%d eligible at %v: need %d, want %d", tt.eligible, tt.fraction, needed, tt.needed)
+		}
+	}
+}
+
+func TestReadyMajorityEndsTheDay(t *testing.T) {
+	gm, code := readyGame(t)
+
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if status := toggleReady(t, gm, code, id); status.DayEnded || status.Needed != 4 {
+			t.Fatalf("after %s: %+v, want 4 of 6 needed", id, status)
+		}
+	}
+	status := toggleReady(t, gm, code, "p4")
+	if !status.DayEnded || status.Count != 4 {
+		t.Fatalf("the fourth signal: %+v, want the day ended", status)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseVoting {
+		t.Errorf("phase = %s, want the vote", phase)
+	}
+}
+
+func TestReadyCountsOnlyThosePresent(t *testing.T) {
+	gm, code := readyGame(t)
+	gm.SetConnected(code, "p5", false)
+	gm.SetConnected(code, "p6", false)
+
+	toggleReady(t, gm, code, "p1")
+	toggleReady(t, gm, code, "p2")
+	if status := toggleReady(t, gm, code, "p3"); !status.DayEnded || status.Needed != 3 {
+		t.Errorf("three of four present: %+v, want the day ended", status)
+	}
+}
+
+func TestReadySignalWithdrawn(t *testing.T) {
+	gm, code := readyGame(t)
+
+	toggleReady(t, gm, code, "p1")
+	if status := toggleReady(t, gm, code, "p1"); status.Ready || status.Count != 0 {
+		t.Fatalf("a second signal: %+v, want it withdrawn", status)
+	}
+
+	// p3 changes their mind, so p4 makes three rather than four
+	toggleReady(t, gm, code, "p1")
+	toggleReady(t, gm, code, "p2")
+	toggleReady(t, gm, code, "p3")
+	toggleReady(t, gm, code, "p3")
+	if status := toggleReady(t, gm, code, "p4"); status.DayEnded || status.Count != 3 {
+		t.Errorf("after the withdrawal: %+v, want 3 ready and the day going on", status)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Errorf("phase = %s, want the day", phase)
+	}
+}
+
+func TestReadySignalsResetEachDay(t *testing.T) {
+	gm, code := readyGame(t)
+	toggleReady(t, gm, code, "p2")
+	toggleReady(t, gm, code, "p3")
+
+	// The host's skip still works as an override
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p6")
+	playNight(t, gm, code, nil)
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Fatalf("phase = %s, want the second day", phase)
+	}
+
+	if status := toggleReady(t, gm, code, "p2"); !status.Ready || status.Count != 1 {
+		t.Errorf("p2 on the new day: %+v, want only their own signal", status)
+	}
+	if _, _, err := gm.ToggleReadyToVote(code, "p6"); !errors.Is(err, ErrCannotCallVote) {
+		t.Errorf("the dead signal: %v, want %s", err, ErrCannotCallVote.Code)
+	}
+}
@@ -0,0 +1,181 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Recap is a special role's private summary of the night just resolved
+type Recap struct {
+	Role    models.Role `json:"role"`
+	Outcome string      `json:"outcome"`
+	Message string      `json:"message"`
+	// Visions is the shaman's running history, oldest first
+	Visions []VisionRecap `json:"visions,omitempty"`
+}
+
+// VisionRecap is one night of the shaman's visions
+type VisionRecap struct {
+	Round    int    `json:"round"`
+	Username string `json:"username"`
+	Result   string `json:"result"`
+	// Inspected marks a look at a dead player; Result is then their role
+	Inspected bool `json:"inspected,omitempty"`
+}
+
+// Recap outcomes
+const (
+	RecapProtectedAttacked = "protected_attacked"
+	RecapProtectedQuiet    = "protected_quiet"
+	RecapKillSucceeded     = "kill_succeeded"
+	RecapKillBlocked       = "kill_blocked"
+	RecapKillSpared        = "kill_spared"
+	RecapNoKill            = "no_kill"
+	RecapVision            = "vision"
+	RecapInspected         = "inspected"
+	RecapNoAction          = "no_action"
+	RecapPassed            = "passed"
+)
+
+// RecapBuilder turns a resolved night into one recap per living special
+// role. It reads the settled action records, so it runs after they are settled.
+type RecapBuilder struct {
+	Room   *models.GameRoom
+	Result *NightResult
+}
+
+// Build returns the recaps keyed by player ID
+func (b RecapBuilder) Build() map[string]Recap {
+	recaps := make(map[string]Recap)
+	for _, player := range b.Room.LivingPlayers() {
+		switch player.Role {
+		case models.RoleHunter:
+			recaps[player.ID] = b.hunter(player)
+		case models.RoleTiger, models.RoleAlphaTiger:
+			recaps[player.ID] = b.tiger(player)
+		case models.RoleShaman:
+			recaps[player.ID] = b.shaman(player)
+		}
+	}
+	return recaps
+}
+
+func (b RecapBuilder) hunter(player *models.Player) Recap {
+	recap := Recap{Role: player.Role, Outcome: RecapNoAction, Message: "You did not protect anyone tonight."}
+
+	if b.nightRecord(player, models.ActionPass) != nil {
+		recap.Outcome = RecapPassed
+		recap.Message = "You chose not to protect anyone tonight."
+		return recap
+	}
+
+	record := b.nightRecord(player, models.ActionProtect)
+	if record == nil {
+		return recap
+	}
+
+	if record.Outcome == "saved" {
+		recap.Outcome = RecapProtectedAttacked
+		recap.Message = fmt.Sprintf("%s was attacked tonight, and your protection saved them.", record.TargetUsername)
+	} else {
+		recap.Outcome = RecapProtectedQuiet
+		recap.Message = fmt.Sprintf("%s was not attacked tonight.", record.TargetUsername)
+	}
+	return recap
+}
+
+// tiger reports the team's kill, whoever chose it. A failed kill is only
+// ever "blocked", never why, unless the room's first-night rule stopped it.
+func (b RecapBuilder) tiger(player *models.Player) Recap {
+	recap := Recap{Role: player.Role}
+
+	team := b.Room.TigerTeam
+	killTarget := b.Room.Players[b.Room.TigerTarget]
+	if team != nil {
+		killTarget = b.Room.Players[team.KillTargetID]
+	}
+
+	switch {
+	case killTarget == nil && b.nightRecord(player, models.ActionPass) != nil:
+		recap.Outcome = RecapPassed
+		recap.Message = "You chose not to attack anyone tonight."
+	case killTarget == nil:
+		recap.Outcome = RecapNoKill
+		recap.Message = "Your team did not attack anyone tonight."
+	case b.Result.firstNightSpared:
+		recap.Outcome = RecapKillSpared
+		recap.Message = fmt.Sprintf("No one dies on the first night, so your attack on %s failed.", killTarget.Username)
+	case b.Result.died(killTarget.ID):
+		recap.Outcome = RecapKillSucceeded
+		recap.Message = fmt.Sprintf("Your attack on %s succeeded.", killTarget.Username)
+	default:
+		recap.Outcome = RecapKillBlocked
+		recap.Message = fmt.Sprintf("Your attack on %s was blocked.", killTarget.Username)
+	}
+
+	// A blood moon's second victim
+	if team != nil && b.Result.attacked(team.SecondKillTargetID) {
+		if second := b.Room.Players[team.SecondKillTargetID]; second != nil {
+			if b.Result.died(second.ID) {
+				recap.Message += fmt.Sprintf(" Your attack on %s succeeded too.", second.Username)
+			} else {
+				recap.Message += fmt.Sprintf(" Your attack on %s was blocked.", second.Username)
+			}
+		}
+	}
+
+	if curse := b.nightRecord(player, models.ActionCurse); curse != nil {
+		recap.Message += fmt.Sprintf(" You cursed %s.", curse.TargetUsername)
+	}
+	return recap
+}
+
+func (b RecapBuilder) shaman(player *models.Player) Recap {
+	recap := Recap{Role: player.Role, Outcome: RecapNoAction, Message: "You did not look into anyone tonight."}
+	if b.nightRecord(player, models.ActionPass) != nil {
+		recap.Outcome = RecapPassed
+		recap.Message = "You chose not to look into anyone tonight."
+	}
+
+	for _, record := range player.ActionHistory {
+		if record.ActionType == models.ActionVision || record.ActionType == models.ActionInspect {
+			recap.Visions = append(recap.Visions, VisionRecap{
+				Round:     record.Round,
+				Username:  record.TargetUsername,
+				Result:    record.Outcome,
+				Inspected: record.ActionType == models.ActionInspect,
+			})
+		}
+	}
+
+	if record := b.nightRecord(player, models.ActionVision); record != nil {
+		recap.Outcome = RecapVision
+		recap.Message = fmt.Sprintf("Your vision showed %s is %s.", record.TargetUsername, record.Outcome)
+		if record.Outcome == "unknown" {
+			recap.Message = fmt.Sprintf("Your vision of %s was clouded.", record.TargetUsername)
+		}
+	}
+	if record := b.nightRecord(player, models.ActionInspect); record != nil {
+		recap.Outcome = RecapInspected
+		recap.Message = fmt.Sprintf("Your vision showed the dead %s was %s.", record.TargetUsername, record.Outcome)
+		if record.Outcome == "unknown" {
+			recap.Message = fmt.Sprintf("Your vision of %s was clouded.", record.TargetUsername)
+		}
+	}
+	return recap
+}
+
+// nightRecord returns the player's record of the given type from tonight
+func (b RecapBuilder) nightRecord(player *models.Player, actionType string) *models.ActionRecord {
+	for i := len(player.ActionHistory) - 1; i >= 0; i-- {
+		record := &player.ActionHistory[i]
+		if record.Round != b.Room.Round || record.Phase != models.PhaseNight {
+			return nil
+		}
+		if record.ActionType == actionType {
+			return record
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecapsForAProtectedNight(t *testing.T) {
+	gm, code := deathsGame(t)
+	result := playNight(t, gm, code, map[string]string{"p1": "p4", "p2": "p4", "p3": "p1"})
+
+	if deaths := deathIDs(result); len(deaths) != 0 {
+		t.Fatalf("deaths = %v, want the protection to hold", deaths)
+	}
+	if recap := result.Recaps["p2"]; recap.Outcome != RecapProtectedAttacked || !strings.Contains(recap.Message, "Player4") {
+		t.Errorf("hunter recap = %+v, want Player4 attacked and saved", recap)
+	}
+	// The tiger learns the kill failed, not that the hunter stopped it
+	recap := result.Recaps["p1"]
+	if recap.Outcome != RecapKillBlocked || !strings.Contains(recap.Message, "blocked") ||
+		strings.Contains(strings.ToLower(recap.Message), "protect") {
+		t.Errorf("tiger recap = %+v, want a plain block", recap)
+	}
+	if recap := result.Recaps["p3"]; recap.Outcome != RecapVision || len(recap.Visions) != 1 || recap.Visions[0].Username != "Player1" {
+		t.Errorf("shaman recap = %+v, want the vision of Player1", recap)
+	}
+	// Villagers have nothing to recap
+	for _, id := range []string{"p4", "p5", "p6", "p7"} {
+		if recap, ok := result.Recaps[id]; ok {
+			t.Errorf("villager %s got %+v", id, recap)
+		}
+	}
+}
+
+func TestRecapsForASuccessfulKill(t *testing.T) {
+	gm, code := deathsGame(t)
+	playNight(t, gm, code, map[string]string{"p3": "p1"})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p7")
+	result := playNight(t, gm, code, map[string]string{"p1": "p5", "p2": "p4", "p3": "p6"})
+
+	if deaths := deathIDs(result); len(deaths) != 1 || deaths[0] != "p5" {
+		t.Fatalf("deaths = %v, want p5", deaths)
+	}
+	if recap := result.Recaps["p2"]; recap.Outcome != RecapProtectedQuiet || !strings.Contains(recap.Message, "Player4") {
+		t.Errorf("hunter recap = %+v, want Player4 left alone", recap)
+	}
+	if recap := result.Recaps["p1"]; recap.Outcome != RecapKillSucceeded || !strings.Contains(recap.Message, "Player5") {
+		t.Errorf("tiger recap = %+v, want the kill of Player5", recap)
+	}
+
+	// The shaman's recap restates every vision so far, oldest first
+	recap := result.Recaps["p3"]
+	if recap.Outcome != RecapVision || len(recap.Visions) != 2 {
+		t.Fatalf("shaman recap = %+v, want two visions", recap)
+	}
+	first, second := recap.Visions[0], recap.Visions[1]
+	if first.Username != "Player1" || second.Username != "Player6" || first.Round >= second.Round || second.Result == "" {
+		t.Errorf("visions = %+v, want Player1 then Player6", recap.Visions)
+	}
+	if _, ok := result.Recaps["p5"]; ok {
+		t.Error("the night's victim got a recap")
+	}
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const (
+	// DefaultReconnectVoteGrace is the voting time a reconnecting voter is
+	// promised
+	DefaultReconnectVoteGrace = 15 * time.Second
+	// maxReconnectExtensions caps how many reconnections may extend one vote
+	maxReconnectExtensions = 3
+)
+
+// ExtensionReconnection is the reason given for time added because a voter
+// came back
+const ExtensionReconnection = "reconnection"
+
+// PhaseExtension is time added to the current phase
+type PhaseExtension struct {
+	Reason       string           `json:"reason"`
+	PlayerID     string           `json:"playerId"`
+	AddedSeconds int              `json:"addedSeconds"`
+	PhaseEndTime models.Timestamp `json:"phaseEndTime"`
+}
+
+// extendVoteForReconnectLocked gives a voter who reconnects late in the
+// vote the room's grace to cast their ballot, by moving the deadline to
+// grace from now. Each player gets it once a vote and a vote at most
+// maxReconnectExtensions times. A voter whose ballot is already in gets
+// nothing, and the extension never holds back the vote ending early once
+// everyone has voted.
+func extendVoteForReconnectLocked(room *models.GameRoom, player *models.Player, grace time.Duration, now time.Time) *PhaseExtension {
+	if grace <= 0 || room.Phase != models.PhaseVoting || room.PhaseEndTime == nil || pausedLocked(room) {
+		return nil
+	}
+	if !player.IsAlive || player.IsBot || player.VotedFor != "" {
+		return nil
+	}
+	if containsID(room.VoteExtendedFor, player.ID) || len(room.VoteExtendedFor) >= maxReconnectExtensions {
+		return nil
+	}
+
+	remaining := room.PhaseEndTime.Sub(now)
+	if remaining <= 0 || remaining >= grace {
+		return nil
+	}
+
+	endTime := now.Add(grace)
+	room.PhaseEndTime = models.TimestampPtr(endTime)
+	room.VoteExtendedFor = append(room.VoteExtendedFor, player.ID)
+
+	return &PhaseExtension{
+		Reason:       ExtensionReconnection,
+		PlayerID:     player.ID,
+		AddedSeconds: int(math.Ceil((grace - remaining).Seconds())),
+		PhaseEndTime: *room.PhaseEndTime,
+	}
+}
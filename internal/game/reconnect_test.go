@@ -0,0 +1,143 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// lateReconnect drops the player, winds the vote's clock to left before its
+// end and brings them back, returning any extension they got
+func lateReconnect(t *testing.T, gm *GameManager, code models.RoomCode, playerID string, left time.Duration) *PhaseExtension {
+	t.Helper()
+
+	gm.SetConnected(code, playerID, false)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.PhaseEndTime = models.TimestampPtr(time.Now().Add(left))
+	})
+	_, extension := gm.SetConnected(code, playerID, true)
+	return extension
+}
+
+// phaseEnd is the room's current deadline
+func phaseEnd(t *testing.T, gm *GameManager, code models.RoomCode) time.Time {
+	t.Helper()
+
+	view, _ := gm.RoomView(code, "")
+	return view.PhaseEndTime.Time
+}
+
+func TestLateReconnectExtendsTheVote(t *testing.T) {
+	gm, code := votingGame(t)
+
+	before := time.Now()
+	extension := lateReconnect(t, gm, code, "p3", 5*time.Second)
+	if extension == nil {
+		t.Fatal("a voter back with 5 seconds left got no extension")
+	}
+	if extension.Reason != ExtensionReconnection || extension.PlayerID != "p3" {
+		t.Errorf("extension = %+v, want a reconnection for p3", extension)
+	}
+	if extension.AddedSeconds < 10 || extension.AddedSeconds > 11 {
+		t.Errorf("added %d seconds, want the 10 up to the grace", extension.AddedSeconds)
+	}
+	end := phaseEnd(t, gm, code)
+	if end.Before(before.Add(DefaultReconnectVoteGrace)) || end.After(time.Now().Add(DefaultReconnectVoteGrace)) {
+		t.Errorf("the vote ends %s from now, want the grace", time.Until(end).Round(time.Second))
+	}
+	if !extension.PhaseEndTime.Equal(end) {
+		t.Errorf("the extension says the vote ends at %s, the room %s", extension.PhaseEndTime, end)
+	}
+
+	// Once a vote per player
+	if again := lateReconnect(t, gm, code, "p3", 5*time.Second); again != nil {
+		t.Errorf("p3 was extended twice: %+v", again)
+	}
+}
+
+func TestReconnectWithTimeToVoteIsNotExtended(t *testing.T) {
+	gm, code := votingGame(t)
+
+	if extension := lateReconnect(t, gm, code, "p3", 20*time.Second); extension != nil {
+		t.Errorf("a voter back with 20 seconds left got %+v", extension)
+	}
+	// Nor did that spend their extension
+	if extension := lateReconnect(t, gm, code, "p3", 5*time.Second); extension == nil {
+		t.Error("p3's later late reconnection was not extended")
+	}
+}
+
+func TestReconnectExtensionsAreCapped(t *testing.T) {
+	gm, code := votingGame(t)
+
+	for i, id := range []string{"p2", "p3", "p4", "p5"} {
+		extension := lateReconnect(t, gm, code, id, 5*time.Second)
+		if got, want := extension != nil, i < maxReconnectExtensions; got != want {
+			t.Errorf("reconnection %d (%s) extended = %v, want %v", i+1, id, got, want)
+		}
+	}
+}
+
+func TestVoterWhoVotedIsNotExtended(t *testing.T) {
+	gm, code := votingGame(t)
+
+	if err := gm.Vote(code, "p3", "p1"); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if extension := lateReconnect(t, gm, code, "p3", 5*time.Second); extension != nil {
+		t.Errorf("a voter whose ballot is in got %+v", extension)
+	}
+}
+
+func TestExtensionDoesNotHoldBackAFastVote(t *testing.T) {
+	gm, code := votingGame(t)
+
+	if extension := lateReconnect(t, gm, code, "p3", 5*time.Second); extension == nil {
+		t.Fatal("no extension")
+	}
+	view, _ := gm.RoomView(code, "")
+	for _, voter := range view.LivingPlayers() {
+		target := "p1"
+		if voter.ID == "p1" {
+			target = "p2"
+		}
+		if err := gm.Vote(code, voter.ID, target); err != nil {
+			t.Fatalf("%s votes: %v", voter.ID, err)
+		}
+	}
+	if !gm.CheckAllVoted(code) {
+		t.Fatal("everyone voted but the vote waits on the extension")
+	}
+	nextPhase(t, gm, code)
+	if isAlive(gm, code, "p1") {
+		t.Error("the vote did not resolve")
+	}
+}
+
+func TestNoExtensionOutsideTheVote(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		gm, code := votingGame(t)
+		gm.ReconnectVoteGrace = 0
+		if extension := lateReconnect(t, gm, code, "p3", 5*time.Second); extension != nil {
+			t.Errorf("got %+v with the grace off", extension)
+		}
+	})
+
+	t.Run("day", func(t *testing.T) {
+		gm := NewGameManager()
+		code := newTestRoom(t, gm, 6, nil)
+		startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+		if extension := lateReconnect(t, gm, code, "p3", 5*time.Second); extension != nil {
+			t.Errorf("the day was extended: %+v", extension)
+		}
+	})
+
+	t.Run("dead player", func(t *testing.T) {
+		gm, code := votingGame(t)
+		withRoom(t, gm, code, func(room *models.GameRoom) { room.SetAlive(room.Players["p3"], false) })
+		if extension := lateReconnect(t, gm, code, "p3", 5*time.Second); extension != nil {
+			t.Errorf("a dead player extended the vote: %+v", extension)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// rematchGrace is how long a player may have been gone and still be carried
+// over into a rematch; it matches the grace a paused game gives them
+const rematchGrace = quorumGrace
+
+// RematchRoster is who a rematch carried over into the new lobby and who
+// it dropped for being away too long
+type RematchRoster struct {
+	Kept    []string `json:"kept"`
+	Dropped []string `json:"dropped"`
+}
+
+// Rematch reopens an ended game's lobby for another game with the same room
+// (host only). Players disconnected for longer than rematchGrace, or who
+// never connected, are dropped so they do not hold up the next start; the
+// rest keep their colors, and seats are dealt again at the start.
+func (gm *GameManager) Rematch(code models.RoomCode, hostID string) (*RematchRoster, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, hostID, PermRematch); err != nil {
+		return nil, err
+	}
+
+	if room.Phase != models.PhaseEnded {
+		return nil, ErrGameNotEnded
+	}
+
+	now := time.Now()
+	roster := &RematchRoster{Kept: []string{}, Dropped: []string{}}
+	var dropped []*models.Player
+	for _, player := range playersByIDLocked(room) {
+		if player.ID != hostID && absentLocked(player, now) {
+			room.RemovePlayer(player.ID)
+			dropped = append(dropped, player)
+			roster.Dropped = append(roster.Dropped, player.ID)
+			continue
+		}
+		// Moderators are granted for one game; the host grants them again
+		player.IsModerator = false
+		roster.Kept = append(roster.Kept, player.ID)
+	}
+
+	if err := gm.transitionLocked(room, models.PhaseWaiting, ReasonRematch); err != nil {
+		return nil, err
+	}
+
+	for _, player := range dropped {
+		player := player
+		gm.notify(room, func(o RoomObserver) { o.OnPlayerLeft(room, player) })
+	}
+	return roster, nil
+}
+
+// absentLocked reports whether a player has been away longer than
+// rematchGrace. Bots never connect and are never absent.
+func absentLocked(player *models.Player, now time.Time) bool {
+	if player.IsBot || player.IsConnected {
+		return false
+	}
+	return player.DisconnectedAt == nil || now.Sub(*player.DisconnectedAt) > rematchGrace
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// rematchGame is a game of seven the village won by voting out its tiger
+// p2. Everyone is connected but p6, who left a while ago, and p7, who only
+// just dropped.
+func rematchGame(t *testing.T, observers ...RoomObserver) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager(observers...)
+	code := newTestRoom(t, gm, 7, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger})
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7"} {
+		gm.SetConnected(code, id, true)
+	}
+	gm.SetConnected(code, "p6", false)
+	gm.SetConnected(code, "p7", false)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		gone := time.Now().Add(-2 * rematchGrace)
+		room.Players["p6"].DisconnectedAt = &gone
+	})
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p2")
+	if phase := phaseOf(t, gm, code); phase != models.PhaseEnded {
+		t.Fatalf("phase = %s, want the game over", phase)
+	}
+	return gm, code
+}
+
+func TestRematchDropsLongAbsentPlayers(t *testing.T) {
+	recorder := &recordingObserver{}
+	gm, code := rematchGame(t, recorder)
+
+	roster, err := gm.Rematch(code, "p1")
+	if err != nil {
+		t.Fatalf("rematch: %v", err)
+	}
+	if want := []string{"p6"}; !reflect.DeepEqual(roster.Dropped, want) {
+		t.Errorf("dropped %v, want %v", roster.Dropped, want)
+	}
+	if want := []string{"p1", "p2", "p3", "p4", "p5", "p7"}; !reflect.DeepEqual(roster.Kept, want) {
+		t.Errorf("kept %v, want %v", roster.Kept, want)
+	}
+
+	view, _ := gm.RoomView(code, "")
+	if view.Phase != models.PhaseWaiting || view.Players["p6"] != nil || len(view.Players) != 6 {
+		t.Errorf("the new lobby is %s with %d players", view.Phase, len(view.Players))
+	}
+	for id, player := range view.Players {
+		if !player.IsAlive || player.Role != "" {
+			t.Errorf("%s carried over alive = %v, role %q", id, player.IsAlive, player.Role)
+		}
+	}
+	left := 0
+	for _, event := range recorder.events {
+		if event == "left p6" {
+			left++
+		}
+	}
+	if left != 1 {
+		t.Errorf("observer saw %v, want p6 leaving once", recorder.events)
+	}
+}
+
+func TestBrieflyAbsentPlayerRejoinsTheRematch(t *testing.T) {
+	gm, code := rematchGame(t)
+	if _, err := gm.Rematch(code, "p1"); err != nil {
+		t.Fatalf("rematch: %v", err)
+	}
+
+	gm.SetConnected(code, "p7", true)
+	view, _ := gm.RoomView(code, "p7")
+	if player := view.Players["p7"]; player == nil || !player.IsConnected {
+		t.Fatal("p7 did not reconnect into the new lobby")
+	}
+
+	// Readiness is counted over the roster that carried over
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p7"} {
+		if err := gm.SetReady(code, id, true); err != nil {
+			t.Fatalf("%s ready: %v", id, err)
+		}
+	}
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Errorf("the rematch did not start with the kept players: %v", err)
+	}
+}
+
+func TestRematchNeedsAnEndedGameAndTheHost(t *testing.T) {
+	gm, code := rematchGame(t)
+	if _, err := gm.Rematch(code, "p3"); err == nil {
+		t.Error("a player who is not the host called a rematch")
+	}
+
+	playing := NewGameManager()
+	started := newTestRoom(t, playing, 6, nil)
+	startTestGame(t, playing, started, nil)
+	if _, err := playing.Rematch(started, "p1"); !errors.Is(err, ErrGameNotEnded) {
+		t.Errorf("rematch during the game: err = %v, want %v", err, ErrGameNotEnded)
+	}
+}
@@ -0,0 +1,204 @@
+package game
+
+import (
+	"log"
+	"sort"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// RoomSummary is one room in the admin listing
+type RoomSummary struct {
+	Code           models.RoomCode  `json:"code"`
+	HostID         string           `json:"hostId"`
+	Phase          models.GamePhase `json:"phase"`
+	Round          int              `json:"round"`
+	Players        int              `json:"players"`
+	CreatedAt      models.Timestamp `json:"createdAt"`
+	LastActivityAt models.Timestamp `json:"lastActivityAt"`
+	Problems       []string         `json:"problems,omitempty"`
+}
+
+// unlock releases the write lock, first validating every room when
+// DebugValidate is on. Every mutating method defers it.
+func (gm *GameManager) unlock() {
+	if gm.DebugValidate {
+		gm.validateRoomsLocked()
+	}
+	gm.mu.Unlock()
+}
+
+// validateRoomsLocked logs and counts every invariant violation
+func (gm *GameManager) validateRoomsLocked() {
+	for code, room := range gm.Rooms {
+		for _, problem := range room.Validate() {
+			gm.validationFailures++
+			log.Printf("🚨 Room %s is inconsistent: %s", code, problem)
+		}
+	}
+}
+
+// ValidationFailures returns how many invariant violations debug validation has seen
+func (gm *GameManager) ValidationFailures() int {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.validationFailures
+}
+
+// RoomSummaries lists every room with its current invariant violations, by code
+func (gm *GameManager) RoomSummaries() []RoomSummary {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	summaries := make([]RoomSummary, 0, len(gm.Rooms))
+	for _, room := range gm.Rooms {
+		summaries = append(summaries, RoomSummary{
+			Code:           room.Code,
+			HostID:         room.HostID,
+			Phase:          room.Phase,
+			Round:          room.Round,
+			Players:        len(room.Players),
+			CreatedAt:      room.CreatedAt,
+			LastActivityAt: models.NewTimestamp(room.LastActivityAt),
+			Problems:       room.Validate(),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Code < summaries[j].Code })
+
+	return summaries
+}
+
+// RepairRoom resets every inconsistent piece of room state to a safe value
+// and returns a description of each repair made:
+//   - a missing host is replaced by the longest-standing player
+//   - a dangling hunter wait is cleared, so the phase resumes as after a shot
+//   - a night role outside the night order moves to the first role still to act
+//   - a defense without nominees goes straight to voting
+//   - vote tallies are recounted from the ballots
+//   - targets and curses pointing at unknown players are cleared
+func (gm *GameManager) RepairRoom(code models.RoomCode) ([]string, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	return gm.repairRoomLocked(room), nil
+}
+
+// repairRoomLocked makes RepairRoom's repairs
+func (gm *GameManager) repairRoomLocked(room *models.GameRoom) []string {
+	var repairs []string
+	repaired := func(what string) {
+		repairs = append(repairs, what)
+		log.Printf("🔧 Repaired room %s: %s", room.Code, what)
+	}
+
+	if len(room.Players) > 0 && room.Players[room.HostID] == nil {
+		room.HostID = longestStandingPlayerLocked(room).ID
+		repaired("reassigned host to " + room.HostID)
+	}
+
+	if hunter := room.Players[room.DeadHunterID]; room.WaitingHunterShoot &&
+		(hunter == nil || hunter.Role != models.RoleHunter || hunter.IsAlive) {
+		clearHunterWaitLocked(room)
+		repaired("cleared dangling hunter wait")
+	} else if !room.WaitingHunterShoot && room.DeadHunterID != "" {
+		room.DeadHunterID = ""
+		repaired("cleared stale dead hunter")
+	}
+
+	if room.CurrentNightRole != "" {
+		if room.Phase != models.PhaseNight {
+			setNightRoleLocked(room, "")
+			repaired("cleared night role outside the night")
+		} else if !containsRole(room.NightActionOrder, room.CurrentNightRole) {
+			room.NightActionOrder = gm.getNightActionOrder(room)
+			setNightRoleLocked(room, firstPendingNightRoleLocked(room))
+			if room.CurrentNightRole == "" {
+				repaired("rebuilt night order; every role has acted")
+			} else {
+				repaired("rebuilt night order, resuming at " + string(room.CurrentNightRole))
+			}
+		}
+	}
+
+	if room.Phase == models.PhaseDefense && len(room.Nominees) == 0 {
+		gm.transitionLocked(room, models.PhaseVoting, ReasonRepaired)
+		repaired("moved defense without nominees to voting")
+	}
+
+	if recountVotesLocked(room) {
+		repaired("recounted vote tallies from ballots")
+	}
+
+	for _, target := range []*string{&room.CursedPlayer, &room.TigerTarget, &room.HunterProtection, &room.ShamanVision} {
+		if *target != "" && room.Players[*target] == nil {
+			repaired("cleared unknown target " + *target)
+			*target = ""
+		}
+	}
+
+	return repairs
+}
+
+// longestStandingPlayerLocked returns the player who joined first
+func longestStandingPlayerLocked(room *models.GameRoom) *models.Player {
+	var first *models.Player
+	for _, player := range room.Players {
+		if first == nil || player.JoinedAt.Before(first.JoinedAt.Time) {
+			first = player
+		}
+	}
+	return first
+}
+
+// firstPendingNightRoleLocked returns the first role in the night order
+// with a living holder who has not acted, or "" if none is left
+func firstPendingNightRoleLocked(room *models.GameRoom) models.Role {
+	for _, role := range room.NightActionOrder {
+		for _, player := range room.LivingPlayers() {
+			if nightTurnRole(player.Role) == role && !player.HasActedThisNight {
+				return role
+			}
+		}
+	}
+	return ""
+}
+
+// recountVotesLocked rebuilds the tallies from ballots, reporting whether they changed
+func recountVotesLocked(room *models.GameRoom) bool {
+	ballots := make(map[string]int)
+	for _, player := range room.Players {
+		if player.VotedFor != "" && player.VotedFor != models.VoteAbstain {
+			ballots[player.VotedFor]++
+		}
+	}
+
+	if len(ballots) == len(room.VoteResults) {
+		same := true
+		for targetID, count := range ballots {
+			if room.VoteResults[targetID] != count {
+				same = false
+				break
+			}
+		}
+		if same {
+			return false
+		}
+	}
+
+	room.VoteResults = ballots
+	return true
+}
+
+func containsRole(roles []models.Role, role models.Role) bool {
+	for _, candidate := range roles {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// lobbyGame is a lobby of four
+func lobbyGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	return gm, newTestRoom(t, gm, 4, nil)
+}
+
+// dayGame is a game of six on its first day
+func dayGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleHunter})
+	return gm, code
+}
+
+func TestRepairRoom(t *testing.T) {
+	tests := []struct {
+		name    string
+		game    func(*testing.T) (*GameManager, models.RoomCode)
+		corrupt func(*models.GameRoom)
+		problem string // part of the violation Validate reports
+		repair  string // part of the repair RepairRoom reports
+		check   func(*testing.T, *models.GameRoom)
+	}{
+		{
+			name:    "missing host",
+			game:    lobbyGame,
+			corrupt: func(room *models.GameRoom) { room.HostID = "ghost" },
+			problem: `host "ghost" is not a player`,
+			repair:  "reassigned host to ",
+			check: func(t *testing.T, room *models.GameRoom) {
+				if room.Players[room.HostID] == nil {
+					t.Errorf("host %q is still not a player", room.HostID)
+				}
+			},
+		},
+		{
+			name: "hunter wait on a living villager",
+			game: deathsGame,
+			corrupt: func(room *models.GameRoom) {
+				room.WaitingHunterShoot = true
+				room.DeadHunterID = "p4"
+			},
+			problem: `waiting for hunter "p4" whose role is "villager"`,
+			repair:  "cleared dangling hunter wait",
+			check: func(t *testing.T, room *models.GameRoom) {
+				if room.WaitingHunterShoot || room.DeadHunterID != "" {
+					t.Errorf("still waiting on %q", room.DeadHunterID)
+				}
+			},
+		},
+		{
+			name:    "dead hunter without a shot",
+			game:    dayGame,
+			corrupt: func(room *models.GameRoom) { room.DeadHunterID = "p2" },
+			problem: `dead hunter "p2" set without a pending shot`,
+			repair:  "cleared stale dead hunter",
+			check: func(t *testing.T, room *models.GameRoom) {
+				if room.DeadHunterID != "" {
+					t.Errorf("dead hunter = %q", room.DeadHunterID)
+				}
+			},
+		},
+		{
+			name:    "night role by day",
+			game:    dayGame,
+			corrupt: func(room *models.GameRoom) { room.CurrentNightRole = models.RoleTiger },
+			problem: `current night role "tiger" set during "day"`,
+			repair:  "cleared night role outside the night",
+			check: func(t *testing.T, room *models.GameRoom) {
+				if room.CurrentNightRole != "" {
+					t.Errorf("night role = %q", room.CurrentNightRole)
+				}
+			},
+		},
+		{
+			name: "night role outside the night order",
+			game: deathsGame,
+			corrupt: func(room *models.GameRoom) {
+				room.NightActionOrder = []models.Role{models.RoleTiger}
+				room.CurrentNightRole = models.RoleShaman
+			},
+			problem: `current night role "shaman" is not in the night order`,
+			repair:  "rebuilt night order, resuming at ",
+			check: func(t *testing.T, room *models.GameRoom) {
+				if !containsRole(room.NightActionOrder, room.CurrentNightRole) {
+					t.Errorf("night role %q still outside %v", room.CurrentNightRole, room.NightActionOrder)
+				}
+			},
+		},
+		{
+			name: "defense without nominees",
+			game: votingGame,
+			corrupt: func(room *models.GameRoom) {
+				room.Phase = models.PhaseDefense
+				room.Nominees = nil
+			},
+			problem: "defense phase without nominees",
+			repair:  "moved defense without nominees to voting",
+			check: func(t *testing.T, room *models.GameRoom) {
+				if room.Phase != models.PhaseVoting {
+					t.Errorf("phase = %s, want voting", room.Phase)
+				}
+			},
+		},
+		{
+			name: "tally out of step with the ballots",
+			game: votingGame,
+			corrupt: func(room *models.GameRoom) {
+				room.Players["p2"].VotedFor = "p3"
+				room.VoteResults = map[string]int{"p3": 4, "p4": 1}
+			},
+			problem: `vote tally for "p3" is 4 but 1 ballots were cast`,
+			repair:  "recounted vote tallies from ballots",
+			check: func(t *testing.T, room *models.GameRoom) {
+				if len(room.VoteResults) != 1 || room.VoteResults["p3"] != 1 {
+					t.Errorf("tally = %v, want the one ballot for p3", room.VoteResults)
+				}
+			},
+		},
+		{
+			name:    "curse on an unknown player",
+			game:    deathsGame,
+			corrupt: func(room *models.GameRoom) { room.CursedPlayer = "ghost" },
+			problem: `cursedPlayer "ghost" is not a player`,
+			repair:  "cleared unknown target ghost",
+			check: func(t *testing.T, room *models.GameRoom) {
+				if room.CursedPlayer != "" {
+					t.Errorf("cursed = %q", room.CursedPlayer)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, code := tt.game(t)
+			before := gm.ValidationFailures()
+
+			var problems []string
+			withRoom(t, gm, code, func(room *models.GameRoom) {
+				tt.corrupt(room)
+				problems = room.Validate()
+			})
+			if !anyContains(problems, tt.problem) {
+				t.Errorf("problems = %q, want one about %q", problems, tt.problem)
+			}
+			// Debug validation saw it when the lock was released
+			if gm.ValidationFailures() <= before {
+				t.Error("the violation was not counted")
+			}
+
+			repairs, err := gm.RepairRoom(code)
+			if err != nil {
+				t.Fatalf("repair: %v", err)
+			}
+			if !anyContains(repairs, tt.repair) {
+				t.Errorf("repairs = %q, want %q", repairs, tt.repair)
+			}
+			withRoom(t, gm, code, func(room *models.GameRoom) {
+				tt.check(t, room)
+				if problems := room.Validate(); len(problems) != 0 {
+					t.Errorf("still inconsistent after repair: %q", problems)
+				}
+			})
+		})
+	}
+}
+
+func TestRepairConsistentRoom(t *testing.T) {
+	gm, code := deathsGame(t)
+	if repairs, err := gm.RepairRoom(code); err != nil || len(repairs) != 0 {
+		t.Errorf("repairs = %q, %v; want none", repairs, err)
+	}
+}
+
+func anyContains(lines []string, part string) bool {
+	for _, line := range lines {
+		if strings.Contains(line, part) {
+			return true
+		}
+	}
+	return false
+}
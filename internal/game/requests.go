@@ -0,0 +1,99 @@
+package game
+
+import (
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// maxHandledRequests is how many requestIds are remembered per player
+const maxHandledRequests = 32
+
+// ActionMark returns how many actions the player has had accepted, to be
+// handed back to RememberRequest once their request has been handled
+func (gm *GameManager) ActionMark(code models.RoomCode, playerID string) int {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return 0
+	}
+	player := room.Players[playerID]
+	if player == nil {
+		return 0
+	}
+	return player.ActionSeq
+}
+
+// HandledRequest returns the action a request produced if the player has
+// already sent it. Requests are kept per player, not per connection, so a
+// request retried over REST after going out on the websocket (or the other
+// way round) is found too.
+func (gm *GameManager) HandledRequest(code models.RoomCode, playerID, requestID string) (models.ActionRecord, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return models.ActionRecord{}, false
+	}
+	player := room.Players[playerID]
+	if player == nil {
+		return models.ActionRecord{}, false
+	}
+
+	for _, handled := range player.HandledRequests {
+		if handled.ID == requestID {
+			return handled.Record, true
+		}
+	}
+	return models.ActionRecord{}, false
+}
+
+// RememberRequest ties the requestId to the action the player took while it
+// was handled, if any was accepted since mark. Rejected requests are not
+// remembered, so they may be retried with the same requestId.
+func (gm *GameManager) RememberRequest(code models.RoomCode, playerID, requestID string, mark int) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return
+	}
+	player := room.Players[playerID]
+	if player == nil || player.ActionSeq == mark {
+		return
+	}
+
+	player.HandledRequests = append(player.HandledRequests, models.HandledRequest{
+		ID:     requestID,
+		Record: currentActionLocked(room, player),
+	})
+	if excess := len(player.HandledRequests) - maxHandledRequests; excess > 0 {
+		player.HandledRequests = append([]models.HandledRequest(nil), player.HandledRequests[excess:]...)
+	}
+}
+
+// currentActionLocked describes the player's latest action this round: an
+// undecided tiger team's pending choice, or else their last record
+func currentActionLocked(room *models.GameRoom, player *models.Player) models.ActionRecord {
+	if team := room.TigerTeam; team != nil && !team.Resolved && room.Phase == models.PhaseNight {
+		if decision := team.Decisions[player.ID]; decision != nil {
+			record := models.ActionRecord{
+				Round:      room.Round,
+				Phase:      room.Phase,
+				ActionType: decision.Action,
+				TargetID:   decision.TargetID,
+			}
+			if target := room.Players[decision.TargetID]; target != nil {
+				record.TargetUsername = target.Username
+			}
+			return record
+		}
+	}
+
+	if n := len(player.ActionHistory); n > 0 && player.ActionHistory[n-1].Round == room.Round {
+		return player.ActionHistory[n-1]
+	}
+	return models.ActionRecord{Round: room.Round, Phase: room.Phase}
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// votingGame is a started game of six in its first vote
+func votingGame(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	nextPhase(t, gm, code)
+	return gm, code
+}
+
+// voteAs votes as a handler would for a request, remembering it
+func voteAs(gm *GameManager, code models.RoomCode, voterID, targetID, requestID string) error {
+	mark := gm.ActionMark(code, voterID)
+	defer gm.RememberRequest(code, voterID, requestID, mark)
+	return gm.Vote(code, voterID, targetID)
+}
+
+func TestOnlyAcceptedRequestsAreRemembered(t *testing.T) {
+	gm, code := votingGame(t)
+
+	if err := voteAs(gm, code, "p2", "nobody", "r1"); err == nil {
+		t.Fatal("a vote for nobody was accepted")
+	}
+	if _, handled := gm.HandledRequest(code, "p2", "r1"); handled {
+		t.Error("the rejected request was remembered")
+	}
+
+	if err := voteAs(gm, code, "p2", "p3", "r1"); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	record, handled := gm.HandledRequest(code, "p2", "r1")
+	if !handled || record.ActionType != models.ActionVote || record.TargetID != "p3" {
+		t.Errorf("r1 = %+v, %v; want p2's vote for p3", record, handled)
+	}
+	// Requests are the player's own
+	if _, handled := gm.HandledRequest(code, "p3", "r1"); handled {
+		t.Error("p2's request was found for p3")
+	}
+}
+
+func TestHandledRequestsAreCapped(t *testing.T) {
+	gm, code := votingGame(t)
+
+	for i := 0; i < maxHandledRequests+8; i++ {
+		target := []string{"p3", "p4"}[i%2]
+		if err := voteAs(gm, code, "p2", target, fmt.Sprintf("r%d", i)); err != nil {
+			t.Fatalf("vote %d: %v", i, err)
+		}
+	}
+	if _, handled := gm.HandledRequest(code, "p2", "r0"); handled {
+		t.Error("the oldest request was still remembered")
+	}
+	if _, handled := gm.HandledRequest(code, "p2", fmt.Sprintf("r%d", maxHandledRequests+7)); !handled {
+		t.Error("the latest request was forgotten")
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if n := len(room.Players["p2"].HandledRequests); n != maxHandledRequests {
+			t.Errorf("%d requests remembered, want %d", n, maxHandledRequests)
+		}
+	})
+}
+
+func TestHandledRequestsSurviveARestore(t *testing.T) {
+	gm, code := votingGame(t)
+	if err := voteAs(gm, code, "p2", "p3", "r1"); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+
+	restored := restore(t, gm, code)
+	record, handled := restored.HandledRequest(code, "p2", "r1")
+	if !handled || record.TargetID != "p3" {
+		t.Errorf("after the restore r1 = %+v, %v; want the vote for p3", record, handled)
+	}
+}
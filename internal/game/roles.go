@@ -0,0 +1,128 @@
+package game
+
+import (
+	"strconv"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// CatalogText is a message key with its English rendering. Clients with
+// their own translations render Key instead of Text.
+type CatalogText struct {
+	Key  string `json:"key"`
+	Text string `json:"text"`
+}
+
+// RoleInfo is a role's rules card
+type RoleInfo struct {
+	Role         models.Role   `json:"role"`
+	Team         string        `json:"team"` // "human" or "tiger"
+	Name         CatalogText   `json:"name"`
+	Description  CatalogText   `json:"description"`
+	NightAction  *CatalogText  `json:"nightAction,omitempty"` // nil for roles that sleep through the night
+	WinCondition CatalogText   `json:"winCondition"`
+	Tips         []CatalogText `json:"tips"`
+}
+
+const (
+	winHumans = "Win when every tiger is dead."
+	winTigers = "Win when the tigers are at least as many as the humans still alive."
+)
+
+// roleCatalog holds a rules card for every role. Compositions may only use
+// roles listed here.
+var roleCatalog = map[models.Role]RoleInfo{
+	models.RoleAlphaTiger: roleInfo(models.RoleAlphaTiger, "tiger", "Alpha Tiger",
+		"The leader of the tigers. You hunt with them and look human to the shaman until you use your curse.",
+		"Choose tonight's victim with the other tigers, or use your one curse instead.",
+		winTigers,
+		"A cursed player looks like a tiger to the shaman.",
+		"Once you curse, the shaman sees you as a tiger too, so pick the moment."),
+	models.RoleTiger: roleInfo(models.RoleTiger, "tiger", "Tiger",
+		"A man-eater hiding in the village. You know the other tigers.",
+		"Choose tonight's victim with the other tigers.",
+		winTigers,
+		"The hunter can protect your target; spread suspicion so they guess wrong.",
+		"Vote with the village often enough that you are not the odd one out."),
+	models.RoleShaman: roleInfo(models.RoleShaman, "human", "Shaman",
+		"A seer who can tell tigers from humans.",
+		"Look at one player each night to learn whether they are a tiger or a human.",
+		winHumans,
+		"The alpha tiger looks human to you until they have used their curse.",
+		"If you look at the alpha tiger on the night the tigers come for you, you survive."),
+	models.RoleHunter: roleInfo(models.RoleHunter, "human", "Hunter",
+		"A guardian who protects the village by night and takes a shot when they fall.",
+		"Protect one player from the tigers each night; not the same player twice in a row.",
+		winHumans,
+		"When you die you may shoot one player, so choose your suspect early.",
+		"Protecting yourself is allowed; think about who the tigers fear most."),
+	models.RoleVillager: roleInfo(models.RoleVillager, "human", "Villager",
+		"An ordinary villager with no powers but a vote.",
+		"",
+		winHumans,
+		"Listen for stories that change between days.",
+		"Your vote is your power; do not waste it."),
+}
+
+// roleInfo builds a catalog entry, deriving each message key from the role
+func roleInfo(role models.Role, team, name, description, nightAction, win string, tips ...string) RoleInfo {
+	prefix := "role." + string(role) + "."
+	info := RoleInfo{
+		Role:         role,
+		Team:         team,
+		Name:         CatalogText{Key: prefix + "name", Text: name},
+		Description:  CatalogText{Key: prefix + "description", Text: description},
+		WinCondition: CatalogText{Key: "win." + team, Text: win},
+		Tips:         make([]CatalogText, len(tips)),
+	}
+	if nightAction != "" {
+		info.NightAction = &CatalogText{Key: prefix + "night_action", Text: nightAction}
+	}
+	for i, tip := range tips {
+		info.Tips[i] = CatalogText{Key: prefix + "tip." + strconv.Itoa(i+1), Text: tip}
+	}
+	return info
+}
+
+// RoleCatalog returns every role's rules card in models.AllRoles order
+func RoleCatalog() []RoleInfo {
+	catalog := make([]RoleInfo, 0, len(models.AllRoles))
+	for _, role := range models.AllRoles {
+		if info, ok := roleCatalog[role]; ok {
+			catalog = append(catalog, info)
+		}
+	}
+	return catalog
+}
+
+// LookupRole returns a role's rules card
+func LookupRole(role models.Role) (RoleInfo, bool) {
+	info, ok := roleCatalog[role]
+	return info, ok
+}
+
+// RoleAssignment is what one player is privately told when the game starts
+type RoleAssignment struct {
+	Role models.Role `json:"role"`
+	Info RoleInfo    `json:"info"`
+}
+
+// RoleAssignments returns each human player's role with its rules card
+func (gm *GameManager) RoleAssignments(code models.RoomCode) map[string]RoleAssignment {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists || room.Phase == models.PhaseWaiting {
+		return nil
+	}
+
+	assignments := make(map[string]RoleAssignment, len(room.Players))
+	for id, player := range room.Players {
+		if player.IsBot {
+			continue
+		}
+		assignments[id] = RoleAssignment{Role: player.Role, Info: roleCatalog[player.Role]}
+	}
+	return assignments
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// declaredRoles reads the value of every Role constant in the models package
+func declaredRoles(t *testing.T) []models.Role {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "../models/models.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parse models: %v", err)
+	}
+	var roles []models.Role
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != "Role" {
+				continue
+			}
+			for _, v := range value.Values {
+				literal, _ := strconv.Unquote(v.(*ast.BasicLit).Value)
+				roles = append(roles, models.Role(literal))
+			}
+		}
+	}
+	return roles
+}
+
+func TestCatalogCoversEveryRole(t *testing.T) {
+	declared := declaredRoles(t)
+	if len(declared) != len(models.AllRoles) || len(roleCatalog) != len(declared) {
+		t.Errorf("%d roles declared, %d in AllRoles, %d in the catalog", len(declared), len(models.AllRoles), len(roleCatalog))
+	}
+
+	keys := make(map[string]models.Role)
+	for _, role := range declared {
+		info, ok := LookupRole(role)
+		if !ok {
+			t.Errorf("%s has no rules card", role)
+			continue
+		}
+		if info.Role != role || info.Team != "human" && info.Team != "tiger" {
+			t.Errorf("%s card is for %s on team %q", role, info.Role, info.Team)
+		}
+		if (info.NightAction == nil) != (role == models.RoleVillager) {
+			t.Errorf("%s night action = %v", role, info.NightAction)
+		}
+		if len(info.Tips) == 0 {
+			t.Errorf("%s has no tips", role)
+		}
+
+		texts := append([]CatalogText{info.Name, info.Description}, info.Tips...)
+		if info.NightAction != nil {
+			texts = append(texts, *info.NightAction)
+		}
+		for _, text := range texts {
+			if text.Key == "" || text.Text == "" {
+				t.Errorf("%s has an empty text %+v", role, text)
+			}
+			if other, taken := keys[text.Key]; taken {
+				t.Errorf("%s reuses %s's key %s", role, other, text.Key)
+			}
+			keys[text.Key] = role
+		}
+		if info.WinCondition.Key != "win."+info.Team || info.WinCondition.Text == "" {
+			t.Errorf("%s win condition = %+v", role, info.WinCondition)
+		}
+	}
+
+	catalog := RoleCatalog()
+	for i, role := range models.AllRoles {
+		if i >= len(catalog) || catalog[i].Role != role {
+			t.Fatalf("catalog out of AllRoles order at %d", i)
+		}
+	}
+}
+
+func TestCompositionLimitedToTheCatalog(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+
+	var settings models.RoomSettings
+	withRoom(t, gm, code, func(room *models.GameRoom) { settings = room.Settings })
+	settings.Composition = map[models.Role]int{models.RoleTiger: 1, models.RoleVillager: 4, "witch": 1}
+	var coded *errs.Error
+	if err := gm.UpdateSettings(code, "p1", settings); !errors.As(err, &coded) || coded.Code != "COMPOSITION_INVALID" {
+		t.Errorf("a composition with a witch: %v, want COMPOSITION_INVALID", err)
+	}
+
+	settings.Composition = map[models.Role]int{models.RoleTiger: 1, models.RoleVillager: 4, models.RoleShaman: 1}
+	if err := gm.UpdateSettings(code, "p1", settings); err != nil {
+		t.Errorf("a catalog composition: %v", err)
+	}
+}
+
+func TestRoleAssignments(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	if assignments := gm.RoleAssignments(code); assignments != nil {
+		t.Errorf("the lobby has assignments %v", assignments)
+	}
+
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleShaman})
+	assignments := gm.RoleAssignments(code)
+	if len(assignments) != 6 {
+		t.Fatalf("%d assignments, want one per player", len(assignments))
+	}
+	for id, want := range map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleShaman, "p3": models.RoleVillager} {
+		if got := assignments[id]; got.Role != want || got.Info.Role != want {
+			t.Errorf("%s assigned %s with the %s card, want %s", id, got.Role, got.Info.Role, want)
+		}
+	}
+
+	practice, practiceCode, bots := practiceGame(t)
+	for _, bot := range bots {
+		if _, ok := practice.RoleAssignments(practiceCode)[bot]; ok {
+			t.Errorf("bot %s was sent a rules card", bot)
+		}
+	}
+}
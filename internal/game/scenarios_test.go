@@ -0,0 +1,292 @@
+package game_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/testutil"
+)
+
+// table is the usual cast of a scenario: one of each special role and two
+// villagers
+var table = []string{"A:alpha_tiger", "T:tiger", "H:hunter", "S:shaman", "V:villager", "W:villager"}
+
+// cast is the table with the given entries in place of the same players'
+func cast(replaced ...string) []string {
+	players := append([]string(nil), table...)
+	for _, entry := range replaced {
+		for i, player := range players {
+			if player[:1] == entry[:1] {
+				players[i] = entry
+			}
+		}
+	}
+	return players
+}
+
+func runScenarios(t *testing.T, scenarios []testutil.NightScenario) {
+	t.Helper()
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+		t.Run(scenario.Name, func(t *testing.T) {
+			testutil.RunNight(t, scenario)
+		})
+	}
+}
+
+func TestNightKills(t *testing.T) {
+	runScenarios(t, []testutil.NightScenario{
+		{
+			Name:    "the tigers kill a villager",
+			Players: table,
+			Actions: []string{"A kill V"},
+			Expect: testutil.NightExpectation{
+				Deaths: []string{"V:tiger"},
+				Recaps: map[string]string{"A": game.RecapKillSucceeded, "T": game.RecapKillSucceeded, "H": game.RecapNoAction},
+			},
+		},
+		{
+			Name:    "the alpha overrules the tiger",
+			Players: table,
+			Actions: []string{"A kill V", "T kill W"},
+			Expect:  testutil.NightExpectation{Deaths: []string{"V:tiger"}},
+		},
+		{
+			Name:    "a lone tiger's kill stands",
+			Players: cast("A:alpha_tiger:dead"),
+			Actions: []string{"T kill W"},
+			Expect:  testutil.NightExpectation{Deaths: []string{"W:tiger"}},
+		},
+		{
+			Name:    "the tigers kill the hunter",
+			Players: table,
+			Actions: []string{"A kill H", "H protect V"},
+			Expect: testutil.NightExpectation{
+				Deaths: []string{"H:tiger"},
+				Recaps: map[string]string{"A": game.RecapKillSucceeded},
+			},
+		},
+		{
+			Name:    "the tigers pass",
+			Players: table,
+			Actions: []string{"A pass", "T pass"},
+			Expect:  testutil.NightExpectation{Recaps: map[string]string{"A": game.RecapPassed, "T": game.RecapPassed}},
+		},
+		{
+			Name:    "nobody decides",
+			Players: table,
+			Expect:  testutil.NightExpectation{Recaps: map[string]string{"A": game.RecapNoKill, "H": game.RecapNoAction}},
+		},
+	})
+}
+
+func TestNightProtection(t *testing.T) {
+	runScenarios(t, []testutil.NightScenario{
+		{
+			Name:    "protection blocks the kill",
+			Players: table,
+			Actions: []string{"A kill V", "H protect V"},
+			Expect: testutil.NightExpectation{
+				Protected:     true,
+				Recaps:        map[string]string{"H": game.RecapProtectedAttacked, "A": game.RecapKillBlocked},
+				RecapMessages: map[string]string{"H": "V was attacked", "A": "attack on V was blocked"},
+			},
+		},
+		{
+			Name:    "protecting someone else",
+			Players: table,
+			Actions: []string{"A kill V", "H protect W"},
+			Expect: testutil.NightExpectation{
+				Deaths: []string{"V:tiger"},
+				Recaps: map[string]string{"H": game.RecapProtectedQuiet},
+			},
+		},
+		{
+			Name:    "the hunter protects themself",
+			Players: table,
+			Actions: []string{"A kill H", "H protect H"},
+			Expect:  testutil.NightExpectation{Protected: true, Recaps: map[string]string{"H": game.RecapProtectedAttacked}},
+		},
+		{
+			Name:    "the hunter passes",
+			Players: table,
+			Actions: []string{"H pass"},
+			Expect:  testutil.NightExpectation{Recaps: map[string]string{"H": game.RecapPassed}},
+		},
+	})
+}
+
+func TestNightVisions(t *testing.T) {
+	runScenarios(t, []testutil.NightScenario{
+		{
+			Name:    "a tiger is seen",
+			Players: table,
+			Actions: []string{"S vision T"},
+			Expect:  testutil.NightExpectation{Vision: "tiger", Recaps: map[string]string{"S": game.RecapVision}},
+		},
+		{
+			Name:    "a villager is seen",
+			Players: table,
+			Actions: []string{"S vision V"},
+			Expect:  testutil.NightExpectation{Vision: "human"},
+		},
+		{
+			Name:    "the alpha hides",
+			Players: table,
+			Actions: []string{"S vision A"},
+			Expect:  testutil.NightExpectation{Vision: "human"},
+		},
+		{
+			Name:    "the alpha who spent the curse is seen",
+			Players: cast("A:alpha_tiger:curse_used"),
+			Actions: []string{"S vision A"},
+			Expect:  testutil.NightExpectation{Vision: "tiger"},
+		},
+		{
+			Name:    "a cursed villager looks like a tiger",
+			Players: cast("A:alpha_tiger:curse_used", "W:villager:cursed"),
+			Actions: []string{"S vision W"},
+			Expect:  testutil.NightExpectation{Vision: "tiger"},
+		},
+		{
+			Name:    "a villager cursed tonight looks like a tiger",
+			Players: table,
+			Actions: []string{"A curse W", "S vision W"},
+			Expect:  testutil.NightExpectation{Vision: "tiger"},
+		},
+		{
+			Name:    "the dead show their role",
+			Players: cast("T:tiger:dead"),
+			Actions: []string{"S vision T"},
+			Expect:  testutil.NightExpectation{Vision: "tiger", Recaps: map[string]string{"S": game.RecapInspected}},
+		},
+		{
+			Name:    "a dead cursed villager shows the truth",
+			Players: cast("A:alpha_tiger:curse_used", "W:villager:cursed:dead"),
+			Actions: []string{"S vision W"},
+			Expect:  testutil.NightExpectation{Vision: "villager"},
+		},
+	})
+}
+
+func TestNightModifiersAndRules(t *testing.T) {
+	runScenarios(t, []testutil.NightScenario{
+		{
+			Name:     "a blood moon takes the overruled victim too",
+			Players:  table,
+			Actions:  []string{"A kill V", "T kill W"},
+			Modifier: game.ModifierBloodMoon,
+			Expect:   testutil.NightExpectation{Deaths: []string{"V:tiger", "W:tiger"}},
+		},
+		{
+			Name:     "a blood moon with one victim chosen",
+			Players:  table,
+			Actions:  []string{"A kill V", "T kill V"},
+			Modifier: game.ModifierBloodMoon,
+			Expect:   testutil.NightExpectation{Deaths: []string{"V:tiger"}},
+		},
+		{
+			Name:     "a quiet night",
+			Players:  table,
+			Actions:  []string{"A kill V"},
+			Modifier: game.ModifierQuietNight,
+			Expect:   testutil.NightExpectation{Recaps: map[string]string{"A": game.RecapKillBlocked}},
+		},
+		{
+			Name:     "fog clouds the vision",
+			Players:  table,
+			Actions:  []string{"S vision T"},
+			Modifier: game.ModifierFog,
+			Expect:   testutil.NightExpectation{Vision: "unknown"},
+		},
+		{
+			Name:     "the first night spares the victim",
+			Players:  table,
+			Actions:  []string{"A kill V"},
+			Night:    1,
+			Settings: func(settings *models.RoomSettings) { settings.FirstNightNoKill = true },
+			Expect:   testutil.NightExpectation{Recaps: map[string]string{"A": game.RecapKillSpared}},
+		},
+		{
+			Name:     "the first night rule off",
+			Players:  table,
+			Actions:  []string{"A kill V"},
+			Night:    1,
+			Settings: func(settings *models.RoomSettings) { settings.FirstNightNoKill = false },
+			Expect:   testutil.NightExpectation{Deaths: []string{"V:tiger"}},
+		},
+	})
+}
+
+// TestNightMatrix plays every combination of protection, the shaman's
+// luck, the curse and a second victim. The tigers always go for the
+// shaman, and on a blood moon for V as well.
+func TestNightMatrix(t *testing.T) {
+	var scenarios []testutil.NightScenario
+	for _, protected := range []bool{false, true} {
+		for _, lucky := range []bool{false, true} {
+			for _, curse := range []string{"none", "tonight", "spent"} {
+				for _, twoVictims := range []bool{false, true} {
+					scenarios = append(scenarios, matrixScenario(protected, lucky, curse, twoVictims))
+				}
+			}
+		}
+	}
+	runScenarios(t, scenarios)
+}
+
+// matrixScenario is one cell of the matrix. Three tigers let the alpha
+// curse while the other two still split their kill.
+func matrixScenario(protected, lucky bool, curse string, twoVictims bool) testutil.NightScenario {
+	scenario := testutil.NightScenario{
+		Name:    fmt.Sprintf("protected=%v lucky=%v curse=%s two victims=%v", protected, lucky, curse, twoVictims),
+		Players: append(cast(), "U:tiger"),
+		Actions: []string{"T kill S", "H pass"},
+	}
+
+	if twoVictims {
+		scenario.Modifier = game.ModifierBloodMoon
+		scenario.Actions = append(scenario.Actions, "U kill V")
+	} else {
+		scenario.Actions = append(scenario.Actions, "U kill S")
+	}
+	switch curse {
+	case "tonight":
+		scenario.Actions = append(scenario.Actions, "A curse W")
+	case "spent":
+		scenario.Players[0] = "A:alpha_tiger:curse_used"
+		fallthrough
+	default:
+		scenario.Actions = append(scenario.Actions, "A pass")
+	}
+	if protected {
+		scenario.Actions[1] = "H protect S"
+	}
+
+	// Seeing the alpha before the curse is spent is what saves the shaman;
+	// the alpha shows as a tiger from the night it curses
+	scenario.Actions = append(scenario.Actions, "S vision V")
+	scenario.Expect.Vision = "human"
+	if lucky {
+		scenario.Actions[len(scenario.Actions)-1] = "S vision A"
+		if curse != "none" {
+			scenario.Expect.Vision = "tiger"
+		}
+	}
+
+	switch {
+	case protected:
+		scenario.Expect.Protected = true
+	case lucky && curse == "none":
+		scenario.Expect.ShamanSaved = true
+	default:
+		scenario.Expect.Deaths = []string{"S:tiger"}
+	}
+	if twoVictims {
+		scenario.Expect.Deaths = append(scenario.Expect.Deaths, "V:tiger")
+	}
+	return scenario
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// ErrInvalidSeat is returned when an action names a seat nobody sits in
+var ErrInvalidSeat = &errs.Error{Code: "INVALID_SEAT", Message: "no player sits in that seat"}
+
+// newSeed picks a new room's random seed
+func (gm *GameManager) newSeed() int64 {
+	if gm.Seed != nil {
+		return gm.Seed()
+	}
+	return time.Now().UnixNano()
+}
+
+// roomRandLocked is the room's own random source. Everything random in a
+// game draws from it, so a room replays exactly from its seed.
+func roomRandLocked(room *models.GameRoom) *rand.Rand {
+	if room.Rand == nil {
+		room.Rand = rand.New(rand.NewSource(room.Seed))
+	}
+	return room.Rand
+}
+
+// playersByIDLocked lists the room's players in a fixed order, so random
+// draws over them do not depend on map iteration
+func playersByIDLocked(room *models.GameRoom) []*models.Player {
+	players := make([]*models.Player, 0, len(room.Players))
+	for _, player := range room.Players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
+
+// assignSeats seats the players around the table in a random order. Seats
+// run from 1 and stay put for the whole game, the dead included.
+func assignSeats(room *models.GameRoom) {
+	players := playersByIDLocked(room)
+	for i, seat := range roomRandLocked(room).Perm(len(players)) {
+		players[i].Seat = seat + 1
+	}
+}
+
+// SeatPlayer returns the ID of the player in the given seat
+func (gm *GameManager) SeatPlayer(code models.RoomCode, seat int) (string, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return "", gm.missingRoomLocked(code)
+	}
+
+	if seat > 0 {
+		for id, player := range room.Players {
+			if player.Seat == seat {
+				return id, nil
+			}
+		}
+	}
+	return "", ErrInvalidSeat
+}
@@ -0,0 +1,118 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// seatsOf returns every player's seat
+func seatsOf(t *testing.T, gm *GameManager, code models.RoomCode) map[string]int {
+	t.Helper()
+
+	seats := make(map[string]int)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for id, player := range room.Players {
+			seats[id] = player.Seat
+		}
+	})
+	return seats
+}
+
+// seededGame starts an eight-player game whose room draws from seed
+func seededGame(t *testing.T, seed int64) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	gm.Seed = func() int64 { return seed }
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	return gm, code
+}
+
+func TestSeatsFollowTheSeed(t *testing.T) {
+	gm, code := seededGame(t, 42)
+	again, againCode := seededGame(t, 42)
+	other, otherCode := seededGame(t, 43)
+
+	seats := seatsOf(t, gm, code)
+	if replay := seatsOf(t, again, againCode); !reflect.DeepEqual(seats, replay) {
+		t.Errorf("the same seed seated %v, then %v", seats, replay)
+	}
+	if reflect.DeepEqual(seats, seatsOf(t, other, otherCode)) {
+		t.Error("another seed dealt the very same seats")
+	}
+
+	taken := make(map[int]bool)
+	for id, seat := range seats {
+		if seat < 1 || seat > len(seats) || taken[seat] {
+			t.Errorf("%s sits in seat %d", id, seat)
+		}
+		taken[seat] = true
+	}
+}
+
+func TestSeatsSurviveDeaths(t *testing.T) {
+	gm, code := seededGame(t, 7)
+	seats := seatsOf(t, gm, code)
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p5")
+
+	if after := seatsOf(t, gm, code); !reflect.DeepEqual(seats, after) {
+		t.Errorf("seats moved after a death: %v, then %v", seats, after)
+	}
+	// The dead keep their seat, so it still resolves to them
+	if id, err := gm.SeatPlayer(code, seats["p5"]); err != nil || id != "p5" {
+		t.Errorf("seat %d is %q (%v), want the dead p5", seats["p5"], id, err)
+	}
+}
+
+func TestSeatPlayerRejectsEmptySeats(t *testing.T) {
+	gm, code := seededGame(t, 7)
+
+	for _, seat := range []int{-1, 0, 9, 100} {
+		if _, err := gm.SeatPlayer(code, seat); !errors.Is(err, ErrInvalidSeat) {
+			t.Errorf("seat %d: err = %v, want ErrInvalidSeat", seat, err)
+		}
+	}
+	for id, seat := range seatsOf(t, gm, code) {
+		if got, err := gm.SeatPlayer(code, seat); err != nil || got != id {
+			t.Errorf("seat %d = %q (%v), want %s", seat, got, err, id)
+		}
+	}
+}
+
+func TestRematchDealsSeatsAgain(t *testing.T) {
+	gm, code := seededGame(t, 7)
+	first := seatsOf(t, gm, code)
+
+	reshuffled := false
+	for game := 0; game < 3 && !reshuffled; game++ {
+		withRoom(t, gm, code, func(room *models.GameRoom) {
+			room.WinningTeam = "human"
+			if err := gm.transitionLocked(room, models.PhaseEnded, ReasonGameOver); err != nil {
+				t.Fatalf("end the game: %v", err)
+			}
+			for _, player := range room.Players {
+				player.IsConnected = true
+			}
+		})
+		if _, err := gm.Rematch(code, "p1"); err != nil {
+			t.Fatalf("rematch: %v", err)
+		}
+		for id, seat := range seatsOf(t, gm, code) {
+			if seat != 0 {
+				t.Fatalf("%s kept seat %d in the lobby", id, seat)
+			}
+		}
+
+		startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+		reshuffled = !reflect.DeepEqual(first, seatsOf(t, gm, code))
+	}
+	if !reshuffled {
+		t.Error("three rematches dealt the first game's seats every time")
+	}
+}
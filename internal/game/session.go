@@ -0,0 +1,68 @@
+package game
+
+import (
+	"crypto/subtle"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// ErrInvalidSession is returned when a request acting as a player does not
+// carry that player's session token
+var ErrInvalidSession = &errs.Error{Code: "INVALID_SESSION", Message: "invalid session"}
+
+// newSessionToken mints a player's session secret. Player IDs are public,
+// since every view lists them, so only this proves who is asking.
+func newSessionToken() string {
+	return uuid.New().String()
+}
+
+// Authenticate checks that token is the session of the player in the room
+func (gm *GameManager) Authenticate(code models.RoomCode, playerID, token string) error {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	player := room.Players[playerID]
+	if player == nil || player.SessionToken == "" ||
+		subtle.ConstantTimeCompare([]byte(player.SessionToken), []byte(token)) != 1 {
+		return ErrInvalidSession
+	}
+	return nil
+}
+
+// SessionToken returns the player's session token, to hand to the player
+// who just joined
+func (gm *GameManager) SessionToken(code models.RoomCode, playerID string) (string, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return "", false
+	}
+
+	player := room.Players[playerID]
+	if player == nil {
+		return "", false
+	}
+	return player.SessionToken, true
+}
+
+// JoinRequestSession checks the requester's token against a join request
+// and returns the request
+func (gm *GameManager) JoinRequestSession(code models.RoomCode, requestID, token string) (models.JoinRequest, error) {
+	request, err := gm.JoinRequestStatus(code, requestID)
+	if err != nil {
+		return request, err
+	}
+	if subtle.ConstantTimeCompare([]byte(request.SessionToken), []byte(token)) != 1 {
+		return models.JoinRequest{}, ErrInvalidSession
+	}
+	return request, nil
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// sessionsOf returns every player's session token
+func sessionsOf(t *testing.T, gm *GameManager, code models.RoomCode) map[string]string {
+	t.Helper()
+
+	sessions := make(map[string]string)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for id, player := range room.Players {
+			sessions[id] = player.SessionToken
+		}
+	})
+	return sessions
+}
+
+func TestAuthenticate(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 3, nil)
+	sessions := sessionsOf(t, gm, code)
+
+	seen := make(map[string]bool)
+	for id, token := range sessions {
+		if token == "" || token == id || seen[token] {
+			t.Fatalf("%s has session %q", id, token)
+		}
+		seen[token] = true
+
+		if err := gm.Authenticate(code, id, token); err != nil {
+			t.Errorf("%s with their own session: %v", id, err)
+		}
+	}
+
+	for name, attempt := range map[string]struct{ playerID, token string }{
+		"no token":               {"p1", ""},
+		"the player ID":          {"p1", "p1"},
+		"another player's token": {"p1", sessions["p2"]},
+		"a stranger":             {"stranger", sessions["p1"]},
+	} {
+		if err := gm.Authenticate(code, attempt.playerID, attempt.token); !errors.Is(err, ErrInvalidSession) {
+			t.Errorf("%s: err = %v, want ErrInvalidSession", name, err)
+		}
+	}
+
+	if err := gm.Authenticate("NOROOM", "p1", sessions["p1"]); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("missing room: err = %v, want ErrRoomNotFound", err)
+	}
+}
+
+func TestSessionTokensAreRandom(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		token := newSessionToken()
+		parsed, err := uuid.Parse(token)
+		if err != nil || parsed.Version() != 4 || parsed.Variant() != uuid.RFC4122 {
+			t.Fatalf("session %q is not a random UUID", token)
+		}
+		if seen[token] {
+			t.Fatalf("session %q minted twice", token)
+		}
+		seen[token] = true
+	}
+
+	// The same players in another room are handed other sessions, so
+	// nothing about the player predicts theirs
+	first, second := NewGameManager(), NewGameManager()
+	firstSessions := sessionsOf(t, first, newTestRoom(t, first, 6, nil))
+	secondSessions := sessionsOf(t, second, newTestRoom(t, second, 6, nil))
+	for id, token := range firstSessions {
+		if token == secondSessions[id] || strings.Contains(token, id) {
+			t.Errorf("%s has session %q in one room and %q in the other", id, token, secondSessions[id])
+		}
+	}
+}
+
+func TestSessionsStayOutOfViews(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger})
+
+	for viewer, token := range sessionsOf(t, gm, code) {
+		view, _ := gm.RoomView(code, viewer)
+		data, err := json.Marshal(models.NewRoomForViewer(view))
+		if err != nil {
+			t.Fatalf("marshal %s's view: %v", viewer, err)
+		}
+		for id, player := range view.Players {
+			if player.SessionToken != "" {
+				t.Errorf("%s's view carries %s's session", viewer, id)
+			}
+		}
+		if strings.Contains(string(data), token) {
+			t.Errorf("%s's own session is in their view", viewer)
+		}
+	}
+}
+
+func TestApprovedGuestKeepsTheRequestSession(t *testing.T) {
+	gm, code := knockRoom(t, 3)
+	request := knock(t, gm, code, "guest")
+	if request.SessionToken == "" {
+		t.Fatal("the request has no session")
+	}
+
+	if _, err := gm.JoinRequestSession(code, request.ID, "guest"); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("polling with the player ID: err = %v, want ErrInvalidSession", err)
+	}
+	if _, err := gm.RespondJoinRequest(code, "p1", request.ID, true); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	polled, err := gm.JoinRequestSession(code, request.ID, request.SessionToken)
+	if err != nil || polled.Status != models.JoinApproved {
+		t.Fatalf("poll: status %s, err %v", polled.Status, err)
+	}
+	if err := gm.Authenticate(code, "guest", request.SessionToken); err != nil {
+		t.Errorf("the approved guest's session: %v", err)
+	}
+}
+
+func TestFixtureKeepsSessions(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 4, nil)
+	sessions := sessionsOf(t, gm, code)
+
+	data, err := gm.RecordFixture(code)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	restored := NewGameManager()
+	if _, err := restored.LoadFixture(data); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for id, token := range sessions {
+		if err := restored.Authenticate(code, id, token); err != nil {
+			t.Errorf("%s's session after a round trip: %v", id, err)
+		}
+	}
+}
+
+func TestOldFixturesMintSessions(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 4, nil)
+	sessions := sessionsOf(t, gm, code)
+
+	data, err := gm.RecordFixture(code)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	var fixture map[string]interface{}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	fixture["version"] = 6
+	delete(fixture, "sessions")
+	if data, err = json.Marshal(fixture); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	restored := NewGameManager()
+	if _, err := restored.LoadFixture(data); err != nil {
+		t.Fatalf("load a version 6 fixture: %v", err)
+	}
+	for id, token := range sessionsOf(t, restored, code) {
+		if token == "" || token == sessions[id] {
+			t.Errorf("%s has session %q after migrating", id, token)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Room sizes the role table supports
+const (
+	minPlayers      = 5
+	maxPlayersLimit = 20
+)
+
+// DefaultSettingsAckCooldown is how long StartGame waits after a settings
+// change for players who have not acknowledged it
+const DefaultSettingsAckCooldown = 5 * time.Second
+
+// UpdateSettings replaces the room settings (host only, before the game starts)
+func (gm *GameManager) UpdateSettings(code models.RoomCode, playerID string, settings models.RoomSettings) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if err := settingsEditorLocked(room, playerID); err != nil {
+		return err
+	}
+
+	if settings.MinDiscussionSeconds < 0 {
+		return invalidSetting("minDiscussionSeconds", nil, "minimum discussion time cannot be negative")
+	}
+
+	if settings.ReadyToVoteFraction < 0 || settings.ReadyToVoteFraction > 1 {
+		return invalidSetting("readyToVoteFraction", nil, "ready-to-vote fraction must be between 0 and 1")
+	}
+
+	if settings.QuorumFraction < 0 || settings.QuorumFraction > 1 {
+		return invalidSetting("quorumFraction", nil, "quorum fraction must be between 0 and 1")
+	}
+
+	if settings.VoteLockSeconds < 0 {
+		return invalidSetting("voteLockSeconds", nil, "vote lock time cannot be negative")
+	}
+
+	if settings.AutoStartSeconds < 0 {
+		return invalidSetting("autoStartSeconds", nil, "auto-start countdown cannot be negative")
+	}
+
+	if settings.MysteryDawnRevealSeconds < 0 {
+		return invalidSetting("mysteryDawnRevealSeconds", nil, "body reveal time cannot be negative")
+	}
+
+	if settings.AbortApprovals < 0 {
+		return invalidSetting("abortApprovals", nil, "abort approvals cannot be negative")
+	}
+
+	switch settings.VoteTieBreak {
+	case "", models.TieBreakRandom, models.TieBreakNone:
+	default:
+		return invalidSetting("voteTieBreak", map[string]interface{}{"value": settings.VoteTieBreak},
+			fmt.Sprintf("unknown vote tie-break %q", settings.VoteTieBreak))
+	}
+
+	switch settings.Hints {
+	case "", models.HintsNone, models.HintsBasic:
+	default:
+		return invalidSetting("hints", map[string]interface{}{"value": settings.Hints},
+			fmt.Sprintf("unknown hint level %q", settings.Hints))
+	}
+
+	for role, cooldown := range settings.NightCooldowns {
+		if nightAbility(role) == "" {
+			return invalidSetting("nightCooldowns", map[string]interface{}{"role": role},
+				fmt.Sprintf("role %q has no night ability to cool down", role))
+		}
+		if cooldown < models.CooldownForever {
+			return invalidSetting("nightCooldowns", map[string]interface{}{"role": role, "value": cooldown},
+				fmt.Sprintf("invalid cooldown %d for role %q", cooldown, role))
+		}
+	}
+
+	for _, key := range settings.NightModifiers {
+		if _, known := nightModifiers[key]; !known {
+			return invalidSetting("nightModifiers", map[string]interface{}{"value": key},
+				fmt.Sprintf("unknown night modifier %q", key))
+		}
+	}
+
+	// Practice mode is fixed when the room is created
+	settings.PracticeMode = room.Settings.PracticeMode
+
+	if settings.MaxPlayers == 0 {
+		settings.MaxPlayers = room.MaxPlayers
+	}
+	if err := checkMaxPlayersLocked(room, settings.MaxPlayers); err != nil {
+		return err
+	}
+
+	// The composition is checked for shape only; it must match the player
+	// count by the time the game starts
+	if len(settings.Composition) > 0 {
+		if err := checkCompositionShape(settings.Composition, settings.MaxPlayers); err != nil {
+			return err
+		}
+	}
+
+	room.Settings = settings
+	room.MaxPlayers = settings.MaxPlayers
+	markSettingsChangedLocked(room, playerID, time.Now())
+	gm.recordHostActionLocked(room, playerID, HostActionChangeSettings, "", "")
+
+	return nil
+}
+
+// markSettingsChangedLocked makes every player acknowledge the settings
+// afresh; the player who changed them has seen them already
+func markSettingsChangedLocked(room *models.GameRoom, playerID string, now time.Time) {
+	room.SettingsChangedAt = models.TimestampPtr(now)
+	room.SettingsAcks = map[string]bool{playerID: true}
+}
+
+// invalidSetting is a settings value UpdateSettings refuses; field is its
+// JSON name
+func invalidSetting(field string, params map[string]interface{}, message string) *errs.Error {
+	all := map[string]interface{}{"field": field}
+	for k, v := range params {
+		all[k] = v
+	}
+	return &errs.Error{Code: "INVALID_SETTING", Params: all, Message: message}
+}
+
+// checkMaxPlayersLocked rejects a player cap the roles cannot fill or that
+// would leave players already in the room over it
+func checkMaxPlayersLocked(room *models.GameRoom, maxPlayers int) error {
+	if maxPlayers < minPlayers || maxPlayers > maxPlayersLimit {
+		return &errs.Error{
+			Code:    "MAX_PLAYERS_OUT_OF_RANGE",
+			Params:  map[string]interface{}{"min": minPlayers, "max": maxPlayersLimit},
+			Message: fmt.Sprintf("max players must be between %d and %d", minPlayers, maxPlayersLimit),
+		}
+	}
+
+	if excess := len(room.Players) - maxPlayers; excess > 0 {
+		return &errs.Error{
+			Code:    "MAX_PLAYERS_BELOW_COUNT",
+			Params:  map[string]interface{}{"players": len(room.Players), "excess": excess},
+			Message: fmt.Sprintf("room already has %d players, %d more than %d", len(room.Players), excess, maxPlayers),
+		}
+	}
+
+	return nil
+}
+
+// AckSettings records that a player has seen the latest settings change
+func (gm *GameManager) AckSettings(code models.RoomCode, playerID string) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if room.Players[playerID] == nil {
+		return ErrPlayerNotFound
+	}
+
+	if room.SettingsAcks != nil {
+		room.SettingsAcks[playerID] = true
+	}
+
+	return nil
+}
+
+// settingsEditorLocked checks that the player may change the room's settings now
+func settingsEditorLocked(room *models.GameRoom, playerID string) error {
+	if err := authorizeLocked(room, playerID, PermChangeSettings); err != nil {
+		return err
+	}
+
+	if room.Phase != models.PhaseWaiting {
+		return ErrGameAlreadyStarted
+	}
+	return nil
+}
+
+// settingsGuardLocked blocks starting right after a settings change until
+// every connected player has acknowledged it or the cooldown has passed
+func settingsGuardLocked(room *models.GameRoom, cooldown time.Duration, now time.Time) error {
+	if cooldown <= 0 || room.SettingsChangedAt == nil {
+		return nil
+	}
+
+	remaining := room.SettingsChangedAt.Add(cooldown).Sub(now)
+	if remaining <= 0 {
+		return nil
+	}
+
+	acked := true
+	for _, player := range room.Players {
+		if player.IsConnected && !player.IsBot && !room.SettingsAcks[player.ID] {
+			acked = false
+			break
+		}
+	}
+	if acked {
+		return nil
+	}
+
+	seconds := int(math.Ceil(remaining.Seconds()))
+	return &errs.Error{
+		Code:    "SETTINGS_UNACKNOWLEDGED",
+		Params:  map[string]interface{}{"remainingSeconds": seconds},
+		Message: fmt.Sprintf("settings just changed: %d seconds until start", seconds),
+	}
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// changedSettingsRoom is a lobby of six, all connected, whose host has just
+// changed the settings
+func changedSettingsRoom(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	gm.SettingsAckCooldown = DefaultSettingsAckCooldown
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for _, player := range room.Players {
+			player.IsConnected = true
+		}
+	})
+	changeSettings(t, gm, code)
+	return gm, code
+}
+
+// changeSettings has the host save the settings again
+func changeSettings(t *testing.T, gm *GameManager, code models.RoomCode) {
+	t.Helper()
+
+	var settings models.RoomSettings
+	withRoom(t, gm, code, func(room *models.GameRoom) { settings = room.Settings })
+	settings.VoteLockSeconds++
+	if err := gm.UpdateSettings(code, "p1", settings); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+}
+
+// ageSettingsChange moves the last settings change back by d
+func ageSettingsChange(t *testing.T, gm *GameManager, code models.RoomCode, d time.Duration) {
+	t.Helper()
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.SettingsChangedAt = models.TimestampPtr(room.SettingsChangedAt.Add(-d))
+	})
+}
+
+// wantUnacknowledged fails unless starting is refused with the given wait
+func wantUnacknowledged(t *testing.T, gm *GameManager, code models.RoomCode, seconds int) {
+	t.Helper()
+
+	err := gm.StartGame(code, "p1")
+	var coded *errs.Error
+	if !errors.As(err, &coded) || coded.Code != "SETTINGS_UNACKNOWLEDGED" || coded.Params["remainingSeconds"] != seconds {
+		t.Fatalf("start: %v, want SETTINGS_UNACKNOWLEDGED with %d seconds remaining", err, seconds)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseWaiting {
+		t.Fatalf("phase = %s, want the lobby", phase)
+	}
+}
+
+func TestStartWaitsOutTheSettingsCooldown(t *testing.T) {
+	gm, code := changedSettingsRoom(t)
+	wantUnacknowledged(t, gm, code, 5)
+
+	ageSettingsChange(t, gm, code, 3*time.Second)
+	wantUnacknowledged(t, gm, code, 2)
+
+	// Once the cooldown is over nobody's ack is needed
+	ageSettingsChange(t, gm, code, 2*time.Second)
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Fatalf("start after the cooldown: %v", err)
+	}
+}
+
+func TestStartOnceEveryoneAcked(t *testing.T) {
+	gm, code := changedSettingsRoom(t)
+	for _, id := range []string{"p2", "p3", "p4", "p5"} {
+		if err := gm.AckSettings(code, id); err != nil {
+			t.Fatalf("ack %s: %v", id, err)
+		}
+	}
+	wantUnacknowledged(t, gm, code, 5)
+
+	if err := gm.AckSettings(code, "p6"); err != nil {
+		t.Fatalf("ack p6: %v", err)
+	}
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Fatalf("start with every ack in: %v", err)
+	}
+}
+
+func TestAbsentPlayersNeedNotAck(t *testing.T) {
+	gm, code := changedSettingsRoom(t)
+	for _, id := range []string{"p2", "p3", "p4"} {
+		gm.AckSettings(code, id)
+	}
+	// p5 has left and p6 is a bot; neither can acknowledge anything
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.Players["p5"].IsConnected = false
+		room.Players["p6"].IsBot = true
+	})
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+}
+
+func TestSettingsChangesStack(t *testing.T) {
+	gm, code := changedSettingsRoom(t)
+	for _, id := range []string{"p2", "p3", "p4", "p5", "p6"} {
+		gm.AckSettings(code, id)
+	}
+	ageSettingsChange(t, gm, code, 4*time.Second)
+
+	// A second change voids the acks and restarts the cooldown from itself
+	changeSettings(t, gm, code)
+	wantUnacknowledged(t, gm, code, 5)
+	ageSettingsChange(t, gm, code, 4*time.Second)
+	wantUnacknowledged(t, gm, code, 1)
+
+	if err := gm.AckSettings(code, "nobody"); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("ack from outside the room: %v, want %s", err, ErrPlayerNotFound.Code)
+	}
+}
+
+func TestMaxPlayersBelowTheRoom(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+
+	var settings models.RoomSettings
+	withRoom(t, gm, code, func(room *models.GameRoom) { settings = room.Settings })
+	settings.MaxPlayers = 6
+	err := gm.UpdateSettings(code, "p1", settings)
+	var coded *errs.Error
+	if !errors.As(err, &coded) || coded.Code != "MAX_PLAYERS_BELOW_COUNT" ||
+		coded.Params["players"] != 8 || coded.Params["excess"] != 2 {
+		t.Fatalf("cap of 6 for 8 players: %v, want MAX_PLAYERS_BELOW_COUNT with 2 excess", err)
+	}
+
+	// Exactly the room's size is still allowed, and then it is full
+	settings.MaxPlayers = 8
+	if err := gm.UpdateSettings(code, "p1", settings); err != nil {
+		t.Fatalf("cap of 8 for 8 players: %v", err)
+	}
+	if _, err := gm.JoinRoom(code, "p9", "Player9"); !errors.Is(err, ErrRoomFull) {
+		t.Errorf("join past the cap: %v, want %s", err, ErrRoomFull.Code)
+	}
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// nightSleepTimeout is how long a sleep-confirmation night waits before
+// the remaining players are put to sleep automatically
+const nightSleepTimeout = 90 * time.Second
+
+// ErrSleepConfirmationDisabled is returned for a sleep acknowledgment in a
+// room without nightSleepConfirmation
+var ErrSleepConfirmationDisabled = &errs.Error{Code: "SLEEP_CONFIRMATION_DISABLED", Message: "sleep confirmation is disabled"}
+
+// Sleep acknowledges the night for a player in nightSleepConfirmation mode.
+// Players without a turn use it as their dummy action; anyone may send it.
+// It reports whether this acknowledgment completed the night.
+func (gm *GameManager) Sleep(code models.RoomCode, playerID string) (bool, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return false, gm.missingRoomLocked(code)
+	}
+
+	if !room.Settings.NightSleepConfirmation {
+		return false, ErrSleepConfirmationDisabled
+	}
+
+	if room.Phase != models.PhaseNight {
+		return false, ErrNotNightPhase
+	}
+
+	player := room.Players[playerID]
+	if player == nil || !player.IsAlive {
+		return false, ErrCannotAct
+	}
+
+	if err := duplicateActionLocked(room, player, models.ActionSleep, ""); err != nil {
+		return false, err
+	}
+
+	recordActionLocked(room, player, models.ActionSleep, nil)
+	acknowledgeNightLocked(room, player)
+
+	return room.CurrentNightRole == "" && nightAcknowledgedLocked(room), nil
+}
+
+// resetNightAcknowledgementsLocked starts a fresh night; bots never need to confirm
+func resetNightAcknowledgementsLocked(room *models.GameRoom) {
+	room.NightAcknowledged = make(map[string]bool)
+	for _, player := range room.LivingPlayers() {
+		if player.IsBot {
+			acknowledgeNightLocked(room, player)
+		}
+	}
+}
+
+// acknowledgeNightLocked marks the player as asleep when the mode is on
+func acknowledgeNightLocked(room *models.GameRoom, player *models.Player) {
+	if !room.Settings.NightSleepConfirmation {
+		return
+	}
+	if room.NightAcknowledged == nil {
+		room.NightAcknowledged = make(map[string]bool)
+	}
+	room.NightAcknowledged[player.ID] = true
+}
+
+// nightAcknowledgedLocked reports whether every living player is asleep.
+// It is always true when the mode is off.
+func nightAcknowledgedLocked(room *models.GameRoom) bool {
+	if !room.Settings.NightSleepConfirmation {
+		return true
+	}
+	for _, player := range room.LivingPlayers() {
+		if !room.NightAcknowledged[player.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// autoSleepLocked puts every living player who has not confirmed to sleep,
+// recording the acknowledgment as timed out
+func autoSleepLocked(room *models.GameRoom) {
+	if !room.Settings.NightSleepConfirmation {
+		return
+	}
+	for _, player := range room.LivingPlayers() {
+		if room.NightAcknowledged[player.ID] {
+			continue
+		}
+		recordActionLocked(room, player, models.ActionSleep, nil)
+		player.ActionHistory[len(player.ActionHistory)-1].Outcome = "timed_out"
+		acknowledgeNightLocked(room, player)
+	}
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// sleepGame starts a six-player game and votes p6 out, so the next phase
+// is the first night with a tiger, hunter, shaman and two villagers alive
+func sleepGame(t *testing.T, sleepConfirmation bool) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.NightSleepConfirmation = sleepConfirmation
+	})
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleShaman,
+	})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p6")
+	return gm, code
+}
+
+// wireView is the viewer's view of the room exactly as they would be sent it
+func wireView(t *testing.T, gm *GameManager, code models.RoomCode, viewerID string) string {
+	t.Helper()
+
+	view, ok := gm.RoomView(code, viewerID)
+	if !ok {
+		t.Fatalf("room %s not found", code)
+	}
+	data, err := json.Marshal(models.NewRoomWire(view))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSleepConfirmationHidesWhoActed(t *testing.T) {
+	gm, code := sleepGame(t, true)
+
+	// The villagers, the public and the shaman waiting for their own turn
+	viewers := []string{"", "p3", "p4", "p5"}
+	before := make(map[string]string)
+	for _, id := range viewers {
+		before[id] = wireView(t, gm, code, id)
+	}
+
+	prompts, _ := gm.CurrentTurnPrompts(code)
+	if err := gm.PerformNightAction(code, "p2", "p4", prompts["p2"].TurnToken); err != nil {
+		t.Fatalf("hunter protects: %v", err)
+	}
+	if done, err := gm.MoveToNextNightRole(code); err != nil || done {
+		t.Fatalf("next night role: done = %v, err = %v", done, err)
+	}
+
+	for _, id := range viewers {
+		if after := wireView(t, gm, code, id); after != before[id] {
+			t.Errorf("viewer %q can tell the hunter acted:\nbefore %s\nafter  %s", id, before[id], after)
+		}
+	}
+
+	// The hunter still sees that they are done, and the tiger whose turn it is sees it
+	hunter, _ := gm.RoomView(code, "p2")
+	if !hunter.Players["p2"].HasActedThisNight || !hunter.NightActionsCompleted["p2"] {
+		t.Error("the hunter's own view lost their action")
+	}
+	if tiger, _ := gm.RoomView(code, "p1"); tiger.CurrentNightRole != models.RoleTiger || tiger.TurnEndTime == nil {
+		t.Errorf("the tiger holding the turn sees role %q", tiger.CurrentNightRole)
+	}
+}
+
+func TestNightWaitsForLastSleeper(t *testing.T) {
+	gm, code := sleepGame(t, true)
+
+	if done := playTurns(t, gm, code, map[string]string{"p1": "p4", "p2": "p5", "p3": "p1"}); done {
+		t.Fatal("the night was over before the villagers slept")
+	}
+	if done, err := gm.Sleep(code, "p4"); err != nil || done {
+		t.Fatalf("first villager sleeps: done = %v, err = %v", done, err)
+	}
+	if done, _ := gm.MoveToNextNightRole(code); done {
+		t.Fatal("the night was over with a villager still awake")
+	}
+	if done, err := gm.Sleep(code, "p5"); err != nil || !done {
+		t.Fatalf("last villager sleeps: done = %v, err = %v", done, err)
+	}
+}
+
+func TestNightTimeoutPutsEveryoneToSleep(t *testing.T) {
+	gm, code := sleepGame(t, true)
+
+	playTurns(t, gm, code, map[string]string{"p1": "p4", "p2": "p5", "p3": "p1"})
+	if _, err := gm.Sleep(code, "p4"); err != nil {
+		t.Fatalf("p4 sleeps: %v", err)
+	}
+	nextPhase(t, gm, code) // the night's deadline passed
+
+	view, _ := gm.RoomView(code, "")
+	if view.Phase != models.PhaseDay {
+		t.Fatalf("night ended in %s, want day", view.Phase)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		last := func(id string) models.ActionRecord {
+			history := room.Players[id].ActionHistory
+			return history[len(history)-1]
+		}
+		if record := last("p5"); record.ActionType != models.ActionSleep || record.Outcome != "timed_out" {
+			t.Errorf("p5's last action = %+v, want a timed out sleep", record)
+		}
+		if record := last("p4"); record.ActionType != models.ActionSleep || record.Outcome != "" {
+			t.Errorf("p4's last action = %+v, want their own sleep", record)
+		}
+	})
+}
+
+func TestNightWithoutSleepConfirmationIsUnchanged(t *testing.T) {
+	gm, code := sleepGame(t, false)
+
+	if _, err := gm.Sleep(code, "p4"); err == nil {
+		t.Error("sleep was accepted with the mode off")
+	}
+
+	prompts, _ := gm.CurrentTurnPrompts(code)
+	if err := gm.PerformNightAction(code, "p2", "p4", prompts["p2"].TurnToken); err != nil {
+		t.Fatalf("hunter protects: %v", err)
+	}
+	if done, _ := gm.MoveToNextNightRole(code); done {
+		t.Fatal("the night was over after the first turn")
+	}
+
+	// The turn moving on stays visible; who finished it would name a night
+	// role, so only the hunter is told
+	view, _ := gm.RoomView(code, "p4")
+	if view.NightActionsCompleted["p2"] || view.Players["p2"].HasActedThisNight {
+		t.Error("a villager can tell who the hunter is")
+	}
+	if hunter, _ := gm.RoomView(code, "p2"); !hunter.NightActionsCompleted["p2"] || !hunter.Players["p2"].HasActedThisNight {
+		t.Error("the hunter's own view lost their action")
+	}
+	if view.CurrentNightRole != models.RoleTiger {
+		t.Errorf("current night role = %q, want tiger", view.CurrentNightRole)
+	}
+
+	if done := playTurns(t, gm, code, map[string]string{"p1": "p4", "p3": "p1"}); !done {
+		t.Error("the night waited for the villagers")
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// maxSlowMode caps the interval slow mode can enforce
+const maxSlowMode = 5 * time.Minute
+
+// SetSlowMode makes every player wait the given number of seconds between
+// chat messages in the same channel; 0 turns slow mode off. The host and
+// moderators are exempt.
+func (gm *GameManager) SetSlowMode(code models.RoomCode, actorID string, seconds int) error {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return gm.missingRoomLocked(code)
+	}
+
+	if err := authorizeLocked(room, actorID, PermSlowMode); err != nil {
+		return err
+	}
+
+	if seconds < 0 || time.Duration(seconds)*time.Second > maxSlowMode {
+		return &errs.Error{
+			Code:    "INVALID_SLOW_MODE",
+			Params:  map[string]interface{}{"max": int(maxSlowMode.Seconds())},
+			Message: fmt.Sprintf("slow mode must be between 0 and %d seconds", int(maxSlowMode.Seconds())),
+		}
+	}
+
+	if seconds == room.SlowModeSeconds {
+		return nil
+	}
+
+	room.SlowModeSeconds = seconds
+	gm.recordHostActionLocked(room, actorID, HostActionSlowMode, "", strconv.Itoa(seconds))
+
+	return nil
+}
+
+// SlowMode returns the room's slow mode interval in seconds, 0 when it is off
+func (gm *GameManager) SlowMode(code models.RoomCode) int {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return 0
+	}
+	return room.SlowModeSeconds
+}
+
+// slowModeKey identifies a player's messages in one chat channel
+func slowModeKey(kind, playerID string) string {
+	return kind + ":" + playerID
+}
+
+// checkSlowModeLocked refuses a message sent before the player's slow mode
+// interval in that channel is up
+func checkSlowModeLocked(room *models.GameRoom, playerID, kind string, now time.Time) error {
+	if room.SlowModeSeconds <= 0 || exemptFromSlowModeLocked(room, playerID) {
+		return nil
+	}
+
+	last, sent := room.LastChatAt[slowModeKey(kind, playerID)]
+	if !sent {
+		return nil
+	}
+
+	remaining := last.Add(time.Duration(room.SlowModeSeconds) * time.Second).Sub(now)
+	if remaining <= 0 {
+		return nil
+	}
+
+	seconds := int(math.Ceil(remaining.Seconds()))
+	return &errs.Error{
+		Code:    "SLOW_MODE",
+		Params:  map[string]interface{}{"remainingSeconds": seconds},
+		Message: fmt.Sprintf("slow mode is on: wait %d seconds", seconds),
+	}
+}
+
+// noteChatSentLocked starts the player's slow mode interval in the channel
+func noteChatSentLocked(room *models.GameRoom, playerID, kind string, at time.Time) {
+	if room.LastChatAt == nil {
+		room.LastChatAt = make(map[string]time.Time)
+	}
+	room.LastChatAt[slowModeKey(kind, playerID)] = at
+}
+
+// exemptFromSlowModeLocked reports whether the player keeps order in the room
+// and so is not slowed down
+func exemptFromSlowModeLocked(room *models.GameRoom, playerID string) bool {
+	if room.HostID == playerID {
+		return true
+	}
+	player := room.Players[playerID]
+	return player != nil && player.IsModerator
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// slowRoom is a lobby of six with slow mode at ten seconds
+func slowRoom(t *testing.T) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	if err := gm.SetSlowMode(code, "p1", 10); err != nil {
+		t.Fatalf("slow mode: %v", err)
+	}
+	return gm, code
+}
+
+// rewindChat moves the player's last message back by d
+func rewindChat(t *testing.T, gm *GameManager, code models.RoomCode, playerID string, d time.Duration) {
+	t.Helper()
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for key, at := range room.LastChatAt {
+			if strings.HasSuffix(key, ":"+playerID) {
+				room.LastChatAt[key] = at.Add(-d)
+			}
+		}
+	})
+}
+
+func TestSlowModeWindow(t *testing.T) {
+	gm, code := slowRoom(t)
+	if _, err := gm.PostChat(code, "p2", "first"); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+
+	_, err := gm.PostChat(code, "p2", "too soon")
+	var coded *errs.Error
+	if !errors.As(err, &coded) || coded.Code != "SLOW_MODE" {
+		t.Fatalf("second message: err = %v, want SLOW_MODE", err)
+	}
+	if wait, _ := coded.Params["remainingSeconds"].(int); wait < 1 || wait > 10 {
+		t.Errorf("told to wait %v seconds, want 1 to 10", coded.Params["remainingSeconds"])
+	}
+
+	// Another player has a window of their own
+	if _, err := gm.PostChat(code, "p3", "hello"); err != nil {
+		t.Errorf("p3 was held back by p2's message: %v", err)
+	}
+
+	rewindChat(t, gm, code, "p2", 9*time.Second)
+	if _, err := gm.PostChat(code, "p2", "nearly"); err == nil {
+		t.Error("a message a second early got through")
+	}
+	rewindChat(t, gm, code, "p2", time.Second)
+	if _, err := gm.PostChat(code, "p2", "now"); err != nil {
+		t.Errorf("a message after the window: %v", err)
+	}
+}
+
+func TestSlowModeExemptsHostAndModerators(t *testing.T) {
+	gm, code := slowRoom(t)
+	if err := gm.SetModerator(code, "p1", "p2", true); err != nil {
+		t.Fatalf("grant moderator: %v", err)
+	}
+
+	for _, id := range []string{"p1", "p2"} {
+		for i := 0; i < 3; i++ {
+			if _, err := gm.PostChat(code, id, "order, please"); err != nil {
+				t.Fatalf("%s message %d: %v", id, i, err)
+			}
+		}
+	}
+}
+
+func TestSlowModeIsAnnouncedAndShown(t *testing.T) {
+	gm, code := slowRoom(t)
+
+	notices := gm.TakeSystemNotices(code)
+	if len(notices) == 0 || !strings.Contains(notices[len(notices)-1].Content, "one message every 10 seconds") {
+		t.Errorf("notices = %+v, want slow mode announced", notices)
+	}
+	if view, _ := gm.RoomView(code, "p3"); view.SlowModeSeconds != 10 {
+		t.Errorf("the room view shows slow mode at %d", view.SlowModeSeconds)
+	}
+
+	if err := gm.SetSlowMode(code, "p1", 0); err != nil {
+		t.Fatalf("turn off slow mode: %v", err)
+	}
+	if notices := gm.TakeSystemNotices(code); len(notices) == 0 || !strings.Contains(notices[len(notices)-1].Content, "turned off slow mode") {
+		t.Errorf("notices = %+v, want slow mode turned off", notices)
+	}
+}
+
+func TestSlowModeIsTheHostsToSet(t *testing.T) {
+	gm, code := slowRoom(t)
+
+	if err := gm.SetSlowMode(code, "p2", 0); err == nil {
+		t.Error("a player turned slow mode off")
+	}
+	if err := gm.SetSlowMode(code, "p1", int(maxSlowMode/time.Second)+1); err == nil {
+		t.Error("slow mode was set past its limit")
+	}
+	if seconds := gm.SlowMode(code); seconds != 10 {
+		t.Errorf("slow mode = %d after refused changes", seconds)
+	}
+}
+
+func TestRematchEndsSlowMode(t *testing.T) {
+	gm, code := slowRoom(t)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.WinningTeam = "human"
+		if err := gm.transitionLocked(room, models.PhaseEnded, ReasonGameOver); err != nil {
+			t.Fatalf("end the game: %v", err)
+		}
+		for _, player := range room.Players {
+			player.IsConnected = true
+		}
+	})
+	if _, err := gm.Rematch(code, "p1"); err != nil {
+		t.Fatalf("rematch: %v", err)
+	}
+
+	if seconds := gm.SlowMode(code); seconds != 0 {
+		t.Errorf("slow mode = %d after the rematch", seconds)
+	}
+	gm.PostChat(code, "p2", "one")
+	if _, err := gm.PostChat(code, "p2", "two"); err != nil {
+		t.Errorf("a rematch still slowed chat: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestOnlyTheHostStarts(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+
+	if err := gm.StartGame(code, "p2"); !errors.Is(err, ErrNotPermitted) {
+		t.Fatalf("a guest started the game: %v, want %s", err, ErrNotPermitted.Code)
+	}
+	if err := gm.StartGame(code, "stranger"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("someone outside the room started the game: %v", err)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseWaiting {
+		t.Fatalf("phase = %s, want the lobby", phase)
+	}
+
+	// A moderator may run the room but not start it
+	if err := gm.SetModerator(code, "p1", "p2", true); err != nil {
+		t.Fatalf("make p2 a moderator: %v", err)
+	}
+	if err := gm.StartGame(code, "p2"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("a moderator started the game: %v", err)
+	}
+
+	if err := gm.StartGame(code, "p1"); err != nil {
+		t.Fatalf("the host's start: %v", err)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Errorf("phase = %s, want the first day", phase)
+	}
+}
+
+func TestStartHandsAGoneHostsLobbyOn(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 7, nil)
+	// The room is left as validation would flag it: a host who isn't there
+	gm.DebugValidate = false
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		joined := time.Now().Add(-time.Hour)
+		for _, id := range []string{"p4", "p3", "p2", "p5", "p6", "p7"} {
+			room.Players[id].JoinedAt = models.Timestamp{Time: joined}
+			joined = joined.Add(time.Minute)
+		}
+		room.RemovePlayer("p1")
+	})
+
+	err := gm.StartGame(code, "p2")
+	var coded *errs.Error
+	if !errors.Is(err, ErrHostReassigned) || !errors.As(err, &coded) || coded.Params["hostId"] != "p4" {
+		t.Fatalf("start with the host gone: %v, want %s naming p4", err, ErrHostReassigned.Code)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseWaiting {
+		t.Fatalf("the reassignment started the game: %s", phase)
+	}
+	if view, _ := gm.RoomView(code, ""); view.HostID != "p4" {
+		t.Errorf("host = %q, want the longest-standing player p4", view.HostID)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if problems := room.Validate(); len(problems) != 0 {
+			t.Errorf("the room is still inconsistent: %v", problems)
+		}
+	})
+
+	// The new host starts it on purpose; nobody else can
+	if err := gm.StartGame(code, "p2"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("a guest started the game after the reassignment: %v", err)
+	}
+	if err := gm.StartGame(code, "p4"); err != nil {
+		t.Fatalf("the new host's start: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+var ErrGameNotEnded = &errs.Error{Code: "GAME_NOT_ENDED", Message: "game has not ended"}
+
+// GameSummary is the final board of an ended game
+type GameSummary struct {
+	Code         models.RoomCode      `json:"code"`
+	WinningTeam  string               `json:"winningTeam"`
+	Rounds       int                  `json:"rounds"`
+	StartedAt    *models.Timestamp    `json:"startedAt,omitempty"`
+	Players      []SummaryPlayer      `json:"players"`
+	NightTraces  []models.NightTrace  `json:"nightTraces"`  // how each night resolved
+	VoteHistory  []models.Ballot      `json:"voteHistory"`  // who voted for whom every day
+	VoteOutcomes []models.VoteOutcome `json:"voteOutcomes"` // how each day's vote was settled, ties included
+	HostActions  []models.HostAction  `json:"hostActions"`  // every administrative action taken
+	Room         models.RoomPublic    `json:"room"`         // the full, unredacted final state
+}
+
+// SummaryPlayer is one player's role and fate
+type SummaryPlayer struct {
+	ID         string      `json:"id"`
+	Username   string      `json:"username"`
+	Role       models.Role `json:"role"`
+	IsAlive    bool        `json:"isAlive"`
+	DeathCause string      `json:"deathCause,omitempty"`
+	ColorSlot  int         `json:"colorSlot"`
+}
+
+// GameSummary returns the final board of an ended game. Anyone who knows the
+// code may see it: once the game is over nothing in it is secret.
+func (gm *GameManager) GameSummary(code models.RoomCode) (*GameSummary, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	if room.Phase != models.PhaseEnded {
+		return nil, ErrGameNotEnded
+	}
+
+	summary := &GameSummary{
+		Code:         room.Code,
+		WinningTeam:  room.WinningTeam,
+		Rounds:       room.Round,
+		StartedAt:    room.StartedAt,
+		Players:      make([]SummaryPlayer, 0, len(room.Players)),
+		Room:         models.NewRoomPublic(viewForPlayer(room, "")),
+		NightTraces:  append([]models.NightTrace(nil), room.NightTraces...),
+		VoteHistory:  append([]models.Ballot(nil), room.VoteHistory...),
+		VoteOutcomes: append([]models.VoteOutcome(nil), room.VoteOutcomes...),
+		HostActions:  append([]models.HostAction(nil), room.HostActionLog...),
+	}
+	for _, player := range room.Players {
+		summary.Players = append(summary.Players, SummaryPlayer{
+			ID:         player.ID,
+			Username:   player.Username,
+			Role:       player.Role,
+			IsAlive:    player.IsAlive,
+			DeathCause: player.DeathCause,
+			ColorSlot:  player.ColorSlot,
+		})
+	}
+	sort.Slice(summary.Players, func(i, j int) bool {
+		return summary.Players[i].ColorSlot < summary.Players[j].ColorSlot
+	})
+
+	return summary, nil
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// voteLeaders returns the candidates with the most votes in the tally, in
+// ID order, and that count. Votes for anyone no longer alive are ignored.
+func voteLeaders(room *models.GameRoom, tally map[string]int) ([]string, int) {
+	candidates := make([]string, 0, len(tally))
+	for id := range tally {
+		candidates = append(candidates, id)
+	}
+	sort.Strings(candidates)
+
+	var leaders []string
+	most := 0
+	for _, id := range candidates {
+		count := tally[id]
+		if player := room.Players[id]; player == nil || !player.IsAlive || count <= 0 {
+			continue
+		}
+		switch {
+		case count > most:
+			most = count
+			leaders = []string{id}
+		case count == most:
+			leaders = append(leaders, id)
+		}
+	}
+	return leaders, most
+}
+
+// settleVoteLocked decides whom the tally eliminates. A tie is broken by the
+// room's VoteTieBreak; a random break draws from the room's RNG, so a seeded
+// game settles the same tie the same way every time.
+func settleVoteLocked(room *models.GameRoom, tally map[string]int) models.VoteOutcome {
+	leaders, most := voteLeaders(room, tally)
+	outcome := models.VoteOutcome{Round: room.Round, Votes: most}
+
+	switch {
+	case len(leaders) == 1:
+		outcome.EliminatedID = leaders[0]
+
+	case len(leaders) > 1:
+		outcome.Tied = leaders
+		outcome.TieBreak = room.Settings.VoteTieBreak
+		if outcome.TieBreak != models.TieBreakNone {
+			outcome.TieBreak = models.TieBreakRandom
+			outcome.EliminatedID = leaders[roomRandLocked(room).Intn(len(leaders))]
+		}
+	}
+	return outcome
+}
+
+// recordVoteOutcomeLocked adds the outcome to the room's history, keeps it
+// for the vote result announcement and tells the room how a tie was broken
+func (gm *GameManager) recordVoteOutcomeLocked(room *models.GameRoom, outcome models.VoteOutcome) {
+	room.VoteOutcomes = append(room.VoteOutcomes, outcome)
+	room.LastVoteOutcome = &outcome
+
+	if len(outcome.Tied) == 0 {
+		return
+	}
+
+	names := make([]string, len(outcome.Tied))
+	for i, id := range outcome.Tied {
+		names[i] = traceName(room, id)
+	}
+	tied := strings.Join(names, ", ")
+
+	if outcome.EliminatedID == "" {
+		gm.systemNoticeLocked(room, fmt.Sprintf("%s tied for the most votes; no one is eliminated.", tied),
+			"vote.tie_no_elimination", time.Now())
+		return
+	}
+	gm.systemNoticeLocked(room, fmt.Sprintf("%s tied for the most votes; %s was drawn at random.", tied, traceName(room, outcome.EliminatedID)),
+		"vote.tie_random", time.Now())
+}
+
+// TakeVoteOutcome returns how the vote just resolved was settled and forgets
+// it, so each resolution is announced once
+func (gm *GameManager) TakeVoteOutcome(code models.RoomCode) *models.VoteOutcome {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil
+	}
+
+	outcome := room.LastVoteOutcome
+	room.LastVoteOutcome = nil
+	return outcome
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// tiedVote gives p5 and p6 four votes each, then resolves the vote
+func tiedVote(t *testing.T, gm *GameManager, code models.RoomCode) models.VoteOutcome {
+	t.Helper()
+
+	nextPhase(t, gm, code)
+	for _, voter := range []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8"} {
+		target := "p5"
+		if voter == "p4" || voter == "p5" || voter == "p7" || voter == "p8" {
+			target = "p6"
+		}
+		if err := gm.Vote(code, voter, target); err != nil {
+			t.Fatalf("%s votes for %s: %v", voter, target, err)
+		}
+	}
+	var outcome models.VoteOutcome
+	nextPhase(t, gm, code)
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if len(room.VoteOutcomes) == 0 {
+			t.Fatal("the vote left no outcome")
+		}
+		outcome = room.VoteOutcomes[len(room.VoteOutcomes)-1]
+	})
+	return outcome
+}
+
+func TestSeededTieBreakReproduces(t *testing.T) {
+	gm, code := seededGame(t, 42)
+	first := tiedVote(t, gm, code)
+	again, againCode := seededGame(t, 42)
+	replay := tiedVote(t, again, againCode)
+
+	if !reflect.DeepEqual(first.Tied, []string{"p5", "p6"}) || first.TieBreak != models.TieBreakRandom {
+		t.Fatalf("outcome = %+v, want p5 and p6 tied and broken at random", first)
+	}
+	if first.EliminatedID != replay.EliminatedID {
+		t.Errorf("the same seed eliminated %s, then %s", first.EliminatedID, replay.EliminatedID)
+	}
+	if view, _ := gm.RoomView(code, ""); view.Players[first.EliminatedID].IsAlive {
+		t.Errorf("the drawn %s is still alive", first.EliminatedID)
+	}
+}
+
+func TestTieWithoutBreakEliminatesNoOne(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, func(settings *models.RoomSettings) {
+		settings.VoteTieBreak = models.TieBreakNone
+	})
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	outcome := tiedVote(t, gm, code)
+
+	if outcome.EliminatedID != "" || outcome.TieBreak != models.TieBreakNone || len(outcome.Tied) != 2 {
+		t.Errorf("outcome = %+v, want the tie recorded and no one eliminated", outcome)
+	}
+	if view, _ := gm.RoomView(code, ""); len(view.LivingPlayers()) != 8 {
+		t.Errorf("%d players alive after an unbroken tie", len(view.LivingPlayers()))
+	}
+}
+
+func TestTieSetLeavesOutTheDead(t *testing.T) {
+	room := &models.GameRoom{
+		Players: map[string]*models.Player{
+			"p1": {ID: "p1", IsAlive: true},
+			"p2": {ID: "p2", IsAlive: true},
+			"p3": {ID: "p3", IsAlive: false},
+		},
+		Settings: models.RoomSettings{VoteTieBreak: models.TieBreakRandom},
+	}
+	outcome := settleVoteLocked(room, map[string]int{"p1": 2, "p2": 1, "p3": 2})
+
+	if outcome.EliminatedID != "p1" || len(outcome.Tied) != 0 {
+		t.Errorf("outcome = %+v, want p1 alone in the lead once the dead p3 is left out", outcome)
+	}
+}
+
+func TestRandomTieBreakIsUniform(t *testing.T) {
+	const trials = 3000
+	tied := []string{"p1", "p2", "p3"}
+	seeds := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	drawn := make(map[string]int)
+	for i := 0; i < trials; i++ {
+		room := &models.GameRoom{
+			Players:  make(map[string]*models.Player),
+			Settings: models.RoomSettings{VoteTieBreak: models.TieBreakRandom},
+			Seed:     seeds.Int63(),
+		}
+		tally := make(map[string]int)
+		for _, id := range tied {
+			room.Players[id] = &models.Player{ID: id, IsAlive: true}
+			tally[id] = 2
+		}
+		drawn[settleVoteLocked(room, tally).EliminatedID]++
+	}
+
+	// Each should be drawn a third of the time; the bounds are nearly six
+	// standard deviations wide
+	for _, id := range tied {
+		if n := drawn[id]; n < trials/3-150 || n > trials/3+150 {
+			t.Errorf("%s was drawn %d times in %d, want about %d", id, n, trials, trials/3)
+		}
+	}
+}
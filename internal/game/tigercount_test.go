@@ -0,0 +1,97 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// tigersAnnounced is the count everyone is shown, -1 when there is none
+func tigersAnnounced(t *testing.T, gm *GameManager, code models.RoomCode) int {
+	t.Helper()
+
+	public, _ := gm.RoomView(code, "")
+	villager, _ := gm.RoomView(code, "p6")
+	for _, view := range []*models.GameRoom{public, villager} {
+		if (view.TigersRemaining == nil) != (public.TigersRemaining == nil) ||
+			view.TigersRemaining != nil && *view.TigersRemaining != *public.TigersRemaining {
+			t.Fatal("viewers are told different counts")
+		}
+	}
+	if public.TigersRemaining == nil {
+		return -1
+	}
+	return *public.TigersRemaining
+}
+
+func TestTigerCountMovesOnlyAtDawn(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 10, func(settings *models.RoomSettings) {
+		settings.AnnounceTigerCount = true
+	})
+	startTestGame(t, gm, code, map[string]models.Role{
+		"p1": models.RoleTiger,
+		"p2": models.RoleHunter,
+		"p3": models.RoleTiger,
+		"p4": models.RoleAlphaTiger,
+	})
+	if n := tigersAnnounced(t, gm, code); n != 3 {
+		t.Fatalf("first day: %d tigers, want 3", n)
+	}
+
+	// The hunter voted out takes the alpha with them; that is not
+	// confirmed until the next dawn
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p2")
+	if _, err := gm.HunterShoot(code, "p2", "p4", true); err != nil {
+		t.Fatalf("shoot: %v", err)
+	}
+	nextPhase(t, gm, code)
+	if isAlive(gm, code, "p4") || phaseOf(t, gm, code) != models.PhaseNight {
+		t.Fatal("the shot did not kill the alpha and bring the night")
+	}
+	if n := tigersAnnounced(t, gm, code); n != 3 {
+		t.Errorf("the night after the hunter shot a tiger: %d, want still 3", n)
+	}
+	playNight(t, gm, code, map[string]string{"p1": "p5"})
+	if n := tigersAnnounced(t, gm, code); n != 2 {
+		t.Errorf("second dawn: %d tigers, want 2", n)
+	}
+
+	// Nor is a tiger voted out
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p3")
+	if n := tigersAnnounced(t, gm, code); n != 2 {
+		t.Errorf("the night after a tiger was voted out: %d, want still 2", n)
+	}
+	playNight(t, gm, code, nil)
+	if n := tigersAnnounced(t, gm, code); n != 1 {
+		t.Errorf("third dawn: %d tigers, want 1", n)
+	}
+}
+
+func TestTigerCountAbsentWhenOff(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger})
+
+	if n := tigersAnnounced(t, gm, code); n != -1 {
+		t.Errorf("the view counts %d tigers with the setting off", n)
+	}
+	view, _ := gm.RoomView(code, "p3")
+	for name, wire := range map[string]interface{}{
+		"room":         models.NewRoomPublic(view),
+		"viewer":       models.NewRoomForViewer(view),
+		"phase update": models.NewPhaseUpdate(view),
+	} {
+		data, err := json.Marshal(wire)
+		if err != nil {
+			t.Fatalf("marshal %s: %v", name, err)
+		}
+		if strings.Contains(string(data), "tigersRemaining") {
+			t.Errorf("the %s carries tigersRemaining", name)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// ErrTigerTeamDecided is returned for a tiger team choice after the team's
+// step is settled
+var ErrTigerTeamDecided = &errs.Error{Code: "TIGER_TEAM_DECIDED", Message: "the tiger team has already decided"}
+
+// TigerTeamView returns the tiger team's night step and the IDs of the
+// living members allowed to see it
+func (gm *GameManager) TigerTeamView(code models.RoomCode) (*models.TigerTeamNight, []string, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists || room.TigerTeam == nil {
+		return nil, nil, false
+	}
+
+	var memberIDs []string
+	for _, member := range tigerTeamMembersLocked(room) {
+		memberIDs = append(memberIDs, member.ID)
+	}
+
+	return copyTigerTeam(room.TigerTeam), memberIDs, true
+}
+
+// isTigerTeam reports whether the role belongs to the tiger team
+// tigerTeamRoles are the roles isTigerTeam accepts, for counting the team
+var tigerTeamRoles = []models.Role{models.RoleTiger, models.RoleAlphaTiger}
+
+func isTigerTeam(role models.Role) bool {
+	return role == models.RoleTiger || role == models.RoleAlphaTiger
+}
+
+// nightTurnRole returns the night-order step the role acts in.
+// The whole tiger team shares the RoleTiger step.
+func nightTurnRole(role models.Role) models.Role {
+	if isTigerTeam(role) {
+		return models.RoleTiger
+	}
+	return role
+}
+
+// submitTigerDecisionLocked records a member's choice, replacing any earlier
+// one, and resolves the step once every living human member has decided
+func submitTigerDecisionLocked(room *models.GameRoom, player *models.Player, action string, target *models.Player) error {
+	if room.TigerTeam == nil {
+		room.TigerTeam = &models.TigerTeamNight{Decisions: make(map[string]*models.TigerDecision)}
+	}
+
+	if room.TigerTeam.Resolved {
+		return ErrTigerTeamDecided
+	}
+
+	decision := &models.TigerDecision{
+		PlayerID: player.ID,
+		Username: player.Username,
+		Action:   action,
+	}
+	if target != nil {
+		decision.TargetID = target.ID
+	}
+	room.TigerTeam.Decisions[player.ID] = decision
+	player.ActionSeq++
+
+	for _, member := range tigerTeamMembersLocked(room) {
+		if !member.IsBot && room.TigerTeam.Decisions[member.ID] == nil {
+			return nil // still waiting on a teammate
+		}
+	}
+
+	resolveTigerTeamLocked(room)
+	return nil
+}
+
+// resolveTigerTeamLocked turns the decisions into at most one kill and one
+// curse. The alpha's kill wins a disagreement, otherwise the target most of
+// the team chose; members who never decided skip.
+func resolveTigerTeamLocked(room *models.GameRoom) {
+	if room.TigerTeam == nil {
+		room.TigerTeam = &models.TigerTeamNight{Decisions: make(map[string]*models.TigerDecision)}
+	}
+	team := room.TigerTeam
+
+	var kills []*models.TigerDecision
+	for _, member := range tigerTeamMembersLocked(room) {
+		if member.HasActedThisNight {
+			continue // bots whose whole step was skipped
+		}
+
+		decision := team.Decisions[member.ID]
+		if decision == nil {
+			recordActionLocked(room, member, models.ActionSkip, nil)
+			markNightActionCompleteLocked(room, member)
+			continue
+		}
+
+		target := room.Players[decision.TargetID]
+		recordActionLocked(room, member, decision.Action, target)
+
+		switch decision.Action {
+		case models.ActionKill:
+			kills = append(kills, decision)
+		case models.ActionCurse:
+			target.IsCursed = true
+			member.UseAbility(models.AbilityCurse, room.NightNumber)
+			room.CursedPlayer = target.ID
+			team.CurseTargetID = target.ID
+			lastActionLocked(room, member, models.ActionCurse).Outcome = "cursed"
+		}
+
+		markNightActionCompleteLocked(room, member)
+	}
+
+	team.KillTargetID, team.SecondKillTargetID = tigerKillTargets(room, kills)
+	for _, kill := range kills {
+		if kill.TargetID != team.KillTargetID {
+			lastActionLocked(room, room.Players[kill.PlayerID], models.ActionKill).Outcome = "overruled"
+		}
+	}
+
+	room.TigerTarget = team.KillTargetID
+	team.Resolved = true
+	if room.CurrentNightRole == models.RoleTiger {
+		room.TurnToken = ""
+	}
+}
+
+// tigerKillTargets settles the team's kill decisions, given alpha first. The
+// alpha's choice wins; without one, the target most members chose does, the
+// earlier member breaking a tie. The runner-up is the second kill a blood
+// moon allows.
+func tigerKillTargets(room *models.GameRoom, kills []*models.TigerDecision) (string, string) {
+	counts := make(map[string]int)
+	var targets []string // in the order first chosen
+	for _, kill := range kills {
+		if counts[kill.TargetID] == 0 {
+			targets = append(targets, kill.TargetID)
+		}
+		counts[kill.TargetID]++
+	}
+
+	mostChosen := func(except string) string {
+		chosen := ""
+		for _, targetID := range targets {
+			if targetID != except && (chosen == "" || counts[targetID] > counts[chosen]) {
+				chosen = targetID
+			}
+		}
+		return chosen
+	}
+
+	first := mostChosen("")
+	if len(kills) > 0 && room.Players[kills[0].PlayerID].Role == models.RoleAlphaTiger {
+		first = kills[0].TargetID
+	}
+	return first, mostChosen(first)
+}
+
+// tigerTeamResolvedLocked reports whether the tiger team has finished its step
+func tigerTeamResolvedLocked(room *models.GameRoom) bool {
+	return room.TigerTeam != nil && room.TigerTeam.Resolved
+}
+
+// tigerTeamMembersLocked returns the living tiger team, alpha first
+func tigerTeamMembersLocked(room *models.GameRoom) []*models.Player {
+	var members []*models.Player
+	for _, player := range room.LivingPlayers() {
+		if isTigerTeam(player.Role) {
+			members = append(members, player)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if a.Role != b.Role {
+			return a.Role == models.RoleAlphaTiger
+		}
+		return a.ID < b.ID
+	})
+	return members
+}
+
+func copyTigerTeam(team *models.TigerTeamNight) *models.TigerTeamNight {
+	copied := *team
+	copied.Decisions = make(map[string]*models.TigerDecision, len(team.Decisions))
+	for id, decision := range team.Decisions {
+		d := *decision
+		copied.Decisions[id] = &d
+	}
+	return &copied
+}
@@ -0,0 +1,123 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// tigerNight is a game of eight on its first night, with the given tiger
+// team alongside a hunter (p3) and a shaman (p4)
+func tigerNight(t *testing.T, team map[string]models.Role) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	roles := map[string]models.Role{"p3": models.RoleHunter, "p4": models.RoleShaman}
+	for id, role := range team {
+		roles[id] = role
+	}
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, roles)
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+	return gm, code
+}
+
+// tigerTeamOf returns the team's night step, failing if it has none
+func tigerTeamOf(t *testing.T, gm *GameManager, code models.RoomCode) (*models.TigerTeamNight, []string) {
+	t.Helper()
+
+	team, members, ok := gm.TigerTeamView(code)
+	if !ok {
+		t.Fatal("no tiger team step")
+	}
+	return team, members
+}
+
+func TestAlphaCursesWhileTigerKills(t *testing.T) {
+	gm, code := tigerNight(t, map[string]models.Role{"p1": models.RoleAlphaTiger, "p2": models.RoleTiger})
+
+	if err := gm.SetAlphaTigerCurse(code, "p1", "p5", turnOf(t, gm, code, "p1").TurnToken); err != nil {
+		t.Fatalf("curse: %v", err)
+	}
+	// The tiger sees the alpha's choice and the step waits for theirs
+	team, members := tigerTeamOf(t, gm, code)
+	if team.Resolved || len(members) != 2 || team.Decisions["p1"] == nil || team.Decisions["p1"].Action != models.ActionCurse {
+		t.Fatalf("team = %+v for %v, want the alpha's curse pending", team, members)
+	}
+
+	if err := gm.PerformNightAction(code, "p2", "p6", turnOf(t, gm, code, "p2").TurnToken); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	team, _ = tigerTeamOf(t, gm, code)
+	if !team.Resolved || team.KillTargetID != "p6" || team.CurseTargetID != "p5" {
+		t.Fatalf("team = %+v, want p6 killed and p5 cursed", team)
+	}
+
+	result := playNight(t, gm, code, nil)
+	if deaths := deathIDs(result); len(deaths) != 1 || deaths[0] != "p6" {
+		t.Errorf("deaths = %v, want p6", deaths)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if !room.Players["p5"].IsCursed || !room.Players["p5"].IsAlive {
+			t.Error("p5 is not alive and cursed")
+		}
+	})
+}
+
+func TestAlphaKillsWithTheTigerDead(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleAlphaTiger, "p2": models.RoleTiger, "p3": models.RoleHunter})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p2")
+
+	// Nobody is left to wait for once the alpha has chosen
+	if err := gm.PerformNightAction(code, "p1", "p5", turnOf(t, gm, code, "p1").TurnToken); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	team, members := tigerTeamOf(t, gm, code)
+	if !team.Resolved || team.KillTargetID != "p5" || team.CurseTargetID != "" || len(members) != 1 {
+		t.Fatalf("team = %+v for %v, want the alpha's kill alone", team, members)
+	}
+	if deaths := deathIDs(playNight(t, gm, code, nil)); len(deaths) != 1 || deaths[0] != "p5" {
+		t.Errorf("deaths = %v, want p5", deaths)
+	}
+}
+
+func TestLoneAlpha(t *testing.T) {
+	t.Run("curses", func(t *testing.T) {
+		gm, code := tigerNight(t, map[string]models.Role{"p1": models.RoleAlphaTiger})
+		if err := gm.SetAlphaTigerCurse(code, "p1", "p5", turnOf(t, gm, code, "p1").TurnToken); err != nil {
+			t.Fatalf("curse: %v", err)
+		}
+		// Cursing is the alpha's whole night: nobody is killed
+		team, _ := tigerTeamOf(t, gm, code)
+		if !team.Resolved || team.KillTargetID != "" || team.CurseTargetID != "p5" {
+			t.Fatalf("team = %+v, want only the curse", team)
+		}
+		if deaths := deathIDs(playNight(t, gm, code, nil)); len(deaths) != 0 {
+			t.Errorf("deaths = %v, want none", deaths)
+		}
+	})
+
+	t.Run("kills", func(t *testing.T) {
+		gm, code := tigerNight(t, map[string]models.Role{"p1": models.RoleAlphaTiger})
+		if err := gm.PerformNightAction(code, "p1", "p5", turnOf(t, gm, code, "p1").TurnToken); err != nil {
+			t.Fatalf("kill: %v", err)
+		}
+		team, _ := tigerTeamOf(t, gm, code)
+		if !team.Resolved || team.KillTargetID != "p5" || team.CurseTargetID != "" {
+			t.Fatalf("team = %+v, want only the kill", team)
+		}
+		// Having killed, the alpha cannot also curse tonight
+		if err := gm.SetAlphaTigerCurse(code, "p1", "p6", ""); err == nil {
+			t.Error("the alpha cursed after killing")
+		}
+		if deaths := deathIDs(playNight(t, gm, code, nil)); len(deaths) != 1 || deaths[0] != "p5" {
+			t.Errorf("deaths = %v, want p5", deaths)
+		}
+		// The curse is saved for another night
+		wantAbility(t, gm, code, "p1", models.AbilityCurse, 0, false)
+	})
+}
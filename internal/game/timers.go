@@ -0,0 +1,81 @@
+package game
+
+import (
+	"sort"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// RoomTimer is one of a room's deadlines. The server keeps no timer
+// goroutines per room: clients report when a deadline passes and the
+// watches sweep for the rest, so a room's timers are its deadline fields.
+type RoomTimer struct {
+	Name     string           `json:"name"`
+	Deadline models.Timestamp `json:"deadline"`
+	// Armed is false while the game is paused; the deadline then moves on
+	// by however long the pause lasts
+	Armed       bool  `json:"armed"`
+	RemainingMs int64 `json:"remainingMs"`
+}
+
+// RoomTimers lists the room's set deadlines, soonest first
+func (gm *GameManager) RoomTimers(code models.RoomCode, now time.Time) ([]RoomTimer, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, gm.missingRoomLocked(code)
+	}
+
+	return gm.roomTimersLocked(room, now), nil
+}
+
+// roomTimersLocked collects every deadline the room is waiting on. New
+// deadline fields belong here so the debug endpoint shows them.
+func (gm *GameManager) roomTimersLocked(room *models.GameRoom, now time.Time) []RoomTimer {
+	paused := pausedLocked(room)
+	var timers []RoomTimer
+	add := func(name string, deadline *models.Timestamp, pausable bool) {
+		if deadline == nil {
+			return
+		}
+		timers = append(timers, RoomTimer{
+			Name:        name,
+			Deadline:    *deadline,
+			Armed:       !(pausable && paused),
+			RemainingMs: deadline.Sub(now).Milliseconds(),
+		})
+	}
+
+	add("phase", room.PhaseEndTime, true)
+	add("turn", room.TurnEndTime, true)
+	add("night_ceiling", room.NightCeilingAt, true)
+	add("hunter_shot", room.HunterShotEndsAt, true)
+	add("vote_lock", room.VoteLockAt(), true)
+	add("body_reveal", room.BodyRevealAt, true)
+	if room.DayStartedAt != nil && room.Settings.MinDiscussionSeconds > 0 {
+		minimum := room.DayStartedAt.Add(time.Duration(room.Settings.MinDiscussionSeconds) * time.Second)
+		add("min_discussion", models.TimestampPtr(minimum), false)
+	}
+	if room.SettingsChangedAt != nil && gm.SettingsAckCooldown > 0 {
+		cooldown := room.SettingsChangedAt.Add(gm.SettingsAckCooldown)
+		add("settings_ack", models.TimestampPtr(cooldown), false)
+	}
+	add("auto_start", room.AutoStartAt, false)
+	if room.AbortVote != nil {
+		add("abort_vote", &room.AbortVote.EndsAt, false)
+	}
+	if room.QuorumPause != nil {
+		add("quorum_grace", &room.QuorumPause.GraceEndsAt, false)
+	}
+	for _, player := range playersByIDLocked(room) {
+		for _, prompt := range room.PendingPrompts[player.ID] {
+			add("prompt:"+prompt.Type+":"+player.ID, prompt.Deadline, true)
+		}
+	}
+
+	sort.SliceStable(timers, func(i, j int) bool { return timers[i].Deadline.Before(timers[j].Deadline.Time) })
+	return timers
+}
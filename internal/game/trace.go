@@ -0,0 +1,63 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Night trace message keys. Text is the English rendering; clients with
+// their own translations render Key with Params instead.
+const (
+	TraceTigerTarget      = "night.tiger_target"
+	TraceNoTigerTarget    = "night.no_tiger_target"
+	TraceHunterProtected  = "night.hunter_protected"
+	TraceKillBlocked      = "night.kill_blocked"
+	TraceShamanLuck       = "night.shaman_luck"
+	TraceKilled           = "night.killed"
+	TraceVision           = "night.vision"
+	TraceModifier         = "night.modifier"
+	TraceKillsCalled      = "night.kills_called_off"
+	TraceVisionClouded    = "night.vision_clouded"
+	TraceFirstNightSpared = "night.first_night_spared"
+	TraceDeadInspected    = "night.dead_inspected"
+	TracePassed           = "night.passed"
+)
+
+var traceTemplates = map[string]string{
+	TraceTigerTarget:      "tigers targeted {target}",
+	TraceNoTigerTarget:    "tigers chose no target",
+	TraceHunterProtected:  "hunter protected {target}",
+	TraceKillBlocked:      "kill on {target} blocked by the hunter",
+	TraceShamanLuck:       "shaman {target} survived the attack after looking at the alpha tiger {seen}",
+	TraceKilled:           "{target} died ({cause})",
+	TraceVision:           "shaman saw {target} as {result}",
+	TraceModifier:         "night modifier: {modifier}",
+	TraceKillsCalled:      "the {modifier} called off the attack",
+	TraceVisionClouded:    "the {modifier} hid {target} from the shaman",
+	TraceFirstNightSpared: "no one dies on the first night",
+	TraceDeadInspected:    "shaman found the dead {target} was {result}",
+	TracePassed:           "{player} chose to do nothing",
+}
+
+// traceStep renders one step of a night's resolution. Params are name/value pairs.
+func traceStep(key string, params ...string) models.NightTraceStep {
+	step := models.NightTraceStep{Key: key, Params: make(map[string]string, len(params)/2)}
+
+	text := traceTemplates[key]
+	for i := 0; i+1 < len(params); i += 2 {
+		step.Params[params[i]] = params[i+1]
+		text = strings.ReplaceAll(text, "{"+params[i]+"}", params[i+1])
+	}
+	step.Text = text
+
+	return step
+}
+
+// traceName names a player in the trace, falling back to the ID
+func traceName(room *models.GameRoom, playerID string) string {
+	if player := room.Players[playerID]; player != nil {
+		return player.Username
+	}
+	return playerID
+}
@@ -0,0 +1,117 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// traceTexts returns the English rendering of each step of a night's trace
+func traceTexts(steps []models.NightTraceStep) []string {
+	texts := make([]string, len(steps))
+	for i, step := range steps {
+		texts[i] = step.Text
+	}
+	return texts
+}
+
+func TestNightTraces(t *testing.T) {
+	tests := []struct {
+		name     string
+		modifier string
+		plan     nightPlan
+		want     []string
+	}{
+		{
+			name: "protected night",
+			plan: nightPlan{kill: "p5", protect: "p5", vision: "p2"},
+			want: []string{
+				"tigers targeted Player5",
+				"hunter protected Player5",
+				"kill on Player5 blocked by the hunter",
+				"shaman saw Player2 as tiger",
+			},
+		},
+		{
+			name: "luck save",
+			plan: nightPlan{kill: "p4", vision: "p1"},
+			want: []string{
+				"tigers targeted Player4",
+				"shaman Player4 survived the attack after looking at the alpha tiger Player1",
+				"shaman saw Player1 as human",
+			},
+		},
+		{
+			name: "two deaths", modifier: ModifierBloodMoon,
+			plan: nightPlan{kill: "p5", secondKill: "p6", protect: "p3"},
+			want: []string{
+				"night modifier: blood moon",
+				"tigers targeted Player5",
+				"hunter protected Player3",
+				"tigers targeted Player6",
+				"Player5 died (tiger)",
+				"Player6 died (tiger)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := resolvePlan(t, tt.modifier, tt.plan)
+			if got := traceTexts(result.Trace); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trace =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestTraceStepsCarryTheirKeys(t *testing.T) {
+	result, _ := resolvePlan(t, "", nightPlan{kill: "p5", protect: "p5"})
+
+	// A locale renders the step from its key and params, not the English
+	blocked := result.Trace[len(result.Trace)-1]
+	if blocked.Key != TraceKillBlocked || !reflect.DeepEqual(blocked.Params, map[string]string{"target": "Player5"}) {
+		t.Errorf("step = %+v, want %s for Player5", blocked, TraceKillBlocked)
+	}
+}
+
+func TestTracesKeptUntilTheEnd(t *testing.T) {
+	gm, code := deathsGame(t)
+	playNight(t, gm, code, map[string]string{"p1": "p4", "p2": "p4"})
+
+	// While the game is on, no view says how the night resolved
+	view, _ := gm.RoomView(code, "p1")
+	data, err := json.Marshal(view)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), TraceKillBlocked) || strings.Contains(string(data), "blocked by the hunter") {
+		t.Error("a view carries the night's trace")
+	}
+	if _, err := gm.GameSummary(code); !errors.Is(err, ErrGameNotEnded) {
+		t.Errorf("summary during the game: %v, want %s", err, ErrGameNotEnded.Code)
+	}
+
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p1")
+	summary, err := gm.GameSummary(code)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if len(summary.NightTraces) != 1 || !strings.Contains(strings.Join(traceTexts(summary.NightTraces[0].Steps), "\n"), "kill on Player4 blocked by the hunter") {
+		t.Errorf("summary traces = %+v, want the protected night", summary.NightTraces)
+	}
+
+	// The replay export carries the traces as well
+	fixture, err := gm.RecordFixture(code)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if !strings.Contains(string(fixture), TraceKillBlocked) {
+		t.Error("the export has no night trace")
+	}
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// nightTurnTimeout is how long a role has to act before its turn can be timed out
+const nightTurnTimeout = 30 * time.Second
+
+var ErrTurnExpired = &errs.Error{Code: "TURN_EXPIRED", Message: "turn is over"}
+
+// ErrTurnNotTimedOut is returned when a turn is skipped before its time is up
+var ErrTurnNotTimedOut = &errs.Error{Code: "TURN_NOT_TIMED_OUT", Message: "turn has not timed out yet"}
+
+// TurnPrompt describes the current night turn to one of the players who
+// hold it, with the targets they may choose
+type TurnPrompt struct {
+	Role        models.Role       `json:"role"`
+	TurnToken   string            `json:"turnToken"`
+	TurnEndTime *models.Timestamp `json:"turnEndTime,omitempty"`
+	Targets     []PromptTarget    `json:"targets"`
+	// CooldownNights is set when the player's night ability is still
+	// resting: how many nights until it is back (models.CooldownForever
+	// when spent for good). They can only pass or skip this turn.
+	CooldownNights int `json:"cooldownNights,omitempty"`
+	PromptDeadline
+}
+
+// CurrentTurnPrompts returns the open night turn's prompt for each living
+// player it belongs to, by player ID, if there is one
+func (gm *GameManager) CurrentTurnPrompts(code models.RoomCode) (map[string]TurnPrompt, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists || room.Phase != models.PhaseNight || room.TurnToken == "" {
+		return nil, false
+	}
+
+	now := time.Now()
+	prompts := make(map[string]TurnPrompt)
+	for _, player := range room.Players {
+		if holdsTurnLocked(room, player) {
+			prompts[player.ID] = turnPromptLocked(room, player, now)
+		}
+	}
+
+	return prompts, true
+}
+
+// turnPromptLocked describes the open night turn to the player
+func turnPromptLocked(room *models.GameRoom, player *models.Player, now time.Time) TurnPrompt {
+	prompt := TurnPrompt{
+		Role:           room.CurrentNightRole,
+		TurnToken:      room.TurnToken,
+		TurnEndTime:    room.TurnEndTime,
+		Targets:        nightTargetsLocked(room, player),
+		PromptDeadline: promptDeadlineLocked(room, models.EventYourTurn, now),
+	}
+	if ability := player.Abilities[nightAbility(player.Role)]; ability != nil {
+		prompt.CooldownNights = ability.NightsUntilReady(room.NightNumber)
+	}
+	return prompt
+}
+
+// TimeoutNightTurn skips whoever has not acted in the turn identified by
+// token once its deadline has passed, then advances the night. It reports
+// whether the night has nothing left to wait for.
+func (gm *GameManager) TimeoutNightTurn(code models.RoomCode, token string) (bool, error) {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return false, gm.missingRoomLocked(code)
+	}
+
+	if room.Phase != models.PhaseNight {
+		return false, ErrNotNightPhase
+	}
+
+	if err := checkTurnTokenLocked(room, token); err != nil {
+		return false, err
+	}
+
+	if pausedLocked(room) {
+		return false, ErrGamePaused
+	}
+
+	if room.TurnEndTime != nil && time.Now().Before(room.TurnEndTime.Time) {
+		return false, ErrTurnNotTimedOut
+	}
+
+	if room.CurrentNightRole == models.RoleTiger {
+		resolveTigerTeamLocked(room)
+	} else {
+		for _, player := range room.Players {
+			if holdsTurnLocked(room, player) && !player.HasActedThisNight {
+				recordActionLocked(room, player, models.ActionSkip, nil)
+				lastActionLocked(room, player, models.ActionSkip).Outcome = "timed_out"
+				markNightActionCompleteLocked(room, player)
+			}
+		}
+	}
+	room.TurnToken = ""
+
+	return advanceNightRoleLocked(room), nil
+}
+
+// setNightRoleLocked opens the turn for role with a fresh token and deadline.
+// An empty role closes the night's turns.
+func setNightRoleLocked(room *models.GameRoom, role models.Role) {
+	room.CurrentNightRole = role
+	room.TurnToken = ""
+	room.TurnEndTime = nil
+	withdrawPromptsLocked(room, models.EventYourTurn)
+	if role == "" {
+		return
+	}
+
+	room.TurnToken = uuid.New().String()
+	endTime := time.Now().Add(nightTurnTimeout)
+	room.TurnEndTime = models.TimestampPtr(endTime)
+
+	var holderIDs []string
+	for _, player := range room.Players {
+		if holdsTurnLocked(room, player) && !player.IsBot {
+			holderIDs = append(holderIDs, player.ID)
+		}
+	}
+	issuePromptLocked(room, holderIDs, models.PendingPrompt{
+		Type:     models.EventYourTurn,
+		Context:  room.TurnToken,
+		Deadline: models.TimestampPtr(endTime),
+	})
+}
+
+// checkTurnTokenLocked rejects actions sent for a turn that is no longer open
+func checkTurnTokenLocked(room *models.GameRoom, token string) error {
+	if token == "" || token != room.TurnToken {
+		return ErrTurnExpired
+	}
+	return nil
+}
+
+// holdsTurnLocked reports whether the player acts in the current night turn
+func holdsTurnLocked(room *models.GameRoom, player *models.Player) bool {
+	return player.IsAlive && room.CurrentNightRole != "" && nightTurnRole(player.Role) == room.CurrentNightRole
+}
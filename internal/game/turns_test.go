@@ -0,0 +1,150 @@
+package game
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// expireTurn runs the open turn's clock out. Turn deadlines are fields the
+// timeout checks, so moving one is the test's controllable timer.
+func expireTurn(t *testing.T, gm *GameManager, code models.RoomCode) {
+	t.Helper()
+
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		room.TurnEndTime = models.TimestampPtr(time.Now().Add(-time.Second))
+	})
+}
+
+// lastNightAction returns the player's latest night action record
+func lastNightAction(t *testing.T, gm *GameManager, code models.RoomCode, playerID string) models.ActionRecord {
+	t.Helper()
+
+	var last models.ActionRecord
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		for _, record := range room.Players[playerID].ActionHistory {
+			if record.Phase == models.PhaseNight {
+				last = record
+			}
+		}
+	})
+	return last
+}
+
+func TestActionBeforeTheTimeout(t *testing.T) {
+	gm, code := deathsGame(t)
+	prompt := turnOf(t, gm, code, "p2")
+	expireTurn(t, gm, code)
+
+	// The hunter's action lands first and consumes the token, so the
+	// timeout that was already on its way finds the turn over
+	if err := gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken); err != nil {
+		t.Fatalf("protect: %v", err)
+	}
+	if _, err := gm.TimeoutNightTurn(code, prompt.TurnToken); !errors.Is(err, ErrTurnExpired) {
+		t.Fatalf("late timeout: %v, want %s", err, ErrTurnExpired.Code)
+	}
+
+	if last := lastNightAction(t, gm, code, "p2"); last.ActionType != models.ActionProtect || last.TargetID != "p4" {
+		t.Errorf("the hunter's night ended with %+v, want the protection", last)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.HunterProtection != "p4" {
+			t.Errorf("protection = %q, want p4", room.HunterProtection)
+		}
+	})
+}
+
+func TestTimeoutBeforeTheAction(t *testing.T) {
+	gm, code := deathsGame(t)
+	prompt := turnOf(t, gm, code, "p2")
+	expireTurn(t, gm, code)
+
+	// The timeout lands first and hands the night on to the shaman, so the
+	// late action is refused rather than applied to the shaman's step
+	if _, err := gm.TimeoutNightTurn(code, prompt.TurnToken); err != nil {
+		t.Fatalf("timeout: %v", err)
+	}
+	if err := gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken); !errors.Is(err, ErrNotYourTurn) {
+		t.Fatalf("late protect: %v, want %s", err, ErrNotYourTurn.Code)
+	}
+
+	if last := lastNightAction(t, gm, code, "p2"); last.ActionType != models.ActionSkip || last.Outcome != "timed_out" {
+		t.Errorf("the hunter's night ended with %+v, want a timed out skip", last)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.HunterProtection != "" {
+			t.Errorf("the late protection of %q was applied", room.HunterProtection)
+		}
+	})
+}
+
+func TestTimeoutBeforeItsTime(t *testing.T) {
+	gm, code := deathsGame(t)
+	prompt := turnOf(t, gm, code, "p2")
+
+	if _, err := gm.TimeoutNightTurn(code, prompt.TurnToken); !errors.Is(err, ErrTurnNotTimedOut) {
+		t.Fatalf("early timeout: %v, want %s", err, ErrTurnNotTimedOut.Code)
+	}
+	if err := gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken); err != nil {
+		t.Errorf("protect after an early timeout was refused: %v", err)
+	}
+}
+
+func TestStaleTurnToken(t *testing.T) {
+	gm, code := deathsGame(t)
+	hunterTurn := turnOf(t, gm, code, "p2")
+	shamanTurn := turnOf(t, gm, code, "p3")
+	if shamanTurn.TurnToken == hunterTurn.TurnToken {
+		t.Fatal("the handoff kept the token")
+	}
+
+	// A token from the last turn, or none, does not act in this one
+	for _, token := range []string{hunterTurn.TurnToken, ""} {
+		if err := gm.PerformNightAction(code, "p3", "p4", token); !errors.Is(err, ErrTurnExpired) {
+			t.Errorf("acting with %q: %v, want %s", token, err, ErrTurnExpired.Code)
+		}
+	}
+	if _, err := gm.TimeoutNightTurn(code, hunterTurn.TurnToken); !errors.Is(err, ErrTurnExpired) {
+		t.Errorf("timing out the last turn again: %v, want %s", err, ErrTurnExpired.Code)
+	}
+}
+
+func TestActionRacesTheTimeout(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		gm, code := deathsGame(t)
+		prompt := turnOf(t, gm, code, "p2")
+		expireTurn(t, gm, code)
+
+		var acted error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			acted = gm.PerformNightAction(code, "p2", "p4", prompt.TurnToken)
+		}()
+		go func() {
+			defer wg.Done()
+			gm.TimeoutNightTurn(code, prompt.TurnToken)
+		}()
+		wg.Wait()
+
+		// Exactly one of them decided the hunter's turn
+		last := lastNightAction(t, gm, code, "p2")
+		switch {
+		case acted == nil:
+			if last.ActionType != models.ActionProtect {
+				t.Fatalf("the action was accepted but the turn ended with %+v", last)
+			}
+		case errors.Is(acted, ErrTurnExpired), errors.Is(acted, ErrNotYourTurn):
+			if last.ActionType != models.ActionSkip || last.Outcome != "timed_out" {
+				t.Fatalf("the action was refused but the turn ended with %+v", last)
+			}
+		default:
+			t.Fatalf("protect: %v", acted)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// RoomView returns a copy of the room as seen by the given player.
+// An empty viewerID produces the public view.
+func (gm *GameManager) RoomView(code models.RoomCode, viewerID string) (*models.GameRoom, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, false
+	}
+
+	return viewForPlayer(room, viewerID), true
+}
+
+// RoomViews returns the public view plus one personalized view per player,
+// all taken from the same snapshot of the room
+func (gm *GameManager) RoomViews(code models.RoomCode) (*models.GameRoom, map[string]*models.GameRoom, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	room, exists := gm.Rooms[code]
+	if !exists {
+		return nil, nil, false
+	}
+
+	views := make(map[string]*models.GameRoom, len(room.Players))
+	for playerID := range room.Players {
+		views[playerID] = viewForPlayer(room, playerID)
+	}
+
+	return viewForPlayer(room, ""), views, true
+}
+
+// viewForPlayer copies the room, hiding what the viewer is not allowed to see.
+// Once the game has ended nothing is secret any more and the view is unredacted.
+func viewForPlayer(room *models.GameRoom, viewerID string) *models.GameRoom {
+	view := *room
+	view.ViewerID = viewerID
+	revealAll := room.Phase == models.PhaseEnded
+	viewer := room.Players[viewerID]
+	tigerViewer := viewer != nil && isTigerTeam(viewer.Role)
+	// The dead watch the rest of the game knowing every role, unless roles are hard-hidden
+	spectator := viewer != nil && !viewer.IsAlive && !room.Settings.HardHiddenRoles
+
+	view.Players = make(map[string]*models.Player, len(room.Players))
+	for id, player := range room.Players {
+		p := *player
+		p.SessionToken = "" // a view is never a way to learn someone's session
+		if !revealAll {
+			// Tigers know each other; everyone else only knows themselves
+			knowsRole := id == viewerID || spectator || (tigerViewer && isTigerTeam(player.Role))
+			if !knowsRole {
+				p.Role = ""
+			}
+			// Having acted tonight tells that the player holds a night role;
+			// with sleep confirmation it is not told even to those who know it
+			if !knowsRole || (room.Settings.NightSleepConfirmation && id != viewerID) {
+				p.HasActedThisNight = false
+			}
+			if id != viewerID {
+				p.LastProtected = ""
+			}
+			if !tigerViewer {
+				p.IsCursed = false
+			}
+			if !room.Settings.RevealVotes && id != viewerID {
+				p.VotedFor = ""
+			}
+			if deathConcealedFrom(room, viewer, id) {
+				p.IsAlive = true
+				p.DeathCause = ""
+			}
+		}
+		if id == viewerID || revealAll {
+			p.ActionHistory = append([]models.ActionRecord(nil), player.ActionHistory...)
+		} else {
+			p.ActionHistory = nil
+		}
+		p.AbilityStatus = nil
+		if id == viewerID {
+			p.AbilityStatus = abilityStatuses(room, player)
+		}
+		view.Players[id] = &p
+	}
+	view.ReindexLiving()
+
+	// Night choices belong to the role that made them
+	if !revealAll {
+		if viewer == nil || viewer.Role != models.RoleHunter {
+			view.HunterProtection = ""
+		}
+		if !tigerViewer {
+			view.TigerTarget = ""
+			view.CursedPlayer = ""
+		}
+		if viewer == nil || viewer.Role != models.RoleShaman {
+			view.ShamanVision = ""
+		}
+	}
+
+	view.VoteResults = copyCounts(room.VoteResults)
+	view.VoteHistory = append([]models.Ballot(nil), room.VoteHistory...)
+	if !room.Settings.RevealVotes && !revealAll {
+		// Anonymous ballots keep only who was voted for
+		for i := range view.VoteHistory {
+			view.VoteHistory[i].VoterID = ""
+		}
+	}
+	// Who has finished acting is shown as far as the players' flags are
+	view.NightActionsCompleted = nil
+	for id, done := range room.NightActionsCompleted {
+		if p := view.Players[id]; done && p != nil && p.HasActedThisNight {
+			if view.NightActionsCompleted == nil {
+				view.NightActionsCompleted = make(map[string]bool)
+			}
+			view.NightActionsCompleted[id] = true
+		}
+	}
+	if room.Settings.NightSleepConfirmation && !revealAll {
+		// Which role is acting now is exactly what the mode hides
+		if viewer == nil || !holdsTurnLocked(room, viewer) {
+			view.CurrentNightRole = ""
+			view.TurnEndTime = nil
+		}
+	}
+	view.NightActionOrder = nil
+	view.NightEntry = nightEntryFor(room, viewer, spectator || revealAll)
+	if !room.Settings.AnnounceTigerCount {
+		view.TigersRemaining = nil
+	}
+	view.Permissions = permissionsFor(room, viewer)
+
+	if room.QuorumPause != nil {
+		pause := *room.QuorumPause
+		view.QuorumPause = &pause
+	}
+
+	if room.AbortVote != nil {
+		vote := *room.AbortVote
+		vote.Eligible = append([]string(nil), vote.Eligible...)
+		vote.Votes = nil
+		view.AbortVote = &vote
+	}
+
+	// Only the players whose turn it is get its token
+	if viewer := room.Players[viewerID]; viewer == nil || !holdsTurnLocked(room, viewer) {
+		view.TurnToken = ""
+	}
+
+	// Only the tiger team sees its own deliberation
+	view.TigerTeam = nil
+	if viewer := room.Players[viewerID]; room.TigerTeam != nil && (revealAll || (viewer != nil && isTigerTeam(viewer.Role))) {
+		view.TigerTeam = copyTigerTeam(room.TigerTeam)
+	}
+
+	return &view
+}
+
+// livingTigersLocked counts the living tiger team. It is what
+// TigersRemaining announces at dawn; who they are stays hidden.
+func livingTigersLocked(room *models.GameRoom) int {
+	return room.LivingCount(tigerTeamRoles...)
+}
+
+// nightEntryFor tells the viewer what to expect of the current night. The
+// order of the turns would give away which roles are still alive, so only
+// viewers who know every role already are told it.
+func nightEntryFor(room *models.GameRoom, viewer *models.Player, knowsRoles bool) *models.NightEntry {
+	if room.Phase != models.PhaseNight {
+		return nil
+	}
+
+	entry := &models.NightEntry{
+		WillAct: viewer != nil && viewer.IsAlive && containsRole(room.NightActionOrder, nightTurnRole(viewer.Role)),
+	}
+	if room.NightCeilingAt != nil && room.PhaseStartedAt != nil {
+		entry.MaxDurationSeconds = int(room.NightCeilingAt.Sub(room.PhaseStartedAt.Time).Seconds())
+	}
+	if knowsRoles {
+		entry.Order = append([]models.Role(nil), room.NightActionOrder...)
+	}
+	return entry
+}
+
+// abilityStatuses summarizes the player's limited abilities for their own view
+func abilityStatuses(room *models.GameRoom, player *models.Player) []models.AbilityStatus {
+	names := make([]string, 0, len(player.Abilities))
+	for name := range player.Abilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := make([]models.AbilityStatus, 0, len(names))
+	for _, name := range names {
+		ability := player.Abilities[name]
+		status := models.AbilityStatus{
+			Name:   name,
+			Used:   ability.Used,
+			Usable: player.AbilityReady(name, room.NightNumber) && abilityUsableNow(room, player, name),
+		}
+		if ability.CooldownNights == models.CooldownForever {
+			remaining := 0
+			if ability.Used == 0 {
+				remaining = 1
+			}
+			status.Remaining = &remaining
+		} else {
+			status.CooldownNights = ability.NightsUntilReady(room.NightNumber)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// abilityUsableNow applies the phase, turn and alive checks for an ability
+func abilityUsableNow(room *models.GameRoom, player *models.Player, name string) bool {
+	switch name {
+	case models.AbilityCurse:
+		return player.IsAlive && room.Phase == models.PhaseNight && room.CurrentNightRole == nightTurnRole(player.Role) &&
+			!tigerTeamResolvedLocked(room)
+	case models.AbilityShoot:
+		return room.WaitingHunterShoot && room.DeadHunterID == player.ID
+	case models.AbilityProtect, models.AbilityVision:
+		return player.IsAlive && room.Phase == models.PhaseNight && room.CurrentNightRole == player.Role &&
+			!player.HasActedThisNight
+	}
+	return false
+}
+
+func copyCounts(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
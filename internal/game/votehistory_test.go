@@ -0,0 +1,124 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// castVotes has each voter vote as given and resolves the vote
+func castVotes(t *testing.T, gm *GameManager, code models.RoomCode, ballots map[string]string) {
+	t.Helper()
+
+	nextPhase(t, gm, code)
+	for voter, target := range ballots {
+		if err := gm.Vote(code, voter, target); err != nil {
+			t.Fatalf("%s votes for %s: %v", voter, target, err)
+		}
+	}
+	nextPhase(t, gm, code)
+}
+
+// ballotLines lists ballots as "round voter target outcome"
+func ballotLines(ballots []models.Ballot) []string {
+	lines := make([]string, len(ballots))
+	for i, ballot := range ballots {
+		lines[i] = fmt.Sprintf("%d %s %s %s", ballot.Round, ballot.VoterID, ballot.TargetID, ballot.Outcome)
+	}
+	return lines
+}
+
+// threeDayGame plays a game of six with anonymous ballots: a clear vote,
+// a tie nobody breaks, and the vote that finds the tiger. p5 sits out
+// the first two votes.
+func threeDayGame(t *testing.T) (*GameManager, models.RoomCode, []models.Ballot) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.RevealVotes = false
+		settings.VoteTieBreak = models.TieBreakNone
+	})
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger, "p2": models.RoleHunter})
+
+	castVotes(t, gm, code, map[string]string{"p1": "p6", "p2": "p6", "p3": "p6", "p4": "p5", "p6": "p1"})
+	playNight(t, gm, code, nil)
+
+	// Within the day of the tie, whoever asks sees who was voted for but not by whom
+	castVotes(t, gm, code, map[string]string{"p1": "p4", "p2": "p4", "p3": "p5", "p4": "p5"})
+	view, _ := gm.RoomView(code, "p2")
+	hidden := view.VoteHistory
+	playNight(t, gm, code, nil)
+
+	castVotes(t, gm, code, map[string]string{"p1": "p2", "p2": "p1", "p3": "p1", "p4": "p1", "p5": "p1"})
+	if phase := phaseOf(t, gm, code); phase != models.PhaseEnded {
+		t.Fatalf("phase = %s, want the game over", phase)
+	}
+	return gm, code, hidden
+}
+
+func TestVoteHistoryAcrossDays(t *testing.T) {
+	gm, code, hidden := threeDayGame(t)
+
+	if len(hidden) != 9 {
+		t.Fatalf("mid-game history has %d ballots, want the 9 of two days", len(hidden))
+	}
+	for _, ballot := range hidden {
+		if ballot.VoterID != "" || ballot.TargetID == "" {
+			t.Errorf("anonymous ballot shown as %+v", ballot)
+		}
+	}
+
+	summary, err := gm.GameSummary(code)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	want := []string{
+		"1 p1 p6 eliminated",
+		"1 p2 p6 eliminated",
+		"1 p3 p6 eliminated",
+		"1 p4 p5 survived",
+		"1 p6 p1 survived",
+		// The tie left everyone standing
+		"2 p1 p4 survived",
+		"2 p2 p4 survived",
+		"2 p3 p5 survived",
+		"2 p4 p5 survived",
+		"3 p1 p2 survived",
+		"3 p2 p1 eliminated",
+		"3 p3 p1 eliminated",
+		"3 p4 p1 eliminated",
+		"3 p5 p1 eliminated",
+	}
+	if got := ballotLines(summary.VoteHistory); !reflect.DeepEqual(got, want) {
+		t.Errorf("history =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(summary.VoteOutcomes) != 3 || summary.VoteOutcomes[1].EliminatedID != "" || len(summary.VoteOutcomes[1].Tied) != 2 {
+		t.Errorf("outcomes = %+v, want the second day tied between two", summary.VoteOutcomes)
+	}
+
+	// Once the game is over the views and the export name every voter
+	view, _ := gm.RoomView(code, "p3")
+	if !reflect.DeepEqual(ballotLines(view.VoteHistory), want) {
+		t.Errorf("the final view hides voters: %v", ballotLines(view.VoteHistory))
+	}
+	fixture, err := gm.RecordFixture(code)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	var exported struct {
+		Room struct {
+			VoteHistory []models.Ballot `json:"voteHistory"`
+		} `json:"room"`
+	}
+	if err := json.Unmarshal(fixture, &exported); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if !reflect.DeepEqual(ballotLines(exported.Room.VoteHistory), want) {
+		t.Errorf("exported history = %v", ballotLines(exported.Room.VoteHistory))
+	}
+}
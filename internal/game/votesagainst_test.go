@@ -0,0 +1,56 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// splitVote resolves a vote in which p5 is eliminated by four voters and
+// p6 survives two; nobody else receives a vote
+func splitVote(t *testing.T, reveal bool) (*GameManager, models.RoomCode) {
+	t.Helper()
+
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, func(settings *models.RoomSettings) { settings.RevealVotes = reveal })
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	nextPhase(t, gm, code)
+
+	ballots := map[string]string{"p1": "p5", "p3": "p5", "p4": "p5", "p6": "p5", "p2": "p6", "p5": "p6"}
+	for voter, target := range ballots {
+		if err := gm.Vote(code, voter, target); err != nil {
+			t.Fatalf("%s votes for %s: %v", voter, target, err)
+		}
+	}
+	nextPhase(t, gm, code)
+	if isAlive(gm, code, "p5") {
+		t.Fatal("p5 survived the vote")
+	}
+	return gm, code
+}
+
+func TestVotesAgainstYou(t *testing.T) {
+	gm, code := splitVote(t, true)
+
+	want := map[string][]string{
+		"p5": {"Player1", "Player3", "Player4", "Player6"},
+		"p6": {"Player2", "Player5"},
+	}
+	// The eliminated player hears it as well as the survivor, and a player
+	// nobody voted for hears nothing
+	if against := gm.TakeVotesAgainst(code); !reflect.DeepEqual(against, want) {
+		t.Errorf("votes against = %v, want %v", against, want)
+	}
+	if again := gm.TakeVotesAgainst(code); len(again) != 0 {
+		t.Errorf("the resolution was announced twice: %v", again)
+	}
+}
+
+func TestAnonymousVotesAreNotRevealed(t *testing.T) {
+	gm, code := splitVote(t, false)
+
+	if against := gm.TakeVotesAgainst(code); len(against) != 0 {
+		t.Errorf("anonymous ballots revealed %v", against)
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const (
+	// stuckPhaseFactor is how many times its expected length a phase may run
+	// before its room is reported as stuck
+	stuckPhaseFactor = 3
+	// openEndedPhaseDuration stands in for phases with no deadline, such as a
+	// vote waiting on a dead hunter's shot
+	openEndedPhaseDuration = 2 * time.Minute
+)
+
+// checkStuckRoomsLocked runs on every janitor sweep. It reports rooms that
+// have sat in one phase far longer than the phase should last, which points
+// at a transition bug, and with AutoRepairStuckRooms on, runs the repair and
+// night-resolution safety nets on them.
+func (gm *GameManager) checkStuckRoomsLocked(now time.Time) {
+	stuck := 0
+	for code, room := range gm.Rooms {
+		age, expected, overdue := gm.phaseOverdueLocked(room, now)
+		if !overdue {
+			continue
+		}
+		stuck++
+		log.Printf("⚠️ Room %s stuck in %s for %s (expected %s): %s",
+			code, room.Phase, age.Round(time.Second), expected, stuckStateLocked(room))
+
+		if gm.AutoRepairStuckRooms {
+			gm.repairStuckRoomLocked(room)
+		}
+	}
+	gm.stuckRooms = stuck
+}
+
+// phaseOverdueLocked reports how long the room has been in its phase, how
+// long the phase is expected to last, and whether it has run past
+// stuckPhaseFactor times that. The lobby, finished games and paused games
+// may wait indefinitely.
+func (gm *GameManager) phaseOverdueLocked(room *models.GameRoom, now time.Time) (time.Duration, time.Duration, bool) {
+	if room.Phase == models.PhaseWaiting || room.Phase == models.PhaseEnded ||
+		room.PhaseStartedAt == nil || pausedLocked(room) {
+		return 0, 0, false
+	}
+
+	expected := openEndedPhaseDuration
+	switch {
+	case room.Phase == models.PhaseNight && !room.WaitingHunterShoot:
+		expected = gm.NightCeiling
+		if expected <= 0 {
+			expected = DefaultNightCeiling
+		}
+	case room.PhaseEndTime != nil:
+		expected = room.PhaseEndTime.Sub(room.PhaseStartedAt.Time)
+	}
+
+	age := now.Sub(room.PhaseStartedAt.Time)
+	return age, expected, age > stuckPhaseFactor*expected
+}
+
+// repairStuckRoomLocked repairs whatever is inconsistent in a stuck room and
+// resolves a night that is still waiting on someone
+func (gm *GameManager) repairStuckRoomLocked(room *models.GameRoom) {
+	repairs := gm.repairRoomLocked(room)
+
+	if room.Phase == models.PhaseNight && !room.WaitingHunterShoot && !pausedLocked(room) {
+		if _, err := gm.forceResolveNightLocked(room); err == nil {
+			repairs = append(repairs, "resolved the night")
+		}
+	}
+
+	if len(repairs) == 0 {
+		log.Printf("⚠️ Room %s is stuck but nothing could be repaired", room.Code)
+	}
+}
+
+// stuckStateLocked describes a room for the stuck-room warning
+func stuckStateLocked(room *models.GameRoom) string {
+	connected, aliveConnected := 0, 0
+	for _, player := range room.Players {
+		if player.IsConnected {
+			connected++
+		}
+	}
+	alive := room.LivingCount()
+	for _, player := range room.LivingPlayers() {
+		if player.IsConnected {
+			aliveConnected++
+		}
+	}
+
+	return fmt.Sprintf("phase=%s round=%d currentRole=%q waitingHunterShoot=%t connected=%d/%d aliveConnected=%d/%d",
+		room.Phase, room.Round, room.CurrentNightRole, room.WaitingHunterShoot,
+		connected, len(room.Players), aliveConnected, alive)
+}
+
+// StuckRooms returns how many rooms the last janitor sweep found stuck
+func (gm *GameManager) StuckRooms() int {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.stuckRooms
+}
@@ -0,0 +1,133 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// phaseStart returns when the room entered its phase
+func phaseStart(t *testing.T, gm *GameManager, code models.RoomCode) time.Time {
+	t.Helper()
+
+	var started time.Time
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.PhaseStartedAt == nil {
+			t.Fatalf("%s has no phase start", room.Phase)
+		}
+		started = room.PhaseStartedAt.Time
+	})
+	return started
+}
+
+func TestStuckNightDetected(t *testing.T) {
+	gm, code := deathsGame(t)
+	started := phaseStart(t, gm, code)
+
+	gm.SweepIdleRooms(started.Add(stuckPhaseFactor * DefaultNightCeiling))
+	if stuck := gm.StuckRooms(); stuck != 0 {
+		t.Fatalf("a night at its limit was reported stuck (%d)", stuck)
+	}
+
+	gm.SweepIdleRooms(started.Add(stuckPhaseFactor*DefaultNightCeiling + time.Second))
+	if stuck := gm.StuckRooms(); stuck != 1 {
+		t.Fatalf("stuck rooms = %d, want 1", stuck)
+	}
+	// Reporting alone leaves the room as it was
+	if phase := phaseOf(t, gm, code); phase != models.PhaseNight {
+		t.Errorf("phase = %s without auto-repair", phase)
+	}
+	if forced := gm.NightsForceResolved(); forced != 0 {
+		t.Errorf("%d nights were resolved without auto-repair", forced)
+	}
+
+	// The gauge is recomputed each sweep, not accumulated
+	gm.SweepIdleRooms(started)
+	if stuck := gm.StuckRooms(); stuck != 0 {
+		t.Errorf("stuck rooms = %d after a sweep that found none", stuck)
+	}
+}
+
+func TestStuckDayUsesItsDeadline(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleTiger})
+	started := phaseStart(t, gm, code)
+
+	expected := openEndedPhaseDuration
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		if room.PhaseEndTime != nil {
+			expected = room.PhaseEndTime.Sub(started)
+		}
+	})
+
+	gm.SweepIdleRooms(started.Add(stuckPhaseFactor * expected))
+	if stuck := gm.StuckRooms(); stuck != 0 {
+		t.Errorf("a day at its limit was reported stuck (%d)", stuck)
+	}
+	gm.SweepIdleRooms(started.Add(stuckPhaseFactor*expected + time.Second))
+	if stuck := gm.StuckRooms(); stuck != 1 {
+		t.Errorf("stuck rooms = %d, want 1", stuck)
+	}
+}
+
+func TestWaitingRoomsAreNeverStuck(t *testing.T) {
+	gm := NewGameManager()
+	newTestRoom(t, gm, 4, nil)
+
+	ended := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, ended, nil)
+	nextPhase(t, gm, ended)
+	voteOut(t, gm, ended, "p6")
+	if phase := phaseOf(t, gm, ended); phase != models.PhaseEnded {
+		t.Fatalf("the all-villager game is in %s", phase)
+	}
+
+	paused := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, paused, map[string]models.Role{"p1": models.RoleTiger})
+	withRoom(t, gm, paused, func(room *models.GameRoom) {
+		room.QuorumPause = &models.QuorumPause{LostAt: models.NewTimestamp(time.Now())}
+	})
+
+	gm.SweepIdleRooms(time.Now().Add(24 * time.Hour))
+	if stuck := gm.StuckRooms(); stuck != 0 {
+		t.Errorf("a lobby, an ended game and a paused game counted %d stuck", stuck)
+	}
+}
+
+func TestStuckNightAutoRepaired(t *testing.T) {
+	gm, code := deathsGame(t)
+	gm.AutoRepairStuckRooms = true
+	started := phaseStart(t, gm, code)
+
+	gm.SweepIdleRooms(started.Add(stuckPhaseFactor*DefaultNightCeiling + time.Second))
+	if stuck := gm.StuckRooms(); stuck != 1 {
+		t.Errorf("stuck rooms = %d, want 1", stuck)
+	}
+	if phase := phaseOf(t, gm, code); phase != models.PhaseDay {
+		t.Errorf("phase = %s, want the night resolved into day", phase)
+	}
+	if forced := gm.NightsForceResolved(); forced != 1 {
+		t.Errorf("nights resolved = %d, want 1", forced)
+	}
+}
+
+func TestHunterWaitIsRepairedNotResolved(t *testing.T) {
+	gm, code := deathsGame(t)
+	gm.AutoRepairStuckRooms = true
+	playNight(t, gm, code, map[string]string{"p1": "p2"})
+	started := phaseStart(t, gm, code)
+
+	gm.SweepIdleRooms(started.Add(stuckPhaseFactor*openEndedPhaseDuration + time.Second))
+	if stuck := gm.StuckRooms(); stuck != 1 {
+		t.Errorf("stuck rooms = %d, want 1", stuck)
+	}
+	// The shot still belongs to the hunter; only its own timeout ends it
+	if view, _ := gm.RoomView(code, ""); !view.WaitingHunterShoot {
+		t.Error("the watchdog took the hunter's shot away")
+	}
+	if forced := gm.NightsForceResolved(); forced != 0 {
+		t.Errorf("nights resolved = %d while the hunter aims", forced)
+	}
+}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const (
+	DefaultWithdrawAfter           = 3 * time.Minute  // how long a tiger may be gone before they flee
+	DefaultWithdrawalWatchInterval = 15 * time.Second // how often absent players are checked
+)
+
+// Withdrawal is what the withdrawal watch changed in one room
+type Withdrawal struct {
+	RoomCode models.RoomCode
+	// Withdrawn are the tiger-team players who fled; they count as dead
+	Withdrawn []string
+	// Skipped are the absent players whose night step was skipped for them
+	Skipped []string
+	// NightResult is set when that finished the night
+	NightResult *NightResult
+}
+
+// StartWithdrawalWatch applies the withdrawal policy every interval until
+// stop is called. withdrew is called outside the manager lock for each room
+// it changed, so the frontend can announce it.
+func (gm *GameManager) StartWithdrawalWatch(interval time.Duration, withdrew func(Withdrawal)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				for _, withdrawal := range gm.WithdrawAbsentPlayers(now) {
+					withdrew(withdrawal)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// WithdrawAbsentPlayers keeps games from stalling on players who left for
+// good. A living tiger-team player disconnected longer than WithdrawAfter
+// flees into the forest: they count as dead and the game may end on the
+// spot. Village roles are never killed for it; an absent one holding the
+// night's turn only has their step skipped.
+func (gm *GameManager) WithdrawAbsentPlayers(now time.Time) []Withdrawal {
+	gm.mu.Lock()
+	defer gm.unlock()
+
+	if gm.WithdrawAfter <= 0 {
+		return nil
+	}
+
+	var withdrawals []Withdrawal
+	for _, room := range gm.Rooms {
+		if room.Phase == models.PhaseWaiting || room.Phase == models.PhaseEnded ||
+			room.WaitingHunterShoot || pausedLocked(room) {
+			continue
+		}
+
+		withdrawal := Withdrawal{RoomCode: room.Code}
+		for _, player := range room.LivingPlayers() {
+			if isTigerTeam(player.Role) && gm.absentLocked(player, now) {
+				gm.withdrawLocked(room, player, now)
+				withdrawal.Withdrawn = append(withdrawal.Withdrawn, player.ID)
+			}
+		}
+
+		if len(withdrawal.Withdrawn) > 0 {
+			if isEnded, winner := gm.checkGameEndLocked(room); isEnded {
+				log.Printf("Room %s ended after %v withdrew", room.Code, withdrawal.Withdrawn)
+				room.WinningTeam = winner
+				if err := gm.transitionLocked(room, models.PhaseEnded, ReasonWithdrawal); err != nil {
+					log.Printf("⚠️ Room %s could not end after a withdrawal: %v", room.Code, err)
+				}
+				withdrawals = append(withdrawals, withdrawal)
+				continue
+			}
+		}
+
+		if room.Phase == models.PhaseNight {
+			withdrawal.Skipped = gm.skipAbsentTurnLocked(room, now)
+			if len(withdrawal.Withdrawn) > 0 || len(withdrawal.Skipped) > 0 {
+				withdrawal.NightResult = gm.continueNightLocked(room)
+			}
+		}
+
+		if len(withdrawal.Withdrawn) > 0 || len(withdrawal.Skipped) > 0 {
+			withdrawals = append(withdrawals, withdrawal)
+		}
+	}
+
+	sort.Slice(withdrawals, func(i, j int) bool { return withdrawals[i].RoomCode < withdrawals[j].RoomCode })
+	return withdrawals
+}
+
+// absentLocked reports whether the player has been disconnected longer than
+// WithdrawAfter. Bots are never absent.
+func (gm *GameManager) absentLocked(player *models.Player, now time.Time) bool {
+	return !player.IsBot && !player.IsConnected && player.DisconnectedAt != nil &&
+		now.Sub(*player.DisconnectedAt) >= gm.WithdrawAfter
+}
+
+// withdrawLocked removes a tiger-team player from the game as if dead and
+// tells the room they fled
+func (gm *GameManager) withdrawLocked(room *models.GameRoom, player *models.Player, now time.Time) {
+	log.Printf("Room %s: %s withdrew after being disconnected since %s", room.Code, player.ID, player.DisconnectedAt.Format(time.RFC3339))
+
+	gm.killPlayerLocked(room, player, models.DeathByWithdrawal)
+	if room.TigerTeam != nil && !room.TigerTeam.Resolved {
+		delete(room.TigerTeam.Decisions, player.ID)
+	}
+	gm.systemNoticeLocked(room, fmt.Sprintf("%s fled into the forest.", player.Username), "player.withdrew", now)
+}
+
+// skipAbsentTurnLocked skips the night step for absent village players who
+// hold the current turn and returns their IDs. The tiger team's step is left
+// to the rest of the team.
+func (gm *GameManager) skipAbsentTurnLocked(room *models.GameRoom, now time.Time) []string {
+	if room.CurrentNightRole == "" || room.CurrentNightRole == models.RoleTiger || room.TurnToken == "" {
+		return nil
+	}
+
+	var skipped []string
+	waiting := false
+	for _, player := range playersByIDLocked(room) {
+		if !holdsTurnLocked(room, player) || player.HasActedThisNight {
+			continue
+		}
+		if !gm.absentLocked(player, now) {
+			waiting = true
+			continue
+		}
+		recordActionLocked(room, player, models.ActionSkip, nil)
+		lastActionLocked(room, player, models.ActionSkip).Outcome = "absent"
+		markNightActionCompleteLocked(room, player)
+		skipped = append(skipped, player.ID)
+	}
+
+	if len(skipped) > 0 && !waiting {
+		room.TurnToken = ""
+	}
+	return skipped
+}
+
+// continueNightLocked settles the tiger team's step if only decided members
+// are left, moves past finished turns and resolves the night once nothing
+// is left to wait for. It returns the night's result if it was resolved.
+func (gm *GameManager) continueNightLocked(room *models.GameRoom) *NightResult {
+	if room.CurrentNightRole == models.RoleTiger && !tigerTeamResolvedLocked(room) {
+		decided := true
+		for _, member := range tigerTeamMembersLocked(room) {
+			if !member.IsBot && (room.TigerTeam == nil || room.TigerTeam.Decisions[member.ID] == nil) {
+				decided = false
+				break
+			}
+		}
+		if !decided {
+			return nil
+		}
+		resolveTigerTeamLocked(room)
+		room.TurnToken = ""
+	}
+
+	if !advanceNightRoleLocked(room) {
+		return nil
+	}
+
+	result, err := gm.endNightLocked(room, ReasonNightResolved)
+	if err != nil {
+		log.Printf("⚠️ Room %s night could not be resolved after a withdrawal: %v", room.Code, err)
+		return nil
+	}
+	return result
+}
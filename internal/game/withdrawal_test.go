@@ -0,0 +1,111 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// leave connects the players and drops them again, starting their absence now
+func leave(gm *GameManager, code models.RoomCode, ids ...string) {
+	for _, id := range ids {
+		gm.SetConnected(code, id, true)
+	}
+	disconnect(gm, code, ids...)
+}
+
+func TestLoneTigerWithdrawalEndsTheGame(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger})
+	leave(gm, code, "p2")
+
+	if withdrawals := gm.WithdrawAbsentPlayers(time.Now().Add(DefaultWithdrawAfter - time.Second)); len(withdrawals) != 0 {
+		t.Fatalf("withdrew %+v before the threshold", withdrawals)
+	}
+
+	withdrawals := gm.WithdrawAbsentPlayers(time.Now().Add(DefaultWithdrawAfter))
+	if len(withdrawals) != 1 || len(withdrawals[0].Withdrawn) != 1 || withdrawals[0].Withdrawn[0] != "p2" {
+		t.Fatalf("withdrawals = %+v, want p2", withdrawals)
+	}
+	view, _ := gm.RoomView(code, "")
+	if view.Phase != models.PhaseEnded || view.WinningTeam != "human" {
+		t.Errorf("phase %s, winner %q; want the humans to win", view.Phase, view.WinningTeam)
+	}
+	if cause := view.Players["p2"].DeathCause; cause != models.DeathByWithdrawal {
+		t.Errorf("p2's death cause = %q", cause)
+	}
+	fled := false
+	for _, notice := range gm.TakeSystemNotices(code) {
+		fled = fled || strings.Contains(notice.Content, "fled into the forest")
+	}
+	if !fled {
+		t.Error("the room was not told the tiger fled")
+	}
+}
+
+func TestTigerTeamCarriesOnWithoutAWithdrawnMember(t *testing.T) {
+	gm := NewGameManager()
+	code := newTestRoom(t, gm, 8, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p1": models.RoleAlphaTiger, "p2": models.RoleTiger})
+	nextPhase(t, gm, code)
+	voteOut(t, gm, code, "p8")
+
+	// p2 chooses; the alpha is gone and never will
+	prompt := turnOf(t, gm, code, "p2")
+	if err := gm.PerformNightAction(code, "p2", "p5", prompt.TurnToken); err != nil {
+		t.Fatalf("p2 kills: %v", err)
+	}
+	leave(gm, code, "p1")
+	withdrawals := gm.WithdrawAbsentPlayers(time.Now().Add(DefaultWithdrawAfter))
+
+	if len(withdrawals) != 1 || len(withdrawals[0].Withdrawn) != 1 {
+		t.Fatalf("withdrawals = %+v, want the alpha", withdrawals)
+	}
+	if phase := phaseOf(t, gm, code); phase == models.PhaseEnded {
+		t.Fatal("the game ended with a tiger left")
+	}
+	// The rest of the night plays on and p2's kill stands
+	if result := withdrawals[0].NightResult; result == nil {
+		playNight(t, gm, code, nil)
+	}
+	if view, _ := gm.RoomView(code, ""); view.Players["p5"].IsAlive {
+		t.Error("the remaining tiger's kill was lost")
+	}
+}
+
+func TestAbsentShamanIsSkippedNotKilled(t *testing.T) {
+	gm, code := deathsGame(t)
+	turnOf(t, gm, code, "p3")
+	leave(gm, code, "p3")
+
+	withdrawals := gm.WithdrawAbsentPlayers(time.Now().Add(DefaultWithdrawAfter))
+	if len(withdrawals) != 1 || len(withdrawals[0].Withdrawn) != 0 ||
+		len(withdrawals[0].Skipped) != 1 || withdrawals[0].Skipped[0] != "p3" {
+		t.Fatalf("withdrawals = %+v, want only the shaman's step skipped", withdrawals)
+	}
+	withRoom(t, gm, code, func(room *models.GameRoom) {
+		shaman := room.Players["p3"]
+		if !shaman.IsAlive {
+			t.Error("the absent shaman died")
+		}
+		history := shaman.ActionHistory
+		if last := history[len(history)-1]; last.ActionType != models.ActionSkip || last.Outcome != "absent" {
+			t.Errorf("the shaman's last action is %+v, want a skip for being absent", last)
+		}
+	})
+}
+
+func TestWithdrawalOff(t *testing.T) {
+	gm := NewGameManager()
+	gm.WithdrawAfter = 0
+	code := newTestRoom(t, gm, 6, nil)
+	startTestGame(t, gm, code, map[string]models.Role{"p2": models.RoleTiger})
+	leave(gm, code, "p2")
+
+	if withdrawals := gm.WithdrawAbsentPlayers(time.Now().Add(24 * time.Hour)); len(withdrawals) != 0 {
+		t.Errorf("withdrew %+v with the policy off", withdrawals)
+	}
+}
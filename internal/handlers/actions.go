@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// ActionRequest is a game action sent over REST instead of the websocket.
+// Type is the websocket event name; for night_action, ActionType "curse",
+// "skip" or "pass" selects the alpha tiger's curse, a skip or a pass. Seat
+// may stand in for TargetID. A RequestID already handled for the player,
+// over either transport, is acked again without being reapplied.
+type ActionRequest struct {
+	PlayerID   string `json:"playerId" binding:"required"`
+	Type       string `json:"type" binding:"required"`
+	TargetID   string `json:"targetId"`
+	Seat       int    `json:"seat"`
+	ActionType string `json:"actionType"`
+	TurnToken  string `json:"turnToken"`
+	Seconds    int    `json:"seconds"`
+	Approve    bool   `json:"approve"`
+	Confirm    bool   `json:"confirm"`
+	RequestID  string `json:"requestId"`
+}
+
+// restActions are the websocket events that may also be sent over REST
+var restActions = map[string]bool{
+	models.EventNightAction:      true,
+	models.EventCurseAction:      true,
+	models.EventSkipAction:       true,
+	models.EventTurnTimeout:      true,
+	models.EventNightSleep:       true,
+	models.EventNominate:         true,
+	models.EventVote:             true,
+	models.EventVoteResult:       true,
+	models.EventHunterShoot:      true,
+	models.EventHunterTimeout:    true,
+	models.EventReadyToVote:      true,
+	models.EventSkipPhase:        true,
+	models.EventExtendDiscussion: true,
+	models.EventAbortVote:        true,
+	models.EventAbortTimeout:     true,
+	models.EventQuorumTimeout:    true,
+}
+
+// PerformAction runs a game action for a player over REST, who must send
+// their session token in the X-Session-Token header. It goes through
+// the same dispatcher as the websocket, so the rules, the room's send lock
+// and the broadcasts to everyone else are identical; errors come back with
+// the same payload as an error event.
+func PerformAction(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		var req ActionRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		eventType := req.Type
+		if eventType == models.EventNightAction {
+			switch req.ActionType {
+			case models.ActionCurse:
+				eventType = models.EventCurseAction
+			case models.ActionSkip:
+				eventType = models.EventSkipAction
+			}
+		}
+		if !restActions[eventType] {
+			respondError(c, http.StatusBadRequest, &fieldError{Code: CodeInvalidField, Field: "type", message: "unsupported action type"})
+			return
+		}
+
+		if !authenticate(c, gm, code, req.PlayerID) {
+			return
+		}
+		view, exists := gm.RoomView(code, req.PlayerID)
+		if !exists {
+			respondError(c, http.StatusNotFound, gm.MissingRoomError(code))
+			return
+		}
+
+		client := ws.NewDetachedClient(req.PlayerID, view.Code)
+		msg := &models.WSMessage{
+			Type: eventType,
+			Payload: map[string]interface{}{
+				"targetId":  req.TargetID,
+				"seat":      req.Seat,
+				"turnToken": req.TurnToken,
+				"seconds":   req.Seconds,
+				"approve":   req.Approve,
+				"confirm":   req.Confirm,
+				"pass":      req.Type == models.EventNightAction && req.ActionType == models.ActionPass,
+				"requestId": req.RequestID,
+			},
+		}
+
+		unlock := lockRoom(view.Code)
+		handleWebSocketMessage(client, gm, msg)
+		unlock()
+
+		// Anything addressed to the sender alone is the outcome of the action
+		for _, reply := range client.Replies() {
+			var frame struct {
+				Type    string          `json:"type"`
+				Payload json.RawMessage `json:"payload"`
+			}
+			if err := json.Unmarshal(reply, &frame); err != nil {
+				log.Printf("JSON unmarshal error: %v", err)
+				continue
+			}
+
+			switch frame.Type {
+			case models.EventError:
+				c.Data(http.StatusBadRequest, "application/json; charset=utf-8", frame.Payload)
+				return
+			case models.EventActionAck:
+				c.JSON(http.StatusOK, gin.H{"requestId": req.RequestID, "ack": frame.Payload})
+				return
+			}
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"requestId": req.RequestID})
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestGamePlayedWithOneRESTPlayer(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	server := newTestServer(t, gm)
+	host := ids[0]
+
+	// The REST player holds a night turn of the village's; the tigers are
+	// voted out one a day after a villager goes first
+	roles := make(map[string]models.Role)
+	for id, assignment := range gm.RoleAssignments(code) {
+		roles[id] = assignment.Role
+	}
+	tiger := func(id string) bool { return roles[id] == models.RoleTiger || roles[id] == models.RoleAlphaTiger }
+	var rest, villager string
+	for _, id := range ids[1:] {
+		switch {
+		case rest == "" && (roles[id] == models.RoleHunter || roles[id] == models.RoleShaman):
+			rest = id
+		case villager == "" && roles[id] == models.RoleVillager:
+			villager = id
+		}
+	}
+	if rest == "" || villager == "" {
+		t.Fatalf("no village night role or villager besides the host in %v", roles)
+	}
+
+	clients := make(map[string]*wsClient)
+	for _, id := range ids {
+		if id != rest {
+			clients[id] = server.dialPlayer(t, code, id)
+			clients[id].next(models.EventGameStateUpdate)
+		}
+	}
+	path := "/api/rooms/" + code.String() + "/actions"
+	act := func(body map[string]interface{}, token string) (int, map[string]interface{}) {
+		t.Helper()
+		body["playerId"] = rest
+		data, _ := json.Marshal(body)
+		return server.do(t, http.MethodPost, path, string(data), token)
+	}
+	accepted := func(body map[string]interface{}) {
+		t.Helper()
+		if status, answer := act(body, sessionOf(t, gm, code, rest)); status != http.StatusAccepted && status != http.StatusOK {
+			t.Fatalf("REST %v answered %d %v", body["type"], status, answer)
+		}
+	}
+	phaseIs := func(phase models.GamePhase) func() bool {
+		return func() bool {
+			current, _ := gm.RoomPhase(code)
+			return current == phase
+		}
+	}
+
+	for day := 1; ; day++ {
+		if day > len(ids) {
+			t.Fatal("the game never ended")
+		}
+		clients[host].send(models.EventSkipPhase, nil)
+		waitFor(t, 2*time.Second, phaseIs(models.PhaseVoting))
+
+		view, _ := gm.RoomView(code, "")
+		target := villager
+		if day > 1 {
+			for _, player := range view.LivingPlayers() {
+				if tiger(player.ID) {
+					target = player.ID
+					break
+				}
+			}
+		}
+		for _, player := range view.LivingPlayers() {
+			choice := target
+			if player.ID == target {
+				choice = host
+			}
+			if player.ID == rest {
+				accepted(map[string]interface{}{"type": models.EventVote, "targetId": choice})
+			} else {
+				clients[player.ID].send(models.EventVote, map[string]string{"targetId": choice})
+			}
+		}
+		waitFor(t, 2*time.Second, func() bool { return gm.CheckAllVoted(code) })
+		accepted(map[string]interface{}{"type": models.EventVoteResult})
+
+		if phase, _ := gm.RoomPhase(code); phase == models.PhaseEnded {
+			break
+		}
+		if phase, _ := gm.RoomPhase(code); phase != models.PhaseNight {
+			t.Fatalf("day %d: phase = %s after the vote, want the night", day, phase)
+		}
+
+		// The REST player guards or reads the host, and only with their own
+		// session; everyone else skips over their socket
+		skipped := make(map[string]bool)
+		for deadline := time.Now().Add(2 * time.Second); !phaseIs(models.PhaseDay)(); time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("night %d never ended", day)
+			}
+			prompts, _ := gm.CurrentTurnPrompts(code)
+			for id, prompt := range prompts {
+				if skipped[prompt.TurnToken] {
+					continue
+				}
+				skipped[prompt.TurnToken] = true
+				if id != rest {
+					clients[id].send(models.EventSkipAction, map[string]string{"turnToken": prompt.TurnToken})
+					continue
+				}
+				body := map[string]interface{}{"type": models.EventNightAction, "targetId": host, "turnToken": prompt.TurnToken}
+				if status, answer := act(body, sessionOf(t, gm, code, host)); status != http.StatusUnauthorized {
+					t.Errorf("night action with the host's session answered %d %v", status, answer)
+				}
+				accepted(body)
+			}
+		}
+	}
+
+	view, _ := gm.RoomView(code, rest)
+	if view.WinningTeam != "human" {
+		t.Errorf("winner = %q, want the village", view.WinningTeam)
+	}
+	var nights int
+	for _, action := range view.Players[rest].ActionHistory {
+		if action.Phase == models.PhaseNight && action.TargetID == host {
+			nights++
+		}
+	}
+	if nights == 0 {
+		t.Error("the REST player's night actions were not recorded")
+	}
+}
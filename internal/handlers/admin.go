@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+)
+
+// AdminAuth requires "Authorization: Bearer <token>". An empty token
+// disables the admin API entirely.
+//
+// The admin API and /debug are the only surfaces that show other players'
+// roles during a game, and they take this token, never a player's session.
+// A host who also plays gets nothing more than any other player:
+//   - room state, in every snapshot and scoped update, comes from
+//     game.RoomViews, which redacts roles per viewer without regard to who
+//     hosts; so does GET /api/rooms/:code (the public view) and the view in
+//     join responses
+//   - phase_update's night result goes out as NightResult.Public; the
+//     shaman's vision reaches the shaman alone, in their night_recap
+//   - host-only events (join_request, composition_invalid and
+//     composition_outdated) are sent in the lobby, before roles are dealt
+//   - settings_changed carries the settings, whose composition is counts per
+//     role, never who holds them
+//   - host actions show in the chat as terse lines naming the actor and the
+//     target; the log itself is admin-only
+//   - who has acted tonight (nightActionsCompleted, hasActedThisNight)
+//     would name the night roles, so a viewer is only told it of players
+//     whose role they already know; under nightSleepConfirmation only of
+//     themselves. Which role's turn it is stays public unless that mode is
+//     on, and no view carries how many night roles are left
+//   - invites, the lobby listing and room_heartbeat carry no player state
+//
+// TestHostWhoPlaysLearnsNoSecrets plays a day and a night as the host and
+// checks every frame, room and chat response they get.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "admin API is disabled"})
+			return
+		}
+
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AdminListRooms lists every room with its invariant violations, the server
+// capacity, how many clients speak each protocol version or declared each
+// capability, how many are watching the lobby and each room's ping latency
+func AdminListRooms(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"rooms":              gm.RoomSummaries(),
+			"capacity":           gm.Capacity(),
+			"validationFailures": gm.ValidationFailures(),
+			"nightForceResolved": gm.NightsForceResolved(),
+			"stuckRooms":         gm.StuckRooms(),
+			"clientProtocols":    ProtocolCounts(),
+			"clientCapabilities": CapabilityCounts(),
+			"lobbySubscribers":   lobby.Subscribers(),
+			"roomLatency":        RoomLatencies(),
+		})
+	}
+}
+
+// AdminRepairRoom resets a room's inconsistent state to safe values
+func AdminRepairRoom(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		repairs, err := gm.RepairRoom(code)
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"repairs": repairs})
+	}
+}
+
+// AdminHostActions lists every administrative action taken in a room, for abuse reports
+func AdminHostActions(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actions, err := gm.HostActionLog(gm.ResolveRoomCode(c.Param("code")))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"hostActions": actions})
+	}
+}
+
+// AdminRecordFixture dumps a room's full internal state for use as a test
+// fixture. The players' session tokens are part of it, so it is as secret as
+// the admin token itself.
+func AdminRecordFixture(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		data, err := gm.RecordFixture(code)
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		c.Header("Content-Disposition", "attachment; filename=\""+strings.ToLower(code.String())+".json\"")
+		c.Data(http.StatusOK, "application/json", data)
+	}
+}
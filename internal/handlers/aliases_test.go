@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestJoinByAlias(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	code, _ := server.createRoom(t, "Host")
+	view, _ := gm.RoomView(code, "")
+	alias := view.Aliases["en"]
+
+	// Read out over voice chat, in whatever case and spacing it was typed
+	typed := strings.ToUpper(strings.ReplaceAll(alias, "-", " "))
+	joined := server.join(t, typed, "Guest")
+	if room, _ := joined["room"].(map[string]interface{}); room["code"] != code.String() {
+		t.Fatalf("joined %v, want %s", joined["room"], code)
+	}
+
+	status, body := server.get(t, "/api/rooms/"+url.PathEscape(view.Aliases["th"]))
+	if room, _ := body["room"].(map[string]interface{}); status != http.StatusOK || room["code"] != code.String() {
+		t.Errorf("fetching by the Thai alias: %d %v", status, body)
+	}
+
+	client := server.dialAs(t, alias, joined)
+	client.next(models.EventGameStateUpdate)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// shutdownWarningLead is how long before a scheduled shutdown games in
+// progress are warned again
+const shutdownWarningLead = 5 * time.Minute
+
+// Announcement is a message from the server's operators, such as a warning
+// of maintenance
+type Announcement struct {
+	Key        string            `json:"key,omitempty"` // message key for translated clients
+	Text       string            `json:"text"`
+	ShutdownAt *models.Timestamp `json:"shutdownAt,omitempty"`
+	PostedAt   models.Timestamp  `json:"postedAt"`
+	// Reminder marks the repeat sent to games in progress shortly before
+	// the shutdown
+	Reminder bool `json:"reminder,omitempty"`
+}
+
+// announcements holds the active announcement until an admin clears it
+var announcements struct {
+	mu      sync.Mutex
+	current *Announcement
+	warning *time.Timer // the shutdown reminder, while one is due
+}
+
+// AnnounceRequest is the body of POST /api/admin/announce
+type AnnounceRequest struct {
+	Key        string            `json:"key" binding:"max=100"`
+	Text       string            `json:"text" binding:"required,max=500"`
+	ShutdownAt *models.Timestamp `json:"shutdownAt"`
+}
+
+// AdminAnnounce posts an announcement to every room and the lobby,
+// replacing any earlier one. Players who connect later are sent it too.
+// With a shutdown time, games still going shutdownWarningLead before it
+// are reminded.
+func AdminAnnounce(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AnnounceRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		now := time.Now()
+		if req.ShutdownAt != nil && !req.ShutdownAt.After(now) {
+			respondError(c, http.StatusBadRequest, &fieldError{Code: CodeInvalidField, Field: "shutdownAt", message: "shutdownAt must be in the future"})
+			return
+		}
+
+		announcement := &Announcement{
+			Key:        req.Key,
+			Text:       req.Text,
+			ShutdownAt: req.ShutdownAt,
+			PostedAt:   models.NewTimestamp(now),
+		}
+
+		announcements.mu.Lock()
+		stopShutdownWarningLocked()
+		announcements.current = announcement
+		if announcement.ShutdownAt != nil {
+			if lead := announcement.ShutdownAt.Sub(now) - shutdownWarningLead; lead > 0 {
+				announcements.warning = time.AfterFunc(lead, func() { remindShutdown(gm, announcement) })
+			}
+		}
+		announcements.mu.Unlock()
+
+		for roomCode := range gm.RoomPhases() {
+			broadcastToRoom(roomCode, models.EventServerAnnouncement, announcement)
+		}
+		broadcastLobby(models.EventServerAnnouncement, announcement)
+
+		c.JSON(http.StatusOK, gin.H{"announcement": announcement})
+	}
+}
+
+// AdminClearAnnouncement withdraws the active announcement and its reminder
+func AdminClearAnnouncement(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		announcements.mu.Lock()
+		stopShutdownWarningLocked()
+		cleared := announcements.current != nil
+		announcements.current = nil
+		announcements.mu.Unlock()
+
+		if cleared {
+			for roomCode := range gm.RoomPhases() {
+				broadcastToRoom(roomCode, models.EventServerAnnouncementCleared, nil)
+			}
+			broadcastLobby(models.EventServerAnnouncementCleared, nil)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"cleared": cleared})
+	}
+}
+
+// remindShutdown repeats the announcement to games in progress, unless it
+// has been replaced or cleared since
+func remindShutdown(gm *game.GameManager, announcement *Announcement) {
+	announcements.mu.Lock()
+	current := announcements.current == announcement
+	announcements.warning = nil
+	announcements.mu.Unlock()
+	if !current {
+		return
+	}
+
+	reminder := *announcement
+	reminder.Reminder = true
+	for roomCode, phase := range gm.RoomPhases() {
+		if phase != models.PhaseWaiting && phase != models.PhaseEnded {
+			broadcastToRoom(roomCode, models.EventServerAnnouncement, reminder)
+		}
+	}
+}
+
+func stopShutdownWarningLocked() {
+	if announcements.warning != nil {
+		announcements.warning.Stop()
+		announcements.warning = nil
+	}
+}
+
+// activeAnnouncement returns the announcement in force, nil when there is none
+func activeAnnouncement() *Announcement {
+	announcements.mu.Lock()
+	defer announcements.mu.Unlock()
+	return announcements.current
+}
+
+// sendAnnouncement gives a newly connected client the announcement in force
+func sendAnnouncement(client *ws.Client) {
+	if announcement := activeAnnouncement(); announcement != nil {
+		sendToClient(client, models.EventServerAnnouncement, announcement)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// withoutAnnouncements clears the announcement in force once the test is done;
+// it is shared by the whole server
+func withoutAnnouncements(t *testing.T) {
+	t.Cleanup(func() {
+		announcements.mu.Lock()
+		stopShutdownWarningLocked()
+		announcements.current = nil
+		announcements.mu.Unlock()
+	})
+}
+
+// announce posts an announcement as an admin would
+func announce(t *testing.T, gm *game.GameManager, body string) map[string]interface{} {
+	t.Helper()
+
+	w := serve(t, AdminAnnounce(gm), http.MethodPost, "/admin/announce", "/admin/announce", body)
+	wantStatus(t, w, http.StatusOK)
+	answer, _ := decodeBody(t, w)["announcement"].(map[string]interface{})
+	return answer
+}
+
+func TestAnnouncementReachesEveryRoomAndTheLobby(t *testing.T) {
+	withoutAnnouncements(t)
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	_, listing := lobbyServer(t)
+
+	var players []*wsClient
+	for i := 0; i < 2; i++ {
+		code, ids := startedGame(t, gm, 6)
+		client := server.dialPlayer(t, code, ids[1])
+		client.next(models.EventGameStateUpdate)
+		players = append(players, client)
+	}
+	watcher, _, err := dialLobby(t, listing, newLobbyAddr())
+	if err != nil {
+		t.Fatalf("dial the lobby: %v", err)
+	}
+	watcher.next(models.EventRoomList)
+
+	announce(t, gm, `{"key":"maintenance","text":"Back in ten minutes"}`)
+	for i, client := range append(players, watcher) {
+		payload, _ := client.next(models.EventServerAnnouncement).(map[string]interface{})
+		if payload["text"] != "Back in ten minutes" || payload["key"] != "maintenance" {
+			t.Errorf("client %d was sent %v", i, payload)
+		}
+	}
+
+	w := serve(t, AdminClearAnnouncement(gm), http.MethodDelete, "/admin/announce", "/admin/announce", "")
+	if answer := decodeBody(t, w); answer["cleared"] != true {
+		t.Errorf("clearing answered %v", answer)
+	}
+	for i, client := range append(players, watcher) {
+		if _, ok := client.await(models.EventServerAnnouncementCleared, 2*time.Second); !ok {
+			t.Errorf("client %d was not told the announcement was cleared", i)
+		}
+	}
+	w = serve(t, AdminClearAnnouncement(gm), http.MethodDelete, "/admin/announce", "/admin/announce", "")
+	if answer := decodeBody(t, w); answer["cleared"] != false {
+		t.Errorf("clearing nothing answered %v", answer)
+	}
+}
+
+func TestAnnouncementSentOnConnect(t *testing.T) {
+	withoutAnnouncements(t)
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+
+	// Announced before the room exists, so the broadcast cannot race the
+	// connection
+	announce(t, gm, `{"text":"Restarting soon"}`)
+	code, ids := startedGame(t, gm, 6)
+
+	// It follows the snapshot, so the client has a room to show it in
+	client := server.dialPlayer(t, code, ids[2])
+	client.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var types []string
+	for len(types) < 2 {
+		var frame struct {
+			Type    string                 `json:"type"`
+			Payload map[string]interface{} `json:"payload"`
+		}
+		if err := client.conn.ReadJSON(&frame); err != nil {
+			t.Fatalf("after %v: %v", types, err)
+		}
+		types = append(types, frame.Type)
+		if frame.Type == models.EventServerAnnouncement && frame.Payload["text"] != "Restarting soon" {
+			t.Errorf("announcement = %v", frame.Payload)
+		}
+	}
+	if types[0] != models.EventGameStateUpdate || types[1] != models.EventServerAnnouncement {
+		t.Errorf("a new connection was sent %v, want the snapshot then the announcement", types)
+	}
+}
+
+func TestShutdownReminderGoesToGamesInProgress(t *testing.T) {
+	withoutAnnouncements(t)
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+
+	code, ids := startedGame(t, gm, 6)
+	playing := server.dialPlayer(t, code, ids[1])
+	playing.next(models.EventGameStateUpdate)
+	lobbyCode, joined := server.createRoom(t, "waiting")
+	waiting := server.dialAs(t, lobbyCode.String(), joined)
+	waiting.next(models.EventGameStateUpdate)
+
+	shutdownAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	announce(t, gm, fmt.Sprintf(`{"text":"Shutting down","shutdownAt":%q}`, shutdownAt))
+	playing.next(models.EventServerAnnouncement)
+	waiting.next(models.EventServerAnnouncement)
+
+	announcements.mu.Lock()
+	current, scheduled := announcements.current, announcements.warning != nil
+	announcements.mu.Unlock()
+	if !scheduled {
+		t.Fatal("no reminder was scheduled an hour ahead of the shutdown")
+	}
+
+	// The timer firing at T-5 minutes
+	remindShutdown(gm, current)
+	payload, _ := playing.next(models.EventServerAnnouncement).(map[string]interface{})
+	if payload["reminder"] != true || payload["shutdownAt"] == nil {
+		t.Errorf("the game in progress was reminded with %v", payload)
+	}
+	if payload, reminded := waiting.await(models.EventServerAnnouncement, 200*time.Millisecond); reminded {
+		t.Errorf("the waiting room was reminded: %v", payload)
+	}
+}
+
+func TestShutdownReminderFollowsTheAnnouncement(t *testing.T) {
+	withoutAnnouncements(t)
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	code, ids := startedGame(t, gm, 6)
+	client := server.dialPlayer(t, code, ids[1])
+	client.next(models.EventGameStateUpdate)
+
+	soon := time.Now().Add(2 * time.Minute).UTC().Format(time.RFC3339)
+	announce(t, gm, fmt.Sprintf(`{"text":"Shutting down","shutdownAt":%q}`, soon))
+	client.next(models.EventServerAnnouncement)
+	announcements.mu.Lock()
+	replaced, scheduled := announcements.current, announcements.warning != nil
+	announcements.mu.Unlock()
+	if scheduled {
+		t.Error("a reminder was scheduled inside the last five minutes")
+	}
+
+	// A replaced announcement's reminder is not sent
+	announce(t, gm, `{"text":"Never mind"}`)
+	client.next(models.EventServerAnnouncement)
+	remindShutdown(gm, replaced)
+	if payload, reminded := client.await(models.EventServerAnnouncement, 200*time.Millisecond); reminded {
+		t.Errorf("the replaced announcement was repeated: %v", payload)
+	}
+
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	w := serve(t, AdminAnnounce(gm), http.MethodPost, "/admin/announce", "/admin/announce", fmt.Sprintf(`{"text":"Too late","shutdownAt":%q}`, past))
+	wantStatus(t, w, http.StatusBadRequest)
+}
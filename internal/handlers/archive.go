@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// closeRoomClosed is the close code sent to clients of an archived game
+const closeRoomClosed = 4002
+
+// RoomCloser ends the connections of games as they are archived. Each
+// client gets a room_closed close message, and broadcasts still queued for
+// the room are dropped, so a stale client is never left talking to a room
+// that is gone.
+//
+// It is a game.RoomTeardown, so practice rooms are closed too. It runs with
+// the manager lock held; closing only takes the transport's own locks.
+type RoomCloser struct {
+	game.NopObserver
+}
+
+// NewRoomCloser returns a closer for the connections HandleWebSocket
+// serves; pass it to game.NewGameManager
+func NewRoomCloser() *RoomCloser {
+	return &RoomCloser{}
+}
+
+func (*RoomCloser) TeardownRoom(room *models.GameRoom) {
+	if closed := outbound.CloseRoom(room.Code, closeRoomClosed, models.DisconnectRoomClosed, models.DisconnectRoomClosed); closed > 0 {
+		log.Printf("Room %s archived; closed %d connections", room.Code, closed)
+	}
+}
+
+// rejectArchived closes a connection made to an archived game
+func rejectArchived(conn ws.Conn) {
+	conn.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(closeRoomClosed, models.DisconnectRoomClosed))
+	conn.Close()
+}
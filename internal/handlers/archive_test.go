@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestRoomCloserClosesPracticeRooms(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+
+	settings := models.DefaultRoomSettings()
+	settings.PracticeMode = true
+	hostID := "practice-" + uuid.New().String()[:8]
+	room, err := gm.CreateRoom(hostID, "Player1", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	if err := gm.StartGame(room.Code, hostID); err != nil {
+		t.Fatalf("start practice game: %v", err)
+	}
+	client := server.dial(t, url.Values{
+		"roomCode": {room.Code.String()},
+		"playerId": {hostID},
+		"token":    {sessionOf(t, gm, room.Code, hostID)},
+	})
+	client.next(models.EventGameStateUpdate)
+
+	// The manager skips practice rooms for observers, so the closer must
+	// hear of them as a teardown instead
+	teardown, ok := game.RoomObserver(NewRoomCloser()).(game.RoomTeardown)
+	if !ok {
+		t.Fatal("the room closer is not told of practice rooms being archived")
+	}
+	view, _ := gm.RoomView(room.Code, "")
+	teardown.TeardownRoom(view)
+	if code := client.closeCode(); code != closeRoomClosed {
+		t.Errorf("close code %d, want %d", code, closeRoomClosed)
+	}
+}
+
+func TestArchivedChatIsGone(t *testing.T) {
+	gm := game.NewGameManager()
+	stop := gm.StartJanitor(time.Hour, time.Minute)
+	defer stop()
+	code := endedGame(t, gm)
+	server := newTestServer(t, gm)
+	view, _ := gm.RoomView(code, "")
+	var playerID string
+	for id := range view.Players {
+		playerID = id
+		break
+	}
+	token := sessionOf(t, gm, code, playerID)
+
+	if removed := gm.SweepIdleRooms(time.Now().Add(2 * time.Minute)); removed != 1 {
+		t.Fatalf("swept %d rooms, want the ended game", removed)
+	}
+	chat := "/api/rooms/" + code.String() + "/chat"
+	for name, target := range map[string]string{
+		"anonymous":   chat,
+		"as a player": chat + "?playerId=" + url.QueryEscape(playerID),
+	} {
+		status, body := server.do(t, http.MethodGet, target, "", token)
+		if status != http.StatusGone || body["code"] != game.ErrRoomArchived.Code {
+			t.Errorf("%s: %d %v, want 410 %s", name, status, body, game.ErrRoomArchived.Code)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestHostActionsAnnouncedAndListed(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	server := newTestServer(t, gm)
+
+	host := server.dialPlayer(t, code, ids[0])
+	host.next(models.EventGameStateUpdate)
+	watcher := server.dialPlayer(t, code, ids[2])
+	watcher.next(models.EventGameStateUpdate)
+
+	host.send(models.EventMutePlayer, map[string]string{"targetId": ids[3]})
+	host.send(models.EventChatMessage, map[string]string{"content": "done"})
+	var notices []string
+	for {
+		message, _ := watcher.next(models.EventChatMessage).(map[string]interface{})
+		if message["content"] == "done" {
+			break
+		}
+		if message["type"] == string(models.MessageSystem) {
+			notices = append(notices, message["content"].(string))
+		}
+	}
+	if len(notices) != 1 || notices[0] != "Host Player1 muted Player4" {
+		t.Errorf("the room was told %q, want the mute", notices)
+	}
+
+	w := serve(t, AdminHostActions(gm), http.MethodGet, "/admin/rooms/:code/host-actions", "/admin/rooms/"+code.String()+"/host-actions", "")
+	wantStatus(t, w, http.StatusOK)
+	actions, _ := decodeBody(t, w)["hostActions"].([]interface{})
+	if len(actions) != 1 {
+		t.Fatalf("listed %v, want the mute", actions)
+	}
+	if action := actions[0].(map[string]interface{}); action["actorId"] != ids[0] || action["action"] != game.HostActionMute || action["targetId"] != ids[3] {
+		t.Errorf("listed %v", action)
+	}
+
+	w = serve(t, AdminHostActions(gm), http.MethodGet, "/admin/rooms/:code/host-actions", "/admin/rooms/NOPE00/host-actions", "")
+	wantStatus(t, w, http.StatusNotFound)
+}
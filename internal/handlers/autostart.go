@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// refreshAutoStart starts or stops the room's auto-start countdown after a
+// lobby change and tells the room. Call it holding the room's ordering lock.
+func refreshAutoStart(gm *game.GameManager, roomCode models.RoomCode) {
+	countdown, stopped := gm.RefreshAutoStart(roomCode, time.Now())
+	switch {
+	case countdown != nil:
+		broadcastToRoom(roomCode, models.EventAutoStartCountdown, countdown)
+		scheduleAutoStart(gm, roomCode, *countdown)
+	case stopped:
+		broadcastToRoom(roomCode, models.EventAutoStartCancelled, gin.H{"reason": game.AutoStartNotReady})
+	}
+}
+
+// scheduleAutoStart starts the game when the countdown elapses, unless it
+// was stopped by then. The wait is one of the room's goroutines, so it ends
+// with the room.
+func scheduleAutoStart(gm *game.GameManager, roomCode models.RoomCode, countdown game.AutoStartCountdown) {
+	gm.GoRoom(roomCode, func(ctx context.Context) {
+		timer := time.NewTimer(time.Until(countdown.StartsAt.Time))
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		unlock := lockRoom(roomCode)
+		defer unlock()
+
+		started, err := gm.AutoStartGame(roomCode, countdown.StartsAt, time.Now())
+		if err != nil {
+			log.Printf("Room %s auto-start refused: %v", roomCode, err)
+			broadcastToRoom(roomCode, models.EventAutoStartCancelled, gin.H{"reason": game.AutoStartFailed, "error": err.Error()})
+			return
+		}
+		if !started {
+			return
+		}
+
+		broadcastRoomState(gm, roomCode, models.EventGameStarted, nil)
+		sendRoleAssignments(gm, roomCode)
+		broadcastSystemNotices(gm, roomCode)
+	})
+}
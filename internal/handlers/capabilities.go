@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// knownCapabilities are the flags the server acts on; others are ignored
+var knownCapabilities = map[ws.Capability]bool{
+	ws.CapScopedUpdates: true,
+	ws.CapChatReplay:    true,
+}
+
+// defaultCapabilities is what a client that declares nothing gets: what its
+// protocol version has always received
+func defaultCapabilities(protocol int) map[ws.Capability]bool {
+	if protocol == ProtocolV1 {
+		return map[ws.Capability]bool{ws.CapChatReplay: true}
+	}
+	return map[ws.Capability]bool{ws.CapScopedUpdates: true, ws.CapChatReplay: true}
+}
+
+// parseCapabilities reads declared flags, keeping the ones the server knows.
+// It reports false when nothing was declared.
+func parseCapabilities(declared []string) (map[ws.Capability]bool, bool) {
+	if len(declared) == 0 {
+		return nil, false
+	}
+
+	capabilities := make(map[ws.Capability]bool)
+	for _, flag := range declared {
+		capability := ws.Capability(strings.ToLower(strings.TrimSpace(flag)))
+		if knownCapabilities[capability] {
+			capabilities[capability] = true
+		}
+	}
+	return capabilities, true
+}
+
+// splitCapabilities splits the comma-separated query parameter
+func splitCapabilities(query string) []string {
+	if query == "" {
+		return nil
+	}
+	return strings.Split(query, ",")
+}
+
+// capabilityList is the client's flags in a stable order
+func capabilityList(capabilities map[ws.Capability]bool) []ws.Capability {
+	list := make([]ws.Capability, 0, len(capabilities))
+	for capability := range capabilities {
+		list = append(list, capability)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// dialCapable opens a websocket as a player declaring the given
+// comma-separated capabilities, or none at all when empty
+func (s *testServer) dialCapable(t *testing.T, code models.RoomCode, playerID, capabilities string) *wsClient {
+	t.Helper()
+
+	query := url.Values{
+		"roomCode": {code.String()},
+		"playerId": {playerID},
+		"token":    {sessionOf(t, s.gm, code, playerID)},
+	}
+	if capabilities != "" {
+		query.Set("capabilities", capabilities)
+	}
+	return s.dial(t, query)
+}
+
+func TestParseCapabilities(t *testing.T) {
+	tests := []struct {
+		declared []string
+		want     map[ws.Capability]bool
+		ok       bool
+	}{
+		{nil, nil, false},
+		{[]string{"scoped_updates"}, map[ws.Capability]bool{ws.CapScopedUpdates: true}, true},
+		{[]string{" Chat_Replay ", "scoped_updates"}, map[ws.Capability]bool{ws.CapScopedUpdates: true, ws.CapChatReplay: true}, true},
+		// Declaring only what the server does not know still counts as declaring
+		{[]string{"msgpack", "deltas"}, map[ws.Capability]bool{}, true},
+		{splitCapabilities("chat_replay,unknown"), map[ws.Capability]bool{ws.CapChatReplay: true}, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseCapabilities(tt.declared)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parse %q = %v %v, want %v %v", tt.declared, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if got := defaultCapabilities(ProtocolV1); !reflect.DeepEqual(got, map[ws.Capability]bool{ws.CapChatReplay: true}) {
+		t.Errorf("protocol 1 defaults to %v, want chat replay only", got)
+	}
+	if got := defaultCapabilities(CurrentProtocol); !got[ws.CapScopedUpdates] || !got[ws.CapChatReplay] {
+		t.Errorf("protocol %d defaults to %v, want both", CurrentProtocol, got)
+	}
+}
+
+func TestClientsGetWhatTheyDeclared(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	server := newTestServer(t, gm)
+
+	clients := []struct {
+		name         string
+		capabilities string
+		snapshots    bool
+		chatHistory  bool
+	}{
+		{"both", "scoped_updates,chat_replay", false, true},
+		{"replay only", "chat_replay", true, true},
+		{"scoped only", "scoped_updates", false, false},
+		{"unknown only", "msgpack", true, false},
+		{"undeclared", "", false, true},
+	}
+	watched := map[string]bool{
+		models.EventChatHistory:     true,
+		models.EventGameStateUpdate: true,
+		models.EventVotesUpdate:     true,
+		models.EventVoteUpdate:      true,
+	}
+
+	sockets := make([]*wsClient, len(clients))
+	for i, c := range clients {
+		sockets[i] = server.dialCapable(t, code, ids[i+1], c.capabilities)
+		sockets[i].next(models.EventGameStateUpdate)
+	}
+	// Each arrival set off updates for those already in; the marker comes after them all
+	sockets[0].send(models.EventChatMessage, map[string]string{"content": "sync"})
+	for i, c := range clients {
+		var history bool
+		for _, event := range sockets[i].eventsBefore("sync", watched) {
+			history = history || event == models.EventChatHistory
+		}
+		if history != c.chatHistory {
+			t.Errorf("%s: chat history on connect %v, want %v", c.name, history, c.chatHistory)
+		}
+	}
+
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("move to the vote: %v", err)
+	}
+	sockets[0].send(models.EventVote, map[string]string{"targetId": ids[0]})
+	sockets[0].send(models.EventChatMessage, map[string]string{"content": "voted"})
+	for i, c := range clients {
+		want := []string{models.EventVotesUpdate}
+		if c.snapshots {
+			want = []string{models.EventVoteUpdate}
+		}
+		if seen := sockets[i].eventsBefore("voted", watched); !reflect.DeepEqual(seen, want) {
+			t.Errorf("%s: a vote set off %v, want %v", c.name, seen, want)
+		}
+	}
+}
+
+func TestHelloRedeclaresCapabilities(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	server := newTestServer(t, gm)
+
+	client := server.dialCapable(t, code, ids[1], "scoped_updates")
+	client.next(models.EventGameStateUpdate)
+
+	client.send(models.EventHello, map[string]interface{}{
+		"protocol":     CurrentProtocol,
+		"capabilities": []string{"chat_replay", "msgpack", "CHAT_REPLAY"},
+	})
+	reply, _ := client.next(models.EventHello).(map[string]interface{})
+	if got := reply["capabilities"]; !reflect.DeepEqual(got, []interface{}{"chat_replay"}) {
+		t.Errorf("hello echoed %v, want only chat_replay", got)
+	}
+
+	// Having dropped scoped updates, the client is sent the whole room
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("move to the vote: %v", err)
+	}
+	client.send(models.EventVote, map[string]string{"targetId": ids[0]})
+	client.send(models.EventChatMessage, map[string]string{"content": "voted"})
+	seen := client.eventsBefore("voted", map[string]bool{models.EventVoteUpdate: true, models.EventVotesUpdate: true})
+	if !reflect.DeepEqual(seen, []string{models.EventVoteUpdate}) {
+		t.Errorf("after the hello a vote set off %v, want a snapshot", seen)
+	}
+
+	// A hello that declares nothing falls back to the protocol's defaults
+	client.send(models.EventHello, map[string]interface{}{"protocol": ProtocolV1})
+	reply, _ = client.next(models.EventHello).(map[string]interface{})
+	if got := reply["capabilities"]; !reflect.DeepEqual(got, []interface{}{"chat_replay"}) {
+		t.Errorf("protocol 1 hello echoed %v, want its defaults", got)
+	}
+}
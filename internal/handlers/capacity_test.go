@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+)
+
+// createRoomAt posts a room creation straight to the handler
+func createRoomAt(t *testing.T, gm *game.GameManager, username string) (int, http.Header, map[string]interface{}) {
+	t.Helper()
+
+	w := serve(t, CreateRoom(gm), http.MethodPost, "/api/rooms", "/api/rooms", `{"username":"`+username+`"}`)
+	return w.Code, w.Header(), decodeBody(t, w)
+}
+
+func TestCreateRoomAtCapacity(t *testing.T) {
+	gm := game.NewGameManager()
+	gm.MaxRooms = 2
+	stop := gm.StartJanitor(time.Hour, time.Minute)
+	defer stop()
+
+	for _, host := range []string{"Ann", "Bo"} {
+		if status, _, body := createRoomAt(t, gm, host); status != http.StatusCreated {
+			t.Fatalf("room for %s: %d %v", host, status, body)
+		}
+	}
+
+	status, header, body := createRoomAt(t, gm, "Cy")
+	if status != http.StatusServiceUnavailable || body["code"] != game.ErrServerAtCapacity.Code || body["messageKey"] != game.ErrServerAtCapacity.MessageKey() {
+		t.Fatalf("a third room: %d %v, want 503 %s", status, body, game.ErrServerAtCapacity.Code)
+	}
+	capacity, _ := body["params"].(map[string]interface{})
+	if capacity["roomsUsed"] != 2.0 || capacity["roomCap"] != 2.0 {
+		t.Errorf("capacity = %v, want 2 of 2 used", capacity)
+	}
+	// Both rooms are fresh, so the janitor run in an hour is the first that can free one
+	retry, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || retry < 3590 || retry > 3600 || float64(retry) != capacity["retryAfterSeconds"] {
+		t.Errorf("Retry-After %q, body %v; want about an hour in both", header.Get("Retry-After"), capacity["retryAfterSeconds"])
+	}
+
+	// The admin listing shows the same numbers
+	listing := decodeBody(t, serve(t, AdminListRooms(gm), http.MethodGet, "/admin/rooms", "/admin/rooms", ""))
+	if listed, _ := listing["capacity"].(map[string]interface{}); listed["roomsUsed"] != 2.0 || listed["roomCap"] != 2.0 {
+		t.Errorf("admin capacity = %v, want 2 of 2 used", listed)
+	}
+
+	// Once the janitor reclaims the idle rooms the retry goes through
+	if removed := gm.SweepIdleRooms(time.Now().Add(2 * time.Minute)); removed != 2 {
+		t.Fatalf("swept %d rooms, want both", removed)
+	}
+	if status, header, body := createRoomAt(t, gm, "Cy"); status != http.StatusCreated || header.Get("Retry-After") != "" {
+		t.Errorf("retry: %d %v", status, body)
+	}
+}
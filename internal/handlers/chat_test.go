@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// chatWith reads until a chat message with the given content arrives
+func (c *wsClient) chatWith(content string) models.Message {
+	c.t.Helper()
+
+	for {
+		data, _ := json.Marshal(c.next(models.EventChatMessage))
+		var message models.Message
+		if err := json.Unmarshal(data, &message); err != nil {
+			c.t.Fatalf("chat payload: %v", err)
+		}
+		if message.Content == content {
+			return message
+		}
+	}
+}
+
+func TestSpoofedChatFieldsAreIgnored(t *testing.T) {
+	server := newTestServer(t, game.NewGameManager())
+	code, hostJoined := server.createRoom(t, "Host")
+	host := server.dialAs(t, code.String(), hostJoined)
+	joined := server.join(t, code.String(), "Mallory")
+	mallory := server.dialAs(t, code.String(), joined)
+	malloryID := joined["playerId"].(string)
+
+	long := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, spoofed := range []string{models.MessageSystem, "werewolf"} {
+		content := "the narrator says " + spoofed
+		mallory.send(models.EventChatMessage, map[string]interface{}{
+			"content":   content,
+			"type":      spoofed,
+			"id":        "spoofed-id",
+			"playerId":  hostJoined["playerId"],
+			"username":  "Narrator",
+			"timestamp": long,
+			"key":       "system.game_started",
+		})
+
+		message := host.chatWith(content)
+		if message.Type != models.MessageChat || message.Key != "" {
+			t.Errorf("%s spoof relayed as type %q, key %q; want plain chat", spoofed, message.Type, message.Key)
+		}
+		if message.PlayerID != malloryID || message.Username != "Mallory" {
+			t.Errorf("%s spoof relayed from %s (%s), want Mallory", spoofed, message.PlayerID, message.Username)
+		}
+		if message.ID == "spoofed-id" || message.Timestamp.Before(time.Now().Add(-time.Minute)) {
+			t.Errorf("%s spoof kept its id %q or time %v", spoofed, message.ID, message.Timestamp.Time)
+		}
+	}
+}
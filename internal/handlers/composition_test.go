@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestHostWarnedWhenJoinsOutgrowComposition(t *testing.T) {
+	gm := game.NewGameManager()
+	settings := models.DefaultRoomSettings()
+	settings.Composition = game.DefaultComposition(6)
+	room, err := gm.CreateRoom("host", "Host", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for i := 2; i <= 5; i++ {
+		if _, err := gm.JoinRoom(room.Code, fmt.Sprintf("p%d", i), fmt.Sprintf("Player%d", i)); err != nil {
+			t.Fatalf("join p%d: %v", i, err)
+		}
+	}
+	server := newTestServer(t, gm)
+	host := server.dialPlayer(t, room.Code, "host")
+
+	// The sixth fills the composition; the seventh outgrows it
+	server.join(t, room.Code.String(), "Sixth")
+	server.join(t, room.Code.String(), "Seventh")
+
+	warning, _ := host.next(models.EventCompositionOutdated).(map[string]interface{})
+	if warning["players"] != float64(7) {
+		t.Fatalf("warned about %v players, want the first warning at 7", warning["players"])
+	}
+	if warning["acceptEvent"] != models.EventAcceptSuggestedComposition {
+		t.Errorf("acceptEvent = %v", warning["acceptEvent"])
+	}
+	suggested, _ := warning["suggested"].(map[string]interface{})
+	total := 0.0
+	for _, count := range suggested {
+		total += count.(float64)
+	}
+	if total != 7 {
+		t.Errorf("suggested %v, want roles for 7", suggested)
+	}
+
+	// Every further join warns again
+	server.join(t, room.Code.String(), "Eighth")
+	if warning, _ := host.next(models.EventCompositionOutdated).(map[string]interface{}); warning["players"] != float64(8) {
+		t.Errorf("warned about %v players, want 8", warning["players"])
+	}
+}
+
+func TestHostWarnedWhenLeavesBreakComposition(t *testing.T) {
+	gm := game.NewGameManager()
+	settings := models.DefaultRoomSettings()
+	settings.Composition = game.DefaultComposition(7)
+	room, err := gm.CreateRoom("host", "Host", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for i := 2; i <= 7; i++ {
+		if _, err := gm.JoinRoom(room.Code, fmt.Sprintf("p%d", i), fmt.Sprintf("Player%d", i)); err != nil {
+			t.Fatalf("join p%d: %v", i, err)
+		}
+	}
+	server := newTestServer(t, gm)
+	host := server.dialPlayer(t, room.Code, "host")
+	host.next(models.EventGameStateUpdate)
+
+	host.send(models.EventKickPlayer, map[string]string{"targetId": "p7"})
+	warning, _ := host.next(models.EventCompositionInvalid).(map[string]interface{})
+	suggested, _ := warning["suggested"].(map[string]interface{})
+	if warning["players"] != float64(6) || suggested[string(models.RoleAlphaTiger)] != nil || suggested[string(models.RoleVillager)] != float64(3) {
+		t.Fatalf("warning = %v, want the default for 6", warning)
+	}
+
+	host.send(models.EventAcceptSuggestedComposition, nil)
+	changed, _ := host.next(models.EventSettingsChanged).(map[string]interface{})
+	applied, _ := changed["settings"].(map[string]interface{})
+	if composition, _ := applied["composition"].(map[string]interface{}); len(composition) != len(suggested) {
+		t.Errorf("composition = %v, want the suggestion %v", composition, suggested)
+	}
+	if err := gm.StartGame(room.Code, "host"); err != nil {
+		t.Errorf("start after accepting: %v", err)
+	}
+}
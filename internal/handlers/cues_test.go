@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// documentedCues maps each event type to the cues the catalog says it may carry
+func documentedCues() map[string]map[string]bool {
+	documented := make(map[string]map[string]bool)
+	for _, info := range models.CueCatalog {
+		for _, eventType := range info.Events {
+			if documented[eventType] == nil {
+				documented[eventType] = make(map[string]bool)
+			}
+			documented[eventType][info.Cue] = true
+		}
+	}
+	return documented
+}
+
+// awaitCue reads until an event of the given type arrives carrying cue,
+// failing the test if any frame read on the way carries a cue the catalog
+// does not list for its event
+func (c *wsClient) awaitCue(eventType, cue string) {
+	c.t.Helper()
+
+	documented := documentedCues()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+	for {
+		var frame struct {
+			Type string `json:"type"`
+			Cue  string `json:"cue"`
+		}
+		if err := c.conn.ReadJSON(&frame); err != nil {
+			c.t.Fatalf("no %s with cue %s arrived: %v", eventType, cue, err)
+		}
+		if frame.Cue != "" && !documented[frame.Type][frame.Cue] {
+			c.t.Errorf("%s carries the undocumented cue %s", frame.Type, frame.Cue)
+		}
+		if frame.Type == eventType && frame.Cue == cue {
+			return
+		}
+	}
+}
+
+func TestEventsCarryTheirFixedCues(t *testing.T) {
+	for eventType, cue := range models.EventCues {
+		data, err := encodeMessage(eventType, nil)
+		if err != nil {
+			t.Fatalf("encode %s: %v", eventType, err)
+		}
+		var frame models.WSMessage
+		if err := json.Unmarshal(data, &frame); err != nil || frame.Cue != cue {
+			t.Errorf("%s carries cue %q, want %q", eventType, frame.Cue, cue)
+		}
+	}
+
+	data, _ := encodeMessage(models.EventChatMessage, nil)
+	var frame map[string]interface{}
+	json.Unmarshal(data, &frame)
+	if _, ok := frame["cue"]; ok {
+		t.Errorf("an uncued event has a cue field: %s", data)
+	}
+}
+
+func TestGameCues(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+
+	settings := models.DefaultRoomSettings()
+	settings.MinDiscussionSeconds = 0
+	settings.QuorumFraction = 0
+	prefix := uuid.New().String()[:8]
+	ids := make([]string, 6)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s-p%d", prefix, i+1)
+	}
+	room, err := gm.CreateRoom(ids[0], "Player1", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	code := room.Code
+	for i, id := range ids[1:] {
+		if _, err := gm.JoinRoom(code, id, fmt.Sprintf("Player%d", i+2)); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+	}
+	clients := make(map[string]*wsClient)
+	for _, id := range ids {
+		clients[id] = server.dial(t, url.Values{"roomCode": {code.String()}, "playerId": {id}, "token": {sessionOf(t, gm, code, id)}})
+		clients[id].next(models.EventGameStateUpdate)
+	}
+	host := clients[ids[0]]
+
+	host.send(models.EventStartGame, nil)
+	for _, client := range clients {
+		client.awaitCue(models.EventGameStarted, models.CueGameStart)
+		client.awaitCue(models.EventRoleAssigned, models.CueRoleReveal)
+	}
+	roles := make(map[models.Role]string)
+	for id, assignment := range gm.RoleAssignments(code) {
+		roles[assignment.Role] = id
+	}
+	hunter, tiger := roles[models.RoleHunter], roles[models.RoleTiger]
+
+	host.send(models.EventSkipPhase, nil)
+	host.awaitCue(models.EventPhaseUpdate, models.CueVoteDrums)
+
+	// Everyone votes out the hunter, the host last so the handler sees it complete
+	for _, id := range ids {
+		target := hunter
+		if id == hunter {
+			target = tiger
+		}
+		if id == ids[0] {
+			host.send(models.EventVote, map[string]string{"targetId": target})
+			continue
+		}
+		if err := gm.Vote(code, id, target); err != nil {
+			t.Fatalf("%s votes: %v", id, err)
+		}
+	}
+	host.awaitCue(models.EventVotingComplete, models.CueVoteClosed)
+	host.send(models.EventSkipPhase, nil)
+	host.awaitCue(models.EventPhaseUpdate, models.CueDeathReveal)
+	clients[hunter].awaitCue(models.EventHunterPrompt, models.CueHunterAim)
+
+	// The hunter takes a villager with them
+	var victim string
+	for _, id := range ids {
+		if id != ids[0] && id != hunter && id != tiger {
+			victim = id
+			break
+		}
+	}
+	clients[hunter].send(models.EventHunterShoot, map[string]interface{}{"targetId": victim, "confirm": true})
+	host.awaitCue(models.EventPlayersUpdate, models.CueGunshot)
+
+	host.send(models.EventSkipPhase, nil)
+	host.awaitCue(models.EventPhaseUpdate, models.CueNightFalls)
+
+	// Each turn holder is prompted with their own cue, then skips
+	skipped := make(map[string]bool)
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		if time.Now().After(deadline) {
+			t.Fatal("the night's turns never finished")
+		}
+		if phase, _ := gm.RoomPhase(code); phase != models.PhaseNight {
+			break
+		}
+		prompts, _ := gm.CurrentTurnPrompts(code)
+		for id, prompt := range prompts {
+			if skipped[prompt.TurnToken] {
+				continue
+			}
+			skipped[prompt.TurnToken] = true
+			cue := models.CueYourTurn
+			if prompt.Role == models.RoleTiger || prompt.Role == models.RoleAlphaTiger {
+				cue = models.CueTigerHowl
+			}
+			clients[id].awaitCue(models.EventYourTurn, cue)
+			clients[id].send(models.EventSkipAction, map[string]string{"turnToken": prompt.TurnToken})
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	host.awaitCue(models.EventPhaseUpdate, models.CueDayBreaks)
+
+	// The village votes out the tiger and the game is over
+	host.send(models.EventSkipPhase, nil)
+	host.awaitCue(models.EventPhaseUpdate, models.CueVoteDrums)
+	view, _ := gm.RoomView(code, "")
+	for _, voter := range view.LivingPlayers() {
+		target := tiger
+		if voter.ID == tiger {
+			target = view.LivingPlayers()[0].ID
+			if target == tiger {
+				target = view.LivingPlayers()[1].ID
+			}
+		}
+		if err := gm.Vote(code, voter.ID, target); err != nil {
+			t.Fatalf("%s votes: %v", voter.ID, err)
+		}
+	}
+	host.send(models.EventSkipPhase, nil)
+	host.awaitCue(models.EventGameEnded, models.CueGameOver)
+}
+
+func TestCueCatalogIsServed(t *testing.T) {
+	w := serve(t, ListCues(), http.MethodGet, "/cues", "/cues", "")
+	wantStatus(t, w, http.StatusOK)
+
+	var body struct {
+		Cues []models.CueInfo `json:"cues"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Cues) != len(models.CueCatalog) {
+		t.Fatalf("served %d cues, want %d", len(body.Cues), len(models.CueCatalog))
+	}
+	for i, info := range body.Cues {
+		want := models.CueCatalog[i]
+		if info.Cue != want.Cue || info.Private != want.Private || len(info.Events) != len(want.Events) {
+			t.Errorf("cue %d = %+v, want %+v", i, info, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// MountDebug adds the Go profiler under /debug/pprof and a room inspector at
+// /debug/rooms/:code, both behind the admin token. Only mount them where
+// they are wanted: the profiler can slow the server while it samples.
+func MountDebug(router *gin.Engine, gm *game.GameManager, adminToken string) {
+	debug := router.Group("/debug", AdminAuth(adminToken))
+	debug.GET("/pprof/*profile", debugProfile)
+	debug.GET("/rooms/:code", DebugRoom(gm))
+}
+
+// debugProfile serves the profile named in the path, or the index
+func debugProfile(c *gin.Context) {
+	switch c.Param("profile") {
+	case "/cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "/profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "/symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "/trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// Index serves named profiles such as goroutine and heap too
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// DebugRoom dumps a room's unredacted internal state with its deadlines,
+// its outbound queue, the connections in it and the latency they measured
+func DebugRoom(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+		now := time.Now()
+
+		state, err := gm.RecordFixture(code)
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		timers, err := gm.RoomTimers(code, now)
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		clients := []ws.ClientStats{}
+		for _, stats := range hub.ClientStats(now) {
+			if stats.RoomCode == code {
+				clients = append(clients, stats)
+			}
+		}
+
+		body := gin.H{
+			"room":       json.RawMessage(state),
+			"timers":     timers,
+			"queueDepth": outbound.QueueDepth(code),
+			"clients":    clients,
+		}
+		if measured, ok := latency.Room(code, now); ok {
+			body["latency"] = measured
+		}
+		c.JSON(http.StatusOK, body)
+	}
+}
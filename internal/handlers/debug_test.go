@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const debugToken = "debug-secret"
+
+// debugRequest sends a GET to the router with the bearer token, if any
+func debugRequest(router *gin.Engine, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestDebugEndpointsAbsentUnlessMounted(t *testing.T) {
+	gm := game.NewGameManager()
+	code, _ := startedGame(t, gm, 6)
+
+	// Without DEBUG_ENDPOINTS the server never mounts them
+	router := gin.New()
+	for _, path := range []string{"/debug/pprof/", "/debug/rooms/" + code.String()} {
+		if w := debugRequest(router, path, debugToken); w.Code != http.StatusNotFound {
+			t.Errorf("%s answered %d without the flag, want 404", path, w.Code)
+		}
+	}
+}
+
+func TestDebugEndpointsNeedTheAdminToken(t *testing.T) {
+	gm := game.NewGameManager()
+	code, _ := startedGame(t, gm, 6)
+
+	router := gin.New()
+	MountDebug(router, gm, debugToken)
+	for _, path := range []string{"/debug/pprof/", "/debug/rooms/" + code.String()} {
+		if w := debugRequest(router, path, ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s answered %d with no token, want 401", path, w.Code)
+		}
+		if w := debugRequest(router, path, "wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s answered %d with a wrong token, want 401", path, w.Code)
+		}
+	}
+
+	// With no admin token configured there is nothing to authenticate with
+	closed := gin.New()
+	MountDebug(closed, gm, "")
+	if w := debugRequest(closed, "/debug/rooms/"+code.String(), ""); w.Code != http.StatusNotFound {
+		t.Errorf("answered %d with no admin token set, want 404", w.Code)
+	}
+}
+
+func TestDebugRoomDumpsHiddenState(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+
+	router := gin.New()
+	MountDebug(router, gm, debugToken)
+	w := debugRequest(router, "/debug/rooms/"+code.String(), debugToken)
+	wantStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	for _, field := range []string{"room", "timers", "queueDepth", "clients"} {
+		if _, ok := body[field]; !ok {
+			t.Errorf("the dump has no %s: %v", field, body)
+		}
+	}
+	fixture, _ := body["room"].(map[string]interface{})
+	room, _ := fixture["room"].(map[string]interface{})
+	players, _ := room["players"].(map[string]interface{})
+	for _, id := range ids {
+		player, _ := players[id].(map[string]interface{})
+		if role, _ := player["role"].(string); role == "" {
+			t.Errorf("%s's role is not in the dump", id)
+		}
+	}
+	if phase, _ := room["phase"].(string); phase != string(models.PhaseDay) {
+		t.Errorf("the dumped phase is %q", phase)
+	}
+
+	if w := debugRequest(router, "/debug/rooms/NOPE", debugToken); w.Code != http.StatusNotFound {
+		t.Errorf("an unknown room answered %d, want 404", w.Code)
+	}
+}
+
+func TestDebugProfilerIndex(t *testing.T) {
+	router := gin.New()
+	MountDebug(router, game.NewGameManager(), debugToken)
+
+	w := debugRequest(router, "/debug/pprof/", debugToken)
+	wantStatus(t, w, http.StatusOK)
+	w = debugRequest(router, "/debug/pprof/goroutine?debug=1", debugToken)
+	wantStatus(t, w, http.StatusOK)
+}
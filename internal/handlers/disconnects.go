@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+)
+
+// AdminDisconnects lists a room's recent disconnects and why they happened,
+// for support
+func AdminDisconnects(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		disconnects, err := gm.DisconnectLog(gm.ResolveRoomCode(c.Param("code")))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"disconnects": disconnects})
+	}
+}
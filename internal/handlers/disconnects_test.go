@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// disconnectOf waits for the player's connection to be recorded as ended
+// and returns the record
+func disconnectOf(t *testing.T, gm *game.GameManager, code models.RoomCode, playerID string) models.DisconnectRecord {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		records, err := gm.DisconnectLog(code)
+		if err != nil {
+			t.Fatalf("disconnect log: %v", err)
+		}
+		for _, record := range records {
+			if record.PlayerID == playerID {
+				return record
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no disconnect was recorded for %s", playerID)
+	return models.DisconnectRecord{}
+}
+
+// connectedGuest is a room with a connected host and a connected guest
+func connectedGuest(t *testing.T) (*testServer, models.RoomCode, *wsClient, map[string]interface{}) {
+	t.Helper()
+
+	server := newTestServer(t, game.NewGameManager())
+	code, host := server.createRoom(t, "Ann")
+	hostClient := server.dialAs(t, code.String(), host)
+	hostClient.next(models.EventGameStateUpdate)
+
+	guest := server.join(t, code.String(), "Ben")
+	return server, code, hostClient, guest
+}
+
+func TestDisconnectReasons(t *testing.T) {
+	for _, tc := range []struct {
+		reason string
+		end    func(t *testing.T, server *testServer, host, guest *wsClient, joined map[string]interface{})
+	}{
+		{models.DisconnectClientClosed, func(t *testing.T, _ *testServer, _, guest *wsClient, _ map[string]interface{}) {
+			guest.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		}},
+		{models.DisconnectConnectionLost, func(t *testing.T, _ *testServer, _, guest *wsClient, _ map[string]interface{}) {
+			guest.conn.UnderlyingConn().Close()
+		}},
+		{models.DisconnectSuperseded, func(t *testing.T, server *testServer, _, _ *wsClient, joined map[string]interface{}) {
+			code := joined["room"].(map[string]interface{})["code"].(string)
+			server.dialAs(t, code, joined).next(models.EventGameStateUpdate)
+		}},
+		{models.DisconnectKicked, func(t *testing.T, _ *testServer, host, guest *wsClient, joined map[string]interface{}) {
+			host.send(models.EventKickPlayer, map[string]string{"targetId": joined["playerId"].(string)})
+			guest.next(models.EventKicked)
+			guest.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		}},
+		{models.DisconnectUnsupportedProtocol, func(t *testing.T, _ *testServer, _, guest *wsClient, _ map[string]interface{}) {
+			guest.send(models.EventHello, map[string]int{"protocol": 99})
+			if code := guest.closeCode(); code != closeUnsupportedProtocol {
+				t.Errorf("close code %d, want %d", code, closeUnsupportedProtocol)
+			}
+		}},
+	} {
+		t.Run(tc.reason, func(t *testing.T) {
+			server, code, host, joined := connectedGuest(t)
+			guest := server.dialAs(t, code.String(), joined)
+			guest.next(models.EventGameStateUpdate)
+
+			before := server.gm.DisconnectCounts()[tc.reason]
+			tc.end(t, server, host, guest, joined)
+
+			record := disconnectOf(t, server.gm, code, joined["playerId"].(string))
+			if record.Reason != tc.reason {
+				t.Errorf("recorded %q, want %q", record.Reason, tc.reason)
+			}
+			if record.AddrHash != ws.HashAddr("127.0.0.1") || strings.Contains(record.AddrHash, "127.0.0.1") {
+				t.Errorf("address recorded as %q", record.AddrHash)
+			}
+			if got := server.gm.DisconnectCounts()[tc.reason]; got != before+1 {
+				t.Errorf("%s count went from %d to %d", tc.reason, before, got)
+			}
+		})
+	}
+}
+
+func TestDisconnectsGoWithTheRoom(t *testing.T) {
+	server, code, _, joined := connectedGuest(t)
+	guest := server.dialAs(t, code.String(), joined)
+	guest.next(models.EventGameStateUpdate)
+	guest.conn.UnderlyingConn().Close()
+	disconnectOf(t, server.gm, code, joined["playerId"].(string))
+
+	// The room is cleaned up once its last player leaves
+	view, _ := server.gm.RoomView(code, "")
+	for id := range view.Players {
+		if err := server.gm.RemovePlayer(code, id); err != nil {
+			t.Fatalf("remove %s: %v", id, err)
+		}
+	}
+	if _, err := server.gm.DisconnectLog(code); err == nil {
+		t.Error("a cleaned-up room still has a disconnect log")
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/game"
+)
+
+// Error codes for request bodies that fail to bind
+const (
+	CodeInvalidJSON   = "INVALID_JSON"
+	CodeMissingField  = "MISSING_FIELD"
+	CodeFieldTooLong  = "FIELD_TOO_LONG"
+	CodeFieldTooShort = "FIELD_TOO_SHORT"
+	CodeFieldTooLarge = "FIELD_TOO_LARGE"
+	CodeFieldTooSmall = "FIELD_TOO_SMALL"
+	CodeInvalidField  = "INVALID_FIELD"
+)
+
+// fieldError is a request body problem in terms a client can show: a code,
+// the JSON field at fault and the rule's parameters
+type fieldError struct {
+	Code    string
+	Field   string
+	Params  map[string]interface{}
+	message string
+}
+
+func (e *fieldError) Error() string {
+	return e.message
+}
+
+func init() {
+	// Report fields by their JSON names, never as Go struct paths
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// bindJSON decodes the request body into req, answering 400 with a
+// translated error when it does not bind
+func bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		respondError(c, http.StatusBadRequest, translateBindingError(err))
+		return false
+	}
+	return true
+}
+
+// translateBindingError turns a JSON or validator error into a fieldError
+func translateBindingError(err error) *fieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) && len(validationErrors) > 0 {
+		return translateFieldError(validationErrors[0])
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &fieldError{
+			Code:    CodeInvalidField,
+			Field:   typeErr.Field,
+			Params:  map[string]interface{}{"expected": typeErr.Type.Kind().String()},
+			message: fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type.Kind()),
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		return &fieldError{Code: CodeInvalidJSON, message: "request body is empty"}
+	}
+	return &fieldError{Code: CodeInvalidJSON, message: "request body is not valid JSON"}
+}
+
+// translateFieldError maps one failed validation rule to its code
+func translateFieldError(fe validator.FieldError) *fieldError {
+	field := fe.Field()
+	numeric := fe.Kind() >= reflect.Int && fe.Kind() <= reflect.Float64
+
+	switch fe.Tag() {
+	case "required":
+		return &fieldError{Code: CodeMissingField, Field: field, message: field + " is required"}
+
+	case "max":
+		if numeric {
+			return &fieldError{Code: CodeFieldTooLarge, Field: field, Params: map[string]interface{}{"max": ruleParam(fe)},
+				message: fmt.Sprintf("%s must be at most %s", field, fe.Param())}
+		}
+		return &fieldError{Code: CodeFieldTooLong, Field: field, Params: map[string]interface{}{"max": ruleParam(fe)},
+			message: fmt.Sprintf("%s must be at most %s characters", field, fe.Param())}
+
+	case "min":
+		if numeric {
+			return &fieldError{Code: CodeFieldTooSmall, Field: field, Params: map[string]interface{}{"min": ruleParam(fe)},
+				message: fmt.Sprintf("%s must be at least %s", field, fe.Param())}
+		}
+		return &fieldError{Code: CodeFieldTooShort, Field: field, Params: map[string]interface{}{"min": ruleParam(fe)},
+			message: fmt.Sprintf("%s must be at least %s characters", field, fe.Param())}
+	}
+
+	return &fieldError{Code: CodeInvalidField, Field: field, Params: map[string]interface{}{"rule": fe.Tag()},
+		message: field + " is invalid"}
+}
+
+// ruleParam is a rule's parameter as a number when it is one
+func ruleParam(fe validator.FieldError) interface{} {
+	if n, err := strconv.Atoi(fe.Param()); err == nil {
+		return n
+	}
+	return fe.Param()
+}
+
+// respondError answers with the REST error envelope: the English message,
+// plus the code, message key, field and parameters when the error has them.
+// The code is found anywhere in err's wrap chain. An archived game is
+// always 410 Gone, whatever status the caller chose.
+func respondError(c *gin.Context, status int, err error) {
+	if errors.Is(err, game.ErrRoomArchived) {
+		status = http.StatusGone
+	}
+	c.JSON(status, errorBody(err))
+}
+
+// errorBody is the error envelope shared by REST responses and websocket
+// error frames
+func errorBody(err error) gin.H {
+	body := gin.H{"error": err.Error()}
+
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		body["code"] = fe.Code
+		body["messageKey"] = errs.MessageKey(fe.Code)
+		if fe.Field != "" {
+			body["field"] = fe.Field
+		}
+		if len(fe.Params) > 0 {
+			body["params"] = fe.Params
+		}
+		return body
+	}
+
+	if e, ok := errs.Coded(err); ok {
+		body["code"] = e.Code
+		body["messageKey"] = e.MessageKey()
+		if len(e.Params) > 0 {
+			body["params"] = e.Params
+		}
+	}
+	return body
+}
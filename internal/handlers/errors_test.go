@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// boundsRequest carries the rules no endpoint uses yet
+type boundsRequest struct {
+	Name  string `json:"name" binding:"min=3"`
+	Seats int    `json:"seats" binding:"min=5"`
+	Code  string `json:"code" binding:"omitempty,alphanum"`
+}
+
+func bindBounds(c *gin.Context) {
+	var req boundsRequest
+	if bindJSON(c, &req) {
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func TestBindingErrorsAreTranslated(t *testing.T) {
+	gm := game.NewGameManager()
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		route   string
+		body    string
+		code    string
+		field   string
+		params  map[string]interface{}
+	}{
+		{
+			name: "required", handler: CreateRoom(gm), route: "/rooms",
+			body: `{}`, code: CodeMissingField, field: "username",
+		},
+		{
+			name: "max on a string", handler: CreateRoom(gm), route: "/rooms",
+			body: `{"username":"` + strings.Repeat("x", 33) + `"}`, code: CodeFieldTooLong, field: "username",
+			params: map[string]interface{}{"max": float64(32)},
+		},
+		{
+			name: "max on a number", handler: CreateInvites(gm), route: "/rooms/:code/invites",
+			body: `{"playerId":"p1","count":21}`, code: CodeFieldTooLarge, field: "count",
+			params: map[string]interface{}{"max": float64(20)},
+		},
+		{
+			name: "min on a string", handler: bindBounds, route: "/bounds",
+			body: `{"name":"ab","seats":5}`, code: CodeFieldTooShort, field: "name",
+			params: map[string]interface{}{"min": float64(3)},
+		},
+		{
+			name: "min on a number", handler: bindBounds, route: "/bounds",
+			body: `{"name":"abc","seats":4}`, code: CodeFieldTooSmall, field: "seats",
+			params: map[string]interface{}{"min": float64(5)},
+		},
+		{
+			name: "any other rule", handler: bindBounds, route: "/bounds",
+			body: `{"name":"abc","seats":5,"code":"a-b"}`, code: CodeInvalidField, field: "code",
+			params: map[string]interface{}{"rule": "alphanum"},
+		},
+		{
+			name: "wrong type", handler: CreateRoom(gm), route: "/rooms",
+			body: `{"username":7}`, code: CodeInvalidField, field: "username",
+			params: map[string]interface{}{"expected": "string"},
+		},
+		{
+			name: "not JSON", handler: CreateRoom(gm), route: "/rooms",
+			body: `{"username":`, code: CodeInvalidJSON,
+		},
+		{
+			name: "empty body", handler: CreateRoom(gm), route: "/rooms",
+			body: ``, code: CodeInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := strings.Replace(tt.route, ":code", "ABCDEF", 1)
+			w := serve(t, tt.handler, http.MethodPost, tt.route, target, tt.body)
+			wantStatus(t, w, http.StatusBadRequest)
+			body := decodeBody(t, w)
+
+			if body["code"] != tt.code {
+				t.Errorf("code = %v, want %s", body["code"], tt.code)
+			}
+			if body["messageKey"] == nil || body["messageKey"] == "" {
+				t.Error("no message key")
+			}
+			if field, _ := body["field"].(string); field != tt.field {
+				t.Errorf("field = %q, want %q", field, tt.field)
+			}
+			if params, _ := body["params"].(map[string]interface{}); tt.params != nil && !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("params = %v, want %v", params, tt.params)
+			}
+
+			// Go struct paths and validator wording never reach the client
+			message, _ := body["error"].(string)
+			for _, leak := range []string{"Key:", "Request.", "Error:Field", "tag"} {
+				if strings.Contains(message, leak) {
+					t.Errorf("message %q leaks %q", message, leak)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorBodyCarriesCodeFromWrapChain(t *testing.T) {
+	wrapped := fmt.Errorf("lookup: %w", game.ErrRoomNotFound)
+	body := errorBody(wrapped)
+
+	if body["code"] != game.ErrRoomNotFound.Code {
+		t.Errorf("code = %v, want %s", body["code"], game.ErrRoomNotFound.Code)
+	}
+	if body["error"] != wrapped.Error() {
+		t.Errorf("error = %v", body["error"])
+	}
+}
+
+func TestErrorBodyMapping(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		code   string
+		params map[string]interface{}
+	}{
+		{
+			name: "sentinel",
+			err:  game.ErrNotVotingPhase,
+			code: "NOT_VOTING_PHASE",
+		},
+		{
+			name: "wrapped with context",
+			err:  errs.Wrap(errs.Wrap(game.ErrPlayerNotFound, "player %s", "p9"), "room %s", "ABC123"),
+			code: "PLAYER_NOT_FOUND",
+		},
+		{
+			name:   "with params",
+			err:    errs.Wrap(game.ErrOnCooldown.WithParams(map[string]interface{}{"nights": 2}), "protect"),
+			code:   "ON_COOLDOWN",
+			params: map[string]interface{}{"nights": float64(2)},
+		},
+		{
+			name: "coded cause under an uncoded error",
+			err:  &errs.Error{Message: "could not seat the player", Err: game.ErrRoomFull},
+			code: "ROOM_FULL",
+		},
+		{
+			name: "uncoded",
+			err:  errors.New("disk on fire"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Round trip through JSON, as a client would read it
+			w := serve(t, func(c *gin.Context) { respondError(c, http.StatusBadRequest, tc.err) },
+				http.MethodGet, "/fail", "/fail", "")
+			wantStatus(t, w, http.StatusBadRequest)
+			body := decodeBody(t, w)
+
+			if body["error"] != tc.err.Error() {
+				t.Errorf("error = %v, want %q", body["error"], tc.err)
+			}
+			if tc.code == "" {
+				for _, field := range []string{"code", "messageKey", "params"} {
+					if _, ok := body[field]; ok {
+						t.Errorf("an uncoded error carries %s", field)
+					}
+				}
+				return
+			}
+			if body["code"] != tc.code || body["messageKey"] != errs.MessageKey(tc.code) {
+				t.Errorf("code = %v, key %v; want %s", body["code"], body["messageKey"], tc.code)
+			}
+			if params, _ := body["params"].(map[string]interface{}); tc.params != nil && !reflect.DeepEqual(params, tc.params) {
+				t.Errorf("params = %v, want %v", params, tc.params)
+			}
+		})
+	}
+}
+
+func TestArchivedRoomIsGoneThroughWraps(t *testing.T) {
+	err := errs.Wrap(game.ErrRoomArchived, "room %s", "ABC123")
+	w := serve(t, func(c *gin.Context) { respondError(c, http.StatusBadRequest, err) },
+		http.MethodGet, "/fail", "/fail", "")
+	wantStatus(t, w, http.StatusGone)
+	if code := decodeBody(t, w)["code"]; code != game.ErrRoomArchived.Code {
+		t.Errorf("code = %v", code)
+	}
+}
+
+func TestEngineRuleViolationReachesClientCoded(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+
+	// Voting by day
+	body := fmt.Sprintf(`{"playerId":%q,"type":"vote","targetId":%q}`, ids[0], ids[1])
+	w := serveAs(t, PerformAction(gm), http.MethodPost, "/rooms/:code/actions", "/rooms/"+code.String()+"/actions", body, sessionOf(t, gm, code, ids[0]))
+	wantStatus(t, w, http.StatusBadRequest)
+	if answer := decodeBody(t, w); answer["code"] != game.ErrNotVotingPhase.Code || answer["messageKey"] != "error.not_voting_phase" {
+		t.Errorf("voting by day answered %v", answer)
+	}
+}
+
+func TestClientCannotCloseAnOpenVote(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	if _, err := gm.AdvancePhase(code, ids[0]); err != nil {
+		t.Fatalf("start the vote: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"playerId":%q,"type":"vote_result"}`, ids[1])
+	w := serveAs(t, PerformAction(gm), http.MethodPost, "/rooms/:code/actions", "/rooms/"+code.String()+"/actions", body, sessionOf(t, gm, code, ids[1]))
+	wantStatus(t, w, http.StatusBadRequest)
+	if answer := decodeBody(t, w); answer["code"] != game.ErrVoteNotOver.Code {
+		t.Errorf("closing the vote early answered %v", answer)
+	}
+	if phase, _ := gm.RoomPhase(code); phase != models.PhaseVoting {
+		t.Errorf("the vote ended early: %s", phase)
+	}
+}
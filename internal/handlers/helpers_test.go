@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve sends one request to handler mounted at route and returns the
+// recorded response
+func serve(t testing.TB, handler gin.HandlerFunc, method, route, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveAs(t, handler, method, route, target, body, "")
+}
+
+// serveAs is serve for a request sent with a player's session token
+func serveAs(t testing.TB, handler gin.HandlerFunc, method, route, target, body, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := gin.New()
+	router.Handle(method, route, handler)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set(sessionHeader, token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeBody decodes a JSON response body, failing the test if it is not JSON
+func decodeBody(t testing.TB, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %d is not JSON: %s", w.Code, w.Body)
+	}
+	return body
+}
+
+// sessionOf returns a player's session token, failing the test if they have none
+func sessionOf(t testing.TB, gm *game.GameManager, code models.RoomCode, playerID string) string {
+	t.Helper()
+
+	token, ok := gm.SessionToken(code, playerID)
+	if !ok || token == "" {
+		t.Fatalf("%s has no session in %s", playerID, code)
+	}
+	return token
+}
+
+// wantStatus fails the test unless the response has the given status
+func wantStatus(t testing.TB, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d: %s", w.Code, status, w.Body)
+	}
+}
+
+// testServer serves the REST API and the websocket over a real listener
+type testServer struct {
+	*httptest.Server
+	gm *game.GameManager
+}
+
+func newTestServer(t testing.TB, gm *game.GameManager) *testServer {
+	t.Helper()
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.POST("/rooms", CreateRoom(gm))
+	api.GET("/rooms/:code", GetRoom(gm))
+	api.GET("/rooms/:code/chat", ChatHistory(gm))
+	api.POST("/rooms/:code/join", JoinRoom(gm))
+	api.GET("/rooms/:code/join-requests/:id", JoinRequestStatus(gm))
+	api.POST("/rooms/:code/actions", PerformAction(gm))
+	api.POST("/rooms/:code/practice/resolve-night", PreviewNight(gm))
+	api.POST("/rooms/:code/invites", CreateInvites(gm))
+	api.GET("/rooms/:code/invites", ListInvites(gm))
+	api.DELETE("/rooms/:code/invites/:token", RevokeInvite(gm))
+	api.POST("/rooms/join-by-invite", JoinByInvite(gm))
+	router.GET(WebSocketPath, HandleWebSocket(gm))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return &testServer{Server: server, gm: gm}
+}
+
+// post sends a JSON body and decodes the JSON answer
+func (s *testServer) post(t testing.TB, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	return s.do(t, http.MethodPost, path, body, "")
+}
+
+// get fetches a path and decodes the JSON answer
+func (s *testServer) get(t testing.TB, path string) (int, map[string]interface{}) {
+	t.Helper()
+	return s.do(t, http.MethodGet, path, "", "")
+}
+
+// do sends a request, with a session token unless it is empty, and decodes
+// the JSON answer
+func (s *testServer) do(t testing.TB, method, path, body, token string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set(sessionHeader, token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, decodeResponse(t, resp)
+}
+
+func decodeResponse(t testing.TB, resp *http.Response) map[string]interface{} {
+	t.Helper()
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("response %d is not JSON: %v", resp.StatusCode, err)
+	}
+	return body
+}
+
+// createRoom creates a private room over REST and returns its code and the
+// host's join response
+func (s *testServer) createRoom(t testing.TB, username string) (models.RoomCode, map[string]interface{}) {
+	t.Helper()
+
+	status, body := s.post(t, "/api/rooms", `{"username":"`+username+`"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("create room: %d %v", status, body)
+	}
+	room := body["room"].(map[string]interface{})
+	return models.RoomCode(room["code"].(string)), body
+}
+
+// join adds a player over REST, sending code as given, and returns their
+// join response
+func (s *testServer) join(t testing.TB, code, username string) map[string]interface{} {
+	t.Helper()
+
+	status, body := s.post(t, "/api/rooms/"+url.PathEscape(code)+"/join", `{"username":"`+username+`"}`)
+	if status != http.StatusOK && status != http.StatusCreated {
+		t.Fatalf("join %s: %d %v", code, status, body)
+	}
+	return body
+}
+
+// wsClient is a test's end of a websocket
+type wsClient struct {
+	t    testing.TB
+	conn *websocket.Conn
+}
+
+// dial opens a websocket with the given query
+func (s *testServer) dial(t testing.TB, query url.Values) *wsClient {
+	t.Helper()
+
+	if query.Get("protocol") == "" {
+		query.Set("protocol", strconv.Itoa(CurrentProtocol))
+	}
+	target := "ws" + strings.TrimPrefix(s.URL, "http") + WebSocketPath + "?" + query.Encode()
+	conn, _, err := websocket.DefaultDialer.Dial(target, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &wsClient{t: t, conn: conn}
+}
+
+// dialAs opens a websocket as the player a join response belongs to
+func (s *testServer) dialAs(t testing.TB, code string, joined map[string]interface{}) *wsClient {
+	t.Helper()
+
+	return s.dial(t, url.Values{
+		"roomCode": {code},
+		"playerId": {joined["playerId"].(string)},
+		"token":    {joined["sessionToken"].(string)},
+	})
+}
+
+// closeCode reads until the server closes the socket and returns the close
+// code, or 0 if it was not closed with one in time
+func (c *wsClient) closeCode() int {
+	c.t.Helper()
+
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := c.conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return closeErr.Code
+		}
+		return 0
+	}
+}
+
+// send writes an event
+func (c *wsClient) send(eventType string, payload interface{}) {
+	c.t.Helper()
+
+	if err := c.conn.WriteJSON(models.WSMessage{Type: eventType, Payload: payload}); err != nil {
+		c.t.Fatalf("send %s: %v", eventType, err)
+	}
+}
+
+// next reads until an event of the given type arrives and returns its payload
+func (c *wsClient) next(eventType string) interface{} {
+	c.t.Helper()
+
+	payload, ok := c.await(eventType, 2*time.Second)
+	if !ok {
+		c.t.Fatalf("no %s arrived", eventType)
+	}
+	return payload
+}
+
+// await reads until an event of the given type arrives or the wait runs out
+func (c *wsClient) await(eventType string, wait time.Duration) (interface{}, bool) {
+	c.t.Helper()
+
+	c.conn.SetReadDeadline(time.Now().Add(wait))
+	defer c.conn.SetReadDeadline(time.Time{})
+	for {
+		var message struct {
+			Type    string      `json:"type"`
+			Payload interface{} `json:"payload"`
+		}
+		if err := c.conn.ReadJSON(&message); err != nil {
+			return nil, false
+		}
+		if message.Type == eventType {
+			return message.Payload, true
+		}
+	}
+}
+
+// startedGame starts a game of n players straight on the manager and
+// returns its code and the players' IDs, the host first. IDs are unique
+// across tests, since the hub is shared. The day may be ended at once.
+func startedGame(t testing.TB, gm *game.GameManager, n int) (models.RoomCode, []string) {
+	t.Helper()
+	return startedGameWith(t, gm, n, nil)
+}
+
+// startedGameWith is startedGame with the settings adjusted first
+func startedGameWith(t testing.TB, gm *game.GameManager, n int, adjust func(*models.RoomSettings)) (models.RoomCode, []string) {
+	t.Helper()
+
+	settings := models.DefaultRoomSettings()
+	settings.MinDiscussionSeconds = 0
+	settings.QuorumFraction = 0
+	if adjust != nil {
+		adjust(&settings)
+	}
+	if n > settings.MaxPlayers {
+		settings.MaxPlayers = n
+	}
+
+	prefix := uuid.New().String()[:8]
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s-p%d", prefix, i+1)
+	}
+
+	room, err := gm.CreateRoom(ids[0], "Player1", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for i, id := range ids[1:] {
+		if _, err := gm.JoinRoom(room.Code, id, fmt.Sprintf("Player%d", i+2)); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+	}
+	if err := gm.StartGame(room.Code, ids[0]); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	return room.Code, ids
+}
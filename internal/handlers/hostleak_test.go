@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// frameLog keeps every frame a websocket receives, in order
+type frameLog struct {
+	mu     sync.Mutex
+	frames []map[string]interface{}
+}
+
+// recordFrames reads the client's websocket in the background until it closes
+func recordFrames(client *wsClient) *frameLog {
+	log := &frameLog{}
+	go func() {
+		for {
+			var frame map[string]interface{}
+			if err := client.conn.ReadJSON(&frame); err != nil {
+				return
+			}
+			log.mu.Lock()
+			log.frames = append(log.frames, frame)
+			log.mu.Unlock()
+		}
+	}()
+	return log
+}
+
+// since returns the frames received after the first n, once the socket has
+// been quiet for a moment
+func (l *frameLog) since(n int) []map[string]interface{} {
+	time.Sleep(150 * time.Millisecond)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]map[string]interface{}(nil), l.frames[n:]...)
+}
+
+// mark waits for the socket to go quiet and returns how many frames it has
+// received
+func (l *frameLog) mark() int {
+	time.Sleep(150 * time.Millisecond)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.frames)
+}
+
+// hostSecrets lists what a host who plays must not learn from a frame or a
+// response: other players' roles, including who has acted at night, and the
+// night's choices of roles they do not hold. Under sleep confirmation the
+// night's progress is secret as well.
+type hostSecrets struct {
+	hostID string
+	roles  map[string]models.Role
+	sleep  bool
+}
+
+func (s hostSecrets) hostRole() models.Role {
+	return s.roles[s.hostID]
+}
+
+func (s hostSecrets) tigerTeam(role models.Role) bool {
+	return role == models.RoleTiger || role == models.RoleAlphaTiger
+}
+
+// knowsRole reports whether the host may know the player's role
+func (s hostSecrets) knowsRole(playerID string) bool {
+	return playerID == s.hostID || (s.tigerTeam(s.hostRole()) && s.tigerTeam(s.roles[playerID]))
+}
+
+// knowsActed reports whether the host may know that the player acted tonight
+func (s hostSecrets) knowsActed(playerID string) bool {
+	return playerID == s.hostID || (!s.sleep && s.knowsRole(playerID))
+}
+
+// leaks walks a decoded frame and describes each secret in it. night is
+// set while the night the host must not follow is going on.
+func (s hostSecrets) leaks(value interface{}, path string, night bool) []string {
+	var found []string
+	switch value := value.(type) {
+	case map[string]interface{}:
+		id, _ := value["id"].(string)
+		if role, _ := value["role"].(string); role != "" && id != "" && !s.knowsRole(id) {
+			found = append(found, fmt.Sprintf("%s: %s's role", path, id))
+		}
+		if acted, _ := value["hasActedThisNight"].(bool); acted && !s.knowsActed(id) {
+			found = append(found, fmt.Sprintf("%s: %s has acted", path, id))
+		}
+		for key, field := range value {
+			set := field != nil && field != "" && field != float64(0)
+			switch key {
+			case "tigerTarget", "cursedPlayer":
+				if set && !s.tigerTeam(s.hostRole()) {
+					found = append(found, path+"."+key)
+				}
+			case "shamanVision", "visionResult":
+				if set && s.hostRole() != models.RoleShaman {
+					found = append(found, path+"."+key)
+				}
+			case "hunterProtection":
+				if set && s.hostRole() != models.RoleHunter {
+					found = append(found, path+"."+key)
+				}
+			case "nightActionsRequired":
+				if set {
+					found = append(found, path+"."+key)
+				}
+			case "nightActionsCompleted":
+				for playerID := range field.(map[string]interface{}) {
+					if !s.knowsActed(playerID) {
+						found = append(found, fmt.Sprintf("%s.%s: %s", path, key, playerID))
+					}
+				}
+			case "currentNightRole":
+				role := s.hostRole()
+				if s.tigerTeam(role) {
+					role = models.RoleTiger
+				}
+				if night && s.sleep && field != string(role) {
+					found = append(found, fmt.Sprintf("%s.%s: %v", path, key, field))
+				}
+			}
+			found = append(found, s.leaks(field, path+"."+key, night)...)
+		}
+	case []interface{}:
+		for i, item := range value {
+			found = append(found, s.leaks(item, fmt.Sprintf("%s[%d]", path, i), night)...)
+		}
+	}
+	return found
+}
+
+func (s hostSecrets) check(t *testing.T, what string, value interface{}, night bool) {
+	t.Helper()
+
+	// Round-trip through JSON so structs and maps are walked alike
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("%s: %v", what, err)
+	}
+	var decoded interface{}
+	json.Unmarshal(data, &decoded)
+	for _, leak := range s.leaks(decoded, what, night) {
+		t.Errorf("the host learns %s", leak)
+	}
+}
+
+// TestHostWhoPlaysLearnsNoSecrets plays a day and a night with the host as
+// one of the players, and checks every frame the host's websocket receives
+// and everything the host can fetch over REST. The admin API and /debug are
+// left out: they take the admin token, which no player has.
+func TestHostWhoPlaysLearnsNoSecrets(t *testing.T) {
+	for _, sleep := range []bool{false, true} {
+		t.Run(fmt.Sprintf("sleep=%v", sleep), func(t *testing.T) {
+			testHostLeaks(t, sleep)
+		})
+	}
+}
+
+func testHostLeaks(t *testing.T, sleep bool) {
+	gm := game.NewGameManager()
+	code, ids := startedGameWith(t, gm, 8, func(settings *models.RoomSettings) {
+		settings.NightSleepConfirmation = sleep
+	})
+	server := newTestServer(t, gm)
+	host := ids[0]
+	secrets := hostSecrets{hostID: host, roles: map[string]models.Role{}, sleep: sleep}
+	for id, assignment := range gm.RoleAssignments(code) {
+		secrets.roles[id] = assignment.Role
+	}
+
+	frames := recordFrames(server.dialPlayer(t, code, host))
+	path := "/api/rooms/" + code.String()
+	act := func(playerID string, body map[string]interface{}) {
+		t.Helper()
+		body["playerId"] = playerID
+		data, _ := json.Marshal(body)
+		if status, answer := server.do(t, http.MethodPost, path+"/actions", string(data), sessionOf(t, gm, code, playerID)); status >= http.StatusBadRequest {
+			t.Fatalf("%s's %v answered %d %v", playerID, body["type"], status, answer)
+		}
+	}
+	// Everything the host can fetch over REST
+	fetch := func(night bool) {
+		t.Helper()
+		token := sessionOf(t, gm, code, host)
+		for _, target := range []string{path, path + "/chat?playerId=" + host} {
+			status, body := server.do(t, http.MethodGet, target, "", token)
+			if status != http.StatusOK {
+				t.Fatalf("GET %s answered %d %v", target, status, body)
+			}
+			secrets.check(t, "GET "+target, body, night)
+		}
+	}
+
+	// The host ends the day and the vote, which nobody cast
+	fetch(false)
+	act(host, map[string]interface{}{"type": models.EventSkipPhase})
+	act(host, map[string]interface{}{"type": models.EventSkipPhase})
+	if phase, _ := gm.RoomPhase(code); phase != models.PhaseNight {
+		t.Fatalf("phase = %s, want the night", phase)
+	}
+	nightStart := frames.mark()
+
+	// Everyone skips their turn; under sleep confirmation the others' turns
+	// must pass unseen
+	acted := map[string]bool{}
+	for {
+		prompts, _ := gm.CurrentTurnPrompts(code)
+		if len(prompts) == 0 {
+			break
+		}
+		before := frames.mark()
+		hostActed := false
+		for playerID, prompt := range prompts {
+			hostActed = hostActed || playerID == host
+			acted[playerID] = true
+			act(playerID, map[string]interface{}{"type": models.EventNightAction, "actionType": models.ActionSkip, "turnToken": prompt.TurnToken})
+		}
+		for _, frame := range frames.since(before) {
+			switch frame["type"] {
+			case models.EventYourTurn, models.EventTigerTeamUpdate:
+				// Addressed to the host as a holder of the next turn, or of this one
+			default:
+				if sleep && !hostActed {
+					t.Errorf("the host was sent %v when another turn ended", frame["type"])
+				}
+			}
+		}
+		fetch(sleep)
+	}
+
+	if sleep {
+		// Falling asleep is not announced either, save by the last sleeper.
+		// Acting counted as falling asleep, so the last is someone who had
+		// no turn.
+		var sleepers []string
+		for _, id := range ids {
+			if !acted[id] {
+				sleepers = append(sleepers, id)
+			}
+		}
+		last := sleepers[len(sleepers)-1]
+		if last == host {
+			t.Fatal("only the host had no turn")
+		}
+		before := frames.mark()
+		for _, id := range sleepers[:len(sleepers)-1] {
+			act(id, map[string]interface{}{"type": models.EventNightSleep})
+		}
+		if sent := frames.since(before); len(sent) != 0 {
+			t.Errorf("the host was sent %d frames as the others fell asleep, the first %v", len(sent), sent[0]["type"])
+		}
+		fetch(true)
+
+		for i, frame := range frames.since(nightStart) {
+			secrets.check(t, fmt.Sprintf("night frame %d (%v)", i, frame["type"]), frame, true)
+		}
+		act(last, map[string]interface{}{"type": models.EventNightSleep})
+	}
+
+	if phase, _ := gm.RoomPhase(code); phase != models.PhaseDay {
+		t.Fatalf("phase = %s after the night, want the day", phase)
+	}
+	for i, frame := range frames.since(0) {
+		secrets.check(t, fmt.Sprintf("frame %d (%v)", i, frame["type"]), frame, false)
+	}
+	fetch(false)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+)
+
+func TestInviteRedeemedOnceOverREST(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	code, host := server.createRoom(t, "Host")
+	hostID := host["playerId"].(string)
+
+	body := fmt.Sprintf(`{"playerId":%q,"count":1}`, hostID)
+	status, minted := server.do(t, http.MethodPost, "/api/rooms/"+code.String()+"/invites", body, host["sessionToken"].(string))
+	invites, _ := minted["invites"].([]interface{})
+	if status != http.StatusCreated || len(invites) != 1 {
+		t.Fatalf("create invite: %d %v", status, minted)
+	}
+	token := invites[0].(map[string]interface{})["token"].(string)
+
+	// Everyone who got the link clicks it at once
+	const racers = 20
+	var wg sync.WaitGroup
+	statuses := make(chan int, racers)
+	for i := 0; i < racers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"token":%q,"username":"Racer%d"}`, token, i)
+			resp, err := http.Post(server.URL+"/api/rooms/join-by-invite", "application/json", strings.NewReader(body))
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}(i)
+	}
+	wg.Wait()
+	close(statuses)
+
+	admitted := 0
+	for status := range statuses {
+		switch status {
+		case http.StatusOK:
+			admitted++
+		case http.StatusBadRequest:
+		default:
+			t.Errorf("a redemption was answered %d", status)
+		}
+	}
+	if admitted != 1 {
+		t.Fatalf("%d players redeemed one invite", admitted)
+	}
+	if view, _ := gm.RoomView(code, ""); len(view.Players) != 2 {
+		t.Errorf("%d players in the room, want the host and one racer", len(view.Players))
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// endedGame plays a game of six until the village votes its only tiger out
+func endedGame(t *testing.T, gm *game.GameManager) models.RoomCode {
+	t.Helper()
+
+	code, ids := startedGame(t, gm, 6)
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("move to the vote: %v", err)
+	}
+	var tiger string
+	for id, assignment := range gm.RoleAssignments(code) {
+		if assignment.Role == models.RoleTiger {
+			tiger = id
+		}
+	}
+	for _, id := range ids {
+		if id != tiger {
+			if err := gm.Vote(code, id, tiger); err != nil {
+				t.Fatalf("%s votes: %v", id, err)
+			}
+		}
+	}
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("resolve the vote: %v", err)
+	}
+	if phase, _ := gm.RoomPhase(code); phase != models.PhaseEnded {
+		t.Fatalf("phase = %s, want the game over", phase)
+	}
+	return code
+}
+
+// fullRoom is a lobby with as many players as its cap
+func fullRoom(t *testing.T, gm *game.GameManager) models.RoomCode {
+	t.Helper()
+
+	settings := models.DefaultRoomSettings()
+	settings.MaxPlayers = 5
+	room, err := gm.CreateRoom("full-host", "Host", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for i := 2; i <= 5; i++ {
+		if _, err := gm.JoinRoom(room.Code, fmt.Sprintf("full-p%d", i), fmt.Sprintf("Player%d", i)); err != nil {
+			t.Fatalf("join %d: %v", i, err)
+		}
+	}
+	return room.Code
+}
+
+// useSpectatorDelay sets how far behind delayed spectators watch for the
+// rest of the test
+func useSpectatorDelay(t *testing.T, delay time.Duration) {
+	previous := SpectatorDelay
+	SpectatorDelay = delay
+	t.Cleanup(func() { SpectatorDelay = previous })
+}
+
+func TestRefusedJoinOffersAlternatives(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		room     func(*testing.T, *game.GameManager) models.RoomCode
+		code     string
+		spectate bool
+		alive    float64
+		phase    models.GamePhase
+		round    float64
+	}{
+		{
+			name: "started",
+			room: func(t *testing.T, gm *game.GameManager) models.RoomCode {
+				code, _ := startedGame(t, gm, 6)
+				return code
+			},
+			code:  "GAME_ALREADY_STARTED",
+			alive: 6,
+			phase: models.PhaseDay,
+			round: 1,
+		},
+		{
+			// A full game under way is reported as started
+			name: "started and full",
+			room: func(t *testing.T, gm *game.GameManager) models.RoomCode {
+				code, _ := startedGameWith(t, gm, 6, func(settings *models.RoomSettings) { settings.MaxPlayers = 6 })
+				return code
+			},
+			code:  "GAME_ALREADY_STARTED",
+			alive: 6,
+			phase: models.PhaseDay,
+			round: 1,
+		},
+		{
+			name:     "ended",
+			room:     endedGame,
+			code:     "GAME_ALREADY_STARTED",
+			spectate: true,
+			alive:    5,
+			phase:    models.PhaseEnded,
+			round:    1,
+		},
+		{
+			name:  "full",
+			room:  fullRoom,
+			code:  "ROOM_FULL",
+			alive: 5,
+			phase: models.PhaseWaiting,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gm := game.NewGameManager()
+			code := tc.room(t, gm)
+
+			// Any room but a finished one can be watched only delayed, and
+			// only while the server offers that
+			for _, delay := range []time.Duration{DefaultSpectatorDelay, 0} {
+				useSpectatorDelay(t, delay)
+				spectate, delaySeconds := tc.spectate, interface{}(nil)
+				if !tc.spectate && delay > 0 {
+					spectate, delaySeconds = true, delay.Seconds()
+				}
+
+				w := serve(t, JoinRoom(gm), http.MethodPost, "/rooms/:code/join", "/rooms/"+code.String()+"/join", `{"username":"late"}`)
+				wantStatus(t, w, http.StatusBadRequest)
+				body := decodeBody(t, w)
+				if body["code"] != tc.code || body["messageKey"] == "" {
+					t.Errorf("code = %v, key %v; want %s", body["code"], body["messageKey"], tc.code)
+				}
+				if body["canSpectate"] != spectate || body["canLateJoin"] != false || body["spectatorDelaySeconds"] != delaySeconds {
+					t.Errorf("delay %v: canSpectate = %v after %v s, canLateJoin = %v; want %v after %v s, false",
+						delay, body["canSpectate"], body["spectatorDelaySeconds"], body["canLateJoin"], spectate, delaySeconds)
+				}
+				if body["playersAlive"] != tc.alive || body["phase"] != string(tc.phase) || body["round"] != tc.round {
+					t.Errorf("the room stands at %v alive, %v, round %v; want %v, %s, %v", body["playersAlive"], body["phase"], body["round"], tc.alive, tc.phase, tc.round)
+				}
+			}
+		})
+	}
+}
+
+func TestRefusedInviteOffersAlternatives(t *testing.T) {
+	gm := game.NewGameManager()
+	code := fullRoom(t, gm)
+	invites, err := gm.CreateInvites(code, "full-host", 1, 0)
+	if err != nil {
+		t.Fatalf("create invite: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"token":%q,"username":"late"}`, invites[0].Token)
+	w := serve(t, JoinByInvite(gm), http.MethodPost, "/rooms/join-by-invite", "/rooms/join-by-invite", body)
+	wantStatus(t, w, http.StatusBadRequest)
+	if answer := decodeBody(t, w); answer["code"] != "ROOM_FULL" || answer["phase"] != string(models.PhaseWaiting) || answer["canSpectate"] != true {
+		t.Errorf("invite into a full room answered %v", answer)
+	}
+}
+
+func TestOtherJoinErrorsOfferNothing(t *testing.T) {
+	gm := game.NewGameManager()
+
+	w := serve(t, JoinRoom(gm), http.MethodPost, "/rooms/:code/join", "/rooms/NOROOM/join", `{"username":"late"}`)
+	body := decodeBody(t, w)
+	for _, field := range []string{"canSpectate", "canLateJoin", "playersAlive", "phase", "round"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("a join into no room carries %s", field)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestConcurrentJoinsFillTheCap(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	settings := models.DefaultRoomSettings()
+	settings.MaxPlayers = 10
+	room, err := gm.CreateRoom("host", "Host", settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+
+	// Twenty players race for the nine seats left beside the host
+	type joinResult struct {
+		status   int
+		playerID string
+		code     string
+	}
+	results := make(chan joinResult, 20)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Post(server.URL+"/api/rooms/"+string(room.Code)+"/join", "application/json",
+				strings.NewReader(fmt.Sprintf(`{"username":"Racer%d"}`, i)))
+			if err != nil {
+				t.Errorf("join %d: %v", i, err)
+				return
+			}
+			defer resp.Body.Close()
+			var body struct {
+				PlayerID string `json:"playerId"`
+				Code     string `json:"code"`
+			}
+			json.NewDecoder(resp.Body).Decode(&body)
+			results <- joinResult{resp.StatusCode, body.PlayerID, body.Code}
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	joined := make(map[string]bool)
+	for result := range results {
+		switch result.status {
+		case http.StatusOK, http.StatusCreated:
+			if result.playerID == "" || joined[result.playerID] {
+				t.Errorf("join admitted player %q twice or without an ID", result.playerID)
+			}
+			joined[result.playerID] = true
+		case http.StatusBadRequest:
+			if result.code != "ROOM_FULL" {
+				t.Errorf("join refused with %q, want ROOM_FULL", result.code)
+			}
+		default:
+			t.Errorf("join answered %d", result.status)
+		}
+	}
+	if len(joined) != 9 {
+		t.Errorf("%d joins succeeded, want the 9 free seats", len(joined))
+	}
+	view, _ := gm.RoomView(room.Code, "")
+	if len(view.Players) != 10 {
+		t.Errorf("room holds %d players, want its cap of 10", len(view.Players))
+	}
+}
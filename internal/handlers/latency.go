@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+var latency = ws.NewLatencyTracker()
+
+// ping is what a client sends to measure its connection. ClientTime is
+// echoed back untouched. RTTMs is the round trip the client measured from
+// its previous pong; DeliveryMs, optional, is how long the last broadcast
+// it received took to arrive.
+type ping struct {
+	ClientTime json.RawMessage `json:"clientTime"`
+	RTTMs      *float64        `json:"rttMs"`
+	DeliveryMs *float64        `json:"deliveryMs"`
+}
+
+// pong answers a ping
+type pong struct {
+	ClientTime json.RawMessage  `json:"clientTime,omitempty"`
+	ServerTime models.Timestamp `json:"serverTime"`
+}
+
+// handlePing answers a ping at once and records what the client measured.
+// It runs outside the room lock, so a busy room doesn't inflate the round
+// trip. Pings sent faster than ws.MinPingInterval get no pong.
+func handlePing(client *ws.Client, msg *models.WSMessage) {
+	now := time.Now()
+	if !hub.AllowPing(client, now) {
+		return
+	}
+
+	var data ping
+	payloadBytes, _ := json.Marshal(msg.Payload)
+	json.Unmarshal(payloadBytes, &data)
+
+	sendToClient(client, models.EventPong, pong{ClientTime: data.ClientTime, ServerTime: models.NewTimestamp(now)})
+
+	if data.RTTMs != nil {
+		var delivery time.Duration
+		if data.DeliveryMs != nil {
+			delivery = milliseconds(*data.DeliveryMs)
+		}
+		latency.Record(client.RoomCode, milliseconds(*data.RTTMs), delivery, data.DeliveryMs != nil, now)
+	}
+}
+
+// RoomLatencies reports the ping round trips and delivery delays clients
+// measured in each room over the last few minutes
+func RoomLatencies() map[models.RoomCode]ws.RoomLatency {
+	return latency.Rooms(time.Now())
+}
+
+// PingLatency reports the latencies measured across every room, without
+// naming the rooms, for the public metrics
+func PingLatency() *ws.RoomLatency {
+	if measured, ok := latency.Overall(time.Now()); ok {
+		return &measured
+	}
+	return nil
+}
+
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestPingIsAnsweredAndRecorded(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	server := newTestServer(t, gm)
+	client := server.dialPlayer(t, code, ids[1])
+
+	client.send(models.EventPing, map[string]interface{}{"clientTime": 1234.5, "rttMs": 42, "deliveryMs": 7})
+	answer, _ := client.next(models.EventPong).(map[string]interface{})
+	if answer["clientTime"] != 1234.5 || answer["serverTime"] == nil {
+		t.Errorf("pong = %v, want the client time echoed with the server's", answer)
+	}
+
+	// The report is recorded just after the pong goes out
+	measured, ok := RoomLatencies()[code]
+	for deadline := time.Now().Add(2 * time.Second); !ok && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		measured, ok = RoomLatencies()[code]
+	}
+	if !ok {
+		t.Fatal("the room's latency was not recorded")
+	}
+	if measured.RoundTrip.P50Ms != 42 || measured.Delivery == nil || measured.Delivery.P50Ms != 7 {
+		t.Errorf("latency = %+v / %+v, want 42ms and 7ms", measured.RoundTrip, measured.Delivery)
+	}
+	if overall := PingLatency(); overall == nil || overall.RoundTrip.Samples == 0 {
+		t.Errorf("the overall latency = %+v", overall)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// TestClosedRoomsLeaveNoGoroutines opens 100 rooms in every state, with
+// players, delayed spectators and held-back lobby updates, closes them all
+// and checks the process is back to the goroutines it started with
+func TestClosedRoomsLeaveNoGoroutines(t *testing.T) {
+	feed := NewLobbyFeed()
+	gm := game.NewGameManager(feed, NewRoomCloser())
+	ScopeRoomSenders(gm)
+	feed.SetSpawner(gm.GoRoom)
+	t.Cleanup(func() {
+		outbound.SetSpawner(nil)
+		hub.SetSpawner(nil)
+	})
+	server := newTestServer(t, gm)
+	baseline := runtime.NumGoroutine()
+
+	var clients []*wsClient
+	connect := func(code models.RoomCode, playerID string) {
+		client := server.dial(t, url.Values{"roomCode": {code.String()}, "playerId": {playerID}, "token": {sessionOf(t, gm, code, playerID)}})
+		client.next(models.EventGameStateUpdate)
+		clients = append(clients, client)
+	}
+	for i := 0; i < 100; i++ {
+		switch i % 4 {
+		case 0:
+			// A public lobby whose second join is held back from the lobby feed
+			settings := models.DefaultRoomSettings()
+			settings.Public = true
+			hostID := fmt.Sprintf("leak-host-%d", i)
+			room, err := gm.CreateRoom(hostID, "Host", settings)
+			if err != nil {
+				t.Fatalf("create room: %v", err)
+			}
+			for _, id := range []string{"a", "b"} {
+				if _, err := gm.JoinRoom(room.Code, fmt.Sprintf("leak-%s-%d", id, i), "Guest"); err != nil {
+					t.Fatalf("join: %v", err)
+				}
+			}
+			connect(room.Code, hostID)
+		case 1:
+			code, ids := startedGame(t, gm, 6)
+			connect(code, ids[0])
+		case 2:
+			code, ids := startedGame(t, gm, 6)
+			connect(code, ids[0])
+			clients = append(clients, server.dial(t, url.Values{"roomCode": {code.String()}, "mode": {"delayed"}}))
+		case 3:
+			code := endedGame(t, gm)
+			view, _ := gm.RoomView(code, "")
+			connect(code, view.HostID)
+		}
+	}
+	if gm.RoomGoroutines() == 0 {
+		t.Fatal("no room goroutines ran")
+	}
+
+	stop := gm.StartJanitor(time.Hour, time.Minute)
+	stop()
+	if removed := gm.SweepIdleRooms(time.Now().Add(time.Hour)); removed != 100 {
+		t.Fatalf("swept %d rooms, want 100", removed)
+	}
+
+	// The rooms' own goroutines stop with their rooms, before anyone hangs up
+	waitFor(t, 500*time.Millisecond, func() bool { return gm.RoomGoroutines() == 0 })
+
+	for _, client := range clients {
+		client.conn.Close()
+	}
+	waitFor(t, 2*time.Second, func() bool { return runtime.NumGoroutine() <= baseline })
+	if overdue := gm.OverdueRoomShutdowns(); overdue != 0 {
+		t.Errorf("%d rooms were slow to shut down", overdue)
+	}
+}
+
+// waitFor polls until done holds, failing the test with every goroutine's
+// stack if it does not within the bound
+func waitFor(t *testing.T, bound time.Duration, done func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(bound); !done(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("still running after %v:\n%s", bound, buf[:runtime.Stack(buf, true)])
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// LobbyPath is where HandleLobby is served
+const LobbyPath = "/ws/lobby"
+
+// lobbyUpdateInterval is the shortest gap between two room_updated for a room
+const lobbyUpdateInterval = time.Second
+
+var lobby = ws.NewLobby()
+
+// LobbyFeed turns room lifecycle notifications into the lobby's deltas:
+// room_created when a room is listed, room_updated when its listing changes
+// and room_removed when it goes away or stops being public. Updates for a
+// room are held back to one per lobbyUpdateInterval; the latest one wins.
+//
+// It is a game.RoomObserver, so it runs with the manager lock held and only
+// ever queues frames.
+type LobbyFeed struct {
+	game.NopObserver
+
+	mu      sync.Mutex
+	listed  map[models.RoomCode]bool
+	sentAt  map[models.RoomCode]time.Time
+	pending map[models.RoomCode]*game.RoomListing
+	spawn   ws.RoomSpawner
+}
+
+// NewLobbyFeed returns a feed for the lobby served at LobbyPath; pass it to
+// game.NewGameManager
+func NewLobbyFeed() *LobbyFeed {
+	return &LobbyFeed{
+		listed:  make(map[models.RoomCode]bool),
+		sentAt:  make(map[models.RoomCode]time.Time),
+		pending: make(map[models.RoomCode]*game.RoomListing),
+	}
+}
+
+func (f *LobbyFeed) OnRoomCreated(room *models.GameRoom) {
+	f.update(room)
+}
+
+func (f *LobbyFeed) OnPlayerJoined(room *models.GameRoom, _ *models.Player) {
+	f.update(room)
+}
+
+func (f *LobbyFeed) OnPlayerLeft(room *models.GameRoom, _ *models.Player) {
+	f.update(room)
+}
+
+func (f *LobbyFeed) OnGameStarted(room *models.GameRoom) {
+	f.update(room)
+}
+
+func (f *LobbyFeed) OnPhaseChanged(room *models.GameRoom, _ models.GamePhase) {
+	f.update(room)
+}
+
+func (f *LobbyFeed) OnGameEnded(room *models.GameRoom, _ string) {
+	f.update(room)
+}
+
+// OnHostAction catches settings changes, which may make a room public or
+// private, and kicks
+func (f *LobbyFeed) OnHostAction(room *models.GameRoom, _ models.HostAction) {
+	f.update(room)
+}
+
+func (f *LobbyFeed) OnRoomDeleted(room *models.GameRoom) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removeLocked(room.Code)
+}
+
+// update sends or schedules the room's new listing
+func (f *LobbyFeed) update(room *models.GameRoom) {
+	listing, public := game.ListRoom(room)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !public {
+		f.removeLocked(room.Code)
+		return
+	}
+
+	now := time.Now()
+	if !f.listed[room.Code] {
+		f.listed[room.Code] = true
+		f.sentAt[room.Code] = now
+		broadcastLobby(models.EventRoomCreated, listing)
+		return
+	}
+
+	if _, waiting := f.pending[room.Code]; waiting {
+		f.pending[room.Code] = &listing
+		return
+	}
+
+	due := f.sentAt[room.Code].Add(lobbyUpdateInterval)
+	if !now.Before(due) {
+		f.sentAt[room.Code] = now
+		broadcastLobby(models.EventRoomUpdated, listing)
+		return
+	}
+
+	f.pending[room.Code] = &listing
+	code := room.Code
+	wait := func(ctx context.Context) {
+		timer := time.NewTimer(due.Sub(now))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			f.flush(code)
+		case <-ctx.Done():
+		}
+	}
+	if f.spawn == nil || !f.spawn(code, wait) {
+		go wait(context.Background())
+	}
+}
+
+// SetSpawner makes each held-back update wait as one of its room's
+// goroutines, so it is dropped when the room goes away
+func (f *LobbyFeed) SetSpawner(spawn ws.RoomSpawner) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.spawn = spawn
+}
+
+// flush sends the room's held-back listing, if it is still wanted
+func (f *LobbyFeed) flush(code models.RoomCode) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	listing := f.pending[code]
+	delete(f.pending, code)
+	if listing == nil || !f.listed[code] {
+		return
+	}
+
+	f.sentAt[code] = time.Now()
+	broadcastLobby(models.EventRoomUpdated, *listing)
+}
+
+// removeLocked unlists the room, telling the lobby if it was listed
+func (f *LobbyFeed) removeLocked(code models.RoomCode) {
+	delete(f.pending, code)
+	delete(f.sentAt, code)
+	if !f.listed[code] {
+		return
+	}
+	delete(f.listed, code)
+	broadcastLobby(models.EventRoomRemoved, gin.H{"code": code})
+}
+
+// broadcastLobby queues an event for every lobby subscriber
+func broadcastLobby(eventType string, payload interface{}) {
+	data, err := encodeMessage(eventType, payload)
+	if err != nil {
+		log.Printf("JSON marshal error: %v", err)
+		return
+	}
+	lobby.Broadcast(data)
+}
+
+// HandleLobby serves the lobby browser's subscription. Connections are
+// anonymous and limited per address. Each gets the full room_list, with
+// any server announcement in force, then the deltas; a delta right after the list may repeat what it shows, so
+// clients apply room_created and room_updated as upserts.
+func HandleLobby(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		addr := c.ClientIP()
+		if err := lobby.Admit(addr, time.Now()); err != nil {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			log.Printf("WebSocket upgrade error: %v", err)
+			lobby.Release(addr)
+			return
+		}
+
+		// Subscribe before taking the listing, so no change falls between them
+		sub := lobby.Subscribe(conn, addr)
+		listing, err := encodeMessage(models.EventRoomList, gin.H{"rooms": gm.PublicRooms(), "announcement": activeAnnouncement()})
+		if err != nil {
+			log.Printf("JSON marshal error: %v", err)
+			lobby.Unsubscribe(sub)
+			conn.Close()
+			return
+		}
+
+		lobby.Serve(sub, listing)
+	}
+}
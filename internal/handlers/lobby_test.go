@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// lobbyServer serves the lobby for a manager fed by a LobbyFeed
+func lobbyServer(t *testing.T) (*game.GameManager, *httptest.Server) {
+	t.Helper()
+
+	gm := game.NewGameManager(NewLobbyFeed())
+	router := gin.New()
+	router.GET(LobbyPath, HandleLobby(gm))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return gm, server
+}
+
+// lobbyAddrs hands out client addresses; the lobby's limits are shared
+// across tests, so each connection is counted against an address of its own
+var lobbyAddrs uint32
+
+// newLobbyAddr returns an address no other test has connected from
+func newLobbyAddr() string {
+	n := atomic.AddUint32(&lobbyAddrs, 1)
+	return fmt.Sprintf("10.%d.%d.%d", n>>16&0xff, n>>8&0xff, n&0xff)
+}
+
+// dialLobby subscribes to the lobby as the given address
+func dialLobby(t *testing.T, server *httptest.Server, addr string) (*wsClient, *http.Response, error) {
+	t.Helper()
+
+	header := http.Header{"X-Forwarded-For": {addr}}
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+LobbyPath, header)
+	if err != nil {
+		return nil, resp, err
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &wsClient{t: t, conn: conn}, resp, nil
+}
+
+// listedRoom creates a room, public or not, hosted by host
+func listedRoom(t *testing.T, gm *game.GameManager, host string, adjust func(*models.RoomSettings)) models.RoomCode {
+	t.Helper()
+
+	settings := models.DefaultRoomSettings()
+	adjust(&settings)
+	room, err := gm.CreateRoom(host, host, settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	return room.Code
+}
+
+func TestLobbyListsOnlyPublicRooms(t *testing.T) {
+	gm, server := lobbyServer(t)
+	public := listedRoom(t, gm, "alice", func(settings *models.RoomSettings) { settings.Public = true })
+	listedRoom(t, gm, "bob", func(*models.RoomSettings) {})
+	listedRoom(t, gm, "carol", func(settings *models.RoomSettings) {
+		settings.Public = true
+		settings.PracticeMode = true
+	})
+
+	client, _, err := dialLobby(t, server, newLobbyAddr())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	list, _ := client.next(models.EventRoomList).(map[string]interface{})
+	rooms, _ := list["rooms"].([]interface{})
+	if len(rooms) != 1 {
+		t.Fatalf("rooms = %v, want only the public one", rooms)
+	}
+
+	// A listing carries the whitelisted fields and nothing else
+	listing := rooms[0].(map[string]interface{})
+	if listing["code"] != public.String() || listing["hostName"] != "alice" {
+		t.Errorf("listing = %v, want alice's room %s", listing, public)
+	}
+	allowed := map[string]bool{"code": true, "hostName": true, "players": true, "maxPlayers": true, "phase": true, "joinApprovalRequired": true, "createdAt": true}
+	for field := range listing {
+		if !allowed[field] {
+			t.Errorf("the listing carries %s", field)
+		}
+	}
+}
+
+func TestLobbyDeltas(t *testing.T) {
+	gm, server := lobbyServer(t)
+	client, _, err := dialLobby(t, server, newLobbyAddr())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	client.next(models.EventRoomList)
+
+	code := listedRoom(t, gm, "alice", func(settings *models.RoomSettings) { settings.Public = true })
+	created, _ := client.next(models.EventRoomCreated).(map[string]interface{})
+	if created["code"] != code.String() || created["players"] != float64(1) {
+		t.Fatalf("room_created = %v", created)
+	}
+
+	// Two joins inside the interval arrive as one update with the latest count
+	for _, id := range []string{"bob", "carol"} {
+		if _, err := gm.JoinRoom(code, id, id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+	}
+	payload, ok := client.await(models.EventRoomUpdated, 3*lobbyUpdateInterval)
+	updated, _ := payload.(map[string]interface{})
+	if !ok || updated["players"] != float64(3) {
+		t.Fatalf("room_updated = %v, want one update with 3 players", payload)
+	}
+
+	// The room goes once its last player does; the held-back updates for
+	// the leaves go with it
+	for _, id := range []string{"bob", "carol", "alice"} {
+		if err := gm.RemovePlayer(code, id); err != nil {
+			t.Fatalf("remove %s: %v", id, err)
+		}
+	}
+	client.conn.SetReadDeadline(time.Now().Add(3 * lobbyUpdateInterval))
+	for {
+		var frame struct {
+			Type    string                 `json:"type"`
+			Payload map[string]interface{} `json:"payload"`
+		}
+		if err := client.conn.ReadJSON(&frame); err != nil {
+			t.Fatalf("no room_removed arrived: %v", err)
+		}
+		if frame.Type == models.EventRoomUpdated {
+			t.Errorf("another update arrived: %v", frame.Payload)
+		}
+		if frame.Type == models.EventRoomRemoved {
+			if frame.Payload["code"] != code.String() {
+				t.Errorf("room_removed = %v", frame.Payload)
+			}
+			break
+		}
+	}
+}
+
+func TestLobbyLimitsConnectionsPerAddress(t *testing.T) {
+	_, server := lobbyServer(t)
+	addr := newLobbyAddr()
+
+	clients := []*wsClient{}
+	for i := 0; i < 4; i++ {
+		client, _, err := dialLobby(t, server, addr)
+		if err != nil {
+			t.Fatalf("connection %d: %v", i, err)
+		}
+		clients = append(clients, client)
+	}
+	if _, resp, err := dialLobby(t, server, addr); err == nil || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("a fifth connection was not refused with 429: %v", err)
+	}
+	// Another address is not held back
+	if _, _, err := dialLobby(t, server, newLobbyAddr()); err != nil {
+		t.Errorf("another address was refused: %v", err)
+	}
+
+	// A closed connection frees its place
+	clients[0].conn.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, _, err := dialLobby(t, server, addr)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the freed place was not given back: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
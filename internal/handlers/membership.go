@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/errs"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+const (
+	// DefaultPublicRoomsPerAddr is how many public rooms one address may
+	// hold players in at once
+	DefaultPublicRoomsPerAddr = 3
+	// membershipTTL is how long a membership is counted without the tracker
+	// hearing that it ended. Rooms are reclaimed well before this; it only
+	// clears entries whose leave was missed.
+	membershipTTL = 6 * time.Hour
+	// maxTrackedMemberships bounds the tracker; past it the oldest
+	// membership is forgotten
+	maxTrackedMemberships = 10000
+)
+
+// PublicRoomsPerAddr caps the public rooms one address may hold players
+// in; 0 turns the cap off. Addresses come from gin's ClientIP, so behind a
+// proxy they are only as good as the trusted proxy configuration.
+var PublicRoomsPerAddr = DefaultPublicRoomsPerAddr
+
+var ErrTooManyRooms = &errs.Error{Code: "TOO_MANY_ROOMS_PER_ADDRESS", Message: "you are already playing in too many public rooms"}
+
+var memberships = newMembershipTracker()
+
+// membership is one player joined to a public room from an address
+type membership struct {
+	addrHash string
+	room     models.RoomCode // empty while the room is being created
+	since    time.Time
+	until    time.Time // when it stops being counted unless renewed
+}
+
+// membershipTracker counts the public rooms each address holds players in:
+// rooms created, joined directly or by invite, and asked to join. It is best
+// effort, since addresses are.
+type membershipTracker struct {
+	mu      sync.Mutex
+	members map[string]membership      // by player ID
+	byAddr  map[string]map[string]bool // player IDs by address hash
+}
+
+func newMembershipTracker() *membershipTracker {
+	return &membershipTracker{
+		members: make(map[string]membership),
+		byAddr:  make(map[string]map[string]bool),
+	}
+}
+
+// admit counts playerID's membership of room for addr, or returns
+// ErrTooManyRooms if addr already holds limit other rooms. An empty room is
+// one about to be created, named later with settle. A membership whose join
+// then fails must be given back with forget.
+func (t *membershipTracker) admit(addr string, room models.RoomCode, playerID string, limit int, now time.Time) error {
+	hash := ws.HashAddr(addr)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.evictLocked(now)
+
+	// Every room still being created is a room of its own
+	held := 0
+	rooms := make(map[models.RoomCode]bool)
+	for id := range t.byAddr[hash] {
+		code := t.members[id].room
+		if code == "" || !rooms[code] {
+			held++
+		}
+		if code != "" {
+			rooms[code] = true
+		}
+	}
+	if (room == "" || !rooms[room]) && held >= limit {
+		return ErrTooManyRooms.WithParams(map[string]interface{}{"limit": limit})
+	}
+
+	if len(t.members) >= maxTrackedMemberships {
+		t.forgetLocked(t.oldestLocked())
+	}
+	t.members[playerID] = membership{addrHash: hash, room: room, since: now, until: now.Add(membershipTTL)}
+	if t.byAddr[hash] == nil {
+		t.byAddr[hash] = make(map[string]bool)
+	}
+	t.byAddr[hash][playerID] = true
+	return nil
+}
+
+// settle names the room a membership admitted for a new room belongs to
+func (t *membershipTracker) settle(playerID string, room models.RoomCode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m, ok := t.members[playerID]; ok {
+		m.room = room
+		t.members[playerID] = m
+	}
+}
+
+// hold counts playerID's membership only until the given time, as for a
+// join request waiting on the host
+func (t *membershipTracker) hold(playerID string, until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m, ok := t.members[playerID]; ok {
+		m.until = until
+		t.members[playerID] = m
+	}
+}
+
+// keep counts playerID's membership for a full membershipTTL from now
+func (t *membershipTracker) keep(playerID string, now time.Time) {
+	t.hold(playerID, now.Add(membershipTTL))
+}
+
+// forget ends playerID's membership, if it is counted
+func (t *membershipTracker) forget(playerID string) {
+	t.mu.Lock()
+	t.forgetLocked(playerID)
+	t.mu.Unlock()
+}
+
+// forgetRoom ends every membership of room
+func (t *membershipTracker) forgetRoom(room models.RoomCode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for playerID, m := range t.members {
+		if m.room == room {
+			t.forgetLocked(playerID)
+		}
+	}
+}
+
+func (t *membershipTracker) forgetLocked(playerID string) {
+	m, ok := t.members[playerID]
+	if !ok {
+		return
+	}
+	delete(t.members, playerID)
+	delete(t.byAddr[m.addrHash], playerID)
+	if len(t.byAddr[m.addrHash]) == 0 {
+		delete(t.byAddr, m.addrHash)
+	}
+}
+
+// evictLocked forgets memberships no longer counted: those older than
+// membershipTTL and join requests the host never answered
+func (t *membershipTracker) evictLocked(now time.Time) {
+	for playerID, m := range t.members {
+		if !now.Before(m.until) {
+			t.forgetLocked(playerID)
+		}
+	}
+}
+
+func (t *membershipTracker) oldestLocked() string {
+	var oldest string
+	var since time.Time
+	for playerID, m := range t.members {
+		if oldest == "" || m.since.Before(since) {
+			oldest, since = playerID, m.since
+		}
+	}
+	return oldest
+}
+
+// admitMembership counts a join to a public room against the caller's
+// address. It answers 429 and returns false when the address is at
+// PublicRoomsPerAddr; private and practice rooms and a disabled cap always
+// pass.
+func admitMembership(c *gin.Context, gm *game.GameManager, code models.RoomCode, playerID string) bool {
+	room, ok := gm.RoomView(code, "")
+	if !ok {
+		return true
+	}
+	return admitTo(c, room.Settings, code, playerID)
+}
+
+// admitNewRoom is admitMembership for a room about to be created with the
+// given settings; once it is, its code goes to memberships.settle
+func admitNewRoom(c *gin.Context, settings models.RoomSettings, playerID string) bool {
+	return admitTo(c, settings, "", playerID)
+}
+
+func admitTo(c *gin.Context, settings models.RoomSettings, code models.RoomCode, playerID string) bool {
+	// Practice rooms are never reported to the releaser, and hold nobody else up
+	if PublicRoomsPerAddr <= 0 || !settings.Public || settings.PracticeMode {
+		return true
+	}
+	if err := memberships.admit(c.ClientIP(), code, playerID, PublicRoomsPerAddr, time.Now()); err != nil {
+		respondError(c, http.StatusTooManyRequests, err)
+		return false
+	}
+	return true
+}
+
+// MembershipReleaser stops counting memberships as players leave or are
+// kicked and as rooms are reclaimed, and counts a join request's membership
+// in full once the host lets the player in.
+//
+// It is a game.RoomObserver, so it runs with the manager lock held; it only
+// takes the tracker's own lock.
+type MembershipReleaser struct {
+	game.NopObserver
+}
+
+// NewMembershipReleaser returns the releaser for the memberships JoinRoom
+// counts; pass it to game.NewGameManager
+func NewMembershipReleaser() *MembershipReleaser {
+	return &MembershipReleaser{}
+}
+
+func (*MembershipReleaser) OnPlayerJoined(_ *models.GameRoom, player *models.Player) {
+	memberships.keep(player.ID, time.Now())
+}
+
+func (*MembershipReleaser) OnPlayerLeft(_ *models.GameRoom, player *models.Player) {
+	memberships.forget(player.ID)
+}
+
+func (*MembershipReleaser) OnRoomDeleted(room *models.GameRoom) {
+	memberships.forgetRoom(room.Code)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// membershipServer mounts every route that adds a player, with a fresh
+// tracker capped at two public rooms per address
+func membershipServer(t *testing.T) (*game.GameManager, *gin.Engine) {
+	t.Helper()
+
+	tracker, limit := memberships, PublicRoomsPerAddr
+	memberships, PublicRoomsPerAddr = newMembershipTracker(), 2
+	t.Cleanup(func() { memberships, PublicRoomsPerAddr = tracker, limit })
+
+	gm := game.NewGameManager(NewMembershipReleaser())
+	router := gin.New()
+	router.POST("/rooms", CreateRoom(gm))
+	router.POST("/rooms/:code/join", JoinRoom(gm))
+	router.POST("/rooms/join-by-invite", JoinByInvite(gm))
+	return gm, router
+}
+
+// fromAddr posts body to path as a client at addr
+func fromAddr(router *gin.Engine, path, body, addr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = addr + ":40000"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// hostedRoom is a lobby someone elsewhere created
+func hostedRoom(t *testing.T, gm *game.GameManager, hostID string, adjust func(*models.RoomSettings)) models.RoomCode {
+	t.Helper()
+
+	settings := models.DefaultRoomSettings()
+	settings.Public = true
+	if adjust != nil {
+		adjust(&settings)
+	}
+	room, err := gm.CreateRoom(hostID, hostID, settings)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	return room.Code
+}
+
+func joinFrom(router *gin.Engine, code models.RoomCode, addr string) *httptest.ResponseRecorder {
+	return fromAddr(router, "/rooms/"+code.String()+"/join", `{"username":"Griefer"}`, addr)
+}
+
+// wantCapped fails the test unless the response is the cap refusal
+func wantCapped(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	wantStatus(t, w, http.StatusTooManyRequests)
+	body := decodeBody(t, w)
+	params, _ := body["params"].(map[string]interface{})
+	if body["code"] != ErrTooManyRooms.Code || params["limit"] != float64(2) {
+		t.Errorf("refusal = %v, want %s with the limit", body, ErrTooManyRooms.Code)
+	}
+}
+
+func TestPublicRoomCapPerAddress(t *testing.T) {
+	gm, router := membershipServer(t)
+	first, second, third := hostedRoom(t, gm, "h1", nil), hostedRoom(t, gm, "h2", nil), hostedRoom(t, gm, "h3", nil)
+
+	wantStatus(t, joinFrom(router, first, "198.51.100.1"), http.StatusOK)
+	wantStatus(t, joinFrom(router, second, "198.51.100.1"), http.StatusOK)
+	wantCapped(t, joinFrom(router, third, "198.51.100.1"))
+
+	// Another player from the same address in a room it already holds is
+	// no further room; another address has its own count
+	wantStatus(t, joinFrom(router, second, "198.51.100.1"), http.StatusOK)
+	wantStatus(t, joinFrom(router, third, "198.51.100.2"), http.StatusOK)
+
+	// Creating a public room counts like joining one
+	wantCapped(t, fromAddr(router, "/rooms", `{"username":"Griefer","public":true}`, "198.51.100.1"))
+	wantStatus(t, fromAddr(router, "/rooms", `{"username":"Griefer","public":true}`, "198.51.100.2"), http.StatusCreated)
+	wantCapped(t, joinFrom(router, first, "198.51.100.2"))
+}
+
+func TestPrivateRoomsAreExempt(t *testing.T) {
+	gm, router := membershipServer(t)
+	for _, host := range []string{"h1", "h2"} {
+		wantStatus(t, joinFrom(router, hostedRoom(t, gm, host, nil), "198.51.100.1"), http.StatusOK)
+	}
+
+	private := hostedRoom(t, gm, "h3", func(settings *models.RoomSettings) { settings.Public = false })
+	wantStatus(t, joinFrom(router, private, "198.51.100.1"), http.StatusOK)
+	wantStatus(t, fromAddr(router, "/rooms", `{"username":"Griefer"}`, "198.51.100.1"), http.StatusCreated)
+	wantStatus(t, fromAddr(router, "/rooms", `{"username":"Griefer","public":true,"practiceMode":true}`, "198.51.100.1"), http.StatusCreated)
+}
+
+func TestInviteJoinsCount(t *testing.T) {
+	gm, router := membershipServer(t)
+	invited := hostedRoom(t, gm, "h1", nil)
+	invites, err := gm.CreateInvites(invited, "h1", 2, time.Hour)
+	if err != nil {
+		t.Fatalf("create invites: %v", err)
+	}
+
+	wantStatus(t, joinFrom(router, hostedRoom(t, gm, "h2", nil), "198.51.100.1"), http.StatusOK)
+	wantStatus(t, fromAddr(router, "/rooms/join-by-invite", `{"token":"`+invites[0].Token+`","username":"Griefer"}`, "198.51.100.1"), http.StatusOK)
+	wantCapped(t, joinFrom(router, hostedRoom(t, gm, "h3", nil), "198.51.100.1"))
+
+	// The cap turns away an invite to a third public room, and keeps the invite
+	full := hostedRoom(t, gm, "h4", nil)
+	wantStatus(t, joinFrom(router, full, "198.51.100.2"), http.StatusOK)
+	wantStatus(t, joinFrom(router, hostedRoom(t, gm, "h5", nil), "198.51.100.2"), http.StatusOK)
+	wantCapped(t, fromAddr(router, "/rooms/join-by-invite", `{"token":"`+invites[1].Token+`","username":"Griefer"}`, "198.51.100.2"))
+	if view, _ := gm.RoomView(invited, ""); len(view.Players) != 2 {
+		t.Errorf("the invited room has %d players, want the host and the first guest", len(view.Players))
+	}
+}
+
+func TestJoinRequestsCount(t *testing.T) {
+	gm, router := membershipServer(t)
+	gated := func(settings *models.RoomSettings) { settings.JoinApprovalRequired = true }
+	approved, denied := hostedRoom(t, gm, "h1", gated), hostedRoom(t, gm, "h2", gated)
+
+	knock := func(code models.RoomCode) models.JoinRequest {
+		t.Helper()
+		w := joinFrom(router, code, "198.51.100.1")
+		wantStatus(t, w, http.StatusAccepted)
+		request, _ := decodeBody(t, w)["request"].(map[string]interface{})
+		status, err := gm.JoinRequestStatus(code, request["id"].(string))
+		if err != nil {
+			t.Fatalf("request status: %v", err)
+		}
+		return status
+	}
+	first, second := knock(approved), knock(denied)
+
+	// Waiting at two doors is two rooms
+	wantCapped(t, joinFrom(router, hostedRoom(t, gm, "h3", nil), "198.51.100.1"))
+
+	if _, err := gm.RespondJoinRequest(approved, "h1", first.ID, true); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	// An answered request no longer counts once it would have expired; the
+	// approved player counts on as a member
+	later := second.ExpiresAt.Add(time.Second)
+	if err := memberships.admit("198.51.100.1", "ELSEWHERE", "p-later", 2, later); err != nil {
+		t.Errorf("the expired request still counts: %v", err)
+	}
+	if err := memberships.admit("198.51.100.1", "ELSEWHERE2", "p-later2", 2, later); err == nil {
+		t.Error("the approved player stopped counting with their request")
+	}
+}
+
+func TestMembershipsEndWithTheRoom(t *testing.T) {
+	gm, router := membershipServer(t)
+	left, kicked, swept, next := hostedRoom(t, gm, "h1", nil), hostedRoom(t, gm, "h2", nil), hostedRoom(t, gm, "h3", nil), hostedRoom(t, gm, "h4", nil)
+
+	playerIn := func(code models.RoomCode) string {
+		t.Helper()
+		w := joinFrom(router, code, "198.51.100.1")
+		wantStatus(t, w, http.StatusOK)
+		return decodeBody(t, w)["playerId"].(string)
+	}
+
+	// Leaving
+	leaver := playerIn(left)
+	playerIn(kicked)
+	wantCapped(t, joinFrom(router, swept, "198.51.100.1"))
+	if err := gm.RemovePlayer(left, leaver); err != nil {
+		t.Fatalf("leave: %v", err)
+	}
+
+	// Being kicked
+	kickedID := playerIn(swept)
+	wantCapped(t, joinFrom(router, next, "198.51.100.1"))
+	if err := gm.KickPlayer(swept, "h3", kickedID); err != nil {
+		t.Fatalf("kick: %v", err)
+	}
+
+	// The room being reclaimed
+	playerIn(next)
+	wantCapped(t, joinFrom(router, swept, "198.51.100.1"))
+	stop := gm.StartJanitor(time.Hour, time.Minute)
+	stop()
+	gm.SweepIdleRooms(time.Now().Add(time.Hour))
+	wantStatus(t, fromAddr(router, "/rooms", `{"username":"Griefer","public":true}`, "198.51.100.1"), http.StatusCreated)
+}
+
+func TestMembershipsExpire(t *testing.T) {
+	tracker := newMembershipTracker()
+	start := time.Now()
+
+	for i, room := range []models.RoomCode{"ROOM1", "ROOM2"} {
+		if err := tracker.admit("198.51.100.1", room, "p"+room.String(), 2, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("admit %s: %v", room, err)
+		}
+	}
+	if err := tracker.admit("198.51.100.1", "ROOM3", "p3", 2, start.Add(membershipTTL-time.Second)); err == nil {
+		t.Error("admitted a third room before any membership expired")
+	}
+	if err := tracker.admit("198.51.100.1", "ROOM3", "p3", 2, start.Add(membershipTTL)); err != nil {
+		t.Errorf("the oldest membership did not expire: %v", err)
+	}
+	if _, counted := tracker.members["pROOM1"]; counted || len(tracker.members) != 2 {
+		t.Errorf("tracked %v, want the expired membership gone", tracker.members)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// announceRevealedDeaths tells the room who was found dead since the last
+// announcement. It reports whether there was anyone to announce.
+func announceRevealedDeaths(gm *game.GameManager, roomCode models.RoomCode) bool {
+	deaths := gm.TakeRevealedDeaths(roomCode)
+	if len(deaths) == 0 {
+		return false
+	}
+	broadcastToRoom(roomCode, models.EventPlayerDied, gin.H{"deaths": deaths})
+	return true
+}
+
+// scheduleBodyReveal finds the room's concealed bodies when their timer
+// runs out, unless the host or the end of the day found them first. The
+// wait is one of the room's goroutines, so it ends with the room.
+func scheduleBodyReveal(gm *game.GameManager, roomCode models.RoomCode) {
+	room, exists := gm.RoomView(roomCode, "")
+	if !exists || room.BodyRevealAt == nil {
+		return
+	}
+	revealAt := *room.BodyRevealAt
+
+	gm.GoRoom(roomCode, func(ctx context.Context) {
+		timer := time.NewTimer(time.Until(revealAt.Time))
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		unlock := lockRoom(roomCode)
+		defer unlock()
+
+		if !gm.RevealDueBodies(roomCode, revealAt, time.Now()) {
+			return
+		}
+		announceRevealedDeaths(gm, roomCode)
+		broadcastPlayersUpdate(gm, roomCode)
+	})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestConcealedBodyAnnouncedWhenFound(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGameWith(t, gm, 6, func(settings *models.RoomSettings) {
+		settings.MysteryDawn = true
+		settings.MysteryDawnRevealSeconds = 1
+	})
+	server := newTestServer(t, gm)
+
+	var villagers []string
+	for _, id := range ids {
+		if view, _ := gm.RoomView(code, id); view.Players[id].Role == models.RoleVillager {
+			villagers = append(villagers, id)
+		}
+	}
+	if len(villagers) < 2 {
+		t.Fatalf("dealt %d villagers, want a victim and a witness", len(villagers))
+	}
+	victim, witness := villagers[0], villagers[1]
+
+	for i := 0; i < 2; i++ {
+		if _, err := gm.MoveToNextPhase(code); err != nil {
+			t.Fatalf("move to the night: %v", err)
+		}
+	}
+	for step := 0; step < 10; step++ {
+		prompts, _ := gm.CurrentTurnPrompts(code)
+		for holder, prompt := range prompts {
+			var err error
+			if prompt.Role == models.RoleTiger || prompt.Role == models.RoleAlphaTiger {
+				err = gm.PerformNightAction(code, holder, victim, prompt.TurnToken)
+			} else {
+				err = gm.SkipNightAction(code, holder, prompt.TurnToken)
+			}
+			if err != nil {
+				t.Fatalf("%s's turn: %v", prompt.Role, err)
+			}
+		}
+		if done, _ := gm.MoveToNextNightRole(code); done {
+			break
+		}
+	}
+
+	client := server.dial(t, url.Values{"roomCode": {code.String()}, "playerId": {witness}, "token": {sessionOf(t, gm, code, witness)}})
+	client.next(models.EventGameStateUpdate)
+
+	unlock := lockRoom(code)
+	result, err := gm.MoveToNextPhase(code)
+	if err != nil {
+		unlock()
+		t.Fatalf("move to the day: %v", err)
+	}
+	broadcastPhaseChange(gm, code, result, "")
+	unlock()
+
+	update, _ := client.next(models.EventPhaseUpdate).(map[string]interface{})
+	nightResult, _ := update["nightResult"].(map[string]interface{})
+	deaths, _ := nightResult["deaths"].([]interface{})
+	if nightResult["concealed"] != true || len(deaths) != 1 || deaths[0].(map[string]interface{})["playerId"] != "" {
+		t.Fatalf("dawn told the village %v, want one unnamed death", nightResult)
+	}
+
+	// Nobody is found until the timer runs out, then the room hears who it was
+	view, _ := gm.RoomView(code, "")
+	if view.BodyRevealAt == nil {
+		t.Fatal("no reveal time was set")
+	}
+	revealAt := view.BodyRevealAt.Time
+	died, _ := client.next(models.EventPlayerDied).(map[string]interface{})
+	if time.Now().Before(revealAt) {
+		t.Errorf("the body was announced %v early", time.Until(revealAt))
+	}
+	found, _ := died["deaths"].([]interface{})
+	if len(found) != 1 || found[0].(map[string]interface{})["playerId"] != victim {
+		t.Errorf("player_died = %v, want %s", died, victim)
+	}
+	if view, _ := gm.RoomView(code, witness); view.Players[victim].IsAlive {
+		t.Error("the witness still sees the victim alive")
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// roomLocks serializes, per room, each change to the game and the broadcasts
+// describing it. The hub delivers in the order broadcasts are queued, so
+// holding a room's lock from the mutation until its last broadcast is queued
+// means clients see a room's events in the order they happened. Different
+// rooms never wait on each other.
+var roomLocks = struct {
+	sync.Mutex
+	rooms map[models.RoomCode]*roomLock
+}{rooms: make(map[models.RoomCode]*roomLock)}
+
+type roomLock struct {
+	sync.Mutex
+	holders int // goroutines holding or waiting; the entry goes away at zero
+}
+
+// lockRoom takes the room's send lock and returns the function releasing it
+func lockRoom(roomCode models.RoomCode) (unlock func()) {
+	roomLocks.Lock()
+	lock := roomLocks.rooms[roomCode]
+	if lock == nil {
+		lock = &roomLock{}
+		roomLocks.rooms[roomCode] = lock
+	}
+	lock.holders++
+	roomLocks.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		roomLocks.Lock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(roomLocks.rooms, roomCode)
+		}
+		roomLocks.Unlock()
+	}
+}
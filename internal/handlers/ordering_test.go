@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// chatIDs reads chat frames until want have arrived, or the connection
+// fails or goes quiet, and returns their IDs in arrival order
+func (c *wsClient) chatIDs(want int) ([]string, []models.Message) {
+	var ids []string
+	var messages []models.Message
+	c.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+	for len(ids) < want {
+		var frame struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := c.conn.ReadJSON(&frame); err != nil {
+			return ids, messages
+		}
+		if frame.Type != models.EventChatMessage {
+			continue
+		}
+		var message models.Message
+		if json.Unmarshal(frame.Payload, &message) == nil && message.Type == models.MessageChat {
+			ids = append(ids, message.ID)
+			messages = append(messages, message)
+		}
+	}
+	return ids, messages
+}
+
+func TestEachRoomHearsItsChangesInOrder(t *testing.T) {
+	const clientsPerRoom, messagesEach = 3, 30
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+
+	type room struct {
+		code    models.RoomCode
+		clients []*wsClient
+	}
+	rooms := make([]*room, 2)
+	for r := range rooms {
+		code, hostJoined := server.createRoom(t, fmt.Sprintf("Host%d", r))
+		rm := &room{code: code, clients: []*wsClient{server.dialAs(t, code.String(), hostJoined)}}
+		for i := 1; i < clientsPerRoom; i++ {
+			joined := server.join(t, code.String(), fmt.Sprintf("Guest%d", i))
+			rm.clients = append(rm.clients, server.dialAs(t, code.String(), joined))
+		}
+		rooms[r] = rm
+	}
+	// Every client is subscribed once it has had its first state
+	for _, rm := range rooms {
+		for _, client := range rm.clients {
+			client.next(models.EventGameStateUpdate)
+		}
+	}
+
+	// Every client of both rooms floods its room at once, while reading
+	total := clientsPerRoom * messagesEach
+	received := make(map[*wsClient][]string)
+	payloads := make(map[*wsClient][]models.Message)
+	var mu sync.Mutex
+	var senders, readers sync.WaitGroup
+	for r, rm := range rooms {
+		for i, client := range rm.clients {
+			readers.Add(1)
+			go func(client *wsClient) {
+				defer readers.Done()
+				ids, messages := client.chatIDs(total)
+				mu.Lock()
+				received[client], payloads[client] = ids, messages
+				mu.Unlock()
+			}(client)
+
+			senders.Add(1)
+			go func(r, i int, client *wsClient) {
+				defer senders.Done()
+				for n := 0; n < messagesEach; n++ {
+					frame := models.WSMessage{Type: models.EventChatMessage, Payload: map[string]string{"content": fmt.Sprintf("room%d client%d #%d", r, i, n)}}
+					if err := client.conn.WriteJSON(frame); err != nil {
+						t.Errorf("room %d client %d send %d: %v", r, i, n, err)
+						return
+					}
+				}
+			}(r, i, client)
+		}
+	}
+	senders.Wait()
+	readers.Wait()
+
+	for r, rm := range rooms {
+		var history []string
+		for _, message := range gm.ChatHistory(rm.code, "") {
+			if message.Type == models.MessageChat {
+				history = append(history, message.ID)
+			}
+		}
+		if len(history) != total {
+			t.Fatalf("room %d stored %d messages, want %d", r, len(history), total)
+		}
+
+		for i, client := range rm.clients {
+			if !reflect.DeepEqual(received[client], history) {
+				t.Errorf("room %d client %d heard %d messages out of the room's order", r, i, len(received[client]))
+			}
+			prefix := fmt.Sprintf("room%d ", r)
+			for _, message := range payloads[client] {
+				if !strings.HasPrefix(message.Content, prefix) {
+					t.Errorf("room %d client %d heard %q from another room", r, i, message.Content)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestPassIsAcknowledgedOverREST(t *testing.T) {
+	gm := game.NewGameManager()
+	code, _ := startedGame(t, gm, 6)
+	for i := 0; i < 2; i++ {
+		if _, err := gm.MoveToNextPhase(code); err != nil {
+			t.Fatalf("move to the night: %v", err)
+		}
+	}
+	server := newTestServer(t, gm)
+	path := "/api/rooms/" + code.String() + "/actions"
+
+	passed := 0
+	for step := 0; step < 10; step++ {
+		prompts, _ := gm.CurrentTurnPrompts(code)
+		for holder, prompt := range prompts {
+			view, _ := gm.RoomView(code, holder)
+			role := view.Players[holder].Role
+			token := sessionOf(t, gm, code, holder)
+			body := fmt.Sprintf(`{"playerId":%q,"type":"night_action","actionType":"pass","turnToken":%q}`, holder, prompt.TurnToken)
+			status, answer := server.do(t, http.MethodPost, path, body, token)
+
+			if role == models.RoleTiger || role == models.RoleAlphaTiger {
+				// The room's default makes the tigers kill; they may still skip
+				if status != http.StatusBadRequest || answer["code"] != game.ErrKillRequired.Code {
+					t.Errorf("%s passed: %d %v, want %s", role, status, answer, game.ErrKillRequired.Code)
+				}
+				body = fmt.Sprintf(`{"playerId":%q,"type":"night_action","actionType":"skip","turnToken":%q}`, holder, prompt.TurnToken)
+				server.do(t, http.MethodPost, path, body, token)
+				continue
+			}
+
+			ack, _ := answer["ack"].(map[string]interface{})
+			if status != http.StatusOK || ack["actionType"] != models.ActionPass {
+				t.Errorf("%s passed: %d %v, want a private ack of the pass", role, status, answer)
+			}
+			passed++
+		}
+		if done, _ := gm.MoveToNextNightRole(code); done {
+			break
+		}
+	}
+	if passed == 0 {
+		t.Fatal("no village role had a turn to pass")
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestReconnectResendsPendingPrompt(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	server := newTestServer(t, gm)
+	session := url.Values{
+		"roomCode": {code.String()},
+		"playerId": {ids[1]},
+		"token":    {sessionOf(t, gm, code, ids[1])},
+	}
+
+	first := server.dial(t, session)
+	first.next(models.EventGameStateUpdate)
+	if _, _, err := gm.RequestAbort(code, ids[0]); err != nil {
+		t.Fatalf("request abort: %v", err)
+	}
+	first.conn.Close()
+
+	// The prompt follows the snapshot on the new connection
+	again := server.dial(t, session)
+	again.next(models.EventGameStateUpdate)
+	prompt := again.next(models.EventAbortRequested).(map[string]interface{})
+	if remaining, ok := prompt["remainingSeconds"].(float64); !ok || remaining <= 0 {
+		t.Errorf("the resent prompt leaves %v seconds", prompt["remainingSeconds"])
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// Protocol versions a client may declare with ?protocol=N or a hello message
+const (
+	// ProtocolV1 is the original protocol: every update ships the whole room.
+	// Clients that declare nothing are assumed to speak it.
+	ProtocolV1 = 1
+	// ProtocolV2 sends scoped players, phase and votes updates
+	ProtocolV2 = 2
+
+	CurrentProtocol = ProtocolV2
+)
+
+// closeUnsupportedProtocol is the close code sent to clients declaring a
+// protocol version the server cannot speak
+const (
+	closeUnsupportedProtocol     = 4001
+	closeUnsupportedProtocolText = "unsupported protocol version"
+)
+
+// parseProtocol reads a declared protocol version; an empty declaration means v1
+func parseProtocol(declared string) (int, bool) {
+	if declared == "" {
+		return ProtocolV1, true
+	}
+
+	version, err := strconv.Atoi(declared)
+	if err != nil || version < ProtocolV1 || version > CurrentProtocol {
+		return 0, false
+	}
+	return version, true
+}
+
+// rejectProtocol closes a connection that declared an unknown version when
+// it was opened, before its pumps run. Once they do, the hub closes it.
+func rejectProtocol(conn ws.Conn) {
+	conn.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(closeUnsupportedProtocol, closeUnsupportedProtocolText))
+	conn.Close()
+}
+
+// legacyFrame translates an event for one viewer into its v1 shape. It
+// reports false for events v1 clients receive unchanged.
+func legacyFrame(eventType string, view *models.GameRoom, payload interface{}) (string, interface{}, bool) {
+	switch eventType {
+	case models.EventPlayersUpdate:
+		return models.EventGameStateUpdate, models.NewRoomWire(view), true
+
+	case models.EventVotesUpdate:
+		return models.EventVoteUpdate, models.NewRoomWire(view), true
+
+	case models.EventPhaseUpdate:
+		change, ok := payload.(phaseChange)
+		if !ok {
+			return models.EventGameStateUpdate, models.NewRoomWire(view), true
+		}
+
+		legacy := map[string]interface{}{
+			"room": models.NewRoomWire(view),
+		}
+		if change.Message != "" {
+			legacy["message"] = change.Message
+		}
+		if change.NightResult != nil {
+			legacy["nightResult"] = change.NightResult
+		}
+		if change.VoteResult != nil {
+			legacy["voteResult"] = change.VoteResult
+		}
+		return models.EventPhaseChanged, legacy, true
+
+	case models.EventSettingsChanged:
+		return models.EventSettingsChanged, models.NewRoomWire(view), true
+	}
+
+	return "", nil, false
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestNightRecapIsPrivate(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	server := newTestServer(t, gm)
+	hunter, villager := ids[1], ids[2]
+
+	hunterClient := server.dialPlayer(t, code, hunter)
+	hunterClient.next(models.EventGameStateUpdate)
+	villagerClient := server.dialPlayer(t, code, villager)
+	villagerClient.next(models.EventGameStateUpdate)
+
+	broadcastPhaseChange(gm, code, &game.NightResult{Recaps: map[string]game.Recap{
+		hunter: {Role: models.RoleHunter, Outcome: game.RecapProtectedAttacked, Message: "saved"},
+	}}, "")
+
+	recap, _ := hunterClient.next(models.EventNightRecap).(map[string]interface{})
+	if recap["outcome"] != game.RecapProtectedAttacked {
+		t.Errorf("hunter's recap = %v", recap)
+	}
+
+	villagerClient.send(models.EventChatMessage, map[string]string{"content": "morning"})
+	if seen := villagerClient.eventsBefore("morning", map[string]bool{models.EventNightRecap: true}); len(seen) != 0 {
+		t.Errorf("the villager was sent %v", seen)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// dialPlayer opens a websocket as a player of a game started on the manager
+func (s *testServer) dialPlayer(t testing.TB, code models.RoomCode, playerID string) *wsClient {
+	t.Helper()
+
+	return s.dialAs(t, code.String(), map[string]interface{}{
+		"playerId":     playerID,
+		"sessionToken": sessionOf(t, s.gm, code, playerID),
+	})
+}
+
+func TestVoteReplayedOverRESTIsNotReapplied(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("move to the vote: %v", err)
+	}
+	server := newTestServer(t, gm)
+	voter := ids[1]
+
+	client := server.dialPlayer(t, code, voter)
+	client.send(models.EventVote, map[string]interface{}{"targetId": ids[2], "requestId": "vote-1"})
+	client.next(models.EventVotesUpdate)
+
+	// The retry names someone else; it is acked with the vote that counted
+	body := fmt.Sprintf(`{"playerId":%q,"type":"vote","targetId":%q,"requestId":"vote-1"}`, voter, ids[3])
+	status, answer := server.do(t, http.MethodPost, "/api/rooms/"+code.String()+"/actions", body, sessionOf(t, gm, code, voter))
+	ack, _ := answer["ack"].(map[string]interface{})
+	if status != http.StatusOK || ack["targetId"] != ids[2] {
+		t.Fatalf("replay answered %d %v, want an ack of the vote for %s", status, answer, ids[2])
+	}
+
+	view, _ := gm.RoomView(code, voter)
+	if votedFor := view.Players[voter].VotedFor; votedFor != ids[2] {
+		t.Errorf("the replay moved the vote to %q", votedFor)
+	}
+	if counted := view.VoteResults[ids[2]]; counted != 1 {
+		t.Errorf("%s has %d votes after the replay, want 1", ids[2], counted)
+	}
+
+	// A new requestId is a new request
+	body = fmt.Sprintf(`{"playerId":%q,"type":"vote","targetId":%q,"requestId":"vote-2"}`, voter, ids[3])
+	if status, answer := server.do(t, http.MethodPost, "/api/rooms/"+code.String()+"/actions", body, sessionOf(t, gm, code, voter)); status != http.StatusAccepted {
+		t.Errorf("a changed vote answered %d %v", status, answer)
+	}
+	if view, _ := gm.RoomView(code, voter); view.Players[voter].VotedFor != ids[3] {
+		t.Errorf("the changed vote was not applied")
+	}
+}
+
+func TestNightActionReplayedOverWebsocketIsAcked(t *testing.T) {
+	gm := game.NewGameManager()
+	code, _ := startedGame(t, gm, 6)
+	for i := 0; i < 2; i++ {
+		if _, err := gm.MoveToNextPhase(code); err != nil {
+			t.Fatalf("move to the night: %v", err)
+		}
+	}
+	server := newTestServer(t, gm)
+
+	prompts, _ := gm.CurrentTurnPrompts(code)
+	var holder string
+	var prompt game.TurnPrompt
+	for holder, prompt = range prompts {
+		break
+	}
+	body := fmt.Sprintf(`{"playerId":%q,"type":"night_action","actionType":"skip","turnToken":%q,"requestId":"night-1"}`, holder, prompt.TurnToken)
+	if status, answer := server.do(t, http.MethodPost, "/api/rooms/"+code.String()+"/actions", body, sessionOf(t, gm, code, holder)); status >= http.StatusBadRequest {
+		t.Fatalf("skip answered %d %v", status, answer)
+	}
+
+	// Sent again over the websocket, the skip would be refused as a second
+	// action; as a replay it is acked
+	client := server.dialPlayer(t, code, holder)
+	client.send(models.EventSkipAction, map[string]interface{}{"turnToken": prompt.TurnToken, "requestId": "night-1"})
+	ack, _ := client.next(models.EventActionAck).(map[string]interface{})
+	if ack["actionType"] != models.ActionSkip {
+		t.Errorf("ack = %v, want the skip", ack)
+	}
+	if payload, failed := client.await(models.EventError, 200*time.Millisecond); failed {
+		t.Errorf("the replay was reapplied: %v", payload)
+	}
+}
+
+func TestRejectedRequestMayBeRetried(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("move to the vote: %v", err)
+	}
+	server := newTestServer(t, gm)
+	path := "/api/rooms/" + code.String() + "/actions"
+	token := sessionOf(t, gm, code, ids[1])
+
+	body := fmt.Sprintf(`{"playerId":%q,"type":"vote","targetId":"nobody","requestId":"vote-1"}`, ids[1])
+	if status, _ := server.do(t, http.MethodPost, path, body, token); status != http.StatusBadRequest {
+		t.Fatalf("a vote for nobody answered %d", status)
+	}
+	body = fmt.Sprintf(`{"playerId":%q,"type":"vote","targetId":%q,"requestId":"vote-1"}`, ids[1], ids[2])
+	if status, answer := server.do(t, http.MethodPost, path, body, token); status != http.StatusAccepted {
+		t.Fatalf("the retry answered %d %v, want it applied", status, answer)
+	}
+	if view, _ := gm.RoomView(code, ids[1]); view.Players[ids[1]].VotedFor != ids[2] {
+		t.Error("the retried vote was not applied")
+	}
+}
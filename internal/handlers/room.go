@@ -1,76 +1,457 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/werewolf-game/backend/internal/errs"
 	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
 )
 
 type CreateRoomRequest struct {
-	Username string `json:"username" binding:"required"`
+	Username     string `json:"username" binding:"required,max=32"`
+	PracticeMode bool   `json:"practiceMode"`
+	Public       bool   `json:"public"`
 }
 
 type JoinRoomRequest struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required,max=32"`
+}
+
+type CreateInvitesRequest struct {
+	PlayerID         string `json:"playerId" binding:"required"`
+	Count            int    `json:"count" binding:"required,max=20"`
+	ExpiresInSeconds int    `json:"expiresInSeconds"`
+}
+
+type JoinByInviteRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"required,max=32"`
+}
+
+type PreviewNightRequest struct {
+	PlayerID string            `json:"playerId" binding:"required"`
+	Actions  game.NightActions `json:"actions"`
 }
 
 // CreateRoom creates a new game room
 func CreateRoom(gm *game.GameManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateRoomRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindJSON(c, &req) {
 			return
 		}
 
 		playerID := uuid.New().String()
-		room := gm.CreateRoom(playerID, req.Username)
-
-		c.JSON(http.StatusCreated, gin.H{
-			"room":     room,
-			"playerId": playerID,
-		})
+		settings := models.DefaultRoomSettings()
+		settings.PracticeMode = req.PracticeMode
+		settings.Public = req.Public
+		if !admitNewRoom(c, settings, playerID) {
+			return
+		}
+		room, err := gm.CreateRoom(playerID, req.Username, settings)
+		if err != nil {
+			memberships.forget(playerID)
+		} else {
+			memberships.settle(playerID, room.Code)
+		}
+		if errors.Is(err, game.ErrServerAtCapacity) {
+			capacity := gm.Capacity()
+			c.Header("Retry-After", strconv.Itoa(capacity.RetryAfterSeconds))
+			respondError(c, http.StatusServiceUnavailable, game.ErrServerAtCapacity.WithParams(map[string]interface{}{
+				"roomsUsed":         capacity.RoomsUsed,
+				"roomCap":           capacity.RoomCap,
+				"retryAfterSeconds": capacity.RetryAfterSeconds,
+			}))
+			return
+		}
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		c.JSON(http.StatusCreated, joinResponse(gm, room.Code, playerID))
 	}
 }
 
 // GetRoom retrieves room information
 func GetRoom(gm *game.GameManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
-		
-		room, exists := gm.GetRoom(code)
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		view, exists := gm.RoomView(code, "")
 		if !exists {
-			c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+			respondError(c, http.StatusNotFound, gm.MissingRoomError(code))
+			return
+		}
+
+		// Ended games also carry the final board for anyone with the link
+		if summary, err := gm.GameSummary(code); err == nil {
+			c.JSON(http.StatusOK, gin.H{"room": models.NewRoomPublic(view), "summary": summary})
+			return
+		}
+
+		body := gin.H{"room": models.NewRoomPublic(view)}
+		// A lobby shows a coming shutdown before anyone starts a game
+		if announcement := activeAnnouncement(); announcement != nil && view.Phase == models.PhaseWaiting {
+			body["announcement"] = announcement
+		}
+		c.JSON(http.StatusOK, body)
+	}
+}
+
+// ChatHistory returns the room's chat after ?since=<seq>, including
+// messages spilled to the chat store. Anyone gets the public chat; a
+// ?playerId with that player's session token also gets the channels they
+// may read, such as the dead chat.
+func ChatHistory(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		playerID := c.Query("playerId")
+		if playerID != "" && !authenticate(c, gm, code, playerID) {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"room": room})
+		var since int64
+		if v := c.Query("since"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				respondError(c, http.StatusBadRequest, &fieldError{Code: CodeInvalidField, Field: "since", message: "invalid since"})
+				return
+			}
+			since = n
+		}
+
+		messages, err := gm.ChatSince(code, playerID, since)
+		if errors.Is(err, game.ErrRoomNotFound) || errors.Is(err, game.ErrRoomArchived) {
+			// respondError makes an archived game's 404 a 410
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"messages": messages})
+	}
+}
+
+// ListRooms lists the public rooms for the lobby browser. Clients that keep
+// the browser open should subscribe at LobbyPath instead of polling.
+func ListRooms(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"rooms": gm.PublicRooms()})
+	}
+}
+
+// ListRoles returns the rules card for every role a room can deal
+func ListRoles() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"roles": game.RoleCatalog()})
+	}
+}
+
+// ListCues returns every cue events may carry, so clients can preload the
+// sounds they play for them
+func ListCues() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"cues": models.CueCatalog})
 	}
 }
 
 // JoinRoom adds a player to a room
 func JoinRoom(gm *game.GameManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
-		
+		code := gm.ResolveRoomCode(c.Param("code"))
+
 		var req JoinRoomRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindJSON(c, &req) {
 			return
 		}
 
 		playerID := uuid.New().String()
+		unlock := lockRoom(code)
+		if !admitMembership(c, gm, code, playerID) {
+			unlock()
+			return
+		}
 		room, err := gm.JoinRoom(code, playerID, req.Username)
+		if err != nil && !errors.Is(err, game.ErrJoinApprovalRequired) {
+			// A refused join holds no seat
+			memberships.forget(playerID)
+		}
+		if errors.Is(err, game.ErrJoinApprovalRequired) {
+			request, err := gm.RequestJoin(code, playerID, req.Username)
+			if err != nil {
+				memberships.forget(playerID)
+				unlock()
+				respondJoinError(c, gm, code, err)
+				return
+			}
+			// Waiting at the door counts until the host answers
+			memberships.hold(playerID, request.ExpiresAt.Time)
+			sendToPlayers(code, []string{request.HostID}, models.EventJoinRequest, request)
+			unlock()
+
+			// The token is what the guest polls the request with, and their
+			// session once the host lets them in
+			c.JSON(http.StatusAccepted, gin.H{"request": request, "sessionToken": request.SessionToken})
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			unlock()
+			respondJoinError(c, gm, code, err)
+			return
+		}
+		broadcastPlayersUpdate(gm, room.Code)
+		warnOutgrownComposition(gm, room.Code)
+		refreshAutoStart(gm, room.Code)
+		unlock()
+
+		c.JSON(http.StatusOK, joinResponse(gm, room.Code, playerID))
+	}
+}
+
+// respondJoinError answers a refused join. A player turned away from a
+// started game or a full room also learns where the room stands and whether
+// they can watch it instead.
+func respondJoinError(c *gin.Context, gm *game.GameManager, code models.RoomCode, err error) {
+	if !errors.Is(err, game.ErrGameAlreadyStarted) && !errors.Is(err, game.ErrRoomFull) {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	gameErr, _ := errs.Coded(err)
+
+	alternatives, exists := gm.JoinAlternatives(code, SpectatorDelay)
+	if !exists {
+		respondError(c, http.StatusNotFound, gm.MissingRoomError(code))
+		return
+	}
+
+	body := gin.H{
+		"error":        err.Error(),
+		"code":         gameErr.Code,
+		"messageKey":   gameErr.MessageKey(),
+		"canSpectate":  alternatives.CanSpectate,
+		"canLateJoin":  alternatives.CanLateJoin,
+		"playersAlive": alternatives.PlayersAlive,
+		"phase":        alternatives.Phase,
+		"round":        alternatives.Round,
+	}
+	// Watching anything but a finished game means connecting with mode=delayed
+	if alternatives.SpectatorDelaySeconds > 0 {
+		body["spectatorDelaySeconds"] = alternatives.SpectatorDelaySeconds
+	}
+	c.JSON(http.StatusBadRequest, body)
+}
+
+// JoinRequestStatus tells a player waiting at the door whether the host let
+// them in. The guest polls with the session token they were given when they
+// knocked; once approved it carries the same session as a direct join.
+func JoinRequestStatus(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		request, err := gm.JoinRequestSession(code, c.Param("id"), c.GetHeader(sessionHeader))
+		if errors.Is(err, game.ErrInvalidSession) {
+			respondError(c, http.StatusUnauthorized, err)
+			return
+		}
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		if request.Status != models.JoinApproved {
+			c.JSON(http.StatusOK, gin.H{"request": request})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"room":     room,
-			"playerId": playerID,
+		response := joinResponse(gm, code, request.PlayerID)
+		response["request"] = request
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// joinResponse is everything a player needs to render the room and connect
+// right after creating or joining it, without a separate GET: their view of
+// the room, the chat they may read, where and how to open the websocket and
+// any server announcement in force. The session token is the player's
+// secret: the websocket URL carries it, and REST requests acting as the
+// player send it in the X-Session-Token header.
+func joinResponse(gm *game.GameManager, code models.RoomCode, playerID string) gin.H {
+	var room *models.RoomForViewer
+	if view, exists := gm.RoomView(code, playerID); exists {
+		wire := models.NewRoomForViewer(view)
+		room = &wire
+	}
+	token, _ := gm.SessionToken(code, playerID)
+
+	query := url.Values{}
+	query.Set("roomCode", code.String())
+	query.Set("playerId", playerID)
+	query.Set("token", token)
+	query.Set("protocol", strconv.Itoa(CurrentProtocol))
+
+	response := gin.H{
+		"room":         room,
+		"playerId":     playerID,
+		"sessionToken": token,
+		"protocol":     CurrentProtocol,
+		"websocket":    WebSocketPath + "?" + query.Encode(),
+		"chat":         gm.ChatHistory(code, playerID),
+	}
+	if announcement := activeAnnouncement(); announcement != nil {
+		response["announcement"] = announcement
+	}
+	return response
+}
+
+// PreviewNight resolves hypothetical night actions in a practice room without changing it
+func PreviewNight(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		var req PreviewNightRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		if !authenticate(c, gm, code, req.PlayerID) {
+			return
+		}
+
+		result, err := gm.PreviewNight(code, req.PlayerID, req.Actions)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"nightResult": result})
+	}
+}
+
+// CreateInvites mints single-use invite links for a room (host only)
+func CreateInvites(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		var req CreateInvitesRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		if !authenticate(c, gm, code, req.PlayerID) {
+			return
+		}
+
+		ttl := time.Duration(req.ExpiresInSeconds) * time.Second
+		invites, err := gm.CreateInvites(code, req.PlayerID, req.Count, ttl)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"invites": inviteLinks(c, invites)})
+	}
+}
+
+// ListInvites returns the room's outstanding invite links (host only)
+func ListInvites(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		if !authenticate(c, gm, code, c.Query("playerId")) {
+			return
+		}
+
+		invites, err := gm.ListInvites(code, c.Query("playerId"))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"invites": inviteLinks(c, invites)})
+	}
+}
+
+// RevokeInvite cancels an outstanding invite (host only)
+func RevokeInvite(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := gm.ResolveRoomCode(c.Param("code"))
+
+		if !authenticate(c, gm, code, c.Query("playerId")) {
+			return
+		}
+
+		if err := gm.RevokeInvite(code, c.Query("playerId"), c.Param("token")); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"revoked": c.Param("token")})
+	}
+}
+
+// JoinByInvite consumes an invite token and joins its room
+func JoinByInvite(gm *game.GameManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req JoinByInviteRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		playerID := uuid.New().String()
+		if code, ok := gm.InviteRoom(req.Token); ok && !admitMembership(c, gm, code, playerID) {
+			return
+		}
+		room, err := gm.RedeemInvite(req.Token, playerID, req.Username)
+		if err != nil {
+			memberships.forget(playerID)
+			if room != nil {
+				respondJoinError(c, gm, room.Code, err)
+				return
+			}
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		// The room is only known after redeeming; the update is a fresh snapshot either way
+		unlock := lockRoom(room.Code)
+		broadcastPlayersUpdate(gm, room.Code)
+		warnOutgrownComposition(gm, room.Code)
+		refreshAutoStart(gm, room.Code)
+		unlock()
+
+		c.JSON(http.StatusOK, joinResponse(gm, room.Code, playerID))
+	}
+}
+
+// inviteLinks pairs each invite with the join URL the host can share
+func inviteLinks(c *gin.Context, invites []models.Invite) []gin.H {
+	// Get the public site URL from environment variable, default to the caller's origin
+	base := os.Getenv("INVITE_BASE_URL")
+	if base == "" {
+		base = c.GetHeader("Origin")
+	}
+	if base == "" {
+		base = "http://" + c.Request.Host
+	}
+	base = strings.TrimRight(base, "/")
+
+	links := make([]gin.H, 0, len(invites))
+	for _, invite := range invites {
+		links = append(links, gin.H{
+			"token":     invite.Token,
+			"url":       base + "/join?invite=" + url.QueryEscape(invite.Token),
+			"expiresAt": invite.ExpiresAt,
 		})
 	}
+	return links
 }
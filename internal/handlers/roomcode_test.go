@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// mixCase returns code with each letter's case picked at random
+func mixCase(rng *rand.Rand, code string) string {
+	var b strings.Builder
+	for _, r := range code {
+		if rng.Intn(2) == 0 {
+			b.WriteString(strings.ToLower(string(r)))
+		} else {
+			b.WriteString(strings.ToUpper(string(r)))
+		}
+	}
+	return b.String()
+}
+
+func TestLowercaseSocketReceivesBroadcasts(t *testing.T) {
+	server := newTestServer(t, game.NewGameManager())
+	code, host := server.createRoom(t, "Ann")
+
+	client := server.dialAs(t, strings.ToLower(code.String()), host)
+	client.next(models.EventGameStateUpdate)
+
+	// A join sent with the code in upper case reaches the lowercase socket
+	joined := server.join(t, code.String(), "Ben")
+	for {
+		update := client.next(models.EventPlayersUpdate).(map[string]interface{})
+		if _, ok := update["players"].(map[string]interface{})[joined["playerId"].(string)]; ok {
+			return
+		}
+	}
+}
+
+func TestMixedCaseCodesReachTheSameRoom(t *testing.T) {
+	server := newTestServer(t, game.NewGameManager())
+	code, host := server.createRoom(t, "Ann")
+	client := server.dialAs(t, code.String(), host)
+	client.next(models.EventGameStateUpdate)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 8; i++ {
+		mixed := mixCase(rng, code.String())
+		for _, input := range []string{mixed, " " + mixed + " "} {
+			if status, body := server.get(t, "/api/rooms/"+url.PathEscape(input)); status != http.StatusOK {
+				t.Fatalf("GET %q: %d %v", input, status, body)
+			}
+
+			joined := server.join(t, input, "Guest")
+			if got := models.RoomCode(joined["room"].(map[string]interface{})["code"].(string)); got != code {
+				t.Fatalf("joining with %q landed in %s", input, got)
+			}
+			client.next(models.EventPlayersUpdate)
+
+			guest := server.dialAs(t, input, joined)
+			state := guest.next(models.EventGameStateUpdate).(map[string]interface{})
+			if got := models.RoomCode(state["code"].(string)); got != code {
+				t.Fatalf("socket opened with %q got the state of %s", input, got)
+			}
+			client.next(models.EventPlayersUpdate)
+
+			if err := server.gm.RemovePlayer(code, joined["playerId"].(string)); err != nil {
+				t.Fatalf("remove guest: %v", err)
+			}
+		}
+	}
+}
+
+func TestNormalizeRoomCode(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		code := make([]byte, 6)
+		for j := range code {
+			code[j] = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"[rng.Intn(32)]
+		}
+		want := models.RoomCode(code)
+		if got := models.NormalizeRoomCode("\t" + mixCase(rng, string(code)) + " "); got != want {
+			t.Fatalf("NormalizeRoomCode(%q) = %q", code, got)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// fakeTransport records what the handlers send instead of delivering it
+type fakeTransport struct {
+	mu         sync.Mutex
+	sent       map[string][]string
+	broadcasts map[models.RoomCode][]string
+}
+
+// useFakeTransport puts a fakeTransport behind the handlers' routes for the
+// rest of the test. Frames for other tests' rooms, whose timers may still
+// fire, land in it too; read it by room.
+func useFakeTransport(t testing.TB) *fakeTransport {
+	t.Helper()
+
+	fake := &fakeTransport{sent: make(map[string][]string), broadcasts: make(map[models.RoomCode][]string)}
+	realSender, realBroadcaster := routes.swap(fake, fake)
+	t.Cleanup(func() { routes.swap(realSender, realBroadcaster) })
+	return fake
+}
+
+func (f *fakeTransport) SendTo(client *ws.Client, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent[client.ID] = append(f.sent[client.ID], eventTypeOf(data))
+}
+
+func (f *fakeTransport) Broadcast(message *ws.BroadcastMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if message.Message != nil {
+		f.broadcasts[message.RoomCode] = append(f.broadcasts[message.RoomCode], eventTypeOf(message.Message))
+		return
+	}
+	for _, data := range message.PerClient {
+		f.broadcasts[message.RoomCode] = append(f.broadcasts[message.RoomCode], eventTypeOf(data))
+		return
+	}
+}
+
+// sentTo returns the event types sent to one client, oldest first
+func (f *fakeTransport) sentTo(id string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sent[id]...)
+}
+
+// broadcast returns the event types sent to a room, oldest first
+func (f *fakeTransport) broadcast(code models.RoomCode) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.broadcasts[code]...)
+}
+
+func eventTypeOf(data []byte) string {
+	var msg models.WSMessage
+	json.Unmarshal(data, &msg)
+	return msg.Type
+}
+
+// lobbyRoom is a room with the host h and the guest g
+func lobbyRoom(t *testing.T, gm *game.GameManager) models.RoomCode {
+	t.Helper()
+
+	room, err := gm.CreateRoom("h", "Host", models.DefaultRoomSettings())
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	if _, err := gm.JoinRoom(room.Code, "g", "Guest"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	return room.Code
+}
+
+func TestHandlerTable(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		from      string
+		observer  bool
+		msg       models.WSMessage
+		reply     string
+		broadcast string
+	}{
+		{"hello", "g", false, models.WSMessage{Type: models.EventHello, Payload: map[string]int{"protocol": CurrentProtocol}}, models.EventHello, ""},
+		{"observer acting", "g", true, models.WSMessage{Type: models.EventSetReady, Payload: map[string]bool{"ready": true}}, models.EventError, ""},
+		{"start by a guest", "g", false, models.WSMessage{Type: models.EventStartGame}, models.EventError, ""},
+		{"ready", "g", false, models.WSMessage{Type: models.EventSetReady, Payload: map[string]bool{"ready": true}}, "", models.EventPlayersUpdate},
+		{"chat", "g", false, models.WSMessage{Type: models.EventChatMessage, Payload: map[string]string{"content": "hi"}}, "", models.EventChatMessage},
+		{"vote in the lobby", "g", false, models.WSMessage{Type: models.EventVote, Payload: map[string]string{"targetId": "h"}}, models.EventError, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := useFakeTransport(t)
+			gm := game.NewGameManager()
+			code := lobbyRoom(t, gm)
+			client := ws.NewDetachedClient(tc.from, code)
+			client.Observer = tc.observer
+
+			msg := tc.msg
+			handleWebSocketMessage(client, gm, &msg)
+
+			replies := fake.sentTo(tc.from)
+			if tc.reply == "" && len(replies) != 0 {
+				t.Errorf("replied %v, want nothing", replies)
+			}
+			if tc.reply != "" && (len(replies) != 1 || replies[0] != tc.reply) {
+				t.Errorf("replied %v, want %s", replies, tc.reply)
+			}
+			broadcasts := fake.broadcast(code)
+			if tc.broadcast == "" && len(broadcasts) != 0 {
+				t.Errorf("broadcast %v, want nothing", broadcasts)
+			}
+			if tc.broadcast != "" && (len(broadcasts) == 0 || broadcasts[0] != tc.broadcast) {
+				t.Errorf("broadcast %v, want %s", broadcasts, tc.broadcast)
+			}
+		})
+	}
+}
+
+func BenchmarkLargestRoomBroadcast(b *testing.B) {
+	useFakeTransport(b)
+	gm := game.NewGameManager()
+	code, _ := startedGame(b, gm, 20)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		broadcastRoomState(gm, code, models.EventGameStateUpdate, nil)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestVoteBySeat(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("end the day: %v", err)
+	}
+
+	view, _ := gm.RoomView(code, "")
+	target := view.Players[ids[3]]
+	body := fmt.Sprintf(`{"playerId":%q,"type":"vote","seat":%d}`, ids[0], target.Seat)
+	w := serveAs(t, PerformAction(gm), http.MethodPost, "/rooms/:code/actions", "/rooms/"+code.String()+"/actions", body, sessionOf(t, gm, code, ids[0]))
+	wantStatus(t, w, http.StatusAccepted)
+
+	view, _ = gm.RoomView(code, "")
+	if got := view.Players[ids[0]].VotedFor; got != ids[3] {
+		t.Errorf("the vote for seat %d went to %q, want %s", target.Seat, got, ids[3])
+	}
+}
+
+func TestVoteByEmptySeat(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("end the day: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"playerId":%q,"type":"vote","seat":7}`, ids[0])
+	w := serveAs(t, PerformAction(gm), http.MethodPost, "/rooms/:code/actions", "/rooms/"+code.String()+"/actions", body, sessionOf(t, gm, code, ids[0]))
+	wantStatus(t, w, http.StatusBadRequest)
+	if got := decodeBody(t, w)["code"]; got != game.ErrInvalidSeat.Code {
+		t.Errorf("code = %v, want %s", got, game.ErrInvalidSeat.Code)
+	}
+
+	view, _ := gm.RoomView(code, "")
+	if voted := view.Players[ids[0]].VotedFor; voted != "" {
+		t.Errorf("a vote for an empty seat was counted for %s", voted)
+	}
+	if view.Phase != models.PhaseVoting {
+		t.Errorf("phase = %s", view.Phase)
+	}
+}
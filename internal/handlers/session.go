@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// sessionHeader carries the session token on REST requests that act as a
+// player. The websocket, which browsers cannot give headers, takes it as
+// ?token instead.
+const sessionHeader = "X-Session-Token"
+
+// closeInvalidSession is the close code sent to a websocket opened for a
+// player without that player's session token
+const closeInvalidSession = 4003
+
+// authenticate checks that the request carries playerID's session token,
+// answering 401 when it does not. A missing room is a 404, or a 410 once
+// archived.
+func authenticate(c *gin.Context, gm *game.GameManager, code models.RoomCode, playerID string) bool {
+	err := gm.Authenticate(code, playerID, c.GetHeader(sessionHeader))
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, game.ErrInvalidSession) {
+		respondError(c, http.StatusUnauthorized, err)
+		return false
+	}
+	respondError(c, http.StatusNotFound, err)
+	return false
+}
+
+// rejectSession closes a player's connection made without their session token
+func rejectSession(conn ws.Conn) {
+	conn.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(closeInvalidSession, models.DisconnectInvalidSession))
+	conn.Close()
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// wantInvalidSession fails the test unless the answer is a 401 INVALID_SESSION
+func wantInvalidSession(t *testing.T, status int, body map[string]interface{}) {
+	t.Helper()
+
+	if status != http.StatusUnauthorized || body["code"] != game.ErrInvalidSession.Code {
+		t.Errorf("got %d %v, want 401 %s", status, body, game.ErrInvalidSession.Code)
+	}
+}
+
+func TestJoinResponseContract(t *testing.T) {
+	server := newTestServer(t, game.NewGameManager())
+	code, host := server.createRoom(t, "Ann")
+	guest := server.join(t, code.String(), "Ben")
+
+	for name, body := range map[string]map[string]interface{}{"host": host, "guest": guest} {
+		for _, key := range []string{"room", "playerId", "sessionToken", "protocol", "websocket", "chat"} {
+			if _, ok := body[key]; !ok {
+				t.Errorf("%s's join response has no %s", name, key)
+			}
+		}
+
+		playerID := body["playerId"].(string)
+		token, _ := body["sessionToken"].(string)
+		if token == "" || token == playerID {
+			t.Errorf("%s's session token is %q", name, token)
+		}
+		if err := server.gm.Authenticate(code, playerID, token); err != nil {
+			t.Errorf("%s's session token does not authenticate: %v", name, err)
+		}
+
+		socket, err := url.Parse(body["websocket"].(string))
+		if err != nil {
+			t.Fatalf("%s's websocket URL: %v", name, err)
+		}
+		if socket.Query().Get("token") != token || socket.Query().Get("playerId") != playerID {
+			t.Errorf("%s's websocket URL %s does not carry their session", name, socket)
+		}
+	}
+
+	// The embedded view never carries a session, the reader's or anyone else's
+	room, err := json.Marshal(guest["room"])
+	if err != nil {
+		t.Fatalf("marshal the guest's room: %v", err)
+	}
+	for _, token := range []string{host["sessionToken"].(string), guest["sessionToken"].(string)} {
+		if strings.Contains(string(room), token) {
+			t.Errorf("the guest's room view carries the session %s", token)
+		}
+	}
+	if strings.Contains(string(room), "sessionToken") {
+		t.Error("the guest's room view has a sessionToken field")
+	}
+}
+
+func TestSocketNeedsSession(t *testing.T) {
+	server := newTestServer(t, game.NewGameManager())
+	code, host := server.createRoom(t, "Ann")
+	guest := server.join(t, code.String(), "Ben")
+	hostID := host["playerId"].(string)
+
+	for name, token := range map[string]string{
+		"no token":           "",
+		"the player ID":      hostID,
+		"another's session":  guest["sessionToken"].(string),
+		"a malformed secret": "not-a-session",
+	} {
+		client := server.dial(t, url.Values{"roomCode": {code.String()}, "playerId": {hostID}, "token": {token}})
+		if got := client.closeCode(); got != closeInvalidSession {
+			t.Errorf("%s: close code %d, want %d", name, got, closeInvalidSession)
+		}
+	}
+
+	view, _ := server.gm.RoomView(code, "")
+	if view.Players[hostID].IsConnected {
+		t.Error("a rejected socket marked the host connected")
+	}
+
+	client := server.dialAs(t, code.String(), host)
+	client.next(models.EventGameStateUpdate)
+}
+
+func TestRESTActionsNeedSession(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("end the day: %v", err)
+	}
+	server := newTestServer(t, gm)
+	path := "/api/rooms/" + code.String() + "/actions"
+	body := fmt.Sprintf(`{"playerId":%q,"type":"vote","targetId":%q}`, ids[0], ids[1])
+
+	for name, token := range map[string]string{
+		"no token":          "",
+		"the player ID":     ids[0],
+		"another's session": sessionOf(t, gm, code, ids[2]),
+	} {
+		status, answer := server.do(t, http.MethodPost, path, body, token)
+		wantInvalidSession(t, status, answer)
+		if view, _ := gm.RoomView(code, ""); view.Players[ids[0]].VotedFor != "" {
+			t.Fatalf("%s: the vote was counted", name)
+		}
+	}
+
+	if status, answer := server.do(t, http.MethodPost, path, body, sessionOf(t, gm, code, ids[0])); status != http.StatusAccepted {
+		t.Fatalf("vote with the session: %d %v", status, answer)
+	}
+	if view, _ := gm.RoomView(code, ""); view.Players[ids[0]].VotedFor != ids[1] {
+		t.Error("the vote sent with the session was not counted")
+	}
+}
+
+func TestHostRoutesNeedSession(t *testing.T) {
+	server := newTestServer(t, game.NewGameManager())
+	code, host := server.createRoom(t, "Ann")
+	hostID := host["playerId"].(string)
+	token := host["sessionToken"].(string)
+	invites := "/api/rooms/" + code.String() + "/invites"
+	create := fmt.Sprintf(`{"playerId":%q,"count":1}`, hostID)
+	list := invites + "?playerId=" + url.QueryEscape(hostID)
+
+	status, answer := server.do(t, http.MethodPost, invites, create, "")
+	wantInvalidSession(t, status, answer)
+	status, answer = server.do(t, http.MethodGet, list, "", hostID)
+	wantInvalidSession(t, status, answer)
+
+	status, answer = server.do(t, http.MethodPost, invites, create, token)
+	if status != http.StatusCreated {
+		t.Fatalf("create invites with the session: %d %v", status, answer)
+	}
+	invite := answer["invites"].([]interface{})[0].(map[string]interface{})["token"].(string)
+	revoke := invites + "/" + url.PathEscape(invite) + "?playerId=" + url.QueryEscape(hostID)
+
+	status, answer = server.do(t, http.MethodDelete, revoke, "", "")
+	wantInvalidSession(t, status, answer)
+	if status, answer = server.do(t, http.MethodGet, list, "", token); status != http.StatusOK || len(answer["invites"].([]interface{})) != 1 {
+		t.Fatalf("list invites with the session: %d %v", status, answer)
+	}
+	if status, answer = server.do(t, http.MethodDelete, revoke, "", token); status != http.StatusOK {
+		t.Errorf("revoke with the session: %d %v", status, answer)
+	}
+
+	preview := fmt.Sprintf(`{"playerId":%q,"actions":{}}`, hostID)
+	status, answer = server.do(t, http.MethodPost, "/api/rooms/"+code.String()+"/practice/resolve-night", preview, "")
+	wantInvalidSession(t, status, answer)
+}
+
+func TestChatHistoryNeedsSessionForPrivateChannels(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+
+	// A villager dies at the vote, then speaks in the dead chat
+	room, _ := gm.GetRoom(code)
+	victim := ""
+	for _, id := range ids[1:] {
+		if room.Players[id].Role == models.RoleVillager {
+			victim = id
+			break
+		}
+	}
+	if victim == "" {
+		t.Fatal("no villager was dealt")
+	}
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("end the day: %v", err)
+	}
+	for _, voter := range ids {
+		choice := victim
+		if voter == victim {
+			choice = ids[0]
+		}
+		if err := gm.Vote(code, voter, choice); err != nil {
+			t.Fatalf("%s votes: %v", voter, err)
+		}
+	}
+	if _, err := gm.MoveToNextPhase(code); err != nil {
+		t.Fatalf("count the votes: %v", err)
+	}
+	message, err := gm.PostChat(code, victim, "boo")
+	if err != nil || message.Type != models.MessageDead {
+		t.Fatalf("post in the dead chat: %+v, %v", message, err)
+	}
+
+	server := newTestServer(t, gm)
+	chat := "/api/rooms/" + code.String() + "/chat"
+	hasDeadChat := func(body map[string]interface{}) bool {
+		for _, m := range body["messages"].([]interface{}) {
+			if m.(map[string]interface{})["type"] == models.MessageDead {
+				return true
+			}
+		}
+		return false
+	}
+
+	if status, body := server.get(t, chat); status != http.StatusOK || hasDeadChat(body) {
+		t.Errorf("anonymous reader: %d, dead chat shown %v", status, hasDeadChat(body))
+	}
+	status, body := server.do(t, http.MethodGet, chat+"?playerId="+url.QueryEscape(victim), "", "")
+	wantInvalidSession(t, status, body)
+	status, body = server.do(t, http.MethodGet, chat+"?playerId="+url.QueryEscape(victim), "", sessionOf(t, gm, code, ids[0]))
+	wantInvalidSession(t, status, body)
+
+	if status, body := server.do(t, http.MethodGet, chat+"?playerId="+url.QueryEscape(victim), "", sessionOf(t, gm, code, victim)); status != http.StatusOK || !hasDeadChat(body) {
+		t.Errorf("the dead player with their session: %d, dead chat shown %v", status, hasDeadChat(body))
+	}
+	if status, body := server.do(t, http.MethodGet, chat+"?playerId="+url.QueryEscape(ids[0]), "", sessionOf(t, gm, code, ids[0])); status != http.StatusOK || hasDeadChat(body) {
+		t.Errorf("a living player with their session: %d, dead chat shown %v", status, hasDeadChat(body))
+	}
+}
+
+func TestJoinRequestPollNeedsSession(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	code, host := server.createRoom(t, "Ann")
+	hostID := host["playerId"].(string)
+
+	view, _ := gm.RoomView(code, hostID)
+	settings := view.Settings
+	settings.JoinApprovalRequired = true
+	if err := gm.UpdateSettings(code, hostID, settings); err != nil {
+		t.Fatalf("require approval: %v", err)
+	}
+
+	status, knocked := server.post(t, "/api/rooms/"+code.String()+"/join", `{"username":"Ben"}`)
+	if status != http.StatusAccepted {
+		t.Fatalf("knock: %d %v", status, knocked)
+	}
+	request := knocked["request"].(map[string]interface{})
+	token, _ := knocked["sessionToken"].(string)
+	if token == "" {
+		t.Fatal("the knock came back without a session")
+	}
+	if _, leaked := request["sessionToken"]; leaked {
+		t.Error("the request itself carries the session")
+	}
+	poll := "/api/rooms/" + code.String() + "/join-requests/" + request["id"].(string)
+
+	if _, err := gm.RespondJoinRequest(code, hostID, request["id"].(string), true); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	status, body := server.do(t, http.MethodGet, poll, "", "")
+	wantInvalidSession(t, status, body)
+	status, body = server.do(t, http.MethodGet, poll, "", host["sessionToken"].(string))
+	wantInvalidSession(t, status, body)
+
+	status, body = server.do(t, http.MethodGet, poll, "", token)
+	if status != http.StatusOK || body["sessionToken"] != token {
+		t.Fatalf("poll with the session: %d %v", status, body)
+	}
+	client := server.dialAs(t, code.String(), body)
+	client.next(models.EventGameStateUpdate)
+}
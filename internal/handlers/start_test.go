@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// fullLobby creates a room over REST and joins five guests, returning the
+// host's and the guests' join responses in the order they joined
+func fullLobby(t *testing.T, server *testServer) (models.RoomCode, map[string]interface{}, []map[string]interface{}) {
+	t.Helper()
+
+	code, host := server.createRoom(t, "Host")
+	var guests []map[string]interface{}
+	for _, name := range []string{"Ann", "Ben", "Cat", "Dan", "Eve"} {
+		guests = append(guests, server.join(t, code.String(), name))
+	}
+	return code, host, guests
+}
+
+// errorCode is the code of an error event's payload
+func errorCode(payload interface{}) interface{} {
+	body, _ := payload.(map[string]interface{})
+	return body["code"]
+}
+
+func TestGuestCannotStartTheGame(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	code, host, guests := fullLobby(t, server)
+
+	guest := server.dialAs(t, code.String(), guests[0])
+	guest.next(models.EventGameStateUpdate)
+	guest.send(models.EventStartGame, nil)
+	if got := errorCode(guest.next(models.EventError)); got != game.ErrNotPermitted.Code {
+		t.Fatalf("a guest's start was answered with %v, want %s", got, game.ErrNotPermitted.Code)
+	}
+	if phase, _ := gm.RoomPhase(code); phase != models.PhaseWaiting {
+		t.Fatalf("phase = %s, want the lobby", phase)
+	}
+
+	client := server.dialAs(t, code.String(), host)
+	client.next(models.EventGameStateUpdate)
+	client.send(models.EventStartGame, nil)
+	client.next(models.EventGameStarted)
+}
+
+func TestStartWithTheHostGone(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	code, host, guests := fullLobby(t, server)
+
+	// The host vanished without the room handing it on
+	room, _ := gm.GetRoom(code)
+	room.RemovePlayer(host["playerId"].(string))
+	heir := guests[0]["playerId"].(string)
+
+	guest := server.dialAs(t, code.String(), guests[2])
+	guest.next(models.EventGameStateUpdate)
+	guest.send(models.EventStartGame, nil)
+
+	refused, _ := guest.next(models.EventError).(map[string]interface{})
+	params, _ := refused["params"].(map[string]interface{})
+	if refused["code"] != game.ErrHostReassigned.Code || params["hostId"] != heir {
+		t.Fatalf("start with the host gone = %v, want %s naming %s", refused, game.ErrHostReassigned.Code, heir)
+	}
+	update, _ := guest.next(models.EventPlayersUpdate).(map[string]interface{})
+	if update["hostId"] != heir {
+		t.Errorf("players_update names host %v, want %s", update["hostId"], heir)
+	}
+	if phase, _ := gm.RoomPhase(code); phase != models.PhaseWaiting {
+		t.Fatalf("the reassignment started the game: %s", phase)
+	}
+
+	// The guest is still not the host; the heir starts the game
+	guest.send(models.EventStartGame, nil)
+	if got := errorCode(guest.next(models.EventError)); got != game.ErrNotPermitted.Code {
+		t.Errorf("a guest's second start was answered with %v", got)
+	}
+	client := server.dialAs(t, code.String(), guests[0])
+	client.next(models.EventGameStateUpdate)
+	client.send(models.EventStartGame, nil)
+	client.next(models.EventGameStarted)
+}
+
+func TestRolesListed(t *testing.T) {
+	w := serve(t, ListRoles(), http.MethodGet, "/api/roles", "/api/roles", "")
+	wantStatus(t, w, http.StatusOK)
+	roles, _ := decodeBody(t, w)["roles"].([]interface{})
+	if len(roles) != len(models.AllRoles) {
+		t.Fatalf("listed %d roles, want %d", len(roles), len(models.AllRoles))
+	}
+	for i, role := range models.AllRoles {
+		if card := roles[i].(map[string]interface{}); card["role"] != string(role) || card["winCondition"] == nil {
+			t.Errorf("card %d = %v, want %s's", i, card, role)
+		}
+	}
+}
+
+func TestEachPlayerToldTheirRoleOnStart(t *testing.T) {
+	gm := game.NewGameManager()
+	server := newTestServer(t, gm)
+	code, host, guests := fullLobby(t, server)
+
+	joined := append([]map[string]interface{}{host}, guests...)
+	clients := make([]*wsClient, len(joined))
+	for i, player := range joined {
+		clients[i] = server.dialAs(t, code.String(), player)
+		clients[i].next(models.EventGameStateUpdate)
+	}
+	clients[0].send(models.EventStartGame, nil)
+	clients[0].next(models.EventGameStarted)
+	dealt := gm.RoleAssignments(code)
+
+	for i, player := range joined {
+		id := player["playerId"].(string)
+		want := dealt[id].Role
+
+		assignment, _ := clients[i].next(models.EventRoleAssigned).(map[string]interface{})
+		info, _ := assignment["info"].(map[string]interface{})
+		if assignment["role"] != string(want) || info["role"] != string(want) || info["description"] == nil {
+			t.Errorf("%s was assigned %v, want their %s card", id, assignment, want)
+		}
+		// Only their own card, once
+		if again, ok := clients[i].await(models.EventRoleAssigned, 100*time.Millisecond); ok {
+			t.Errorf("%s was sent a second card %v", id, again)
+		}
+	}
+}
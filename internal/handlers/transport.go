@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
+)
+
+// Sender delivers a frame to one client
+type Sender interface {
+	SendTo(client *ws.Client, data []byte)
+}
+
+// Broadcaster delivers frames to the clients in a room
+type Broadcaster interface {
+	Broadcast(message *ws.BroadcastMessage)
+}
+
+// degradePolicy is what a client that falls behind can do without
+var degradePolicy = ws.DegradePolicy{
+	Coalesce: map[string]bool{
+		models.EventGameStateUpdate: true,
+		models.EventPlayersUpdate:   true,
+		models.EventVotesUpdate:     true,
+		models.EventVoteUpdate:      true,
+		models.EventTigerTeamUpdate: true,
+		models.EventReadyUpdate:     true,
+	},
+	Drop: map[string]bool{
+		models.EventColorChanged: true,
+	},
+}
+
+var (
+	hub      = ws.NewHub(degradePolicy)
+	outbound = ws.NewRoomQueues(hub)
+
+	// The handlers send everything through routes, so the transport behind
+	// it can be swapped out
+	routes = &switchboard{sender: outbound, broadcaster: outbound}
+)
+
+// switchboard hands each frame to the sender or broadcaster in place at
+// the time. Tests swap them while other rooms' timers may still be sending.
+type switchboard struct {
+	mu          sync.RWMutex
+	sender      Sender
+	broadcaster Broadcaster
+}
+
+func (s *switchboard) SendTo(client *ws.Client, data []byte) {
+	s.mu.RLock()
+	to := s.sender
+	s.mu.RUnlock()
+	to.SendTo(client, data)
+}
+
+func (s *switchboard) Broadcast(message *ws.BroadcastMessage) {
+	s.mu.RLock()
+	to := s.broadcaster
+	s.mu.RUnlock()
+	to.Broadcast(message)
+}
+
+// swap puts sender and broadcaster in place and returns the ones they replace
+func (s *switchboard) swap(sender Sender, broadcaster Broadcaster) (Sender, Broadcaster) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previousSender, previousBroadcaster := s.sender, s.broadcaster
+	s.sender, s.broadcaster = sender, broadcaster
+	return previousSender, previousBroadcaster
+}
+
+func init() {
+	go hub.Run()
+}
+
+// ProtocolCounts reports how many connected clients speak each protocol
+// version, so we can tell when an old version can be dropped
+func ProtocolCounts() map[int]int {
+	return hub.ProtocolCounts()
+}
+
+// CapabilityCounts reports how many connected clients declared each capability
+func CapabilityCounts() map[ws.Capability]int {
+	return hub.CapabilityCounts()
+}
+
+// BroadcastDrops reports how many broadcasts were dropped because a room's
+// outbound queue overflowed
+func BroadcastDrops() int64 {
+	return outbound.Dropped()
+}
+
+// ScopeRoomSenders runs each room's outbound sender, and the watch over
+// each delayed client's timer, as the room's goroutines, so they stop when
+// the game manager deletes the room
+func ScopeRoomSenders(gm *game.GameManager) {
+	outbound.SetSpawner(gm.GoRoom)
+	hub.SetSpawner(gm.GoRoom)
+}
+
+// AdminListClients lists every connection with its outbound rate, queue
+// depth and whether it is in degraded mode
+func AdminListClients() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"clients": hub.ClientStats(time.Now())})
+	}
+}
+
+// DefaultRoomHeartbeatInterval is how long a room may go without a broadcast
+// before it is sent a room_heartbeat
+const DefaultRoomHeartbeatInterval = 30 * time.Second
+
+// roomHeartbeat is the payload of room_heartbeat
+type roomHeartbeat struct {
+	Players    int              `json:"players"`
+	Spectators int              `json:"spectators"`
+	Phase      models.GamePhase `json:"phase"`
+	Seq        int64            `json:"seq"`
+	ServerTime models.Timestamp `json:"serverTime"`
+}
+
+// StartRoomHeartbeat sends a room_heartbeat to every room that goes the
+// interval without any other broadcast, so lobbies can show who is there
+// and clients know the connection is alive. An interval of 0 turns it off.
+func StartRoomHeartbeat(gm *game.GameManager, interval time.Duration) {
+	outbound.SetHeartbeat(interval, func(beat ws.Heartbeat) []byte {
+		phase, exists := gm.RoomPhase(beat.RoomCode)
+		if !exists {
+			return nil
+		}
+
+		data, err := encodeMessage(models.EventRoomHeartbeat, roomHeartbeat{
+			Players:    beat.Players,
+			Spectators: beat.Spectators,
+			Phase:      phase,
+			Seq:        beat.Seq,
+			ServerTime: models.NewTimestamp(beat.At),
+		})
+		if err != nil {
+			log.Printf("JSON marshal error: %v", err)
+			return nil
+		}
+		return data
+	})
+}
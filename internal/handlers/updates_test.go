@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// stateEvents are the events that carry room state for clients to re-render
+var stateEvents = map[string]bool{
+	models.EventGameStateUpdate: true,
+	models.EventPlayersUpdate:   true,
+	models.EventPhaseUpdate:     true,
+	models.EventVotesUpdate:     true,
+}
+
+// eventsBefore reads until a chat message with the given content arrives
+// and returns the events of the watched types seen on the way, in order
+func (c *wsClient) eventsBefore(content string, watched map[string]bool) []string {
+	c.t.Helper()
+
+	var seen []string
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+	for {
+		var frame struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := c.conn.ReadJSON(&frame); err != nil {
+			c.t.Fatalf("no chat %q arrived: %v", content, err)
+		}
+		if frame.Type == models.EventChatMessage {
+			var message models.Message
+			if json.Unmarshal(frame.Payload, &message) == nil && message.Content == content {
+				return seen
+			}
+		}
+		if watched[frame.Type] {
+			seen = append(seen, frame.Type)
+		}
+	}
+}
+
+func TestScopedUpdates(t *testing.T) {
+	gm := game.NewGameManager()
+	code, ids := startedGame(t, gm, 6)
+	server := newTestServer(t, gm)
+
+	watcher := server.dialPlayer(t, code, ids[2])
+	watcher.next(models.EventGameStateUpdate)
+	actor := server.dialPlayer(t, code, ids[1])
+	actor.next(models.EventGameStateUpdate)
+
+	// Whatever the actor's arrival set off comes before their first message
+	actor.send(models.EventChatMessage, map[string]string{"content": "sync"})
+	watcher.eventsBefore("sync", stateEvents)
+
+	t.Run("chat", func(t *testing.T) {
+		actor.send(models.EventChatMessage, map[string]string{"content": "hello"})
+		actor.send(models.EventChatMessage, map[string]string{"content": "again"})
+		if seen := append(watcher.eventsBefore("hello", stateEvents), watcher.eventsBefore("again", stateEvents)...); len(seen) != 0 {
+			t.Errorf("chat set off %v", seen)
+		}
+	})
+
+	t.Run("vote", func(t *testing.T) {
+		if _, err := gm.MoveToNextPhase(code); err != nil {
+			t.Fatalf("move to the vote: %v", err)
+		}
+		actor.send(models.EventVote, map[string]string{"targetId": ids[3]})
+		actor.send(models.EventChatMessage, map[string]string{"content": "voted"})
+		if seen := watcher.eventsBefore("voted", stateEvents); len(seen) != 1 || seen[0] != models.EventVotesUpdate {
+			t.Errorf("a vote set off %v, want only %s", seen, models.EventVotesUpdate)
+		}
+	})
+}
@@ -2,14 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/werewolf-game/backend/internal/game"
 	"github.com/werewolf-game/backend/internal/models"
+	"github.com/werewolf-game/backend/internal/ws"
 )
 
 var upgrader = websocket.Upgrader{
@@ -18,71 +21,15 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-type Client struct {
-	ID       string
-	RoomCode string
-	Conn     *websocket.Conn
-	Send     chan []byte
-}
-
-type Hub struct {
-	Clients    map[string]*Client
-	Broadcast  chan *BroadcastMessage
-	Register   chan *Client
-	Unregister chan *Client
-	mu         sync.RWMutex
-}
-
-type BroadcastMessage struct {
-	RoomCode string
-	Message  []byte
-}
-
-var hub = &Hub{
-	Clients:    make(map[string]*Client),
-	Broadcast:  make(chan *BroadcastMessage),
-	Register:   make(chan *Client),
-	Unregister: make(chan *Client),
-}
+// WebSocketPath is where HandleWebSocket is served
+const WebSocketPath = "/ws"
 
-func init() {
-	go hub.Run()
-}
-
-func (h *Hub) Run() {
-	for {
-		select {
-		case client := <-h.Register:
-			h.mu.Lock()
-			h.Clients[client.ID] = client
-			h.mu.Unlock()
-			log.Printf("Client registered: %s in room %s", client.ID, client.RoomCode)
+// DefaultSpectatorDelay is how far behind the game delayed spectators see it
+const DefaultSpectatorDelay = 90 * time.Second
 
-		case client := <-h.Unregister:
-			h.mu.Lock()
-			if _, ok := h.Clients[client.ID]; ok {
-				delete(h.Clients, client.ID)
-				close(client.Send)
-				log.Printf("Client unregistered: %s", client.ID)
-			}
-			h.mu.Unlock()
-
-		case message := <-h.Broadcast:
-			h.mu.RLock()
-			for _, client := range h.Clients {
-				if client.RoomCode == message.RoomCode {
-					select {
-					case client.Send <- message.Message:
-					default:
-						close(client.Send)
-						delete(h.Clients, client.ID)
-					}
-				}
-			}
-			h.mu.RUnlock()
-		}
-	}
-}
+// SpectatorDelay is how far behind the game a spectator connecting with
+// mode=delayed sees it; 0 turns the mode off. Set it before serving.
+var SpectatorDelay = DefaultSpectatorDelay
 
 // HandleWebSocket handles WebSocket connections
 func HandleWebSocket(gm *game.GameManager) gin.HandlerFunc {
@@ -94,159 +41,604 @@ func HandleWebSocket(gm *game.GameManager) gin.HandlerFunc {
 		}
 
 		playerID := c.Query("playerId")
-		roomCode := c.Query("roomCode")
+		roomCode := gm.ResolveRoomCode(c.Query("roomCode"))
 
-		if playerID == "" || roomCode == "" {
+		if roomCode == "" {
 			conn.Close()
 			return
 		}
 
-		client := &Client{
-			ID:       playerID,
-			RoomCode: roomCode,
-			Conn:     conn,
-			Send:     make(chan []byte, 256),
+		protocol, ok := parseProtocol(c.Query("protocol"))
+		if !ok {
+			rejectProtocol(conn)
+			return
 		}
 
-		hub.Register <- client
+		// A client left over from an archived game is told it is over
+		if errors.Is(gm.MissingRoomError(roomCode), game.ErrRoomArchived) {
+			if _, exists := gm.GetRoom(roomCode); !exists {
+				rejectArchived(conn)
+				return
+			}
+		}
 
-		// Send current room state to the newly connected client
-		room, exists := gm.GetRoom(roomCode)
-		if exists {
-			sendToClient(client, models.EventGameStateUpdate, room)
+		capabilities, declared := parseCapabilities(splitCapabilities(c.Query("capabilities")))
+		if !declared {
+			capabilities = defaultCapabilities(protocol)
+		}
 
-			// Broadcast player joined event to all clients in the room
-			broadcastToRoom(roomCode, models.EventPlayerJoined, room)
+		// A delayed spectator, such as a streamer, may watch a game in
+		// progress. They see only what the public sees, and that late.
+		if playerID == "" && c.Query("mode") == "delayed" && SpectatorDelay > 0 {
+			observer := ws.NewClient(conn, "observer-"+uuid.New().String(), roomCode, c.ClientIP())
+			observer.Observer = true
+			observer.Delay = SpectatorDelay
+			observer.Protocol = protocol
+			observer.Capabilities = capabilities
+
+			// Like a player's, the snapshot goes out before any later
+			// broadcast, and is held back just as long
+			unlock := lockRoom(roomCode)
+			view, exists := gm.RoomView(roomCode, "")
+			if !exists {
+				unlock()
+				conn.Close()
+				return
+			}
+			hub.RegisterNow(observer)
+			sendToClient(observer, models.EventGameStateUpdate, models.NewRoomPublic(view))
+			sendAnnouncement(observer)
+			unlock()
+
+			serveClient(observer, gm)
+			return
 		}
 
-		go client.WritePump()
-		go client.ReadPump(gm)
-	}
-}
+		// Without a player ID, anyone who knows the code may watch a
+		// finished game; secrets stop mattering once it has ended
+		if playerID == "" {
+			summary, err := gm.GameSummary(roomCode)
+			if err != nil {
+				conn.Close()
+				return
+			}
 
-func (c *Client) ReadPump(gm *game.GameManager) {
-	defer func() {
-		hub.Unregister <- c
-		c.Conn.Close()
-	}()
+			observer := ws.NewClient(conn, "observer-"+uuid.New().String(), roomCode, c.ClientIP())
+			observer.Observer = true
+			observer.Protocol = protocol
+			observer.Capabilities = capabilities
 
-	for {
-		_, message, err := c.Conn.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+			hub.RegisterNow(observer)
+			sendToClient(observer, models.EventGameSummary, summary)
+			sendAnnouncement(observer)
+
+			serveClient(observer, gm)
+			return
+		}
+
+		// A player ID is public, so only the session token lets a socket act as one
+		if err := gm.Authenticate(roomCode, playerID, c.Query("token")); errors.Is(err, game.ErrInvalidSession) {
+			rejectSession(conn)
+			return
+		} else if err != nil {
+			conn.Close()
+			return
+		}
+
+		client := ws.NewClient(conn, playerID, roomCode, c.ClientIP())
+		client.Protocol = protocol
+		client.Capabilities = capabilities
+
+		// The snapshot must reach the client before any later broadcast
+		unlock := lockRoom(roomCode)
+		hub.RegisterNow(client)
+		restored, extension := gm.SetConnected(roomCode, playerID, true)
+
+		// Send current room state to the newly connected client
+		view, exists := gm.RoomView(roomCode, playerID)
+		if exists {
+			sendToClient(client, models.EventGameStateUpdate, models.NewRoomForViewer(view))
+			sendAnnouncement(client)
+			if hub.HasCapability(client, ws.CapChatReplay) {
+				sendToClient(client, models.EventChatHistory, gm.ChatHistory(roomCode, playerID))
+			}
+			// Prompts are events, not state, so the snapshot alone would leave
+			// a player reconnecting mid-prompt with nothing to answer
+			for _, prompt := range gm.PendingPrompts(roomCode, playerID) {
+				sendToClient(client, prompt.Type, prompt.Payload)
+			}
+
+			// Let everyone else in the room see the new roster
+			broadcastPlayersUpdate(gm, roomCode)
+			refreshAutoStart(gm, roomCode)
+			if restored {
+				broadcastToRoom(roomCode, models.EventQuorumRestored, nil)
+				broadcastPhaseUpdate(gm, roomCode)
+				sendTurnPrompt(gm, roomCode)
+			}
+			if extension != nil {
+				broadcastToRoom(roomCode, models.EventPhaseExtended, extension)
+				broadcastPhaseUpdate(gm, roomCode)
 			}
-			break
 		}
+		unlock()
 
+		serveClient(client, gm)
+	}
+}
+
+// serveClient runs a connected client's pumps, dispatching what it sends
+func serveClient(client *ws.Client, gm *game.GameManager) {
+	go client.WritePump(hub)
+	go client.ReadPump(hub, func(message []byte) {
 		var wsMsg models.WSMessage
 		if err := json.Unmarshal(message, &wsMsg); err != nil {
 			log.Printf("JSON unmarshal error: %v", err)
-			continue
+			return
+		}
+		if wsMsg.Type == models.EventPing {
+			handlePing(client, &wsMsg)
+			return
 		}
 
-		handleWebSocketMessage(c, gm, &wsMsg)
-	}
+		unlock := lockRoom(client.RoomCode)
+		handleWebSocketMessage(client, gm, &wsMsg)
+		unlock()
+	}, func(readErr error) {
+		if client.Observer {
+			return
+		}
+
+		gm.RecordDisconnect(client.RoomCode, models.DisconnectRecord{
+			PlayerID: client.ID,
+			Reason:   hub.DisconnectReason(client, readErr),
+			At:       models.NewTimestamp(time.Now()),
+			AddrHash: client.AddrHash,
+		})
+
+		unlock := lockRoom(client.RoomCode)
+		gm.SetConnected(client.RoomCode, client.ID, false)
+		broadcastPlayersUpdate(gm, client.RoomCode)
+		refreshAutoStart(gm, client.RoomCode)
+		unlock()
+	})
 }
 
-func (c *Client) WritePump() {
-	defer c.Conn.Close()
+func handleWebSocketMessage(client *ws.Client, gm *game.GameManager, msg *models.WSMessage) {
+	// Anyone may (re)declare their protocol version and capabilities, observers included
+	if msg.Type == models.EventHello {
+		var hello struct {
+			Protocol     json.Number `json:"protocol"`
+			Capabilities []string    `json:"capabilities"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &hello)
 
-	for message := range c.Send {
-		if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
-			log.Printf("Write error: %v", err)
+		version, ok := parseProtocol(hello.Protocol.String())
+		if !ok {
+			// The write pump may be mid-frame; it sends the close itself
+			hub.Close(client, closeUnsupportedProtocol, closeUnsupportedProtocolText, models.DisconnectUnsupportedProtocol)
 			return
 		}
+		capabilities, declared := parseCapabilities(hello.Capabilities)
+		if !declared {
+			capabilities = defaultCapabilities(version)
+		}
+		hub.SetProtocol(client, version)
+		hub.SetCapabilities(client, capabilities)
+		sendToClient(client, models.EventHello, gin.H{
+			"protocol":     version,
+			"current":      CurrentProtocol,
+			"capabilities": capabilityList(capabilities),
+		})
+		return
+	}
+
+	if client.Observer {
+		sendError(client, "observers cannot act")
+		return
+	}
+
+	// Administrative actions leave a system line in the chat
+	defer broadcastSystemNotices(gm, client.RoomCode)
+
+	// A request the player already sent, over this transport or the other,
+	// is acked with what it did instead of being applied twice
+	if requestID := payloadString(msg, "requestId"); requestID != "" {
+		if record, handled := gm.HandledRequest(client.RoomCode, client.ID, requestID); handled {
+			sendToClient(client, models.EventActionAck, record)
+			return
+		}
+		mark := gm.ActionMark(client.RoomCode, client.ID)
+		defer gm.RememberRequest(client.RoomCode, client.ID, requestID, mark)
 	}
-}
 
-func handleWebSocketMessage(client *Client, gm *game.GameManager, msg *models.WSMessage) {
 	switch msg.Type {
 	case models.EventStartGame:
-		if err := gm.StartGame(client.RoomCode); err != nil {
-			sendError(client, err.Error())
+		if err := gm.StartGame(client.RoomCode, client.ID); err != nil {
+			sendGameError(client, err)
+			if errors.Is(err, game.ErrHostReassigned) {
+				broadcastPlayersUpdate(gm, client.RoomCode)
+			}
 			return
 		}
 
-		room, _ := gm.GetRoom(client.RoomCode)
-		broadcastToRoom(client.RoomCode, models.EventGameStarted, room)
+		broadcastRoomState(gm, client.RoomCode, models.EventGameStarted, nil)
+		sendRoleAssignments(gm, client.RoomCode)
 
-	case models.EventSkipPhase:
-		nightResult, err := gm.MoveToNextPhase(client.RoomCode)
-		if err != nil {
-			sendError(client, err.Error())
+	case models.EventSetReady:
+		var readyData struct {
+			Ready bool `json:"ready"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &readyData)
+
+		if err := gm.SetReady(client.RoomCode, client.ID, readyData.Ready); err != nil {
+			sendGameError(client, err)
 			return
 		}
 
-		room, _ := gm.GetRoom(client.RoomCode)
+		broadcastPlayersUpdate(gm, client.RoomCode)
+		refreshAutoStart(gm, client.RoomCode)
 
-		// Include night result if transitioning from night to day
-		payload := map[string]interface{}{
-			"room": room,
+	case models.EventCancelAutoStart:
+		if err := gm.CancelAutoStart(client.RoomCode, client.ID); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastToRoom(client.RoomCode, models.EventAutoStartCancelled, gin.H{"reason": game.AutoStartHostCancelled})
+
+	case models.EventRevealBody:
+		if err := gm.RevealBody(client.RoomCode, client.ID); err != nil {
+			sendGameError(client, err)
+			return
 		}
-		if nightResult != nil {
-			payload["nightResult"] = nightResult
+
+		announceRevealedDeaths(gm, client.RoomCode)
+		broadcastPlayersUpdate(gm, client.RoomCode)
+		broadcastSystemNotices(gm, client.RoomCode)
+
+	case models.EventLeaveRoom:
+		if err := gm.RemovePlayer(client.RoomCode, client.ID); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastPlayersUpdate(gm, client.RoomCode)
+		warnComposition(gm, client.RoomCode)
+		refreshAutoStart(gm, client.RoomCode)
+
+	case models.EventSkipPhase:
+		nightResult, err := gm.AdvancePhase(client.RoomCode, client.ID)
+		if err != nil {
+			sendGameError(client, err)
+			return
 		}
 
-		broadcastToRoom(client.RoomCode, models.EventPhaseChanged, payload)
+		broadcastPhaseChange(gm, client.RoomCode, nightResult, "")
 
 	case models.EventSkipAction:
-		room, _ := gm.GetRoom(client.RoomCode)
-		player := room.Players[client.ID]
+		if err := gm.SkipNightAction(client.RoomCode, client.ID, payloadString(msg, "turnToken")); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		finishNightTurn(client, gm)
 
-		// Validate it's this player's turn
-		if room.CurrentNightRole != player.Role {
-			sendError(client, "not your turn")
+	case models.EventChatMessage:
+		// Only the content is taken from the client. Type, ID, sender and
+		// timestamp are set by the server, so nobody can pose as the narrator.
+		var chatData struct {
+			Content string `json:"content"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &chatData)
+
+		message, err := gm.PostChat(client.RoomCode, client.ID, chatData.Content)
+		if err != nil {
+			sendGameError(client, err)
 			return
 		}
 
-		if err := gm.MarkNightActionComplete(client.RoomCode, client.ID); err != nil {
-			sendError(client, err.Error())
+		broadcastChat(gm, client.RoomCode, models.EventChatMessage, message)
+
+	case models.EventEditMessage:
+		var editData struct {
+			MessageID string `json:"messageId"`
+			Content   string `json:"content"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &editData)
+
+		message, err := gm.EditMessage(client.RoomCode, client.ID, editData.MessageID, editData.Content)
+		if err != nil {
+			sendGameError(client, err)
 			return
 		}
 
-		// Move to next role
-		allDone, err := gm.MoveToNextNightRole(client.RoomCode)
+		broadcastChat(gm, client.RoomCode, models.EventMessageEdited, message)
+
+	case models.EventDeleteMessage:
+		var deleteData struct {
+			MessageID string `json:"messageId"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &deleteData)
+
+		message, err := gm.DeleteMessage(client.RoomCode, client.ID, deleteData.MessageID)
 		if err != nil {
-			sendError(client, err.Error())
+			sendGameError(client, err)
 			return
 		}
 
-		room, _ = gm.GetRoom(client.RoomCode)
+		broadcastChat(gm, client.RoomCode, models.EventMessageDeleted, message)
 
-		if allDone {
-			// All roles have acted or skipped, move to next phase
-			nightResult, err := gm.MoveToNextPhase(client.RoomCode)
-			if err != nil {
-				sendError(client, err.Error())
-				return
-			}
+	case models.EventUpdateSettings:
+		current, exists := gm.RoomSettings(client.RoomCode)
+		if !exists {
+			sendGameError(client, gm.MissingRoomError(client.RoomCode))
+			return
+		}
 
-			room, _ = gm.GetRoom(client.RoomCode)
-			payload := map[string]interface{}{
-				"message": "All night actions completed",
-				"room":    room,
-			}
-			if nightResult != nil {
-				payload["nightResult"] = nightResult
+		// Fields missing from the payload keep their current value; a
+		// composition, when sent, replaces the old one rather than merging
+		settings := current
+		settings.Composition = nil
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		if err := json.Unmarshal(payloadBytes, &settings); err != nil {
+			sendError(client, "invalid settings")
+			return
+		}
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(payloadBytes, &fields) == nil {
+			if _, sent := fields["composition"]; !sent {
+				settings.Composition = current.Composition
 			}
+		}
 
-			broadcastToRoom(client.RoomCode, models.EventPhaseChanged, payload)
-		} else {
-			// Broadcast role change
-			broadcastToRoom(client.RoomCode, models.EventNightRoleChange, room)
+		if err := gm.UpdateSettings(client.RoomCode, client.ID, settings); err != nil {
+			sendGameError(client, err)
+			return
 		}
 
-	case models.EventChatMessage:
-		broadcastToRoom(client.RoomCode, models.EventChatMessage, msg.Payload)
+		broadcastRoomState(gm, client.RoomCode, models.EventSettingsChanged, func(view *models.GameRoom) interface{} {
+			return gin.H{"settings": view.Settings}
+		})
+		refreshAutoStart(gm, client.RoomCode)
 
-	case models.EventVote:
-		// Parse vote payload
-		var voteData map[string]string
+	case models.EventReadyToVote:
+		status, nightResult, err := gm.ToggleReadyToVote(client.RoomCode, client.ID)
+		if err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		// Everyone sees the count; only the sender learns their own state
+		public := status
+		public.Ready = false
+		data, err := encodeMessage(models.EventReadyUpdate, public)
+		if err != nil {
+			log.Printf("JSON marshal error: %v", err)
+			return
+		}
+		personal, err := encodeMessage(models.EventReadyUpdate, status)
+		if err != nil {
+			log.Printf("JSON marshal error: %v", err)
+			return
+		}
+		routes.Broadcast(&ws.BroadcastMessage{
+			RoomCode:  client.RoomCode,
+			Message:   data,
+			PerClient: map[string][]byte{client.ID: personal},
+		})
+
+		switch {
+		case status.DayEnded:
+			broadcastPhaseChange(gm, client.RoomCode, nightResult, "The village is ready to vote")
+		case status.EndsAt != nil:
+			broadcastPhaseUpdate(gm, client.RoomCode)
+		}
+
+	case models.EventGrantModerator, models.EventRevokeModerator:
+		grant := msg.Type == models.EventGrantModerator
+		if err := gm.SetModerator(client.RoomCode, client.ID, payloadTarget(msg), grant); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastPlayersUpdate(gm, client.RoomCode)
+
+	case models.EventKickPlayer:
+		targetID := payloadTarget(msg)
+		if err := gm.KickPlayer(client.RoomCode, client.ID, targetID); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		hub.SetPlayerCloseReason(targetID, models.DisconnectKicked)
+		sendToPlayers(client.RoomCode, []string{targetID}, models.EventKicked, gin.H{"by": client.ID})
+		broadcastPlayersUpdate(gm, client.RoomCode)
+		warnComposition(gm, client.RoomCode)
+		refreshAutoStart(gm, client.RoomCode)
+
+	case models.EventRematch:
+		roster, err := gm.Rematch(client.RoomCode, client.ID)
+		if err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		for _, playerID := range roster.Dropped {
+			broadcastToRoom(client.RoomCode, models.EventPlayerLeft, gin.H{"playerId": playerID})
+		}
+		broadcastToRoom(client.RoomCode, models.EventRematchRoster, roster)
+		broadcastPhaseChange(gm, client.RoomCode, nil, "")
+		warnComposition(gm, client.RoomCode)
+
+	case models.EventAcceptSuggestedComposition:
+		if _, err := gm.AcceptSuggestedComposition(client.RoomCode, client.ID); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastRoomState(gm, client.RoomCode, models.EventSettingsChanged, func(view *models.GameRoom) interface{} {
+			return gin.H{"settings": view.Settings}
+		})
+		refreshAutoStart(gm, client.RoomCode)
+
+	case models.EventAbortGame:
+		vote, outcome, err := gm.RequestAbort(client.RoomCode, client.ID)
+		if err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		sendToPlayers(client.RoomCode, vote.Eligible, models.EventAbortRequested, vote)
+		announceAbort(gm, client.RoomCode, outcome)
+
+	case models.EventAbortVote:
+		var voteData struct {
+			Approve bool `json:"approve"`
+		}
 		payloadBytes, _ := json.Marshal(msg.Payload)
 		json.Unmarshal(payloadBytes, &voteData)
 
-		targetID := voteData["targetId"]
+		outcome, err := gm.VoteAbort(client.RoomCode, client.ID, voteData.Approve)
+		if err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		announceAbort(gm, client.RoomCode, outcome)
+
+	case models.EventAbortTimeout:
+		outcome, err := gm.TimeoutAbort(client.RoomCode)
+		if err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		announceAbort(gm, client.RoomCode, outcome)
+
+	case models.EventRespondJoinRequest:
+		var respondData struct {
+			RequestID string `json:"requestId"`
+			Approve   bool   `json:"approve"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &respondData)
+
+		request, err := gm.RespondJoinRequest(client.RoomCode, client.ID, respondData.RequestID, respondData.Approve)
+		if request.ID != "" {
+			sendToClient(client, models.EventJoinRequestResolved, request)
+		}
+		if request.Status == models.JoinDenied {
+			memberships.forget(request.PlayerID)
+		}
+		if err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		if request.Status == models.JoinApproved {
+			broadcastPlayersUpdate(gm, client.RoomCode)
+			warnOutgrownComposition(gm, client.RoomCode)
+			refreshAutoStart(gm, client.RoomCode)
+		}
+
+	case models.EventQuorumTimeout:
+		if err := gm.ForfeitLostQuorum(client.RoomCode); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastPhaseChange(gm, client.RoomCode, nil, "Too few players came back")
+
+	case models.EventMutePlayer, models.EventUnmutePlayer:
+		muted := msg.Type == models.EventMutePlayer
+		if err := gm.SetMuted(client.RoomCode, client.ID, payloadTarget(msg), muted); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastPlayersUpdate(gm, client.RoomCode)
+
+	case models.EventExtendDiscussion:
+		var extendData struct {
+			Seconds int `json:"seconds"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &extendData)
+
+		if err := gm.ExtendDiscussion(client.RoomCode, client.ID, time.Duration(extendData.Seconds)*time.Second); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastPhaseUpdate(gm, client.RoomCode)
+
+	case models.EventSetSlowMode:
+		var slowData struct {
+			Seconds int `json:"seconds"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &slowData)
+
+		if err := gm.SetSlowMode(client.RoomCode, client.ID, slowData.Seconds); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastToRoom(client.RoomCode, models.EventSlowModeChanged, gin.H{"seconds": gm.SlowMode(client.RoomCode)})
+
+	case models.EventSettingsAck:
+		if err := gm.AckSettings(client.RoomCode, client.ID); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+	case models.EventChooseColor:
+		var colorData struct {
+			Slot *int `json:"slot"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &colorData)
+
+		if colorData.Slot == nil {
+			sendError(client, "invalid color slot")
+			return
+		}
+
+		if err := gm.ChooseColor(client.RoomCode, client.ID, *colorData.Slot); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastPlayersUpdate(gm, client.RoomCode)
+
+	case models.EventNominate:
+		targetID, ok := actionTarget(gm, client, msg)
+		if !ok {
+			return
+		}
+		if targetID == "" {
+			sendError(client, "invalid nominee")
+			return
+		}
+
+		if err := gm.Nominate(client.RoomCode, client.ID, targetID); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		broadcastVotesUpdate(gm, client.RoomCode)
+
+	case models.EventVote:
+		targetID, ok := actionTarget(gm, client, msg)
+		if !ok {
+			return
+		}
 		if targetID == "" {
 			sendError(client, "invalid vote target")
 			return
@@ -254,247 +646,564 @@ func handleWebSocketMessage(client *Client, gm *game.GameManager, msg *models.WS
 
 		// Record vote
 		if err := gm.Vote(client.RoomCode, client.ID, targetID); err != nil {
-			sendError(client, err.Error())
+			sendGameError(client, err)
 			return
 		}
 
-		// Broadcast updated room state with vote info
-		room, _ := gm.GetRoom(client.RoomCode)
-		broadcastToRoom(client.RoomCode, models.EventVoteUpdate, room)
+		// Broadcast updated tallies
+		broadcastVotesUpdate(gm, client.RoomCode)
 
 		// Check if all players have voted
 		if gm.CheckAllVoted(client.RoomCode) {
-			// Broadcast voting complete - start 5 second countdown
-			broadcastToRoom(client.RoomCode, models.EventVotingComplete, room)
+			// Signal voting complete - start 5 second countdown
+			broadcastToRoom(client.RoomCode, models.EventVotingComplete, nil)
 		}
 
 	case models.EventVoteResult:
-		// Process votes after countdown
-		nightResult, err := gm.MoveToNextPhase(client.RoomCode)
+		// Process votes after countdown, if the vote is really over
+		nightResult, err := gm.CloseVote(client.RoomCode)
 		if err != nil {
-			sendError(client, err.Error())
+			sendGameError(client, err)
 			return
 		}
 
-		room, _ := gm.GetRoom(client.RoomCode)
+		broadcastPhaseChange(gm, client.RoomCode, nightResult, "")
 
-		// Include night result if transitioning from night to day
-		payload := map[string]interface{}{
-			"room": room,
+	case models.EventHunterShoot:
+		targetID, ok := actionTarget(gm, client, msg)
+		if !ok {
+			return
 		}
-		if nightResult != nil {
-			payload["nightResult"] = nightResult
+		if targetID == "" {
+			sendError(client, "invalid shoot target")
+			return
 		}
 
-		broadcastToRoom(client.RoomCode, models.EventPhaseChanged, payload)
-
-	case models.EventHunterShoot:
-		// Parse shoot payload
-		var shootData map[string]string
+		var shotData struct {
+			Confirm bool `json:"confirm"`
+		}
 		payloadBytes, _ := json.Marshal(msg.Payload)
-		json.Unmarshal(payloadBytes, &shootData)
+		json.Unmarshal(payloadBytes, &shotData)
 
-		targetID := shootData["targetId"]
-		if targetID == "" {
-			sendError(client, "invalid shoot target")
+		// Execute hunter shoot
+		record, err := gm.HunterShoot(client.RoomCode, client.ID, targetID, shotData.Confirm)
+		if err != nil {
+			sendGameError(client, err)
 			return
 		}
 
-		// Execute hunter shoot
-		if err := gm.HunterShoot(client.RoomCode, client.ID, targetID); err != nil {
-			sendError(client, err.Error())
+		// A selection waiting for confirmation only goes back to the hunter
+		sendToClient(client, models.EventActionAck, record)
+		if record.Outcome == "" {
 			return
 		}
 
-		room, _ := gm.GetRoom(client.RoomCode)
+		afterHunterShot(gm, client.RoomCode, models.CueGunshot)
 
-		// Check game end after hunter shoot
-		isEnded, winner := gm.CheckGameEnd(client.RoomCode)
-		if isEnded {
-			room.Phase = models.PhaseEnded
-			room.WinningTeam = winner
-			broadcastToRoom(client.RoomCode, models.EventGameEnded, room)
+	case models.EventHunterTimeout:
+		if err := gm.TimeoutHunterShot(client.RoomCode); err != nil {
+			sendGameError(client, err)
 			return
 		}
 
-		// Continue to next phase
-		broadcastToRoom(client.RoomCode, models.EventGameStateUpdate, room)
+		afterHunterShot(gm, client.RoomCode, "")
 
 	case models.EventCurseAction:
-		// Parse curse payload
-		var curseData map[string]string
-		payloadBytes, _ := json.Marshal(msg.Payload)
-		json.Unmarshal(payloadBytes, &curseData)
-
-		targetID := curseData["targetId"]
+		targetID, ok := actionTarget(gm, client, msg)
+		if !ok {
+			return
+		}
 		if targetID == "" {
 			sendError(client, "invalid curse target")
 			return
 		}
 
-		room, _ := gm.GetRoom(client.RoomCode)
-		player := room.Players[client.ID]
-
-		// Validate it's alpha tiger
-		if player.Role != models.RoleAlphaTiger {
-			sendError(client, "only alpha tiger can curse")
+		if err := gm.SetAlphaTigerCurse(client.RoomCode, client.ID, targetID, payloadString(msg, "turnToken")); err != nil {
+			sendGameError(client, err)
 			return
 		}
 
-		if player.HasUsedCurse {
-			sendError(client, "curse already used")
-			return
-		}
+		finishNightTurn(client, gm)
 
-		// Apply curse
-		target := room.Players[targetID]
-		if target != nil && target.IsAlive {
-			target.IsCursed = true
-			player.HasUsedCurse = true
-			room.CursedPlayer = targetID
+	case models.EventTurnTimeout:
+		nightDone, err := gm.TimeoutNightTurn(client.RoomCode, payloadString(msg, "turnToken"))
+		if err != nil {
+			sendGameError(client, err)
+			return
 		}
 
-		// Mark night action complete
-		gm.MarkNightActionComplete(client.RoomCode, client.ID)
+		broadcastTigerTeamUpdate(gm, client.RoomCode)
+		advanceNight(client, gm, nightDone)
 
-		// Move to next role
-		allDone, err := gm.MoveToNextNightRole(client.RoomCode)
+	case models.EventNightSleep:
+		nightDone, err := gm.Sleep(client.RoomCode, client.ID)
 		if err != nil {
-			sendError(client, err.Error())
+			sendGameError(client, err)
 			return
 		}
 
-		room, _ = gm.GetRoom(client.RoomCode)
-
-		if allDone {
-			// All roles have acted, move to next phase
+		// The last player to fall asleep ends the night
+		if nightDone {
 			nightResult, err := gm.MoveToNextPhase(client.RoomCode)
 			if err != nil {
-				sendError(client, err.Error())
+				sendGameError(client, err)
 				return
 			}
 
-			room, _ = gm.GetRoom(client.RoomCode)
-			payload := map[string]interface{}{
-				"message": "All night actions completed",
-				"room":    room,
-			}
-			if nightResult != nil {
-				payload["nightResult"] = nightResult
-			}
+			broadcastPhaseChange(gm, client.RoomCode, nightResult, "All night actions completed")
+		}
 
-			// Check game end after night phase
-			isEnded, winner := gm.CheckGameEnd(client.RoomCode)
-			if isEnded {
-				room.Phase = models.PhaseEnded
-				room.WinningTeam = winner
-				broadcastToRoom(client.RoomCode, models.EventGameEnded, room)
+	case models.EventNightAction:
+		var passData struct {
+			Pass bool `json:"pass"`
+		}
+		payloadBytes, _ := json.Marshal(msg.Payload)
+		json.Unmarshal(payloadBytes, &passData)
+
+		// Passing is a choice to do nothing, told apart from a skip
+		if passData.Pass {
+			record, err := gm.PassNightAction(client.RoomCode, client.ID, payloadString(msg, "turnToken"))
+			if err != nil {
+				sendGameError(client, err)
 				return
 			}
 
-			broadcastToRoom(client.RoomCode, models.EventPhaseChanged, payload)
-		} else {
-			// Broadcast role change
-			broadcastToRoom(client.RoomCode, models.EventNightRoleChange, room)
+			sendToClient(client, models.EventActionAck, record)
+			finishNightTurn(client, gm)
+			return
 		}
 
-	case models.EventNightAction:
-		// Parse night action payload
-		var actionData map[string]string
-		payloadBytes, _ := json.Marshal(msg.Payload)
-		json.Unmarshal(payloadBytes, &actionData)
-
-		targetID := actionData["targetId"]
+		targetID, ok := actionTarget(gm, client, msg)
+		if !ok {
+			return
+		}
 		if targetID == "" {
 			sendError(client, "invalid action target")
 			return
 		}
 
-		room, _ := gm.GetRoom(client.RoomCode)
-		player := room.Players[client.ID]
+		if err := gm.PerformNightAction(client.RoomCode, client.ID, targetID, payloadString(msg, "turnToken")); err != nil {
+			sendGameError(client, err)
+			return
+		}
+
+		finishNightTurn(client, gm)
+	}
+}
+
+// payloadTarget extracts the targetId field from an action payload
+func payloadTarget(msg *models.WSMessage) string {
+	return payloadString(msg, "targetId")
+}
+
+// actionTarget resolves an action's target from its targetId or, when that
+// is missing, its seat number. It reports an invalid seat to the client itself.
+func actionTarget(gm *game.GameManager, client *ws.Client, msg *models.WSMessage) (string, bool) {
+	if targetID := payloadTarget(msg); targetID != "" {
+		return targetID, true
+	}
+
+	var seatData struct {
+		Seat int `json:"seat"`
+	}
+	payloadBytes, _ := json.Marshal(msg.Payload)
+	json.Unmarshal(payloadBytes, &seatData)
+	if seatData.Seat == 0 {
+		return "", true
+	}
+
+	targetID, err := gm.SeatPlayer(client.RoomCode, seatData.Seat)
+	if err != nil {
+		sendGameError(client, err)
+		return "", false
+	}
+	return targetID, true
+}
+
+// payloadString extracts a string field from a message payload
+func payloadString(msg *models.WSMessage, key string) string {
+	var data map[string]interface{}
+	payloadBytes, _ := json.Marshal(msg.Payload)
+	json.Unmarshal(payloadBytes, &data)
+	value, _ := data[key].(string)
+	return value
+}
+
+// finishNightTurn advances the night after the current player acted or skipped
+func finishNightTurn(client *ws.Client, gm *game.GameManager) {
+	broadcastTigerTeamUpdate(gm, client.RoomCode)
+
+	allDone, err := gm.MoveToNextNightRole(client.RoomCode)
+	if err != nil {
+		sendGameError(client, err)
+		return
+	}
+
+	advanceNight(client, gm, allDone)
+}
+
+// advanceNight announces the next night turn, or ends the night once nothing is left
+func advanceNight(client *ws.Client, gm *game.GameManager, allDone bool) {
+	if !allDone {
+		// Broadcast role change, unless its timing would tell the room a
+		// turn just ended; the next role learns of it from its prompt
+		if view, ok := gm.RoomView(client.RoomCode, ""); ok && !view.Settings.NightSleepConfirmation {
+			broadcastPhaseUpdate(gm, client.RoomCode)
+		}
+		sendTurnPrompt(gm, client.RoomCode)
+		return
+	}
+
+	// All roles have acted or skipped, move to next phase
+	nightResult, err := gm.MoveToNextPhase(client.RoomCode)
+	if err != nil {
+		sendGameError(client, err)
+		return
+	}
+
+	broadcastPhaseChange(gm, client.RoomCode, nightResult, "All night actions completed")
+}
+
+// AnnounceForcedNight tells a room the night watch resolved its night
+func AnnounceForcedNight(gm *game.GameManager) func(game.ForcedNight) {
+	return func(forced game.ForcedNight) {
+		broadcastTigerTeamUpdate(gm, forced.RoomCode)
+		broadcastPhaseChange(gm, forced.RoomCode, forced.Result, "The night ran out of time")
+	}
+}
+
+// AnnounceWithdrawal tells a room who fled or had their night step skipped
+// for being away too long, and whatever that changed
+func AnnounceWithdrawal(gm *game.GameManager) func(game.Withdrawal) {
+	return func(withdrawal game.Withdrawal) {
+		message := ""
+		if len(withdrawal.Withdrawn) > 0 {
+			message = "A player fled into the forest"
+		}
+
+		broadcastSystemNotices(gm, withdrawal.RoomCode)
+		broadcastTigerTeamUpdate(gm, withdrawal.RoomCode)
+		broadcastPhaseChange(gm, withdrawal.RoomCode, withdrawal.NightResult, message)
+	}
+}
+
+// announceAbort shows the abort vote's progress, or its result once decided
+func announceAbort(gm *game.GameManager, roomCode models.RoomCode, outcome string) {
+	if outcome != game.AbortPending {
+		broadcastToRoom(roomCode, models.EventAbortResult, gin.H{"outcome": outcome})
+	}
+
+	if outcome == game.AbortApproved {
+		broadcastPhaseChange(gm, roomCode, nil, "The game was aborted")
+		return
+	}
+	broadcastPhaseUpdate(gm, roomCode)
+}
+
+// phaseChange is a phase_update that also explains the transition
+type phaseChange struct {
+	models.PhaseUpdate
+	Message     string              `json:"message,omitempty"`
+	NightResult *game.NightResult   `json:"nightResult,omitempty"`
+	VoteResult  *models.VoteOutcome `json:"voteResult,omitempty"`
+}
+
+// broadcastPhaseChange announces a phase transition and the roster and tallies
+// it changed, followed by game_ended if the transition decided the game
+func broadcastPhaseChange(gm *game.GameManager, roomCode models.RoomCode, nightResult *game.NightResult, message string) {
+	// Only a vote resolution leaves an outcome here
+	voteResult := gm.TakeVoteOutcome(roomCode)
+	publicResult := nightResult.Public()
+
+	// A body still concealed when the day ended is announced before what comes next
+	announceRevealedDeaths(gm, roomCode)
+
+	phase, _ := gm.RoomPhase(roomCode)
+	revealedDeath := (nightResult != nil && len(nightResult.Deaths) > 0 && !nightResult.Concealed) || (voteResult != nil && voteResult.EliminatedID != "")
+	broadcastCuedState(gm, roomCode, models.EventPhaseUpdate, models.PhaseCue(phase, revealedDeath), func(view *models.GameRoom) interface{} {
+		// Include night result if transitioning from night to day
+		return phaseChange{
+			PhaseUpdate: models.NewPhaseUpdate(view),
+			Message:     message,
+			NightResult: publicResult,
+			VoteResult:  voteResult,
+		}
+	})
+	broadcastPlayersUpdate(gm, roomCode)
+	broadcastVotesUpdate(gm, roomCode)
+	sendTurnPrompt(gm, roomCode)
+	sendHunterPrompt(gm, roomCode)
+	if nightResult != nil && nightResult.Concealed {
+		scheduleBodyReveal(gm, roomCode)
+	}
 
-		// Validate it's this player's turn
-		if room.CurrentNightRole != player.Role {
-			sendError(client, "not your turn")
+	// Only a vote resolution leaves anything here
+	for playerID, voters := range gm.TakeVotesAgainst(roomCode) {
+		sendToPlayers(roomCode, []string{playerID}, models.EventVotesAgainstYou, gin.H{"voters": voters})
+	}
+
+	// Each special role gets its own dawn recap alongside the public result
+	if nightResult != nil {
+		for playerID, recap := range nightResult.Recaps {
+			sendToPlayers(roomCode, []string{playerID}, models.EventNightRecap, recap)
+		}
+	}
+
+	if phase, _ := gm.RoomPhase(roomCode); phase == models.PhaseEnded {
+		broadcastRoomState(gm, roomCode, models.EventGameEnded, nil)
+	}
+
+	if pause, paused := gm.QuorumPause(roomCode); paused {
+		broadcastToRoom(roomCode, models.EventQuorumLost, pause)
+	}
+}
+
+// Scoped broadcasts only ever ship their own slice of each viewer's room
+
+func broadcastPlayersUpdate(gm *game.GameManager, roomCode models.RoomCode) {
+	broadcastRoomState(gm, roomCode, models.EventPlayersUpdate, func(view *models.GameRoom) interface{} {
+		return models.NewPlayersUpdate(view)
+	})
+}
+
+// sendRoleAssignments privately tells each player their role and its rules
+func sendRoleAssignments(gm *game.GameManager, roomCode models.RoomCode) {
+	perClient := make(map[string][]byte)
+	for playerID, assignment := range gm.RoleAssignments(roomCode) {
+		data, err := encodeMessage(models.EventRoleAssigned, assignment)
+		if err != nil {
+			log.Printf("JSON marshal error: %v", err)
 			return
 		}
+		perClient[playerID] = data
+	}
 
-		// Record the action based on role
-		switch player.Role {
-		case models.RoleShaman:
-			room.ShamanVision = targetID
-		case models.RoleHunter:
-			// ห้ามกันคนเดิม 2 คืนซ้อน
-			if player.LastProtected == targetID {
-				sendError(client, "cannot protect same player twice in a row")
-				return
-			}
-			room.HunterProtection = targetID
-			player.LastProtected = targetID
-		case models.RoleTiger:
-			room.TigerTarget = targetID
-		case models.RoleAlphaTiger:
-			// Alpha tiger can choose to kill or curse
-			// For now, just set as target
-			room.TigerTarget = targetID
+	routes.Broadcast(&ws.BroadcastMessage{
+		RoomCode:  roomCode,
+		PerClient: perClient,
+	})
+}
+
+// warnComposition tells the host when the lobby no longer fits the roles they chose
+func warnComposition(gm *game.GameManager, roomCode models.RoomCode) {
+	if warning := gm.CheckComposition(roomCode); warning != nil {
+		sendToPlayers(roomCode, []string{warning.HostID}, warning.Event, warning)
+	}
+}
+
+// warnOutgrownComposition tells the host when a join takes the lobby past the
+// roles they chose, again on every further join. A lobby still filling up to
+// its composition is not warned about.
+func warnOutgrownComposition(gm *game.GameManager, roomCode models.RoomCode) {
+	if warning := gm.CheckComposition(roomCode); warning != nil && warning.Event == models.EventCompositionOutdated {
+		sendToPlayers(roomCode, []string{warning.HostID}, warning.Event, warning)
+	}
+}
+
+func broadcastPhaseUpdate(gm *game.GameManager, roomCode models.RoomCode) {
+	broadcastRoomState(gm, roomCode, models.EventPhaseUpdate, func(view *models.GameRoom) interface{} {
+		return models.NewPhaseUpdate(view)
+	})
+}
+
+func broadcastVotesUpdate(gm *game.GameManager, roomCode models.RoomCode) {
+	broadcastRoomState(gm, roomCode, models.EventVotesUpdate, func(view *models.GameRoom) interface{} {
+		return models.NewVotesUpdate(view, time.Now())
+	})
+}
+
+// sendTurnPrompt privately gives the players whose night turn it is its
+// token and their own list of targets
+func sendTurnPrompt(gm *game.GameManager, roomCode models.RoomCode) {
+	prompts, exists := gm.CurrentTurnPrompts(roomCode)
+	if !exists {
+		return
+	}
+	for playerID, prompt := range prompts {
+		cue := models.CueYourTurn
+		if prompt.Role == models.RoleTiger || prompt.Role == models.RoleAlphaTiger {
+			cue = models.CueTigerHowl
 		}
+		sendCuedToPlayers(roomCode, []string{playerID}, models.EventYourTurn, cue, prompt)
+	}
+}
+
+// afterHunterShot tells the room the game goes on, or that the shot ended it.
+// cue goes with the roster that shows the shot's victim.
+func afterHunterShot(gm *game.GameManager, roomCode models.RoomCode, cue string) {
+	// HunterShoot ends the game itself if the shot decided it
+	if phase, _ := gm.RoomPhase(roomCode); phase == models.PhaseEnded {
+		broadcastRoomState(gm, roomCode, models.EventGameEnded, nil)
+		return
+	}
+
+	// Continue to next phase
+	broadcastCuedState(gm, roomCode, models.EventPlayersUpdate, cue, func(view *models.GameRoom) interface{} {
+		return models.NewPlayersUpdate(view)
+	})
+	broadcastPhaseUpdate(gm, roomCode)
+}
 
-		// Mark that this player has acted
-		gm.MarkNightActionComplete(client.RoomCode, client.ID)
+// sendHunterPrompt privately asks a dead hunter to take their shot
+func sendHunterPrompt(gm *game.GameManager, roomCode models.RoomCode) {
+	prompt, hunterID, exists := gm.HunterPromptFor(roomCode)
+	if !exists {
+		return
+	}
+	sendToPlayers(roomCode, []string{hunterID}, models.EventHunterPrompt, prompt)
+}
 
-		// Move to next role
-		allDone, err := gm.MoveToNextNightRole(client.RoomCode)
+// broadcastTigerTeamUpdate shows the tiger team's night step to its members only
+func broadcastTigerTeamUpdate(gm *game.GameManager, roomCode models.RoomCode) {
+	team, memberIDs, exists := gm.TigerTeamView(roomCode)
+	if !exists {
+		return
+	}
+	sendToPlayers(roomCode, memberIDs, models.EventTigerTeamUpdate, team)
+}
+
+// sendToPlayers sends the same payload to only the listed players in the room
+func sendToPlayers(roomCode models.RoomCode, playerIDs []string, eventType string, payload interface{}) {
+	sendCuedToPlayers(roomCode, playerIDs, eventType, models.EventCues[eventType], payload)
+}
+
+// sendCuedToPlayers is sendToPlayers with the cue the sender picked
+func sendCuedToPlayers(roomCode models.RoomCode, playerIDs []string, eventType, cue string, payload interface{}) {
+	if len(playerIDs) == 0 {
+		return
+	}
+
+	data, err := encodeCued(eventType, cue, payload)
+	if err != nil {
+		log.Printf("JSON marshal error: %v", err)
+		return
+	}
+
+	perClient := make(map[string][]byte, len(playerIDs))
+	for _, playerID := range playerIDs {
+		perClient[playerID] = data
+	}
+
+	routes.Broadcast(&ws.BroadcastMessage{
+		RoomCode:  roomCode,
+		PerClient: perClient,
+	})
+}
+
+// broadcastRoomState sends every client in the room a payload built from its
+// own view of the room. A nil build sends the view itself, as its wire type.
+func broadcastRoomState(gm *game.GameManager, roomCode models.RoomCode, eventType string, build func(view *models.GameRoom) interface{}) {
+	broadcastCuedState(gm, roomCode, eventType, models.EventCues[eventType], build)
+}
+
+// broadcastCuedState is broadcastRoomState with the cue the sender picked
+func broadcastCuedState(gm *game.GameManager, roomCode models.RoomCode, eventType, cue string, build func(view *models.GameRoom) interface{}) {
+	public, views, exists := gm.RoomViews(roomCode)
+	if !exists {
+		return
+	}
+
+	if build == nil {
+		build = models.NewRoomWire
+	}
+
+	data, err := encodeCued(eventType, cue, build(public))
+	if err != nil {
+		log.Printf("JSON marshal error: %v", err)
+		return
+	}
+
+	perClient := make(map[string][]byte, len(views))
+	for playerID, view := range views {
+		viewData, err := encodeCued(eventType, cue, build(view))
 		if err != nil {
-			sendError(client, err.Error())
+			log.Printf("JSON marshal error: %v", err)
 			return
 		}
+		perClient[playerID] = viewData
+	}
 
-		room, _ = gm.GetRoom(client.RoomCode)
+	message := &ws.BroadcastMessage{
+		RoomCode:  roomCode,
+		Message:   data,
+		PerClient: perClient,
+	}
 
-		if allDone {
-			// All roles have acted, move to next phase
-			nightResult, err := gm.MoveToNextPhase(client.RoomCode)
+	// Old clients get the same update in its v1 shape
+	if _, _, translated := legacyFrame(eventType, public, nil); translated && hub.HasSnapshotClients(roomCode) {
+		message.Legacy = &ws.BroadcastMessage{
+			RoomCode:  roomCode,
+			PerClient: make(map[string][]byte, len(views)),
+		}
+		message.Legacy.Message, err = encodeLegacy(eventType, cue, public, build(public))
+		if err != nil {
+			log.Printf("JSON marshal error: %v", err)
+			return
+		}
+		for playerID, view := range views {
+			viewData, err := encodeLegacy(eventType, cue, view, build(view))
 			if err != nil {
-				sendError(client, err.Error())
+				log.Printf("JSON marshal error: %v", err)
 				return
 			}
-
-			room, _ = gm.GetRoom(client.RoomCode)
-			payload := map[string]interface{}{
-				"message": "All night actions completed",
-				"room":    room,
-			}
-			if nightResult != nil {
-				payload["nightResult"] = nightResult
-			}
-
-			broadcastToRoom(client.RoomCode, models.EventPhaseChanged, payload)
-		} else {
-			// Broadcast role change
-			broadcastToRoom(client.RoomCode, models.EventNightRoleChange, room)
+			message.Legacy.PerClient[playerID] = viewData
 		}
 	}
+
+	routes.Broadcast(message)
 }
 
-func broadcastToRoom(roomCode, eventType string, payload interface{}) {
-	msg := models.WSMessage{
+// encodeLegacy encodes an event in its ProtocolV1 shape
+func encodeLegacy(eventType, cue string, view *models.GameRoom, payload interface{}) ([]byte, error) {
+	legacyType, legacyPayload, _ := legacyFrame(eventType, view, payload)
+	return encodeCued(legacyType, cue, legacyPayload)
+}
+
+// encodeMessage encodes an event with the cue it always carries, if any
+func encodeMessage(eventType string, payload interface{}) ([]byte, error) {
+	return encodeCued(eventType, models.EventCues[eventType], payload)
+}
+
+// encodeCued encodes an event with the cue its sender picked
+func encodeCued(eventType, cue string, payload interface{}) ([]byte, error) {
+	return json.Marshal(models.WSMessage{
 		Type:    eventType,
 		Payload: payload,
+		Cue:     cue,
+	})
+}
+
+// broadcastChat sends a chat event to the players allowed to read the message
+func broadcastChat(gm *game.GameManager, roomCode models.RoomCode, eventType string, message *models.Message) {
+	if audience := gm.ChatAudience(roomCode, message); audience != nil {
+		sendToPlayers(roomCode, audience, eventType, message)
+		return
 	}
 
-	data, err := json.Marshal(msg)
+	broadcastToRoom(roomCode, eventType, message)
+}
+
+// broadcastSystemNotices sends the room's queued system chat lines
+func broadcastSystemNotices(gm *game.GameManager, roomCode models.RoomCode) {
+	for _, notice := range gm.TakeSystemNotices(roomCode) {
+		broadcastToRoom(roomCode, models.EventChatMessage, notice)
+	}
+}
+
+func broadcastToRoom(roomCode models.RoomCode, eventType string, payload interface{}) {
+	data, err := encodeMessage(eventType, payload)
 	if err != nil {
 		log.Printf("JSON marshal error: %v", err)
 		return
 	}
 
-	hub.Broadcast <- &BroadcastMessage{
+	routes.Broadcast(&ws.BroadcastMessage{
 		RoomCode: roomCode,
 		Message:  data,
-	}
+	})
 }
 
-func sendError(client *Client, errMsg string) {
+func sendError(client *ws.Client, errMsg string) {
 	msg := models.WSMessage{
 		Type:    models.EventError,
 		Payload: map[string]string{"error": errMsg},
@@ -506,20 +1215,33 @@ func sendError(client *Client, errMsg string) {
 		return
 	}
 
-	client.Send <- data
+	sendFrame(client, data)
 }
 
-func sendToClient(client *Client, eventType string, payload interface{}) {
-	msg := models.WSMessage{
-		Type:    eventType,
-		Payload: payload,
+// sendGameError acknowledges duplicate submissions and reports any other
+// error, including its code when it has one
+func sendGameError(client *ws.Client, err error) {
+	var dup *game.DuplicateActionError
+	if errors.As(err, &dup) {
+		sendToClient(client, models.EventActionAck, dup.Record)
+		return
 	}
 
-	data, err := json.Marshal(msg)
+	sendToClient(client, models.EventError, errorBody(err))
+}
+
+func sendToClient(client *ws.Client, eventType string, payload interface{}) {
+	data, err := encodeMessage(eventType, payload)
 	if err != nil {
 		log.Printf("JSON marshal error: %v", err)
 		return
 	}
 
-	client.Send <- data
+	sendFrame(client, data)
+}
+
+// sendFrame queues a frame for one client, or keeps it for the response
+// when the client is a REST request
+func sendFrame(client *ws.Client, data []byte) {
+	routes.SendTo(client, data)
 }
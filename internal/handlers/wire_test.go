@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// internalModels are the server's own records, which clients are only ever
+// sent as wire structs
+var internalModels = map[string]bool{"GameRoom": true, "Player": true}
+
+// holdsInternalModel names the internal model a value of type typ is, or
+// holds directly in a pointer, slice, array or map, or "" when it holds none
+func holdsInternalModel(typ types.Type) string {
+	switch typ := typ.(type) {
+	case *types.Named:
+		obj := typ.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "github.com/werewolf-game/backend/internal/models" && internalModels[obj.Name()] {
+			return "models." + obj.Name()
+		}
+	case *types.Pointer:
+		return holdsInternalModel(typ.Elem())
+	case *types.Slice:
+		return holdsInternalModel(typ.Elem())
+	case *types.Array:
+		return holdsInternalModel(typ.Elem())
+	case *types.Map:
+		if name := holdsInternalModel(typ.Key()); name != "" {
+			return name
+		}
+		return holdsInternalModel(typ.Elem())
+	}
+	return ""
+}
+
+// TestHandlersNeverSendInternalModels type-checks the package and fails on
+// any GameRoom or Player handed to an interface, the way every path to
+// json.Marshal, c.JSON or a websocket payload takes one
+func TestHandlersNeverSendInternalModels(t *testing.T) {
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatalf("list the package: %v", err)
+	}
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", path, err)
+		}
+		files = append(files, file)
+	}
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+	}
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := config.Check("github.com/werewolf-game/backend/internal/handlers", fset, files, info); err != nil {
+		t.Fatalf("type-check the package: %v", err)
+	}
+
+	// check fails when value, an internal model, is put where target, an
+	// interface, will carry it on
+	check := func(value ast.Expr, target types.Type) {
+		if target == nil || !types.IsInterface(target) {
+			return
+		}
+		if name := holdsInternalModel(info.TypeOf(value)); name != "" {
+			t.Errorf("%s: a %s is passed as %s; convert it to a wire struct", fset.Position(value.Pos()), name, target)
+		}
+	}
+
+	for _, file := range files {
+		var results []*types.Tuple // of the functions being walked, innermost last
+		var walk func(node ast.Node) bool
+		walk = func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				if node.Body != nil {
+					results = append(results, info.Defs[node.Name].Type().(*types.Signature).Results())
+					ast.Inspect(node.Body, walk)
+					results = results[:len(results)-1]
+				}
+				return false
+
+			case *ast.FuncLit:
+				results = append(results, info.TypeOf(node).(*types.Signature).Results())
+				ast.Inspect(node.Body, walk)
+				results = results[:len(results)-1]
+				return false
+
+			case *ast.ReturnStmt:
+				if len(results) > 0 && results[len(results)-1].Len() == len(node.Results) {
+					for i, value := range node.Results {
+						check(value, results[len(results)-1].At(i).Type())
+					}
+				}
+
+			case *ast.CallExpr:
+				fun := info.Types[node.Fun]
+				signature, ok := fun.Type.(*types.Signature)
+				if fun.IsType() || !ok {
+					break
+				}
+				params := signature.Params()
+				for i, arg := range node.Args {
+					switch {
+					case signature.Variadic() && i >= params.Len()-1:
+						last := params.At(params.Len() - 1).Type()
+						if node.Ellipsis.IsValid() {
+							check(arg, last)
+						} else {
+							check(arg, last.(*types.Slice).Elem())
+						}
+					case i < params.Len():
+						check(arg, params.At(i).Type())
+					}
+				}
+
+			case *ast.CompositeLit:
+				switch typ := info.TypeOf(node).Underlying().(type) {
+				case *types.Map:
+					for _, element := range node.Elts {
+						if pair, ok := element.(*ast.KeyValueExpr); ok {
+							check(pair.Value, typ.Elem())
+						}
+					}
+				case *types.Slice:
+					for _, element := range node.Elts {
+						check(element, typ.Elem())
+					}
+				case *types.Struct:
+					for i, element := range node.Elts {
+						if pair, ok := element.(*ast.KeyValueExpr); ok {
+							for j := 0; j < typ.NumFields(); j++ {
+								if field := typ.Field(j); field.Name() == pair.Key.(*ast.Ident).Name {
+									check(pair.Value, field.Type())
+								}
+							}
+						} else if i < typ.NumFields() {
+							check(element, typ.Field(i).Type())
+						}
+					}
+				}
+
+			case *ast.AssignStmt:
+				if len(node.Lhs) == len(node.Rhs) {
+					for i, value := range node.Rhs {
+						check(value, info.TypeOf(node.Lhs[i]))
+					}
+				}
+			}
+			return true
+		}
+		ast.Inspect(file, walk)
+	}
+}
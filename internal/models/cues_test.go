@@ -0,0 +1,50 @@
+package models
+
+import "testing"
+
+func TestPhaseCue(t *testing.T) {
+	for _, tc := range []struct {
+		phase         GamePhase
+		revealedDeath bool
+		want          string
+	}{
+		{PhaseNight, false, CueNightFalls},
+		{PhaseDay, false, CueDayBreaks},
+		{PhaseVoting, false, CueVoteDrums},
+		{PhaseEnded, false, ""},
+		{PhaseWaiting, false, ""},
+		{PhaseDay, true, CueDeathReveal},
+		{PhaseNight, true, CueDeathReveal},
+	} {
+		if got := PhaseCue(tc.phase, tc.revealedDeath); got != tc.want {
+			t.Errorf("PhaseCue(%s, %v) = %q, want %q", tc.phase, tc.revealedDeath, got, tc.want)
+		}
+	}
+}
+
+// Every cue an event always carries is in the catalog under that event,
+// and the catalog names each cue once
+func TestCueCatalogListsEveryCue(t *testing.T) {
+	listed := make(map[string]CueInfo)
+	for _, info := range CueCatalog {
+		if _, dup := listed[info.Cue]; dup {
+			t.Errorf("%s is listed twice", info.Cue)
+		}
+		listed[info.Cue] = info
+	}
+
+	for eventType, cue := range EventCues {
+		info, ok := listed[cue]
+		if !ok {
+			t.Errorf("%s carries %s, which the catalog lacks", eventType, cue)
+			continue
+		}
+		found := false
+		for _, listedType := range info.Events {
+			found = found || listedType == eventType
+		}
+		if !found {
+			t.Errorf("the catalog does not list %s under %s", eventType, cue)
+		}
+	}
+}
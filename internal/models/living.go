@@ -0,0 +1,183 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// livingIndex is a room's record of who is alive, kept up to date by the
+// GameRoom methods that change life, role or membership, so the many
+// questions about the living are answered without scanning the roster
+type livingIndex struct {
+	players map[string]*Player
+	byRole  map[Role]int
+}
+
+// living returns the room's index, building it from the roster the first
+// time it is asked for
+func (r *GameRoom) living() *livingIndex {
+	if r.livingIdx == nil {
+		r.livingIdx = &livingIndex{
+			players: make(map[string]*Player),
+			byRole:  make(map[Role]int),
+		}
+		for _, player := range r.Players {
+			if player.IsAlive {
+				r.livingIdx.add(player)
+			}
+		}
+	}
+	return r.livingIdx
+}
+
+func (l *livingIndex) add(player *Player) {
+	l.players[player.ID] = player
+	l.byRole[player.Role]++
+}
+
+func (l *livingIndex) remove(player *Player) {
+	if _, ok := l.players[player.ID]; !ok {
+		return
+	}
+	delete(l.players, player.ID)
+	l.byRole[player.Role]--
+	if l.byRole[player.Role] <= 0 {
+		delete(l.byRole, player.Role)
+	}
+}
+
+// ReindexLiving drops the index so it is rebuilt from the roster. Copies of
+// a room call it, as a copied room shares its original's index.
+func (r *GameRoom) ReindexLiving() {
+	r.livingIdx = nil
+}
+
+// AddPlayer seats a player in the room
+func (r *GameRoom) AddPlayer(player *Player) {
+	if old := r.Players[player.ID]; old != nil && r.livingIdx != nil {
+		r.livingIdx.remove(old)
+	}
+	r.Players[player.ID] = player
+	if player.IsAlive && r.livingIdx != nil {
+		r.livingIdx.add(player)
+	}
+}
+
+// RemovePlayer takes a player out of the room
+func (r *GameRoom) RemovePlayer(playerID string) {
+	player := r.Players[playerID]
+	if player == nil {
+		return
+	}
+	if r.livingIdx != nil {
+		r.livingIdx.remove(player)
+	}
+	delete(r.Players, playerID)
+}
+
+// SetAlive marks a player in the room alive or dead
+func (r *GameRoom) SetAlive(player *Player, alive bool) {
+	if player.IsAlive == alive {
+		return
+	}
+	if r.livingIdx != nil {
+		if alive {
+			r.livingIdx.add(player)
+		} else {
+			r.livingIdx.remove(player)
+		}
+	}
+	player.IsAlive = alive
+}
+
+// SetRole deals a player in the room a role
+func (r *GameRoom) SetRole(player *Player, role Role) {
+	if player.Role == role {
+		return
+	}
+	if player.IsAlive && r.livingIdx != nil {
+		r.livingIdx.remove(player)
+		player.Role = role
+		r.livingIdx.add(player)
+		return
+	}
+	player.Role = role
+}
+
+// LivingPlayers returns the living players, ordered by ID
+func (r *GameRoom) LivingPlayers() []*Player {
+	index := r.living()
+	players := make([]*Player, 0, len(index.players))
+	for _, player := range index.players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
+
+// LivingCount counts the living players holding any of the roles, or every
+// living player when no role is given
+func (r *GameRoom) LivingCount(roles ...Role) int {
+	index := r.living()
+	if len(roles) == 0 {
+		return len(index.players)
+	}
+	count := 0
+	for _, role := range roles {
+		count += index.byRole[role]
+	}
+	return count
+}
+
+// LivingConnected returns the living players who are present: connected,
+// or bots, which never leave. They are ordered by ID.
+func (r *GameRoom) LivingConnected() []*Player {
+	var present []*Player
+	for _, player := range r.LivingPlayers() {
+		if player.IsConnected || player.IsBot {
+			present = append(present, player)
+		}
+	}
+	return present
+}
+
+// checkLiving recounts the living from the roster and reports where the
+// index disagrees
+func (r *GameRoom) checkLiving() []string {
+	if r.livingIdx == nil {
+		return nil
+	}
+
+	var problems []string
+	byRole := make(map[Role]int)
+	for id, player := range r.Players {
+		_, indexed := r.livingIdx.players[id]
+		switch {
+		case player.IsAlive && !indexed:
+			problems = append(problems, fmt.Sprintf("living player %q is missing from the living index", id))
+		case !player.IsAlive && indexed:
+			problems = append(problems, fmt.Sprintf("dead player %q is in the living index", id))
+		case indexed && r.livingIdx.players[id] != player:
+			problems = append(problems, fmt.Sprintf("living index holds a stale copy of player %q", id))
+		}
+		if player.IsAlive {
+			byRole[player.Role]++
+		}
+	}
+	for id := range r.livingIdx.players {
+		if r.Players[id] == nil {
+			problems = append(problems, fmt.Sprintf("living index holds %q who is not in the room", id))
+		}
+	}
+	for role, count := range byRole {
+		if r.livingIdx.byRole[role] != count {
+			problems = append(problems, fmt.Sprintf("living index counts %d living %q, the roster %d", r.livingIdx.byRole[role], role, count))
+		}
+	}
+	for role, count := range r.livingIdx.byRole {
+		if _, ok := byRole[role]; !ok {
+			problems = append(problems, fmt.Sprintf("living index counts %d living %q, the roster none", count, role))
+		}
+	}
+	return problems
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"math/rand"
+	"time"
+)
 
 // GamePhase represents the current phase of the game
 type GamePhase string
@@ -9,10 +12,250 @@ const (
 	PhaseWaiting GamePhase = "waiting"
 	PhaseNight   GamePhase = "night"
 	PhaseDay     GamePhase = "day"
+	PhaseDefense GamePhase = "defense" // ผู้ถูกกล่าวหาแก้ต่าง (formal accusations)
 	PhaseVoting  GamePhase = "voting"
 	PhaseEnded   GamePhase = "ended"
 )
 
+// VoteAbstain is the vote target for abstaining on a restricted ballot
+const VoteAbstain = "abstain"
+
+// RoomSettings holds the host-configurable rules of a room
+type RoomSettings struct {
+	FormalAccusations bool `json:"formalAccusations"` // เสนอชื่อผู้ต้องสงสัยก่อนโหวต
+	PracticeMode      bool `json:"practiceMode"`      // ห้องฝึกเล่น: ผู้เล่นคนเดียว + บอท (ตั้งได้ตอนสร้างห้องเท่านั้น)
+	// NightSleepConfirmation makes every living player acknowledge the night,
+	// so the time it takes to advance no longer hints at who holds a role
+	NightSleepConfirmation bool `json:"nightSleepConfirmation"`
+	// MinDiscussionSeconds blocks skipping the day until this much time has passed (0 disables)
+	MinDiscussionSeconds int `json:"minDiscussionSeconds"`
+	// RevealVotes shows who voted for whom; when off ballots stay anonymous
+	// and only the tallies are public
+	RevealVotes bool `json:"revealVotes"`
+	// MaxPlayers caps the lobby; it can never drop below the players already in it
+	MaxPlayers int `json:"maxPlayers"`
+	// HardHiddenRoles keeps the dead as much in the dark as the living until
+	// the game ends, and closes the dead chat
+	HardHiddenRoles bool `json:"hardHiddenRoles"`
+	// ReadyToVoteFraction ends the day once more than this fraction of living,
+	// connected players are ready to vote (0.5 = strict majority, 0 turns it off)
+	ReadyToVoteFraction float64 `json:"readyToVoteFraction"`
+	// Composition fixes how many of each role are dealt; empty deals the
+	// default for the player count
+	Composition map[Role]int `json:"composition,omitempty"`
+	// AbortApprovals is how many players must approve the host's request to
+	// abort a game; 0 means all eligible players but one
+	AbortApprovals int `json:"abortApprovals"`
+	// QuorumFraction pauses the game at a phase change when fewer than this
+	// fraction of living players are connected (0 turns it off)
+	QuorumFraction float64 `json:"quorumFraction"`
+	// NightModifiers are the random night modifiers (e.g. "blood_moon") that
+	// may be drawn at dusk; empty keeps every night ordinary
+	NightModifiers []string `json:"nightModifiers,omitempty"`
+	// JoinApprovalRequired makes players ask the host to let them in;
+	// invite links still join directly
+	JoinApprovalRequired bool `json:"joinApprovalRequired"`
+	// FirstNightNoKill spares whoever the tigers attack on the first night;
+	// every other night action resolves as usual
+	FirstNightNoKill bool `json:"firstNightNoKill"`
+	// ShamanCanInspectDead lets the shaman spend a night on a dead player to
+	// learn their true role, curse or not
+	ShamanCanInspectDead bool `json:"shamanCanInspectDead"`
+	// VoteTieBreak settles a day vote with several players tied for the most
+	// votes: TieBreakRandom (the default) or TieBreakNone
+	VoteTieBreak string `json:"voteTieBreak"`
+	// TigersMustKill stops the tiger team from passing on the night's kill;
+	// a turn that runs out is still skipped
+	TigersMustKill bool `json:"tigersMustKill"`
+	// HunterShotNeedsConfirmation makes the dead hunter select a target and
+	// then confirm the shot, so a misclick cannot kill anyone
+	HunterShotNeedsConfirmation bool `json:"hunterShotNeedsConfirmation"`
+	// HunterCannotShootProtectedTarget makes the player the hunter protected
+	// on the last night immune to the revenge shot
+	HunterCannotShootProtectedTarget bool `json:"hunterCannotShootProtectedTarget"`
+	// Public lists the room in the lobby browser; other rooms are only
+	// found by their code
+	Public bool `json:"public"`
+	// Hints is how much help night prompts give: HintsNone (the default)
+	// or HintsBasic, which flags targets with what the player already knows
+	Hints string `json:"hints"`
+	// AnnounceTigerCount tells everyone at each dawn how many of the tiger
+	// team are still alive, but not who they are
+	AnnounceTigerCount bool `json:"announceTigerCount"`
+	// VoteLockSeconds freezes ballots once less than this much of the vote
+	// is left: votes can no longer be changed (0 disables)
+	VoteLockSeconds int `json:"voteLockSeconds"`
+	// VoteLockBlocksFirstVotes makes the lock refuse first votes as well,
+	// so players who have not voted by then stay out of the tally
+	VoteLockBlocksFirstVotes bool `json:"voteLockBlocksFirstVotes"`
+	// NightCooldowns rests a role's night ability for this many nights after
+	// each use, e.g. 1 lets the hunter protect every other night;
+	// CooldownForever makes it once per game. Roles left out act every night.
+	NightCooldowns map[Role]int `json:"nightCooldowns,omitempty"`
+	// AutoStartWhenReady counts down to starting the game on its own once
+	// every seat is filled and everyone is ready and connected
+	AutoStartWhenReady bool `json:"autoStartWhenReady"`
+	// AutoStartSeconds is that countdown; 0 means the default of 10
+	AutoStartSeconds int `json:"autoStartSeconds"`
+	// MysteryDawn keeps who the tigers killed secret at dawn: the victim
+	// looks alive to the village until the body is found, when the timer
+	// runs out or the host reveals it, and at the latest when the day ends
+	MysteryDawn bool `json:"mysteryDawn"`
+	// MysteryDawnRevealSeconds is how long into the day the body is found;
+	// 0 means the default of 30
+	MysteryDawnRevealSeconds int `json:"mysteryDawnRevealSeconds"`
+}
+
+// Vote tie-breaks
+const (
+	TieBreakRandom = "random" // สุ่มหนึ่งคนจากคนที่คะแนนเท่ากัน (สุ่มจาก seed ของห้อง)
+	TieBreakNone   = "none"   // คะแนนเท่ากันไม่มีใครถูกประหาร
+)
+
+// Hint levels
+const (
+	HintsNone  = "none"  // ไม่มีคำใบ้ (ห้องแข่งขัน)
+	HintsBasic = "basic" // บอกสิ่งที่ผู้เล่นรู้อยู่แล้ว เช่น เคยปกป้องหรือเคยส่องใคร
+)
+
+// DefaultRoomSettings returns the settings a new room starts with
+func DefaultRoomSettings() RoomSettings {
+	return RoomSettings{
+		MinDiscussionSeconds: 30,
+		RevealVotes:          true,
+		MaxPlayers:           10,
+		ReadyToVoteFraction:  0.5,
+		QuorumFraction:       0.5,
+		VoteTieBreak:         TieBreakRandom,
+		TigersMustKill:       true,
+		Hints:                HintsNone,
+	}
+}
+
+// AbortVote is the consent vote the host opens to abandon a game. Living,
+// connected players are eligible; the phase clocks stand still until it closes.
+type AbortVote struct {
+	RequestedBy string          `json:"requestedBy"`
+	EndsAt      Timestamp       `json:"endsAt"`
+	Eligible    []string        `json:"eligible"`
+	Needed      int             `json:"needed"`
+	Approvals   int             `json:"approvals"`
+	Rejections  int             `json:"rejections"`
+	StartedAt   time.Time       `json:"-"`
+	Votes       map[string]bool `json:"-"` // คำตอบของแต่ละคน (true = เห็นด้วย) ไม่เปิดเผยรายคน
+}
+
+// QuorumPause holds a game whose living players mostly disconnected. It
+// ends in a forfeit unless enough of them are back by GraceEndsAt.
+type QuorumPause struct {
+	LostAt      Timestamp `json:"lostAt"`
+	GraceEndsAt Timestamp `json:"graceEndsAt"`
+	Connected   int       `json:"connected"`
+	Needed      int       `json:"needed"`
+}
+
+// PendingPrompt is a prompt a player was sent and has not answered yet, kept
+// so it can be sent again when they reconnect
+type PendingPrompt struct {
+	Type     string     // event ที่ใช้ส่ง prompt
+	Context  string     // สิ่งที่ prompt อ้างถึง เช่น turn token
+	Deadline *Timestamp // หมดเวลาตอบเมื่อใด (nil = ไม่มีกำหนด)
+}
+
+// Nomination is one player's accusation during a formal-accusations day
+type Nomination struct {
+	NominatorID string    `json:"nominatorId"`
+	NomineeID   string    `json:"nomineeId"`
+	At          Timestamp `json:"at"`
+}
+
+// TigerDecision is one tiger-team member's choice for the night
+type TigerDecision struct {
+	PlayerID string `json:"playerId"`
+	Username string `json:"username"`
+	Action   string `json:"action"` // ActionKill, ActionCurse or ActionSkip
+	TargetID string `json:"targetId,omitempty"`
+}
+
+// TigerTeamNight is the tiger team's shared night step. Living members
+// decide in any order, may change their mind until everyone has decided,
+// and the step then resolves to at most one kill and at most one curse.
+type TigerTeamNight struct {
+	Decisions     map[string]*TigerDecision `json:"decisions"`
+	Resolved      bool                      `json:"resolved"`
+	KillTargetID  string                    `json:"killTargetId,omitempty"`
+	CurseTargetID string                    `json:"curseTargetId,omitempty"`
+	// SecondKillTargetID is the first victim the team's kill overruled;
+	// only a blood moon attacks them too
+	SecondKillTargetID string `json:"secondKillTargetId,omitempty"`
+}
+
+// Message types. Players never choose one: the server assigns it from
+// where the message is routed.
+const (
+	MessageChat     = "chat"     // ข้อความผู้เล่นทั่วไป
+	MessageSystem   = "system"   // ผู้บรรยาย (เซิร์ฟเวอร์เท่านั้น)
+	MessageWerewolf = "werewolf" // ช่องแชทฝ่ายเสือ
+	MessageDead     = "dead"     // ช่องแชทคนตาย
+)
+
+// NightTrace explains how one night resolved, step by step
+type NightTrace struct {
+	Round    int              `json:"round"`
+	Modifier string           `json:"modifier,omitempty"` // the night modifier drawn that night
+	Steps    []NightTraceStep `json:"steps"`
+}
+
+// NightTraceStep is one step of a night's resolution. Key and Params let
+// clients render it in their own language; Text is the English rendering.
+type NightTraceStep struct {
+	Key    string            `json:"key"`
+	Params map[string]string `json:"params,omitempty"`
+	Text   string            `json:"text"`
+}
+
+// Join request states
+const (
+	JoinPending  = "pending"
+	JoinApproved = "approved"
+	JoinDenied   = "denied"
+	JoinExpired  = "expired"
+)
+
+// JoinRequest is a player waiting for the host to let them into a lobby
+// that requires approval
+type JoinRequest struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	RequestedAt Timestamp `json:"requestedAt"`
+	ExpiresAt   Timestamp `json:"expiresAt"`
+	Status      string    `json:"status"`
+	Reason      string    `json:"reason,omitempty"` // why a request was turned down, e.g. "room_full"
+	PlayerID    string    `json:"-"`                // ID the player joins with; only the requester learns it
+	HostID      string    `json:"-"`                // who to ask
+	// SessionToken is the session the player holds once approved; only the
+	// requester learns it, and polls with it
+	SessionToken string `json:"-"`
+}
+
+// Invite is a single-use token that lets its holder join a room directly
+type Invite struct {
+	Token     string     `json:"token"`
+	CreatedAt Timestamp  `json:"createdAt"`
+	ExpiresAt *Timestamp `json:"expiresAt,omitempty"`
+	UsedBy    string     `json:"usedBy,omitempty"`
+	UsedAt    *Timestamp `json:"usedAt,omitempty"`
+}
+
+// PlayerColors is the fixed palette of player color/avatar slots, one for
+// each seat of the largest room. A player's ColorSlot indexes into it.
+var PlayerColors = []string{
+	"#E53935", "#1E88E5", "#43A047", "#FDD835", "#8E24AA",
+	"#FB8C00", "#00ACC1", "#D81B60", "#6D4C41", "#546E7A",
+	"#3949AB", "#00897B", "#7CB342", "#FFB300", "#5E35B1",
+	"#F4511E", "#039BE5", "#C0CA33", "#EC407A", "#757575",
+}
+
 // Role represents player roles in the game
 type Role string
 
@@ -24,88 +267,517 @@ const (
 	RoleVillager   Role = "villager"    // ชาวบ้าน
 )
 
-// Player represents a player in the game
+// AllRoles lists every role in the game
+var AllRoles = []Role{RoleAlphaTiger, RoleTiger, RoleShaman, RoleHunter, RoleVillager}
+
+// Player represents a player in the game. Clients are sent it as a
+// PlayerPublic or PlayerPrivate (see wire.go), never as it is.
 type Player struct {
-	ID                string    `json:"id"`
-	Username          string    `json:"username"`
-	Role              Role      `json:"role,omitempty"` // Hidden from other players
-	IsAlive           bool      `json:"isAlive"`
-	IsReady           bool      `json:"isReady"`
-	IsCursed          bool      `json:"isCursed,omitempty"`          // ถูกสาปโดยพญาสมิง
-	HasUsedCurse      bool      `json:"hasUsedCurse,omitempty"`      // พญาสมิงใช้สาปแล้ว
-	CanShoot          bool      `json:"canShoot,omitempty"`          // นายพรานสามารถยิงได้
-	LastProtected     string    `json:"lastProtected,omitempty"`     // ID ของคนที่กันไปคืนก่อน
-	HasActedThisNight bool      `json:"hasActedThisNight,omitempty"` // ใช้ความสามารถในคืนนี้แล้ว
-	VotedFor          string    `json:"votedFor,omitempty"`          // ID ของคนที่โหวต (ใน voting phase)
-	RoomCode          string    `json:"roomCode"`
-	JoinedAt          time.Time `json:"joinedAt"`
-}
-
-// GameRoom represents a game room
+	ID                string     `json:"id"`
+	Username          string     `json:"username"`
+	Role              Role       `json:"role,omitempty"` // ซ่อนจากผู้เล่นอื่นใน view (ดู PlayerPublic)
+	IsAlive           bool       `json:"isAlive"`
+	DeathCause        string     `json:"deathCause,omitempty"` // สาเหตุการตาย (DeathBy*)
+	IsReady           bool       `json:"isReady"`
+	IsBot             bool       `json:"isBot,omitempty"`             // บอทในห้องฝึกเล่น
+	IsConnected       bool       `json:"isConnected"`                 // มี websocket เชื่อมต่ออยู่
+	IsModerator       bool       `json:"isModerator,omitempty"`       // ผู้ช่วย host (เตะ/ปิดเสียง/ขยายเวลาได้)
+	IsMuted           bool       `json:"isMuted,omitempty"`           // ถูกปิดเสียงแชท
+	Connections       int        `json:"-"`                           // จำนวน websocket ที่เปิดอยู่ (เปิดหลายแท็บได้)
+	DisconnectedAt    *time.Time `json:"-"`                           // เวลาที่ websocket สุดท้ายหลุด (nil = เชื่อมต่ออยู่หรือยังไม่เคยเชื่อมต่อ)
+	ColorSlot         int        `json:"colorSlot"`                   // ช่องสี/อวตารจาก PlayerColors
+	IsCursed          bool       `json:"isCursed,omitempty"`          // ถูกสาปโดยพญาสมิง
+	LastProtected     string     `json:"lastProtected,omitempty"`     // ID ของคนที่กันไปคืนก่อน
+	HasActedThisNight bool       `json:"hasActedThisNight,omitempty"` // ใช้ความสามารถในคืนนี้แล้ว
+	VotedFor          string     `json:"votedFor,omitempty"`          // ID ของคนที่โหวต (ใน voting phase)
+	RoomCode          RoomCode   `json:"roomCode"`
+	Seat              int        `json:"seat,omitempty"` // ที่นั่งรอบวง (1..n) สุ่มตอนเริ่มเกม คงเดิมแม้ตายแล้ว
+	JoinedAt          Timestamp  `json:"joinedAt"`
+
+	// ActionHistory is private to the player until the game ends
+	ActionHistory []ActionRecord `json:"actionHistory,omitempty"`
+	// ActionSeq counts the actions accepted from the player, whichever
+	// transport they came over
+	ActionSeq int `json:"-"`
+	// HandledRequests are the player's latest requestIds that produced an
+	// action, oldest first
+	HandledRequests []HandledRequest `json:"-"`
+	// SessionToken is the secret the player's websocket and REST requests
+	// must present; only the player is ever told it
+	SessionToken string `json:"-"`
+
+	// Abilities tracks limited-use abilities by name; it is never serialized
+	Abilities map[string]*AbilityUse `json:"-"`
+	// AbilityStatus is computed for the viewer's own player in personalized views
+	AbilityStatus []AbilityStatus `json:"abilities,omitempty"`
+}
+
+// Limited-use abilities
+const (
+	AbilityCurse   = "curse"   // พญาสมิงสาปได้ครั้งเดียว
+	AbilityShoot   = "shoot"   // นายพรานยิงได้ครั้งเดียวเมื่อตาย
+	AbilityProtect = "protect" // นายพรานปกป้อง (จำกัดเมื่อห้องตั้งคูลดาวน์)
+	AbilityVision  = "vision"  // หมอผีส่อง/ตรวจศพ (จำกัดเมื่อห้องตั้งคูลดาวน์)
+)
+
+// CooldownForever is the cooldown of a once-per-game ability: after one
+// use it never comes back
+const CooldownForever = -1
+
+// AbilityUse tracks one limited ability: how often it was used and how long
+// it rests between uses
+type AbilityUse struct {
+	Used           int
+	CooldownNights int // จำนวนคืนที่ต้องเว้นหลังใช้ก่อนใช้ได้อีก (CooldownForever = ใช้ได้ครั้งเดียว)
+	LastUsedNight  int // คืนล่าสุดที่ใช้ (0 = ยังไม่เคยใช้ หรือใช้ตอนกลางวัน)
+}
+
+// NightsUntilReady returns how many nights must pass before the ability can
+// be used on the given night: 0 when it is ready, CooldownForever when it
+// is spent for good
+func (a *AbilityUse) NightsUntilReady(night int) int {
+	if a.Used == 0 {
+		return 0
+	}
+	if a.CooldownNights == CooldownForever {
+		return CooldownForever
+	}
+	if a.LastUsedNight == 0 {
+		return 0
+	}
+	if wait := a.LastUsedNight + a.CooldownNights + 1 - night; wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// AbilityStatus is the client-facing summary of one limited ability
+type AbilityStatus struct {
+	Name string `json:"name"`
+	Used int    `json:"used"`
+	// Remaining is the uses left of a once-per-game ability; abilities that
+	// only rest between uses leave it out
+	Remaining *int `json:"remaining,omitempty"`
+	// CooldownNights is how many nights are left before the ability can be
+	// used again; 0 when it is ready
+	CooldownNights int  `json:"cooldownNights,omitempty"`
+	Usable         bool `json:"usable"` // usable right now (phase, turn, alive, cooldown)
+}
+
+// AbilityReady reports whether the player holds the ability and may use it
+// on the given night. Abilities used outside the night, such as the
+// hunter's shot, only depend on whether they are spent.
+func (p *Player) AbilityReady(name string, night int) bool {
+	ability := p.Abilities[name]
+	return ability != nil && ability.NightsUntilReady(night) == 0
+}
+
+// HasUsedAbility reports whether the player has used the ability at least once
+func (p *Player) HasUsedAbility(name string) bool {
+	ability := p.Abilities[name]
+	return ability != nil && ability.Used > 0
+}
+
+// UseAbility consumes one use of the ability on the given night (0 for a
+// use outside the night)
+func (p *Player) UseAbility(name string, night int) {
+	if ability := p.Abilities[name]; ability != nil {
+		ability.Used++
+		ability.LastUsedNight = night
+	}
+}
+
+// Death causes
+const (
+	DeathByTiger      = "tiger"      // ถูกเสือกัดตอนกลางคืน
+	DeathByVote       = "vote"       // ถูกโหวตออก
+	DeathByHunter     = "hunter"     // ถูกนายพรานยิง
+	DeathByWithdrawal = "withdrawal" // หลุดการเชื่อมต่อนานเกินไป หนีเข้าป่า
+)
+
+// Disconnect reasons
+const (
+	DisconnectClientClosed        = "client_closed"        // client ปิดการเชื่อมต่อเอง
+	DisconnectConnectionLost      = "connection_lost"      // การเชื่อมต่อหลุดโดยไม่มีการปิดปกติ
+	DisconnectSlowConsumer        = "slow_consumer"        // รับข้อมูลไม่ทัน buffer เต็ม
+	DisconnectWriteFailed         = "write_failed"         // ส่งข้อมูลไปหา client ไม่สำเร็จ
+	DisconnectSuperseded          = "superseded"           // มีการเชื่อมต่อใหม่ของผู้เล่นคนเดียวกันมาแทน
+	DisconnectKicked              = "kicked"               // ถูก host เตะออก
+	DisconnectUnsupportedProtocol = "unsupported_protocol" // ประกาศเวอร์ชันโปรโตคอลที่ไม่รองรับ
+	DisconnectRoomClosed          = "room_closed"          // ห้องถูกเก็บเข้าคลังหลังเกมจบ
+	DisconnectInvalidSession      = "invalid_session"      // ไม่ได้แนบ session token ของผู้เล่นที่ถูกต้อง
+)
+
+// DisconnectRecord is one connection of a player ending, kept for support
+type DisconnectRecord struct {
+	PlayerID string    `json:"playerId"`
+	Reason   string    `json:"reason"` // Disconnect*
+	At       Timestamp `json:"at"`
+	AddrHash string    `json:"addrHash"` // hash ของ IP ไม่เก็บ IP จริง
+}
+
+// Action types recorded in a player's action history
+const (
+	ActionProtect = "protect"
+	ActionKill    = "kill"
+	ActionVision  = "vision"
+	ActionInspect = "inspect" // หมอผีส่องคนตายดูบทบาทจริง
+	ActionCurse   = "curse"
+	ActionSkip    = "skip"
+	ActionPass    = "pass" // ตั้งใจไม่ทำอะไรในคืนนี้ (ต่างจาก skip ที่ข้ามหรือหมดเวลา)
+	ActionVote    = "vote"
+	ActionShoot   = "shoot"
+	ActionSleep   = "sleep"
+)
+
+// ActionRecord is one entry in a player's action history.
+// Outcome is filled in once it is known (e.g. when the night resolves).
+type ActionRecord struct {
+	Round          int       `json:"round"`
+	Phase          GamePhase `json:"phase"`
+	ActionType     string    `json:"actionType"`
+	TargetID       string    `json:"targetId,omitempty"`
+	TargetUsername string    `json:"targetUsername,omitempty"`
+	Outcome        string    `json:"outcome,omitempty"`
+}
+
+// HandledRequest is a client requestId the server already acted on and the
+// action it produced
+type HandledRequest struct {
+	ID     string       `json:"id"`
+	Record ActionRecord `json:"record"`
+}
+
+// Ballot is one vote cast in a day vote that has been resolved
+type Ballot struct {
+	Round    int    `json:"round"`
+	VoterID  string `json:"voterId,omitempty"` // ว่างระหว่างเกมเมื่อโหวตแบบไม่เปิดเผย
+	TargetID string `json:"targetId"`          // ID ผู้ถูกโหวต หรือ VoteAbstain
+	Outcome  string `json:"outcome"`           // "eliminated", "survived" หรือ "abstained"
+}
+
+// VoteOutcome is how one day vote was settled
+type VoteOutcome struct {
+	Round        int      `json:"round"`
+	Votes        int      `json:"votes"`                  // คะแนนสูงสุด
+	Tied         []string `json:"tied,omitempty"`         // ID ของคนที่คะแนนสูงสุดเท่ากัน (เมื่อมีมากกว่าหนึ่งคน)
+	TieBreak     string   `json:"tieBreak,omitempty"`     // วิธีตัดสินเมื่อเสมอ
+	EliminatedID string   `json:"eliminatedId,omitempty"` // ว่างเมื่อไม่มีใครถูกประหาร
+}
+
+// HostAction is one administrative action a host or moderator took in a room
+type HostAction struct {
+	ActorID  string    `json:"actorId"`
+	Action   string    `json:"action"` // kick, mute, unmute, grant_moderator, revoke_moderator, skip_phase, change_settings, accept_composition, extend_discussion, abort_game
+	TargetID string    `json:"targetId,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+	At       Timestamp `json:"at"`
+}
+
+// GameRoom represents a game room. Clients are sent it as a RoomPublic or
+// RoomForViewer (see wire.go), never as it is.
 type GameRoom struct {
-	Code                  string             `json:"code"`
-	HostID                string             `json:"hostId"`
-	Players               map[string]*Player `json:"players"`
-	Phase                 GamePhase          `json:"phase"`
-	Round                 int                `json:"round"`
-	MaxPlayers            int                `json:"maxPlayers"`
-	CreatedAt             time.Time          `json:"createdAt"`
-	StartedAt             *time.Time         `json:"startedAt,omitempty"`
-	VoteResults           map[string]int     `json:"voteResults,omitempty"`
-	HunterProtection      string             `json:"hunterProtection,omitempty"`      // ID ของคนที่นายพรานกัน
-	TigerTarget           string             `json:"tigerTarget,omitempty"`           // ID ของเหยื่อที่เสือเลือก
-	ShamanVision          string             `json:"shamanVision,omitempty"`          // ID ของคนที่หมอผีส่อง
-	KilledTonight         string             `json:"killedTonight,omitempty"`         // ID ของคนที่ตายคืนนี้
-	CursedPlayer          string             `json:"cursedPlayer,omitempty"`          // ID ของคนที่ถูกสาป
-	PhaseEndTime          *time.Time         `json:"phaseEndTime,omitempty"`          // เวลาสิ้นสุดเฟส
-	NightActionsCompleted map[string]bool    `json:"nightActionsCompleted,omitempty"` // ผู้เล่นที่ใช้พลังหรือข้ามแล้วในคืนนี้
-	NightActionsRequired  int                `json:"nightActionsRequired,omitempty"`  // จำนวนผู้เล่นที่ต้องใช้พลังในคืนนี้
-	CurrentNightRole      Role               `json:"currentNightRole,omitempty"`      // Role ที่กำลัง action ในคืนนี้
-	NightActionOrder      []Role             `json:"nightActionOrder,omitempty"`      // ลำดับการ action ในคืน
-	WaitingHunterShoot    bool               `json:"waitingHunterShoot,omitempty"`    // รอนายพรานยิงหรือไม่
-	DeadHunterID          string             `json:"deadHunterID,omitempty"`          // ID ของนายพรานที่ตายและรอยิง
-	WinningTeam           string             `json:"winningTeam,omitempty"`           // "human" หรือ "tiger"
+	Code                  RoomCode                   `json:"code"`
+	Aliases               map[string]string          `json:"aliases,omitempty"` // ชื่อเล่นของรหัสห้องเป็นคำ ๆ แยกตามภาษา (บอกกันทางเสียงได้ง่าย)
+	HostID                string                     `json:"hostId"`
+	Players               map[string]*Player         `json:"players"`
+	Phase                 GamePhase                  `json:"phase"`
+	Round                 int                        `json:"round"`
+	NightNumber           int                        `json:"nightNumber,omitempty"` // คืนที่เท่าไรของเกม (คืนแรก = 1)
+	MaxPlayers            int                        `json:"maxPlayers"`
+	CreatedAt             Timestamp                  `json:"createdAt"`
+	StartedAt             *Timestamp                 `json:"startedAt,omitempty"`
+	VoteResults           map[string]int             `json:"voteResults,omitempty"`
+	HunterProtection      string                     `json:"hunterProtection,omitempty"`      // ID ของคนที่นายพรานกัน
+	TigerTarget           string                     `json:"tigerTarget,omitempty"`           // ID ของเหยื่อที่เสือเลือก
+	ShamanVision          string                     `json:"shamanVision,omitempty"`          // ID ของคนที่หมอผีส่อง
+	KilledTonight         string                     `json:"killedTonight,omitempty"`         // ID ของคนที่ตายคืนนี้
+	CursedPlayer          string                     `json:"cursedPlayer,omitempty"`          // ID ของคนที่ถูกสาป
+	PhaseEndTime          *Timestamp                 `json:"phaseEndTime,omitempty"`          // เวลาสิ้นสุดเฟส
+	DayStartedAt          *Timestamp                 `json:"dayStartedAt,omitempty"`          // เวลาเริ่มเฟสกลางวันล่าสุด
+	NightActionsCompleted map[string]bool            `json:"nightActionsCompleted,omitempty"` // ผู้เล่นที่ใช้พลังหรือข้ามแล้วในคืนนี้
+	NightActionsRequired  int                        `json:"nightActionsRequired,omitempty"`  // จำนวนผู้เล่นที่ต้องใช้พลังในคืนนี้
+	CurrentNightRole      Role                       `json:"currentNightRole,omitempty"`      // Role ที่กำลัง action ในคืนนี้
+	NightActionOrder      []Role                     `json:"nightActionOrder,omitempty"`      // ลำดับการ action ในคืน (ไม่ส่งให้ client ใช้ NightEntry แทน)
+	NightEntry            *NightEntry                `json:"nightEntry,omitempty"`            // สิ่งที่ผู้ชมแต่ละคนควรรู้ตอนเข้ากลางคืน (มีเฉพาะใน view)
+	Permissions           *Permissions               `json:"permissions,omitempty"`           // สิ่งที่ผู้ชมทำได้ตอนนี้ (มีเฉพาะใน view ของผู้เล่น)
+	ViewerID              string                     `json:"-"`                               // ผู้ชมของ view นี้ ("" = view สาธารณะ)
+	WaitingHunterShoot    bool                       `json:"waitingHunterShoot,omitempty"`    // รอนายพรานยิงหรือไม่
+	DeadHunterID          string                     `json:"deadHunterID,omitempty"`          // ID ของนายพรานที่ตายและรอยิง
+	HunterShotEndsAt      *Timestamp                 `json:"hunterShotEndsAt,omitempty"`      // หมดเวลายิงของนายพราน (nil = ไม่มีกำหนด)
+	HunterSelection       string                     `json:"-"`                               // เป้าที่นายพรานเลือกไว้ รอยืนยัน (hunterShotNeedsConfirmation)
+	WinningTeam           string                     `json:"winningTeam,omitempty"`           // "human" หรือ "tiger"
+	TigersRemaining       *int                       `json:"tigersRemaining,omitempty"`       // ฝ่ายเสือที่ยังมีชีวิต ณ รุ่งเช้าล่าสุด (ส่งเฉพาะเมื่อเปิด announceTigerCount)
+	Settings              RoomSettings               `json:"settings"`
+	Nominations           []Nomination               `json:"nominations,omitempty"`       // การเสนอชื่อในวันนี้
+	Nominees              []string                   `json:"nominees,omitempty"`          // ผู้ถูกเสนอชื่อที่เข้ารอบโหวต
+	VoteExtendedFor       []string                   `json:"-"`                           // ผู้เล่นที่กลับมาเชื่อมต่อแล้วได้ต่อเวลาโหวตในรอบนี้
+	Invites               map[string]*Invite         `json:"-"`                           // ลิงก์เชิญ (เห็นเฉพาะ host ผ่าน API)
+	JoinRequests          map[string]*JoinRequest    `json:"-"`                           // คำขอเข้าห้องที่รอ host อนุมัติ
+	NightAcknowledged     map[string]bool            `json:"-"`                           // ผู้เล่นที่ยืนยันเข้านอนแล้ว (ซ่อนไว้กันเดาบทบาทจากเวลา)
+	ChatHistory           []*Message                 `json:"-"`                           // แชทล่าสุด (ส่งให้คนที่เพิ่งเชื่อมต่อ)
+	LastActivityAt        time.Time                  `json:"-"`                           // ใช้ตัดสินว่าห้องว่างนานพอให้ janitor ลบหรือยัง
+	TigerTeam             *TigerTeamNight            `json:"tigerTeam,omitempty"`         // การตัดสินใจของฝ่ายเสือคืนนี้ (เห็นเฉพาะฝ่ายเสือ)
+	TurnToken             string                     `json:"turnToken,omitempty"`         // โทเคนของตาปัจจุบัน (เห็นเฉพาะเจ้าของตา)
+	TurnEndTime           *Timestamp                 `json:"turnEndTime,omitempty"`       // หมดเวลาของตาปัจจุบัน
+	SettingsChangedAt     *Timestamp                 `json:"settingsChangedAt,omitempty"` // เวลาที่ host เปลี่ยนการตั้งค่าล่าสุด
+	SettingsAcks          map[string]bool            `json:"-"`                           // ผู้เล่นที่รับทราบการตั้งค่าล่าสุดแล้ว
+	NightTraces           []NightTrace               `json:"-"`                           // เหตุผลการตัดสินแต่ละคืน (เปิดเผยหลังจบเกม)
+	ReadyToVote           map[string]bool            `json:"-"`                           // ผู้เล่นที่พร้อมโหวตแล้ว (นับเฉพาะจำนวนให้คนอื่นเห็น)
+	VotesAgainst          map[string][]string        `json:"-"`                           // ชื่อคนที่โหวตใส่แต่ละคนในรอบที่เพิ่งตัดสิน (รอส่งแบบส่วนตัว)
+	PhaseReason           string                     `json:"phaseReason,omitempty"`       // เหตุผลที่เข้าสู่เฟสปัจจุบัน
+	VoteHistory           []Ballot                   `json:"voteHistory,omitempty"`       // บัตรโหวตที่ตัดสินแล้วของทุกวัน
+	VoteOutcomes          []VoteOutcome              `json:"voteOutcomes,omitempty"`      // ผลการตัดสินโหวตของทุกวัน รวมการเสมอ
+	LastVoteOutcome       *VoteOutcome               `json:"-"`                           // ผลโหวตที่เพิ่งตัดสิน (รอประกาศ)
+	HostActionLog         []HostAction               `json:"-"`                           // การกระทำของ host/ผู้ช่วย (เปิดเผยหลังจบเกมและใน admin API)
+	SystemNotices         []*Message                 `json:"-"`                           // ข้อความระบบที่รอส่งให้ทั้งห้อง
+	NightCeilingAt        *Timestamp                 `json:"-"`                           // เส้นตายของทั้งคืน ถ้าคืนยังไม่จบเซิร์ฟเวอร์จะตัดสินเอง
+	ChatSeq               int64                      `json:"-"`                           // seq ของข้อความแชทล่าสุด
+	AbortVote             *AbortVote                 `json:"abortVote,omitempty"`         // การโหวตยกเลิกเกมที่ host ขอ (ระหว่างนี้นาฬิกาหยุด)
+	QuorumPause           *QuorumPause               `json:"quorumPause,omitempty"`       // เกมหยุดเพราะผู้เล่นที่ยังเชื่อมต่อไม่พอ
+	NightModifier         string                     `json:"nightModifier,omitempty"`     // เหตุการณ์พิเศษของคืนนี้ (เช่น blood_moon)
+	Seed                  int64                      `json:"-"`                           // seed ของตัวสุ่มประจำห้อง
+	Rand                  *rand.Rand                 `json:"-"`                           // ตัวสุ่มประจำห้อง (แจกบทบาท ที่นั่ง เหตุการณ์กลางคืน)
+	PhaseStartedAt        *Timestamp                 `json:"-"`                           // เวลาที่เข้าสู่เฟสปัจจุบัน (ใช้ตรวจห้องที่ค้าง)
+	Disconnects           []DisconnectRecord         `json:"-"`                           // การหลุดการเชื่อมต่อล่าสุดของผู้เล่น (admin API)
+	PendingPrompts        map[string][]PendingPrompt `json:"-"`                           // prompt ที่ผู้เล่นแต่ละคนยังไม่ได้ตอบ (ส่งซ้ำเมื่อเชื่อมต่อใหม่)
+	SlowModeSeconds       int                        `json:"slowModeSeconds,omitempty"`   // ระยะห่างขั้นต่ำระหว่างข้อความแชทของแต่ละคน (0 = ปิด)
+	LastChatAt            map[string]time.Time       `json:"-"`                           // เวลาที่ผู้เล่นส่งแชทล่าสุด แยกตามช่อง (key: channel + ":" + player ID)
+	AutoStartAt           *Timestamp                 `json:"autoStartAt,omitempty"`       // เวลาที่เกมจะเริ่มเองเมื่อทุกคนพร้อม (มีเฉพาะตอนนับถอยหลัง)
+	AutoStartHeld         bool                       `json:"-"`                           // host ยกเลิกการนับถอยหลัง ไม่นับใหม่จนกว่าจะมีคนไม่พร้อม
+	ConcealedDeaths       []string                   `json:"-"`                           // ผู้ตายเมื่อคืนที่ยังไม่พบศพ (mystery dawn)
+	BodyRevealAt          *Timestamp                 `json:"bodyRevealAt,omitempty"`      // เวลาที่จะพบศพและประกาศผู้ตาย (มีเฉพาะตอนที่ยังซ่อนอยู่)
+	RevealedDeaths        []string                   `json:"-"`                           // ผู้ตายที่เพิ่งพบศพ (รอประกาศ)
+
+	livingIdx *livingIndex // ผู้เล่นที่ยังมีชีวิต (ดู living.go)
 }
 
 // Message represents a chat message
 type Message struct {
-	ID        string    `json:"id"`
-	RoomCode  string    `json:"roomCode"`
-	PlayerID  string    `json:"playerId"`
-	Username  string    `json:"username"`
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
-	Type      string    `json:"type"` // "chat", "system", "werewolf"
+	ID        string     `json:"id"`
+	Seq       int64      `json:"seq"` // ลำดับข้อความในห้อง เริ่มที่ 1
+	RoomCode  RoomCode   `json:"roomCode"`
+	PlayerID  string     `json:"playerId"`
+	Username  string     `json:"username"`
+	Content   string     `json:"content"`
+	Timestamp Timestamp  `json:"timestamp"`
+	Type      string     `json:"type"`          // one of the Message* types, always set by the server
+	Key       string     `json:"key,omitempty"` // message key of a system line clients may translate
+	Phase     GamePhase  `json:"phase"`
+	EditedAt  *Timestamp `json:"editedAt,omitempty"`
+	Deleted   bool       `json:"deleted,omitempty"`
+	DeletedBy string     `json:"deletedBy,omitempty"` // "author" หรือ "host"
 }
 
 // WSMessage represents a WebSocket message
 type WSMessage struct {
 	Type    string      `json:"type"`
 	Payload interface{} `json:"payload"`
+	Cue     string      `json:"cue,omitempty"` // เสียง/บรรยากาศที่ client เล่นประกอบ event นี้ (ดู CueCatalog)
 }
 
 // Event types
 const (
-	EventJoinRoom        = "join_room"
-	EventLeaveRoom       = "leave_room"
-	EventStartGame       = "start_game"
-	EventPlayerJoined    = "player_joined"
-	EventPlayerLeft      = "player_left"
-	EventGameStarted     = "game_started"
-	EventPhaseChanged    = "phase_changed"
-	EventNightAction     = "night_action"
-	EventSkipAction      = "skip_action" // ข้ามการใช้พลัง
-	EventSkipPhase       = "skip_phase"  // ข้ามเฟส (host only)
-	EventVote            = "vote"
-	EventVoteUpdate      = "vote_update"     // real-time vote update
-	EventVotingComplete  = "voting_complete" // โหวตครบทุกคนแล้ว
-	EventVoteResult      = "vote_result"
-	EventPlayerDied      = "player_died"
-	EventGameEnded       = "game_ended"
-	EventChatMessage     = "chat_message"
-	EventGameStateUpdate = "game_state_update"
-	EventNightRoleChange = "night_role_change" // เปลี่ยน role ที่กำลัง action
-	EventHunterShoot     = "hunter_shoot"      // นายพรานยิงเมื่อตาย
-	EventCurseAction     = "curse_action"      // พญาสมิงสาป
-	EventError           = "error"
+	EventJoinRoom         = "join_room"
+	EventLeaveRoom        = "leave_room"
+	EventStartGame        = "start_game"
+	EventPlayerJoined     = "player_joined"
+	EventPlayerLeft       = "player_left"
+	EventGameStarted      = "game_started"
+	EventPhaseChanged     = "phase_changed"
+	EventNightAction      = "night_action"
+	EventSkipAction       = "skip_action" // ข้ามการใช้พลัง
+	EventSkipPhase        = "skip_phase"  // ข้ามเฟส (host only)
+	EventVote             = "vote"
+	EventVoteUpdate       = "vote_update"     // real-time vote update
+	EventVotingComplete   = "voting_complete" // โหวตครบทุกคนแล้ว
+	EventVoteResult       = "vote_result"
+	EventPlayerDied       = "player_died"
+	EventGameEnded        = "game_ended"
+	EventChatMessage      = "chat_message"
+	EventGameStateUpdate  = "game_state_update"
+	EventNightRoleChange  = "night_role_change"   // เปลี่ยน role ที่กำลัง action
+	EventHunterShoot      = "hunter_shoot"        // นายพรานยิงเมื่อตาย
+	EventHunterPrompt     = "hunter_shoot_prompt" // แจ้งนายพรานที่ตายว่าถึงเวลายิง
+	EventHunterTimeout    = "hunter_timeout"      // หมดเวลายิงของนายพราน เสียสิทธิ์ยิง
+	EventCurseAction      = "curse_action"        // พญาสมิงสาป
+	EventUpdateSettings   = "update_settings"     // host เปลี่ยนการตั้งค่าห้อง
+	EventSettingsChanged  = "settings_changed"
+	EventSettingsAck      = "settings_ack"      // ผู้เล่นรับทราบการตั้งค่าใหม่
+	EventHello            = "hello"             // ประกาศเวอร์ชันโปรโตคอลของ client
+	EventReadyToVote      = "ready_to_vote"     // สลับสถานะพร้อมโหวต (กลางวัน)
+	EventReadyUpdate      = "ready_update"      // จำนวนคนที่พร้อมโหวต
+	EventGrantModerator   = "grant_moderator"   // host แต่งตั้งผู้ช่วย
+	EventRevokeModerator  = "revoke_moderator"  // host ถอดผู้ช่วย
+	EventKickPlayer       = "kick_player"       // เตะผู้เล่นออกจาก lobby
+	EventKicked           = "kicked"            // แจ้งผู้เล่นที่ถูกเตะ
+	EventMutePlayer       = "mute_player"       // ปิดเสียงแชทผู้เล่น
+	EventUnmutePlayer     = "unmute_player"     // เปิดเสียงแชทผู้เล่น
+	EventExtendDiscussion = "extend_discussion" // ขยายเวลากลางวัน
+	EventSetSlowMode      = "set_slow_mode"     // host/ผู้ช่วยเปิดปิด slow mode ของแชท
+	EventSlowModeChanged  = "slow_mode_changed" // แจ้งทั้งห้องว่า slow mode เปลี่ยน
+	EventNominate         = "nominate"          // เสนอชื่อผู้ต้องสงสัย
+	EventNominationUpdate = "nomination_update"
+	EventActionAck        = "action_ack"   // ยืนยันการกระทำที่ส่งซ้ำ
+	EventChooseColor      = "choose_color" // เลือกสี/อวตาร (waiting phase)
+	EventColorChanged     = "color_changed"
+	EventNightSleep       = "night_sleep"    // ยืนยันเข้านอน (โหมด nightSleepConfirmation)
+	EventEditMessage      = "edit_message"   // แก้ไขข้อความแชท
+	EventDeleteMessage    = "delete_message" // ลบข้อความแชท
+	EventMessageEdited    = "message_edited"
+	EventMessageDeleted   = "message_deleted"
+	EventChatHistory      = "chat_history"      // ประวัติแชทสำหรับคนที่เพิ่งเชื่อมต่อ
+	EventPlayersUpdate    = "players_update"    // รายชื่อ/สถานะผู้เล่นเปลี่ยน
+	EventPhaseUpdate      = "phase_update"      // เฟส/เวลา/รอบเปลี่ยน
+	EventVotesUpdate      = "votes_update"      // ผลโหวตเปลี่ยน
+	EventTigerTeamUpdate  = "tiger_team_update" // ส่งเฉพาะฝ่ายเสือ
+	EventYourTurn         = "your_turn"         // แจ้งเจ้าของตาพร้อม turn token
+	EventTurnTimeout      = "turn_timeout"      // ตาปัจจุบันหมดเวลา ข้ามให้อัตโนมัติ
+	EventVotesAgainstYou  = "votes_against_you" // บอกผู้เล่นว่าใครโหวตใส่ (เมื่อเปิดเผยผลโหวต)
+	EventNightRecap       = "night_recap"       // สรุปผลคืนนี้ส่วนตัวสำหรับแต่ละบทบาท
+	EventGameSummary      = "game_summary"      // สรุปเกมที่จบแล้วสำหรับผู้ชม
+	EventDegradedMode     = "degraded_mode"     // การเชื่อมต่อช้า ส่งเฉพาะข้อมูลจำเป็น/กลับมาส่งปกติ
+	EventRoomHeartbeat    = "room_heartbeat"    // ห้องยังอยู่ดี ส่งเมื่อเงียบนานเกินรอบ (จำนวนผู้เล่น/ผู้ชม/เฟส/seq)
+	EventPing             = "ping"              // client วัด latency (แนบเวลาของ client และ RTT ที่วัดได้ล่าสุด)
+	EventPong             = "pong"              // ตอบ ping ทันที พร้อมเวลาของ client และเวลาเซิร์ฟเวอร์
+	EventError            = "error"
+)
+
+// Role and composition events
+const (
+	EventCompositionInvalid         = "composition_invalid"          // แจ้ง host ว่าชุดบทบาทไม่ตรงกับจำนวนผู้เล่น
+	EventCompositionOutdated        = "composition_outdated"         // แจ้ง host ว่าผู้เล่นเกินชุดบทบาทที่ตั้งไว้
+	EventAcceptSuggestedComposition = "accept_suggested_composition" // host ใช้ชุดบทบาทที่แนะนำ
+	EventRoleAssigned               = "role_assigned"                // บทบาทของผู้เล่นพร้อมกติกา (ส่วนตัว)
+)
+
+// Abort events
+const (
+	EventAbortGame      = "abort_game"      // host ขอยกเลิกเกม
+	EventAbortRequested = "abort_requested" // ถามความเห็นผู้เล่นที่มีสิทธิ์โหวต
+	EventAbortVote      = "abort_vote"      // ตอบรับ/ปฏิเสธการยกเลิก ({approve})
+	EventAbortTimeout   = "abort_timeout"   // หมดเวลาโหวตยกเลิก
+	EventAbortResult    = "abort_result"    // ผลการโหวตยกเลิก
+)
+
+// Rematch events
+const (
+	EventRematch       = "rematch"        // host เปิด lobby ใหม่หลังเกมจบ
+	EventRematchRoster = "rematch_roster" // ใครได้อยู่ต่อและใครถูกตัดออกเพราะหลุดนานเกินไป ({kept, dropped})
 )
+
+// Join approval events
+const (
+	EventJoinRequest         = "join_request"          // มีคนขอเข้าห้อง (ส่งถึง host)
+	EventRespondJoinRequest  = "respond_join_request"  // host อนุมัติ/ปฏิเสธ ({requestId, approve})
+	EventJoinRequestResolved = "join_request_resolved" // ผลของคำขอเข้าห้อง (ส่งถึง host)
+)
+
+// Auto-start events
+const (
+	EventSetReady           = "set_ready"            // ผู้เล่นพร้อม/ไม่พร้อมใน lobby ({ready})
+	EventAutoStartCountdown = "auto_start_countdown" // ทุกคนพร้อม เริ่มนับถอยหลังเริ่มเกมเอง ({startsAt, seconds})
+	EventAutoStartCancelled = "auto_start_cancelled" // หยุดนับถอยหลัง ({reason})
+	EventCancelAutoStart    = "cancel_auto_start"    // host ยกเลิกการนับถอยหลัง
+)
+
+// Mystery dawn events
+const (
+	EventRevealBody = "reveal_body" // host เปิดเผยผู้ตายเมื่อคืนก่อนหมดเวลา
+)
+
+// Quorum events
+const (
+	EventQuorumLost     = "quorum_lost"     // ผู้เล่นที่ยังเชื่อมต่อไม่พอ เกมหยุดรอ
+	EventQuorumRestored = "quorum_restored" // ผู้เล่นกลับมาครบ เกมเดินต่อ
+	EventQuorumTimeout  = "quorum_timeout"  // หมดเวลารอ เกมจบแบบสละสิทธิ์
+)
+
+// EventPhaseExtended announces time added to the current phase
+const EventPhaseExtended = "phase_extended" // ต่อเวลาเฟสปัจจุบัน (เช่น ผู้เล่นกลับมาเชื่อมต่อตอนใกล้หมดเวลาโหวต)
+
+// Lobby browser events, sent on the lobby connection
+const (
+	EventRoomList    = "room_list"    // รายชื่อห้องสาธารณะทั้งหมด (ตอนเชื่อมต่อ)
+	EventRoomCreated = "room_created" // มีห้องสาธารณะใหม่
+	EventRoomUpdated = "room_updated" // จำนวนผู้เล่น/เฟสของห้องเปลี่ยน (ไม่เกินวินาทีละครั้งต่อห้อง)
+	EventRoomRemoved = "room_removed" // ห้องถูกลบหรือไม่เป็นสาธารณะแล้ว ({code})
+)
+
+// Server announcements, sent to every room and the lobby
+const (
+	EventServerAnnouncement        = "server_announcement"         // ประกาศจากผู้ดูแลเซิร์ฟเวอร์ (เช่น ปิดปรับปรุง)
+	EventServerAnnouncementCleared = "server_announcement_cleared" // ยกเลิกประกาศที่แสดงอยู่
+)
+
+// Cues name the sound or flavor a client may play with an event. The server
+// only labels events; which asset a cue plays is up to the client.
+const (
+	CueGameStart    = "game_start"    // เกมเริ่ม
+	CueNightFalls   = "night_falls"   // ค่ำลง หมู่บ้านเข้านอน
+	CueDayBreaks    = "day_breaks"    // รุ่งเช้าโดยไม่มีใครตาย
+	CueDeathReveal  = "death_reveal"  // ประกาศผู้ตาย (รุ่งเช้าหรือหลังประหาร)
+	CueVoteDrums    = "vote_drums"    // เริ่มโหวต
+	CueVoteClosed   = "vote_closed"   // โหวตครบทุกคนแล้ว
+	CueGunshot      = "gunshot"       // นายพรานยิงก่อนตาย
+	CueTimeExtended = "time_extended" // ต่อเวลาเฟส
+	CueGameOver     = "game_over"     // เกมจบ
+	CueRoleReveal   = "role_reveal"   // เปิดดูบทบาทของตัวเอง (ส่วนตัว)
+	CueYourTurn     = "your_turn"     // ถึงตาใช้พลังกลางคืน (ส่วนตัว)
+	CueTigerHowl    = "tiger_howl"    // ถึงตาฝ่ายเสือออกล่า (ส่วนตัว)
+	CueHunterAim    = "hunter_aim"    // นายพรานเล็งปืน (ส่วนตัว)
+)
+
+// CueInfo describes one cue for clients preloading assets
+type CueInfo struct {
+	Cue     string   `json:"cue"`
+	Events  []string `json:"events"`  // events that may carry it
+	Private bool     `json:"private"` // only ever sent to the player it concerns
+}
+
+// CueCatalog lists every cue the server sends
+var CueCatalog = []CueInfo{
+	{Cue: CueGameStart, Events: []string{EventGameStarted}},
+	{Cue: CueNightFalls, Events: []string{EventPhaseUpdate}},
+	{Cue: CueDayBreaks, Events: []string{EventPhaseUpdate}},
+	{Cue: CueDeathReveal, Events: []string{EventPhaseUpdate, EventPlayerDied}},
+	{Cue: CueVoteDrums, Events: []string{EventPhaseUpdate}},
+	{Cue: CueVoteClosed, Events: []string{EventVotingComplete}},
+	{Cue: CueGunshot, Events: []string{EventPlayersUpdate}},
+	{Cue: CueTimeExtended, Events: []string{EventPhaseExtended}},
+	{Cue: CueGameOver, Events: []string{EventGameEnded}},
+	{Cue: CueRoleReveal, Events: []string{EventRoleAssigned}, Private: true},
+	{Cue: CueYourTurn, Events: []string{EventYourTurn}, Private: true},
+	{Cue: CueTigerHowl, Events: []string{EventYourTurn}, Private: true},
+	{Cue: CueHunterAim, Events: []string{EventHunterPrompt}, Private: true},
+}
+
+// EventCues are the cues events always carry. Events missing here carry
+// none, or one their sender picks from what happened.
+var EventCues = map[string]string{
+	EventGameStarted:    CueGameStart,
+	EventVotingComplete: CueVoteClosed,
+	EventPhaseExtended:  CueTimeExtended,
+	EventGameEnded:      CueGameOver,
+	EventPlayerDied:     CueDeathReveal,
+	EventRoleAssigned:   CueRoleReveal,
+	EventYourTurn:       CueYourTurn,
+	EventHunterPrompt:   CueHunterAim,
+}
+
+// PhaseCue is the cue for entering phase, or for a transition that revealed
+// a death. Entering the ended phase has none; game_ended carries it.
+func PhaseCue(phase GamePhase, revealedDeath bool) string {
+	if revealedDeath {
+		return CueDeathReveal
+	}
+	switch phase {
+	case PhaseNight:
+		return CueNightFalls
+	case PhaseDay:
+		return CueDayBreaks
+	case PhaseVoting:
+		return CueVoteDrums
+	}
+	return ""
+}
@@ -0,0 +1,17 @@
+package models
+
+import "strings"
+
+// RoomCode identifies a room. Codes are case-insensitive for players but
+// only ever stored and compared in their canonical upper-case form, so
+// every code from outside the server goes through NormalizeRoomCode.
+type RoomCode string
+
+// NormalizeRoomCode returns the canonical form of a code a client sent
+func NormalizeRoomCode(code string) RoomCode {
+	return RoomCode(strings.ToUpper(strings.TrimSpace(code)))
+}
+
+func (c RoomCode) String() string {
+	return string(c)
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// timestampLayout is RFC3339 with exactly three fractional digits. Times are
+// converted to UTC first, so the zone is always written as Z.
+const timestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// unzonedLayouts are read as UTC. Nothing the server writes lacks a zone,
+// but hand-edited snapshots and older tools sometimes do.
+var unzonedLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// Timestamp is a time.Time that every API and snapshot writes the same way:
+// RFC3339 in UTC with millisecond precision, e.g. "2024-05-01T12:00:00.000Z",
+// whatever zone the server runs in. Reading accepts any RFC3339 time,
+// including the local-zone offsets older snapshots were written with, and
+// times without a zone, which are taken as UTC.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps t
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// TimestampPtr wraps t for an optional field
+func TimestampPtr(t time.Time) *Timestamp {
+	return &Timestamp{Time: t}
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.UTC().Format(timestampLayout) + `"`), nil
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("timestamp %s is not a string", data)
+	}
+	value := string(data[1 : len(data)-1])
+
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		t.Time = parsed.UTC()
+		return nil
+	}
+	for _, layout := range unzonedLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("timestamp %q is not RFC3339", value)
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// bangkok is a zone the server might run in, seven hours ahead of UTC
+var bangkok = time.FixedZone("ICT", 7*60*60)
+
+func TestTimestampMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"utc", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), `"2024-05-01T12:00:00.000Z"`},
+		{"local zone", time.Date(2024, 5, 1, 19, 0, 0, 0, bangkok), `"2024-05-01T12:00:00.000Z"`},
+		{"past midnight locally", time.Date(2024, 5, 2, 3, 30, 0, 0, bangkok), `"2024-05-01T20:30:00.000Z"`},
+		{"milliseconds", time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC), `"2024-05-01T12:00:00.123Z"`},
+		{"zero", time.Time{}, `"0001-01-01T00:00:00.000Z"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(NewTimestamp(tc.at))
+			if err != nil || string(data) != tc.want {
+				t.Errorf("marshal = %s, %v; want %s", data, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestTimestampUnmarshal(t *testing.T) {
+	noon := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  time.Time
+		fails bool
+	}{
+		{"utc", `"2024-05-01T12:00:00.000Z"`, noon, false},
+		{"offset", `"2024-05-01T19:00:00+07:00"`, noon, false},
+		{"nanoseconds", `"2024-05-01T19:00:00.000000001+07:00"`, noon.Add(time.Nanosecond), false},
+		{"no zone", `"2024-05-01T12:00:00"`, noon, false},
+		{"no zone, spaced", `"2024-05-01 12:00:00.5"`, noon.Add(500 * time.Millisecond), false},
+		{"garbage", `"yesterday"`, time.Time{}, true},
+		{"not a string", `1714564800`, time.Time{}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got Timestamp
+			err := json.Unmarshal([]byte(tc.input), &got)
+			if tc.fails {
+				if err == nil {
+					t.Errorf("%s was read as %v", tc.input, got)
+				}
+				return
+			}
+			if err != nil || !got.Equal(tc.want) || got.Location() != time.UTC {
+				t.Errorf("unmarshal %s = %v, %v; want %v in UTC", tc.input, got, err, tc.want)
+			}
+		})
+	}
+
+	// null leaves an optional time unset
+	var optional struct {
+		At *Timestamp `json:"at"`
+	}
+	if err := json.Unmarshal([]byte(`{"at":null}`), &optional); err != nil || optional.At != nil {
+		t.Errorf("null = %v, %v; want nil", optional.At, err)
+	}
+}
+
+func TestSerializedTimesAreUTC(t *testing.T) {
+	local := time.Date(2024, 5, 1, 19, 0, 0, 250000000, bangkok)
+	const want = "2024-05-01T12:00:00.250Z"
+
+	room := &GameRoom{
+		Code:         "ABC123",
+		Phase:        PhaseDay,
+		CreatedAt:    NewTimestamp(local),
+		StartedAt:    TimestampPtr(local),
+		PhaseEndTime: TimestampPtr(local),
+		TurnEndTime:  TimestampPtr(local),
+		Players: map[string]*Player{
+			"p1": {ID: "p1", Username: "Alice", IsAlive: true, JoinedAt: NewTimestamp(local)},
+		},
+	}
+	for _, tc := range []struct {
+		name   string
+		value  interface{}
+		fields []string
+	}{
+		{"room", NewRoomPublic(room), []string{"createdAt", "startedAt", "phaseEndTime", "turnEndTime"}},
+		{"player", NewPlayerPublic(room.Players["p1"]), []string{"joinedAt"}},
+		{"message", Message{ID: "m1", Timestamp: NewTimestamp(local), EditedAt: TimestampPtr(local)}, []string{"timestamp", "editedAt"}},
+		{"invite", Invite{Token: "t", CreatedAt: NewTimestamp(local), ExpiresAt: TimestampPtr(local), UsedAt: TimestampPtr(local)}, []string{"createdAt", "expiresAt", "usedAt"}},
+		{"join request", JoinRequest{ID: "r1", RequestedAt: NewTimestamp(local), ExpiresAt: NewTimestamp(local)}, []string{"requestedAt", "expiresAt"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			var fields map[string]interface{}
+			json.Unmarshal(data, &fields)
+			for _, field := range tc.fields {
+				if fields[field] != want {
+					t.Errorf("%s = %v, want %s", field, fields[field], want)
+				}
+			}
+		})
+	}
+}
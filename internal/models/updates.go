@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// Scoped updates carry one slice of a room so clients can re-render only
+// what changed. Each is built from an already-personalized view of the room;
+// full snapshots (game_state_update) remain for connect and resync.
+
+// PlayersUpdate is the roster: who is in the room, alive, ready, and their colors
+type PlayersUpdate struct {
+	HostID      string       `json:"hostId"`
+	Players     Roster       `json:"players"`
+	Permissions *Permissions `json:"permissions,omitempty"`
+}
+
+// PhaseUpdate is the game clock: phase, round, timers and whose turn it is
+type PhaseUpdate struct {
+	Phase              GamePhase    `json:"phase"`
+	Round              int          `json:"round"`
+	PhaseEndTime       *Timestamp   `json:"phaseEndTime,omitempty"`
+	DayStartedAt       *Timestamp   `json:"dayStartedAt,omitempty"`
+	CurrentNightRole   Role         `json:"currentNightRole,omitempty"`
+	TurnEndTime        *Timestamp   `json:"turnEndTime,omitempty"`
+	NightEntry         *NightEntry  `json:"nightEntry,omitempty"`
+	Permissions        *Permissions `json:"permissions,omitempty"`
+	WaitingHunterShoot bool         `json:"waitingHunterShoot,omitempty"`
+	DeadHunterID       string       `json:"deadHunterID,omitempty"`
+	Nominees           []string     `json:"nominees,omitempty"`
+	WinningTeam        string       `json:"winningTeam,omitempty"`
+	TigersRemaining    *int         `json:"tigersRemaining,omitempty"`
+}
+
+// NightEntry tells one viewer what to expect of the night: whether they will
+// be prompted and how long the night can last at most. Only viewers who may
+// already know every role are told the order of the turns.
+type NightEntry struct {
+	WillAct            bool   `json:"willAct"`
+	MaxDurationSeconds int    `json:"maxDurationSeconds,omitempty"` // omitted when nights have no ceiling
+	Order              []Role `json:"order,omitempty"`
+}
+
+// Permissions tells one player which actions the server would accept from
+// them right now, so clients enable controls from it instead of guessing.
+// Limits that lapse on their own (slow mode, the minimum discussion time,
+// the settings acknowledgement wait) are left to the action's error.
+type Permissions struct {
+	CanVote           bool            `json:"canVote"`
+	CanChat           map[string]bool `json:"canChat"` // by channel: chat, dead
+	CanAct            bool            `json:"canAct"`  // the night turn is theirs
+	CanShoot          bool            `json:"canShoot"`
+	CanSkipPhase      bool            `json:"canSkipPhase"` // end the phase before its timer
+	CanStartGame      bool            `json:"canStartGame"`
+	CanManageSettings bool            `json:"canManageSettings"`
+	CanRevealBody     bool            `json:"canRevealBody"` // reveal a mystery dawn's victim early
+}
+
+// VotesUpdate is the current tallies and nominations
+type VotesUpdate struct {
+	VoteResults map[string]int `json:"voteResults"`
+	Nominations []Nomination   `json:"nominations,omitempty"`
+	// VoteLockAt is when ballots freeze, so clients can grey out their
+	// buttons on time; VoteLocked says the lock already holds
+	VoteLockAt *Timestamp `json:"voteLockAt,omitempty"`
+	VoteLocked bool       `json:"voteLocked"`
+}
+
+// NewPlayersUpdate extracts the roster from a view of the room
+func NewPlayersUpdate(view *GameRoom) PlayersUpdate {
+	return PlayersUpdate{
+		HostID:      view.HostID,
+		Players:     NewRoster(view),
+		Permissions: view.Permissions,
+	}
+}
+
+// NewPhaseUpdate extracts the phase state from a view of the room
+func NewPhaseUpdate(view *GameRoom) PhaseUpdate {
+	return PhaseUpdate{
+		Phase:              view.Phase,
+		Round:              view.Round,
+		PhaseEndTime:       view.PhaseEndTime,
+		DayStartedAt:       view.DayStartedAt,
+		CurrentNightRole:   view.CurrentNightRole,
+		TurnEndTime:        view.TurnEndTime,
+		NightEntry:         view.NightEntry,
+		Permissions:        view.Permissions,
+		WaitingHunterShoot: view.WaitingHunterShoot,
+		DeadHunterID:       view.DeadHunterID,
+		Nominees:           view.Nominees,
+		WinningTeam:        view.WinningTeam,
+		TigersRemaining:    view.TigersRemaining,
+	}
+}
+
+// NewVotesUpdate extracts the tallies from a view of the room
+func NewVotesUpdate(view *GameRoom, now time.Time) VotesUpdate {
+	votes := view.VoteResults
+	if votes == nil {
+		votes = map[string]int{}
+	}
+	lockAt := view.VoteLockAt()
+	return VotesUpdate{
+		VoteResults: votes,
+		Nominations: view.Nominations,
+		VoteLockAt:  lockAt,
+		VoteLocked:  lockAt != nil && !now.Before(lockAt.Time),
+	}
+}
+
+// VoteLockAt returns when the current vote's ballots freeze, or nil when
+// the room is not voting or has no lock. It follows the phase deadline, so
+// time added to the vote moves the lock with it.
+func (r *GameRoom) VoteLockAt() *Timestamp {
+	if r.Phase != PhaseVoting || r.PhaseEndTime == nil || r.Settings.VoteLockSeconds <= 0 {
+		return nil
+	}
+	return TimestampPtr(r.PhaseEndTime.Add(-time.Duration(r.Settings.VoteLockSeconds) * time.Second))
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Validate checks the room's cross-field invariants and returns one
+// description per violation. A consistent room returns nil.
+func (r *GameRoom) Validate() []string {
+	var problems []string
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(r.Players) > 0 && r.Players[r.HostID] == nil {
+		fail("host %q is not a player in the room", r.HostID)
+	}
+
+	if r.MaxPlayers > 0 && len(r.Players) > r.MaxPlayers {
+		fail("%d players in a room capped at %d", len(r.Players), r.MaxPlayers)
+	}
+
+	if r.WaitingHunterShoot {
+		hunter := r.Players[r.DeadHunterID]
+		switch {
+		case hunter == nil:
+			fail("waiting for hunter %q who is not in the room", r.DeadHunterID)
+		case hunter.Role != RoleHunter:
+			fail("waiting for hunter %q whose role is %q", r.DeadHunterID, hunter.Role)
+		case hunter.IsAlive:
+			fail("waiting for hunter %q who is still alive", r.DeadHunterID)
+		}
+	} else if r.DeadHunterID != "" {
+		fail("dead hunter %q set without a pending shot", r.DeadHunterID)
+	}
+
+	if r.CurrentNightRole != "" {
+		if r.Phase != PhaseNight {
+			fail("current night role %q set during %q", r.CurrentNightRole, r.Phase)
+		} else if !containsRole(r.NightActionOrder, r.CurrentNightRole) {
+			fail("current night role %q is not in the night order %v", r.CurrentNightRole, r.NightActionOrder)
+		}
+	}
+
+	if r.Phase == PhaseDefense && len(r.Nominees) == 0 {
+		fail("defense phase without nominees")
+	}
+
+	ballots := make(map[string]int)
+	for _, player := range r.Players {
+		if player.VotedFor != "" && player.VotedFor != VoteAbstain {
+			ballots[player.VotedFor]++
+		}
+	}
+	for targetID, count := range r.VoteResults {
+		if ballots[targetID] != count {
+			fail("vote tally for %q is %d but %d ballots were cast", targetID, count, ballots[targetID])
+		}
+	}
+	for targetID, count := range ballots {
+		if _, tallied := r.VoteResults[targetID]; !tallied {
+			fail("%d ballots for %q are missing from the tally", count, targetID)
+		}
+	}
+
+	for field, playerID := range map[string]string{
+		"cursedPlayer":     r.CursedPlayer,
+		"tigerTarget":      r.TigerTarget,
+		"hunterProtection": r.HunterProtection,
+		"shamanVision":     r.ShamanVision,
+	} {
+		if playerID != "" && r.Players[playerID] == nil {
+			fail("%s %q is not a player in the room", field, playerID)
+		}
+	}
+
+	if len(r.ConcealedDeaths) > 0 && r.Phase != PhaseDay {
+		fail("deaths still concealed during %q", r.Phase)
+	}
+	for _, playerID := range r.ConcealedDeaths {
+		if player := r.Players[playerID]; player != nil && player.IsAlive {
+			fail("concealed death %q is still alive", playerID)
+		}
+	}
+
+	problems = append(problems, r.checkLiving()...)
+
+	sort.Strings(problems)
+	return problems
+}
+
+func containsRole(roles []Role, role Role) bool {
+	for _, candidate := range roles {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+package models
+
+import "encoding/json"
+
+// Wire types are what clients are sent. GameRoom and Player are the
+// server's own records, and the handlers never marshal them: every REST
+// response and websocket payload converts a view of the room, already
+// redacted for its viewer, into one of these. Fields a view only reveals to
+// some viewers are omitted when it does not.
+
+// PlayerPublic is a player as the rest of the room sees them. Role,
+// IsCursed, LastProtected, VotedFor and ActionHistory are only set when the
+// view reveals them to its viewer, e.g. a tiger seeing the other tigers or
+// anyone once the game has ended.
+type PlayerPublic struct {
+	ID                string         `json:"id"`
+	Username          string         `json:"username"`
+	Role              Role           `json:"role,omitempty"`
+	IsAlive           bool           `json:"isAlive"`
+	DeathCause        string         `json:"deathCause,omitempty"`
+	IsReady           bool           `json:"isReady"`
+	IsBot             bool           `json:"isBot,omitempty"`
+	IsConnected       bool           `json:"isConnected"`
+	IsModerator       bool           `json:"isModerator,omitempty"`
+	IsMuted           bool           `json:"isMuted,omitempty"`
+	ColorSlot         int            `json:"colorSlot"`
+	IsCursed          bool           `json:"isCursed,omitempty"`
+	LastProtected     string         `json:"lastProtected,omitempty"`
+	HasActedThisNight bool           `json:"hasActedThisNight,omitempty"`
+	VotedFor          string         `json:"votedFor,omitempty"`
+	RoomCode          RoomCode       `json:"roomCode"`
+	Seat              int            `json:"seat,omitempty"`
+	JoinedAt          Timestamp      `json:"joinedAt"`
+	ActionHistory     []ActionRecord `json:"actionHistory,omitempty"`
+}
+
+// PlayerPrivate is the viewer's own player: their public record plus what
+// only they are ever told
+type PlayerPrivate struct {
+	PlayerPublic
+	Abilities []AbilityStatus `json:"abilities,omitempty"`
+}
+
+// Roster is every player as one viewer sees them. It marshals as a single
+// object keyed by player ID, the viewer's own record among the others, so
+// clients find themselves where they find everyone else.
+type Roster struct {
+	Players map[string]PlayerPublic // everyone but the viewer
+	Me      *PlayerPrivate          // the viewer; nil in the public view
+}
+
+func (r Roster) MarshalJSON() ([]byte, error) {
+	players := make(map[string]interface{}, len(r.Players)+1)
+	for id, player := range r.Players {
+		players[id] = player
+	}
+	if r.Me != nil {
+		players[r.Me.ID] = r.Me
+	}
+	return json.Marshal(players)
+}
+
+// RoomState is what every viewer is told about a room besides its players.
+// The night's choices and the tiger team's deliberation are only set for
+// the roles that made them, and for everyone once the game has ended.
+type RoomState struct {
+	Code                  RoomCode          `json:"code"`
+	Aliases               map[string]string `json:"aliases,omitempty"`
+	HostID                string            `json:"hostId"`
+	Phase                 GamePhase         `json:"phase"`
+	Round                 int               `json:"round"`
+	NightNumber           int               `json:"nightNumber,omitempty"`
+	MaxPlayers            int               `json:"maxPlayers"`
+	CreatedAt             Timestamp         `json:"createdAt"`
+	StartedAt             *Timestamp        `json:"startedAt,omitempty"`
+	VoteResults           map[string]int    `json:"voteResults,omitempty"`
+	HunterProtection      string            `json:"hunterProtection,omitempty"`
+	TigerTarget           string            `json:"tigerTarget,omitempty"`
+	ShamanVision          string            `json:"shamanVision,omitempty"`
+	CursedPlayer          string            `json:"cursedPlayer,omitempty"`
+	PhaseEndTime          *Timestamp        `json:"phaseEndTime,omitempty"`
+	DayStartedAt          *Timestamp        `json:"dayStartedAt,omitempty"`
+	NightActionsCompleted map[string]bool   `json:"nightActionsCompleted,omitempty"`
+	NightActionsRequired  int               `json:"nightActionsRequired,omitempty"`
+	CurrentNightRole      Role              `json:"currentNightRole,omitempty"`
+	NightEntry            *NightEntry       `json:"nightEntry,omitempty"`
+	WaitingHunterShoot    bool              `json:"waitingHunterShoot,omitempty"`
+	DeadHunterID          string            `json:"deadHunterID,omitempty"`
+	HunterShotEndsAt      *Timestamp        `json:"hunterShotEndsAt,omitempty"`
+	WinningTeam           string            `json:"winningTeam,omitempty"`
+	TigersRemaining       *int              `json:"tigersRemaining,omitempty"`
+	Settings              RoomSettings      `json:"settings"`
+	Nominations           []Nomination      `json:"nominations,omitempty"`
+	Nominees              []string          `json:"nominees,omitempty"`
+	TigerTeam             *TigerTeamNight   `json:"tigerTeam,omitempty"`
+	TurnEndTime           *Timestamp        `json:"turnEndTime,omitempty"`
+	SettingsChangedAt     *Timestamp        `json:"settingsChangedAt,omitempty"`
+	PhaseReason           string            `json:"phaseReason,omitempty"`
+	VoteHistory           []Ballot          `json:"voteHistory,omitempty"`
+	VoteOutcomes          []VoteOutcome     `json:"voteOutcomes,omitempty"`
+	AbortVote             *AbortVote        `json:"abortVote,omitempty"`
+	QuorumPause           *QuorumPause      `json:"quorumPause,omitempty"`
+	NightModifier         string            `json:"nightModifier,omitempty"`
+	SlowModeSeconds       int               `json:"slowModeSeconds,omitempty"`
+	AutoStartAt           *Timestamp        `json:"autoStartAt,omitempty"`
+	BodyRevealAt          *Timestamp        `json:"bodyRevealAt,omitempty"`
+}
+
+// RoomPublic is the room as anyone outside it sees it: the public view,
+// and the whole board once the game has ended
+type RoomPublic struct {
+	RoomState
+	Players map[string]PlayerPublic `json:"players"`
+}
+
+// RoomForViewer is the room as one of its players sees it: the room,
+// everyone as they know them, what they may do right now and, on their
+// turn, its token
+type RoomForViewer struct {
+	RoomState
+	Players     Roster       `json:"players"`
+	Permissions *Permissions `json:"permissions,omitempty"`
+	TurnToken   string       `json:"turnToken,omitempty"`
+}
+
+// NewPlayerPublic converts a player from a view of the room
+func NewPlayerPublic(player *Player) PlayerPublic {
+	return PlayerPublic{
+		ID:                player.ID,
+		Username:          player.Username,
+		Role:              player.Role,
+		IsAlive:           player.IsAlive,
+		DeathCause:        player.DeathCause,
+		IsReady:           player.IsReady,
+		IsBot:             player.IsBot,
+		IsConnected:       player.IsConnected,
+		IsModerator:       player.IsModerator,
+		IsMuted:           player.IsMuted,
+		ColorSlot:         player.ColorSlot,
+		IsCursed:          player.IsCursed,
+		LastProtected:     player.LastProtected,
+		HasActedThisNight: player.HasActedThisNight,
+		VotedFor:          player.VotedFor,
+		RoomCode:          player.RoomCode,
+		Seat:              player.Seat,
+		JoinedAt:          player.JoinedAt,
+		ActionHistory:     player.ActionHistory,
+	}
+}
+
+// NewPlayerPrivate converts the viewer's own player from their view
+func NewPlayerPrivate(player *Player) PlayerPrivate {
+	return PlayerPrivate{
+		PlayerPublic: NewPlayerPublic(player),
+		Abilities:    player.AbilityStatus,
+	}
+}
+
+// NewRoster converts the players of a view, setting the viewer's own apart
+func NewRoster(view *GameRoom) Roster {
+	roster := Roster{Players: make(map[string]PlayerPublic, len(view.Players))}
+	for id, player := range view.Players {
+		if id == view.ViewerID {
+			me := NewPlayerPrivate(player)
+			roster.Me = &me
+			continue
+		}
+		roster.Players[id] = NewPlayerPublic(player)
+	}
+	return roster
+}
+
+// NewRoomPublic converts the public view of a room
+func NewRoomPublic(view *GameRoom) RoomPublic {
+	players := make(map[string]PlayerPublic, len(view.Players))
+	for id, player := range view.Players {
+		players[id] = NewPlayerPublic(player)
+	}
+	return RoomPublic{RoomState: newRoomState(view), Players: players}
+}
+
+// NewRoomForViewer converts one player's view of a room
+func NewRoomForViewer(view *GameRoom) RoomForViewer {
+	return RoomForViewer{
+		RoomState:   newRoomState(view),
+		Players:     NewRoster(view),
+		Permissions: view.Permissions,
+		TurnToken:   view.TurnToken,
+	}
+}
+
+// NewRoomWire converts a view into what its viewer is sent: a RoomForViewer
+// for a player's view, a RoomPublic for the public one
+func NewRoomWire(view *GameRoom) interface{} {
+	if view.Players[view.ViewerID] == nil {
+		return NewRoomPublic(view)
+	}
+	return NewRoomForViewer(view)
+}
+
+func newRoomState(view *GameRoom) RoomState {
+	return RoomState{
+		Code:                  view.Code,
+		Aliases:               view.Aliases,
+		HostID:                view.HostID,
+		Phase:                 view.Phase,
+		Round:                 view.Round,
+		NightNumber:           view.NightNumber,
+		MaxPlayers:            view.MaxPlayers,
+		CreatedAt:             view.CreatedAt,
+		StartedAt:             view.StartedAt,
+		VoteResults:           view.VoteResults,
+		HunterProtection:      view.HunterProtection,
+		TigerTarget:           view.TigerTarget,
+		ShamanVision:          view.ShamanVision,
+		CursedPlayer:          view.CursedPlayer,
+		PhaseEndTime:          view.PhaseEndTime,
+		DayStartedAt:          view.DayStartedAt,
+		NightActionsCompleted: view.NightActionsCompleted,
+		NightActionsRequired:  view.NightActionsRequired,
+		CurrentNightRole:      view.CurrentNightRole,
+		NightEntry:            view.NightEntry,
+		WaitingHunterShoot:    view.WaitingHunterShoot,
+		DeadHunterID:          view.DeadHunterID,
+		HunterShotEndsAt:      view.HunterShotEndsAt,
+		WinningTeam:           view.WinningTeam,
+		TigersRemaining:       view.TigersRemaining,
+		Settings:              view.Settings,
+		Nominations:           view.Nominations,
+		Nominees:              view.Nominees,
+		TigerTeam:             view.TigerTeam,
+		TurnEndTime:           view.TurnEndTime,
+		SettingsChangedAt:     view.SettingsChangedAt,
+		PhaseReason:           view.PhaseReason,
+		VoteHistory:           view.VoteHistory,
+		VoteOutcomes:          view.VoteOutcomes,
+		AbortVote:             view.AbortVote,
+		QuorumPause:           view.QuorumPause,
+		NightModifier:         view.NightModifier,
+		SlowModeSeconds:       view.SlowModeSeconds,
+		AutoStartAt:           view.AutoStartAt,
+		BodyRevealAt:          view.BodyRevealAt,
+	}
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// wireView is the night view p1, a tiger, has of a room of three: p2 is
+// alive and unknown to them, p3 was killed on the first night
+func wireView() *GameRoom {
+	at := func(minute int) Timestamp { return NewTimestamp(time.Date(2024, 5, 1, 12, minute, 0, 0, time.UTC)) }
+	started, phaseEnd := at(1), at(9)
+	remaining := 1
+
+	return &GameRoom{
+		Code:        "ABC123",
+		HostID:      "p1",
+		Phase:       PhaseNight,
+		Round:       2,
+		NightNumber: 2,
+		MaxPlayers:  8,
+		CreatedAt:   at(0),
+		StartedAt:   &started,
+		TigerTarget: "p2",
+		// Views never carry these, and the wire structs never send them
+		KilledTonight:    "p2",
+		NightActionOrder: []Role{RoleTiger},
+		PhaseEndTime:     &phaseEnd,
+		CurrentNightRole: RoleTiger,
+		Settings:         DefaultRoomSettings(),
+		ViewerID:         "p1",
+		TurnToken:        "turn-1",
+		Permissions:      &Permissions{CanChat: map[string]bool{"chat": true}, CanAct: true},
+		Players: map[string]*Player{
+			"p1": {
+				ID: "p1", Username: "Ann", Role: RoleTiger, IsAlive: true, IsConnected: true,
+				ColorSlot: 1, RoomCode: "ABC123", Seat: 1, JoinedAt: at(0),
+				ActionHistory: []ActionRecord{{Round: 1, Phase: PhaseNight, ActionType: ActionKill, TargetID: "p3", TargetUsername: "Cy"}},
+				SessionToken:  "secret-p1",
+				AbilityStatus: []AbilityStatus{{Name: AbilityCurse, Remaining: &remaining}},
+			},
+			"p2": {
+				ID: "p2", Username: "Bo", IsAlive: true, IsReady: true, IsConnected: true,
+				ColorSlot: 2, RoomCode: "ABC123", Seat: 2, JoinedAt: at(0), SessionToken: "secret-p2",
+			},
+			"p3": {
+				ID: "p3", Username: "Cy", DeathCause: DeathByTiger,
+				ColorSlot: 3, RoomCode: "ABC123", Seat: 3, JoinedAt: at(0), SessionToken: "secret-p3",
+			},
+		},
+	}
+}
+
+func TestWireGolden(t *testing.T) {
+	view := wireView()
+	for _, tc := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"player_public", NewPlayerPublic(view.Players["p2"])},
+		{"player_private", NewPlayerPrivate(view.Players["p1"])},
+		{"room_public", NewRoomPublic(view)},
+		{"room_for_viewer", NewRoomForViewer(view)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(tc.value, "", "  ")
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			got = append(got, '\n')
+			if bytes.Contains(got, []byte("secret-")) {
+				t.Errorf("a session token was sent:\n%s", got)
+			}
+
+			path := filepath.Join("testdata", tc.name+".golden.json")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatalf("write %s: %v", path, err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read %s: %v", path, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s changed; if the wire format meant to, rerun with -update\ngot:\n%s", path, got)
+			}
+		})
+	}
+}
+
+func TestRoomWirePicksTheViewersShape(t *testing.T) {
+	view := wireView()
+	if _, ok := NewRoomWire(view).(RoomForViewer); !ok {
+		t.Errorf("a player's view converted to %T, want RoomForViewer", NewRoomWire(view))
+	}
+	view.ViewerID = ""
+	if _, ok := NewRoomWire(view).(RoomPublic); !ok {
+		t.Errorf("the public view converted to %T, want RoomPublic", NewRoomWire(view))
+	}
+}
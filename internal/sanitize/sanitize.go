@@ -0,0 +1,186 @@
+// Package sanitize cleans and checks the free text players send: usernames
+// and chat. Every such input goes through Text with the Policy for its
+// field, so they all agree on what counts as a character, as whitespace
+// and as too long.
+//
+// Text is normalized to NFC first, so a length limit counts what a reader
+// sees rather than how it happened to be encoded. Control characters and
+// invisible formatting characters are removed: zero-width spaces and
+// joiners, byte order marks, and the bidirectional overrides and isolates
+// that let a name display as something other than what it is. Combining
+// marks are kept, as Thai and many other scripts need them, but only a few
+// per base character and never without one.
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/werewolf-game/backend/internal/errs"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxMarksPerBase caps the combining marks kept on one base character.
+// Thai stacks at most a vowel and a tone mark; anything past this is
+// decoration meant to spill over other lines.
+const maxMarksPerBase = 4
+
+var (
+	ErrTextEmpty        = &errs.Error{Code: "TEXT_EMPTY", Message: "text is empty"}
+	ErrTextTooShort     = &errs.Error{Code: "TEXT_TOO_SHORT", Message: "text is too short"}
+	ErrTextTooLong      = &errs.Error{Code: "TEXT_TOO_LONG", Message: "text is too long"}
+	ErrTextNotAllowed   = &errs.Error{Code: "TEXT_CHARACTER_NOT_ALLOWED", Message: "text contains a character that is not allowed"}
+	printableCharacters = []*unicode.RangeTable{unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Zs}
+)
+
+// Policy is how one field's text is cleaned and checked
+type Policy struct {
+	// Field names the input in errors, as the client sent it
+	Field string
+	// MinRunes and MaxRunes bound the cleaned text's length in runes
+	MinRunes int
+	MaxRunes int
+	// Multiline keeps line breaks; otherwise they become spaces
+	Multiline bool
+	// CollapseWhitespace turns each run of spaces into one space, and each
+	// run of line breaks into at most one blank line
+	CollapseWhitespace bool
+	// Allowed lists the character classes the text may use once cleaned;
+	// nil allows anything that survives cleaning
+	Allowed []*unicode.RangeTable
+	// Filter, if set, runs on the cleaned text before its length is
+	// checked, e.g. to mask profanity
+	Filter func(string) string
+}
+
+var (
+	// Username is a player's display name
+	Username = Policy{
+		Field:              "username",
+		MinRunes:           1,
+		MaxRunes:           32,
+		CollapseWhitespace: true,
+		Allowed:            printableCharacters,
+	}
+
+	// ChatMessage is the content of a chat message
+	ChatMessage = Policy{
+		Field:              "content",
+		MinRunes:           1,
+		MaxRunes:           500,
+		Multiline:          true,
+		CollapseWhitespace: true,
+		Allowed:            printableCharacters,
+	}
+)
+
+// Text cleans input by the policy and checks the result. The error is an
+// *errs.Error whose params name the field, plus the limit or the offending
+// character.
+func Text(input string, policy Policy) (string, error) {
+	text := clean(input, policy)
+
+	if policy.Allowed != nil {
+		for _, r := range text {
+			if r != '\n' && r != ' ' && !unicode.In(r, policy.Allowed...) {
+				return "", policy.fail(ErrTextNotAllowed, map[string]interface{}{"character": string(r)})
+			}
+		}
+	}
+
+	if policy.Filter != nil {
+		text = policy.Filter(text)
+	}
+
+	length := utf8.RuneCountInString(text)
+	switch {
+	case length == 0 && policy.MinRunes > 0:
+		return "", policy.fail(ErrTextEmpty, nil)
+	case length < policy.MinRunes:
+		return "", policy.fail(ErrTextTooShort, map[string]interface{}{"min": policy.MinRunes})
+	case policy.MaxRunes > 0 && length > policy.MaxRunes:
+		return "", policy.fail(ErrTextTooLong, map[string]interface{}{"max": policy.MaxRunes})
+	}
+	return text, nil
+}
+
+// fail returns sentinel for the policy's field
+func (p Policy) fail(sentinel *errs.Error, params map[string]interface{}) error {
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	params["field"] = p.Field
+	err := sentinel.WithParams(params)
+	err.Message = p.Field + ": " + sentinel.Message
+	return err
+}
+
+// clean normalizes input and removes what no field keeps: invalid UTF-8,
+// controls, invisible formatting, stray combining marks and surrounding
+// whitespace
+func clean(input string, policy Policy) string {
+	input = norm.NFC.String(strings.ToValidUTF8(input, ""))
+
+	var out strings.Builder
+	out.Grow(len(input))
+
+	pendingSpace, pendingBreaks := false, 0
+	marks := -1 // marks on the current base character; -1 with no base to attach to
+	for _, r := range input {
+		switch {
+		case r == '\n' && policy.Multiline:
+			pendingBreaks++
+			marks = -1
+			continue
+		case unicode.IsSpace(r):
+			marks = -1
+			if !policy.CollapseWhitespace && out.Len() > 0 {
+				out.WriteRune(' ')
+				continue
+			}
+			pendingSpace = true
+			continue
+		case invisible(r):
+			continue
+		case unicode.Is(unicode.M, r):
+			if marks < 0 || marks >= maxMarksPerBase {
+				continue
+			}
+			marks++
+			out.WriteRune(r)
+			continue
+		}
+
+		// A visible character: settle the whitespace before it
+		if out.Len() > 0 {
+			switch {
+			case pendingBreaks > 0 && policy.CollapseWhitespace:
+				out.WriteString(strings.Repeat("\n", min(pendingBreaks, 2)))
+			case pendingBreaks > 0:
+				out.WriteString(strings.Repeat("\n", pendingBreaks))
+			case pendingSpace:
+				out.WriteRune(' ')
+			}
+		}
+		pendingSpace, pendingBreaks = false, 0
+
+		out.WriteRune(r)
+		marks = 0
+	}
+
+	// Dropping an invisible character can leave a mark next to a base it
+	// composes with
+	return norm.NFC.String(strings.TrimRight(out.String(), " "))
+}
+
+// invisible reports whether r is a control or formatting character, or one
+// of the fillers that render as nothing
+func invisible(r rune) bool {
+	switch r {
+	case '\u034f', // combining grapheme joiner
+		'\u115f', '\u1160', '\u3164', '\uffa0': // Hangul fillers
+		return true
+	}
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+}
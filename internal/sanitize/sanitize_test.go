@@ -0,0 +1,160 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/werewolf-game/backend/internal/errs"
+)
+
+type textCase struct {
+	name   string
+	input  string
+	want   string
+	err    *errs.Error
+	params map[string]interface{}
+}
+
+func runTextCases(t *testing.T, policy Policy, cases []textCase) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Text(tc.input, policy)
+			if tc.err == nil {
+				if err != nil || got != tc.want {
+					t.Errorf("Text(%q) = %q, %v; want %q", tc.input, got, err, tc.want)
+				}
+				return
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Text(%q) = %q, %v; want %s", tc.input, got, err, tc.err.Code)
+			}
+			coded, _ := errs.Coded(err)
+			if coded.Params["field"] != policy.Field || !strings.HasPrefix(err.Error(), policy.Field+": ") {
+				t.Errorf("%v does not name the field %s", err, policy.Field)
+			}
+			for key, value := range tc.params {
+				if !reflect.DeepEqual(coded.Params[key], value) {
+					t.Errorf("param %s = %v, want %v", key, coded.Params[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestUsernamePolicy(t *testing.T) {
+	runTextCases(t, Username, []textCase{
+		{name: "plain", input: "Alice", want: "Alice"},
+		{name: "surrounding space", input: "  Alice \t", want: "Alice"},
+		{name: "inner runs", input: "Al   \t ice", want: "Al ice"},
+		{name: "line break", input: "Al\nice", want: "Al ice"},
+		{name: "zero-width space", input: "Al\u200bice", want: "Alice"},
+		{name: "zero-width joiner", input: "Al\u200dice", want: "Alice"},
+		{name: "byte order mark", input: "\ufeffAlice", want: "Alice"},
+		{name: "right-to-left override", input: "\u202eecilA", want: "ecilA"},
+		{name: "isolates", input: "\u2066Bob\u2069", want: "Bob"},
+		{name: "control", input: "Bob\x00\x07", want: "Bob"},
+		{name: "invalid UTF-8", input: "Bo\xffb", want: "Bob"},
+		{name: "Hangul filler", input: "Bob\u3164", want: "Bob"},
+		{name: "composed", input: "Re\u0301my", want: "Rémy"},
+		{name: "Thai", input: "สมศักดิ์", want: "สมศักดิ์"},
+		{name: "stray mark", input: "\u0301Bob", want: "Bob"},
+		{name: "stacked marks", input: "a" + strings.Repeat("\u0300", 10), want: "à" + strings.Repeat("\u0300", 4)},
+		{name: "emoji", input: "Bob 🐯", want: "Bob 🐯"},
+		{name: "longest", input: strings.Repeat("a", 32), want: strings.Repeat("a", 32)},
+		{name: "longest once composed", input: strings.Repeat("e\u0301", 32), want: strings.Repeat("é", 32)},
+		{name: "empty", input: "", err: ErrTextEmpty},
+		{name: "only space", input: "   ", err: ErrTextEmpty},
+		{name: "only invisible", input: "\u200b\u202e\ufeff", err: ErrTextEmpty},
+		{name: "too long", input: strings.Repeat("a", 33), err: ErrTextTooLong, params: map[string]interface{}{"max": 32}},
+		{name: "private use", input: "Bob\ue000", err: ErrTextNotAllowed, params: map[string]interface{}{"character": "\ue000"}},
+	})
+}
+
+func TestChatMessagePolicy(t *testing.T) {
+	runTextCases(t, ChatMessage, []textCase{
+		{name: "plain", input: "hello there", want: "hello there"},
+		{name: "surrounding space", input: "\n  hello  \n", want: "hello"},
+		{name: "line break kept", input: "one\ntwo", want: "one\ntwo"},
+		{name: "blank lines collapsed", input: "one\n\n\n\n\ntwo", want: "one\n\ntwo"},
+		{name: "tab", input: "one\ttwo", want: "one two"},
+		{name: "spaces around a break", input: "one   \n   two", want: "one\ntwo"},
+		{name: "right-to-left override", input: "vote \u202eerom\u202c now", want: "vote erom now"},
+		{name: "longest", input: strings.Repeat("x", 500), want: strings.Repeat("x", 500)},
+		{name: "too long", input: strings.Repeat("x", 501), err: ErrTextTooLong, params: map[string]interface{}{"max": 500}},
+		{name: "only breaks", input: "\n\n\n", err: ErrTextEmpty},
+	})
+}
+
+func TestPolicyOptions(t *testing.T) {
+	note := Policy{Field: "note", MinRunes: 3, MaxRunes: 10}
+	runTextCases(t, note, []textCase{
+		{name: "spaces kept", input: "a  b", want: "a  b"},
+		{name: "ends trimmed", input: "  a  b  ", want: "a  b"},
+		{name: "line break becomes a space", input: "a\nb", want: "a b"},
+		{name: "too short", input: "ab", err: ErrTextTooShort, params: map[string]interface{}{"min": 3}},
+		{name: "any class", input: "abc\ue000", want: "abc\ue000"},
+	})
+
+	masked := Policy{
+		Field:    "lastWords",
+		MinRunes: 1,
+		MaxRunes: 20,
+		Filter:   func(s string) string { return strings.ReplaceAll(s, "darn", "") },
+	}
+	runTextCases(t, masked, []textCase{
+		{name: "filtered", input: "oh darn it", want: "oh  it"},
+		{name: "filtered away", input: "darn", err: ErrTextEmpty},
+	})
+}
+
+// FuzzText checks what any input comes out as: valid UTF-8 within the
+// field's limits, with nothing invisible left and nothing more to clean
+func FuzzText(f *testing.F) {
+	for _, seed := range []string{
+		"Alice", "  a \t b  ", "one\n\n\ntwo", "\u202eecilA", "a\u200b\u0301b",
+		"สมศักดิ์", "\u0301\u0301x", "e" + strings.Repeat("\u0301", 9), "\xff\xfe", "\u3164",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, policy := range []Policy{Username, ChatMessage} {
+			got, err := Text(input, policy)
+			if err != nil {
+				var e *errs.Error
+				if !errors.As(err, &e) || e.Code == "" {
+					t.Fatalf("%s: Text(%q) failed without a code: %v", policy.Field, input, err)
+				}
+				continue
+			}
+
+			if !utf8.ValidString(got) {
+				t.Fatalf("%s: Text(%q) = %q is not valid UTF-8", policy.Field, input, got)
+			}
+			if n := utf8.RuneCountInString(got); n < policy.MinRunes || n > policy.MaxRunes {
+				t.Fatalf("%s: Text(%q) = %q has %d runes", policy.Field, input, got, n)
+			}
+			if strings.TrimSpace(got) != got {
+				t.Fatalf("%s: Text(%q) = %q has surrounding space", policy.Field, input, got)
+			}
+			for _, r := range got {
+				if r != '\n' && invisible(r) || r == '\n' && !policy.Multiline {
+					t.Fatalf("%s: Text(%q) = %q keeps %U", policy.Field, input, got, r)
+				}
+				if unicode.IsSpace(r) && r != ' ' && r != '\n' {
+					t.Fatalf("%s: Text(%q) = %q keeps whitespace %U", policy.Field, input, got, r)
+				}
+			}
+			if again, err := Text(got, policy); err != nil || again != got {
+				t.Fatalf("%s: cleaning %q again gave %q, %v", policy.Field, got, again, err)
+			}
+		}
+	})
+}
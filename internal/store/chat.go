@@ -0,0 +1,104 @@
+// Package store persists room data that outgrows memory. FileChatStore is
+// the game.ChatStore the server uses when CHAT_STORE_DIR is set.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// FileChatStore appends each room's spilled chat to its own NDJSON file
+type FileChatStore struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewFileChatStore creates dir if needed and stores chat files in it
+func NewFileChatStore(dir string) (*FileChatStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileChatStore{Dir: dir}, nil
+}
+
+func (s *FileChatStore) AppendChat(roomCode models.RoomCode, messages []models.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path(roomCode), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for i := range messages {
+		if err := encoder.Encode(&messages[i]); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *FileChatStore) ReadChat(roomCode models.RoomCode, since int64) ([]models.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path(roomCode))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var messages []models.Message
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var message models.Message
+		if err := decoder.Decode(&message); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", file.Name(), err)
+		}
+		if message.Seq > since {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (s *FileChatStore) DeleteChat(roomCode models.RoomCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(roomCode))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path keeps room codes from escaping the directory
+func (s *FileChatStore) path(roomCode models.RoomCode) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, string(roomCode))
+	return filepath.Join(s.Dir, name+".ndjson")
+}
@@ -0,0 +1,41 @@
+// Package testutil helps tests start from recorded game states, and play
+// single nights written as scenarios.
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// FixturePath returns the path of a curated fixture in this package's
+// testdata directory, wherever the calling test runs from
+func FixturePath(name string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "testdata", name)
+}
+
+// LoadFixture builds a GameManager holding the room recorded in the
+// fixture file and returns it with the room's code. Rooms are validated
+// on load and after every change.
+func LoadFixture(tb testing.TB, path string) (*game.GameManager, models.RoomCode) {
+	tb.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("read fixture: %v", err)
+	}
+
+	gm := game.NewGameManager()
+	gm.DebugValidate = true
+	code, err := gm.LoadFixture(data)
+	if err != nil {
+		tb.Fatalf("load fixture %s: %v", path, err)
+	}
+
+	return gm, code
+}
@@ -0,0 +1,310 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/werewolf-game/backend/internal/game"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// scenarioRoom is the code every scenario's room is loaded under
+const scenarioRoom models.RoomCode = "NIGHT1"
+
+// NightScenario is one night written as data: who is at the table, what
+// each of them does, and what the night should come to. RunNight compiles
+// it into a room fixture, resolves the night through the same pipeline a
+// real game uses and reports every way the outcome differs.
+//
+// A case fits in a few lines:
+//
+//	{
+//		Name:    "protection blocks the kill",
+//		Players: []string{"T:tiger", "H:hunter", "V:villager"},
+//		Actions: []string{"T kill V", "H protect V"},
+//		Expect:  NightExpectation{Protected: true, Recaps: map[string]string{"H": game.RecapProtectedAttacked}},
+//	},
+type NightScenario struct {
+	Name string
+	// Players are "ID:role" entries, optionally followed by flags:
+	// ":dead", ":cursed", and ":curse_used" for an alpha tiger who already
+	// spent the curse. Each player's username is their ID.
+	Players []string
+	// Actions are "actor action target" entries using the model's action
+	// names: kill and curse for the tiger team, protect for the hunter,
+	// vision for the shaman (an inspection when the target is dead), and
+	// "actor pass" for anyone choosing to do nothing.
+	Actions []string
+	// Night is the night number; 0 means 2, so FirstNightNoKill stays out
+	// of the way unless a case asks for night 1
+	Night int
+	// Modifier is the night modifier in force, e.g. "blood_moon"
+	Modifier string
+	// Settings adjusts the default room settings
+	Settings func(*models.RoomSettings)
+	Expect   NightExpectation
+}
+
+// NightExpectation is what a scenario's night should come to. Zero fields
+// are checked too: no deaths means nobody may die.
+type NightExpectation struct {
+	// Deaths are "ID:cause" entries in the order the players died
+	Deaths      []string
+	Protected   bool
+	ShamanSaved bool
+	// Vision is the shaman's result: "tiger", "human", "unknown", a role
+	// for an inspection, or "" for no vision
+	Vision string
+	// Recaps maps player IDs to their recap outcome; players left out are
+	// not checked
+	Recaps map[string]string
+	// RecapMessages maps player IDs to text their recap message must contain
+	RecapMessages map[string]string
+}
+
+// RunNight plays the scenario's night and fails tb with a readable report
+// of every difference from the expectation, followed by the night's trace.
+// It returns the result for any further checks.
+func RunNight(tb testing.TB, scenario NightScenario) *game.NightResult {
+	tb.Helper()
+
+	data, err := compileNight(scenario)
+	if err != nil {
+		tb.Fatalf("%s: %v", scenario.Name, err)
+	}
+
+	gm := game.NewGameManager()
+	gm.DebugValidate = true
+	code, err := gm.LoadFixture(data)
+	if err != nil {
+		tb.Fatalf("%s: load fixture: %v", scenario.Name, err)
+	}
+
+	result, err := gm.ProcessNightPhase(code)
+	if err != nil {
+		tb.Fatalf("%s: resolve night: %v", scenario.Name, err)
+	}
+
+	if problems := diffNight(result, scenario.Expect); len(problems) > 0 {
+		var trace []string
+		for _, step := range result.Trace {
+			trace = append(trace, "  "+step.Text)
+		}
+		tb.Errorf("%s:\n%s\ntrace:\n%s", scenario.Name, strings.Join(problems, "\n"), strings.Join(trace, "\n"))
+	}
+	return result
+}
+
+// compileNight builds the fixture a scenario's night starts from
+func compileNight(scenario NightScenario) ([]byte, error) {
+	night := scenario.Night
+	if night == 0 {
+		night = 2
+	}
+
+	settings := models.DefaultRoomSettings()
+	if scenario.Settings != nil {
+		scenario.Settings(&settings)
+	}
+
+	room := &models.GameRoom{
+		Code:          scenarioRoom,
+		Players:       make(map[string]*models.Player),
+		Phase:         models.PhaseNight,
+		Round:         night,
+		NightNumber:   night,
+		Settings:      settings,
+		NightModifier: scenario.Modifier,
+		TigerTeam:     &models.TigerTeamNight{Decisions: make(map[string]*models.TigerDecision)},
+	}
+	abilities := make(map[string]map[string]*models.AbilityUse)
+
+	for i, entry := range scenario.Players {
+		fields := strings.Split(entry, ":")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("player %q is not ID:role", entry)
+		}
+		player := &models.Player{
+			ID:       fields[0],
+			Username: fields[0],
+			Role:     models.Role(fields[1]),
+			IsAlive:  true,
+			Seat:     i + 1,
+			RoomCode: scenarioRoom,
+		}
+		abilities[player.ID] = startingAbilities(player.Role, settings)
+
+		for _, flag := range fields[2:] {
+			switch flag {
+			case "dead":
+				player.IsAlive = false
+			case "cursed":
+				player.IsCursed = true
+				room.CursedPlayer = player.ID
+			case "curse_used":
+				if curse := abilities[player.ID][models.AbilityCurse]; curse != nil {
+					curse.Used = 1
+				}
+			default:
+				return nil, fmt.Errorf("player %q: unknown flag %q", entry, flag)
+			}
+		}
+
+		if room.HostID == "" {
+			room.HostID = player.ID
+		}
+		room.Players[player.ID] = player
+	}
+	room.MaxPlayers = len(room.Players)
+
+	for _, entry := range scenario.Actions {
+		if err := compileAction(room, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(game.Fixture{
+		Version:   game.FixtureVersion,
+		Room:      room,
+		Abilities: abilities,
+	})
+}
+
+// compileAction records one action the way the night's turns would have
+func compileAction(room *models.GameRoom, entry string) error {
+	fields := strings.Fields(entry)
+	if len(fields) < 2 {
+		return fmt.Errorf("action %q is not \"actor action target\"", entry)
+	}
+	actor := room.Players[fields[0]]
+	if actor == nil {
+		return fmt.Errorf("action %q: no player %q", entry, fields[0])
+	}
+	action := fields[1]
+
+	if action == models.ActionPass {
+		record(room, actor, action, nil)
+		return nil
+	}
+	if len(fields) != 3 || room.Players[fields[2]] == nil {
+		return fmt.Errorf("action %q needs a target who is a player", entry)
+	}
+	target := room.Players[fields[2]]
+
+	switch action {
+	case models.ActionKill, models.ActionCurse:
+		if actor.Role != models.RoleTiger && actor.Role != models.RoleAlphaTiger {
+			return fmt.Errorf("action %q: only the tiger team can %s", entry, action)
+		}
+		// The team settles its decisions when the night resolves
+		room.TigerTeam.Decisions[actor.ID] = &models.TigerDecision{
+			PlayerID: actor.ID,
+			Username: actor.Username,
+			Action:   action,
+			TargetID: target.ID,
+		}
+	case models.ActionProtect:
+		room.HunterProtection = target.ID
+		actor.LastProtected = target.ID
+		record(room, actor, action, target)
+	case models.ActionVision:
+		room.ShamanVision = target.ID
+		if !target.IsAlive {
+			action = models.ActionInspect
+		}
+		record(room, actor, action, target)
+	default:
+		return fmt.Errorf("action %q: unknown action %q", entry, action)
+	}
+	return nil
+}
+
+// record adds an action to the actor's history for tonight
+func record(room *models.GameRoom, actor *models.Player, action string, target *models.Player) {
+	entry := models.ActionRecord{Round: room.Round, Phase: models.PhaseNight, ActionType: action}
+	if target != nil {
+		entry.TargetID = target.ID
+		entry.TargetUsername = target.Username
+	}
+	actor.ActionHistory = append(actor.ActionHistory, entry)
+	actor.HasActedThisNight = true
+}
+
+// startingAbilities mirrors the limited abilities a role is dealt
+func startingAbilities(role models.Role, settings models.RoomSettings) map[string]*models.AbilityUse {
+	abilities := make(map[string]*models.AbilityUse)
+	switch role {
+	case models.RoleAlphaTiger:
+		abilities[models.AbilityCurse] = &models.AbilityUse{CooldownNights: models.CooldownForever}
+	case models.RoleHunter:
+		abilities[models.AbilityShoot] = &models.AbilityUse{CooldownNights: models.CooldownForever}
+	}
+	if cooldown := settings.NightCooldowns[role]; cooldown != 0 {
+		switch role {
+		case models.RoleHunter:
+			abilities[models.AbilityProtect] = &models.AbilityUse{CooldownNights: cooldown}
+		case models.RoleShaman:
+			abilities[models.AbilityVision] = &models.AbilityUse{CooldownNights: cooldown}
+		}
+	}
+	return abilities
+}
+
+// diffNight lists every way the result differs from the expectation
+func diffNight(result *game.NightResult, expect NightExpectation) []string {
+	var problems []string
+	mismatch := func(what string, got, want interface{}) {
+		problems = append(problems, fmt.Sprintf("  %s: got %v, want %v", what, got, want))
+	}
+
+	deaths := []string{}
+	for _, death := range result.Deaths {
+		deaths = append(deaths, death.PlayerID+":"+death.Cause)
+	}
+	wantDeaths := expect.Deaths
+	if wantDeaths == nil {
+		wantDeaths = []string{}
+	}
+	if !reflect.DeepEqual(deaths, wantDeaths) {
+		mismatch("deaths", deaths, wantDeaths)
+	}
+	if result.Protected != expect.Protected {
+		mismatch("protected", result.Protected, expect.Protected)
+	}
+	if result.ShamanSaved != expect.ShamanSaved {
+		mismatch("shaman saved", result.ShamanSaved, expect.ShamanSaved)
+	}
+	if result.VisionResult != expect.Vision {
+		mismatch("vision", fmt.Sprintf("%q", result.VisionResult), fmt.Sprintf("%q", expect.Vision))
+	}
+
+	for _, id := range sortedKeys(expect.Recaps) {
+		recap, ok := result.Recaps[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("  recap for %s: none, want %s", id, expect.Recaps[id]))
+			continue
+		}
+		if recap.Outcome != expect.Recaps[id] {
+			mismatch("recap for "+id, recap.Outcome, expect.Recaps[id])
+		}
+	}
+	for _, id := range sortedKeys(expect.RecapMessages) {
+		if message := result.Recaps[id].Message; !strings.Contains(message, expect.RecapMessages[id]) {
+			mismatch("recap message for "+id, fmt.Sprintf("%q", message), fmt.Sprintf("to contain %q", expect.RecapMessages[id]))
+		}
+	}
+	return problems
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
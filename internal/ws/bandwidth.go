@@ -0,0 +1,239 @@
+package ws
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const (
+	// bandwidthWindow is the rolling window outbound rates are averaged over
+	bandwidthWindow = 10 * time.Second
+	// degradeQueueDepth is how many unsent frames mean a client is falling behind
+	degradeQueueDepth = 64
+	// degradeBytesPerSecond is the outbound rate that is more than a poor
+	// connection can take
+	degradeBytesPerSecond = 128 << 10
+	// degradeAfter is how long a client must stay over a threshold before it
+	// is degraded, so one burst doesn't do it
+	degradeAfter = 5 * time.Second
+	// recoverAfter is how long a degraded client must stay under both
+	// thresholds before normal delivery resumes
+	recoverAfter = 15 * time.Second
+)
+
+// DegradePolicy says, by event type, what a degraded client can do without
+type DegradePolicy struct {
+	// Coalesce are events carrying the whole of some state, so a degraded
+	// client only needs the latest of each
+	Coalesce map[string]bool
+	// Drop are cosmetic events a degraded client goes without
+	Drop map[string]bool
+}
+
+// connStats measures what one connection is sent and, once it falls behind,
+// holds back what it can do without
+type connStats struct {
+	mu       sync.Mutex
+	buckets  [10]int64 // bytes written in each second of the window
+	seconds  [10]int64 // the unix second each bucket holds
+	overAt   time.Time // when the client went over a threshold, zero while under
+	underAt  time.Time // when a degraded client went back under, zero while over
+	degraded bool
+	held     int64 // frames coalesced or dropped while degraded
+
+	// latest holds the newest coalesced frame of each event until the
+	// write pump gets to it; wake tells the pump there is something there
+	latest map[string][]byte
+	wake   chan struct{}
+}
+
+func newConnStats() *connStats {
+	return &connStats{latest: make(map[string][]byte), wake: make(chan struct{}, 1)}
+}
+
+// wrote records a frame the write pump sent
+func (s *connStats) wrote(n int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	second := now.Unix()
+	slot := second % int64(len(s.buckets))
+	if s.seconds[slot] != second {
+		s.seconds[slot] = second
+		s.buckets[slot] = 0
+	}
+	s.buckets[slot] += int64(n)
+}
+
+// rateLocked is the average outbound bytes per second over the window
+func (s *connStats) rateLocked(now time.Time) int64 {
+	oldest := now.Add(-bandwidthWindow).Unix()
+	var total int64
+	for i, second := range s.seconds {
+		if second > oldest {
+			total += s.buckets[i]
+		}
+	}
+	return total / int64(bandwidthWindow/time.Second)
+}
+
+// observe updates the client's mode from its queue depth and rate. It
+// reports whether the client is degraded and whether that just changed.
+func (s *connStats) observe(queued int, now time.Time) (degraded, changed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	over := queued >= degradeQueueDepth || s.rateLocked(now) >= degradeBytesPerSecond
+	switch {
+	case over:
+		s.underAt = time.Time{}
+		if s.overAt.IsZero() {
+			s.overAt = now
+		}
+		if !s.degraded && now.Sub(s.overAt) >= degradeAfter {
+			s.degraded = true
+			return true, true
+		}
+	case s.degraded:
+		s.overAt = time.Time{}
+		if s.underAt.IsZero() {
+			s.underAt = now
+		}
+		if now.Sub(s.underAt) >= recoverAfter {
+			s.degraded = false
+			s.underAt = time.Time{}
+			s.signalLocked() // flush whatever was held back
+			return false, true
+		}
+	default:
+		s.overAt = time.Time{}
+	}
+	return s.degraded, false
+}
+
+// hold keeps a degraded client from being sent a frame it can do without,
+// reporting whether the frame was held back. Coalesced frames replace the
+// previous one of their kind and are written when the pump gets to them.
+func (s *connStats) hold(data []byte, policy DegradePolicy) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.degraded {
+		// A held frame must never be written after the one replacing it
+		if len(s.latest) > 0 {
+			delete(s.latest, frameType(data))
+		}
+		return false
+	}
+
+	eventType := frameType(data)
+	switch {
+	case policy.Drop[eventType]:
+	case policy.Coalesce[eventType]:
+		s.latest[eventType] = data
+		s.signalLocked()
+	default:
+		return false
+	}
+	s.held++
+	return true
+}
+
+// signalLocked wakes the write pump without blocking
+func (s *connStats) signalLocked() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// takeLatest hands the held coalesced frames to the write pump
+func (s *connStats) takeLatest() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	types := make([]string, 0, len(s.latest))
+	for eventType := range s.latest {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+
+	frames := make([][]byte, 0, len(types))
+	for _, eventType := range types {
+		frames = append(frames, s.latest[eventType])
+		delete(s.latest, eventType)
+	}
+	return frames
+}
+
+// framePrefix is how every frame encodeMessage produces begins
+var framePrefix = []byte(`{"type":"`)
+
+// frameType reads a frame's event type without decoding the payload
+func frameType(data []byte) string {
+	if !bytes.HasPrefix(data, framePrefix) {
+		return ""
+	}
+	rest := data[len(framePrefix):]
+	end := bytes.IndexByte(rest, '"')
+	if end < 0 {
+		return ""
+	}
+	return string(rest[:end])
+}
+
+// ClientStats is one connection in the admin client listing
+type ClientStats struct {
+	ID             string          `json:"id"`
+	RoomCode       models.RoomCode `json:"roomCode"`
+	Observer       bool            `json:"observer,omitempty"`
+	Protocol       int             `json:"protocol"`
+	Capabilities   []Capability    `json:"capabilities,omitempty"`
+	BytesPerSecond int64           `json:"bytesPerSecond"`
+	QueueDepth     int             `json:"queueDepth"`
+	Degraded       bool            `json:"degraded"`
+	HeldFrames     int64           `json:"heldFrames"`
+}
+
+// ClientStats lists every connection's outbound stats, by room and ID
+func (h *Hub) ClientStats(now time.Time) []ClientStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	list := make([]ClientStats, 0, len(h.Clients))
+	for _, client := range h.Clients {
+		stats := ClientStats{
+			ID:         client.ID,
+			RoomCode:   client.RoomCode,
+			Observer:   client.Observer,
+			Protocol:   client.Protocol,
+			QueueDepth: len(client.Send),
+		}
+		for capability, on := range client.Capabilities {
+			if on {
+				stats.Capabilities = append(stats.Capabilities, capability)
+			}
+		}
+		sort.Slice(stats.Capabilities, func(i, j int) bool { return stats.Capabilities[i] < stats.Capabilities[j] })
+		if s := client.stats; s != nil {
+			s.mu.Lock()
+			stats.BytesPerSecond = s.rateLocked(now)
+			stats.Degraded = s.degraded
+			stats.HeldFrames = s.held
+			s.mu.Unlock()
+		}
+		list = append(list, stats)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].RoomCode != list[j].RoomCode {
+			return list[i].RoomCode < list[j].RoomCode
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
@@ -0,0 +1,222 @@
+package ws
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// testPolicy coalesces game state and drops color changes
+var testPolicy = DegradePolicy{
+	Coalesce: map[string]bool{models.EventGameStateUpdate: true},
+	Drop:     map[string]bool{"color_changed": true},
+}
+
+// eventFrame encodes an event as the server sends it
+func eventFrame(t testing.TB, eventType string, payload interface{}) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(models.WSMessage{Type: eventType, Payload: payload})
+	if err != nil {
+		t.Fatalf("encode %s: %v", eventType, err)
+	}
+	return data
+}
+
+func TestSustainedQueueDegradesThenRecovers(t *testing.T) {
+	s := newConnStats()
+	start := time.Unix(1000, 0)
+
+	if degraded, _ := s.observe(degradeQueueDepth, start); degraded {
+		t.Fatal("degraded at the first full queue")
+	}
+	if degraded, _ := s.observe(degradeQueueDepth, start.Add(degradeAfter-time.Second)); degraded {
+		t.Fatal("degraded before the queue stayed full long enough")
+	}
+	degraded, changed := s.observe(degradeQueueDepth, start.Add(degradeAfter))
+	if !degraded || !changed {
+		t.Fatalf("after %s behind: degraded %v, changed %v", degradeAfter, degraded, changed)
+	}
+
+	// Recovery needs a quiet stretch of its own, restarted by any relapse
+	calm := start.Add(degradeAfter + time.Second)
+	s.observe(0, calm)
+	s.observe(degradeQueueDepth, calm.Add(recoverAfter/2))
+	calm = calm.Add(recoverAfter/2 + time.Second)
+	s.observe(0, calm)
+	if degraded, _ := s.observe(0, calm.Add(recoverAfter-time.Second)); !degraded {
+		t.Fatal("recovered before a full quiet stretch")
+	}
+	degraded, changed = s.observe(0, calm.Add(recoverAfter))
+	if degraded || !changed {
+		t.Errorf("after %s quiet: degraded %v, changed %v", recoverAfter, degraded, changed)
+	}
+}
+
+func TestBurstDoesNotDegrade(t *testing.T) {
+	s := newConnStats()
+	start := time.Unix(1000, 0)
+
+	s.observe(degradeQueueDepth, start)
+	s.observe(0, start.Add(time.Second))
+	if degraded, _ := s.observe(degradeQueueDepth, start.Add(degradeAfter)); degraded {
+		t.Error("two bursts with a drain between them degraded the client")
+	}
+}
+
+func TestRateOverTheWindow(t *testing.T) {
+	s := newConnStats()
+	start := time.Unix(1000, 0)
+
+	window := int(bandwidthWindow / time.Second)
+	for i := 0; i < window; i++ {
+		s.wrote(degradeBytesPerSecond, start.Add(time.Duration(i)*time.Second))
+	}
+	now := start.Add(bandwidthWindow - time.Second)
+	s.mu.Lock()
+	rate := s.rateLocked(now)
+	stale := s.rateLocked(now.Add(bandwidthWindow))
+	s.mu.Unlock()
+	if rate != degradeBytesPerSecond {
+		t.Errorf("rate = %d B/s, want %d", rate, degradeBytesPerSecond)
+	}
+	if stale != 0 {
+		t.Errorf("rate a window later = %d B/s, want 0", stale)
+	}
+
+	// A high rate alone degrades, with an empty queue
+	s.observe(0, now)
+	if degraded, _ := s.observe(0, now.Add(degradeAfter)); degraded {
+		t.Error("degraded once the rate had fallen away")
+	}
+	for i := 0; i <= int(degradeAfter/time.Second); i++ {
+		at := now.Add(degradeAfter + time.Duration(i)*time.Second)
+		s.wrote(2*degradeBytesPerSecond*window, at)
+		s.observe(0, at)
+	}
+	if !s.degraded {
+		t.Error("a sustained high rate did not degrade the client")
+	}
+}
+
+func TestDegradedClientHoldsBackWhatItCan(t *testing.T) {
+	s := newConnStats()
+	state := func(n int) []byte { return eventFrame(t, models.EventGameStateUpdate, n) }
+
+	if s.hold(state(1), testPolicy) {
+		t.Fatal("a healthy client was held back")
+	}
+
+	s.degraded = true
+	if !s.hold(state(1), testPolicy) || !s.hold(state(2), testPolicy) {
+		t.Error("game state was not coalesced")
+	}
+	if !s.hold(eventFrame(t, "color_changed", nil), testPolicy) {
+		t.Error("a cosmetic event was not dropped")
+	}
+	for _, essential := range []string{models.EventPhaseChanged, models.EventYourTurn, models.EventError} {
+		if s.hold(eventFrame(t, essential, nil), testPolicy) {
+			t.Errorf("%s was held back", essential)
+		}
+	}
+
+	latest := s.takeLatest()
+	if len(latest) != 1 || string(latest[0]) != string(state(2)) {
+		t.Errorf("held frames = %q, want only the newest state", latest)
+	}
+	if s.held != 3 {
+		t.Errorf("held = %d, want 3", s.held)
+	}
+
+	// A held frame is forgotten once a newer one goes out directly
+	s.hold(state(3), testPolicy)
+	s.degraded = false
+	s.hold(state(4), testPolicy)
+	if stale := s.takeLatest(); len(stale) != 0 {
+		t.Errorf("a state older than the one sent is still held: %q", stale)
+	}
+}
+
+func TestSlowReaderIsDegradedAndRecovers(t *testing.T) {
+	hub := NewHub(testPolicy)
+	conn := newFakeConn(t)
+	client := NewClient(conn, "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(client)
+
+	// The client has been behind for a while, with nothing being written
+	for i := 0; i < degradeQueueDepth; i++ {
+		client.Send <- eventFrame(t, "filler", i)
+	}
+	client.stats.overAt = time.Now().Add(-degradeAfter)
+
+	broadcast := func(eventType string, payload interface{}) {
+		hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, eventType, payload)})
+	}
+	broadcast(models.EventGameStateUpdate, 1)
+	broadcast("color_changed", nil)
+	broadcast(models.EventGameStateUpdate, 2)
+	broadcast(models.EventPhaseChanged, "night")
+
+	stats := hub.ClientStats(time.Now())
+	if len(stats) != 1 || !stats[0].Degraded || stats[0].HeldFrames != 3 {
+		t.Fatalf("client stats = %+v, want degraded with 3 held", stats)
+	}
+
+	go client.WritePump(hub)
+	waitFor(t, func() bool { return len(conn.written()) == degradeQueueDepth+3 })
+
+	// The held state may overtake the backlog; the queue keeps its order
+	var sent []string
+	for _, f := range conn.written() {
+		if frameType(f.data) != "filler" {
+			sent = append(sent, string(f.data))
+		}
+	}
+	notice := string(eventFrame(t, models.EventDegradedMode, map[string]bool{"degraded": true}))
+	phase := string(eventFrame(t, models.EventPhaseChanged, "night"))
+	state := string(eventFrame(t, models.EventGameStateUpdate, 2))
+	queued := strings.Join(sent, "\n")
+	at := strings.Index(queued, notice)
+	if at < 0 || strings.Index(queued, phase) < at || !strings.Contains(queued, state) {
+		t.Errorf("after the backlog the client got\n%s\nwant the notice, the phase change and the latest state", queued)
+	}
+
+	// Quiet long enough, the client is told it is back to normal delivery
+	client.stats.mu.Lock()
+	client.stats.overAt = time.Time{}
+	client.stats.underAt = time.Now().Add(-recoverAfter)
+	client.stats.mu.Unlock()
+	broadcast(models.EventGameStateUpdate, 3)
+	recovered := string(eventFrame(t, models.EventDegradedMode, map[string]bool{"degraded": false}))
+	waitFor(t, func() bool { return string(lastFrame(conn)) == string(eventFrame(t, models.EventGameStateUpdate, 3)) })
+	frames := conn.written()
+	if got := string(frames[len(frames)-2].data); got != recovered {
+		t.Errorf("before the first normal frame came %s, want %s", got, recovered)
+	}
+	hub.Close(client, 1000, "", models.DisconnectClientClosed)
+}
+
+// lastFrame returns the newest frame written to conn
+func lastFrame(conn *fakeConn) []byte {
+	frames := conn.written()
+	if len(frames) == 0 {
+		return nil
+	}
+	return frames[len(frames)-1].data
+}
+
+// waitFor polls until done reports true, failing the test after two seconds
+func waitFor(t testing.TB, done func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
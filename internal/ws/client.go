@@ -0,0 +1,240 @@
+package ws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// sendBuffer is how many frames may wait for a client's write pump
+const sendBuffer = 256
+
+// Conn is the part of a websocket connection the pumps use; *websocket.Conn
+// implements it
+type Conn interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// Capability is an optional feature a client declares it handles
+type Capability string
+
+const (
+	// CapScopedUpdates takes players, phase and votes updates instead of
+	// whole-room snapshots
+	CapScopedUpdates Capability = "scoped_updates"
+	// CapChatReplay wants the recent chat history on connect
+	CapChatReplay Capability = "chat_replay"
+)
+
+type Client struct {
+	ID       string
+	RoomCode models.RoomCode
+	Conn     Conn
+	Send     chan []byte
+	// Observer connections watch a game without a seat. They get the final
+	// summary of an ended game on connect and nothing from room broadcasts,
+	// unless they are delayed.
+	Observer bool
+	// Delay holds back every frame to the client by this long; set it before
+	// registering. Delayed observers may watch a game in progress and get
+	// its public broadcasts, late enough that a stream of them is no help to
+	// anyone still playing.
+	Delay time.Duration
+	// Protocol is the version the client declared; the hub guards it
+	Protocol int
+	// Capabilities are the optional features the client handles, defaulting
+	// to what its protocol version implies; the hub guards them
+	Capabilities map[Capability]bool
+	// AddrHash identifies the remote address in disconnect records
+	AddrHash string
+
+	// stats tracks the connection's outbound traffic and degraded mode
+	stats *connStats
+	// closeReason is why the server ended the connection, if it did; the
+	// hub guards it
+	closeReason string
+	// closeFrame, if set before Send is closed, is written as the
+	// connection's close message
+	closeFrame []byte
+	// lastPingAt is when the client's last answered ping arrived; the hub
+	// guards it
+	lastPingAt time.Time
+	// delayed holds the frames of a client with a Delay
+	delayed *delayLine
+
+	// detached clients have no connection; what is sent to them is
+	// collected in replies instead
+	detached bool
+	replies  [][]byte
+}
+
+// NewClient wraps a connection from addr for the player or observer ID
+func NewClient(conn Conn, id string, roomCode models.RoomCode, addr string) *Client {
+	return &Client{
+		ID:       id,
+		RoomCode: roomCode,
+		Conn:     conn,
+		Send:     make(chan []byte, sendBuffer),
+		AddrHash: HashAddr(addr),
+		stats:    newConnStats(),
+	}
+}
+
+// NewDetachedClient stands in for a player without a connection, such as a
+// REST request acting as them. It is never registered with the hub.
+func NewDetachedClient(id string, roomCode models.RoomCode) *Client {
+	return &Client{ID: id, RoomCode: roomCode, detached: true}
+}
+
+// Replies returns the frames sent to a detached client, oldest first
+func (c *Client) Replies() [][]byte {
+	return c.replies
+}
+
+// WantsSnapshots reports whether the client needs whole-room snapshots
+// instead of scoped updates. The caller holds the hub lock.
+func (c *Client) WantsSnapshots() bool {
+	return !c.Capabilities[CapScopedUpdates]
+}
+
+// SetCapabilities replaces a connected client's flags
+func (h *Hub) SetCapabilities(client *Client, capabilities map[Capability]bool) {
+	h.mu.Lock()
+	client.Capabilities = capabilities
+	h.mu.Unlock()
+}
+
+// HasCapability reads one of a connected client's flags
+func (h *Hub) HasCapability(client *Client, capability Capability) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return client.Capabilities[capability]
+}
+
+// CapabilityCounts reports how many connected clients declared each capability
+func (h *Hub) CapabilityCounts() map[Capability]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	counts := make(map[Capability]int)
+	for _, client := range h.Clients {
+		for capability := range client.Capabilities {
+			counts[capability]++
+		}
+	}
+	return counts
+}
+
+// ReadPump hands every frame the client sends to handle until the
+// connection ends, then unregisters it and reports the read error to closed
+func (c *Client) ReadPump(h *Hub, handle func(message []byte), closed func(readErr error)) {
+	var readErr error
+	defer func() {
+		h.Unregister <- c
+		c.Conn.Close()
+		closed(readErr)
+	}()
+
+	for {
+		_, message, err := c.Conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("WebSocket error: %v", err)
+			}
+			readErr = err
+			return
+		}
+		handle(message)
+	}
+}
+
+// WritePump writes the client's queued frames, and whatever degraded mode
+// held back, until its send channel is closed or a write fails
+func (c *Client) WritePump(h *Hub) {
+	defer c.Conn.Close()
+
+	write := func(message []byte) bool {
+		if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			log.Printf("Write error: %v", err)
+			h.SetCloseReason(c, models.DisconnectWriteFailed)
+			return false
+		}
+		c.stats.wrote(len(message), time.Now())
+		return true
+	}
+
+	for {
+		select {
+		case message, ok := <-c.Send:
+			if !ok {
+				if c.closeFrame != nil {
+					c.Conn.WriteMessage(websocket.CloseMessage, c.closeFrame)
+				}
+				return
+			}
+			if !write(message) {
+				return
+			}
+		case <-c.stats.wake:
+			for _, message := range c.stats.takeLatest() {
+				if !write(message) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// setCloseReasonLocked records why the server is ending the connection.
+// The first reason given sticks. The caller holds the hub lock.
+func (c *Client) setCloseReasonLocked(reason string) {
+	if c.closeReason == "" {
+		c.closeReason = reason
+	}
+}
+
+// SetCloseReason records why the server is ending a connection
+func (h *Hub) SetCloseReason(client *Client, reason string) {
+	h.mu.Lock()
+	client.setCloseReasonLocked(reason)
+	h.mu.Unlock()
+}
+
+// SetPlayerCloseReason records why a player's current connection is about
+// to end, for disconnects the client carries out when told to
+func (h *Hub) SetPlayerCloseReason(playerID, reason string) {
+	h.mu.Lock()
+	if client := h.Clients[playerID]; client != nil {
+		client.setCloseReasonLocked(reason)
+	}
+	h.mu.Unlock()
+}
+
+// DisconnectReason explains a finished connection: the server's reason for
+// ending it if it had one, otherwise how the read side saw it end
+func (h *Hub) DisconnectReason(client *Client, readErr error) string {
+	h.mu.RLock()
+	reason := client.closeReason
+	h.mu.RUnlock()
+
+	switch {
+	case reason != "":
+		return reason
+	case websocket.IsCloseError(readErr, websocket.CloseNormalClosure, websocket.CloseGoingAway):
+		return models.DisconnectClientClosed
+	default:
+		return models.DisconnectConnectionLost
+	}
+}
+
+// HashAddr identifies a remote address without storing it
+func HashAddr(addr string) string {
+	sum := sha256.Sum256([]byte(addr))
+	return hex.EncodeToString(sum[:8])
+}
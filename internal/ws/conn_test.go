@@ -0,0 +1,85 @@
+package ws
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// frame is one message written to a fakeConn
+type frame struct {
+	kind int
+	data []byte
+}
+
+// fakeConn is a connection that records what is written to it and fails
+// the test if two goroutines ever write at once, as gorilla forbids
+type fakeConn struct {
+	t       testing.TB
+	writing atomic.Int32
+	reads   chan []byte
+
+	mu     sync.Mutex
+	frames []frame
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newFakeConn(t testing.TB) *fakeConn {
+	return &fakeConn{t: t, reads: make(chan []byte, 16), closed: make(chan struct{})}
+}
+
+func (c *fakeConn) ReadMessage() (int, []byte, error) {
+	select {
+	case data := <-c.reads:
+		return websocket.TextMessage, data, nil
+	case <-c.closed:
+		return 0, nil, &websocket.CloseError{Code: websocket.CloseAbnormalClosure}
+	}
+}
+
+func (c *fakeConn) WriteMessage(kind int, data []byte) error {
+	if c.writing.Add(1) != 1 {
+		c.t.Errorf("concurrent write of a %d frame", kind)
+	}
+	defer c.writing.Add(-1)
+
+	select {
+	case <-c.closed:
+		return errors.New("write on closed connection")
+	default:
+	}
+	time.Sleep(time.Millisecond) // widen the window a second writer would hit
+
+	c.mu.Lock()
+	c.frames = append(c.frames, frame{kind: kind, data: append([]byte(nil), data...)})
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+// waitClosed waits for the connection to be hung up
+func (c *fakeConn) waitClosed(t testing.TB) {
+	t.Helper()
+
+	select {
+	case <-c.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was never closed")
+	}
+}
+
+// written returns the frames written so far
+func (c *fakeConn) written() []frame {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]frame(nil), c.frames...)
+}
@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// delayedFrame is a frame held back from a delayed client until it is due
+type delayedFrame struct {
+	data []byte
+	due  time.Time
+}
+
+// timer is a pending call made by the hub's afterFunc
+type timer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+func afterFunc(d time.Duration, f func()) timer {
+	return time.AfterFunc(d, f)
+}
+
+// delayLine holds a delayed client's frames until they are due. Every frame
+// waits the same delay, so they leave in the order they arrived. One timer
+// per client releases them; the hub never waits on it.
+type delayLine struct {
+	mu      sync.Mutex
+	frames  []delayedFrame
+	timer   timer
+	stopped bool
+	done    chan struct{} // closed by stop
+}
+
+// hold queues a frame for release after the client's delay, starting the
+// timer when nothing else is waiting
+func (h *Hub) hold(client *Client, data []byte, now time.Time) {
+	line := client.delayed
+	line.mu.Lock()
+	defer line.mu.Unlock()
+
+	if line.stopped {
+		return
+	}
+	line.frames = append(line.frames, delayedFrame{data: data, due: now.Add(client.Delay)})
+	if line.timer == nil {
+		line.timer = h.afterFunc(client.Delay, func() { h.releaseDelayed(client) })
+	}
+}
+
+// due takes the frames due by now and rearms the timer for the rest
+func (line *delayLine) due(now time.Time) [][]byte {
+	line.mu.Lock()
+	defer line.mu.Unlock()
+
+	var ready [][]byte
+	for len(line.frames) > 0 && !line.frames[0].due.After(now) {
+		ready = append(ready, line.frames[0].data)
+		line.frames = line.frames[1:]
+	}
+	if len(line.frames) == 0 {
+		line.timer = nil
+	} else {
+		line.timer.Reset(line.frames[0].due.Sub(now))
+	}
+	return ready
+}
+
+// stop discards what is still held, so nothing reaches a later connection
+func (line *delayLine) stop() {
+	line.mu.Lock()
+	defer line.mu.Unlock()
+
+	if !line.stopped && line.done != nil {
+		close(line.done)
+	}
+	line.stopped = true
+	line.frames = nil
+	if line.timer != nil {
+		line.timer.Stop()
+		line.timer = nil
+	}
+}
+
+// watch stops the line when its room's context is done, or returns once
+// the line is stopped some other way
+func (line *delayLine) watch(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		line.stop()
+	case <-line.done:
+	}
+}
+
+// releaseDelayed hands a delayed client the frames now due. A client whose
+// send buffer cannot take them is dropped, as in deliver.
+func (h *Hub) releaseDelayed(client *Client) {
+	h.mu.RLock()
+	if h.Clients[client.ID] != client {
+		h.mu.RUnlock()
+		return
+	}
+
+	slow := false
+	for _, data := range client.delayed.due(h.now()) {
+		select {
+		case client.Send <- data:
+			continue
+		default:
+		}
+		slow = true
+		break
+	}
+	h.mu.RUnlock()
+
+	if slow {
+		h.SetCloseReason(client, models.DisconnectSlowConsumer)
+		if h.drop(client) {
+			log.Printf("Delayed client %s dropped: send buffer full", client.ID)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package ws
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// fakeClock is a hub clock that only moves when the test advances it
+type fakeClock struct {
+	mu     sync.Mutex
+	at     time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock  *fakeClock
+	due    time.Time
+	f      func()
+	active bool
+}
+
+func newFakeClock(hub *Hub) *fakeClock {
+	clock := &fakeClock{at: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	hub.now = clock.now
+	hub.afterFunc = clock.afterFunc
+	return clock
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.at
+}
+
+func (c *fakeClock) afterFunc(d time.Duration, f func()) timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, due: c.at.Add(d), f: f, active: true}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	was := t.active
+	t.active = false
+	return was
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	was := t.active
+	t.due = t.clock.at.Add(d)
+	t.active = true
+	return was
+}
+
+// advance moves the clock on by d, firing each timer that falls due at its
+// own moment, earliest first
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	until := c.at.Add(d)
+	for {
+		sort.Slice(c.timers, func(i, j int) bool { return c.timers[i].due.Before(c.timers[j].due) })
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if t.active && !t.due.After(until) {
+				next = t
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		c.at = next.due
+		next.active = false
+		c.mu.Unlock()
+		next.f()
+		c.mu.Lock()
+	}
+	c.at = until
+	c.mu.Unlock()
+}
+
+// delayedWatcher is a streamer watching ROOM1 with a 90-second delay
+func delayedWatcher(t *testing.T, hub *Hub) *Client {
+	t.Helper()
+
+	client := NewClient(newFakeConn(t), "o1", "ROOM1", "127.0.0.1")
+	client.Observer = true
+	client.Delay = 90 * time.Second
+	hub.RegisterNow(client)
+	return client
+}
+
+// queued drains what has reached the client's send buffer so far
+func queued(client *Client) []string {
+	var types []string
+	for {
+		select {
+		case data, ok := <-client.Send:
+			if !ok {
+				return types
+			}
+			types = append(types, frameType(data))
+		default:
+			return types
+		}
+	}
+}
+
+func TestDelayedClientGetsFramesShiftedInOrder(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	clock := newFakeClock(hub)
+	watcher := delayedWatcher(t, hub)
+	player := NewClient(newFakeConn(t), "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(player)
+
+	broadcast := func(eventType string) {
+		hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, eventType, nil)})
+	}
+	broadcast(models.EventGameStateUpdate)
+	clock.advance(10 * time.Second)
+	broadcast(models.EventChatMessage)
+	clock.advance(10 * time.Second)
+	broadcast(models.EventPhaseChanged)
+
+	if got := queued(player); len(got) != 3 {
+		t.Errorf("the player got %v at once, want all three", got)
+	}
+	if got := queued(watcher); len(got) != 0 {
+		t.Fatalf("the streamer got %v straight away", got)
+	}
+
+	// 90 seconds after each was sent, and not a moment before
+	for _, step := range []struct {
+		by   time.Duration
+		want []string
+	}{
+		{69*time.Second + 999*time.Millisecond, nil},
+		{time.Millisecond, []string{models.EventGameStateUpdate}},
+		{9 * time.Second, nil},
+		{time.Second, []string{models.EventChatMessage}},
+		{10 * time.Second, []string{models.EventPhaseChanged}},
+		{time.Hour, nil},
+	} {
+		clock.advance(step.by)
+		if got := queued(watcher); len(got) != len(step.want) || len(got) > 0 && got[0] != step.want[0] {
+			t.Errorf("at %s the streamer got %v, want %v", clock.now().Format(time.TimeOnly), got, step.want)
+		}
+	}
+}
+
+func TestDelayedFramesReleasedTogetherKeepTheirOrder(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	clock := newFakeClock(hub)
+	watcher := delayedWatcher(t, hub)
+
+	for _, eventType := range []string{"one", "two", "three"} {
+		hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, eventType, nil)})
+	}
+	clock.advance(90 * time.Second)
+	if got := queued(watcher); len(got) != 3 || got[0] != "one" || got[1] != "two" || got[2] != "three" {
+		t.Errorf("the streamer got %v, want one, two, three", got)
+	}
+}
+
+func TestReconnectedStreamerGetsNoOldBacklog(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	clock := newFakeClock(hub)
+	first := delayedWatcher(t, hub)
+
+	hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, "before", nil)})
+	clock.advance(30 * time.Second)
+
+	// The same streamer comes back on a new connection
+	second := delayedWatcher(t, hub)
+	hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, "after", nil)})
+
+	clock.advance(60 * time.Second)
+	if got := queued(second); len(got) != 0 {
+		t.Errorf("the new connection got %v from before it connected", got)
+	}
+	if got := queued(first); len(got) != 0 {
+		t.Errorf("the replaced connection got %v", got)
+	}
+	clock.advance(30 * time.Second)
+	if got := queued(second); len(got) != 1 || got[0] != "after" {
+		t.Errorf("the new connection got %v, want only what followed it", got)
+	}
+}
+
+func TestDroppedStreamerIsReleasedNothing(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	clock := newFakeClock(hub)
+	watcher := delayedWatcher(t, hub)
+
+	hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, "held", nil)})
+	hub.drop(watcher)
+	clock.advance(90 * time.Second)
+	if got := queued(watcher); len(got) != 0 {
+		t.Errorf("a dropped connection was released %v", got)
+	}
+}
+
+func TestDelayLineStopsWithItsRoom(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	clock := newFakeClock(hub)
+	ctx, cancel := context.WithCancel(context.Background())
+	var watching sync.WaitGroup
+	hub.SetSpawner(func(roomCode models.RoomCode, fn func(ctx context.Context)) bool {
+		watching.Add(1)
+		go func() {
+			defer watching.Done()
+			fn(ctx)
+		}()
+		return true
+	})
+	watcher := delayedWatcher(t, hub)
+
+	hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, "held", nil)})
+	cancel()
+	watching.Wait()
+
+	if active := clock.timers[0].active; active {
+		t.Error("the delay line's timer outlived its room")
+	}
+	clock.advance(90 * time.Second)
+	if got := queued(watcher); len(got) != 0 {
+		t.Errorf("a closed room's streamer was released %v", got)
+	}
+}
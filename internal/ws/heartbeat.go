@@ -0,0 +1,67 @@
+package ws
+
+import (
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Heartbeat describes a quiet room at the moment its heartbeat is due
+type Heartbeat struct {
+	RoomCode models.RoomCode
+	// Players and Spectators count the room's connections
+	Players    int
+	Spectators int
+	// Seq is how many broadcasts the room has been sent, heartbeats aside,
+	// so a client can tell whether it missed any since the last heartbeat
+	Seq int64
+	At  time.Time
+}
+
+// heartbeat is how a room's sender keeps a quiet room informed
+type heartbeat struct {
+	interval time.Duration
+	build    func(Heartbeat) []byte
+	// ticks and stop are the room's own ticker, once its sender starts
+	ticks <-chan time.Time
+	stop  func()
+}
+
+// SetHeartbeat makes every room's sender deliver the frame build returns
+// whenever the room has gone interval without a broadcast. A nil frame
+// sends nothing. Rooms whose sender is already running keep their old
+// setting; call it before serving clients.
+func (q *RoomQueues) SetHeartbeat(interval time.Duration, build func(Heartbeat) []byte) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if interval <= 0 || build == nil {
+		q.heartbeat = heartbeat{}
+		return
+	}
+	q.heartbeat = heartbeat{interval: interval, build: build}
+}
+
+// hasRoomClients reports whether anyone is connected to the room
+func (h *Hub) hasRoomClients(roomCode models.RoomCode) bool {
+	players, observers := h.roomCounts(roomCode)
+	return players+observers > 0
+}
+
+// roomCounts counts the room's player and observer connections
+func (h *Hub) roomCounts(roomCode models.RoomCode) (players, observers int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, client := range h.Clients {
+		if client.RoomCode != roomCode {
+			continue
+		}
+		if client.Observer {
+			observers++
+		} else {
+			players++
+		}
+	}
+	return players, observers
+}
@@ -0,0 +1,131 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// heartbeatRoom is ROOM1, with a player and a spectator if connected, whose
+// sender beats every interval on ticks the test sends itself. It also
+// returns how many heartbeats were built.
+func heartbeatRoom(t *testing.T, interval time.Duration, connected bool) (*RoomQueues, *Client, chan time.Time, *atomic.Int32) {
+	t.Helper()
+
+	hub := NewHub(DegradePolicy{})
+	player := NewClient(newFakeConn(t), "p1", "ROOM1", "127.0.0.1")
+	spectator := NewClient(newFakeConn(t), "o1", "ROOM1", "127.0.0.1")
+	spectator.Observer = true
+	if connected {
+		hub.RegisterNow(player)
+		hub.RegisterNow(spectator)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ticks := make(chan time.Time)
+	q := NewRoomQueues(hub)
+	q.newTicker = func(time.Duration) (<-chan time.Time, func()) { return ticks, func() {} }
+	q.SetSpawner(func(_ models.RoomCode, fn func(ctx context.Context)) bool {
+		go fn(ctx)
+		return true
+	})
+	var built atomic.Int32
+	q.SetHeartbeat(interval, func(beat Heartbeat) []byte {
+		built.Add(1)
+		return eventFrame(t, models.EventRoomHeartbeat, beat)
+	})
+	return q, player, ticks, &built
+}
+
+// nextFrame returns the next frame queued for the client
+func nextFrame(t *testing.T, client *Client) models.WSMessage {
+	t.Helper()
+
+	select {
+	case data := <-client.Send:
+		var msg models.WSMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("frame %q: %v", data, err)
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("nothing was sent")
+		return models.WSMessage{}
+	}
+}
+
+// heartbeatOf decodes a room_heartbeat frame
+func heartbeatOf(t *testing.T, msg models.WSMessage) Heartbeat {
+	t.Helper()
+
+	if msg.Type != models.EventRoomHeartbeat {
+		t.Fatalf("got %s, want a heartbeat", msg.Type)
+	}
+	var beat Heartbeat
+	data, _ := json.Marshal(msg.Payload)
+	json.Unmarshal(data, &beat)
+	return beat
+}
+
+func TestHeartbeatCadence(t *testing.T) {
+	const interval = 30 * time.Second
+	q, player, ticks, _ := heartbeatRoom(t, interval, true)
+	q.Broadcast(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, "hello", nil)})
+	nextFrame(t, player)
+
+	// A quiet room beats once per interval, counting who is there
+	start := time.Now()
+	for i := 1; i <= 3; i++ {
+		ticks <- start.Add(time.Duration(i) * interval)
+		beat := heartbeatOf(t, nextFrame(t, player))
+		if beat.Players != 1 || beat.Spectators != 1 {
+			t.Errorf("beat %d counted %d players and %d spectators, want 1 and 1", i, beat.Players, beat.Spectators)
+		}
+		// Heartbeats are not counted as broadcasts
+		if beat.Seq != 1 {
+			t.Errorf("beat %d has seq %d, want 1", i, beat.Seq)
+		}
+	}
+}
+
+func TestHeartbeatSuppressedByTraffic(t *testing.T) {
+	const interval = 30 * time.Second
+	q, player, ticks, _ := heartbeatRoom(t, interval, true)
+
+	for i := 0; i < 3; i++ {
+		q.Broadcast(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, "state", i)})
+		nextFrame(t, player)
+		// The tick comes within the interval of the broadcast
+		ticks <- time.Now()
+	}
+
+	// The first thing after the busy stretch is the first heartbeat due
+	ticks <- time.Now().Add(interval)
+	if beat := heartbeatOf(t, nextFrame(t, player)); beat.Seq != 3 {
+		t.Errorf("heartbeat seq = %d, want 3", beat.Seq)
+	}
+	select {
+	case data := <-player.Send:
+		t.Errorf("more was sent: %s", data)
+	default:
+	}
+}
+
+func TestEmptyRoomGetsNoHeartbeat(t *testing.T) {
+	const interval = 30 * time.Second
+	q, _, ticks, built := heartbeatRoom(t, interval, false)
+	q.Broadcast(&BroadcastMessage{RoomCode: "ROOM1", Message: eventFrame(t, "state", nil)})
+
+	// The sender takes each tick only once it is done with the last one
+	for i := 1; i <= 3; i++ {
+		ticks <- time.Now().Add(time.Duration(i) * interval)
+	}
+	if n := built.Load(); n != 0 {
+		t.Errorf("%d heartbeats were built for a room no one is in", n)
+	}
+}
@@ -0,0 +1,281 @@
+// Package ws is the websocket transport: the hub of connected clients, their
+// read and write pumps, per-room outbound queues, the bandwidth tracking
+// that degrades clients who fall behind, the delay lines of delayed
+// spectators, the latencies clients report and the lobby's anonymous
+// listeners.
+//
+// It knows nothing about game events. Frames arrive as bytes and are handed
+// to the callback the caller passes to ReadPump; what goes out is whatever
+// the caller queues with Broadcast or SendTo. Which events a degraded client
+// may miss is the caller's policy, given to NewHub.
+package ws
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// Hub tracks every connected client by ID
+type Hub struct {
+	Clients    map[string]*Client
+	Register   chan *Client
+	Unregister chan *Client
+	mu         sync.RWMutex
+
+	policy DegradePolicy
+
+	// now and afterFunc are the clock delay lines run on
+	now       func() time.Time
+	afterFunc func(d time.Duration, f func()) timer
+	// spawn watches each delay line for its room going away
+	spawn RoomSpawner
+}
+
+// NewHub returns a hub that degrades slow clients by the given policy. Call
+// Run to start it.
+func NewHub(policy DegradePolicy) *Hub {
+	return &Hub{
+		Clients:    make(map[string]*Client),
+		Register:   make(chan *Client),
+		Unregister: make(chan *Client),
+		policy:     policy,
+		now:        time.Now,
+		afterFunc:  afterFunc,
+	}
+}
+
+// BroadcastMessage is one frame on its way to a room's clients
+type BroadcastMessage struct {
+	RoomCode models.RoomCode
+	Message  []byte
+	// PerClient overrides Message for the listed client IDs. With a nil
+	// Message, only the listed clients receive anything.
+	PerClient map[string][]byte
+	// Legacy holds the whole-room frames for clients without scoped updates
+	// when they differ
+	Legacy *BroadcastMessage
+	// To limits delivery to this one connection, observers included
+	To *Client
+}
+
+// frameFor picks the frame a client should receive, nil for none
+func (m *BroadcastMessage) frameFor(client *Client) []byte {
+	if client.WantsSnapshots() && m.Legacy != nil {
+		m = m.Legacy
+	}
+	if personal, ok := m.PerClient[client.ID]; ok {
+		return personal
+	}
+	return m.Message
+}
+
+// SetProtocol switches a connected client to another protocol version
+func (h *Hub) SetProtocol(client *Client, version int) {
+	h.mu.Lock()
+	client.Protocol = version
+	h.mu.Unlock()
+}
+
+// HasSnapshotClients reports whether anyone in the room needs whole-room snapshots
+func (h *Hub) HasSnapshotClients(roomCode models.RoomCode) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, client := range h.Clients {
+		if client.RoomCode == roomCode && client.WantsSnapshots() {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtocolCounts reports how many connected clients speak each protocol
+// version, so we can tell when an old version can be dropped
+func (h *Hub) ProtocolCounts() map[int]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, client := range h.Clients {
+		counts[client.Protocol]++
+	}
+	return counts
+}
+
+// RegisterNow adds a client at once, so any broadcast built after it returns
+// takes the client's protocol and capabilities into account
+func (h *Hub) RegisterNow(client *Client) {
+	h.mu.Lock()
+	// The player's older connection stops getting anything, so end it
+	if previous := h.Clients[client.ID]; previous != nil && previous != client {
+		previous.setCloseReasonLocked(models.DisconnectSuperseded)
+		close(previous.Send)
+		if previous.delayed != nil {
+			previous.delayed.stop()
+		}
+	}
+	var line *delayLine
+	if client.Delay > 0 && client.delayed == nil {
+		line = &delayLine{done: make(chan struct{})}
+		client.delayed = line
+	}
+	h.Clients[client.ID] = client
+	spawn := h.spawn
+	h.mu.Unlock()
+	log.Printf("Client registered: %s in room %s", client.ID, client.RoomCode)
+
+	if line != nil && spawn != nil {
+		spawn(client.RoomCode, line.watch)
+	}
+}
+
+// SetSpawner makes every delay line registered from now on stop, with its
+// timer, when spawn cancels its room
+func (h *Hub) SetSpawner(spawn RoomSpawner) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.spawn = spawn
+}
+
+// Run serves the Register and Unregister channels
+func (h *Hub) Run() {
+	for {
+		select {
+		case client := <-h.Register:
+			h.RegisterNow(client)
+
+		case client := <-h.Unregister:
+			if h.drop(client) {
+				log.Printf("Client unregistered: %s", client.ID)
+			}
+		}
+	}
+}
+
+// deliver hands a message to its room's clients. A client persistently
+// behind is degraded: it is spared cosmetic events and gets only the latest
+// of each state snapshot. A client too slow to keep up with its send buffer
+// even so is dropped rather than allowed to hold up the room.
+func (h *Hub) deliver(message *BroadcastMessage) {
+	var slow []*Client
+	now := h.now()
+
+	h.mu.RLock()
+	for _, client := range h.Clients {
+		if message.To != nil && client != message.To {
+			continue
+		}
+		if message.To == nil && (client.RoomCode != message.RoomCode || client.Observer && client.delayed == nil) {
+			continue
+		}
+
+		data := message.frameFor(client)
+		if data == nil {
+			continue
+		}
+		if client.delayed != nil {
+			h.hold(client, data, now)
+			continue
+		}
+		if client.stats != nil {
+			if degraded, changed := client.stats.observe(len(client.Send), now); changed {
+				notice, _ := json.Marshal(models.WSMessage{
+					Type:    models.EventDegradedMode,
+					Payload: map[string]bool{"degraded": degraded},
+				})
+				select {
+				case client.Send <- notice:
+				default:
+				}
+			}
+			if client.stats.hold(data, h.policy) {
+				continue
+			}
+		}
+		select {
+		case client.Send <- data:
+		default:
+			slow = append(slow, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range slow {
+		h.SetCloseReason(client, models.DisconnectSlowConsumer)
+		if h.drop(client) {
+			log.Printf("Client %s dropped: send buffer full", client.ID)
+		}
+	}
+}
+
+// CloseRoom ends every connection in the room, observers included, with a
+// close message carrying code and text. Anything still on its way to them
+// is discarded.
+func (h *Hub) CloseRoom(roomCode models.RoomCode, code int, text, reason string) int {
+	frame := websocket.FormatCloseMessage(code, text)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	closed := 0
+	for id, client := range h.Clients {
+		if client.RoomCode != roomCode {
+			continue
+		}
+		client.setCloseReasonLocked(reason)
+		client.closeFrame = frame
+		delete(h.Clients, id)
+		close(client.Send)
+		if client.delayed != nil {
+			client.delayed.stop()
+		}
+		closed++
+	}
+	return closed
+}
+
+// Close ends one connection the way CloseRoom ends a room's: its write pump
+// sends what is already queued, then the close frame, then hangs up. Only
+// the write pump ever writes to a connection, so call this rather than
+// writing the frame from elsewhere. It reports false when the connection
+// is already gone or was replaced.
+func (h *Hub) Close(client *Client, code int, text, reason string) bool {
+	frame := websocket.FormatCloseMessage(code, text)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.Clients[client.ID] != client {
+		return false
+	}
+	client.setCloseReasonLocked(reason)
+	client.closeFrame = frame
+	delete(h.Clients, client.ID)
+	close(client.Send)
+	if client.delayed != nil {
+		client.delayed.stop()
+	}
+	return true
+}
+
+// drop removes the connection and closes its send channel, unless it is
+// already gone or was replaced by a newer connection for the same player
+func (h *Hub) drop(client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.Clients[client.ID] != client {
+		return false
+	}
+	delete(h.Clients, client.ID)
+	close(client.Send)
+	if client.delayed != nil {
+		client.delayed.stop()
+	}
+	return true
+}
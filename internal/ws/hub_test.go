@@ -0,0 +1,172 @@
+package ws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+func TestCloseSendsQueuedFramesThenCloseFrame(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	conn := newFakeConn(t)
+	client := NewClient(conn, "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(client)
+
+	done := make(chan struct{})
+	go func() {
+		client.WritePump(hub)
+		close(done)
+	}()
+	for _, message := range []string{"one", "two", "three"} {
+		client.Send <- []byte(message)
+	}
+
+	if !hub.Close(client, 4001, "unsupported protocol version", models.DisconnectUnsupportedProtocol) {
+		t.Fatal("Close did not find the client")
+	}
+	conn.waitClosed(t)
+	<-done
+
+	frames := conn.written()
+	if len(frames) != 4 {
+		t.Fatalf("wrote %d frames, want 3 messages and a close", len(frames))
+	}
+	for i, want := range []string{"one", "two", "three"} {
+		if frames[i].kind != websocket.TextMessage || string(frames[i].data) != want {
+			t.Errorf("frame %d = %q, want %q", i, frames[i].data, want)
+		}
+	}
+	if last := frames[3]; last.kind != websocket.CloseMessage ||
+		string(last.data) != string(websocket.FormatCloseMessage(4001, "unsupported protocol version")) {
+		t.Errorf("last frame = %d %q, want the close frame", last.kind, last.data)
+	}
+
+	if reason := hub.DisconnectReason(client, nil); reason != models.DisconnectUnsupportedProtocol {
+		t.Errorf("disconnect reason = %q", reason)
+	}
+	if hub.Close(client, 4001, "again", models.DisconnectUnsupportedProtocol) {
+		t.Error("a closed client was closed twice")
+	}
+}
+
+func TestCloseWhileWritingNeverWritesConcurrently(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	conn := newFakeConn(t)
+	client := NewClient(conn, "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(client)
+	go client.WritePump(hub)
+
+	// Broadcasts keep the write pump busy while the close comes in from
+	// another goroutine, as a hello rejected on the read pump does
+	for i := 0; i < 20; i++ {
+		hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: []byte("update")})
+	}
+	hub.Close(client, 4001, "unsupported protocol version", models.DisconnectUnsupportedProtocol)
+	conn.waitClosed(t)
+}
+
+func TestCloseIgnoresReplacedConnection(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	old := NewClient(newFakeConn(t), "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(old)
+	current := NewClient(newFakeConn(t), "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(current)
+
+	if hub.Close(old, 4001, "", models.DisconnectUnsupportedProtocol) {
+		t.Error("closing the replaced connection reported success")
+	}
+	if hub.Clients["p1"] != current {
+		t.Error("closing the replaced connection dropped the current one")
+	}
+}
+
+func TestFullSendBufferDropsAsSlowConsumer(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	client := NewClient(newFakeConn(t), "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(client)
+
+	for i := 0; i <= sendBuffer; i++ {
+		hub.deliver(&BroadcastMessage{RoomCode: "ROOM1", Message: []byte("update")})
+	}
+	if _, still := hub.Clients["p1"]; still {
+		t.Fatal("a client with a full send buffer was kept")
+	}
+	if reason := hub.DisconnectReason(client, nil); reason != models.DisconnectSlowConsumer {
+		t.Errorf("disconnect reason = %q, want %s", reason, models.DisconnectSlowConsumer)
+	}
+}
+
+func TestFailedWriteIsRecorded(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	conn := newFakeConn(t)
+	client := NewClient(conn, "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(client)
+	conn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		client.WritePump(hub)
+		close(done)
+	}()
+	client.Send <- []byte("update")
+	<-done
+
+	if reason := hub.DisconnectReason(client, nil); reason != models.DisconnectWriteFailed {
+		t.Errorf("disconnect reason = %q, want %s", reason, models.DisconnectWriteFailed)
+	}
+}
+
+func TestReadErrorsWithoutAServerReason(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	client := NewClient(newFakeConn(t), "p1", "ROOM1", "127.0.0.1")
+
+	closed := &websocket.CloseError{Code: websocket.CloseGoingAway}
+	if reason := hub.DisconnectReason(client, closed); reason != models.DisconnectClientClosed {
+		t.Errorf("a going-away close = %q, want %s", reason, models.DisconnectClientClosed)
+	}
+	lost := &websocket.CloseError{Code: websocket.CloseAbnormalClosure}
+	if reason := hub.DisconnectReason(client, lost); reason != models.DisconnectConnectionLost {
+		t.Errorf("an abnormal close = %q, want %s", reason, models.DisconnectConnectionLost)
+	}
+}
+
+func TestFramesFollowCapabilities(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	scoped := NewDetachedClient("p1", "ROOM1")
+	scoped.Capabilities = map[Capability]bool{CapScopedUpdates: true, CapChatReplay: true}
+	snapshots := NewDetachedClient("p2", "ROOM1")
+	snapshots.Capabilities = map[Capability]bool{CapChatReplay: true}
+	hub.RegisterNow(scoped)
+	hub.RegisterNow(snapshots)
+
+	message := &BroadcastMessage{
+		RoomCode: "ROOM1",
+		Message:  []byte("scoped"),
+		Legacy:   &BroadcastMessage{Message: []byte("room"), PerClient: map[string][]byte{"p2": []byte("p2's room")}},
+	}
+	if frame := string(message.frameFor(scoped)); frame != "scoped" {
+		t.Errorf("scoped client gets %q", frame)
+	}
+	if frame := string(message.frameFor(snapshots)); frame != "p2's room" {
+		t.Errorf("snapshot client gets %q", frame)
+	}
+	if !hub.HasSnapshotClients("ROOM1") {
+		t.Error("p2 needs snapshots")
+	}
+
+	want := map[Capability]int{CapScopedUpdates: 1, CapChatReplay: 2}
+	if counts := hub.CapabilityCounts(); !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	// Switching to scoped updates moves p2 off snapshots
+	hub.SetCapabilities(snapshots, map[Capability]bool{CapScopedUpdates: true})
+	if hub.HasSnapshotClients("ROOM1") || !hub.HasCapability(snapshots, CapScopedUpdates) {
+		t.Error("p2 still takes snapshots")
+	}
+	if counts := hub.CapabilityCounts(); counts[CapScopedUpdates] != 2 || counts[CapChatReplay] != 1 {
+		t.Errorf("counts after the switch = %v", counts)
+	}
+}
@@ -0,0 +1,189 @@
+package ws
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const (
+	// MinPingInterval is the shortest gap allowed between one client's
+	// pings; pings sent faster are ignored
+	MinPingInterval = 2 * time.Second
+	// latencyWindow is how far back a room's latency percentiles look
+	latencyWindow = 5 * time.Minute
+	// latencySamples caps the samples kept per room
+	latencySamples = 512
+	// maxLatency is the longest measurement believed; anything over it is a
+	// client clock or a suspended tab, not the network
+	maxLatency = time.Minute
+)
+
+// LatencyPercentiles summarizes a room's recent measurements in milliseconds
+type LatencyPercentiles struct {
+	Samples int     `json:"samples"`
+	P50Ms   float64 `json:"p50Ms"`
+	P90Ms   float64 `json:"p90Ms"`
+	P99Ms   float64 `json:"p99Ms"`
+}
+
+// RoomLatency is what a room's clients measured over the window: the round
+// trip of their pings and, from clients that report it, how long broadcasts
+// took to reach them
+type RoomLatency struct {
+	RoundTrip LatencyPercentiles  `json:"roundTrip"`
+	Delivery  *LatencyPercentiles `json:"delivery,omitempty"`
+}
+
+// latencySample is one client report; delivery is negative when the client
+// did not measure it
+type latencySample struct {
+	at       time.Time
+	rtt      time.Duration
+	delivery time.Duration
+}
+
+// LatencyTracker keeps the latencies clients report, per room
+type LatencyTracker struct {
+	mu    sync.Mutex
+	rooms map[models.RoomCode][]latencySample
+}
+
+// NewLatencyTracker returns a tracker with no samples
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{rooms: make(map[models.RoomCode][]latencySample)}
+}
+
+// Record adds a client's measured round trip and, when reported is set,
+// its broadcast delivery delay. Implausible measurements are ignored.
+func (t *LatencyTracker) Record(roomCode models.RoomCode, rtt, delivery time.Duration, reported bool, now time.Time) {
+	if rtt < 0 || rtt > maxLatency {
+		return
+	}
+	if !reported || delivery < 0 || delivery > maxLatency {
+		delivery = -1
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := append(t.pruneLocked(roomCode, now), latencySample{at: now, rtt: rtt, delivery: delivery})
+	if excess := len(samples) - latencySamples; excess > 0 {
+		samples = append([]latencySample(nil), samples[excess:]...)
+	}
+	t.rooms[roomCode] = samples
+}
+
+// Room summarizes the room's samples from the window, false when it has none
+func (t *LatencyTracker) Room(roomCode models.RoomCode, now time.Time) (RoomLatency, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := t.pruneLocked(roomCode, now)
+	if len(samples) == 0 {
+		return RoomLatency{}, false
+	}
+	return summarize(samples), true
+}
+
+// Rooms summarizes every room with samples in the window
+func (t *LatencyTracker) Rooms(now time.Time) map[models.RoomCode]RoomLatency {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rooms := make(map[models.RoomCode]RoomLatency, len(t.rooms))
+	for roomCode := range t.rooms {
+		if samples := t.pruneLocked(roomCode, now); len(samples) > 0 {
+			rooms[roomCode] = summarize(samples)
+		}
+	}
+	return rooms
+}
+
+// Overall summarizes the samples of every room together, false when there
+// are none in the window
+func (t *LatencyTracker) Overall(now time.Time) (RoomLatency, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var all []latencySample
+	for roomCode := range t.rooms {
+		all = append(all, t.pruneLocked(roomCode, now)...)
+	}
+	if len(all) == 0 {
+		return RoomLatency{}, false
+	}
+	return summarize(all), true
+}
+
+// pruneLocked drops the room's samples older than the window, forgetting
+// the room once none are left
+func (t *LatencyTracker) pruneLocked(roomCode models.RoomCode, now time.Time) []latencySample {
+	samples := t.rooms[roomCode]
+	cutoff := now.Add(-latencyWindow)
+	kept := 0
+	for kept < len(samples) && samples[kept].at.Before(cutoff) {
+		kept++
+	}
+	samples = samples[kept:]
+
+	if len(samples) == 0 {
+		delete(t.rooms, roomCode)
+		return nil
+	}
+	t.rooms[roomCode] = samples
+	return samples
+}
+
+func summarize(samples []latencySample) RoomLatency {
+	rtts := make([]time.Duration, 0, len(samples))
+	var deliveries []time.Duration
+	for _, sample := range samples {
+		rtts = append(rtts, sample.rtt)
+		if sample.delivery >= 0 {
+			deliveries = append(deliveries, sample.delivery)
+		}
+	}
+
+	latency := RoomLatency{RoundTrip: percentiles(rtts)}
+	if len(deliveries) > 0 {
+		delivery := percentiles(deliveries)
+		latency.Delivery = &delivery
+	}
+	return latency
+}
+
+// percentiles takes the nearest-rank percentiles of the durations
+func percentiles(durations []time.Duration) LatencyPercentiles {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(durations)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return float64(durations[i]) / float64(time.Millisecond)
+	}
+	return LatencyPercentiles{
+		Samples: len(durations),
+		P50Ms:   rank(0.50),
+		P90Ms:   rank(0.90),
+		P99Ms:   rank(0.99),
+	}
+}
+
+// AllowPing reports whether the client may be answered, counting the ping
+// against its rate limit when it may
+func (h *Hub) AllowPing(client *Client, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !client.lastPingAt.IsZero() && now.Sub(client.lastPingAt) < MinPingInterval {
+		return false
+	}
+	client.lastPingAt = now
+	return true
+}
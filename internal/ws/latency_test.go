@@ -0,0 +1,104 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyPercentiles(t *testing.T) {
+	tracker := NewLatencyTracker()
+	now := time.Now()
+	for ms := 100; ms >= 1; ms-- {
+		tracker.Record("ROOM1", time.Duration(ms)*time.Millisecond, 0, false, now)
+	}
+
+	measured, ok := tracker.Room("ROOM1", now)
+	if !ok {
+		t.Fatal("the room has no latency")
+	}
+	want := LatencyPercentiles{Samples: 100, P50Ms: 50, P90Ms: 90, P99Ms: 99}
+	if measured.RoundTrip != want {
+		t.Errorf("round trip = %+v, want %+v", measured.RoundTrip, want)
+	}
+	if measured.Delivery != nil {
+		t.Errorf("delivery = %+v without any reported", measured.Delivery)
+	}
+}
+
+func TestLatencyDeliveryIsOptional(t *testing.T) {
+	tracker := NewLatencyTracker()
+	now := time.Now()
+	tracker.Record("ROOM1", 40*time.Millisecond, 0, false, now)
+	tracker.Record("ROOM1", 60*time.Millisecond, 15*time.Millisecond, true, now)
+
+	measured, _ := tracker.Room("ROOM1", now)
+	if measured.RoundTrip.Samples != 2 {
+		t.Errorf("%d round trips, want 2", measured.RoundTrip.Samples)
+	}
+	if measured.Delivery == nil || measured.Delivery.Samples != 1 || measured.Delivery.P50Ms != 15 {
+		t.Errorf("delivery = %+v, want the one report of 15ms", measured.Delivery)
+	}
+}
+
+func TestLatencyIgnoresImplausibleReports(t *testing.T) {
+	tracker := NewLatencyTracker()
+	now := time.Now()
+	tracker.Record("ROOM1", -time.Millisecond, 0, false, now)
+	tracker.Record("ROOM1", maxLatency+time.Millisecond, 0, false, now)
+	if _, ok := tracker.Room("ROOM1", now); ok {
+		t.Fatal("implausible round trips were kept")
+	}
+
+	tracker.Record("ROOM1", 20*time.Millisecond, maxLatency+time.Millisecond, true, now)
+	if measured, _ := tracker.Room("ROOM1", now); measured.Delivery != nil {
+		t.Errorf("an implausible delivery was kept: %+v", measured.Delivery)
+	}
+}
+
+func TestLatencyWindowAndCap(t *testing.T) {
+	tracker := NewLatencyTracker()
+	start := time.Now()
+	tracker.Record("ROOM1", time.Second, 0, false, start)
+	for i := 0; i < latencySamples+10; i++ {
+		tracker.Record("ROOM2", time.Millisecond, 0, false, start)
+	}
+
+	later := start.Add(latencyWindow / 2)
+	tracker.Record("ROOM1", 10*time.Millisecond, 0, false, later)
+	if measured, _ := tracker.Room("ROOM2", later); measured.RoundTrip.Samples != latencySamples {
+		t.Errorf("%d samples kept, want %d", measured.RoundTrip.Samples, latencySamples)
+	}
+	if overall, _ := tracker.Overall(later); overall.RoundTrip.Samples != latencySamples+2 {
+		t.Errorf("overall has %d samples, want every room's", overall.RoundTrip.Samples)
+	}
+
+	// The first measurements age out of the window
+	end := start.Add(latencyWindow + time.Second)
+	if measured, _ := tracker.Room("ROOM1", end); measured.RoundTrip.Samples != 1 || measured.RoundTrip.P99Ms != 10 {
+		t.Errorf("ROOM1 = %+v, want only the later sample", measured.RoundTrip)
+	}
+	rooms := tracker.Rooms(end)
+	if _, ok := rooms["ROOM2"]; ok || len(rooms) != 1 {
+		t.Errorf("rooms = %v, want ROOM2 forgotten", rooms)
+	}
+}
+
+func TestPingsAreRateLimitedPerClient(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	client := NewClient(newFakeConn(t), "p1", "ROOM1", "127.0.0.1")
+	other := NewClient(newFakeConn(t), "p2", "ROOM1", "127.0.0.1")
+	now := time.Now()
+
+	if !hub.AllowPing(client, now) {
+		t.Fatal("the first ping was refused")
+	}
+	if hub.AllowPing(client, now.Add(MinPingInterval-time.Millisecond)) {
+		t.Error("a ping inside the interval was allowed")
+	}
+	if !hub.AllowPing(other, now) {
+		t.Error("another client was held back")
+	}
+	if !hub.AllowPing(client, now.Add(MinPingInterval)) {
+		t.Error("a ping after the interval was refused")
+	}
+}
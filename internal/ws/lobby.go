@@ -0,0 +1,175 @@
+package ws
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// lobbyBuffer is how many frames may wait for a lobby subscriber before
+	// it is dropped as too slow
+	lobbyBuffer = 64
+	// lobbyConnsPerAddr caps the lobby connections open from one address
+	lobbyConnsPerAddr = 4
+	// lobbyConnectsPerMinute caps how often one address may connect
+	lobbyConnectsPerMinute = 10
+)
+
+// ErrLobbyRateLimited is returned when an address opens lobby connections
+// too often or holds too many at once
+var ErrLobbyRateLimited = errors.New("too many lobby connections")
+
+// Lobby fans frames out to anonymous subscribers of the public room
+// listing. Subscribers only listen; anything they send is discarded.
+type Lobby struct {
+	mu          sync.Mutex
+	subscribers map[*LobbySubscriber]bool
+	open        map[string]int         // open connections by address hash
+	connects    map[string][]time.Time // recent connects by address hash
+}
+
+// LobbySubscriber is one connection listening to the lobby
+type LobbySubscriber struct {
+	Conn     Conn
+	Send     chan []byte
+	addrHash string
+}
+
+// NewLobby returns a lobby with no subscribers
+func NewLobby() *Lobby {
+	return &Lobby{
+		subscribers: make(map[*LobbySubscriber]bool),
+		open:        make(map[string]int),
+		connects:    make(map[string][]time.Time),
+	}
+}
+
+// Admit reserves a connection for addr, or returns ErrLobbyRateLimited.
+// Call it before upgrading so a refused client gets a plain HTTP error; a
+// reservation that is not followed by Subscribe must be given back with
+// Release.
+func (l *Lobby) Admit(addr string, now time.Time) error {
+	hash := HashAddr(addr)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recent := l.connects[hash][:0]
+	for _, at := range l.connects[hash] {
+		if now.Sub(at) < time.Minute {
+			recent = append(recent, at)
+		}
+	}
+	l.connects[hash] = recent
+
+	if l.open[hash] >= lobbyConnsPerAddr || len(recent) >= lobbyConnectsPerMinute {
+		if len(recent) == 0 {
+			delete(l.connects, hash)
+		}
+		return ErrLobbyRateLimited
+	}
+
+	l.connects[hash] = append(recent, now)
+	l.open[hash]++
+	return nil
+}
+
+// Release gives back a reservation from Admit
+func (l *Lobby) Release(addr string) {
+	l.mu.Lock()
+	l.releaseLocked(HashAddr(addr))
+	l.mu.Unlock()
+}
+
+func (l *Lobby) releaseLocked(hash string) {
+	l.open[hash]--
+	if l.open[hash] <= 0 {
+		delete(l.open, hash)
+	}
+}
+
+// Subscribe starts delivering broadcasts to a connection admitted for addr.
+// Frames broadcast from now on are queued until Serve writes them.
+func (l *Lobby) Subscribe(conn Conn, addr string) *LobbySubscriber {
+	sub := &LobbySubscriber{
+		Conn:     conn,
+		Send:     make(chan []byte, lobbyBuffer),
+		addrHash: HashAddr(addr),
+	}
+
+	l.mu.Lock()
+	l.subscribers[sub] = true
+	l.mu.Unlock()
+	return sub
+}
+
+// Broadcast queues a frame for every subscriber. One that has fallen
+// lobbyBuffer frames behind is dropped; it can reconnect for a fresh listing.
+func (l *Lobby) Broadcast(data []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for sub := range l.subscribers {
+		select {
+		case sub.Send <- data:
+		default:
+			log.Printf("Dropping slow lobby subscriber")
+			l.unsubscribeLocked(sub)
+		}
+	}
+}
+
+// Unsubscribe stops deliveries to a subscriber that will not be served
+func (l *Lobby) Unsubscribe(sub *LobbySubscriber) {
+	l.mu.Lock()
+	l.unsubscribeLocked(sub)
+	l.mu.Unlock()
+}
+
+// Subscribers reports how many connections are listening
+func (l *Lobby) Subscribers() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.subscribers)
+}
+
+// unsubscribeLocked stops deliveries and lets the write pump finish
+func (l *Lobby) unsubscribeLocked(sub *LobbySubscriber) {
+	if !l.subscribers[sub] {
+		return
+	}
+	delete(l.subscribers, sub)
+	close(sub.Send)
+	l.releaseLocked(sub.addrHash)
+}
+
+// Serve writes first and then the subscriber's queued frames until the
+// connection ends. It blocks until then.
+func (l *Lobby) Serve(sub *LobbySubscriber, first []byte) {
+	go func() {
+		defer sub.Conn.Close()
+
+		if err := sub.Conn.WriteMessage(websocket.TextMessage, first); err != nil {
+			return
+		}
+		for message := range sub.Send {
+			if err := sub.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Reading is only how a closed connection is noticed
+	for {
+		if _, _, err := sub.Conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+
+	l.Unsubscribe(sub)
+	sub.Conn.Close()
+}
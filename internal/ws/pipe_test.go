@@ -0,0 +1,179 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// pipeListener accepts the server ends of net.Pipe connections
+type pipeListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	close(l.closed)
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: "pipe", Net: "pipe"}
+}
+
+// pipeSocket returns both ends of a websocket carried over net.Pipe: the
+// server's, for the pumps, and the browser's
+func pipeSocket(t *testing.T) (server, browser *websocket.Conn) {
+	t.Helper()
+
+	listener := &pipeListener{conns: make(chan net.Conn), closed: make(chan struct{})}
+	upgraded := make(chan *websocket.Conn, 1)
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		upgraded <- conn
+	})}
+	go httpServer.Serve(listener)
+	t.Cleanup(func() { httpServer.Close() })
+
+	dialer := websocket.Dialer{NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		serverEnd, browserEnd := net.Pipe()
+		listener.conns <- serverEnd
+		return browserEnd, nil
+	}}
+	browser, _, err := dialer.Dial("ws://pipe/", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { browser.Close() })
+	return <-upgraded, browser
+}
+
+// readText reads the browser's next frame, failing the test on anything else
+func readText(t *testing.T, browser *websocket.Conn) string {
+	t.Helper()
+
+	browser.SetReadDeadline(time.Now().Add(2 * time.Second))
+	kind, data, err := browser.ReadMessage()
+	if err != nil || kind != websocket.TextMessage {
+		t.Fatalf("read: %d %q %v", kind, data, err)
+	}
+	return string(data)
+}
+
+func TestWritePumpOverAPipe(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	server, browser := pipeSocket(t)
+	client := NewClient(server, "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(client)
+	go client.WritePump(hub)
+
+	for _, message := range []string{"one", "two", "three"} {
+		client.Send <- []byte(message)
+	}
+	for _, want := range []string{"one", "two", "three"} {
+		if got := readText(t, browser); got != want {
+			t.Errorf("read %q, want %q", got, want)
+		}
+	}
+
+	// The server hangs up straight after its close frame, so there is no one
+	// to echo it back to
+	browser.SetCloseHandler(func(int, string) error { return nil })
+	hub.Close(client, 4001, "unsupported protocol version", models.DisconnectUnsupportedProtocol)
+	browser.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := browser.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != 4001 || closeErr.Text != "unsupported protocol version" {
+		t.Errorf("after the frames the browser got %v, want the close frame", err)
+	}
+}
+
+func TestReadPumpOverAPipe(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	go hub.Run()
+	server, browser := pipeSocket(t)
+	client := NewClient(server, "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(client)
+
+	handled := make(chan string, 4)
+	closed := make(chan error, 1)
+	go client.ReadPump(hub,
+		func(message []byte) { handled <- string(message) },
+		func(readErr error) { closed <- readErr })
+
+	for _, message := range []string{"one", "two"} {
+		if err := browser.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+			t.Fatalf("write %s: %v", message, err)
+		}
+		select {
+		case got := <-handled:
+			if got != message {
+				t.Errorf("handled %q, want %q", got, message)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s was never handled", message)
+		}
+	}
+
+	// The browser keeps reading, to take the server's echo of its close
+	go func() {
+		for {
+			if _, _, err := browser.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	browser.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	select {
+	case readErr := <-closed:
+		if reason := hub.DisconnectReason(client, readErr); reason != models.DisconnectClientClosed {
+			t.Errorf("a browser that closed was recorded as %q", reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the read pump did not stop")
+	}
+	waitFor(t, func() bool { return len(hub.ClientStats(time.Now())) == 0 })
+}
+
+func TestWritePumpStopsWhenTheBrowserIsGone(t *testing.T) {
+	hub := NewHub(DegradePolicy{})
+	server, browser := pipeSocket(t)
+	client := NewClient(server, "p1", "ROOM1", "127.0.0.1")
+	hub.RegisterNow(client)
+
+	done := make(chan struct{})
+	go func() {
+		client.WritePump(hub)
+		close(done)
+	}()
+	browser.UnderlyingConn().Close()
+	client.Send <- []byte("lost")
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the write pump kept going after a failed write")
+	}
+	if reason := hub.DisconnectReason(client, nil); reason != models.DisconnectWriteFailed {
+		t.Errorf("disconnect reason = %q, want %s", reason, models.DisconnectWriteFailed)
+	}
+}
@@ -0,0 +1,281 @@
+package ws
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+const (
+	// roomQueueSize bounds the broadcasts waiting for one room's sender
+	roomQueueSize = 256
+	// roomQueueIdle is how long a room's sender waits for work before exiting
+	roomQueueIdle = time.Minute
+)
+
+// RoomQueues gives every room its own bounded outbound queue drained by its
+// own goroutine, so a room whose clients are slow never holds up another
+// room's broadcasts, and queueing never blocks a caller holding game locks
+type RoomQueues struct {
+	hub       *Hub
+	mu        sync.Mutex
+	queues    map[models.RoomCode]*roomQueue
+	heartbeat heartbeat
+	spawn     RoomSpawner
+	dropped   atomic.Int64
+	// newTicker starts the ticks a room's sender checks its heartbeat on
+	newTicker func(interval time.Duration) (ticks <-chan time.Time, stop func())
+}
+
+// RoomSpawner runs fn in a goroutine scoped to the room, whose context is
+// cancelled when the room goes away. It reports false, without running fn,
+// when it knows no such room.
+type RoomSpawner func(roomCode models.RoomCode, fn func(ctx context.Context)) bool
+
+type roomQueue struct {
+	mu       sync.Mutex
+	messages []*BroadcastMessage
+	// ready wakes the sender when messages are waiting
+	ready       chan struct{}
+	overflowing bool // logged once per overflow, until a message fits again
+	closed      bool // the sender exited; a new queue takes over
+}
+
+// signalLocked wakes the sender, if it is not already due to wake
+func (queue *roomQueue) signalLocked() {
+	select {
+	case queue.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop takes the oldest waiting message, nil if there is none
+func (queue *roomQueue) pop() *BroadcastMessage {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if len(queue.messages) == 0 {
+		return nil
+	}
+	message := queue.messages[0]
+	queue.messages[0] = nil
+	queue.messages = queue.messages[1:]
+	if len(queue.messages) > 0 {
+		queue.signalLocked()
+	}
+	return message
+}
+
+// oldestBroadcastLocked is the index of the oldest message for the whole
+// room, -1 if every waiting message is for one client
+func (queue *roomQueue) oldestBroadcastLocked() int {
+	for i, message := range queue.messages {
+		if message.To == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewRoomQueues returns queues that deliver through the hub
+func NewRoomQueues(hub *Hub) *RoomQueues {
+	return &RoomQueues{hub: hub, queues: make(map[models.RoomCode]*roomQueue), newTicker: newTicker}
+}
+
+func newTicker(interval time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+// SendTo queues a frame for one client, after anything already queued for
+// its room. Frames for a detached client are kept as its replies.
+func (q *RoomQueues) SendTo(client *Client, data []byte) {
+	if client.detached {
+		client.replies = append(client.replies, data)
+		return
+	}
+	q.Broadcast(&BroadcastMessage{RoomCode: client.RoomCode, Message: data, To: client})
+}
+
+// Broadcast adds a message to its room's queue without blocking. When the
+// queue is full the oldest waiting message for the whole room is dropped to
+// make room. Frames for one client, such as a snapshot or a reply, are
+// never dropped: losing one would leave that client out of step with no
+// later broadcast to catch it up, so they may take the queue past its bound.
+func (q *RoomQueues) Broadcast(message *BroadcastMessage) {
+	roomCode := message.RoomCode
+
+	for {
+		queue := q.queue(roomCode)
+
+		queue.mu.Lock()
+		if queue.closed {
+			queue.mu.Unlock()
+			continue
+		}
+
+		queue.messages = append(queue.messages, message)
+		if len(queue.messages) <= roomQueueSize {
+			queue.overflowing = false
+		} else if i := queue.oldestBroadcastLocked(); i >= 0 {
+			queue.messages = append(queue.messages[:i], queue.messages[i+1:]...)
+			q.dropped.Add(1)
+			if !queue.overflowing {
+				queue.overflowing = true
+				log.Printf("Room %s outbound queue overflowed; dropping the oldest broadcasts", roomCode)
+			}
+		}
+		queue.signalLocked()
+		queue.mu.Unlock()
+		return
+	}
+}
+
+// queue returns the room's queue, starting its sender if there is none
+func (q *RoomQueues) queue(roomCode models.RoomCode) *roomQueue {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue := q.queues[roomCode]
+	if queue == nil {
+		queue = &roomQueue{ready: make(chan struct{}, 1)}
+		q.queues[roomCode] = queue
+		beat := q.heartbeat
+		if beat.build != nil {
+			beat.ticks, beat.stop = q.newTicker(beat.interval)
+		}
+		drain := func(ctx context.Context) { q.drain(ctx, roomCode, queue, beat) }
+		if q.spawn == nil || !q.spawn(roomCode, drain) {
+			go drain(context.Background())
+		}
+	}
+	return queue
+}
+
+// SetSpawner makes every room's sender run through spawn, so it stops with
+// its room. Senders for rooms spawn does not know, and those already
+// running, are left to exit once idle; call it before serving clients.
+func (q *RoomQueues) SetSpawner(spawn RoomSpawner) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.spawn = spawn
+}
+
+// drain delivers a room's messages in order, and a heartbeat whenever the
+// room goes quiet for the heartbeat interval. It exits once the room has
+// been idle for roomQueueIdle, or with heartbeats on, once it is also
+// empty; and at once when ctx is done, dropping whatever is still queued.
+func (q *RoomQueues) drain(ctx context.Context, roomCode models.RoomCode, queue *roomQueue, beat heartbeat) {
+	idle := time.NewTimer(roomQueueIdle)
+	defer idle.Stop()
+
+	ticks := beat.ticks
+	if beat.stop != nil {
+		defer beat.stop()
+	}
+
+	// seq counts the room's broadcasts while this sender runs
+	var seq int64
+	lastSent := time.Now()
+
+	for {
+		select {
+		case <-queue.ready:
+			message := queue.pop()
+			if message == nil {
+				continue
+			}
+			q.hub.deliver(message)
+			seq++
+			lastSent = time.Now()
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(roomQueueIdle)
+
+		case now := <-ticks:
+			// A room that heard something within the interval needs no heartbeat
+			if now.Sub(lastSent) < beat.interval {
+				continue
+			}
+			players, spectators := q.hub.roomCounts(roomCode)
+			if players+spectators == 0 {
+				continue
+			}
+			data := beat.build(Heartbeat{
+				RoomCode:   roomCode,
+				Players:    players,
+				Spectators: spectators,
+				Seq:        seq,
+				At:         now,
+			})
+			if data != nil {
+				q.hub.deliver(&BroadcastMessage{RoomCode: roomCode, Message: data})
+			}
+
+		case <-ctx.Done():
+			q.mu.Lock()
+			queue.mu.Lock()
+			queue.closed = true
+			if q.queues[roomCode] == queue {
+				delete(q.queues, roomCode)
+			}
+			queue.mu.Unlock()
+			q.mu.Unlock()
+			return
+
+		case <-idle.C:
+			occupied := ticks != nil && q.hub.hasRoomClients(roomCode)
+			q.mu.Lock()
+			queue.mu.Lock()
+			if len(queue.messages) == 0 && !occupied {
+				queue.closed = true
+				delete(q.queues, roomCode)
+				queue.mu.Unlock()
+				q.mu.Unlock()
+				return
+			}
+			queue.mu.Unlock()
+			q.mu.Unlock()
+			idle.Reset(roomQueueIdle)
+		}
+	}
+}
+
+// CloseRoom discards the broadcasts still waiting for the room and ends
+// its connections with a close message carrying code and text (see
+// Hub.CloseRoom). A broadcast already being delivered finds no one left.
+func (q *RoomQueues) CloseRoom(roomCode models.RoomCode, code int, text, reason string) int {
+	q.mu.Lock()
+	if queue := q.queues[roomCode]; queue != nil {
+		queue.mu.Lock()
+		queue.messages = nil
+		queue.mu.Unlock()
+	}
+	q.mu.Unlock()
+
+	return q.hub.CloseRoom(roomCode, code, text, reason)
+}
+
+// QueueDepth reports how many broadcasts are waiting for the room's sender
+func (q *RoomQueues) QueueDepth(roomCode models.RoomCode) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if queue := q.queues[roomCode]; queue != nil {
+		queue.mu.Lock()
+		defer queue.mu.Unlock()
+		return len(queue.messages)
+	}
+	return 0
+}
+
+// Dropped reports how many broadcasts were dropped because a room's
+// outbound queue overflowed
+func (q *RoomQueues) Dropped() int64 {
+	return q.dropped.Load()
+}
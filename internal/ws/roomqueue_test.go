@@ -0,0 +1,190 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/werewolf-game/backend/internal/models"
+)
+
+// heldRoom is ROOM1 with one player whose sender does not start until
+// release is called, so the test can fill the room's queue
+func heldRoom(t *testing.T) (q *RoomQueues, player *Client, release func()) {
+	t.Helper()
+
+	hub := NewHub(DegradePolicy{})
+	player = NewClient(newFakeConn(t), "p1", "ROOM1", "127.0.0.1")
+	player.Send = make(chan []byte, 2*roomQueueSize)
+	player.stats = nil
+	hub.RegisterNow(player)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	senders := make(chan func(ctx context.Context), 1)
+	q = NewRoomQueues(hub)
+	q.SetSpawner(func(_ models.RoomCode, fn func(ctx context.Context)) bool {
+		senders <- fn
+		return true
+	})
+	return q, player, func() { go (<-senders)(ctx) }
+}
+
+// received reads n frames sent to the client
+func received(t *testing.T, client *Client, n int) []string {
+	t.Helper()
+
+	frames := make([]string, n)
+	for i := range frames {
+		select {
+		case data := <-client.Send:
+			frames[i] = string(data)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of %d frames arrived", i, n)
+		}
+	}
+	return frames
+}
+
+func TestOverflowDropsTheOldestBroadcasts(t *testing.T) {
+	q, player, release := heldRoom(t)
+
+	for i := 0; i < roomQueueSize+10; i++ {
+		q.Broadcast(&BroadcastMessage{RoomCode: "ROOM1", Message: []byte(fmt.Sprintf("b%d", i))})
+	}
+	if depth, dropped := q.QueueDepth("ROOM1"), q.Dropped(); depth != roomQueueSize || dropped != 10 {
+		t.Fatalf("depth %d, dropped %d; want %d and 10", depth, dropped, roomQueueSize)
+	}
+
+	release()
+	frames := received(t, player, roomQueueSize)
+	if frames[0] != "b10" || frames[roomQueueSize-1] != fmt.Sprintf("b%d", roomQueueSize+9) {
+		t.Errorf("delivered %s to %s, want the newest %d in order", frames[0], frames[roomQueueSize-1], roomQueueSize)
+	}
+}
+
+func TestTargetedFramesSurviveOverflow(t *testing.T) {
+	q, player, release := heldRoom(t)
+
+	q.SendTo(player, []byte("snapshot"))
+	for i := 0; i < roomQueueSize+10; i++ {
+		q.Broadcast(&BroadcastMessage{RoomCode: "ROOM1", Message: []byte(fmt.Sprintf("b%d", i))})
+	}
+	q.SendTo(player, []byte("reply"))
+
+	// The snapshot held its place and the reply pushed out a broadcast instead
+	if depth, dropped := q.QueueDepth("ROOM1"), q.Dropped(); depth != roomQueueSize || dropped != 12 {
+		t.Fatalf("depth %d, dropped %d; want %d and 12", depth, dropped, roomQueueSize)
+	}
+	release()
+	frames := received(t, player, roomQueueSize)
+	if frames[0] != "snapshot" || frames[1] != "b12" || frames[roomQueueSize-1] != "reply" {
+		t.Errorf("delivered %s, %s ... %s; want the snapshot, b12 ... the reply", frames[0], frames[1], frames[roomQueueSize-1])
+	}
+}
+
+func TestTargetedFramesMayExceedTheBound(t *testing.T) {
+	q, player, release := heldRoom(t)
+
+	for i := 0; i < roomQueueSize+5; i++ {
+		q.SendTo(player, []byte(strconv.Itoa(i)))
+	}
+	if depth, dropped := q.QueueDepth("ROOM1"), q.Dropped(); depth != roomQueueSize+5 || dropped != 0 {
+		t.Fatalf("depth %d, dropped %d; want every targeted frame kept", depth, dropped)
+	}
+
+	// With nothing else to drop, a broadcast into the full queue is the one dropped
+	q.Broadcast(&BroadcastMessage{RoomCode: "ROOM1", Message: []byte("late")})
+	if q.Dropped() != 1 {
+		t.Errorf("dropped %d, want the late broadcast", q.Dropped())
+	}
+
+	release()
+	frames := received(t, player, roomQueueSize+5)
+	for i, frame := range frames {
+		if frame != strconv.Itoa(i) {
+			t.Fatalf("frame %d = %q, want the targeted frames in order", i, frame)
+		}
+	}
+}
+
+// BenchmarkRoomsBesideASlowClient broadcasts a round to 500 rooms at once
+// and reports the p99 time for a frame to reach its client in the rooms
+// other than the first, with and without a client in the first room whose
+// connection takes a millisecond per frame
+func BenchmarkRoomsBesideASlowClient(b *testing.B) {
+	const rooms = 500
+
+	for _, slow := range []bool{false, true} {
+		name := "all fast"
+		if slow {
+			name = "one slow client"
+		}
+		b.Run(name, func(b *testing.B) {
+			hub := NewHub(DegradePolicy{})
+			q := NewRoomQueues(hub)
+			codes := make([]models.RoomCode, rooms)
+			fast := make([]*Client, 0, rooms-1)
+			for r := range codes {
+				codes[r] = models.RoomCode(fmt.Sprintf("ROOM%d", r))
+				client := NewClient(newFakeConn(b), fmt.Sprintf("p%d", r), codes[r], "127.0.0.1")
+				if r == 0 {
+					if !slow {
+						continue
+					}
+					// Never dropped, just slow to write everything it is sent
+					client.Send = make(chan []byte, b.N+1)
+					go client.WritePump(hub)
+					b.Cleanup(func() { hub.drop(client) })
+				} else {
+					client.stats = nil
+					fast = append(fast, client)
+				}
+				hub.RegisterNow(client)
+			}
+
+			var mu sync.Mutex
+			var latencies []time.Duration
+			var readers sync.WaitGroup
+			got := make(chan struct{}, len(fast))
+			for _, client := range fast {
+				readers.Add(1)
+				go func(client *Client) {
+					defer readers.Done()
+					seen := make([]time.Duration, 0, b.N)
+					for len(seen) < b.N {
+						sent, _ := strconv.ParseInt(string(<-client.Send), 10, 64)
+						seen = append(seen, time.Since(time.Unix(0, sent)))
+						got <- struct{}{}
+					}
+					mu.Lock()
+					latencies = append(latencies, seen...)
+					mu.Unlock()
+				}(client)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, code := range codes {
+					q.Broadcast(&BroadcastMessage{RoomCode: code, Message: []byte(strconv.FormatInt(time.Now().UnixNano(), 10))})
+				}
+				// Each round is delivered before the next one starts
+				for range fast {
+					<-got
+				}
+			}
+			readers.Wait()
+			b.StopTimer()
+
+			sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+			b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-µs")
+			if q.Dropped() != 0 {
+				b.Errorf("dropped %d broadcasts", q.Dropped())
+			}
+		})
+	}
+}